@@ -1,18 +1,29 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"koperasi-merah-putih/config"
 	"koperasi-merah-putih/internal/cache"
 	"koperasi-merah-putih/internal/database"
+	"koperasi-merah-putih/internal/gateway"
 	"koperasi-merah-putih/internal/handlers"
 	"koperasi-merah-putih/internal/middleware"
+	"koperasi-merah-putih/internal/money"
 	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
 	cassandraRepo "koperasi-merah-putih/internal/repository/cassandra"
 	"koperasi-merah-putih/internal/routes"
+	"koperasi-merah-putih/internal/scheduler"
 	"koperasi-merah-putih/internal/services"
+	"koperasi-merah-putih/internal/storage"
 )
 
 func main() {
@@ -39,6 +50,27 @@ func main() {
 	var redisCache *cache.RedisCache
 	// TODO: Initialize Redis cache if configured
 
+	fileStorage, err := storage.NewStorage(&cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialize file storage: %v", err)
+	}
+
+	// PPOB providers are picked by PPOBProvider.Adapter; the fake adapter is
+	// only available outside production, for end-to-end testing.
+	ppobAdapters := gateway.NewPPOBAdapterRegistry(&cfg.PPOB)
+	if cfg.App.Environment != "production" {
+		fakePPOB := gateway.NewFakePPOBAdapter(money.FromInt(10000000))
+		fakePPOB.SetTagihan("512345678901", "PELANGGAN DEMO", money.FromInt(150000), money.FromInt(2500), "202609")
+		ppobAdapters.Register(gateway.PPOBAdapterFake, fakePPOB.Factory())
+	}
+
+	// Refunds go to the gateway that took the payment, picked by
+	// PaymentProvider.Kode.
+	paymentRefunders := map[string]services.PaymentRefunder{
+		"midtrans": gateway.NewMidtransGateway(&cfg.Payment.Midtrans),
+		"xendit":   gateway.NewXenditGateway(&cfg.Payment.Xendit),
+	}
+
 	// Initialize repositories
 	userRepo := postgresRepo.NewUserRepository(postgresDB)
 	userRegistrationRepo := postgresRepo.NewUserRegistrationRepository(postgresDB)
@@ -50,6 +82,17 @@ func main() {
 	simpanPinjamRepo := postgresRepo.NewSimpanPinjamRepository(postgresDB)
 	klinikRepo := postgresRepo.NewKlinikRepository(postgresDB)
 	financialRepo := postgresRepo.NewFinancialRepository(postgresDB)
+	postingRepo := postgresRepo.NewPostingRepository(postgresDB)
+	periodeRepo := postgresRepo.NewPeriodeRepository(postgresDB)
+	shuRepo := postgresRepo.NewSHURepository(postgresDB)
+	anggaranRepo := postgresRepo.NewAnggaranRepository(postgresDB)
+	lampiranRepo := postgresRepo.NewLampiranRepository(postgresDB)
+	konsolidasiRepo := postgresRepo.NewKonsolidasiRepository(postgresDB)
+	bankRepo := postgresRepo.NewBankRepository(postgresDB)
+	jurnalTemplateRepo := postgresRepo.NewJurnalTemplateRepository(postgresDB)
+	asetRepo := postgresRepo.NewAsetRepository(postgresDB)
+	saldoAwalRepo := postgresRepo.NewSaldoAwalRepository(postgresDB)
+	pajakRepo := postgresRepo.NewPajakRepository(postgresDB)
 	wilayahRepo := postgresRepo.NewWilayahRepository(postgresDB)
 	masterDataRepo := postgresRepo.NewMasterDataRepository(postgresDB)
 	sequenceRepo := postgresRepo.NewSequenceRepository(postgresDB)
@@ -60,17 +103,32 @@ func main() {
 
 	// Initialize services
 	sequenceService := services.NewSequenceService(sequenceRepo)
-	paymentService := services.NewPaymentService(paymentRepo, paymentProviderRepo, sequenceService)
-	userService := services.NewUserService(userRepo, userRegistrationRepo, anggotaRepo, paymentService, sequenceService)
-	ppobService := services.NewPPOBService(ppobRepo, paymentService, sequenceService)
-	koperasiService := services.NewKoperasiService(koperasiRepo, anggotaRepo, wilayahRepo, sequenceService)
-	simpanPinjamService := services.NewSimpanPinjamService(simpanPinjamRepo, sequenceService)
-	klinikService := services.NewKlinikService(klinikRepo, sequenceService)
-	financialService := services.NewFinancialService(financialRepo, sequenceService)
+	paymentService := services.NewPaymentService(paymentRepo, paymentProviderRepo, sequenceService, paymentRefunders)
+	financialService := services.NewFinancialService(financialRepo, periodeRepo, anggaranRepo, lampiranRepo, sequenceService)
+	postingService := services.NewPostingService(postingRepo, financialRepo, financialService)
+	userService := services.NewUserService(userRepo, userRegistrationRepo, anggotaRepo, paymentService, postingService, sequenceService)
+	periodeService := services.NewPeriodeService(periodeRepo, financialRepo, financialService)
+	coaService := services.NewCOAService(financialRepo, koperasiRepo)
+	laporanKeuanganService := services.NewLaporanKeuanganService(financialRepo, koperasiRepo)
+	koperasiService := services.NewKoperasiService(koperasiRepo, anggotaRepo, wilayahRepo, sequenceService, coaService)
+	pajakService := services.NewPajakService(pajakRepo, produkRepo, koperasiRepo, postingService, sequenceService)
+	simpanPinjamService := services.NewSimpanPinjamService(simpanPinjamRepo, postingService, pajakService, sequenceService)
+	ppobService := services.NewPPOBService(ppobRepo, paymentService, postingService, financialService, simpanPinjamService, sequenceService, ppobAdapters)
+	shuService := services.NewSHUService(shuRepo, financialRepo, simpanPinjamRepo, financialService, simpanPinjamService)
+	anggaranService := services.NewAnggaranService(anggaranRepo, financialRepo)
+	bankService := services.NewBankService(bankRepo, financialRepo, financialService)
+	jurnalTemplateService := services.NewJurnalTemplateService(jurnalTemplateRepo, financialRepo, financialService)
+	asetService := services.NewAsetService(asetRepo, financialRepo, produkRepo, financialService, sequenceService)
+	hutangService := services.NewHutangService(produkRepo, postingService, pajakService, sequenceService)
+	saldoAwalService := services.NewSaldoAwalService(saldoAwalRepo, financialRepo, periodeRepo, postingRepo, simpanPinjamRepo, anggotaRepo, koperasiRepo, asetRepo, produkRepo, financialService, simpanPinjamService)
+	piutangService := services.NewPiutangService(produkRepo, anggotaRepo, postingService, simpanPinjamService, sequenceService)
+	klinikService := services.NewKlinikService(klinikRepo, postingService, sequenceService)
 	wilayahService := services.NewWilayahService(wilayahRepo)
 	masterDataService := services.NewMasterDataService(masterDataRepo)
-	produkService := services.NewProdukService(produkRepo, sequenceRepo)
-	reportingService := services.NewReportingService(koperasiRepo, anggotaRepo, produkRepo, simpanPinjamRepo, financialRepo, klinikRepo, redisCache)
+	produkService := services.NewProdukService(produkRepo, sequenceRepo, postingService, simpanPinjamService, pajakService)
+	lampiranService := services.NewLampiranService(lampiranRepo, financialRepo, produkRepo, simpanPinjamRepo, fileStorage, cfg.Storage.MaxFileSize)
+	konsolidasiService := services.NewKonsolidasiService(konsolidasiRepo, financialRepo, koperasiRepo)
+	reportingService := services.NewReportingService(koperasiRepo, anggotaRepo, produkRepo, simpanPinjamRepo, financialRepo, klinikRepo, financialService, redisCache)
 
 	// Initialize handlers
 	userHandler := handlers.NewUserHandler(userService)
@@ -79,7 +137,17 @@ func main() {
 	koperasiHandler := handlers.NewKoperasiHandler(koperasiService)
 	simpanPinjamHandler := handlers.NewSimpanPinjamHandler(simpanPinjamService)
 	klinikHandler := handlers.NewKlinikHandler(klinikService)
-	financialHandler := handlers.NewFinancialHandler(financialService)
+	financialHandler := handlers.NewFinancialHandler(financialService, postingService, periodeService, jurnalTemplateService, coaService, laporanKeuanganService)
+	shuHandler := handlers.NewSHUHandler(shuService)
+	anggaranHandler := handlers.NewAnggaranHandler(anggaranService)
+	bankHandler := handlers.NewBankHandler(bankService)
+	asetHandler := handlers.NewAsetHandler(asetService)
+	hutangHandler := handlers.NewHutangHandler(hutangService)
+	piutangHandler := handlers.NewPiutangHandler(piutangService)
+	saldoAwalHandler := handlers.NewSaldoAwalHandler(saldoAwalService)
+	pajakHandler := handlers.NewPajakHandler(pajakService)
+	lampiranHandler := handlers.NewLampiranHandler(lampiranService)
+	konsolidasiHandler := handlers.NewKonsolidasiHandler(konsolidasiService)
 	wilayahHandler := handlers.NewWilayahHandler(wilayahService)
 	masterDataHandler := handlers.NewMasterDataHandler(masterDataService)
 	sequenceHandler := handlers.NewSequenceHandler(sequenceService)
@@ -99,6 +167,16 @@ func main() {
 		simpanPinjamHandler,
 		klinikHandler,
 		financialHandler,
+		shuHandler,
+		anggaranHandler,
+		bankHandler,
+		asetHandler,
+		hutangHandler,
+		piutangHandler,
+		saldoAwalHandler,
+		pajakHandler,
+		lampiranHandler,
+		konsolidasiHandler,
 		wilayahHandler,
 		masterDataHandler,
 		sequenceHandler,
@@ -128,8 +206,47 @@ func main() {
 
 	appRoutes.SetupRoutes(router)
 
-	log.Printf("Server starting on port %s", cfg.App.Port)
-	if err := router.Run(":" + cfg.App.Port); err != nil {
+	// Background jobs
+	jobs := scheduler.New()
+	jobs.Every("jurnal-template", time.Hour, jurnalTemplateService.RunDue)
+	jobs.Every("penyusutan-aset", 24*time.Hour, asetService.RunDue)
+	jobs.Every("ppob-inquiry", 5*time.Minute, ppobService.ExpireInquiries)
+	jobs.Every("ppob-status", 5*time.Minute, ppobService.CekTransaksiPending)
+	jobs.Every("ppob-refund", 15*time.Minute, ppobService.RetryRefunds)
+	jobs.Every("ppob-settlement", time.Hour, ppobService.RunAutoSettlement)
+	jobs.Start()
+
+	srv := &http.Server{
+		Addr:    ":" + cfg.App.Port,
+		Handler: router,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("Server starting on port %s", cfg.App.Port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	// Wait for SIGINT/SIGTERM, then let in-flight requests and running jobs
+	// finish before the deferred database close.
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-serverErr:
+		jobs.Stop()
 		log.Fatalf("Failed to start server: %v", err)
+	case sig := <-quit:
+		log.Printf("Received %s, shutting down", sig)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("Server shutdown did not complete: %v", err)
 	}
+	jobs.Stop()
+	log.Println("Server stopped")
 }
\ No newline at end of file
@@ -83,12 +83,49 @@ func runMigrations(db *gorm.DB) error {
 		&postgres.COAAkun{},
 		&postgres.JurnalUmum{},
 		&postgres.JurnalDetail{},
+		&postgres.PostingRule{},
+		&postgres.PostingRuleLine{},
+		&postgres.PeriodeAkuntansi{},
+		&postgres.PeriodeReopenRequest{},
+		&postgres.TutupBuku{},
+		&postgres.SaldoAwalAkun{},
+		&postgres.MigrasiSaldoAwal{},
+		&postgres.MigrasiSaldoAwalAkun{},
+		&postgres.MigrasiSaldoAwalRekening{},
+		&postgres.MigrasiSaldoAwalFaktur{},
+		&postgres.PengaturanPajak{},
+		&postgres.KategoriPajakProduk{},
+		&postgres.PotonganPajak{},
+		&postgres.SetoranPajak{},
+		&postgres.Lampiran{},
+		&postgres.RantaiJurnal{},
+		&postgres.PosKonsolidasi{},
+		&postgres.PemetaanAkunKonsolidasi{},
+		&postgres.ArusKasMapping{},
+		&postgres.Anggaran{},
+		&postgres.AnggaranDetail{},
+		&postgres.RekeningBank{},
+		&postgres.RekeningKoran{},
+		&postgres.RekeningKoranLine{},
+		&postgres.JurnalTemplate{},
+		&postgres.JurnalTemplateLine{},
+		&postgres.JurnalTemplateParameter{},
+		&postgres.KategoriAset{},
+		&postgres.AsetTetap{},
+		&postgres.PenyusutanAset{},
 
 		// Simpan Pinjam
 		&postgres.ProdukSimpanPinjam{},
 		&postgres.RekeningSimpanPinjam{},
 		&postgres.TransaksiSimpanPinjam{},
 
+		// SHU
+		&postgres.SHUConfig{},
+		&postgres.SHUConfigAlokasi{},
+		&postgres.SHUPerhitungan{},
+		&postgres.SHUAlokasi{},
+		&postgres.SHUAnggota{},
+
 		// Klinik
 		&postgres.KlinikTenagaMedis{},
 		&postgres.KlinikPasien{},
@@ -109,14 +146,19 @@ func runMigrations(db *gorm.DB) error {
 		&postgres.PembayaranPembelian{},
 		&postgres.PenjualanHeader{},
 		&postgres.PenjualanDetail{},
+		&postgres.PembayaranPenjualan{},
 		&postgres.StokMovement{},
 		&postgres.ProdukDiskon{},
 
 		// PPOB
 		&postgres.PPOBKategori{},
+		&postgres.PPOBProvider{},
 		&postgres.PPOBProduk{},
+		&postgres.PPOBInquiry{},
 		&postgres.PPOBTransaksi{},
+		&postgres.PPOBPaymentConfig{},
 		&postgres.PPOBSettlement{},
+		&postgres.PPOBSettlementDetail{},
 
 		// Payment
 		&postgres.PaymentTransaction{},
@@ -146,13 +188,18 @@ func dropAllTables(db *gorm.DB) {
 		"sequences",
 		"simpanan_pokok_configs",
 		"payment_transactions",
+		"ppob_settlement_details",
 		"ppob_settlements",
+		"ppob_payment_configs",
 		"ppob_transaksis",
+		"ppob_inquiries",
 		"ppob_produks",
+		"ppob_providers",
 		"ppob_kategoris",
 		"produk_diskons",
 		"stok_movements",
 		"penjualan_details",
+		"pembayaran_penjualans",
 		"penjualan_headers",
 		"pembayaran_pembelians",
 		"pembelian_details",
@@ -169,11 +216,45 @@ func dropAllTables(db *gorm.DB) {
 		"obats",
 		"pasiens",
 		"tenaga_medis",
+		"shu_anggota",
+		"shu_alokasis",
+		"shu_perhitungans",
+		"shu_config_alokasis",
+		"shu_configs",
 		"angsuran_pinjamen",
 		"pinjamen",
 		"transaksi_simpan_pinjams",
 		"rekening_simpan_pinjams",
 		"produk_simpan_pinjams",
+		"penyusutan_asets",
+		"aset_tetaps",
+		"kategori_asets",
+		"jurnal_template_parameters",
+		"jurnal_template_lines",
+		"jurnal_templates",
+		"rekening_koran_lines",
+		"rekening_korans",
+		"rekening_banks",
+		"anggaran_details",
+		"anggarans",
+		"arus_kas_mappings",
+		"pemetaan_akun_konsolidasis",
+		"pos_konsolidasis",
+		"lampirans",
+		"rantai_jurnals",
+		"setoran_pajaks",
+		"potongan_pajaks",
+		"kategori_pajak_produks",
+		"pengaturan_pajaks",
+		"migrasi_saldo_awal_rekenings",
+		"migrasi_saldo_awal_akuns",
+		"migrasi_saldo_awals",
+		"saldo_awal_akuns",
+		"tutup_bukus",
+		"periode_reopen_requests",
+		"periode_akuntansis",
+		"posting_rule_lines",
+		"posting_rules",
 		"jurnal_details",
 		"jurnal_umums",
 		"coa_akuns",
@@ -254,11 +335,33 @@ func createCustomConstraints(db *gorm.DB) {
 		"ALTER TABLE koperasi_aktivitas_usahas ADD CONSTRAINT check_jenis_usaha CHECK (jenis_usaha IN ('utama', 'sampingan'))",
 		"ALTER TABLE modal_koperasis ADD CONSTRAINT check_jenis_modal CHECK (jenis_modal IN ('simpanan_pokok', 'simpanan_wajib', 'dana_cadangan', 'dana_hibah', 'modal_penyertaan'))",
 		"ALTER TABLE coa_kategoris ADD CONSTRAINT check_tipe CHECK (tipe IN ('aset', 'kewajiban', 'ekuitas', 'pendapatan', 'beban'))",
+		"ALTER TABLE posting_rule_lines ADD CONSTRAINT check_posisi_posting CHECK (posisi IN ('debit', 'kredit'))",
+		"ALTER TABLE periode_akuntansis ADD CONSTRAINT check_status_periode CHECK (status IN ('open', 'closed', 'locked'))",
+		"ALTER TABLE periode_reopen_requests ADD CONSTRAINT check_status_reopen CHECK (status IN ('pending', 'approved', 'rejected'))",
+		"ALTER TABLE migrasi_saldo_awals ADD CONSTRAINT check_status_saldo_awal CHECK (status IN ('draft', 'posted'))",
+		"ALTER TABLE potongan_pajaks ADD CONSTRAINT check_jenis_potongan_pajak CHECK (jenis_pajak IN ('pph21', 'pph23', 'pph4_2'))",
+		"ALTER TABLE lampirans ADD CONSTRAINT check_jenis_dokumen_lampiran CHECK (jenis_dokumen IN ('jurnal_umum', 'pembelian', 'pembayaran_pembelian', 'transaksi_simpan_pinjam'))",
+		"ALTER TABLE pos_konsolidasis ADD CONSTRAINT check_tipe_pos_konsolidasi CHECK (tipe IN ('aset', 'kewajiban', 'ekuitas', 'pendapatan', 'beban'))",
+		"ALTER TABLE kategori_pajak_produks ADD CONSTRAINT check_kategori_pajak CHECK (kategori_pajak IN ('bkp', 'non_bkp'))",
+		"ALTER TABLE arus_kas_mappings ADD CONSTRAINT check_aktivitas_arus_kas CHECK (aktivitas IN ('operasi', 'investasi', 'pendanaan'))",
+		"ALTER TABLE anggarans ADD CONSTRAINT check_status_anggaran CHECK (status IN ('draft', 'approved', 'superseded'))",
+		"ALTER TABLE anggarans ADD CONSTRAINT check_kontrol_anggaran CHECK (kontrol_anggaran IN ('none', 'warn', 'block'))",
+		"ALTER TABLE anggaran_details ADD CONSTRAINT check_bulan_anggaran CHECK (bulan BETWEEN 1 AND 12)",
+		"ALTER TABLE rekening_korans ADD CONSTRAINT check_format_rekening_koran CHECK (format IN ('csv', 'mt940'))",
+		"ALTER TABLE rekening_koran_lines ADD CONSTRAINT check_status_mutasi_bank CHECK (status IN ('unmatched', 'matched'))",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_rekening_koran_lines_jurnal_detail ON rekening_koran_lines (jurnal_detail_id) WHERE jurnal_detail_id > 0",
+		"ALTER TABLE jurnal_templates ADD CONSTRAINT check_frekuensi_jurnal_template CHECK (frekuensi IN ('monthly', 'quarterly'))",
+		"ALTER TABLE jurnal_template_lines ADD CONSTRAINT check_posisi_jurnal_template CHECK (posisi IN ('debit', 'kredit'))",
+		"ALTER TABLE kategori_asets ADD CONSTRAINT check_metode_penyusutan CHECK (metode_penyusutan IN ('garis_lurus', 'saldo_menurun'))",
+		"ALTER TABLE aset_tetaps ADD CONSTRAINT check_status_aset CHECK (status IN ('aktif', 'dilepas'))",
+		"CREATE INDEX IF NOT EXISTS idx_pembayaran_pembelian_header ON pembayaran_pembelians(pembelian_header_id)",
+		"CREATE INDEX IF NOT EXISTS idx_penjualan_header_kredit ON penjualan_headers(koperasi_id, anggota_id) WHERE metode_pembayaran = 'credit'",
+		"ALTER TABLE shu_perhitungans ADD CONSTRAINT check_status_shu CHECK (status IN ('draft', 'approved', 'paid'))",
 		"ALTER TABLE coa_akuns ADD CONSTRAINT check_saldo_normal CHECK (saldo_normal IN ('debit', 'kredit'))",
-		"ALTER TABLE jurnal_umums ADD CONSTRAINT check_status_jurnal CHECK (status IN ('draft', 'posted', 'cancelled'))",
+		"ALTER TABLE jurnal_umums ADD CONSTRAINT check_status_jurnal CHECK (status IN ('draft', 'posted', 'cancelled', 'reversed'))",
 		"ALTER TABLE produk_simpan_pinjams ADD CONSTRAINT check_jenis CHECK (jenis IN ('simpanan', 'pinjaman'))",
 		"ALTER TABLE rekening_simpan_pinjams ADD CONSTRAINT check_status_rekening CHECK (status IN ('aktif', 'lunas', 'macet', 'tutup'))",
-		"ALTER TABLE transaksi_simpan_pinjams ADD CONSTRAINT check_jenis_transaksi CHECK (jenis_transaksi IN ('setoran', 'penarikan', 'pencairan', 'angsuran', 'bunga', 'denda'))",
+		"ALTER TABLE transaksi_simpan_pinjams ADD CONSTRAINT check_jenis_transaksi CHECK (jenis_transaksi IN ('setoran', 'penarikan', 'pencairan', 'angsuran', 'bunga', 'denda', 'saldo_awal'))",
 		"ALTER TABLE klinik_tenaga_medis ADD CONSTRAINT check_jenis_kelamin_medis CHECK (jenis_kelamin IN ('L', 'P'))",
 		"ALTER TABLE klinik_tenaga_medis ADD CONSTRAINT check_status_medis CHECK (status IN ('aktif', 'non_aktif', 'cuti'))",
 		"ALTER TABLE klinik_pasiens ADD CONSTRAINT check_jenis_kelamin_pasien CHECK (jenis_kelamin IN ('L', 'P'))",
@@ -270,7 +373,8 @@ func createCustomConstraints(db *gorm.DB) {
 		"ALTER TABLE pembelian_headers ADD CONSTRAINT check_status_pembayaran_pembelian CHECK (status_pembayaran IN ('unpaid', 'partial', 'paid', 'overdue'))",
 		"ALTER TABLE pembayaran_pembelians ADD CONSTRAINT check_metode_pembayaran_pembelian CHECK (metode_pembayaran IN ('cash', 'transfer', 'giro', 'other'))",
 		"ALTER TABLE penjualan_headers ADD CONSTRAINT check_metode_pembayaran_penjualan CHECK (metode_pembayaran IN ('cash', 'debit', 'credit', 'transfer', 'simpanan'))",
-		"ALTER TABLE penjualan_headers ADD CONSTRAINT check_status_pembayaran_penjualan CHECK (status_pembayaran IN ('pending', 'paid', 'failed', 'refund'))",
+		"ALTER TABLE penjualan_headers ADD CONSTRAINT check_status_pembayaran_penjualan CHECK (status_pembayaran IN ('pending', 'unpaid', 'partial', 'paid', 'failed', 'refund'))",
+		"ALTER TABLE pembayaran_penjualans ADD CONSTRAINT check_metode_pembayaran_piutang CHECK (metode_pembayaran IN ('cash', 'transfer', 'simpanan'))",
 		"ALTER TABLE stok_movements ADD CONSTRAINT check_tipe_movement CHECK (tipe_movement IN ('in', 'out', 'adjustment', 'transfer'))",
 		"ALTER TABLE stok_movements ADD CONSTRAINT check_referensi_tipe CHECK (referensi_tipe IN ('pembelian', 'penjualan', 'adjustment', 'transfer', 'expired', 'damaged'))",
 		"ALTER TABLE produk_diskons ADD CONSTRAINT check_tipe_diskon CHECK (tipe_diskon IN ('percentage', 'fixed'))",
@@ -278,9 +382,14 @@ func createCustomConstraints(db *gorm.DB) {
 		"ALTER TABLE produks ADD CONSTRAINT check_harga_positive CHECK (harga_jual > 0)",
 		"ALTER TABLE produks ADD CONSTRAINT check_stok_non_negative CHECK (stok_current >= 0)",
 		"ALTER TABLE ppob_transaksis ADD CONSTRAINT check_status_ppob CHECK (status IN ('pending', 'success', 'failed', 'cancelled'))",
-		"ALTER TABLE ppob_transaksis ADD CONSTRAINT check_payment_status_ppob CHECK (payment_status IN ('pending', 'paid', 'failed'))",
+		"ALTER TABLE ppob_inquiries ADD CONSTRAINT check_status_ppob_inquiry CHECK (status IN ('aktif', 'dipakai', 'kedaluwarsa'))",
+		"ALTER TABLE ppob_transaksis ADD CONSTRAINT check_payment_status_ppob CHECK (payment_status IN ('pending', 'paid', 'failed', 'refunded'))",
+		"ALTER TABLE ppob_transaksis ADD CONSTRAINT check_metode_pembayaran_ppob CHECK (metode_pembayaran IN ('gateway', 'simpanan'))",
+		"ALTER TABLE ppob_transaksis ADD CONSTRAINT check_status_refund_ppob CHECK (status_refund IN ('', 'pending', 'processing', 'success', 'failed'))",
 		"ALTER TABLE ppob_settlements ADD CONSTRAINT check_status_settlement CHECK (status IN ('draft', 'processed', 'paid'))",
+		"ALTER TABLE ppob_payment_configs ADD CONSTRAINT check_settlement_schedule CHECK (settlement_schedule IN ('daily', 'weekly', 'monthly'))",
 		"ALTER TABLE payment_transactions ADD CONSTRAINT check_status_payment CHECK (status IN ('pending', 'paid', 'expired', 'failed', 'cancelled'))",
+		"ALTER TABLE payment_transactions ADD CONSTRAINT check_refund_status_payment CHECK (refund_status IN ('', 'success', 'failed'))",
 		"ALTER TABLE payment_transactions ADD CONSTRAINT check_transaction_type CHECK (transaction_type IN ('simpanan_pokok', 'ppob', 'simpanan', 'pinjaman', 'klinik', 'other'))",
 		"ALTER TABLE audit_logs ADD CONSTRAINT check_action CHECK (action IN ('create', 'update', 'delete'))",
 		"ALTER TABLE sequence_numbers ADD CONSTRAINT check_reset_period CHECK (reset_period IN ('never', 'daily', 'monthly', 'yearly'))",
@@ -5,11 +5,11 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 
 	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
 
 	"gorm.io/gorm"
 )
@@ -209,8 +209,8 @@ func seedJurnalFromCSV(db *gorm.DB) {
 			continue
 		}
 
-		debit, _ := strconv.ParseFloat(debitStr, 64)
-		kredit, _ := strconv.ParseFloat(kreditStr, 64)
+		debit, _ := money.Parse(debitStr)
+		kredit, _ := money.Parse(kreditStr)
 
 		entry := map[string]string{
 			"tanggal":          tanggal,
@@ -218,8 +218,8 @@ func seedJurnalFromCSV(db *gorm.DB) {
 			"keterangan":       keterangan,
 			"namaAkun":         namaAkun,
 			"detailKeterangan": detailKeterangan,
-			"debit":            fmt.Sprintf("%.0f", debit),
-			"kredit":           fmt.Sprintf("%.0f", kredit),
+			"debit":            debit.String(),
+			"kredit":           kredit.String(),
 		}
 
 		jurnalMap[noBukti] = append(jurnalMap[noBukti], entry)
@@ -246,11 +246,11 @@ func seedJurnalFromCSV(db *gorm.DB) {
 		}
 
 		// Calculate total debit and kredit
-		var totalDebit, totalKredit float64
+		var totalDebit, totalKredit money.Amount
 		var keterangan string
 		for _, entry := range entries {
-			debit, _ := strconv.ParseFloat(entry["debit"], 64)
-			kredit, _ := strconv.ParseFloat(entry["kredit"], 64)
+			debit, _ := money.Parse(entry["debit"])
+			kredit, _ := money.Parse(entry["kredit"])
 			totalDebit += debit
 			totalKredit += kredit
 			if keterangan == "" {
@@ -313,8 +313,8 @@ func seedJurnalFromCSV(db *gorm.DB) {
 			}
 			detailCreatedCount++
 
-			debit, _ := strconv.ParseFloat(entry["debit"], 64)
-			kredit, _ := strconv.ParseFloat(entry["kredit"], 64)
+			debit, _ := money.Parse(entry["debit"])
+			kredit, _ := money.Parse(entry["kredit"])
 
 			detail := postgres.JurnalDetail{
 				JurnalID:   jurnal.ID,
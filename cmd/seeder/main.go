@@ -8,6 +8,7 @@ import (
 	"koperasi-merah-putih/config"
 	"koperasi-merah-putih/internal/database"
 	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
 	"gorm.io/gorm"
 )
 
@@ -320,7 +321,7 @@ func seedSimpanPinjamProduk(db *gorm.DB) {
 			Jenis:           "simpanan",
 			Kategori:        "Simpanan Berjangka",
 			BungaSimpanan:   6.5,
-			MinimalSaldo:    1000000,
+			MinimalSaldo:    money.FromInt(1000000),
 			JangkaWaktuMax:  12,
 			SyaratKetentuan: "Simpanan berjangka dengan tenor 12 bulan",
 			IsAktif:         true,
@@ -333,7 +334,7 @@ func seedSimpanPinjamProduk(db *gorm.DB) {
 			Kategori:         "Pinjaman Konsumtif",
 			BungaPinjaman:    18.0,
 			BungaDenda:       2.0,
-			MaksimalPinjaman: 100000000,
+			MaksimalPinjaman: money.FromInt(100000000),
 			JangkaWaktuMax:   24,
 			SyaratKetentuan:  "Pinjaman untuk kebutuhan konsumtif",
 			IsAktif:          true,
@@ -353,7 +354,7 @@ func seedSimpanPinjamRekening(db *gorm.DB) {
 			ProdukID:       1,
 			AnggotaID:      1,
 			NomorRekening:  "SP001001",
-			SaldoSimpanan:  5000000,
+			SaldoSimpanan:  money.FromInt(5000000),
 			BungaBerjalan:  0,
 			Status:         "aktif",
 			TanggalBuka:    time.Now().AddDate(0, -6, 0),
@@ -363,8 +364,8 @@ func seedSimpanPinjamRekening(db *gorm.DB) {
 			ProdukID:      2,
 			AnggotaID:     2,
 			NomorRekening: "SP002001",
-			PokokPinjaman: 25000000,
-			SisaPokok:     25000000,
+			PokokPinjaman: money.FromInt(25000000),
+			SisaPokok:     money.FromInt(25000000),
 			BungaBerjalan: 0,
 			Status:        "aktif",
 			TanggalBuka:   time.Now().AddDate(0, -3, 0),
@@ -384,9 +385,9 @@ func seedSimpanPinjamTransaksi(db *gorm.DB) {
 			RekeningID:       1,
 			NomorTransaksi:   "T001",
 			JenisTransaksi:   "setoran",
-			Jumlah:           5000000,
+			Jumlah:           money.FromInt(5000000),
 			SaldoSebelum:     0,
-			SaldoSesudah:     5000000,
+			SaldoSesudah:     money.FromInt(5000000),
 			Keterangan:       "Setoran awal simpanan berjangka",
 			TanggalTransaksi: time.Now().AddDate(0, -6, 0),
 			CreatedBy:        2,
@@ -396,9 +397,9 @@ func seedSimpanPinjamTransaksi(db *gorm.DB) {
 			RekeningID:       2,
 			NomorTransaksi:   "T002",
 			JenisTransaksi:   "pencairan",
-			Jumlah:           25000000,
+			Jumlah:           money.FromInt(25000000),
 			SaldoSebelum:     0,
-			SaldoSesudah:     25000000,
+			SaldoSesudah:     money.FromInt(25000000),
 			Keterangan:       "Pencairan pinjaman konsumtif",
 			TanggalTransaksi: time.Now().AddDate(0, -3, 0),
 			CreatedBy:        2,
@@ -424,7 +425,7 @@ func seedKlinikTenagaMedis(db *gorm.DB) {
 			Telepon:          "081234567892",
 			Email:            "dr.budi@klinik.com",
 			JadwalPraktik:    "Senin-Jumat 08:00-17:00",
-			TarifKonsultasi:  100000,
+			TarifKonsultasi:  money.FromInt(100000),
 			Status:           "aktif",
 		},
 		{
@@ -504,8 +505,8 @@ func seedKlinikObat(db *gorm.DB) {
 			Satuan:        "Tablet",
 			StokMinimal:   50,
 			StokCurrent:   200,
-			HargaBeli:     500,
-			HargaJual:     1000,
+			HargaBeli:     money.FromInt(500),
+			HargaJual:     money.FromInt(1000),
 			IsAktif:       true,
 		},
 		{
@@ -518,8 +519,8 @@ func seedKlinikObat(db *gorm.DB) {
 			Satuan:        "Kapsul",
 			StokMinimal:   30,
 			StokCurrent:   100,
-			HargaBeli:     2000,
-			HargaJual:     3500,
+			HargaBeli:     money.FromInt(2000),
+			HargaJual:     money.FromInt(3500),
 			IsAktif:       true,
 		},
 	}
@@ -543,10 +544,10 @@ func seedKlinikKunjungan(db *gorm.DB) {
 			PemeriksaanFisik: "TD: 120/80 mmHg, Nadi: 80x/menit, Suhu: 38°C",
 			Diagnosis:        "Demam tifoid suspek",
 			TerapiPengobatan: "Istirahat, minum obat teratur",
-			BiayaKonsultasi:  100000,
+			BiayaKonsultasi:  money.FromInt(100000),
 			BiayaTindakan:    0,
-			BiayaObat:        7000,
-			TotalBiaya:       107000,
+			BiayaObat:        money.FromInt(7000),
+			TotalBiaya:       money.FromInt(107000),
 			StatusPembayaran: "lunas",
 		},
 		{
@@ -560,10 +561,10 @@ func seedKlinikKunjungan(db *gorm.DB) {
 			PemeriksaanFisik: "TD: 130/90 mmHg, Nadi: 85x/menit, Suhu: 36.5°C",
 			Diagnosis:        "ISPA",
 			TerapiPengobatan: "Antibiotik dan ekspektoran",
-			BiayaKonsultasi:  100000,
-			BiayaTindakan:    25000,
-			BiayaObat:        14000,
-			TotalBiaya:       139000,
+			BiayaKonsultasi:  money.FromInt(100000),
+			BiayaTindakan:    money.FromInt(25000),
+			BiayaObat:        money.FromInt(14000),
+			TotalBiaya:       money.FromInt(139000),
 			StatusPembayaran: "lunas",
 		},
 	}
@@ -583,8 +584,8 @@ func seedJurnalUmum(db *gorm.DB) {
 			TanggalTransaksi: time.Now().AddDate(0, -1, 0),
 			Referensi:        "SETORAN-001",
 			Keterangan:       "Penerimaan simpanan pokok anggota A001",
-			TotalDebit:       1000000,
-			TotalKredit:      1000000,
+			TotalDebit:       money.FromInt(1000000),
+			TotalKredit:      money.FromInt(1000000),
 			Status:           "posted",
 			CreatedBy:        2,
 			PostedBy:         2,
@@ -597,8 +598,8 @@ func seedJurnalUmum(db *gorm.DB) {
 			TanggalTransaksi: time.Now().AddDate(0, 0, -15),
 			Referensi:        "PINJAMAN-001",
 			Keterangan:       "Pencairan pinjaman anggota A002",
-			TotalDebit:       25000000,
-			TotalKredit:      25000000,
+			TotalDebit:       money.FromInt(25000000),
+			TotalKredit:      money.FromInt(25000000),
 			Status:           "posted",
 			CreatedBy:        2,
 			PostedBy:         2,
@@ -613,12 +614,12 @@ func seedJurnalUmum(db *gorm.DB) {
 	// Seed Jurnal Details
 	details := []postgres.JurnalDetail{
 		// Jurnal 1: Kas Debit, Simpanan Pokok Kredit
-		{JurnalID: 1, AkunID: 1, Keterangan: "Penerimaan kas dari simpanan pokok", Debit: 1000000, Kredit: 0},
-		{JurnalID: 1, AkunID: 4, Keterangan: "Simpanan pokok anggota A001", Debit: 0, Kredit: 1000000},
+		{JurnalID: 1, AkunID: 1, Keterangan: "Penerimaan kas dari simpanan pokok", Debit: money.FromInt(1000000), Kredit: 0},
+		{JurnalID: 1, AkunID: 4, Keterangan: "Simpanan pokok anggota A001", Debit: 0, Kredit: money.FromInt(1000000)},
 
 		// Jurnal 2: Piutang Debit, Kas Kredit
-		{JurnalID: 2, AkunID: 3, Keterangan: "Pencairan pinjaman anggota A002", Debit: 25000000, Kredit: 0},
-		{JurnalID: 2, AkunID: 1, Keterangan: "Pengeluaran kas untuk pinjaman", Debit: 0, Kredit: 25000000},
+		{JurnalID: 2, AkunID: 3, Keterangan: "Pencairan pinjaman anggota A002", Debit: money.FromInt(25000000), Kredit: 0},
+		{JurnalID: 2, AkunID: 1, Keterangan: "Pengeluaran kas untuk pinjaman", Debit: 0, Kredit: money.FromInt(25000000)},
 	}
 
 	for _, detail := range details {
@@ -644,7 +645,7 @@ func seedPPOBKategori(db *gorm.DB) {
 
 func seedPPOBProvider(db *gorm.DB) {
 	providers := []postgres.PPOBProvider{
-		{Kode: "DEFAULT", Nama: "Provider Default", BaseURL: "https://api.ppob.local", IsAktif: true},
+		{Kode: "DEFAULT", Nama: "Provider Default", Adapter: "fake", BaseURL: "https://api.ppob.local", IsAktif: true},
 	}
 
 	for _, provider := range providers {
@@ -655,10 +656,11 @@ func seedPPOBProvider(db *gorm.DB) {
 
 func seedPPOBProduk(db *gorm.DB) {
 	produks := []postgres.PPOBProduk{
-		{ProviderID: 1, KategoriID: 1, NamaProduk: "Telkomsel 10.000", KodeProduk: "TSEL10", HargaBeli: 10000, HargaJual: 10500, Deskripsi: "Pulsa Telkomsel 10rb", IsAktif: true},
-		{ProviderID: 1, KategoriID: 1, NamaProduk: "Indosat 25.000", KodeProduk: "ISAT25", HargaBeli: 25000, HargaJual: 25200, Deskripsi: "Pulsa Indosat 25rb", IsAktif: true},
-		{ProviderID: 1, KategoriID: 2, NamaProduk: "PLN Token 20.000", KodeProduk: "PLN20", HargaBeli: 20000, HargaJual: 20500, Deskripsi: "Token listrik PLN 20rb", IsAktif: true},
-		{ProviderID: 1, KategoriID: 2, NamaProduk: "PLN Token 50.000", KodeProduk: "PLN50", HargaBeli: 50000, HargaJual: 50500, Deskripsi: "Token listrik PLN 50rb", IsAktif: true},
+		{ProviderID: 1, KategoriID: 1, NamaProduk: "Telkomsel 10.000", KodeProduk: "TSEL10", HargaBeli: money.FromInt(10000), HargaJual: money.FromInt(10500), Deskripsi: "Pulsa Telkomsel 10rb", IsAktif: true},
+		{ProviderID: 1, KategoriID: 1, NamaProduk: "Indosat 25.000", KodeProduk: "ISAT25", HargaBeli: money.FromInt(25000), HargaJual: money.FromInt(25200), Deskripsi: "Pulsa Indosat 25rb", IsAktif: true},
+		{ProviderID: 1, KategoriID: 2, NamaProduk: "PLN Token 20.000", KodeProduk: "PLN20", HargaBeli: money.FromInt(20000), HargaJual: money.FromInt(20500), Deskripsi: "Token listrik PLN 20rb", IsAktif: true},
+		{ProviderID: 1, KategoriID: 2, NamaProduk: "PLN Token 50.000", KodeProduk: "PLN50", HargaBeli: money.FromInt(50000), HargaJual: money.FromInt(50500), Deskripsi: "Token listrik PLN 50rb", IsAktif: true},
+		{ProviderID: 1, KategoriID: 2, NamaProduk: "PLN Pascabayar", KodeProduk: "PLNPASCA", Deskripsi: "Tagihan listrik PLN pascabayar", IsAktif: true, IsPascabayar: true},
 	}
 
 	for _, produk := range produks {
@@ -803,8 +805,8 @@ func seedProduk(db *gorm.DB) {
 			NamaProduk:       "Beras Premium 5kg",
 			Deskripsi:        "Beras putih premium kualitas terbaik",
 			Brand:            "Sania",
-			HargaBeli:        45000,
-			HargaJual:        52000,
+			HargaBeli:        money.FromInt(45000),
+			HargaJual:        money.FromInt(52000),
 			MarginPersen:     15.56,
 			StokMinimal:      10,
 			StokMaksimal:     100,
@@ -822,8 +824,8 @@ func seedProduk(db *gorm.DB) {
 			NamaProduk:       "Sayur Kangkung",
 			Deskripsi:        "Kangkung segar organik",
 			Brand:            "Organik Nusantara",
-			HargaBeli:        3000,
-			HargaJual:        4500,
+			HargaBeli:        money.FromInt(3000),
+			HargaJual:        money.FromInt(4500),
 			MarginPersen:     50,
 			StokMinimal:      5,
 			StokMaksimal:     50,
@@ -843,8 +845,8 @@ func seedProduk(db *gorm.DB) {
 			NamaProduk:       "Air Mineral Botol 600ml",
 			Deskripsi:        "Air mineral murni dalam kemasan botol",
 			Brand:            "Aqua",
-			HargaBeli:        2500,
-			HargaJual:        3500,
+			HargaBeli:        money.FromInt(2500),
+			HargaJual:        money.FromInt(3500),
 			MarginPersen:     40,
 			StokMinimal:      20,
 			StokMaksimal:     200,
@@ -862,8 +864,8 @@ func seedProduk(db *gorm.DB) {
 			NamaProduk:       "Susu Sapi Murni 1L",
 			Deskripsi:        "Susu sapi segar langsung dari peternakan",
 			Brand:            "Fresh Milk",
-			HargaBeli:        15000,
-			HargaJual:        18000,
+			HargaBeli:        money.FromInt(15000),
+			HargaJual:        money.FromInt(18000),
 			MarginPersen:     20,
 			StokMinimal:      10,
 			StokMaksimal:     50,
@@ -884,8 +886,8 @@ func seedProduk(db *gorm.DB) {
 			Deskripsi:        "Ayam kampung segar ukuran 1-1.5kg",
 			Brand:            "Ternak Lokal",
 			BeratBersih:      1.2,
-			HargaBeli:        35000,
-			HargaJual:        45000,
+			HargaBeli:        money.FromInt(35000),
+			HargaJual:        money.FromInt(45000),
 			MarginPersen:     28.57,
 			StokMinimal:      5,
 			StokMaksimal:     30,
@@ -905,8 +907,8 @@ func seedProduk(db *gorm.DB) {
 			NamaProduk:       "Mangga Harum Manis",
 			Deskripsi:        "Mangga harum manis matang pohon",
 			Brand:            "Buah Lokal",
-			HargaBeli:        8000,
-			HargaJual:        12000,
+			HargaBeli:        money.FromInt(8000),
+			HargaJual:        money.FromInt(12000),
 			MarginPersen:     50,
 			StokMinimal:      10,
 			StokMaksimal:     100,
@@ -926,8 +928,8 @@ func seedProduk(db *gorm.DB) {
 			NamaProduk:       "Cabai Merah Keriting",
 			Deskripsi:        "Cabai merah keriting segar dan pedas",
 			Brand:            "Rempah Nusantara",
-			HargaBeli:        25000,
-			HargaJual:        35000,
+			HargaBeli:        money.FromInt(25000),
+			HargaJual:        money.FromInt(35000),
 			MarginPersen:     40,
 			StokMinimal:      2,
 			StokMaksimal:     20,
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"koperasi-merah-putih/config"
+	"koperasi-merah-putih/internal/database"
+	"koperasi-merah-putih/internal/models/postgres"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+	"koperasi-merah-putih/internal/services"
+)
+
+// verify-jurnal walks the hash chain of posted journals and reports the
+// first broken link per koperasi. It exits with status 1 when any chain is
+// broken. The head printed for an intact chain can be kept outside the
+// database and passed back with -anchor to catch removed or rehashed links.
+func main() {
+	koperasiID := flag.Uint64("koperasi", 0, "Koperasi ID to verify (default: every koperasi)")
+	anchorFlag := flag.String("anchor", "", "Head recorded earlier, as urutan:hash (requires -koperasi)")
+	flag.Parse()
+
+	var anchor *services.AnchorRantaiJurnal
+	if *anchorFlag != "" {
+		urutanStr, hash, ok := strings.Cut(*anchorFlag, ":")
+		urutan, err := strconv.ParseUint(urutanStr, 10, 64)
+		if !ok || err != nil || hash == "" || *koperasiID == 0 {
+			log.Fatal("Invalid -anchor: expected urutan:hash together with -koperasi")
+		}
+		anchor = &services.AnchorRantaiJurnal{Urutan: urutan, Hash: hash}
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal("Failed to load config:", err)
+	}
+
+	db, err := database.NewPostgresConnection(&cfg.Postgres)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	financialRepo := postgresRepo.NewFinancialRepository(db.DB)
+	financialService := services.NewFinancialService(
+		financialRepo,
+		postgresRepo.NewPeriodeRepository(db.DB),
+		postgresRepo.NewAnggaranRepository(db.DB),
+		postgresRepo.NewLampiranRepository(db.DB),
+		services.NewSequenceService(postgresRepo.NewSequenceRepository(db.DB)),
+	)
+
+	koperasiIDs := []uint64{*koperasiID}
+	if *koperasiID == 0 {
+		koperasiIDs = nil
+		if err := db.DB.Model(&postgres.Koperasi{}).Order("id ASC").Pluck("id", &koperasiIDs).Error; err != nil {
+			log.Fatal("Failed to list koperasi:", err)
+		}
+	}
+
+	putus := 0
+	for _, id := range koperasiIDs {
+		hasil, err := financialService.VerifikasiRantaiJurnal(id, anchor)
+		if err != nil {
+			log.Fatalf("Failed to verify koperasi %d: %v", id, err)
+		}
+
+		if hasil.Utuh {
+			fmt.Printf("✓ Koperasi %d: %d jurnal verified", id, hasil.JumlahDiperiksa)
+			if hasil.UrutanTerakhir > 0 {
+				fmt.Printf(", head %d:%s", hasil.UrutanTerakhir, hasil.HashTerakhir)
+			}
+		} else {
+			putus++
+			fmt.Printf("✗ Koperasi %d: chain broken at link %d", id, hasil.Putus.Urutan)
+			if hasil.Putus.NomorJurnal != "" {
+				fmt.Printf(" (jurnal %s)", hasil.Putus.NomorJurnal)
+			}
+			fmt.Printf(": %s, %d jurnal verified before it", hasil.Putus.Alasan, hasil.JumlahDiperiksa)
+		}
+		if hasil.JurnalTanpaRantai > 0 {
+			fmt.Printf(", %d posted jurnal not in the chain", hasil.JurnalTanpaRantai)
+		}
+		fmt.Println()
+	}
+
+	if putus > 0 {
+		os.Exit(1)
+	}
+}
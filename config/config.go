@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
@@ -21,6 +22,9 @@ type Config struct {
 
 	// PPOB
 	PPOB PPOBConfig
+
+	// File storage
+	Storage StorageConfig
 }
 
 type PostgresConfig struct {
@@ -67,10 +71,17 @@ type XenditConfig struct {
 
 type PPOBConfig struct {
 	ProviderURL string
+	Username    string
 	APIKey      string
 	SecretKey   string
 }
 
+type StorageConfig struct {
+	Driver      string
+	LocalPath   string
+	MaxFileSize int64
+}
+
 func LoadConfig() (*Config, error) {
 	// Load .env file if exists
 	if err := godotenv.Load(); err != nil {
@@ -116,9 +127,15 @@ func LoadConfig() (*Config, error) {
 		},
 		PPOB: PPOBConfig{
 			ProviderURL: getEnv("PPOB_PROVIDER_URL", ""),
+			Username:    getEnv("PPOB_USERNAME", ""),
 			APIKey:      getEnv("PPOB_API_KEY", ""),
 			SecretKey:   getEnv("PPOB_SECRET_KEY", ""),
 		},
+		Storage: StorageConfig{
+			Driver:      getEnv("STORAGE_DRIVER", "local"),
+			LocalPath:   getEnv("STORAGE_LOCAL_PATH", "./storage"),
+			MaxFileSize: getEnvInt64("STORAGE_MAX_FILE_SIZE", 5<<20),
+		},
 	}
 
 	return config, nil
@@ -129,4 +146,13 @@ func getEnv(key, defaultValue string) string {
 		return value
 	}
 	return defaultValue
+}
+
+func getEnvInt64(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	}
+	return defaultValue
 }
\ No newline at end of file
@@ -30,8 +30,10 @@ func NewDatabaseManager(cfg *config.Config) (*DatabaseManager, error) {
 	}, nil
 }
 
-func (dm *DatabaseManager) AutoMigrate() error {
-	err := dm.Postgres.DB.AutoMigrate(
+// Models lists every PostgreSQL model migrated by the server. Tests use it
+// to build the same schema.
+func Models() []interface{} {
+	return []interface{}{
 		&postgres.Tenant{},
 		&postgres.WilayahProvinsi{},
 		&postgres.WilayahKabupaten{},
@@ -50,12 +52,48 @@ func (dm *DatabaseManager) AutoMigrate() error {
 		&postgres.ModalKoperasi{},
 		&postgres.JurnalUmum{},
 		&postgres.JurnalDetail{},
+		&postgres.PostingRule{},
+		&postgres.PostingRuleLine{},
+		&postgres.PeriodeAkuntansi{},
+		&postgres.PeriodeReopenRequest{},
+		&postgres.TutupBuku{},
+		&postgres.SaldoAwalAkun{},
+		&postgres.MigrasiSaldoAwal{},
+		&postgres.MigrasiSaldoAwalAkun{},
+		&postgres.MigrasiSaldoAwalRekening{},
+		&postgres.MigrasiSaldoAwalFaktur{},
+		&postgres.PengaturanPajak{},
+		&postgres.KategoriPajakProduk{},
+		&postgres.PotonganPajak{},
+		&postgres.SetoranPajak{},
+		&postgres.Lampiran{},
+		&postgres.RantaiJurnal{},
+		&postgres.PosKonsolidasi{},
+		&postgres.PemetaanAkunKonsolidasi{},
+		&postgres.ArusKasMapping{},
+		&postgres.Anggaran{},
+		&postgres.AnggaranDetail{},
+		&postgres.RekeningBank{},
+		&postgres.RekeningKoran{},
+		&postgres.RekeningKoranLine{},
+		&postgres.JurnalTemplate{},
+		&postgres.JurnalTemplateLine{},
+		&postgres.JurnalTemplateParameter{},
+		&postgres.KategoriAset{},
+		&postgres.AsetTetap{},
+		&postgres.PenyusutanAset{},
 		&postgres.ProdukSimpanPinjam{},
 		&postgres.RekeningSimpanPinjam{},
 		&postgres.TransaksiSimpanPinjam{},
+		&postgres.SHUConfig{},
+		&postgres.SHUConfigAlokasi{},
+		&postgres.SHUPerhitungan{},
+		&postgres.SHUAlokasi{},
+		&postgres.SHUAnggota{},
 		&postgres.PPOBKategori{},
 		&postgres.PPOBProvider{},
 		&postgres.PPOBProduk{},
+		&postgres.PPOBInquiry{},
 		&postgres.PPOBTransaksi{},
 		&postgres.PPOBPaymentConfig{},
 		&postgres.PPOBSettlement{},
@@ -76,10 +114,29 @@ func (dm *DatabaseManager) AutoMigrate() error {
 		&postgres.KlinikKunjungan{},
 		&postgres.KlinikObat{},
 		&postgres.KlinikResep{},
+		&postgres.KategoriProduk{},
+		&postgres.SatuanProduk{},
+		&postgres.Supplier{},
+		&postgres.Produk{},
+		&postgres.SupplierProduk{},
+		&postgres.PurchaseOrder{},
+		&postgres.PurchaseOrderDetail{},
+		&postgres.PembelianHeader{},
+		&postgres.PembelianDetail{},
+		&postgres.PembayaranPembelian{},
+		&postgres.PenjualanHeader{},
+		&postgres.PenjualanDetail{},
+		&postgres.PembayaranPenjualan{},
+		&postgres.StokMovement{},
+		&postgres.ProdukDiskon{},
 		&postgres.AuditLog{},
 		&postgres.SystemSetting{},
 		&postgres.SequenceNumber{},
-	)
+	}
+}
+
+func (dm *DatabaseManager) AutoMigrate() error {
+	err := dm.Postgres.DB.AutoMigrate(Models()...)
 	if err != nil {
 		return fmt.Errorf("failed to migrate PostgreSQL models: %v", err)
 	}
@@ -0,0 +1,223 @@
+package gateway
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"koperasi-merah-putih/config"
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+)
+
+const digiflazzBaseURL = "https://api.digiflazz.com"
+
+// DigiflazzAdapter talks to a Digiflazz-style aggregator API: a single
+// /v1/transaction endpoint for topup, inquiry and status checks, and
+// /v1/cek-saldo for the deposit. Every request is signed with
+// md5(username + api key + ref_id).
+type DigiflazzAdapter struct {
+	baseURL  string
+	username string
+	apiKey   string
+	client   *http.Client
+}
+
+func NewDigiflazzAdapter(provider *postgres.PPOBProvider, defaults *config.PPOBConfig) *DigiflazzAdapter {
+	adapter := &DigiflazzAdapter{
+		baseURL:  provider.BaseURL,
+		username: provider.Username,
+		apiKey:   provider.APIKey,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+
+	if defaults != nil {
+		if adapter.baseURL == "" {
+			adapter.baseURL = defaults.ProviderURL
+		}
+		if adapter.username == "" {
+			adapter.username = defaults.Username
+		}
+		if adapter.apiKey == "" {
+			adapter.apiKey = defaults.APIKey
+		}
+	}
+	if adapter.baseURL == "" {
+		adapter.baseURL = digiflazzBaseURL
+	}
+	adapter.baseURL = strings.TrimRight(adapter.baseURL, "/")
+
+	return adapter
+}
+
+type digiflazzTransaksi struct {
+	RefID        string  `json:"ref_id"`
+	CustomerNo   string  `json:"customer_no"`
+	CustomerName string  `json:"customer_name"`
+	BuyerSKUCode string  `json:"buyer_sku_code"`
+	Message      string  `json:"message"`
+	Status       string  `json:"status"`
+	RC           string  `json:"rc"`
+	SN           string  `json:"sn"`
+	Price        float64 `json:"price"`
+	SellingPrice float64 `json:"selling_price"`
+	Admin        float64 `json:"admin"`
+	Desc         struct {
+		Detail []struct {
+			Periode string `json:"periode"`
+		} `json:"detail"`
+	} `json:"desc"`
+}
+
+func (d *DigiflazzAdapter) Topup(req *PPOBRequest) (*PPOBResponse, error) {
+	if req.Pascabayar {
+		return d.transaksi("pay-pasca", req)
+	}
+	return d.transaksi("", req)
+}
+
+func (d *DigiflazzAdapter) Inquiry(req *PPOBRequest) (*PPOBResponse, error) {
+	return d.transaksi("inq-pasca", req)
+}
+
+// CekStatus for prepaid products resends the topup request with the same
+// ref_id; the provider does not create a new transaction and returns the
+// latest status. Postpaid products have a command of their own.
+func (d *DigiflazzAdapter) CekStatus(req *PPOBRequest) (*PPOBResponse, error) {
+	if req.Pascabayar {
+		return d.transaksi("status-pasca", req)
+	}
+	return d.transaksi("", req)
+}
+
+func (d *DigiflazzAdapter) CekSaldo() (money.Amount, error) {
+	requestBody := map[string]interface{}{
+		"cmd":      "deposit",
+		"username": d.username,
+		"sign":     d.sign("depo"),
+	}
+
+	body, statusCode, err := d.makeRequest("/v1/cek-saldo", requestBody)
+	if err != nil {
+		return 0, err
+	}
+
+	if statusCode >= 400 {
+		return 0, fmt.Errorf("digiflazz error: %s", string(body))
+	}
+
+	var response struct {
+		Data struct {
+			Deposit float64 `json:"deposit"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal response: %v", err)
+	}
+
+	return money.FromFloat(response.Data.Deposit), nil
+}
+
+func (d *DigiflazzAdapter) transaksi(commands string, req *PPOBRequest) (*PPOBResponse, error) {
+	requestBody := map[string]interface{}{
+		"username":       d.username,
+		"buyer_sku_code": req.KodeProduk,
+		"customer_no":    req.NomorTujuan,
+		"ref_id":         req.RefID,
+		"sign":           d.sign(req.RefID),
+	}
+	if commands != "" {
+		requestBody["commands"] = commands
+	}
+
+	body, statusCode, err := d.makeRequest("/v1/transaction", requestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Data digiflazzTransaksi `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %v", err)
+	}
+
+	// A rejected transaction still comes back as data with status Gagal
+	// even when the HTTP status is 4xx; only a response without a status
+	// is treated as a communication error.
+	if response.Data.Status == "" {
+		return nil, fmt.Errorf("digiflazz error: %s", string(body))
+	}
+	if statusCode >= 500 {
+		return nil, fmt.Errorf("digiflazz error: %s", string(body))
+	}
+
+	result := &PPOBResponse{
+		RefID:          response.Data.RefID,
+		Status:         d.mapStatus(response.Data.Status),
+		NomorReferensi: response.Data.SN,
+		NamaPelanggan:  response.Data.CustomerName,
+		Harga:          money.FromFloat(response.Data.Price),
+		AdminProvider:  money.FromFloat(response.Data.Admin),
+		Pesan:          response.Data.Message,
+		Raw:            string(body),
+	}
+	// For postpaid products selling_price is the bill plus the admin fee
+	// printed on the receipt; price is what the koperasi deposit is charged.
+	if response.Data.SellingPrice > 0 {
+		result.Tagihan = money.FromFloat(response.Data.SellingPrice) - result.AdminProvider
+	}
+	for _, detail := range response.Data.Desc.Detail {
+		result.Periode = append(result.Periode, detail.Periode)
+	}
+
+	return result, nil
+}
+
+func (d *DigiflazzAdapter) mapStatus(status string) string {
+	switch status {
+	case "Sukses":
+		return PPOBStatusSukses
+	case "Gagal":
+		return PPOBStatusGagal
+	default:
+		return PPOBStatusPending
+	}
+}
+
+func (d *DigiflazzAdapter) sign(suffix string) string {
+	hash := md5.Sum([]byte(d.username + d.apiKey + suffix))
+	return hex.EncodeToString(hash[:])
+}
+
+func (d *DigiflazzAdapter) makeRequest(endpoint string, requestBody interface{}) ([]byte, int, error) {
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", d.baseURL+endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read response: %v", err)
+	}
+
+	return body, resp.StatusCode, nil
+}
@@ -11,6 +11,7 @@ import (
 
 	"koperasi-merah-putih/config"
 	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
 )
 
 type MidtransGateway struct {
@@ -59,7 +60,7 @@ func (m *MidtransGateway) buildVARequest(payment *postgres.PaymentTransaction, m
 		"payment_type": "bank_transfer",
 		"transaction_details": map[string]interface{}{
 			"order_id":     payment.NomorTransaksi,
-			"gross_amount": int64(payment.TotalAmount),
+			"gross_amount": payment.TotalAmount.Rupiah(),
 		},
 		"bank_transfer": map[string]interface{}{
 			"bank": method.BankCode,
@@ -81,7 +82,7 @@ func (m *MidtransGateway) buildQRISRequest(payment *postgres.PaymentTransaction)
 		"payment_type": "qris",
 		"transaction_details": map[string]interface{}{
 			"order_id":     payment.NomorTransaksi,
-			"gross_amount": int64(payment.TotalAmount),
+			"gross_amount": payment.TotalAmount.Rupiah(),
 		},
 		"customer_details": map[string]interface{}{
 			"first_name": payment.CustomerName,
@@ -105,7 +106,7 @@ func (m *MidtransGateway) buildEWalletRequest(payment *postgres.PaymentTransacti
 		"payment_type": walletType,
 		"transaction_details": map[string]interface{}{
 			"order_id":     payment.NomorTransaksi,
-			"gross_amount": int64(payment.TotalAmount),
+			"gross_amount": payment.TotalAmount.Rupiah(),
 		},
 		"customer_details": map[string]interface{}{
 			"first_name": payment.CustomerName,
@@ -163,6 +164,31 @@ func (m *MidtransGateway) makeRequest(endpoint string, requestBody interface{})
 	return response, nil
 }
 
+// Refund returns part or all of a payment. Midtrans uses refundKey to
+// reject a duplicate refund when the request is repeated.
+func (m *MidtransGateway) Refund(payment *postgres.PaymentTransaction, amount money.Amount, refundKey, alasan string) (string, error) {
+	requestBody := map[string]interface{}{
+		"refund_key": refundKey,
+		"amount":     amount.Rupiah(),
+		"reason":     alasan,
+	}
+
+	response, err := m.makeRequest("/v2/"+payment.NomorTransaksi+"/refund", requestBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to refund payment: %v", err)
+	}
+
+	if statusCode, _ := response["status_code"].(string); statusCode != "200" {
+		message, _ := response["status_message"].(string)
+		return "", fmt.Errorf("midtrans refund rejected: %s", message)
+	}
+
+	if key, ok := response["refund_key"].(string); ok && key != "" {
+		return key, nil
+	}
+	return refundKey, nil
+}
+
 func (m *MidtransGateway) VerifySignature(data map[string]interface{}, signature string) bool {
 	orderID, _ := data["order_id"].(string)
 	statusCode, _ := data["status_code"].(string)
@@ -0,0 +1,99 @@
+package gateway
+
+import (
+	"fmt"
+
+	"koperasi-merah-putih/config"
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+)
+
+// Transaction outcomes at the PPOB provider, matching the PPOBTransaksi
+// statuses so they can be stored as is.
+const (
+	PPOBStatusSukses  = "success"
+	PPOBStatusPending = "pending"
+	PPOBStatusGagal   = "failed"
+)
+
+// Adapter kinds selected through PPOBProvider.Adapter.
+const (
+	PPOBAdapterDigiflazz = "digiflazz"
+	PPOBAdapterFake      = "fake"
+)
+
+// PPOBAdapter is the integration contract with a PPOB aggregator. RefID is
+// always the koperasi's transaction number, so repeated calls are idempotent
+// at the provider.
+type PPOBAdapter interface {
+	Topup(req *PPOBRequest) (*PPOBResponse, error)
+	Inquiry(req *PPOBRequest) (*PPOBResponse, error)
+	CekStatus(req *PPOBRequest) (*PPOBResponse, error)
+	CekSaldo() (money.Amount, error)
+}
+
+// PPOBRequest for a postpaid product must use the same RefID as its
+// inquiry.
+type PPOBRequest struct {
+	RefID       string
+	KodeProduk  string
+	NomorTujuan string
+	Pascabayar  bool
+}
+
+// PPOBResponse.Harga is what the provider charges the koperasi. For a
+// postpaid inquiry, Tagihan and AdminProvider are the amounts on the
+// customer's bill.
+type PPOBResponse struct {
+	RefID          string
+	Status         string
+	NomorReferensi string
+	NamaPelanggan  string
+	Harga          money.Amount
+	Tagihan        money.Amount
+	AdminProvider  money.Amount
+	Periode        []string
+	Pesan          string
+	Raw            string
+}
+
+type PPOBAdapterFactory func(provider *postgres.PPOBProvider) (PPOBAdapter, error)
+
+// PPOBAdapterRegistry maps adapter kinds to their factories. The Digiflazz
+// adapter is always registered; others (e.g. the fake one for testing) are
+// registered by the caller.
+type PPOBAdapterRegistry struct {
+	factories map[string]PPOBAdapterFactory
+}
+
+func NewPPOBAdapterRegistry(defaults *config.PPOBConfig) *PPOBAdapterRegistry {
+	registry := &PPOBAdapterRegistry{factories: map[string]PPOBAdapterFactory{}}
+	registry.Register(PPOBAdapterDigiflazz, func(provider *postgres.PPOBProvider) (PPOBAdapter, error) {
+		return NewDigiflazzAdapter(provider, defaults), nil
+	})
+	return registry
+}
+
+func (r *PPOBAdapterRegistry) Register(jenis string, factory PPOBAdapterFactory) {
+	r.factories[jenis] = factory
+}
+
+func (r *PPOBAdapterRegistry) Adapter(provider *postgres.PPOBProvider) (PPOBAdapter, error) {
+	if provider == nil || provider.ID == 0 {
+		return nil, fmt.Errorf("PPOB provider not found")
+	}
+	if !provider.IsAktif {
+		return nil, fmt.Errorf("PPOB provider %s is not active", provider.Kode)
+	}
+
+	jenis := provider.Adapter
+	if jenis == "" {
+		jenis = PPOBAdapterDigiflazz
+	}
+
+	factory, ok := r.factories[jenis]
+	if !ok {
+		return nil, fmt.Errorf("unsupported PPOB adapter: %s", jenis)
+	}
+	return factory(provider)
+}
@@ -0,0 +1,212 @@
+package gateway
+
+import (
+	"fmt"
+	"sync"
+
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+)
+
+// FakePPOBAdapter is an in-process PPOB provider for tests and development.
+// Transactions are kept in memory by ref_id, the deposit is charged the
+// product price for every successful topup, and the outcome per nomor
+// tujuan can be set so the pending and failed flows get exercised too.
+type FakePPOBAdapter struct {
+	mu        sync.Mutex
+	saldo     money.Amount
+	harga     map[string]money.Amount
+	hasil     map[string]string
+	tagihan   map[string]*PPOBResponse
+	inquiry   map[string]*PPOBResponse
+	transaksi map[string]*PPOBResponse
+	putus     map[string]bool
+	urutan    int
+}
+
+func NewFakePPOBAdapter(saldo money.Amount) *FakePPOBAdapter {
+	return &FakePPOBAdapter{
+		saldo:     saldo,
+		harga:     map[string]money.Amount{},
+		hasil:     map[string]string{},
+		tagihan:   map[string]*PPOBResponse{},
+		inquiry:   map[string]*PPOBResponse{},
+		transaksi: map[string]*PPOBResponse{},
+		putus:     map[string]bool{},
+	}
+}
+
+// Factory returns a factory that always hands out this instance, for
+// registering in PPOBAdapterRegistry as PPOBAdapterFake.
+func (f *FakePPOBAdapter) Factory() PPOBAdapterFactory {
+	return func(provider *postgres.PPOBProvider) (PPOBAdapter, error) {
+		return f, nil
+	}
+}
+
+func (f *FakePPOBAdapter) SetHarga(kodeProduk string, harga money.Amount) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.harga[kodeProduk] = harga
+}
+
+// SetTagihan registers a postpaid bill for a customer number. An inquiry
+// for an unregistered number fails.
+func (f *FakePPOBAdapter) SetTagihan(nomorTujuan, namaPelanggan string, tagihan, admin money.Amount, periode ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tagihan[nomorTujuan] = &PPOBResponse{
+		NamaPelanggan: namaPelanggan,
+		Harga:         tagihan + admin,
+		Tagihan:       tagihan,
+		AdminProvider: admin,
+		Periode:       periode,
+	}
+}
+
+// SetHasil sets the status returned for topups to nomorTujuan. Without it,
+// topups always succeed.
+func (f *FakePPOBAdapter) SetHasil(nomorTujuan, status string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.hasil[nomorTujuan] = status
+}
+
+// SetResponsHilang makes the next topup to nomorTujuan take the order and
+// then fail with a transport error, as if the response was lost on the way
+// back. The order can still be found with CekStatus.
+func (f *FakePPOBAdapter) SetResponsHilang(nomorTujuan string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.putus[nomorTujuan] = true
+}
+
+// Selesaikan moves a pending transaction to a final status, like a late
+// provider callback.
+func (f *FakePPOBAdapter) Selesaikan(refID, status string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	trx, ok := f.transaksi[refID]
+	if !ok {
+		return fmt.Errorf("transaction %s not found", refID)
+	}
+	if trx.Status != PPOBStatusPending {
+		return fmt.Errorf("transaction %s is already %s", refID, trx.Status)
+	}
+
+	if status == PPOBStatusSukses {
+		f.urutan++
+		trx.NomorReferensi = fmt.Sprintf("FAKE%08d", f.urutan)
+		trx.Pesan = "Transaksi Sukses"
+	} else {
+		f.saldo += trx.Harga
+		trx.Pesan = "Transaksi Gagal"
+	}
+	trx.Status = status
+	return nil
+}
+
+func (f *FakePPOBAdapter) Topup(req *PPOBRequest) (*PPOBResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if trx, ok := f.transaksi[req.RefID]; ok {
+		copied := *trx
+		return &copied, nil
+	}
+
+	trx := &PPOBResponse{
+		RefID: req.RefID,
+		Harga: f.harga[req.KodeProduk],
+	}
+
+	var inquiry *PPOBResponse
+	if req.Pascabayar {
+		inquiry = f.inquiry[req.RefID]
+		if inquiry != nil {
+			trx.Harga = inquiry.Harga
+			trx.NamaPelanggan = inquiry.NamaPelanggan
+		}
+	}
+
+	status, ok := f.hasil[req.NomorTujuan]
+	if !ok {
+		status = PPOBStatusSukses
+	}
+
+	switch {
+	case req.Pascabayar && inquiry == nil:
+		trx.Status = PPOBStatusGagal
+		trx.Pesan = "Inquiry tidak ditemukan"
+	case status == PPOBStatusGagal:
+		trx.Status = PPOBStatusGagal
+		trx.Pesan = "Nomor tujuan ditolak provider"
+	case f.saldo < trx.Harga:
+		trx.Status = PPOBStatusGagal
+		trx.Pesan = "Saldo deposit tidak cukup"
+	case status == PPOBStatusPending:
+		f.saldo -= trx.Harga
+		trx.Status = PPOBStatusPending
+		trx.Pesan = "Transaksi Pending"
+	default:
+		f.saldo -= trx.Harga
+		f.urutan++
+		trx.Status = PPOBStatusSukses
+		trx.NomorReferensi = fmt.Sprintf("FAKE%08d", f.urutan)
+		trx.Pesan = "Transaksi Sukses"
+	}
+
+	f.transaksi[req.RefID] = trx
+	if f.putus[req.NomorTujuan] {
+		delete(f.putus, req.NomorTujuan)
+		return nil, fmt.Errorf("read tcp: connection reset by peer")
+	}
+	copied := *trx
+	return &copied, nil
+}
+
+func (f *FakePPOBAdapter) Inquiry(req *PPOBRequest) (*PPOBResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tagihan, ok := f.tagihan[req.NomorTujuan]
+	if !ok {
+		return &PPOBResponse{
+			RefID:  req.RefID,
+			Status: PPOBStatusGagal,
+			Pesan:  "Tagihan tidak ditemukan",
+		}, nil
+	}
+
+	inquiry := *tagihan
+	inquiry.RefID = req.RefID
+	inquiry.Status = PPOBStatusSukses
+	inquiry.Pesan = "Inquiry Sukses"
+	f.inquiry[req.RefID] = &inquiry
+
+	copied := inquiry
+	return &copied, nil
+}
+
+func (f *FakePPOBAdapter) CekStatus(req *PPOBRequest) (*PPOBResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	trx, ok := f.transaksi[req.RefID]
+	if !ok {
+		return &PPOBResponse{
+			RefID:  req.RefID,
+			Status: PPOBStatusGagal,
+			Pesan:  "Transaksi tidak ditemukan",
+		}, nil
+	}
+	copied := *trx
+	return &copied, nil
+}
+
+func (f *FakePPOBAdapter) CekSaldo() (money.Amount, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.saldo, nil
+}
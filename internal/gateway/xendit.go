@@ -13,6 +13,7 @@ import (
 
 	"koperasi-merah-putih/config"
 	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
 )
 
 type XenditGateway struct {
@@ -61,7 +62,7 @@ func (x *XenditGateway) buildVARequest(payment *postgres.PaymentTransaction, met
 		"external_id":   payment.NomorTransaksi,
 		"bank_code":     method.BankCode,
 		"name":          payment.CustomerName,
-		"expected_amount": int64(payment.TotalAmount),
+		"expected_amount": payment.TotalAmount.Rupiah(),
 		"expiration_date": payment.ExpiredDate.Format(time.RFC3339),
 		"is_closed":     true,
 		"is_single_use": true,
@@ -72,7 +73,7 @@ func (x *XenditGateway) buildQRISRequest(payment *postgres.PaymentTransaction) m
 	return map[string]interface{}{
 		"external_id": payment.NomorTransaksi,
 		"type":        "DYNAMIC",
-		"amount":      int64(payment.TotalAmount),
+		"amount":      payment.TotalAmount.Rupiah(),
 		"callback_url": "https://your-domain.com/webhook/xendit",
 	}
 }
@@ -86,7 +87,7 @@ func (x *XenditGateway) buildEWalletRequest(payment *postgres.PaymentTransaction
 	request := map[string]interface{}{
 		"reference_id":   payment.NomorTransaksi,
 		"currency":       "IDR",
-		"amount":         int64(payment.TotalAmount),
+		"amount":         payment.TotalAmount.Rupiah(),
 		"checkout_method": "ONE_TIME_PAYMENT",
 		"channel_code":   walletType,
 		"channel_properties": map[string]interface{}{
@@ -142,6 +143,33 @@ func (x *XenditGateway) makeRequest(endpoint string, requestBody interface{}) (m
 	return response, nil
 }
 
+// Refund asks Xendit to return a payment. refundKey is sent as the
+// reference_id so a repeated request does not create a new refund.
+func (x *XenditGateway) Refund(payment *postgres.PaymentTransaction, amount money.Amount, refundKey, alasan string) (string, error) {
+	requestBody := map[string]interface{}{
+		"reference_id": refundKey,
+		"amount":       amount.Rupiah(),
+		"currency":     "IDR",
+		"reason":       "REQUESTED_BY_CUSTOMER",
+		"metadata": map[string]interface{}{
+			"alasan": alasan,
+		},
+	}
+	if payment.InvoiceID != "" {
+		requestBody["invoice_id"] = payment.InvoiceID
+	} else {
+		requestBody["payment_request_id"] = payment.ExternalID
+	}
+
+	response, err := x.makeRequest("/refunds", requestBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to refund payment: %v", err)
+	}
+
+	refundID, _ := response["id"].(string)
+	return refundID, nil
+}
+
 func (x *XenditGateway) VerifySignature(data []byte, signature string) bool {
 	h := hmac.New(sha256.New, []byte(x.config.WebhookToken))
 	h.Write(data)
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"koperasi-merah-putih/internal/services"
+)
+
+type AnggaranHandler struct {
+	anggaranService *services.AnggaranService
+}
+
+func NewAnggaranHandler(anggaranService *services.AnggaranService) *AnggaranHandler {
+	return &AnggaranHandler{anggaranService: anggaranService}
+}
+
+func (h *AnggaranHandler) CreateAnggaran(c *gin.Context) {
+	var req services.CreateAnggaranRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	anggaran, err := h.anggaranService.CreateAnggaran(&req, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Anggaran created successfully",
+		"anggaran": anggaran,
+	})
+}
+
+func (h *AnggaranHandler) GetAnggaranList(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	tahun, _ := strconv.Atoi(c.Query("tahun"))
+
+	anggarans, err := h.anggaranService.GetAnggaranList(koperasiID, tahun)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"anggaran": anggarans,
+	})
+}
+
+func (h *AnggaranHandler) GetAnggaran(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid anggaran ID"})
+		return
+	}
+
+	anggaran, err := h.anggaranService.GetAnggaran(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Anggaran not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"anggaran": anggaran,
+	})
+}
+
+func (h *AnggaranHandler) UpdateAnggaran(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid anggaran ID"})
+		return
+	}
+
+	var req services.UpdateAnggaranRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	anggaran, err := h.anggaranService.UpdateAnggaran(id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Anggaran updated successfully",
+		"anggaran": anggaran,
+	})
+}
+
+func (h *AnggaranHandler) ReviseAnggaran(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid anggaran ID"})
+		return
+	}
+
+	var req services.UpdateAnggaranRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	revisi, err := h.anggaranService.ReviseAnggaran(id, &req, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Anggaran revision created successfully",
+		"anggaran": revisi,
+	})
+}
+
+func (h *AnggaranHandler) ApproveAnggaran(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid anggaran ID"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	err = h.anggaranService.ApproveAnggaran(id, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Anggaran approved successfully",
+	})
+}
+
+func (h *AnggaranHandler) GetRealisasi(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	tahun, err := strconv.Atoi(c.Query("tahun"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tahun"})
+		return
+	}
+
+	bulanSampai, _ := strconv.Atoi(c.DefaultQuery("bulan_sampai", "12"))
+	anggaranID, _ := strconv.ParseUint(c.Query("anggaran_id"), 10, 64)
+
+	laporan, err := h.anggaranService.GetRealisasi(koperasiID, tahun, bulanSampai, anggaranID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"realisasi": laporan,
+	})
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"koperasi-merah-putih/internal/services"
+)
+
+type AsetHandler struct {
+	asetService *services.AsetService
+}
+
+func NewAsetHandler(asetService *services.AsetService) *AsetHandler {
+	return &AsetHandler{asetService: asetService}
+}
+
+func (h *AsetHandler) CreateKategori(c *gin.Context) {
+	var req services.CreateKategoriAsetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	kategori, err := h.asetService.CreateKategori(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Kategori aset created successfully",
+		"kategori": kategori,
+	})
+}
+
+func (h *AsetHandler) GetKategoriList(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	kategoris, err := h.asetService.GetKategoriList(koperasiID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"kategori": kategoris})
+}
+
+func (h *AsetHandler) UpdateKategori(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid kategori ID"})
+		return
+	}
+
+	var req services.UpdateKategoriAsetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	kategori, err := h.asetService.UpdateKategori(id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Kategori aset updated successfully",
+		"kategori": kategori,
+	})
+}
+
+func (h *AsetHandler) CreateAset(c *gin.Context) {
+	var req services.CreateAsetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	aset, err := h.asetService.CreateAset(&req, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Aset created successfully",
+		"aset":    aset,
+	})
+}
+
+func (h *AsetHandler) GetAsetList(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	asets, err := h.asetService.GetAsetList(koperasiID, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"aset": asets})
+}
+
+func (h *AsetHandler) GetAset(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid aset ID"})
+		return
+	}
+
+	aset, err := h.asetService.GetAset(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Aset not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"aset": aset})
+}
+
+func (h *AsetHandler) GetRiwayatPenyusutan(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid aset ID"})
+		return
+	}
+
+	penyusutan, err := h.asetService.GetRiwayatPenyusutan(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"penyusutan": penyusutan})
+}
+
+func (h *AsetHandler) LepasAset(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid aset ID"})
+		return
+	}
+
+	var req services.LepasAsetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	aset, err := h.asetService.LepasAset(id, &req, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Aset disposed successfully",
+		"aset":    aset,
+	})
+}
+
+func (h *AsetHandler) RunPenyusutan(c *gin.Context) {
+	var req services.RunPenyusutanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	hasil, err := h.asetService.RunPenyusutan(req.KoperasiID, req.Periode, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Penyusutan posted successfully",
+		"penyusutan": hasil,
+	})
+}
+
+func (h *AsetHandler) GetRegister(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	tanggal := time.Now()
+	if tanggalStr := c.Query("tanggal"); tanggalStr != "" {
+		tanggal, err = time.Parse("2006-01-02", tanggalStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tanggal format"})
+			return
+		}
+	}
+
+	laporan, err := h.asetService.GetRegister(koperasiID, tanggal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"register": laporan})
+}
@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"koperasi-merah-putih/internal/money"
+	"koperasi-merah-putih/internal/services"
+	"koperasi-merah-putih/internal/utils"
+)
+
+// maxRekeningKoranSize caps uploaded bank statement files.
+const maxRekeningKoranSize = 10 << 20
+
+type BankHandler struct {
+	bankService *services.BankService
+}
+
+func NewBankHandler(bankService *services.BankService) *BankHandler {
+	return &BankHandler{bankService: bankService}
+}
+
+func (h *BankHandler) CreateRekeningBank(c *gin.Context) {
+	var req services.CreateRekeningBankRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rekening, err := h.bankService.CreateRekeningBank(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":       "Rekening bank created successfully",
+		"rekening_bank": rekening,
+	})
+}
+
+func (h *BankHandler) GetRekeningBankList(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	rekenings, err := h.bankService.GetRekeningBankList(koperasiID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"rekening_bank": rekenings,
+	})
+}
+
+func (h *BankHandler) ImportRekeningKoran(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rekening bank ID"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Statement file is required"})
+		return
+	}
+	if fileHeader.Size > maxRekeningKoranSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Statement file is too large"})
+		return
+	}
+
+	format := c.PostForm("format")
+	if format == "" {
+		format = "mt940"
+		if utils.GetFileExtension(fileHeader.Filename) == "csv" {
+			format = "csv"
+		}
+	}
+
+	var saldoAwal *money.Amount
+	if saldoStr := c.PostForm("saldo_awal"); saldoStr != "" {
+		saldo, err := money.Parse(saldoStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid saldo_awal"})
+			return
+		}
+		saldoAwal = &saldo
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	userID, _ := c.Get("user_id")
+
+	result, err := h.bankService.ImportRekeningKoran(id, format, fileHeader.Filename, file, saldoAwal, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Bank statement imported successfully",
+		"import":  result,
+	})
+}
+
+func (h *BankHandler) GetRekeningKoranList(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rekening bank ID"})
+		return
+	}
+
+	korans, err := h.bankService.GetRekeningKoranList(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"rekening_koran": korans,
+	})
+}
+
+func (h *BankHandler) GetMutasiList(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rekening bank ID"})
+		return
+	}
+
+	var dari, sampai time.Time
+	if dariStr := c.Query("dari"); dariStr != "" {
+		if dari, err = time.Parse("2006-01-02", dariStr); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dari date format"})
+			return
+		}
+	}
+	if sampaiStr := c.Query("sampai"); sampaiStr != "" {
+		if sampai, err = time.Parse("2006-01-02", sampaiStr); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sampai date format"})
+			return
+		}
+	}
+
+	lines, err := h.bankService.GetLines(id, c.Query("status"), dari, sampai)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"mutasi": lines,
+	})
+}
+
+func (h *BankHandler) AutoMatch(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rekening bank ID"})
+		return
+	}
+
+	var req struct {
+		ToleransiHari int `json:"toleransi_hari"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	matched, err := h.bankService.AutoMatch(id, req.ToleransiHari, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Auto match completed",
+		"matched": matched,
+	})
+}
+
+func (h *BankHandler) GetKandidatMatch(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mutasi ID"})
+		return
+	}
+
+	candidates, err := h.bankService.GetKandidatMatch(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"kandidat": candidates,
+	})
+}
+
+func (h *BankHandler) MatchMutasi(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mutasi ID"})
+		return
+	}
+
+	var req struct {
+		JurnalDetailID uint64 `json:"jurnal_detail_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	err = h.bankService.MatchLine(id, req.JurnalDetailID, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Mutasi matched successfully",
+	})
+}
+
+func (h *BankHandler) UnmatchMutasi(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mutasi ID"})
+		return
+	}
+
+	err = h.bankService.UnmatchLine(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Mutasi unmatched successfully",
+	})
+}
+
+func (h *BankHandler) BuatJurnal(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mutasi ID"})
+		return
+	}
+
+	var req services.BuatJurnalBankRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	jurnal, err := h.bankService.BuatJurnal(id, &req, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Jurnal created from bank statement line",
+		"jurnal":  jurnal,
+	})
+}
+
+func (h *BankHandler) GetRekonsiliasi(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rekening bank ID"})
+		return
+	}
+
+	tanggal, err := time.Parse("2006-01-02", c.Query("tanggal"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tanggal format"})
+		return
+	}
+
+	laporan, err := h.bankService.GetRekonsiliasi(id, tanggal)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"rekonsiliasi": laporan,
+	})
+}
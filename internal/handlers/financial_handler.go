@@ -1,20 +1,46 @@
 package handlers
 
 import (
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"koperasi-merah-putih/internal/money"
 	"koperasi-merah-putih/internal/services"
+	"koperasi-merah-putih/internal/utils"
 )
 
 type FinancialHandler struct {
 	financialService *services.FinancialService
+	postingService   *services.PostingService
+	periodeService   *services.PeriodeService
+	templateService  *services.JurnalTemplateService
+	coaService       *services.COAService
+	laporanService   *services.LaporanKeuanganService
 }
 
-func NewFinancialHandler(financialService *services.FinancialService) *FinancialHandler {
-	return &FinancialHandler{financialService: financialService}
+// maxCOAFileSize caps uploaded chart of accounts files.
+const maxCOAFileSize = 5 << 20
+
+func NewFinancialHandler(
+	financialService *services.FinancialService,
+	postingService *services.PostingService,
+	periodeService *services.PeriodeService,
+	templateService *services.JurnalTemplateService,
+	coaService *services.COAService,
+	laporanService *services.LaporanKeuanganService,
+) *FinancialHandler {
+	return &FinancialHandler{
+		financialService: financialService,
+		postingService:   postingService,
+		periodeService:   periodeService,
+		templateService:  templateService,
+		coaService:       coaService,
+		laporanService:   laporanService,
+	}
 }
 
 func (h *FinancialHandler) CreateCOAAkun(c *gin.Context) {
@@ -67,6 +93,125 @@ func (h *FinancialHandler) GetCOAKategoriList(c *gin.Context) {
 	})
 }
 
+func (h *FinancialHandler) ExportCOA(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "xlsx" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format. Supported: csv, xlsx"})
+		return
+	}
+
+	rows, err := h.coaService.ExportCOA(koperasiID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("coa-%d.%s", koperasiID, format)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+
+	if format == "xlsx" {
+		c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		err = h.coaService.WriteCOAXLSX(c.Writer, rows)
+	} else {
+		c.Header("Content-Type", "text/csv")
+		err = h.coaService.WriteCOACSV(c.Writer, rows)
+	}
+	if err != nil {
+		c.Error(err)
+	}
+}
+
+func (h *FinancialHandler) ImportCOA(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "COA file is required"})
+		return
+	}
+	if fileHeader.Size > maxCOAFileSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "COA file is too large"})
+		return
+	}
+
+	format := c.PostForm("format")
+	if format == "" {
+		format = utils.GetFileExtension(fileHeader.Filename)
+	}
+	if format != "csv" && format != "xlsx" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format. Supported: csv, xlsx"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	result, err := h.coaService.ImportCOA(koperasiID, format, file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Chart of accounts imported successfully",
+		"import":  result,
+	})
+}
+
+func (h *FinancialHandler) GetCOATemplateList(c *gin.Context) {
+	templates, err := h.coaService.GetTemplateList()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"templates": templates,
+	})
+}
+
+func (h *FinancialHandler) ApplyCOATemplate(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	var req services.ApplyCOATemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.coaService.ApplyTemplate(koperasiID, req.Template)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "COA template applied successfully",
+		"import":  result,
+	})
+}
+
 func (h *FinancialHandler) CreateJurnal(c *gin.Context) {
 	var req services.CreateJurnalRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -163,17 +308,77 @@ func (h *FinancialHandler) PostJurnal(c *gin.Context) {
 
 	userID, _ := c.Get("user_id")
 
-	err = h.financialService.PostJurnal(id, userID.(uint64))
+	peringatan, err := h.financialService.PostJurnal(id, userID.(uint64))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"message": "Jurnal posted successfully",
+	}
+	if len(peringatan) > 0 {
+		response["peringatan_anggaran"] = peringatan
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *FinancialHandler) SetWajibLampiran(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid jurnal ID"})
+		return
+	}
+
+	var req services.SetWajibLampiranRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.financialService.SetWajibLampiran(id, req.WajibLampiran)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Jurnal attachment requirement updated successfully",
 	})
 }
 
+// VerifikasiRantaiJurnal checks the koperasi's posted journals against
+// their hash chain and reports the first broken link. The anchor_urutan and
+// anchor_hash query parameters take a head recorded from an earlier run.
+func (h *FinancialHandler) VerifikasiRantaiJurnal(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	var anchor *services.AnchorRantaiJurnal
+	if urutanStr := c.Query("anchor_urutan"); urutanStr != "" {
+		urutan, err := strconv.ParseUint(urutanStr, 10, 64)
+		if err != nil || urutan == 0 || c.Query("anchor_hash") == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid anchor, expected anchor_urutan and anchor_hash"})
+			return
+		}
+		anchor = &services.AnchorRantaiJurnal{Urutan: urutan, Hash: c.Query("anchor_hash")}
+	}
+
+	hasil, err := h.financialService.VerifikasiRantaiJurnal(koperasiID, anchor)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"verifikasi": hasil})
+}
+
 func (h *FinancialHandler) CancelJurnal(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 64)
@@ -287,6 +492,58 @@ func (h *FinancialHandler) GetNeraca(c *gin.Context) {
 	})
 }
 
+// GetLaporanKeuangan returns the SAK EP statements for the year to date
+// ending at tanggal, with the prior year alongside, as JSON or rendered to
+// PDF or XLSX.
+func (h *FinancialHandler) GetLaporanKeuangan(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	tanggalStr := c.Query("tanggal")
+	tanggal, err := time.Parse("2006-01-02", tanggalStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tanggal format"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "pdf" && format != "xlsx" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format. Supported: json, pdf, xlsx"})
+		return
+	}
+
+	laporan, err := h.laporanService.GetLaporanKeuangan(koperasiID, tanggal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, gin.H{
+			"laporan": laporan,
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("laporan-keuangan-%d-%s.%s", koperasiID, tanggal.Format("20060102"), format)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+
+	if format == "pdf" {
+		c.Header("Content-Type", "application/pdf")
+		err = h.laporanService.WriteLaporanKeuanganPDF(c.Writer, laporan)
+	} else {
+		c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		err = h.laporanService.WriteLaporanKeuanganXLSX(c.Writer, laporan)
+	}
+	if err != nil {
+		c.Error(err)
+	}
+}
+
 func (h *FinancialHandler) GetSaldoAkun(c *gin.Context) {
 	akunIDStr := c.Param("akun_id")
 	akunID, err := strconv.ParseUint(akunIDStr, 10, 64)
@@ -313,4 +570,726 @@ func (h *FinancialHandler) GetSaldoAkun(c *gin.Context) {
 		"saldo":   saldo,
 		"tanggal": tanggal.Format("2006-01-02"),
 	})
-}
\ No newline at end of file
+}
+
+func (h *FinancialHandler) GetPostingEvents(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"events": h.postingService.GetPostingEvents(),
+	})
+}
+
+func (h *FinancialHandler) CreatePostingRule(c *gin.Context) {
+	var req services.CreatePostingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.postingService.CreatePostingRule(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "Posting rule created successfully",
+		"posting_rule": rule,
+	})
+}
+
+func (h *FinancialHandler) GetPostingRuleList(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	rules, err := h.postingService.GetPostingRuleList(koperasiID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"posting_rules": rules,
+	})
+}
+
+func (h *FinancialHandler) GetPostingRule(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid posting rule ID"})
+		return
+	}
+
+	rule, err := h.postingService.GetPostingRuleByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Posting rule not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"posting_rule": rule,
+	})
+}
+
+func (h *FinancialHandler) UpdatePostingRule(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid posting rule ID"})
+		return
+	}
+
+	var req services.UpdatePostingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.postingService.UpdatePostingRule(id, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Posting rule updated successfully",
+		"posting_rule": rule,
+	})
+}
+
+func (h *FinancialHandler) DeletePostingRule(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid posting rule ID"})
+		return
+	}
+
+	err = h.postingService.DeletePostingRule(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Posting rule deactivated successfully",
+	})
+}
+
+func (h *FinancialHandler) ReverseJurnal(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid jurnal ID"})
+		return
+	}
+
+	var req services.ReverseJurnalRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	reversal, err := h.financialService.ReverseJurnal(id, &req, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Jurnal reversed successfully",
+		"jurnal":  reversal,
+	})
+}
+
+func (h *FinancialHandler) GetJurnalBySumber(c *gin.Context) {
+	sumber := c.Param("sumber")
+	sumberID, err := strconv.ParseUint(c.Param("sumber_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sumber ID"})
+		return
+	}
+
+	jurnals, err := h.financialService.GetJurnalBySumber(sumber, sumberID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"jurnals": jurnals,
+	})
+}
+
+func (h *FinancialHandler) ReverseJurnalBySumber(c *gin.Context) {
+	sumber := c.Param("sumber")
+	sumberID, err := strconv.ParseUint(c.Param("sumber_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sumber ID"})
+		return
+	}
+
+	var req services.ReverseJurnalRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	reversal, err := h.financialService.ReverseJurnalBySumber(sumber, sumberID, &req, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Jurnal reversed successfully",
+		"jurnal":  reversal,
+	})
+}
+
+func (h *FinancialHandler) GeneratePeriode(c *gin.Context) {
+	var req services.GeneratePeriodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	periodes, err := h.periodeService.GeneratePeriode(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Periode generated successfully",
+		"periodes": periodes,
+	})
+}
+
+func (h *FinancialHandler) GetPeriodeList(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	tahun, err := strconv.Atoi(c.DefaultQuery("tahun", strconv.Itoa(time.Now().Year())))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tahun"})
+		return
+	}
+
+	periodes, err := h.periodeService.GetPeriodeList(koperasiID, tahun)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"periodes": periodes,
+		"tahun":    tahun,
+	})
+}
+
+func (h *FinancialHandler) ClosePeriode(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid periode ID"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	err = h.periodeService.ClosePeriode(id, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Periode closed successfully",
+	})
+}
+
+func (h *FinancialHandler) RequestReopenPeriode(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid periode ID"})
+		return
+	}
+
+	var req services.ReopenPeriodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	reopen, err := h.periodeService.RequestReopen(id, &req, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":        "Reopen request submitted successfully",
+		"reopen_request": reopen,
+	})
+}
+
+func (h *FinancialHandler) GetReopenRequestList(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	reopens, err := h.periodeService.GetReopenRequestList(koperasiID, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reopen_requests": reopens,
+	})
+}
+
+func (h *FinancialHandler) ReviewReopenPeriode(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reopen request ID"})
+		return
+	}
+
+	var req services.ReviewReopenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	reopen, err := h.periodeService.ReviewReopen(id, &req, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Reopen request reviewed successfully",
+		"reopen_request": reopen,
+	})
+}
+
+func (h *FinancialHandler) TutupBuku(c *gin.Context) {
+	var req services.TutupBukuRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	tutupBuku, err := h.periodeService.TutupBuku(&req, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Tutup buku completed successfully",
+		"tutup_buku": tutupBuku,
+	})
+}
+
+func (h *FinancialHandler) GetTutupBuku(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	tahun, err := strconv.Atoi(c.Param("tahun"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tahun"})
+		return
+	}
+
+	tutupBuku, err := h.periodeService.GetTutupBuku(koperasiID, tahun)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tutup buku not found"})
+		return
+	}
+
+	saldoAwal, err := h.periodeService.GetSaldoAwal(koperasiID, tahun+1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tutup_buku": tutupBuku,
+		"saldo_awal": saldoAwal,
+	})
+}
+
+func (h *FinancialHandler) GetArusKas(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	dari, err := time.Parse("2006-01-02", c.Query("dari"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dari date format"})
+		return
+	}
+
+	sampai, err := time.Parse("2006-01-02", c.Query("sampai"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sampai date format"})
+		return
+	}
+
+	arusKas, err := h.financialService.GetArusKas(koperasiID, dari, sampai, c.Query("metode"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"arus_kas": arusKas})
+}
+
+func (h *FinancialHandler) CreateArusKasMapping(c *gin.Context) {
+	var req services.CreateArusKasMappingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	mapping, err := h.financialService.CreateArusKasMapping(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Arus kas mapping created successfully",
+		"mapping": mapping,
+	})
+}
+
+func (h *FinancialHandler) GetArusKasMappingList(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	mappings, err := h.financialService.GetArusKasMappings(koperasiID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"mappings": mappings})
+}
+
+func (h *FinancialHandler) DeleteArusKasMapping(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mapping ID"})
+		return
+	}
+
+	err = h.financialService.DeleteArusKasMapping(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Arus kas mapping deleted successfully",
+	})
+}
+
+func (h *FinancialHandler) GetBukuBesar(c *gin.Context) {
+	req, ok := bukuBesarRequest(c)
+	if !ok {
+		return
+	}
+
+	bukuBesar, err := h.financialService.GetBukuBesar(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"buku_besar": bukuBesar,
+		"periode": gin.H{
+			"dari":   req.Dari.Format("2006-01-02"),
+			"sampai": req.Sampai.Format("2006-01-02"),
+		},
+	})
+}
+
+func (h *FinancialHandler) ExportBukuBesar(c *gin.Context) {
+	req, ok := bukuBesarRequest(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format. Supported: csv"})
+		return
+	}
+
+	bukuBesar, err := h.financialService.GetBukuBesar(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("buku-besar-%d-%s-%s.csv", req.KoperasiID,
+		req.Dari.Format("20060102"), req.Sampai.Format("20060102"))
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+
+	if err := h.financialService.WriteBukuBesarCSV(c.Writer, bukuBesar); err != nil {
+		c.Error(err)
+	}
+}
+
+func bukuBesarRequest(c *gin.Context) (*services.BukuBesarRequest, bool) {
+	koperasiID, err := strconv.ParseUint(c.Param("koperasi_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return nil, false
+	}
+
+	req := &services.BukuBesarRequest{
+		KoperasiID: koperasiID,
+		KodeDari:   c.Query("kode_dari"),
+		KodeSampai: c.Query("kode_sampai"),
+	}
+
+	if akunIDStr := c.Query("akun_id"); akunIDStr != "" {
+		req.AkunID, err = strconv.ParseUint(akunIDStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid akun ID"})
+			return nil, false
+		}
+	}
+
+	req.Dari, err = time.Parse("2006-01-02", c.Query("dari"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dari date format"})
+		return nil, false
+	}
+
+	req.Sampai, err = time.Parse("2006-01-02", c.Query("sampai"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sampai date format"})
+		return nil, false
+	}
+
+	return req, true
+}
+
+func (h *FinancialHandler) CreateJurnalTemplate(c *gin.Context) {
+	var req services.JurnalTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	template, err := h.templateService.CreateTemplate(&req, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Jurnal template created successfully",
+		"template": template,
+	})
+}
+
+func (h *FinancialHandler) GetJurnalTemplateList(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	templates, err := h.templateService.GetTemplateList(koperasiID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+func (h *FinancialHandler) GetJurnalTemplate(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+
+	template, err := h.templateService.GetTemplate(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Jurnal template not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"template": template})
+}
+
+func (h *FinancialHandler) UpdateJurnalTemplate(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+
+	var req services.JurnalTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := h.templateService.UpdateTemplate(id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Jurnal template updated successfully",
+		"template": template,
+	})
+}
+
+func (h *FinancialHandler) SetJurnalTemplateAktif(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+
+	var req struct {
+		IsAktif bool `json:"is_aktif"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.templateService.SetAktif(id, req.IsAktif)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Jurnal template status updated successfully"})
+}
+
+func (h *FinancialHandler) SetJurnalTemplateParameter(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+
+	var req struct {
+		Parameter map[string]money.Amount `json:"parameter" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.templateService.SetParameter(id, req.Parameter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Jurnal template parameters updated successfully"})
+}
+
+func (h *FinancialHandler) GenerateJurnalTemplate(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+
+	var req struct {
+		Parameter map[string]money.Amount `json:"parameter"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	jurnal, err := h.templateService.Generate(id, req.Parameter, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Jurnal generated successfully",
+		"jurnal":  jurnal,
+	})
+}
+
+func (h *FinancialHandler) GetJurnalTemplateRiwayat(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+
+	jurnals, err := h.templateService.GetRiwayat(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jurnals": jurnals})
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"koperasi-merah-putih/internal/services"
+)
+
+type HutangHandler struct {
+	hutangService *services.HutangService
+}
+
+func NewHutangHandler(hutangService *services.HutangService) *HutangHandler {
+	return &HutangHandler{hutangService: hutangService}
+}
+
+func (h *HutangHandler) BayarHutang(c *gin.Context) {
+	var req services.BayarHutangRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	pembayaran, err := h.hutangService.BayarHutang(&req, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Pembayaran hutang recorded successfully",
+		"pembayaran": pembayaran,
+	})
+}
+
+func (h *HutangHandler) GetPembayaran(c *gin.Context) {
+	pembayaran, err := h.hutangService.GetPembayaran(c.Param("nomor"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if len(pembayaran) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pembayaran not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pembayaran": pembayaran})
+}
+
+func (h *HutangHandler) GetKartuHutang(c *gin.Context) {
+	supplierIDStr := c.Param("supplier_id")
+	supplierID, err := strconv.ParseUint(supplierIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid supplier ID"})
+		return
+	}
+
+	kartu, err := h.hutangService.GetKartuHutang(supplierID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"kartu_hutang": kartu})
+}
+
+func (h *HutangHandler) GetUmurHutang(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	tanggal := time.Now()
+	if tanggalStr := c.Query("tanggal"); tanggalStr != "" {
+		tanggal, err = time.Parse("2006-01-02", tanggalStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tanggal format"})
+			return
+		}
+	}
+
+	laporan, err := h.hutangService.GetUmurHutang(koperasiID, tanggal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"umur_hutang": laporan})
+}
@@ -189,6 +189,28 @@ func (h *KlinikHandler) GetKunjungan(c *gin.Context) {
 	})
 }
 
+func (h *KlinikHandler) BayarKunjungan(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid kunjungan ID"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	kunjungan, err := h.klinikService.BayarKunjungan(id, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Kunjungan paid successfully",
+		"kunjungan": kunjungan,
+	})
+}
+
 func (h *KlinikHandler) GetKunjunganByPasien(c *gin.Context) {
 	pasienIDStr := c.Param("id")
 	pasienID, err := strconv.ParseUint(pasienIDStr, 10, 64)
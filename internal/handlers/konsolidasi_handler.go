@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"koperasi-merah-putih/internal/services"
+)
+
+type KonsolidasiHandler struct {
+	konsolidasiService *services.KonsolidasiService
+}
+
+func NewKonsolidasiHandler(konsolidasiService *services.KonsolidasiService) *KonsolidasiHandler {
+	return &KonsolidasiHandler{konsolidasiService: konsolidasiService}
+}
+
+func (h *KonsolidasiHandler) GetPosList(c *gin.Context) {
+	tenantID, _ := c.Get("tenant_id")
+
+	pos, err := h.konsolidasiService.GetPosList(tenantID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pos_konsolidasi": pos})
+}
+
+func (h *KonsolidasiHandler) CreatePos(c *gin.Context) {
+	var req services.PosKonsolidasiRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tenantID, _ := c.Get("tenant_id")
+
+	pos, err := h.konsolidasiService.CreatePos(tenantID.(uint64), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":         "Pos konsolidasi created successfully",
+		"pos_konsolidasi": pos,
+	})
+}
+
+func (h *KonsolidasiHandler) UpdatePos(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pos ID"})
+		return
+	}
+
+	var req services.PosKonsolidasiRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tenantID, _ := c.Get("tenant_id")
+
+	pos, err := h.konsolidasiService.UpdatePos(id, tenantID.(uint64), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Pos konsolidasi updated successfully",
+		"pos_konsolidasi": pos,
+	})
+}
+
+func (h *KonsolidasiHandler) DeletePos(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pos ID"})
+		return
+	}
+
+	tenantID, _ := c.Get("tenant_id")
+
+	if err := h.konsolidasiService.DeletePos(id, tenantID.(uint64)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pos konsolidasi deleted successfully"})
+}
+
+func (h *KonsolidasiHandler) GetPemetaan(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	tenantID, _ := c.Get("tenant_id")
+
+	pemetaan, err := h.konsolidasiService.GetPemetaan(tenantID.(uint64), koperasiID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pemetaan": pemetaan})
+}
+
+func (h *KonsolidasiHandler) SimpanPemetaan(c *gin.Context) {
+	var req services.PemetaanAkunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tenantID, _ := c.Get("tenant_id")
+
+	pemetaan, err := h.konsolidasiService.SimpanPemetaan(tenantID.(uint64), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Pemetaan akun saved successfully",
+		"pemetaan": pemetaan,
+	})
+}
+
+func (h *KonsolidasiHandler) GetNeracaSaldo(c *gin.Context) {
+	koperasiIDs, ok := parseKoperasiIDs(c)
+	if !ok {
+		return
+	}
+
+	tanggal, err := time.Parse("2006-01-02", c.DefaultQuery("tanggal", time.Now().Format("2006-01-02")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tanggal format"})
+		return
+	}
+
+	tenantID, _ := c.Get("tenant_id")
+
+	neracaSaldo, err := h.konsolidasiService.GetNeracaSaldo(tenantID.(uint64), koperasiIDs, tanggal)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"neraca_saldo": neracaSaldo})
+}
+
+func (h *KonsolidasiHandler) GetNeraca(c *gin.Context) {
+	koperasiIDs, ok := parseKoperasiIDs(c)
+	if !ok {
+		return
+	}
+
+	tanggal, err := time.Parse("2006-01-02", c.DefaultQuery("tanggal", time.Now().Format("2006-01-02")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tanggal format"})
+		return
+	}
+
+	tenantID, _ := c.Get("tenant_id")
+
+	neraca, err := h.konsolidasiService.GetNeraca(tenantID.(uint64), koperasiIDs, tanggal)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"neraca": neraca})
+}
+
+func (h *KonsolidasiHandler) GetLabaRugi(c *gin.Context) {
+	koperasiIDs, ok := parseKoperasiIDs(c)
+	if !ok {
+		return
+	}
+
+	dari, err := time.Parse("2006-01-02", c.Query("dari"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dari date format"})
+		return
+	}
+
+	sampai, err := time.Parse("2006-01-02", c.Query("sampai"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sampai date format"})
+		return
+	}
+
+	tenantID, _ := c.Get("tenant_id")
+
+	labaRugi, err := h.konsolidasiService.GetLabaRugi(tenantID.(uint64), koperasiIDs, dari, sampai)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"laba_rugi": labaRugi})
+}
+
+// parseKoperasiIDs reads the comma separated koperasi_ids query parameter.
+// An empty list means every koperasi of the tenant.
+func parseKoperasiIDs(c *gin.Context) ([]uint64, bool) {
+	var ids []uint64
+	for _, part := range strings.Split(c.Query("koperasi_ids"), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi_ids"})
+			return nil, false
+		}
+		ids = append(ids, id)
+	}
+	return ids, true
+}
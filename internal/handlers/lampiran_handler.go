@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"koperasi-merah-putih/internal/services"
+)
+
+type LampiranHandler struct {
+	lampiranService *services.LampiranService
+}
+
+func NewLampiranHandler(lampiranService *services.LampiranService) *LampiranHandler {
+	return &LampiranHandler{lampiranService: lampiranService}
+}
+
+func (h *LampiranHandler) UploadLampiran(c *gin.Context) {
+	dokumenIDStr := c.Param("dokumen_id")
+	dokumenID, err := strconv.ParseUint(dokumenIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dokumen ID"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Attachment file is required"})
+		return
+	}
+	if fileHeader.Size > h.lampiranService.MaxFileSize() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Attachment file is too large"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	userID, _ := c.Get("user_id")
+
+	req := &services.UploadLampiranRequest{
+		JenisDokumen: c.Param("jenis"),
+		DokumenID:    dokumenID,
+		NamaFile:     fileHeader.Filename,
+		Keterangan:   c.PostForm("keterangan"),
+	}
+
+	lampiran, err := h.lampiranService.UploadLampiran(req, file, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Lampiran uploaded successfully",
+		"lampiran": lampiran,
+	})
+}
+
+func (h *LampiranHandler) GetLampiranList(c *gin.Context) {
+	dokumenIDStr := c.Param("dokumen_id")
+	dokumenID, err := strconv.ParseUint(dokumenIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dokumen ID"})
+		return
+	}
+
+	lampirans, err := h.lampiranService.GetLampiranList(c.Param("jenis"), dokumenID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"lampiran": lampirans})
+}
+
+func (h *LampiranHandler) DownloadLampiran(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lampiran ID"})
+		return
+	}
+
+	lampiran, content, err := h.lampiranService.OpenLampiran(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	defer content.Close()
+
+	c.Header("Content-Type", lampiran.ContentType)
+	c.Header("Content-Length", strconv.FormatInt(lampiran.Ukuran, 10))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", lampiran.NamaFile))
+	c.Header("X-Content-Type-Options", "nosniff")
+
+	if _, err := io.Copy(c.Writer, content); err != nil {
+		c.Error(err)
+	}
+}
+
+func (h *LampiranHandler) DeleteLampiran(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lampiran ID"})
+		return
+	}
+
+	if err := h.lampiranService.DeleteLampiran(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Lampiran deleted successfully"})
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"koperasi-merah-putih/internal/services"
+)
+
+type PajakHandler struct {
+	pajakService *services.PajakService
+}
+
+func NewPajakHandler(pajakService *services.PajakService) *PajakHandler {
+	return &PajakHandler{pajakService: pajakService}
+}
+
+func (h *PajakHandler) GetPengaturan(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	pengaturan, err := h.pajakService.GetPengaturan(koperasiID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pengaturan": pengaturan})
+}
+
+func (h *PajakHandler) SimpanPengaturan(c *gin.Context) {
+	var req services.PengaturanPajakRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	pengaturan, err := h.pajakService.SimpanPengaturan(&req, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Pengaturan pajak saved successfully",
+		"pengaturan": pengaturan,
+	})
+}
+
+func (h *PajakHandler) GetRekap(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	rekap, err := h.pajakService.GetRekapPajak(koperasiID, c.Query("masa"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rekap": rekap})
+}
+
+func (h *PajakHandler) GetPotongan(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	potongan, err := h.pajakService.GetPotonganPajak(koperasiID, c.Query("masa"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"potongan": potongan})
+}
+
+func (h *PajakHandler) SetorPajak(c *gin.Context) {
+	var req services.SetorPajakRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	setoran, err := h.pajakService.SetorPajak(&req, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Setoran pajak recorded successfully",
+		"setoran": setoran,
+	})
+}
+
+func (h *PajakHandler) SetNomorFakturPajak(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid penjualan ID"})
+		return
+	}
+
+	var req services.NomorFakturPajakRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	penjualan, err := h.pajakService.SetNomorFakturPajak(id, &req, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Nomor faktur pajak saved successfully",
+		"penjualan": penjualan,
+	})
+}
+
+// ExportPajak downloads the monthly e-Faktur or e-Bupot import file.
+func (h *PajakHandler) ExportPajak(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	jenis := c.Query("jenis")
+	if jenis != services.ExportEFakturKeluaran && jenis != services.ExportEFakturMasukan && jenis != services.ExportEBupot {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid jenis. Supported: efaktur_keluaran, efaktur_masukan, ebupot"})
+		return
+	}
+
+	masa := c.Query("masa")
+	rows, err := h.pajakService.ExportPajak(koperasiID, masa, jenis)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%d-%s.csv", jenis, koperasiID, masa)
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+
+	if err := h.pajakService.WriteExportPajakCSV(c.Writer, rows); err != nil {
+		c.Error(err)
+	}
+}
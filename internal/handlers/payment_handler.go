@@ -100,6 +100,14 @@ func (h *PaymentHandler) processPaymentCallback(callbackData map[string]interfac
 	}
 
 	if transactionStatus == "settlement" || transactionStatus == "capture" || transactionStatus == "PAID" {
+		payment, err := h.paymentService.GetPaymentByNomor(transactionID)
+		if err != nil {
+			return err
+		}
+
+		if payment.TransactionType == "ppob" {
+			return h.ppobService.ProcessPayment(payment.ID)
+		}
 		return nil
 	}
 
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"koperasi-merah-putih/internal/services"
+)
+
+type PiutangHandler struct {
+	piutangService *services.PiutangService
+}
+
+func NewPiutangHandler(piutangService *services.PiutangService) *PiutangHandler {
+	return &PiutangHandler{piutangService: piutangService}
+}
+
+func (h *PiutangHandler) BayarPiutang(c *gin.Context) {
+	var req services.BayarPiutangRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	pembayaran, err := h.piutangService.BayarPiutang(&req, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Pembayaran piutang recorded successfully",
+		"pembayaran": pembayaran,
+	})
+}
+
+func (h *PiutangHandler) GetPembayaran(c *gin.Context) {
+	pembayaran, err := h.piutangService.GetPembayaran(c.Param("nomor"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if len(pembayaran) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pembayaran not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pembayaran": pembayaran})
+}
+
+func (h *PiutangHandler) GetKartuPiutang(c *gin.Context) {
+	anggotaIDStr := c.Param("anggota_id")
+	anggotaID, err := strconv.ParseUint(anggotaIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid anggota ID"})
+		return
+	}
+
+	kartu, err := h.piutangService.GetKartuPiutang(anggotaID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"kartu_piutang": kartu})
+}
+
+func (h *PiutangHandler) GetUmurPiutang(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	tanggal := time.Now()
+	if tanggalStr := c.Query("tanggal"); tanggalStr != "" {
+		tanggal, err = time.Parse("2006-01-02", tanggalStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tanggal format"})
+			return
+		}
+	}
+
+	laporan, err := h.piutangService.GetUmurPiutang(koperasiID, tanggal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"umur_piutang": laporan})
+}
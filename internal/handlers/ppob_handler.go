@@ -50,6 +50,25 @@ func (h *PPOBHandler) GetProdukByKategori(c *gin.Context) {
 	})
 }
 
+func (h *PPOBHandler) Inquiry(c *gin.Context) {
+	var req services.PPOBInquiryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	inquiry, err := h.ppobService.Inquiry(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"inquiry": inquiry,
+	})
+}
+
 func (h *PPOBHandler) CreateTransaction(c *gin.Context) {
 	var req services.PPOBTransactionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -57,6 +76,30 @@ func (h *PPOBHandler) CreateTransaction(c *gin.Context) {
 		return
 	}
 
+	if userID, exists := c.Get("user_id"); exists {
+		req.CreatedBy, _ = userID.(uint64)
+	}
+
+	// Only a super admin works across koperasi; everyone else orders for the
+	// koperasi they belong to, and a member only for themselves.
+	role, _ := c.Get("role")
+	if role != "super_admin" {
+		koperasiID, _ := c.Get("koperasi_id")
+		if id, _ := koperasiID.(uint64); id == 0 || id != req.KoperasiID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Cannot order for another koperasi"})
+			return
+		}
+	}
+	if role == "anggota" {
+		anggotaID, _ := c.Get("anggota_id")
+		id, _ := anggotaID.(uint64)
+		if id == 0 || (req.AnggotaID != 0 && req.AnggotaID != id) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Members can only order for themselves"})
+			return
+		}
+		req.PembeliAnggotaID = id
+	}
+
 	transaksi, err := h.ppobService.CreateTransaction(&req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -69,6 +112,143 @@ func (h *PPOBHandler) CreateTransaction(c *gin.Context) {
 	})
 }
 
+func (h *PPOBHandler) CekStatusTransaksi(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction ID"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	checkedBy, _ := userID.(uint64)
+
+	transaksi, err := h.ppobService.CekStatusTransaksi(id, checkedBy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Success",
+		"transaksi": transaksi,
+	})
+}
+
+func (h *PPOBHandler) GetSaldoProvider(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid provider ID"})
+		return
+	}
+
+	saldo, err := h.ppobService.GetSaldoProvider(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Success",
+		"provider_id": id,
+		"saldo":       saldo,
+	})
+}
+
+func (h *PPOBHandler) RefundTransaksi(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction ID"})
+		return
+	}
+
+	var req struct {
+		Alasan string `json:"alasan" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	refundedBy, _ := userID.(uint64)
+
+	transaksi, err := h.ppobService.RefundTransaksi(id, req.Alasan, refundedBy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Refund started successfully",
+		"transaksi": transaksi,
+	})
+}
+
+func (h *PPOBHandler) GetRefundQueue(c *gin.Context) {
+	koperasiID, err := strconv.ParseUint(c.Query("koperasi_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	refunds, err := h.ppobService.GetRefundQueue(koperasiID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"refunds": refunds,
+	})
+}
+
+func (h *PPOBHandler) RetryRefund(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction ID"})
+		return
+	}
+
+	transaksi, err := h.ppobService.RetryRefund(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Success",
+		"transaksi": transaksi,
+	})
+}
+
+func (h *PPOBHandler) SelesaikanRefund(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction ID"})
+		return
+	}
+
+	var req struct {
+		Referensi string `json:"referensi" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	transaksi, err := h.ppobService.SelesaikanRefundManual(id, req.Referensi)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Refund completed successfully",
+		"transaksi": transaksi,
+	})
+}
+
 func (h *PPOBHandler) CreateSettlement(c *gin.Context) {
 	var req struct {
 		KoperasiID uint64 `json:"koperasi_id" binding:"required"`
@@ -115,4 +295,48 @@ func (h *PPOBHandler) CreateSettlement(c *gin.Context) {
 		"message":    "Settlement created successfully",
 		"settlement": settlement,
 	})
+}
+
+func (h *PPOBHandler) ProcessSettlement(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid settlement ID"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	processedBy, _ := userID.(uint64)
+
+	settlement, err := h.ppobService.ProcessSettlement(id, processedBy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Settlement processed successfully",
+		"settlement": settlement,
+	})
+}
+
+func (h *PPOBHandler) PaySettlement(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid settlement ID"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	paidBy, _ := userID.(uint64)
+
+	settlement, err := h.ppobService.PaySettlement(id, paidBy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Settlement marked as paid",
+		"settlement": settlement,
+	})
 }
\ No newline at end of file
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"koperasi-merah-putih/internal/services"
+	"koperasi-merah-putih/internal/utils"
+)
+
+// maxSaldoAwalFileSize caps uploaded trial balance, rekening and faktur files.
+const maxSaldoAwalFileSize = 10 << 20
+
+type SaldoAwalHandler struct {
+	saldoAwalService *services.SaldoAwalService
+}
+
+func NewSaldoAwalHandler(saldoAwalService *services.SaldoAwalService) *SaldoAwalHandler {
+	return &SaldoAwalHandler{saldoAwalService: saldoAwalService}
+}
+
+func (h *SaldoAwalHandler) CreateMigrasi(c *gin.Context) {
+	var req services.CreateSaldoAwalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	migrasi, err := h.saldoAwalService.CreateMigrasi(&req, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Saldo awal created successfully",
+		"saldo_awal": migrasi,
+	})
+}
+
+func (h *SaldoAwalHandler) GetMigrasi(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	migrasi, err := h.saldoAwalService.GetMigrasi(koperasiID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Saldo awal not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"saldo_awal": migrasi})
+}
+
+func (h *SaldoAwalHandler) ImportNeraca(c *gin.Context) {
+	h.importFile(c, "Neraca saldo", h.saldoAwalService.ImportNeraca)
+}
+
+func (h *SaldoAwalHandler) ImportRekening(c *gin.Context) {
+	h.importFile(c, "Rekening", h.saldoAwalService.ImportRekening)
+}
+
+func (h *SaldoAwalHandler) ImportFaktur(c *gin.Context) {
+	h.importFile(c, "Faktur", h.saldoAwalService.ImportFaktur)
+}
+
+// importFile handles the CSV/XLSX uploads of the wizard, which differ only
+// in what the service does with the file.
+func (h *SaldoAwalHandler) importFile(c *gin.Context, label string,
+	importFn func(koperasiID uint64, format string, r io.Reader) (*services.ImportSaldoAwalResult, error)) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": label + " file is required"})
+		return
+	}
+	if fileHeader.Size > maxSaldoAwalFileSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": label + " file is too large"})
+		return
+	}
+
+	format := c.PostForm("format")
+	if format == "" {
+		format = utils.GetFileExtension(fileHeader.Filename)
+	}
+	if format != "csv" && format != "xlsx" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format. Supported: csv, xlsx"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	result, err := importFn(koperasiID, format, file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": label + " imported successfully",
+		"import":  result,
+	})
+}
+
+func (h *SaldoAwalHandler) GetRekonsiliasi(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	rekonsiliasi, err := h.saldoAwalService.Rekonsiliasi(koperasiID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rekonsiliasi": rekonsiliasi})
+}
+
+func (h *SaldoAwalHandler) PostMigrasi(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	migrasi, err := h.saldoAwalService.PostMigrasi(koperasiID, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Saldo awal posted successfully",
+		"saldo_awal": migrasi,
+	})
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"koperasi-merah-putih/internal/services"
+)
+
+type SHUHandler struct {
+	shuService *services.SHUService
+}
+
+func NewSHUHandler(shuService *services.SHUService) *SHUHandler {
+	return &SHUHandler{shuService: shuService}
+}
+
+func (h *SHUHandler) SaveConfig(c *gin.Context) {
+	var req services.SaveSHUConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	config, err := h.shuService.SaveConfig(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "SHU config saved successfully",
+		"config":  config,
+	})
+}
+
+func (h *SHUHandler) GetConfig(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	config, err := h.shuService.GetConfig(koperasiID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "SHU config not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"config": config,
+	})
+}
+
+func (h *SHUHandler) Hitung(c *gin.Context) {
+	var req services.HitungSHURequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	perhitungan, err := h.shuService.Hitung(&req, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "SHU calculated successfully",
+		"shu":     perhitungan,
+	})
+}
+
+func (h *SHUHandler) GetPerhitunganList(c *gin.Context) {
+	koperasiIDStr := c.Param("koperasi_id")
+	koperasiID, err := strconv.ParseUint(koperasiIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid koperasi ID"})
+		return
+	}
+
+	perhitungans, err := h.shuService.GetPerhitunganList(koperasiID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"shu": perhitungans,
+	})
+}
+
+func (h *SHUHandler) GetPerhitungan(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid SHU ID"})
+		return
+	}
+
+	perhitungan, err := h.shuService.GetPerhitungan(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "SHU not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"shu": perhitungan,
+	})
+}
+
+func (h *SHUHandler) Approve(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid SHU ID"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	err = h.shuService.Approve(id, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "SHU approved successfully",
+	})
+}
+
+func (h *SHUHandler) Bayar(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid SHU ID"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	perhitungan, err := h.shuService.Bayar(id, userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "SHU paid successfully",
+		"shu":     perhitungan,
+	})
+}
@@ -128,7 +128,10 @@ func (h *UserHandler) VerifyPayment(c *gin.Context) {
 		return
 	}
 
-	err = h.userService.VerifyPayment(paymentID)
+	userID, _ := c.Get("user_id")
+	verifiedBy, _ := userID.(uint64)
+
+	err = h.userService.VerifyPayment(paymentID, verifiedBy)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
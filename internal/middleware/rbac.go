@@ -108,6 +108,9 @@ func (r *RBACMiddleware) RequireKoperasiAccess() gin.HandlerFunc {
 		}
 
 		c.Set("koperasi_id", user.KoperasiID)
+		if user.AnggotaID != 0 {
+			c.Set("anggota_id", user.AnggotaID)
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"time"
+
+	"koperasi-merah-putih/internal/money"
+)
+
+// Anggaran is one version of a koperasi's RAPB (Rencana Anggaran Pendapatan
+// dan Belanja) for a year. Versi 1 is the plan approved by the RAT and later
+// versions are revisions. Only the approved version drives reporting and
+// budget control; approving a revision supersedes the previous one.
+type Anggaran struct {
+	ID              uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID        uint64     `gorm:"not null" json:"tenant_id"`
+	KoperasiID      uint64     `gorm:"not null;uniqueIndex:idx_anggaran_versi" json:"koperasi_id"`
+	Tahun           int        `gorm:"not null;uniqueIndex:idx_anggaran_versi" json:"tahun"`
+	Versi           int        `gorm:"not null;uniqueIndex:idx_anggaran_versi" json:"versi"`
+	Jenis           string     `gorm:"type:varchar(20);default:'awal'" json:"jenis"`
+	RevisiDariID    uint64     `json:"revisi_dari_id"`
+	Keterangan      string     `gorm:"type:text" json:"keterangan"`
+	KontrolAnggaran string     `gorm:"type:varchar(10);default:'none'" json:"kontrol_anggaran"`
+	Status          string     `gorm:"type:varchar(20);default:'draft';index" json:"status"`
+	CreatedBy       uint64     `json:"created_by"`
+	ApprovedBy      uint64     `json:"approved_by"`
+	ApprovedAt      *time.Time `json:"approved_at"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Koperasi Koperasi         `gorm:"foreignKey:KoperasiID" json:"koperasi,omitempty"`
+	Details  []AnggaranDetail `gorm:"foreignKey:AnggaranID" json:"details,omitempty"`
+}
+
+type AnggaranDetail struct {
+	ID         uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	AnggaranID uint64       `gorm:"not null;uniqueIndex:idx_anggaran_detail" json:"anggaran_id"`
+	AkunID     uint64       `gorm:"not null;uniqueIndex:idx_anggaran_detail" json:"akun_id"`
+	Bulan      int          `gorm:"not null;uniqueIndex:idx_anggaran_detail" json:"bulan"`
+	Jumlah     money.Amount `gorm:"type:decimal(15,2);default:0" json:"jumlah"`
+
+	Akun COAAkun `gorm:"foreignKey:AkunID" json:"akun,omitempty"`
+}
@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"time"
+
+	"koperasi-merah-putih/internal/money"
+)
+
+// KategoriAset groups fixed assets that share a useful life, depreciation
+// method and ledger accounts, e.g. kendaraan or bangunan.
+type KategoriAset struct {
+	ID                  uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID            uint64    `gorm:"not null" json:"tenant_id"`
+	KoperasiID          uint64    `gorm:"not null;uniqueIndex:idx_kategori_aset_kode" json:"koperasi_id"`
+	Kode                string    `gorm:"size:20;not null;uniqueIndex:idx_kategori_aset_kode" json:"kode"`
+	Nama                string    `gorm:"size:100;not null" json:"nama"`
+	UmurEkonomis        int       `gorm:"not null" json:"umur_ekonomis"`
+	MetodePenyusutan    string    `gorm:"type:varchar(20);default:'garis_lurus'" json:"metode_penyusutan"`
+	AkunAsetID          uint64    `gorm:"not null" json:"akun_aset_id"`
+	AkunAkumulasiID     uint64    `gorm:"not null" json:"akun_akumulasi_id"`
+	AkunBebanID         uint64    `gorm:"not null" json:"akun_beban_id"`
+	AkunLabaRugiLepasID uint64    `gorm:"not null" json:"akun_laba_rugi_lepas_id"`
+	IsAktif             bool      `gorm:"default:true" json:"is_aktif"`
+	CreatedAt           time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt           time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Koperasi          Koperasi `gorm:"foreignKey:KoperasiID" json:"koperasi,omitempty"`
+	AkunAset          COAAkun  `gorm:"foreignKey:AkunAsetID" json:"akun_aset,omitempty"`
+	AkunAkumulasi     COAAkun  `gorm:"foreignKey:AkunAkumulasiID" json:"akun_akumulasi,omitempty"`
+	AkunBeban         COAAkun  `gorm:"foreignKey:AkunBebanID" json:"akun_beban,omitempty"`
+	AkunLabaRugiLepas COAAkun  `gorm:"foreignKey:AkunLabaRugiLepasID" json:"akun_laba_rugi_lepas,omitempty"`
+}
+
+// AsetTetap is one fixed asset. UmurEkonomis (months) and MetodePenyusutan
+// are copied from the category at acquisition. AkumulasiAwal carries the
+// depreciation of an asset brought in from before the system was used;
+// AkumulasiPenyusutan includes it. PenyusutanSampai is the last month end
+// depreciated.
+type AsetTetap struct {
+	ID                  uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID            uint64       `gorm:"not null" json:"tenant_id"`
+	KoperasiID          uint64       `gorm:"not null;index" json:"koperasi_id"`
+	KategoriAsetID      uint64       `gorm:"not null;index" json:"kategori_aset_id"`
+	NomorAset           string       `gorm:"size:50;not null;uniqueIndex" json:"nomor_aset"`
+	Nama                string       `gorm:"size:255;not null" json:"nama"`
+	Lokasi              string       `gorm:"size:255" json:"lokasi"`
+	TanggalPerolehan    time.Time    `gorm:"not null" json:"tanggal_perolehan"`
+	HargaPerolehan      money.Amount `gorm:"type:decimal(15,2);not null" json:"harga_perolehan"`
+	NilaiResidu         money.Amount `gorm:"type:decimal(15,2);default:0" json:"nilai_residu"`
+	UmurEkonomis        int          `gorm:"not null" json:"umur_ekonomis"`
+	MetodePenyusutan    string       `gorm:"type:varchar(20);not null" json:"metode_penyusutan"`
+	PembelianHeaderID   uint64       `json:"pembelian_header_id"`
+	AkumulasiAwal       money.Amount `gorm:"type:decimal(15,2);default:0" json:"akumulasi_awal"`
+	AkumulasiPenyusutan money.Amount `gorm:"type:decimal(15,2);default:0" json:"akumulasi_penyusutan"`
+	NilaiBuku           money.Amount `gorm:"type:decimal(15,2);default:0" json:"nilai_buku"`
+	PenyusutanSampai    *time.Time   `json:"penyusutan_sampai"`
+	Status              string       `gorm:"type:varchar(20);default:'aktif';index" json:"status"`
+	JurnalPerolehanID   uint64       `json:"jurnal_perolehan_id"`
+	TanggalPelepasan    *time.Time   `json:"tanggal_pelepasan"`
+	HargaPelepasan      money.Amount `gorm:"type:decimal(15,2);default:0" json:"harga_pelepasan"`
+	JurnalPelepasanID   uint64       `json:"jurnal_pelepasan_id"`
+	Keterangan          string       `gorm:"type:text" json:"keterangan"`
+	CreatedBy           uint64       `json:"created_by"`
+	CreatedAt           time.Time    `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt           time.Time    `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Koperasi Koperasi     `gorm:"foreignKey:KoperasiID" json:"koperasi,omitempty"`
+	Kategori KategoriAset `gorm:"foreignKey:KategoriAsetID" json:"kategori,omitempty"`
+}
+
+// PenyusutanAset is the depreciation charged on an asset for one month,
+// dated at the month end.
+type PenyusutanAset struct {
+	ID          uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	AsetTetapID uint64       `gorm:"not null;uniqueIndex:idx_penyusutan_periode" json:"aset_tetap_id"`
+	KoperasiID  uint64       `gorm:"not null;index" json:"koperasi_id"`
+	Periode     time.Time    `gorm:"type:date;not null;uniqueIndex:idx_penyusutan_periode" json:"periode"`
+	Jumlah      money.Amount `gorm:"type:decimal(15,2);not null" json:"jumlah"`
+	Akumulasi   money.Amount `gorm:"type:decimal(15,2);not null" json:"akumulasi"`
+	NilaiBuku   money.Amount `gorm:"type:decimal(15,2);not null" json:"nilai_buku"`
+	JurnalID    uint64       `json:"jurnal_id"`
+	CreatedAt   time.Time    `gorm:"autoCreateTime" json:"created_at"`
+}
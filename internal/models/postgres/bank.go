@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"time"
+
+	"koperasi-merah-putih/internal/money"
+)
+
+// RekeningBank links a bank account to the IsKas COA akun that records it in
+// the books. AkunBiayaID is the default beban account for bank charges
+// journaled from the statement.
+type RekeningBank struct {
+	ID            uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID      uint64    `gorm:"not null" json:"tenant_id"`
+	KoperasiID    uint64    `gorm:"not null;index" json:"koperasi_id"`
+	AkunID        uint64    `gorm:"not null;uniqueIndex" json:"akun_id"`
+	NamaBank      string    `gorm:"size:100;not null" json:"nama_bank"`
+	NomorRekening string    `gorm:"size:50;not null" json:"nomor_rekening"`
+	AtasNama      string    `gorm:"size:255" json:"atas_nama"`
+	AkunBiayaID   uint64    `gorm:"default:0" json:"akun_biaya_id"`
+	IsAktif       bool      `gorm:"default:true" json:"is_aktif"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Koperasi Koperasi `gorm:"foreignKey:KoperasiID" json:"koperasi,omitempty"`
+	Akun     COAAkun  `gorm:"foreignKey:AkunID" json:"akun,omitempty"`
+}
+
+// RekeningKoran is one imported bank statement file.
+type RekeningKoran struct {
+	ID             uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID       uint64       `gorm:"not null" json:"tenant_id"`
+	KoperasiID     uint64       `gorm:"not null;index" json:"koperasi_id"`
+	RekeningBankID uint64       `gorm:"not null;index" json:"rekening_bank_id"`
+	Format         string       `gorm:"type:varchar(10);not null" json:"format"`
+	NamaFile       string       `gorm:"size:255" json:"nama_file"`
+	TanggalAwal    time.Time    `json:"tanggal_awal"`
+	TanggalAkhir   time.Time    `json:"tanggal_akhir"`
+	SaldoAwal      money.Amount `gorm:"type:decimal(15,2);default:0" json:"saldo_awal"`
+	SaldoAkhir     money.Amount `gorm:"type:decimal(15,2);default:0" json:"saldo_akhir"`
+	JumlahBaris    int          `gorm:"default:0" json:"jumlah_baris"`
+	ImportedBy     uint64       `json:"imported_by"`
+	CreatedAt      time.Time    `gorm:"autoCreateTime" json:"created_at"`
+
+	RekeningBank RekeningBank        `gorm:"foreignKey:RekeningBankID" json:"rekening_bank,omitempty"`
+	Lines        []RekeningKoranLine `gorm:"foreignKey:RekeningKoranID" json:"lines,omitempty"`
+}
+
+// RekeningKoranLine is one bank statement line. Jumlah is signed from the
+// koperasi's side: positive is money into the account. A matched line points
+// at the JurnalDetail on the bank akun that records the same movement.
+type RekeningKoranLine struct {
+	ID              uint64        `gorm:"primaryKey;autoIncrement" json:"id"`
+	RekeningKoranID uint64        `gorm:"not null;index" json:"rekening_koran_id"`
+	RekeningBankID  uint64        `gorm:"not null;index" json:"rekening_bank_id"`
+	Tanggal         time.Time     `gorm:"not null;index" json:"tanggal"`
+	Keterangan      string        `gorm:"type:text" json:"keterangan"`
+	Referensi       string        `gorm:"size:100" json:"referensi"`
+	Jumlah          money.Amount  `gorm:"type:decimal(15,2);not null" json:"jumlah"`
+	Saldo           *money.Amount `gorm:"type:decimal(15,2)" json:"saldo"`
+	Status          string        `gorm:"type:varchar(20);default:'unmatched';index" json:"status"`
+	JurnalDetailID  uint64        `gorm:"default:0;index" json:"jurnal_detail_id"`
+	JurnalID        uint64        `gorm:"default:0" json:"jurnal_id"`
+	MetodeMatch     string        `gorm:"type:varchar(10)" json:"metode_match"`
+	MatchedBy       uint64        `json:"matched_by"`
+	MatchedAt       *time.Time    `json:"matched_at"`
+}
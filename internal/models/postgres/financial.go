@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+
+	"koperasi-merah-putih/internal/money"
 )
 
 type COAKategori struct {
@@ -45,7 +47,7 @@ type ModalKoperasi struct {
 	ID                uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
 	KoperasiID        uint64     `gorm:"not null;index" json:"koperasi_id"`
 	JenisModal        string     `gorm:"type:varchar(30);not null" json:"jenis_modal"`
-	Jumlah            float64    `gorm:"type:decimal(15,2);default:0" json:"jumlah"`
+	Jumlah            money.Amount `gorm:"type:decimal(15,2);default:0" json:"jumlah"`
 	Keterangan        string     `gorm:"type:text" json:"keterangan"`
 	TanggalPencatatan *time.Time `json:"tanggal_pencatatan"`
 	CreatedAt         time.Time  `gorm:"autoCreateTime" json:"created_at"`
@@ -54,20 +56,27 @@ type ModalKoperasi struct {
 }
 
 type JurnalUmum struct {
-	ID               uint64      `gorm:"primaryKey;autoIncrement" json:"id"`
-	TenantID         uint64      `gorm:"not null" json:"tenant_id"`
-	KoperasiID       uint64      `gorm:"not null" json:"koperasi_id"`
-	NomorJurnal      string      `gorm:"size:50;not null" json:"nomor_jurnal"`
-	TanggalTransaksi time.Time   `gorm:"not null;index" json:"tanggal_transaksi"`
-	Referensi        string      `gorm:"size:100" json:"referensi"`
-	Keterangan       string      `gorm:"type:text" json:"keterangan"`
-	TotalDebit       float64     `gorm:"type:decimal(15,2);default:0" json:"total_debit"`
-	TotalKredit      float64     `gorm:"type:decimal(15,2);default:0" json:"total_kredit"`
-	Status           string      `gorm:"type:varchar(20);default:'draft';index" json:"status"`
-	CreatedAt        time.Time   `gorm:"autoCreateTime" json:"created_at"`
-	CreatedBy        uint64      `json:"created_by"`
-	PostedAt         *time.Time  `json:"posted_at"`
-	PostedBy         uint64      `json:"posted_by"`
+	ID               uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID         uint64       `gorm:"not null" json:"tenant_id"`
+	KoperasiID       uint64       `gorm:"not null" json:"koperasi_id"`
+	NomorJurnal      string       `gorm:"size:50;not null" json:"nomor_jurnal"`
+	TanggalTransaksi time.Time    `gorm:"not null;index" json:"tanggal_transaksi"`
+	Referensi        string       `gorm:"size:100" json:"referensi"`
+	Keterangan       string       `gorm:"type:text" json:"keterangan"`
+	TotalDebit       money.Amount `gorm:"type:decimal(15,2);default:0" json:"total_debit"`
+	TotalKredit      money.Amount `gorm:"type:decimal(15,2);default:0" json:"total_kredit"`
+	Status           string       `gorm:"type:varchar(20);default:'draft';index" json:"status"`
+	CreatedAt        time.Time    `gorm:"autoCreateTime" json:"created_at"`
+	CreatedBy        uint64       `json:"created_by"`
+	PostedAt         *time.Time   `json:"posted_at"`
+	PostedBy         uint64       `json:"posted_by"`
+	SumberTransaksi  string       `gorm:"type:varchar(50);default:'manual';index" json:"sumber_transaksi"`
+	SumberID         uint64       `gorm:"index" json:"sumber_id"`
+	AnggotaID        uint64       `gorm:"index" json:"anggota_id"`
+	ReversalOfID     uint64       `gorm:"index" json:"reversal_of_id"`
+	ReversedByID     uint64       `json:"reversed_by_id"`
+	ReversedAt       *time.Time   `json:"reversed_at"`
+	WajibLampiran    bool         `gorm:"default:false" json:"wajib_lampiran"`
 
 	Tenant       Tenant         `gorm:"foreignKey:TenantID" json:"tenant,omitempty"`
 	Koperasi     Koperasi       `gorm:"foreignKey:KoperasiID" json:"koperasi,omitempty"`
@@ -84,9 +93,161 @@ type JurnalDetail struct {
 	JurnalID   uint64  `gorm:"not null;index" json:"jurnal_id"`
 	AkunID     uint64  `gorm:"not null;index" json:"akun_id"`
 	Keterangan string  `gorm:"size:255" json:"keterangan"`
-	Debit      float64 `gorm:"type:decimal(15,2);default:0" json:"debit"`
-	Kredit     float64 `gorm:"type:decimal(15,2);default:0" json:"kredit"`
+	Debit      money.Amount `gorm:"type:decimal(15,2);default:0" json:"debit"`
+	Kredit     money.Amount `gorm:"type:decimal(15,2);default:0" json:"kredit"`
 
 	Jurnal JurnalUmum `gorm:"foreignKey:JurnalID" json:"jurnal,omitempty"`
 	Akun   COAAkun    `gorm:"foreignKey:AkunID" json:"akun,omitempty"`
-}
\ No newline at end of file
+}
+
+type PostingRule struct {
+	ID                   uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID             uint64    `gorm:"not null" json:"tenant_id"`
+	KoperasiID           uint64    `gorm:"not null;index" json:"koperasi_id"`
+	KodeEvent            string    `gorm:"type:varchar(50);not null;index" json:"kode_event"`
+	ProdukSimpanPinjamID uint64    `gorm:"default:0" json:"produk_simpan_pinjam_id"`
+	Nama                 string    `gorm:"size:255;not null" json:"nama"`
+	IsAktif              bool      `gorm:"default:true" json:"is_aktif"`
+	CreatedAt            time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt            time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Koperasi Koperasi          `gorm:"foreignKey:KoperasiID" json:"koperasi,omitempty"`
+	Lines    []PostingRuleLine `gorm:"foreignKey:PostingRuleID" json:"lines,omitempty"`
+}
+
+type PostingRuleLine struct {
+	ID            uint64 `gorm:"primaryKey;autoIncrement" json:"id"`
+	PostingRuleID uint64 `gorm:"not null;index" json:"posting_rule_id"`
+	AkunID        uint64 `gorm:"not null" json:"akun_id"`
+	Posisi        string `gorm:"type:varchar(10);not null" json:"posisi"`
+	Komponen      string `gorm:"type:varchar(30);not null" json:"komponen"`
+	Keterangan    string `gorm:"size:255" json:"keterangan"`
+	Urutan        int    `gorm:"default:0" json:"urutan"`
+
+	Akun COAAkun `gorm:"foreignKey:AkunID" json:"akun,omitempty"`
+}
+
+type PeriodeAkuntansi struct {
+	ID             uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID       uint64     `gorm:"not null" json:"tenant_id"`
+	KoperasiID     uint64     `gorm:"not null;uniqueIndex:idx_periode_koperasi_bulan" json:"koperasi_id"`
+	Tahun          int        `gorm:"not null;uniqueIndex:idx_periode_koperasi_bulan" json:"tahun"`
+	Bulan          int        `gorm:"not null;uniqueIndex:idx_periode_koperasi_bulan" json:"bulan"`
+	TanggalMulai   time.Time  `gorm:"type:date;not null" json:"tanggal_mulai"`
+	TanggalSelesai time.Time  `gorm:"type:date;not null" json:"tanggal_selesai"`
+	Status         string     `gorm:"type:varchar(10);default:'open';index" json:"status"`
+	ClosedAt       *time.Time `json:"closed_at"`
+	ClosedBy       uint64     `json:"closed_by"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Koperasi Koperasi `gorm:"foreignKey:KoperasiID" json:"koperasi,omitempty"`
+}
+
+type PeriodeReopenRequest struct {
+	ID            uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	PeriodeID     uint64     `gorm:"not null;index" json:"periode_id"`
+	Alasan        string     `gorm:"type:text;not null" json:"alasan"`
+	Status        string     `gorm:"type:varchar(10);default:'pending';index" json:"status"`
+	RequestedBy   uint64     `gorm:"not null" json:"requested_by"`
+	RequestedAt   time.Time  `gorm:"autoCreateTime" json:"requested_at"`
+	ReviewedBy    uint64     `json:"reviewed_by"`
+	ReviewedAt    *time.Time `json:"reviewed_at"`
+	CatatanReview string     `gorm:"type:text" json:"catatan_review"`
+
+	Periode PeriodeAkuntansi `gorm:"foreignKey:PeriodeID" json:"periode,omitempty"`
+}
+
+type TutupBuku struct {
+	ID               uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID         uint64    `gorm:"not null" json:"tenant_id"`
+	KoperasiID       uint64    `gorm:"not null;uniqueIndex:idx_tutup_buku_koperasi_tahun" json:"koperasi_id"`
+	Tahun            int       `gorm:"not null;uniqueIndex:idx_tutup_buku_koperasi_tahun" json:"tahun"`
+	AkunSHUID        uint64    `gorm:"not null" json:"akun_shu_id"`
+	TotalPendapatan  money.Amount `gorm:"type:decimal(15,2);default:0" json:"total_pendapatan"`
+	TotalBeban       money.Amount `gorm:"type:decimal(15,2);default:0" json:"total_beban"`
+	SHUTahunBerjalan money.Amount `gorm:"type:decimal(15,2);default:0" json:"shu_tahun_berjalan"`
+	JurnalID         uint64    `json:"jurnal_id"`
+	ClosedBy         uint64    `json:"closed_by"`
+	CreatedAt        time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	Koperasi Koperasi `gorm:"foreignKey:KoperasiID" json:"koperasi,omitempty"`
+	AkunSHU  COAAkun  `gorm:"foreignKey:AkunSHUID" json:"akun_shu,omitempty"`
+}
+
+// SaldoAwalAkun holds the balance-sheet balances carried forward into a
+// fiscal year by the year-end close, in the account's normal balance.
+type SaldoAwalAkun struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	KoperasiID  uint64    `gorm:"not null;uniqueIndex:idx_saldo_awal_koperasi_akun_tahun" json:"koperasi_id"`
+	AkunID      uint64    `gorm:"not null;uniqueIndex:idx_saldo_awal_koperasi_akun_tahun" json:"akun_id"`
+	Tahun       int       `gorm:"not null;uniqueIndex:idx_saldo_awal_koperasi_akun_tahun" json:"tahun"`
+	Saldo       money.Amount `gorm:"type:decimal(15,2);default:0" json:"saldo"`
+	TutupBukuID uint64    `gorm:"index" json:"tutup_buku_id"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	Akun COAAkun `gorm:"foreignKey:AkunID" json:"akun,omitempty"`
+}
+
+// ArusKasMapping classifies an akun, or every akun of a kategori, into a cash
+// flow activity: operasi, investasi or pendanaan. Akun mappings win over
+// kategori mappings.
+type ArusKasMapping struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	KoperasiID uint64    `gorm:"not null;index" json:"koperasi_id"`
+	KategoriID uint64    `gorm:"default:0" json:"kategori_id"`
+	AkunID     uint64    `gorm:"default:0" json:"akun_id"`
+	Aktivitas  string    `gorm:"type:varchar(20);not null" json:"aktivitas"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// JurnalTemplate generates a journal on a monthly or quarterly schedule.
+// Lines carry a fixed Jumlah or name a template parameter whose current value
+// is used at generation time. BerikutnyaPada is the date of the next journal.
+type JurnalTemplate struct {
+	ID             uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID       uint64     `gorm:"not null" json:"tenant_id"`
+	KoperasiID     uint64     `gorm:"not null;index" json:"koperasi_id"`
+	Nama           string     `gorm:"size:255;not null" json:"nama"`
+	Referensi      string     `gorm:"size:100" json:"referensi"`
+	Keterangan     string     `gorm:"type:text" json:"keterangan"`
+	Frekuensi      string     `gorm:"type:varchar(20);not null" json:"frekuensi"`
+	HariTanggal    int        `gorm:"not null" json:"hari_tanggal"`
+	TanggalMulai   time.Time  `gorm:"not null" json:"tanggal_mulai"`
+	TanggalSelesai *time.Time `json:"tanggal_selesai"`
+	BerikutnyaPada time.Time  `gorm:"not null;index" json:"berikutnya_pada"`
+	AutoPost       bool       `gorm:"default:false" json:"auto_post"`
+	IsAktif        bool       `gorm:"default:true;index" json:"is_aktif"`
+	TerakhirJalan  *time.Time `json:"terakhir_jalan"`
+	TerakhirError  string     `gorm:"type:text" json:"terakhir_error"`
+	GagalBeruntun  int        `gorm:"default:0" json:"gagal_beruntun"`
+	CobaLagiPada   *time.Time `json:"coba_lagi_pada"`
+	CreatedBy      uint64     `json:"created_by"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Koperasi  Koperasi                  `gorm:"foreignKey:KoperasiID" json:"koperasi,omitempty"`
+	Lines     []JurnalTemplateLine      `gorm:"foreignKey:JurnalTemplateID" json:"lines,omitempty"`
+	Parameter []JurnalTemplateParameter `gorm:"foreignKey:JurnalTemplateID" json:"parameter,omitempty"`
+}
+
+type JurnalTemplateLine struct {
+	ID               uint64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	JurnalTemplateID uint64  `gorm:"not null;index" json:"jurnal_template_id"`
+	AkunID           uint64  `gorm:"not null" json:"akun_id"`
+	Posisi           string  `gorm:"type:varchar(10);not null" json:"posisi"`
+	Jumlah           money.Amount `gorm:"type:decimal(15,2);default:0" json:"jumlah"`
+	Parameter        string  `gorm:"size:50" json:"parameter"`
+	Keterangan       string  `gorm:"size:255" json:"keterangan"`
+	Urutan           int     `gorm:"default:0" json:"urutan"`
+
+	Akun COAAkun `gorm:"foreignKey:AkunID" json:"akun,omitempty"`
+}
+
+type JurnalTemplateParameter struct {
+	ID               uint64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	JurnalTemplateID uint64  `gorm:"not null;uniqueIndex:idx_template_parameter" json:"jurnal_template_id"`
+	Nama             string  `gorm:"size:50;not null;uniqueIndex:idx_template_parameter" json:"nama"`
+	Nilai            money.Amount `gorm:"type:decimal(15,2);default:0" json:"nilai"`
+	Keterangan       string  `gorm:"size:255" json:"keterangan"`
+}
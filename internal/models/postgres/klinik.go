@@ -2,6 +2,8 @@ package postgres
 
 import (
 	"time"
+
+	"koperasi-merah-putih/internal/money"
 )
 
 type KlinikPasien struct {
@@ -40,7 +42,7 @@ type KlinikTenagaMedis struct {
 	Telepon           string    `gorm:"size:20" json:"telepon"`
 	Email             string    `gorm:"size:100" json:"email"`
 	JadwalPraktik     string    `gorm:"type:json" json:"jadwal_praktik"`
-	TarifKonsultasi   float64   `gorm:"type:decimal(15,2);default:0" json:"tarif_konsultasi"`
+	TarifKonsultasi money.Amount `gorm:"type:decimal(15,2);default:0" json:"tarif_konsultasi"`
 	Status            string    `gorm:"type:varchar(20);default:'aktif'" json:"status"`
 	CreatedAt         time.Time `gorm:"autoCreateTime" json:"created_at"`
 
@@ -60,10 +62,10 @@ type KlinikKunjungan struct {
 	PemeriksaanFisik  string    `gorm:"type:text" json:"pemeriksaan_fisik"`
 	Diagnosis         string    `gorm:"type:text" json:"diagnosis"`
 	TerapiPengobatan  string    `gorm:"type:text" json:"terapi_pengobatan"`
-	BiayaKonsultasi   float64   `gorm:"type:decimal(15,2);default:0" json:"biaya_konsultasi"`
-	BiayaTindakan     float64   `gorm:"type:decimal(15,2);default:0" json:"biaya_tindakan"`
-	BiayaObat         float64   `gorm:"type:decimal(15,2);default:0" json:"biaya_obat"`
-	TotalBiaya        float64   `gorm:"type:decimal(15,2);default:0" json:"total_biaya"`
+	BiayaKonsultasi  money.Amount `gorm:"type:decimal(15,2);default:0" json:"biaya_konsultasi"`
+	BiayaTindakan    money.Amount `gorm:"type:decimal(15,2);default:0" json:"biaya_tindakan"`
+	BiayaObat        money.Amount `gorm:"type:decimal(15,2);default:0" json:"biaya_obat"`
+	TotalBiaya       money.Amount `gorm:"type:decimal(15,2);default:0" json:"total_biaya"`
 	StatusPembayaran  string    `gorm:"type:varchar(20);default:'belum_bayar'" json:"status_pembayaran"`
 	JurnalID          uint64    `json:"jurnal_id"`
 	CreatedAt         time.Time `gorm:"autoCreateTime" json:"created_at"`
@@ -86,8 +88,8 @@ type KlinikObat struct {
 	Satuan          string    `gorm:"size:20" json:"satuan"`
 	StokMinimal     int       `gorm:"default:0" json:"stok_minimal"`
 	StokCurrent     int       `gorm:"default:0" json:"stok_current"`
-	HargaBeli       float64   `gorm:"type:decimal(15,2);default:0" json:"harga_beli"`
-	HargaJual       float64   `gorm:"type:decimal(15,2);default:0" json:"harga_jual"`
+	HargaBeli     money.Amount `gorm:"type:decimal(15,2);default:0" json:"harga_beli"`
+	HargaJual     money.Amount `gorm:"type:decimal(15,2);default:0" json:"harga_jual"`
 	IsAktif         bool      `gorm:"default:true" json:"is_aktif"`
 	CreatedAt       time.Time `gorm:"autoCreateTime" json:"created_at"`
 
@@ -102,8 +104,8 @@ type KlinikResep struct {
 	Jumlah       int     `gorm:"not null" json:"jumlah"`
 	AturanPakai  string  `gorm:"size:255" json:"aturan_pakai"`
 	Keterangan   string  `gorm:"size:255" json:"keterangan"`
-	HargaSatuan  float64 `gorm:"type:decimal(15,2);default:0" json:"harga_satuan"`
-	TotalHarga   float64 `gorm:"type:decimal(15,2);default:0" json:"total_harga"`
+	HargaSatuan money.Amount `gorm:"type:decimal(15,2);default:0" json:"harga_satuan"`
+	TotalHarga  money.Amount `gorm:"type:decimal(15,2);default:0" json:"total_harga"`
 
 	Kunjungan KlinikKunjungan `gorm:"foreignKey:KunjunganID" json:"kunjungan,omitempty"`
 	Obat      KlinikObat      `gorm:"foreignKey:ObatID" json:"obat,omitempty"`
@@ -0,0 +1,34 @@
+package postgres
+
+import "time"
+
+// PosKonsolidasi is a line of the common reporting structure a tenant
+// consolidates its koperasi into. Akun whose kode equals a pos kode, or sits
+// under an akun that does, are reported on that pos unless mapped otherwise.
+type PosKonsolidasi struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID  uint64    `gorm:"not null;uniqueIndex:idx_pos_konsolidasi_kode" json:"tenant_id"`
+	Kode      string    `gorm:"size:20;not null;uniqueIndex:idx_pos_konsolidasi_kode" json:"kode"`
+	Nama      string    `gorm:"size:255;not null" json:"nama"`
+	Tipe      string    `gorm:"type:varchar(20);not null" json:"tipe"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// PemetaanAkunKonsolidasi overrides where one koperasi akun is reported in
+// the consolidation. LawanKoperasiID marks the akun as a balance with
+// another koperasi of the tenant, eliminated when both are consolidated
+// together. A zero PosKonsolidasiID keeps the mapping by kode.
+type PemetaanAkunKonsolidasi struct {
+	ID               uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID         uint64    `gorm:"not null;index" json:"tenant_id"`
+	KoperasiID       uint64    `gorm:"not null;index" json:"koperasi_id"`
+	AkunID           uint64    `gorm:"not null;uniqueIndex" json:"akun_id"`
+	PosKonsolidasiID uint64    `json:"pos_konsolidasi_id"`
+	LawanKoperasiID  uint64    `json:"lawan_koperasi_id"`
+	CreatedAt        time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Akun           COAAkun        `gorm:"foreignKey:AkunID" json:"akun,omitempty"`
+	PosKonsolidasi PosKonsolidasi `gorm:"foreignKey:PosKonsolidasiID" json:"pos_konsolidasi,omitempty"`
+}
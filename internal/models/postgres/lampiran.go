@@ -0,0 +1,29 @@
+package postgres
+
+import "time"
+
+// Documents that can carry attachments.
+const (
+	LampiranJurnalUmum            = "jurnal_umum"
+	LampiranPembelian             = "pembelian"
+	LampiranPembayaranPembelian   = "pembayaran_pembelian"
+	LampiranTransaksiSimpanPinjam = "transaksi_simpan_pinjam"
+)
+
+// Lampiran is a supporting document (bukti transaksi) attached to a journal
+// or business document. The file itself lives in the storage backend under
+// StorageKey; Checksum is the SHA-256 of its content.
+type Lampiran struct {
+	ID           uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	KoperasiID   uint64    `gorm:"not null;index" json:"koperasi_id"`
+	JenisDokumen string    `gorm:"type:varchar(30);not null;index:idx_lampiran_dokumen" json:"jenis_dokumen"`
+	DokumenID    uint64    `gorm:"not null;index:idx_lampiran_dokumen" json:"dokumen_id"`
+	NamaFile     string    `gorm:"size:255;not null" json:"nama_file"`
+	ContentType  string    `gorm:"size:100;not null" json:"content_type"`
+	Ukuran       int64     `gorm:"not null" json:"ukuran"`
+	Checksum     string    `gorm:"size:64;not null" json:"checksum"`
+	StorageKey   string    `gorm:"size:255;not null;uniqueIndex" json:"-"`
+	Keterangan   string    `gorm:"type:text" json:"keterangan"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	CreatedBy    uint64    `json:"created_by"`
+}
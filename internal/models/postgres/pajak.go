@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"time"
+
+	"koperasi-merah-putih/internal/money"
+)
+
+// PengaturanPajak is the tax setup of one koperasi. PPN is charged on sales
+// only while IsPKP is set; purchases carry PPN whenever the supplier is PKP.
+// Prices are exclusive of PPN. Interest on simpanan is withheld under PPh
+// 4(2) only above BatasBungaBebas per payment.
+type PengaturanPajak struct {
+	ID              uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	KoperasiID      uint64       `gorm:"not null;uniqueIndex" json:"koperasi_id"`
+	IsPKP           bool         `gorm:"default:false" json:"is_pkp"`
+	NPWP            string       `gorm:"size:20" json:"npwp"`
+	TanggalPKP      *time.Time   `json:"tanggal_pkp"`
+	TarifPPN        float64      `gorm:"type:decimal(5,2);default:11" json:"tarif_ppn"`
+	TarifPPh21      float64      `gorm:"type:decimal(5,2);default:5" json:"tarif_pph21"`
+	TarifPPh23      float64      `gorm:"type:decimal(5,2);default:2" json:"tarif_pph23"`
+	TarifPPh4Ayat2  float64      `gorm:"type:decimal(5,2);default:10" json:"tarif_pph4_ayat2"`
+	BatasBungaBebas money.Amount `gorm:"type:decimal(15,2);default:240000" json:"batas_bunga_bebas"`
+	KodeObjekBunga  string       `gorm:"size:20" json:"kode_objek_bunga"`
+	CreatedAt       time.Time    `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time    `gorm:"autoUpdateTime" json:"updated_at"`
+	UpdatedBy       uint64       `json:"updated_by"`
+
+	Kategori []KategoriPajakProduk `gorm:"foreignKey:KoperasiID;references:KoperasiID" json:"kategori,omitempty"`
+}
+
+// KategoriPajakProduk sets how PPN treats a product category in one
+// koperasi. Categories without a row are BKP.
+type KategoriPajakProduk struct {
+	ID               uint64 `gorm:"primaryKey;autoIncrement" json:"id"`
+	KoperasiID       uint64 `gorm:"not null;uniqueIndex:idx_kategori_pajak_produk" json:"koperasi_id"`
+	KategoriProdukID uint64 `gorm:"not null;uniqueIndex:idx_kategori_pajak_produk" json:"kategori_produk_id"`
+	KategoriPajak    string `gorm:"type:varchar(20);not null" json:"kategori_pajak"`
+
+	KategoriProduk KategoriProduk `gorm:"foreignKey:KategoriProdukID" json:"kategori_produk,omitempty"`
+}
+
+// PotonganPajak is one PPh withheld on a payment, the source of a bukti
+// potong in the monthly e-Bupot export. Bruto is the income the tax is
+// computed on.
+type PotonganPajak struct {
+	ID              uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	KoperasiID      uint64       `gorm:"not null;index" json:"koperasi_id"`
+	NomorBukti      string       `gorm:"size:50;not null;uniqueIndex" json:"nomor_bukti"`
+	JenisPajak      string       `gorm:"type:varchar(10);not null" json:"jenis_pajak"`
+	KodeObjekPajak  string       `gorm:"size:20" json:"kode_objek_pajak"`
+	TanggalPotong   time.Time    `gorm:"type:date;not null;index" json:"tanggal_potong"`
+	SumberTransaksi string       `gorm:"size:50" json:"sumber_transaksi"`
+	SumberID        uint64       `json:"sumber_id"`
+	NamaPenerima    string       `gorm:"size:255" json:"nama_penerima"`
+	NPWP            string       `gorm:"size:20" json:"npwp"`
+	NIK             string       `gorm:"size:20" json:"nik"`
+	Alamat          string       `gorm:"type:text" json:"alamat"`
+	Bruto           money.Amount `gorm:"type:decimal(15,2);not null" json:"bruto"`
+	Tarif           float64      `gorm:"type:decimal(5,2);not null" json:"tarif"`
+	JumlahPPh       money.Amount `gorm:"type:decimal(15,2);not null" json:"jumlah_pph"`
+	JurnalID        uint64       `json:"jurnal_id"`
+	CreatedAt       time.Time    `gorm:"autoCreateTime" json:"created_at"`
+	CreatedBy       uint64       `json:"created_by"`
+}
+
+// SetoranPajak is the monthly settlement of the tax collected and withheld
+// in one masa: PPN keluaran less PPN masukan, plus every PPh withheld.
+// PPN lebih bayar is left on the books and compensated in the next masa;
+// a masa with only lebih bayar is still recorded, with nothing paid for PPN.
+type SetoranPajak struct {
+	ID            uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	KoperasiID    uint64       `gorm:"not null;uniqueIndex:idx_setoran_pajak_masa" json:"koperasi_id"`
+	MasaPajak     string       `gorm:"size:7;not null;uniqueIndex:idx_setoran_pajak_masa" json:"masa_pajak"`
+	TanggalSetor  time.Time    `gorm:"type:date;not null" json:"tanggal_setor"`
+	PPNKeluaran   money.Amount `gorm:"type:decimal(15,2);default:0" json:"ppn_keluaran"`
+	PPNMasukan    money.Amount `gorm:"type:decimal(15,2);default:0" json:"ppn_masukan"`
+	PPNKompensasi money.Amount `gorm:"type:decimal(15,2);default:0" json:"ppn_kompensasi"`
+	PPNLebihBayar money.Amount `gorm:"type:decimal(15,2);default:0" json:"ppn_lebih_bayar"`
+	PPh21         money.Amount `gorm:"type:decimal(15,2);default:0" json:"pph21"`
+	PPh23         money.Amount `gorm:"type:decimal(15,2);default:0" json:"pph23"`
+	PPh4Ayat2     money.Amount `gorm:"type:decimal(15,2);default:0" json:"pph4_ayat2"`
+	Total         money.Amount `gorm:"type:decimal(15,2);default:0" json:"total"`
+	NTPN          string       `gorm:"size:50" json:"ntpn"`
+	Keterangan    string       `gorm:"type:text" json:"keterangan"`
+	JurnalID      uint64       `json:"jurnal_id"`
+	CreatedAt     time.Time    `gorm:"autoCreateTime" json:"created_at"`
+	CreatedBy     uint64       `json:"created_by"`
+}
@@ -2,6 +2,8 @@ package postgres
 
 import (
 	"time"
+
+	"koperasi-merah-putih/internal/money"
 )
 
 type PaymentProvider struct {
@@ -15,7 +17,7 @@ type PaymentProvider struct {
 	SecretKey     string    `gorm:"size:500" json:"secret_key"`
 	CallbackURL   string    `gorm:"size:500" json:"callback_url"`
 	FeeType       string    `gorm:"type:varchar(20);default:'percentage'" json:"fee_type"`
-	FeeAmount     float64   `gorm:"type:decimal(15,2);default:0" json:"fee_amount"`
+	FeeAmount     money.Amount `gorm:"type:decimal(15,2);default:0" json:"fee_amount"`
 	FeePercentage float64   `gorm:"type:decimal(5,2);default:0" json:"fee_percentage"`
 	IsActive      bool      `gorm:"default:true" json:"is_active"`
 	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
@@ -33,8 +35,8 @@ type PaymentMethod struct {
 	BankCode       string  `gorm:"size:10" json:"bank_code"`
 	WalletCode     string  `gorm:"size:20" json:"wallet_code"`
 	LogoURL        string  `gorm:"size:500" json:"logo_url"`
-	MinimalAmount  float64 `gorm:"type:decimal(15,2);default:0" json:"minimal_amount"`
-	MaksimalAmount float64 `gorm:"type:decimal(15,2);default:0" json:"maksimal_amount"`
+	MinimalAmount  money.Amount `gorm:"type:decimal(15,2);default:0" json:"minimal_amount"`
+	MaksimalAmount money.Amount `gorm:"type:decimal(15,2);default:0" json:"maksimal_amount"`
 	IsActive       bool    `gorm:"default:true" json:"is_active"`
 
 	Provider                   PaymentProvider        `gorm:"foreignKey:ProviderID" json:"provider,omitempty"`
@@ -43,31 +45,35 @@ type PaymentMethod struct {
 }
 
 type PaymentTransaction struct {
-	ID               uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
-	TenantID         uint64     `gorm:"not null" json:"tenant_id"`
-	KoperasiID       uint64     `gorm:"index" json:"koperasi_id"`
-	NomorTransaksi   string     `gorm:"uniqueIndex;size:50;not null" json:"nomor_transaksi"`
-	ExternalID       string     `gorm:"size:100;index" json:"external_id"`
-	InvoiceID        string     `gorm:"size:100" json:"invoice_id"`
-	ProviderID       uint64     `gorm:"not null" json:"provider_id"`
-	MethodID         uint64     `gorm:"not null" json:"method_id"`
-	Amount           float64    `gorm:"type:decimal(15,2);not null" json:"amount"`
-	AdminFee         float64    `gorm:"type:decimal(15,2);default:0" json:"admin_fee"`
-	TotalAmount      float64    `gorm:"type:decimal(15,2);not null" json:"total_amount"`
-	CustomerName     string     `gorm:"size:255" json:"customer_name"`
-	CustomerEmail    string     `gorm:"size:255" json:"customer_email"`
-	CustomerPhone    string     `gorm:"size:20" json:"customer_phone"`
-	Description      string     `gorm:"type:text" json:"description"`
-	Status           string     `gorm:"type:varchar(20);default:'pending';index" json:"status"`
-	PaymentDate      *time.Time `json:"payment_date"`
-	ExpiredDate      *time.Time `json:"expired_date"`
-	GatewayResponse  string     `gorm:"type:json" json:"gateway_response"`
-	CallbackData     string     `gorm:"type:json" json:"callback_data"`
-	TransactionType  string     `gorm:"type:varchar(20);not null" json:"transaction_type"`
-	ReferenceID      uint64     `json:"reference_id"`
-	ReferenceTable   string     `gorm:"size:100" json:"reference_table"`
-	CreatedAt        time.Time  `gorm:"autoCreateTime;index" json:"created_at"`
-	UpdatedAt        time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	ID              uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID        uint64       `gorm:"not null" json:"tenant_id"`
+	KoperasiID      uint64       `gorm:"index" json:"koperasi_id"`
+	NomorTransaksi  string       `gorm:"uniqueIndex;size:50;not null" json:"nomor_transaksi"`
+	ExternalID      string       `gorm:"size:100;index" json:"external_id"`
+	InvoiceID       string       `gorm:"size:100" json:"invoice_id"`
+	ProviderID      uint64       `gorm:"not null" json:"provider_id"`
+	MethodID        uint64       `gorm:"not null" json:"method_id"`
+	Amount          money.Amount `gorm:"type:decimal(15,2);not null" json:"amount"`
+	AdminFee        money.Amount `gorm:"type:decimal(15,2);default:0" json:"admin_fee"`
+	TotalAmount     money.Amount `gorm:"type:decimal(15,2);not null" json:"total_amount"`
+	CustomerName    string       `gorm:"size:255" json:"customer_name"`
+	CustomerEmail   string       `gorm:"size:255" json:"customer_email"`
+	CustomerPhone   string       `gorm:"size:20" json:"customer_phone"`
+	Description     string       `gorm:"type:text" json:"description"`
+	Status          string       `gorm:"type:varchar(20);default:'pending';index" json:"status"`
+	PaymentDate     *time.Time   `json:"payment_date"`
+	ExpiredDate     *time.Time   `json:"expired_date"`
+	GatewayResponse string       `gorm:"type:json" json:"gateway_response"`
+	CallbackData    string       `gorm:"type:json" json:"callback_data"`
+	TransactionType string       `gorm:"type:varchar(20);not null" json:"transaction_type"`
+	ReferenceID     uint64       `json:"reference_id"`
+	ReferenceTable  string       `gorm:"size:100" json:"reference_table"`
+	RefundStatus    string       `gorm:"type:varchar(20);index" json:"refund_status"`
+	RefundAmount    money.Amount `gorm:"type:decimal(15,2);default:0" json:"refund_amount"`
+	RefundReference string       `gorm:"size:100" json:"refund_reference"`
+	RefundedAt      *time.Time   `json:"refunded_at"`
+	CreatedAt       time.Time    `gorm:"autoCreateTime;index" json:"created_at"`
+	UpdatedAt       time.Time    `gorm:"autoUpdateTime" json:"updated_at"`
 
 	Tenant                     Tenant                       `gorm:"foreignKey:TenantID" json:"tenant,omitempty"`
 	Koperasi                   Koperasi                     `gorm:"foreignKey:KoperasiID" json:"koperasi,omitempty"`
@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+
+	"koperasi-merah-putih/internal/money"
 )
 
 type PPOBKategori struct {
@@ -22,7 +24,9 @@ type PPOBProvider struct {
 	ID        uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
 	Kode      string    `gorm:"uniqueIndex;size:50;not null" json:"kode"`
 	Nama      string    `gorm:"size:255;not null" json:"nama"`
+	Adapter   string    `gorm:"size:30;not null;default:'digiflazz'" json:"adapter"`
 	BaseURL   string    `gorm:"size:500" json:"base_url"`
+	Username  string    `gorm:"size:100" json:"username"`
 	APIKey    string    `gorm:"size:500" json:"api_key"`
 	SecretKey string    `gorm:"size:500" json:"secret_key"`
 	IsAktif   bool      `gorm:"default:true" json:"is_aktif"`
@@ -32,17 +36,18 @@ type PPOBProvider struct {
 }
 
 type PPOBProduk struct {
-	ID               uint64  `gorm:"primaryKey;autoIncrement" json:"id"`
-	ProviderID       uint64  `gorm:"not null" json:"provider_id"`
-	KategoriID       uint64  `gorm:"not null;index" json:"kategori_id"`
-	KodeProduk       string  `gorm:"size:50;not null" json:"kode_produk"`
-	NamaProduk       string  `gorm:"size:255;not null" json:"nama_produk"`
-	Deskripsi        string  `gorm:"type:text" json:"deskripsi"`
-	HargaBeli        float64 `gorm:"type:decimal(15,2);default:0" json:"harga_beli"`
-	HargaJual        float64 `gorm:"type:decimal(15,2);default:0" json:"harga_jual"`
-	FeeAgen          float64 `gorm:"type:decimal(15,2);default:0" json:"fee_agen"`
-	IsAktif          bool    `gorm:"default:true" json:"is_aktif"`
-	ValidasiFormat   string  `gorm:"size:100" json:"validasi_format"`
+	ID             uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	ProviderID     uint64       `gorm:"not null" json:"provider_id"`
+	KategoriID     uint64       `gorm:"not null;index" json:"kategori_id"`
+	KodeProduk     string       `gorm:"size:50;not null" json:"kode_produk"`
+	NamaProduk     string       `gorm:"size:255;not null" json:"nama_produk"`
+	Deskripsi      string       `gorm:"type:text" json:"deskripsi"`
+	HargaBeli      money.Amount `gorm:"type:decimal(15,2);default:0" json:"harga_beli"`
+	HargaJual      money.Amount `gorm:"type:decimal(15,2);default:0" json:"harga_jual"`
+	FeeAgen        money.Amount `gorm:"type:decimal(15,2);default:0" json:"fee_agen"`
+	IsAktif        bool         `gorm:"default:true" json:"is_aktif"`
+	IsPascabayar   bool         `gorm:"default:false" json:"is_pascabayar"`
+	ValidasiFormat string       `gorm:"size:100" json:"validasi_format"`
 
 	Provider      PPOBProvider    `gorm:"foreignKey:ProviderID" json:"provider,omitempty"`
 	Kategori      PPOBKategori    `gorm:"foreignKey:KategoriID" json:"kategori,omitempty"`
@@ -50,28 +55,38 @@ type PPOBProduk struct {
 }
 
 type PPOBTransaksi struct {
-	ID                 uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
-	KoperasiID         uint64     `gorm:"not null;index" json:"koperasi_id"`
-	AnggotaID          uint64     `gorm:"index" json:"anggota_id"`
-	ProdukID           uint64     `gorm:"not null" json:"produk_id"`
-	NomorTransaksi     string     `gorm:"uniqueIndex;size:50;not null" json:"nomor_transaksi"`
-	NomorReferensi     string     `gorm:"size:100" json:"nomor_referensi"`
-	NomorTujuan        string     `gorm:"size:50;not null" json:"nomor_tujuan"`
-	NamaPelanggan      string     `gorm:"size:255" json:"nama_pelanggan"`
-	HargaBeli          float64    `gorm:"type:decimal(15,2);not null" json:"harga_beli"`
-	HargaJual          float64    `gorm:"type:decimal(15,2);not null" json:"harga_jual"`
-	FeeAgen            float64    `gorm:"type:decimal(15,2);default:0" json:"fee_agen"`
-	Status             string     `gorm:"type:varchar(20);default:'pending';index" json:"status"`
-	PesanResponse      string     `gorm:"type:text" json:"pesan_response"`
-	TanggalTransaksi   time.Time  `gorm:"default:CURRENT_TIMESTAMP;index" json:"tanggal_transaksi"`
-	TanggalSettlement  *time.Time `json:"tanggal_settlement"`
-	JurnalID           uint64     `json:"jurnal_id"`
-	PaymentID          uint64     `json:"payment_id"`
-	PaymentStatus      string     `gorm:"type:varchar(20);default:'pending';index" json:"payment_status"`
-	CustomerName       string     `gorm:"size:255" json:"customer_name"`
-	CustomerEmail      string     `gorm:"size:255" json:"customer_email"`
-	CustomerPhone      string     `gorm:"size:20" json:"customer_phone"`
-	AdminFee           float64    `gorm:"type:decimal(15,2);default:0" json:"admin_fee"`
+	ID                 uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	KoperasiID         uint64       `gorm:"not null;index" json:"koperasi_id"`
+	AnggotaID          uint64       `gorm:"index" json:"anggota_id"`
+	ProdukID           uint64       `gorm:"not null" json:"produk_id"`
+	NomorTransaksi     string       `gorm:"uniqueIndex;size:50;not null" json:"nomor_transaksi"`
+	NomorReferensi     string       `gorm:"size:100" json:"nomor_referensi"`
+	NomorTujuan        string       `gorm:"size:50;not null" json:"nomor_tujuan"`
+	NamaPelanggan      string       `gorm:"size:255" json:"nama_pelanggan"`
+	HargaBeli          money.Amount `gorm:"type:decimal(15,2);not null" json:"harga_beli"`
+	HargaJual          money.Amount `gorm:"type:decimal(15,2);not null" json:"harga_jual"`
+	FeeAgen            money.Amount `gorm:"type:decimal(15,2);default:0" json:"fee_agen"`
+	Status             string       `gorm:"type:varchar(20);default:'pending';index" json:"status"`
+	PesanResponse      string       `gorm:"type:text" json:"pesan_response"`
+	TanggalTransaksi   time.Time    `gorm:"default:CURRENT_TIMESTAMP;index" json:"tanggal_transaksi"`
+	TanggalSettlement  *time.Time   `json:"tanggal_settlement"`
+	JurnalID           uint64       `json:"jurnal_id"`
+	PaymentID          uint64       `json:"payment_id"`
+	PaymentStatus      string       `gorm:"type:varchar(20);default:'pending';index" json:"payment_status"`
+	MetodePembayaran   string       `gorm:"type:varchar(20);default:'gateway'" json:"metode_pembayaran"`
+	RekeningSimpananID uint64       `json:"rekening_simpanan_id"`
+	CustomerName       string       `gorm:"size:255" json:"customer_name"`
+	CustomerEmail      string       `gorm:"size:255" json:"customer_email"`
+	CustomerPhone      string       `gorm:"size:20" json:"customer_phone"`
+	AdminFee           money.Amount `gorm:"type:decimal(15,2);default:0" json:"admin_fee"`
+	InquiryID          uint64       `gorm:"index" json:"inquiry_id"`
+	StatusRefund       string       `gorm:"type:varchar(20);index" json:"status_refund"`
+	JumlahRefund       money.Amount `gorm:"type:decimal(15,2);default:0" json:"jumlah_refund"`
+	AlasanRefund       string       `gorm:"size:255" json:"alasan_refund"`
+	PesanRefund        string       `gorm:"type:text" json:"pesan_refund"`
+	ReferensiRefund    string       `gorm:"size:100" json:"referensi_refund"`
+	TanggalRefund      *time.Time   `json:"tanggal_refund"`
+	RefundJurnalID     uint64       `json:"refund_jurnal_id"`
 
 	Koperasi        Koperasi           `gorm:"foreignKey:KoperasiID" json:"koperasi,omitempty"`
 	Anggota         AnggotaKoperasi    `gorm:"foreignKey:AnggotaID" json:"anggota,omitempty"`
@@ -81,6 +96,45 @@ type PPOBTransaksi struct {
 	SettlementDetails []PPOBSettlementDetail `gorm:"foreignKey:PPOBTransaksiID" json:"settlement_details,omitempty"`
 }
 
+// Refund statuses of a PPOB transaction. A simpanan refund succeeds at once;
+// a payment gateway refund goes through pending and processing.
+const (
+	PPOBRefundPending = "pending"
+	PPOBRefundProses  = "processing"
+	PPOBRefundSukses  = "success"
+	PPOBRefundGagal   = "failed"
+)
+
+const (
+	PPOBInquiryAktif       = "aktif"
+	PPOBInquiryDipakai     = "dipakai"
+	PPOBInquiryKedaluwarsa = "kedaluwarsa"
+)
+
+// PPOBInquiry holds the bill check of a postpaid product. NomorInquiry is
+// sent to the provider as ref_id and later becomes the NomorTransaksi,
+// because the provider requires the payment to reuse the inquiry's ref_id.
+type PPOBInquiry struct {
+	ID              uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	KoperasiID      uint64       `gorm:"not null;index" json:"koperasi_id"`
+	AnggotaID       uint64       `gorm:"index" json:"anggota_id"`
+	ProdukID        uint64       `gorm:"not null" json:"produk_id"`
+	NomorInquiry    string       `gorm:"uniqueIndex;size:50;not null" json:"nomor_inquiry"`
+	NomorTujuan     string       `gorm:"size:50;not null" json:"nomor_tujuan"`
+	NamaPelanggan   string       `gorm:"size:255" json:"nama_pelanggan"`
+	Periode         string       `gorm:"size:255" json:"periode"`
+	JumlahLembar    int          `gorm:"default:0" json:"jumlah_lembar"`
+	JumlahTagihan   money.Amount `gorm:"type:decimal(15,2);not null" json:"jumlah_tagihan"`
+	AdminProvider   money.Amount `gorm:"type:decimal(15,2);default:0" json:"admin_provider"`
+	HargaBeli       money.Amount `gorm:"type:decimal(15,2);not null" json:"harga_beli"`
+	Status          string       `gorm:"type:varchar(20);default:'aktif';index" json:"status"`
+	KedaluwarsaPada time.Time    `gorm:"not null;index" json:"kedaluwarsa_pada"`
+	TransaksiID     uint64       `json:"transaksi_id"`
+	CreatedAt       time.Time    `gorm:"autoCreateTime" json:"created_at"`
+
+	Produk PPOBProduk `gorm:"foreignKey:ProdukID" json:"produk,omitempty"`
+}
+
 type PPOBPaymentConfig struct {
 	ID                      uint64 `gorm:"primaryKey;autoIncrement" json:"id"`
 	KoperasiID              uint64 `gorm:"not null;uniqueIndex" json:"koperasi_id"`
@@ -88,7 +142,7 @@ type PPOBPaymentConfig struct {
 	DefaultPaymentMethodID  uint64 `json:"default_payment_method_id"`
 	AutoSettlement          bool   `gorm:"default:false" json:"auto_settlement"`
 	SettlementSchedule      string `gorm:"type:varchar(20);default:'daily'" json:"settlement_schedule"`
-	PPOBAdminFee            float64 `gorm:"type:decimal(15,2);default:0" json:"ppob_admin_fee"`
+	PPOBAdminFee           money.Amount `gorm:"type:decimal(15,2);default:0" json:"ppob_admin_fee"`
 	PPOBAdminFeeType        string `gorm:"type:varchar(20);default:'fixed'" json:"ppob_admin_fee_type"`
 	IsActive                bool   `gorm:"default:true" json:"is_active"`
 
@@ -97,22 +151,24 @@ type PPOBPaymentConfig struct {
 }
 
 type PPOBSettlement struct {
-	ID               uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
-	KoperasiID       uint64     `gorm:"not null" json:"koperasi_id"`
-	NomorSettlement  string     `gorm:"size:50;not null" json:"nomor_settlement"`
-	TanggalSettlement time.Time  `gorm:"not null;index" json:"tanggal_settlement"`
-	PeriodeDari      time.Time  `gorm:"not null" json:"periode_dari"`
-	PeriodeSampai    time.Time  `gorm:"not null" json:"periode_sampai"`
-	JumlahTransaksi  int        `gorm:"default:0" json:"jumlah_transaksi"`
-	TotalOmzet       float64    `gorm:"type:decimal(15,2);default:0" json:"total_omzet"`
-	TotalFeeAgen     float64    `gorm:"type:decimal(15,2);default:0" json:"total_fee_agen"`
-	TotalAdminFee    float64    `gorm:"type:decimal(15,2);default:0" json:"total_admin_fee"`
-	TotalSettlement  float64    `gorm:"type:decimal(15,2);default:0" json:"total_settlement"`
-	Status           string     `gorm:"type:varchar(20);default:'draft';index" json:"status"`
-	JurnalID         uint64     `json:"jurnal_id"`
-	ProcessedAt      *time.Time `json:"processed_at"`
-	ProcessedBy      uint64     `json:"processed_by"`
-	CreatedAt        time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	ID                uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	KoperasiID        uint64       `gorm:"not null" json:"koperasi_id"`
+	NomorSettlement   string       `gorm:"size:50;not null" json:"nomor_settlement"`
+	TanggalSettlement time.Time    `gorm:"not null;index" json:"tanggal_settlement"`
+	PeriodeDari       time.Time    `gorm:"not null" json:"periode_dari"`
+	PeriodeSampai     time.Time    `gorm:"not null" json:"periode_sampai"`
+	JumlahTransaksi   int          `gorm:"default:0" json:"jumlah_transaksi"`
+	TotalOmzet        money.Amount `gorm:"type:decimal(15,2);default:0" json:"total_omzet"`
+	TotalFeeAgen      money.Amount `gorm:"type:decimal(15,2);default:0" json:"total_fee_agen"`
+	TotalAdminFee     money.Amount `gorm:"type:decimal(15,2);default:0" json:"total_admin_fee"`
+	TotalSettlement   money.Amount `gorm:"type:decimal(15,2);default:0" json:"total_settlement"`
+	Status            string       `gorm:"type:varchar(20);default:'draft';index" json:"status"`
+	JurnalID          uint64       `json:"jurnal_id"`
+	ProcessedAt       *time.Time   `json:"processed_at"`
+	ProcessedBy       uint64       `json:"processed_by"`
+	PaidAt            *time.Time   `json:"paid_at"`
+	PaidBy            uint64       `json:"paid_by"`
+	CreatedAt         time.Time    `gorm:"autoCreateTime" json:"created_at"`
 
 	Koperasi          Koperasi               `gorm:"foreignKey:KoperasiID" json:"koperasi,omitempty"`
 	Jurnal            JurnalUmum             `gorm:"foreignKey:JurnalID" json:"jurnal,omitempty"`
@@ -120,14 +176,29 @@ type PPOBSettlement struct {
 	SettlementDetails []PPOBSettlementDetail `gorm:"foreignKey:SettlementID" json:"settlement_details,omitempty"`
 }
 
+// PPOB settlement statuses: draft when created, processed once the revenue
+// journal is posted, and paid when the koperasi has received the fees.
+const (
+	PPOBSettlementDraft     = "draft"
+	PPOBSettlementProcessed = "processed"
+	PPOBSettlementPaid      = "paid"
+)
+
+// Auto settlement schedules for PPOBPaymentConfig.SettlementSchedule.
+const (
+	PPOBSettlementHarian   = "daily"
+	PPOBSettlementMingguan = "weekly"
+	PPOBSettlementBulanan  = "monthly"
+)
+
 type PPOBSettlementDetail struct {
 	ID               uint64  `gorm:"primaryKey;autoIncrement" json:"id"`
 	SettlementID     uint64  `gorm:"not null" json:"settlement_id"`
 	PPOBTransaksiID  uint64  `gorm:"not null" json:"ppob_transaksi_id"`
-	Omzet            float64 `gorm:"type:decimal(15,2);not null" json:"omzet"`
-	FeeAgen          float64 `gorm:"type:decimal(15,2);not null" json:"fee_agen"`
-	AdminFee         float64 `gorm:"type:decimal(15,2);not null" json:"admin_fee"`
+	Omzet           money.Amount `gorm:"type:decimal(15,2);not null" json:"omzet"`
+	FeeAgen         money.Amount `gorm:"type:decimal(15,2);not null" json:"fee_agen"`
+	AdminFee        money.Amount `gorm:"type:decimal(15,2);not null" json:"admin_fee"`
 
 	Settlement    PPOBSettlement  `gorm:"foreignKey:SettlementID" json:"settlement,omitempty"`
 	PPOBTransaksi PPOBTransaksi   `gorm:"foreignKey:PPOBTransaksiID" json:"ppob_transaksi,omitempty"`
-}
\ No newline at end of file
+}
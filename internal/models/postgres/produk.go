@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+
+	"koperasi-merah-putih/internal/money"
 )
 
 type KategoriProduk struct {
@@ -47,6 +49,7 @@ type Supplier struct {
 	NamaBank       string         `gorm:"size:100" json:"nama_bank"`
 	AtasNamaBank   string         `gorm:"size:100" json:"atas_nama_bank"`
 	NPWP           string         `gorm:"size:20" json:"npwp"`
+	IsPKP          bool           `gorm:"default:false" json:"is_pkp"`
 	JenisSupplier  string         `gorm:"type:varchar(20);default:'individu'" json:"jenis_supplier"`
 	Status         string         `gorm:"type:varchar(20);default:'aktif'" json:"status"`
 	TermPembayaran int            `gorm:"default:30;comment:hari" json:"term_pembayaran"`
@@ -78,8 +81,8 @@ type Produk struct {
 	BeratBersih       float64        `gorm:"type:decimal(10,2)" json:"berat_bersih"`
 	Dimensi           string         `gorm:"size:50" json:"dimensi"`
 	FotoProduk        string         `gorm:"size:500" json:"foto_produk"`
-	HargaBeli         float64        `gorm:"type:decimal(15,2);default:0" json:"harga_beli"`
-	HargaJual         float64        `gorm:"type:decimal(15,2);not null" json:"harga_jual"`
+	HargaBeli        money.Amount   `gorm:"type:decimal(15,2);default:0" json:"harga_beli"`
+	HargaJual        money.Amount   `gorm:"type:decimal(15,2);not null" json:"harga_jual"`
 	MarginPersen      float64        `gorm:"type:decimal(5,2);default:0" json:"margin_persen"`
 	StokMinimal       int            `gorm:"default:0" json:"stok_minimal"`
 	StokMaksimal      int            `gorm:"default:0" json:"stok_maksimal"`
@@ -111,7 +114,7 @@ type SupplierProduk struct {
 	SupplierID    uint64         `gorm:"not null" json:"supplier_id"`
 	ProdukID      uint64         `gorm:"not null" json:"produk_id"`
 	KodeSupplier  string         `gorm:"size:50" json:"kode_supplier"`
-	HargaSupplier float64        `gorm:"type:decimal(15,2)" json:"harga_supplier"`
+	HargaSupplier money.Amount   `gorm:"type:decimal(15,2)" json:"harga_supplier"`
 	MinOrder      int            `gorm:"default:1" json:"min_order"`
 	LeadTime      int            `gorm:"default:1;comment:hari" json:"lead_time"`
 	IsPreferred   bool           `gorm:"default:false" json:"is_preferred"`
@@ -132,12 +135,12 @@ type PurchaseOrder struct {
 	TanggalPO        time.Time      `json:"tanggal_po"`
 	TanggalKirim     *time.Time     `json:"tanggal_kirim"`
 	TotalItem        int            `gorm:"default:0" json:"total_item"`
-	SubTotal         float64        `gorm:"type:decimal(15,2);default:0" json:"sub_total"`
+	SubTotal     money.Amount   `gorm:"type:decimal(15,2);default:0" json:"sub_total"`
 	PajakPersen      float64        `gorm:"type:decimal(5,2);default:0" json:"pajak_persen"`
-	TotalPajak       float64        `gorm:"type:decimal(15,2);default:0" json:"total_pajak"`
-	BiayaKirim       float64        `gorm:"type:decimal(15,2);default:0" json:"biaya_kirim"`
-	Diskon           float64        `gorm:"type:decimal(15,2);default:0" json:"diskon"`
-	GrandTotal       float64        `gorm:"type:decimal(15,2);default:0" json:"grand_total"`
+	TotalPajak   money.Amount   `gorm:"type:decimal(15,2);default:0" json:"total_pajak"`
+	BiayaKirim   money.Amount   `gorm:"type:decimal(15,2);default:0" json:"biaya_kirim"`
+	Diskon       money.Amount   `gorm:"type:decimal(15,2);default:0" json:"diskon"`
+	GrandTotal   money.Amount   `gorm:"type:decimal(15,2);default:0" json:"grand_total"`
 	Status           string         `gorm:"type:varchar(20);default:'draft'" json:"status"`
 	Keterangan       string         `gorm:"type:text" json:"keterangan"`
 	ApprovedBy       uint64         `json:"approved_by"`
@@ -158,8 +161,8 @@ type PurchaseOrderDetail struct {
 	PurchaseOrderID uint64         `gorm:"not null" json:"purchase_order_id"`
 	ProdukID        uint64         `gorm:"not null" json:"produk_id"`
 	Qty             int            `gorm:"not null" json:"qty"`
-	HargaSatuan     float64        `gorm:"type:decimal(15,2);not null" json:"harga_satuan"`
-	Subtotal        float64        `gorm:"type:decimal(15,2);not null" json:"subtotal"`
+	HargaSatuan     money.Amount   `gorm:"type:decimal(15,2);not null" json:"harga_satuan"`
+	Subtotal        money.Amount   `gorm:"type:decimal(15,2);not null" json:"subtotal"`
 	QtyReceived     int            `gorm:"default:0" json:"qty_received"`
 	Keterangan      string         `gorm:"type:text" json:"keterangan"`
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"deleted_at"`
@@ -169,28 +172,31 @@ type PurchaseOrderDetail struct {
 }
 
 type PembelianHeader struct {
-	ID               uint64         `gorm:"primaryKey;autoIncrement" json:"id"`
-	KoperasiID       uint64         `gorm:"not null;index" json:"koperasi_id"`
-	SupplierID       uint64         `gorm:"not null" json:"supplier_id"`
-	PurchaseOrderID  uint64         `json:"purchase_order_id"`
-	NomorFaktur      string         `gorm:"size:50;not null;uniqueIndex" json:"nomor_faktur"`
-	TanggalFaktur    time.Time      `json:"tanggal_faktur"`
-	TanggalJatuhTempo *time.Time    `json:"tanggal_jatuh_tempo"`
-	TotalItem        int            `gorm:"default:0" json:"total_item"`
-	SubTotal         float64        `gorm:"type:decimal(15,2);default:0" json:"sub_total"`
-	PajakPersen      float64        `gorm:"type:decimal(5,2);default:0" json:"pajak_persen"`
-	TotalPajak       float64        `gorm:"type:decimal(15,2);default:0" json:"total_pajak"`
-	BiayaKirim       float64        `gorm:"type:decimal(15,2);default:0" json:"biaya_kirim"`
-	Diskon           float64        `gorm:"type:decimal(15,2);default:0" json:"diskon"`
-	GrandTotal       float64        `gorm:"type:decimal(15,2);default:0" json:"grand_total"`
-	StatusPembayaran string         `gorm:"type:varchar(20);default:'unpaid'" json:"status_pembayaran"`
-	TotalBayar       float64        `gorm:"type:decimal(15,2);default:0" json:"total_bayar"`
-	Keterangan       string         `gorm:"type:text" json:"keterangan"`
-	CreatedAt        time.Time      `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt        time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
-	CreatedBy        uint64         `json:"created_by"`
-	UpdatedBy        uint64         `json:"updated_by"`
-	DeletedAt        gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	ID                uint64         `gorm:"primaryKey;autoIncrement" json:"id"`
+	KoperasiID        uint64         `gorm:"not null;index" json:"koperasi_id"`
+	SupplierID        uint64         `gorm:"not null" json:"supplier_id"`
+	PurchaseOrderID   uint64         `json:"purchase_order_id"`
+	NomorFaktur       string         `gorm:"size:50;not null;uniqueIndex" json:"nomor_faktur"`
+	NomorFakturPajak  string         `gorm:"size:30" json:"nomor_faktur_pajak"`
+	TanggalFaktur     time.Time      `json:"tanggal_faktur"`
+	TanggalJatuhTempo *time.Time     `json:"tanggal_jatuh_tempo"`
+	TotalItem         int            `gorm:"default:0" json:"total_item"`
+	SubTotal          money.Amount   `gorm:"type:decimal(15,2);default:0" json:"sub_total"`
+	DPP               money.Amount   `gorm:"type:decimal(15,2);default:0" json:"dpp"`
+	PajakPersen       float64        `gorm:"type:decimal(5,2);default:0" json:"pajak_persen"`
+	TotalPajak        money.Amount   `gorm:"type:decimal(15,2);default:0" json:"total_pajak"`
+	BiayaKirim        money.Amount   `gorm:"type:decimal(15,2);default:0" json:"biaya_kirim"`
+	Diskon            money.Amount   `gorm:"type:decimal(15,2);default:0" json:"diskon"`
+	GrandTotal        money.Amount   `gorm:"type:decimal(15,2);default:0" json:"grand_total"`
+	StatusPembayaran  string         `gorm:"type:varchar(20);default:'unpaid'" json:"status_pembayaran"`
+	TotalBayar        money.Amount   `gorm:"type:decimal(15,2);default:0" json:"total_bayar"`
+	JurnalID          uint64         `json:"jurnal_id"`
+	Keterangan        string         `gorm:"type:text" json:"keterangan"`
+	CreatedAt         time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	CreatedBy         uint64         `json:"created_by"`
+	UpdatedBy         uint64         `json:"updated_by"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 
 	Koperasi        Koperasi        `gorm:"foreignKey:KoperasiID" json:"koperasi,omitempty"`
 	Supplier        Supplier        `gorm:"foreignKey:SupplierID" json:"supplier,omitempty"`
@@ -204,8 +210,8 @@ type PembelianDetail struct {
 	PembelianHeaderID  uint64         `gorm:"not null" json:"pembelian_header_id"`
 	ProdukID           uint64         `gorm:"not null" json:"produk_id"`
 	Qty                int            `gorm:"not null" json:"qty"`
-	HargaSatuan        float64        `gorm:"type:decimal(15,2);not null" json:"harga_satuan"`
-	Subtotal           float64        `gorm:"type:decimal(15,2);not null" json:"subtotal"`
+	HargaSatuan       money.Amount   `gorm:"type:decimal(15,2);not null" json:"harga_satuan"`
+	Subtotal          money.Amount   `gorm:"type:decimal(15,2);not null" json:"subtotal"`
 	TanggalExpired     *time.Time     `json:"tanggal_expired"`
 	BatchNumber        string         `gorm:"size:50" json:"batch_number"`
 	Keterangan         string         `gorm:"type:text" json:"keterangan"`
@@ -218,11 +224,13 @@ type PembelianDetail struct {
 type PembayaranPembelian struct {
 	ID                uint64         `gorm:"primaryKey;autoIncrement" json:"id"`
 	PembelianHeaderID uint64         `gorm:"not null" json:"pembelian_header_id"`
+	NomorPembayaran   string         `gorm:"size:50;index" json:"nomor_pembayaran"`
 	TanggalBayar      time.Time      `json:"tanggal_bayar"`
-	JumlahBayar       float64        `gorm:"type:decimal(15,2);not null" json:"jumlah_bayar"`
+	JumlahBayar       money.Amount   `gorm:"type:decimal(15,2);not null" json:"jumlah_bayar"`
 	MetodePembayaran  string         `gorm:"type:varchar(20);default:'cash'" json:"metode_pembayaran"`
 	NomorReferensi    string         `gorm:"size:100" json:"nomor_referensi"`
 	Keterangan        string         `gorm:"type:text" json:"keterangan"`
+	JurnalID          uint64         `json:"jurnal_id"`
 	CreatedAt         time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	CreatedBy         uint64         `json:"created_by"`
 	DeletedAt         gorm.DeletedAt `gorm:"index" json:"deleted_at"`
@@ -231,32 +239,38 @@ type PembayaranPembelian struct {
 }
 
 type PenjualanHeader struct {
-	ID               uint64         `gorm:"primaryKey;autoIncrement" json:"id"`
-	KoperasiID       uint64         `gorm:"not null;index" json:"koperasi_id"`
-	AnggotaID        uint64         `json:"anggota_id"`
-	NomorTransaksi   string         `gorm:"size:50;not null;uniqueIndex" json:"nomor_transaksi"`
-	TanggalTransaksi time.Time      `json:"tanggal_transaksi"`
-	TotalItem        int            `gorm:"default:0" json:"total_item"`
-	SubTotal         float64        `gorm:"type:decimal(15,2);default:0" json:"sub_total"`
-	PajakPersen      float64        `gorm:"type:decimal(5,2);default:0" json:"pajak_persen"`
-	TotalPajak       float64        `gorm:"type:decimal(15,2);default:0" json:"total_pajak"`
-	Diskon           float64        `gorm:"type:decimal(15,2);default:0" json:"diskon"`
-	GrandTotal       float64        `gorm:"type:decimal(15,2);default:0" json:"grand_total"`
-	MetodePembayaran string         `gorm:"type:varchar(20);default:'cash'" json:"metode_pembayaran"`
-	StatusPembayaran string         `gorm:"type:varchar(20);default:'pending'" json:"status_pembayaran"`
-	JumlahBayar      float64        `gorm:"type:decimal(15,2);default:0" json:"jumlah_bayar"`
-	JumlahKembalian  float64        `gorm:"type:decimal(15,2);default:0" json:"jumlah_kembalian"`
-	Kasir            string         `gorm:"size:100" json:"kasir"`
-	Keterangan       string         `gorm:"type:text" json:"keterangan"`
-	CreatedAt        time.Time      `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt        time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
-	CreatedBy        uint64         `json:"created_by"`
-	UpdatedBy        uint64         `json:"updated_by"`
-	DeletedAt        gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	ID                uint64         `gorm:"primaryKey;autoIncrement" json:"id"`
+	KoperasiID        uint64         `gorm:"not null;index" json:"koperasi_id"`
+	AnggotaID         uint64         `json:"anggota_id"`
+	NomorTransaksi    string         `gorm:"size:50;not null;uniqueIndex" json:"nomor_transaksi"`
+	NomorFakturPajak  string         `gorm:"size:30" json:"nomor_faktur_pajak"`
+	TanggalTransaksi  time.Time      `json:"tanggal_transaksi"`
+	TotalItem         int            `gorm:"default:0" json:"total_item"`
+	SubTotal          money.Amount   `gorm:"type:decimal(15,2);default:0" json:"sub_total"`
+	DPP               money.Amount   `gorm:"type:decimal(15,2);default:0" json:"dpp"`
+	PajakPersen       float64        `gorm:"type:decimal(5,2);default:0" json:"pajak_persen"`
+	TotalPajak        money.Amount   `gorm:"type:decimal(15,2);default:0" json:"total_pajak"`
+	Diskon            money.Amount   `gorm:"type:decimal(15,2);default:0" json:"diskon"`
+	GrandTotal        money.Amount   `gorm:"type:decimal(15,2);default:0" json:"grand_total"`
+	MetodePembayaran  string         `gorm:"type:varchar(20);default:'cash'" json:"metode_pembayaran"`
+	StatusPembayaran  string         `gorm:"type:varchar(20);default:'pending'" json:"status_pembayaran"`
+	JumlahBayar       money.Amount   `gorm:"type:decimal(15,2);default:0" json:"jumlah_bayar"`
+	JumlahKembalian   money.Amount   `gorm:"type:decimal(15,2);default:0" json:"jumlah_kembalian"`
+	TotalTerbayar     money.Amount   `gorm:"type:decimal(15,2);default:0" json:"total_terbayar"`
+	TanggalJatuhTempo *time.Time     `json:"tanggal_jatuh_tempo"`
+	Kasir             string         `gorm:"size:100" json:"kasir"`
+	JurnalID          uint64         `json:"jurnal_id"`
+	Keterangan        string         `gorm:"type:text" json:"keterangan"`
+	CreatedAt         time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	CreatedBy         uint64         `json:"created_by"`
+	UpdatedBy         uint64         `json:"updated_by"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 
-	Koperasi        Koperasi        `gorm:"foreignKey:KoperasiID" json:"koperasi,omitempty"`
-	Anggota         AnggotaKoperasi `gorm:"foreignKey:AnggotaID" json:"anggota,omitempty"`
-	PenjualanDetail []PenjualanDetail `gorm:"foreignKey:PenjualanHeaderID" json:"penjualan_detail,omitempty"`
+	Koperasi            Koperasi              `gorm:"foreignKey:KoperasiID" json:"koperasi,omitempty"`
+	Anggota             AnggotaKoperasi       `gorm:"foreignKey:AnggotaID" json:"anggota,omitempty"`
+	PenjualanDetail     []PenjualanDetail     `gorm:"foreignKey:PenjualanHeaderID" json:"penjualan_detail,omitempty"`
+	PembayaranPenjualan []PembayaranPenjualan `gorm:"foreignKey:PenjualanHeaderID" json:"pembayaran_penjualan,omitempty"`
 }
 
 type PenjualanDetail struct {
@@ -264,10 +278,10 @@ type PenjualanDetail struct {
 	PenjualanHeaderID uint64         `gorm:"not null" json:"penjualan_header_id"`
 	ProdukID          uint64         `gorm:"not null" json:"produk_id"`
 	Qty               int            `gorm:"not null" json:"qty"`
-	HargaSatuan       float64        `gorm:"type:decimal(15,2);not null" json:"harga_satuan"`
+	HargaSatuan       money.Amount   `gorm:"type:decimal(15,2);not null" json:"harga_satuan"`
 	DiskonPersen      float64        `gorm:"type:decimal(5,2);default:0" json:"diskon_persen"`
-	DiskonRupiah      float64        `gorm:"type:decimal(15,2);default:0" json:"diskon_rupiah"`
-	Subtotal          float64        `gorm:"type:decimal(15,2);not null" json:"subtotal"`
+	DiskonRupiah      money.Amount   `gorm:"type:decimal(15,2);default:0" json:"diskon_rupiah"`
+	Subtotal          money.Amount   `gorm:"type:decimal(15,2);not null" json:"subtotal"`
 	Keterangan        string         `gorm:"type:text" json:"keterangan"`
 	DeletedAt         gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 
@@ -275,6 +289,26 @@ type PenjualanDetail struct {
 	Produk          Produk          `gorm:"foreignKey:ProdukID" json:"produk,omitempty"`
 }
 
+// PembayaranPenjualan is a collection against a credit sale. The down payment
+// taken at the till stays on the header as JumlahBayar.
+type PembayaranPenjualan struct {
+	ID                 uint64         `gorm:"primaryKey;autoIncrement" json:"id"`
+	PenjualanHeaderID  uint64         `gorm:"not null;index" json:"penjualan_header_id"`
+	NomorPembayaran    string         `gorm:"size:50;index" json:"nomor_pembayaran"`
+	TanggalBayar       time.Time      `json:"tanggal_bayar"`
+	JumlahBayar        money.Amount   `gorm:"type:decimal(15,2);not null" json:"jumlah_bayar"`
+	MetodePembayaran   string         `gorm:"type:varchar(20);default:'cash'" json:"metode_pembayaran"`
+	RekeningSimpananID uint64         `json:"rekening_simpanan_id"`
+	NomorReferensi     string         `gorm:"size:100" json:"nomor_referensi"`
+	Keterangan         string         `gorm:"type:text" json:"keterangan"`
+	JurnalID           uint64         `json:"jurnal_id"`
+	CreatedAt          time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	CreatedBy          uint64         `json:"created_by"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+
+	PenjualanHeader PenjualanHeader `gorm:"foreignKey:PenjualanHeaderID" json:"penjualan_header,omitempty"`
+}
+
 type StokMovement struct {
 	ID             uint64         `gorm:"primaryKey;autoIncrement" json:"id"`
 	KoperasiID     uint64         `gorm:"not null;index" json:"koperasi_id"`
@@ -286,8 +320,8 @@ type StokMovement struct {
 	QtyBefore      int            `gorm:"not null" json:"qty_before"`
 	QtyMovement    int            `gorm:"not null" json:"qty_movement"`
 	QtyAfter       int            `gorm:"not null" json:"qty_after"`
-	HargaSatuan    float64        `gorm:"type:decimal(15,2)" json:"harga_satuan"`
-	TotalNilai     float64        `gorm:"type:decimal(15,2)" json:"total_nilai"`
+	HargaSatuan     money.Amount   `gorm:"type:decimal(15,2)" json:"harga_satuan"`
+	TotalNilai      money.Amount   `gorm:"type:decimal(15,2)" json:"total_nilai"`
 	Keterangan     string         `gorm:"type:text" json:"keterangan"`
 	CreatedAt      time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	CreatedBy      uint64         `json:"created_by"`
@@ -302,11 +336,11 @@ type ProdukDiskon struct {
 	ProdukID      uint64         `gorm:"not null" json:"produk_id"`
 	NamaDiskon    string         `gorm:"size:100;not null" json:"nama_diskon"`
 	TipeDiskon    string         `gorm:"type:varchar(20);default:'percentage'" json:"tipe_diskon"`
-	NilaiDiskon   float64        `gorm:"type:decimal(15,2);not null" json:"nilai_diskon"`
+	NilaiDiskon    money.Amount   `gorm:"type:decimal(15,2);not null" json:"nilai_diskon"`
 	TanggalMulai  time.Time      `json:"tanggal_mulai"`
 	TanggalSelesai time.Time     `json:"tanggal_selesai"`
 	MinimumBeli   int            `gorm:"default:1" json:"minimum_beli"`
-	MaksimumDiskon float64       `gorm:"type:decimal(15,2);default:0" json:"maksimum_diskon"`
+	MaksimumDiskon money.Amount   `gorm:"type:decimal(15,2);default:0" json:"maksimum_diskon"`
 	IsActive      bool           `gorm:"default:true" json:"is_active"`
 	CreatedAt     time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt     time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
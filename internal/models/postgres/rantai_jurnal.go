@@ -0,0 +1,17 @@
+package postgres
+
+import "time"
+
+// RantaiJurnal links a posted journal into its koperasi's hash chain. Hash
+// covers the journal header, its lines, Urutan and HashSebelumnya, the hash
+// of the journal posted before it, so changing or removing any posted
+// journal breaks every link after it.
+type RantaiJurnal struct {
+	ID             uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	KoperasiID     uint64    `gorm:"not null;uniqueIndex:idx_rantai_jurnal_urutan" json:"koperasi_id"`
+	Urutan         uint64    `gorm:"not null;uniqueIndex:idx_rantai_jurnal_urutan" json:"urutan"`
+	JurnalID       uint64    `gorm:"not null;uniqueIndex" json:"jurnal_id"`
+	HashSebelumnya string    `gorm:"size:64" json:"hash_sebelumnya"`
+	Hash           string    `gorm:"size:64;not null" json:"hash"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
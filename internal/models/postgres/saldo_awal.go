@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"time"
+
+	"koperasi-merah-putih/internal/money"
+)
+
+// MigrasiSaldoAwal is the opening-balance wizard of a koperasi moving onto
+// the platform. It collects the trial balance, the member savings and loan
+// balances and the open supplier and member invoices at TanggalCutover;
+// posting it writes one opening journal, the rekening and the invoices,
+// after which nothing can be booked on or before the cutover.
+type MigrasiSaldoAwal struct {
+	ID             uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID       uint64       `gorm:"not null" json:"tenant_id"`
+	KoperasiID     uint64       `gorm:"not null;uniqueIndex" json:"koperasi_id"`
+	TanggalCutover time.Time    `gorm:"type:date;not null" json:"tanggal_cutover"`
+	Status         string       `gorm:"type:varchar(10);default:'draft';index" json:"status"`
+	TotalDebit     money.Amount `gorm:"type:decimal(15,2);default:0" json:"total_debit"`
+	TotalKredit    money.Amount `gorm:"type:decimal(15,2);default:0" json:"total_kredit"`
+	JurnalID       uint64       `json:"jurnal_id"`
+	Keterangan     string       `gorm:"type:text" json:"keterangan"`
+	CreatedBy      uint64       `json:"created_by"`
+	PostedAt       *time.Time   `json:"posted_at"`
+	PostedBy       uint64       `json:"posted_by"`
+	CreatedAt      time.Time    `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time    `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Koperasi Koperasi                   `gorm:"foreignKey:KoperasiID" json:"koperasi,omitempty"`
+	Akun     []MigrasiSaldoAwalAkun     `gorm:"foreignKey:MigrasiID" json:"akun,omitempty"`
+	Rekening []MigrasiSaldoAwalRekening `gorm:"foreignKey:MigrasiID" json:"rekening,omitempty"`
+	Faktur   []MigrasiSaldoAwalFaktur   `gorm:"foreignKey:MigrasiID" json:"faktur,omitempty"`
+}
+
+// MigrasiSaldoAwalAkun is one trial balance line at the cutover.
+type MigrasiSaldoAwalAkun struct {
+	ID        uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	MigrasiID uint64       `gorm:"not null;index" json:"migrasi_id"`
+	AkunID    uint64       `gorm:"not null" json:"akun_id"`
+	Debit     money.Amount `gorm:"type:decimal(15,2);default:0" json:"debit"`
+	Kredit    money.Amount `gorm:"type:decimal(15,2);default:0" json:"kredit"`
+
+	Akun COAAkun `gorm:"foreignKey:AkunID" json:"akun,omitempty"`
+}
+
+// MigrasiSaldoAwalRekening is one member savings or loan account brought in
+// at the cutover. Saldo is the savings balance or the outstanding loan
+// principal; for loans SisaAngsuran is the number of installments left and
+// AngsuranPokok/AngsuranBunga the installment going forward. RekeningID is
+// set once the wizard is posted.
+type MigrasiSaldoAwalRekening struct {
+	ID                uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	MigrasiID         uint64       `gorm:"not null;index" json:"migrasi_id"`
+	Baris             int          `json:"baris"`
+	AnggotaID         uint64       `gorm:"not null" json:"anggota_id"`
+	ProdukID          uint64       `gorm:"not null" json:"produk_id"`
+	NomorRekening     string       `gorm:"size:50" json:"nomor_rekening"`
+	Saldo             money.Amount `gorm:"type:decimal(15,2);not null" json:"saldo"`
+	PokokPinjaman     money.Amount `gorm:"type:decimal(15,2);default:0" json:"pokok_pinjaman"`
+	TanggalMulai      *time.Time   `json:"tanggal_mulai"`
+	JangkaWaktu       int          `json:"jangka_waktu"`
+	SisaAngsuran      int          `json:"sisa_angsuran"`
+	AngsuranPokok     money.Amount `gorm:"type:decimal(15,2);default:0" json:"angsuran_pokok"`
+	AngsuranBunga     money.Amount `gorm:"type:decimal(15,2);default:0" json:"angsuran_bunga"`
+	TanggalJatuhTempo *time.Time   `json:"tanggal_jatuh_tempo"`
+	RekeningID        uint64       `json:"rekening_id"`
+
+	Anggota AnggotaKoperasi    `gorm:"foreignKey:AnggotaID" json:"anggota,omitempty"`
+	Produk  ProdukSimpanPinjam `gorm:"foreignKey:ProdukID" json:"produk,omitempty"`
+}
+
+// MigrasiSaldoAwalFaktur is one invoice still open at the cutover: a
+// supplier invoice (hutang) or a member credit sale (piutang). Sisa is what
+// was still owed on it. PembelianHeaderID or PenjualanHeaderID is set once
+// the wizard is posted.
+type MigrasiSaldoAwalFaktur struct {
+	ID                uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	MigrasiID         uint64       `gorm:"not null;index" json:"migrasi_id"`
+	Baris             int          `json:"baris"`
+	Jenis             string       `gorm:"type:varchar(10);not null" json:"jenis"`
+	SupplierID        uint64       `json:"supplier_id"`
+	AnggotaID         uint64       `json:"anggota_id"`
+	NomorFaktur       string       `gorm:"size:50;not null" json:"nomor_faktur"`
+	TanggalFaktur     time.Time    `gorm:"type:date;not null" json:"tanggal_faktur"`
+	TanggalJatuhTempo *time.Time   `gorm:"type:date" json:"tanggal_jatuh_tempo"`
+	Sisa              money.Amount `gorm:"type:decimal(15,2);not null" json:"sisa"`
+	PembelianHeaderID uint64       `json:"pembelian_header_id"`
+	PenjualanHeaderID uint64       `json:"penjualan_header_id"`
+
+	Supplier Supplier        `gorm:"foreignKey:SupplierID" json:"supplier,omitempty"`
+	Anggota  AnggotaKoperasi `gorm:"foreignKey:AnggotaID" json:"anggota,omitempty"`
+}
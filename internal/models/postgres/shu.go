@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"time"
+
+	"koperasi-merah-putih/internal/money"
+)
+
+type SHUConfig struct {
+	ID               uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID         uint64    `gorm:"not null" json:"tenant_id"`
+	KoperasiID       uint64    `gorm:"not null;uniqueIndex" json:"koperasi_id"`
+	AkunSHUID        uint64    `gorm:"not null" json:"akun_shu_id"`
+	ProdukSimpananID uint64    `gorm:"not null" json:"produk_simpanan_id"`
+	AkunSimpananID   uint64    `gorm:"not null" json:"akun_simpanan_id"`
+	CreatedAt        time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Koperasi       Koperasi           `gorm:"foreignKey:KoperasiID" json:"koperasi,omitempty"`
+	AkunSHU        COAAkun            `gorm:"foreignKey:AkunSHUID" json:"akun_shu,omitempty"`
+	ProdukSimpanan ProdukSimpanPinjam `gorm:"foreignKey:ProdukSimpananID" json:"produk_simpanan,omitempty"`
+	AkunSimpanan   COAAkun            `gorm:"foreignKey:AkunSimpananID" json:"akun_simpanan,omitempty"`
+	Alokasi        []SHUConfigAlokasi `gorm:"foreignKey:SHUConfigID" json:"alokasi,omitempty"`
+}
+
+// SHUConfigAlokasi is one AD/ART allocation line. The jasa_modal and
+// jasa_usaha lines are shared out to members; AkunID is the account credited
+// with the allocation (for member shares, the SHU payable account).
+type SHUConfigAlokasi struct {
+	ID          uint64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	SHUConfigID uint64  `gorm:"not null;index" json:"shu_config_id"`
+	KodeAlokasi string  `gorm:"type:varchar(30);not null" json:"kode_alokasi"`
+	Nama        string  `gorm:"size:100;not null" json:"nama"`
+	Persen      float64 `gorm:"type:decimal(5,2);not null" json:"persen"`
+	AkunID      uint64  `gorm:"not null" json:"akun_id"`
+
+	Akun COAAkun `gorm:"foreignKey:AkunID" json:"akun,omitempty"`
+}
+
+type SHUPerhitungan struct {
+	ID                uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID          uint64       `gorm:"not null" json:"tenant_id"`
+	KoperasiID        uint64       `gorm:"not null;uniqueIndex:idx_shu_koperasi_tahun" json:"koperasi_id"`
+	Tahun             int          `gorm:"not null;uniqueIndex:idx_shu_koperasi_tahun" json:"tahun"`
+	SHUBersih         money.Amount `gorm:"type:decimal(15,2);default:0" json:"shu_bersih"`
+	TotalRataSimpanan money.Amount `gorm:"type:decimal(15,2);default:0" json:"total_rata_simpanan"`
+	TotalTransaksi    money.Amount `gorm:"type:decimal(15,2);default:0" json:"total_transaksi"`
+	Status            string       `gorm:"type:varchar(20);default:'draft';index" json:"status"`
+	CalculatedBy      uint64       `json:"calculated_by"`
+	ApprovedBy        uint64       `json:"approved_by"`
+	ApprovedAt        *time.Time   `json:"approved_at"`
+	PaidBy            uint64       `json:"paid_by"`
+	PaidAt            *time.Time   `json:"paid_at"`
+	JurnalID          uint64       `json:"jurnal_id"`
+	CreatedAt         time.Time    `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time    `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Koperasi Koperasi     `gorm:"foreignKey:KoperasiID" json:"koperasi,omitempty"`
+	Alokasi  []SHUAlokasi `gorm:"foreignKey:SHUPerhitunganID" json:"alokasi,omitempty"`
+	Anggota  []SHUAnggota `gorm:"foreignKey:SHUPerhitunganID" json:"anggota,omitempty"`
+}
+
+type SHUAlokasi struct {
+	ID               uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	SHUPerhitunganID uint64       `gorm:"not null;index" json:"shu_perhitungan_id"`
+	KodeAlokasi      string       `gorm:"type:varchar(30);not null" json:"kode_alokasi"`
+	Nama             string       `gorm:"size:100;not null" json:"nama"`
+	Persen           float64      `gorm:"type:decimal(5,2);not null" json:"persen"`
+	Jumlah           money.Amount `gorm:"type:decimal(15,2);default:0" json:"jumlah"`
+	AkunID           uint64       `gorm:"not null" json:"akun_id"`
+}
+
+type SHUAnggota struct {
+	ID                      uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	SHUPerhitunganID        uint64       `gorm:"not null;index" json:"shu_perhitungan_id"`
+	AnggotaID               uint64       `gorm:"not null;index" json:"anggota_id"`
+	RataRataSimpanan        money.Amount `gorm:"type:decimal(15,2);default:0" json:"rata_rata_simpanan"`
+	TotalPenjualan          money.Amount `gorm:"type:decimal(15,2);default:0" json:"total_penjualan"`
+	TotalBungaPinjaman      money.Amount `gorm:"type:decimal(15,2);default:0" json:"total_bunga_pinjaman"`
+	TotalPPOB               money.Amount `gorm:"type:decimal(15,2);default:0" json:"total_ppob"`
+	JasaModal               money.Amount `gorm:"type:decimal(15,2);default:0" json:"jasa_modal"`
+	JasaUsaha               money.Amount `gorm:"type:decimal(15,2);default:0" json:"jasa_usaha"`
+	TotalSHU                money.Amount `gorm:"type:decimal(15,2);default:0" json:"total_shu"`
+	RekeningID              uint64       `json:"rekening_id"`
+	TransaksiSimpanPinjamID uint64       `json:"transaksi_simpan_pinjam_id"`
+
+	Anggota AnggotaKoperasi `gorm:"foreignKey:AnggotaID" json:"anggota,omitempty"`
+}
@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+
+	"koperasi-merah-putih/internal/money"
 )
 
 type ProdukSimpanPinjam struct {
@@ -14,10 +16,10 @@ type ProdukSimpanPinjam struct {
 	Jenis             string         `gorm:"type:varchar(20);not null" json:"jenis"`
 	Kategori          string         `gorm:"size:100" json:"kategori"`
 	BungaSimpanan     float64        `gorm:"type:decimal(5,2);default:0" json:"bunga_simpanan"`
-	MinimalSaldo      float64        `gorm:"type:decimal(15,2);default:0" json:"minimal_saldo"`
+	MinimalSaldo     money.Amount   `gorm:"type:decimal(15,2);default:0" json:"minimal_saldo"`
 	BungaPinjaman     float64        `gorm:"type:decimal(5,2);default:0" json:"bunga_pinjaman"`
 	BungaDenda        float64        `gorm:"type:decimal(5,2);default:0" json:"bunga_denda"`
-	MaksimalPinjaman  float64        `gorm:"type:decimal(15,2);default:0" json:"maksimal_pinjaman"`
+	MaksimalPinjaman money.Amount   `gorm:"type:decimal(15,2);default:0" json:"maksimal_pinjaman"`
 	JangkaWaktuMax    int            `gorm:"default:0" json:"jangka_waktu_max"`
 	SyaratKetentuan   string         `gorm:"type:text" json:"syarat_ketentuan"`
 	IsAktif           bool           `gorm:"default:true" json:"is_aktif"`
@@ -34,16 +36,16 @@ type RekeningSimpanPinjam struct {
 	AnggotaID             uint64     `gorm:"not null;index" json:"anggota_id"`
 	ProdukID              uint64     `gorm:"not null" json:"produk_id"`
 	NomorRekening         string     `gorm:"size:50;not null" json:"nomor_rekening"`
-	SaldoSimpanan         float64    `gorm:"type:decimal(15,2);default:0" json:"saldo_simpanan"`
-	PokokPinjaman         float64    `gorm:"type:decimal(15,2);default:0" json:"pokok_pinjaman"`
-	SisaPokok             float64    `gorm:"type:decimal(15,2);default:0" json:"sisa_pokok"`
-	BungaBerjalan         float64    `gorm:"type:decimal(15,2);default:0" json:"bunga_berjalan"`
-	DendaKeterlambatan    float64    `gorm:"type:decimal(15,2);default:0" json:"denda_keterlambatan"`
+	SaldoSimpanan      money.Amount `gorm:"type:decimal(15,2);default:0" json:"saldo_simpanan"`
+	PokokPinjaman      money.Amount `gorm:"type:decimal(15,2);default:0" json:"pokok_pinjaman"`
+	SisaPokok          money.Amount `gorm:"type:decimal(15,2);default:0" json:"sisa_pokok"`
+	BungaBerjalan      money.Amount `gorm:"type:decimal(15,2);default:0" json:"bunga_berjalan"`
+	DendaKeterlambatan money.Amount `gorm:"type:decimal(15,2);default:0" json:"denda_keterlambatan"`
 	TanggalMulai          *time.Time `json:"tanggal_mulai"`
 	TanggalJatuhTempo     *time.Time `json:"tanggal_jatuh_tempo"`
 	JangkaWaktu           int        `json:"jangka_waktu"`
-	AngsuranPokok         float64    `gorm:"type:decimal(15,2);default:0" json:"angsuran_pokok"`
-	AngsuranBunga         float64    `gorm:"type:decimal(15,2);default:0" json:"angsuran_bunga"`
+	AngsuranPokok      money.Amount `gorm:"type:decimal(15,2);default:0" json:"angsuran_pokok"`
+	AngsuranBunga      money.Amount `gorm:"type:decimal(15,2);default:0" json:"angsuran_bunga"`
 	Status                string     `gorm:"type:varchar(20);default:'aktif';index" json:"status"`
 	TanggalBuka           time.Time  `gorm:"default:CURRENT_DATE" json:"tanggal_buka"`
 	TanggalTutup          *time.Time `json:"tanggal_tutup"`
@@ -62,9 +64,9 @@ type TransaksiSimpanPinjam struct {
 	NomorTransaksi    string    `gorm:"size:50;not null" json:"nomor_transaksi"`
 	TanggalTransaksi  time.Time `gorm:"default:CURRENT_TIMESTAMP;index" json:"tanggal_transaksi"`
 	JenisTransaksi    string    `gorm:"type:varchar(20);not null;index" json:"jenis_transaksi"`
-	Jumlah            float64   `gorm:"type:decimal(15,2);not null" json:"jumlah"`
-	SaldoSebelum      float64   `gorm:"type:decimal(15,2);default:0" json:"saldo_sebelum"`
-	SaldoSesudah      float64   `gorm:"type:decimal(15,2);default:0" json:"saldo_sesudah"`
+	Jumlah           money.Amount `gorm:"type:decimal(15,2);not null" json:"jumlah"`
+	SaldoSebelum     money.Amount `gorm:"type:decimal(15,2);default:0" json:"saldo_sebelum"`
+	SaldoSesudah     money.Amount `gorm:"type:decimal(15,2);default:0" json:"saldo_sesudah"`
 	Keterangan        string    `gorm:"size:255" json:"keterangan"`
 	Referensi         string    `gorm:"size:100" json:"referensi"`
 	JurnalID          uint64    `json:"jurnal_id"`
@@ -2,6 +2,8 @@ package postgres
 
 import (
 	"time"
+
+	"koperasi-merah-putih/internal/money"
 )
 
 type User struct {
@@ -63,7 +65,7 @@ type UserRegistration struct {
 	Email                string     `gorm:"size:255;not null" json:"email"`
 	Username             string     `gorm:"size:100;not null;uniqueIndex" json:"username"`
 	PasswordHash         string     `gorm:"size:255;not null" json:"password_hash"`
-	SimpananPokokAmount  float64    `gorm:"type:decimal(15,2);not null" json:"simpanan_pokok_amount"`
+	SimpananPokokAmount money.Amount `gorm:"type:decimal(15,2);not null" json:"simpanan_pokok_amount"`
 	PaymentID            uint64     `json:"payment_id"`
 	Status               string     `gorm:"type:varchar(20);default:'pending_payment';index" json:"status"`
 	VerificationToken    string     `gorm:"size:100" json:"verification_token"`
@@ -98,7 +100,7 @@ type UserRegistrationLog struct {
 type SimpananPokokConfig struct {
 	ID                     uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
 	KoperasiID             uint64    `gorm:"not null;uniqueIndex" json:"koperasi_id"`
-	JumlahSimpananPokok    float64   `gorm:"type:decimal(15,2);not null" json:"jumlah_simpanan_pokok"`
+	JumlahSimpananPokok   money.Amount `gorm:"type:decimal(15,2);not null" json:"jumlah_simpanan_pokok"`
 	IsWajib                bool      `gorm:"default:true" json:"is_wajib"`
 	AllowedPaymentMethods  string    `gorm:"type:json" json:"allowed_payment_methods"`
 	PaymentDeadlineDays    int       `gorm:"default:7" json:"payment_deadline_days"`
@@ -118,7 +120,7 @@ type SimpananPokokTransaksi struct {
 	AnggotaID        uint64     `json:"anggota_id"`
 	RegistrationID   uint64     `gorm:"index" json:"registration_id"`
 	NomorTransaksi   string     `gorm:"size:50;not null" json:"nomor_transaksi"`
-	Jumlah           float64    `gorm:"type:decimal(15,2);not null" json:"jumlah"`
+	Jumlah           money.Amount `gorm:"type:decimal(15,2);not null" json:"jumlah"`
 	PaymentID        uint64     `json:"payment_id"`
 	Status           string     `gorm:"type:varchar(10);default:'pending';index" json:"status"`
 	TanggalTransaksi time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"tanggal_transaksi"`
@@ -0,0 +1,380 @@
+// Package money holds rupiah amounts as exact whole sen so that ledger
+// arithmetic never drifts the way float64 does. Amount maps to the
+// decimal(15,2) columns and reads and writes JSON as a plain number, the same
+// wire format the API used when amounts were float64.
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Amount is a rupiah amount in sen (1/100 rupiah). The zero value is zero
+// rupiah. Amounts add, subtract and compare with the ordinary operators;
+// anything involving a rate goes through Mul, Percent or Div with a Rule.
+type Amount int64
+
+const (
+	Sen    Amount = 1
+	Rupiah Amount = 100
+)
+
+// Mode is the direction a result between two units is rounded.
+type Mode int
+
+const (
+	// HalfUp rounds to the nearest unit, halves away from zero.
+	HalfUp Mode = iota
+	// Down rounds towards zero.
+	Down
+	// Up rounds away from zero.
+	Up
+)
+
+// Rule is a rounding rule: results are rounded to a multiple of Unit using
+// Mode.
+type Rule struct {
+	Unit Amount
+	Mode Mode
+}
+
+// Rounding rules used across the services. Interest and discounts are
+// rounded to the nearest sen. Tax is rounded down to whole rupiah, as tax
+// invoices and withholding slips are issued in whole rupiah.
+var (
+	RoundBunga  = Rule{Unit: Sen, Mode: HalfUp}
+	RoundDiskon = Rule{Unit: Sen, Mode: HalfUp}
+	RoundPajak  = Rule{Unit: Rupiah, Mode: Down}
+	RoundSen    = Rule{Unit: Sen, Mode: HalfUp}
+)
+
+// FromFloat converts a float amount in rupiah, rounding half away from zero
+// to the sen. It is meant for boundaries that still deal in float64, such as
+// gateway payloads.
+func FromFloat(rupiah float64) Amount {
+	return Amount(math.Round(rupiah * 100))
+}
+
+// FromInt converts a whole rupiah amount.
+func FromInt(rupiah int64) Amount {
+	return Amount(rupiah) * Rupiah
+}
+
+// Parse reads a decimal rupiah amount such as "1500", "-12.5" or
+// "1234.567". Digits beyond the sen are rounded half up. Only plain decimal
+// notation is accepted: no fractions, exponents or digit grouping.
+func Parse(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	if !isDecimal(s) {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return parseNumber(s)
+}
+
+// parseNumber reads s, which must already be a valid decimal or JSON number.
+func parseNumber(s string) (Amount, error) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return fromRat(r.Mul(r, big.NewRat(100, 1)), RoundSen)
+}
+
+// isDecimal reports whether s is an optionally signed run of digits with at
+// most one decimal point, e.g. "12", "-0.5" or ".75".
+func isDecimal(s string) bool {
+	if s[0] == '-' || s[0] == '+' {
+		s = s[1:]
+	}
+	digits, point := 0, false
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '.' && !point:
+			point = true
+		default:
+			return false
+		}
+	}
+	return digits > 0
+}
+
+// MustParse is Parse for constants; it panics on malformed input.
+func MustParse(s string) Amount {
+	a, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// Float64 returns the amount in rupiah as a float, for ratios and display
+// only. Never feed the result back into ledger arithmetic.
+func (a Amount) Float64() float64 {
+	return float64(a) / 100
+}
+
+// Rupiah returns the whole rupiah part, truncated towards zero.
+func (a Amount) Rupiah() int64 {
+	return int64(a / Rupiah)
+}
+
+// String formats the amount with exactly two decimals, e.g. "1500.00".
+func (a Amount) String() string {
+	sign := ""
+	n := int64(a)
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, n/100, n%100)
+}
+
+func (a Amount) Abs() Amount {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func (a Amount) IsZero() bool {
+	return a == 0
+}
+
+// MulInt multiplies by a whole quantity, exactly. It panics when the
+// result does not fit.
+func (a Amount) MulInt(n int64) Amount {
+	result := a * Amount(n)
+	if n != 0 && (result/Amount(n) != a || n == -1 && a == math.MinInt64) {
+		panic(fmt.Sprintf("money: %s times %d is out of range", a, n))
+	}
+	return result
+}
+
+// Mul multiplies by factor and rounds the result with rule. The factor is
+// taken at its shortest decimal form, so 0.11 is exactly eleven hundredths.
+// It panics when factor is not finite or the result does not fit.
+func (a Amount) Mul(factor float64, rule Rule) Amount {
+	f := rate(factor)
+	r := new(big.Rat).SetInt64(int64(a))
+	return mustFromRat(r.Mul(r, f), rule)
+}
+
+// Percent returns persen percent of the amount, rounded with rule. Like
+// Mul, it panics on a rate that is not finite or a result that does not fit.
+func (a Amount) Percent(persen float64, rule Rule) Amount {
+	f := rate(persen)
+	r := new(big.Rat).SetInt64(int64(a))
+	r.Mul(r, f)
+	return mustFromRat(r.Quo(r, big.NewRat(100, 1)), rule)
+}
+
+// Div divides by n and rounds the result with rule. Use Split when the parts
+// must add back up to the amount. Dividing by zero gives zero.
+func (a Amount) Div(n int64, rule Rule) Amount {
+	if n == 0 {
+		return 0
+	}
+	return mustFromRat(big.NewRat(int64(a), n), rule)
+}
+
+// Prorate returns the share of the amount that bagian is of total, i.e.
+// a * bagian / total, rounded with rule. It returns 0 when total is zero.
+func (a Amount) Prorate(bagian, total Amount, rule Rule) Amount {
+	if total == 0 {
+		return 0
+	}
+	r := new(big.Rat).SetInt64(int64(a))
+	r.Mul(r, big.NewRat(int64(bagian), int64(total)))
+	return mustFromRat(r, rule)
+}
+
+// Ratio returns a/b as a float, or 0 when b is zero.
+func (a Amount) Ratio(b Amount) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
+
+// Round rounds the amount itself to a multiple of rule.Unit.
+func (a Amount) Round(rule Rule) Amount {
+	return mustFromRat(new(big.Rat).SetInt64(int64(a)), rule)
+}
+
+// Split divides the amount into n parts of whole sen that add up exactly to
+// the amount; the first parts take the leftover sen.
+func (a Amount) Split(n int) []Amount {
+	if n <= 0 {
+		return nil
+	}
+	parts := make([]Amount, n)
+	base := a / Amount(n)
+	sisa := a - base*Amount(n)
+	step := Amount(1)
+	if sisa < 0 {
+		step = -1
+	}
+	for i := range parts {
+		parts[i] = base
+		if sisa != 0 {
+			parts[i] += step
+			sisa -= step
+		}
+	}
+	return parts
+}
+
+func Sum(amounts ...Amount) Amount {
+	var total Amount
+	for _, a := range amounts {
+		total += a
+	}
+	return total
+}
+
+func Min(a, b Amount) Amount {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func Max(a, b Amount) Amount {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// rate converts a float rate at its shortest decimal form.
+func rate(f float64) *big.Rat {
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(f, 'f', -1, 64))
+	if !ok {
+		panic(fmt.Sprintf("money: invalid rate %v", f))
+	}
+	return r
+}
+
+// mustFromRat is fromRat for arithmetic on amounts that are already valid.
+// A result out of range means corrupt input or a wrong rate, so it panics
+// rather than posting a silent zero.
+func mustFromRat(r *big.Rat, rule Rule) Amount {
+	result, err := fromRat(r, rule)
+	if err != nil {
+		panic(fmt.Sprintf("money: %s is out of range", r.FloatString(2)))
+	}
+	return result
+}
+
+// fromRat rounds r, a value in sen, to a multiple of rule.Unit.
+func fromRat(r *big.Rat, rule Rule) (Amount, error) {
+	unit := int64(rule.Unit)
+	if unit <= 0 {
+		unit = 1
+	}
+	q := new(big.Rat).Quo(r, big.NewRat(unit, 1))
+
+	num := q.Num()
+	den := q.Denom()
+	whole, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() != 0 {
+		switch rule.Mode {
+		case Up:
+			whole.Add(whole, big.NewInt(int64(num.Sign())))
+		case HalfUp:
+			twice := new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2))
+			if twice.Cmp(den) >= 0 {
+				whole.Add(whole, big.NewInt(int64(num.Sign())))
+			}
+		}
+	}
+	whole.Mul(whole, big.NewInt(unit))
+	if !whole.IsInt64() {
+		return 0, fmt.Errorf("amount out of range")
+	}
+	return Amount(whole.Int64()), nil
+}
+
+// MarshalJSON writes the amount as a JSON number with trailing zero
+// decimals dropped, e.g. 1500 or 1500.5, as float64 amounts used to encode.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	s := a.String()
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || s == "-" {
+		s = "0"
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalJSON accepts a number, a numeric string or null.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := strings.TrimSpace(string(data))
+	if s == "null" {
+		return nil
+	}
+	if !strings.HasPrefix(s, `"`) {
+		// An unquoted value is a JSON number, which may use an exponent
+		// the way float64 amounts were sometimes encoded.
+		parsed, err := parseNumber(s)
+		if err != nil {
+			return err
+		}
+		*a = parsed
+		return nil
+	}
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	parsed, err := Parse(str)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// Value stores the amount as an exact decimal string.
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Scan reads a numeric column. NULL scans as zero.
+func (a *Amount) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*a = 0
+	case []byte:
+		parsed, err := Parse(string(v))
+		if err != nil {
+			return err
+		}
+		*a = parsed
+	case string:
+		parsed, err := Parse(v)
+		if err != nil {
+			return err
+		}
+		*a = parsed
+	case float64:
+		*a = FromFloat(v)
+	case float32:
+		*a = FromFloat(float64(v))
+	case int64:
+		*a = FromInt(v)
+	default:
+		return fmt.Errorf("cannot scan %T into money.Amount", value)
+	}
+	return nil
+}
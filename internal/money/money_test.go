@@ -0,0 +1,210 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Amount
+	}{
+		{"1500", 150000},
+		{"1500.00", 150000},
+		{"-12.5", -1250},
+		{"1234.567", 123457},
+		{"0.005", 1},
+		{"0.004", 0},
+		{"-0.005", -1},
+		{" 7 ", 700},
+		{"+5", 500},
+		{".75", 75},
+		{"3.", 300},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): unexpected error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"abc", "12,50", "1/3", "1e3", "0x10", "--1", ".", "-", "1.2.3", "100000000000000000000", "-100000000000000000000"} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q): expected an error", in)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		in   Amount
+		want string
+	}{
+		{150000, "1500.00"},
+		{150050, "1500.50"},
+		{1, "0.01"},
+		{-1250, "-12.50"},
+		{-1, "-0.01"},
+		{0, "0.00"},
+	}
+	for _, tt := range tests {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("Amount(%d).String() = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestJSONMatchesFloat checks that amounts encode the same as the float64
+// fields they replaced, so API clients see no difference.
+func TestJSONMatchesFloat(t *testing.T) {
+	for _, rupiah := range []float64{0, 1500, 1500.5, 1500.25, 0.01, 100, -12.5, 1234567.89} {
+		got, err := json.Marshal(struct{ Jumlah Amount }{FromFloat(rupiah)})
+		if err != nil {
+			t.Fatalf("marshal %v: %v", rupiah, err)
+		}
+		want, _ := json.Marshal(struct{ Jumlah float64 }{rupiah})
+		if string(got) != string(want) {
+			t.Errorf("marshal %v = %s, want %s", rupiah, got, want)
+		}
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Amount
+	}{
+		{`1500`, 150000},
+		{`1500.5`, 150050},
+		{`"1500.5"`, 150050},
+		{`-12.5`, -1250},
+		{`0.015`, 2},
+		{`1.5e3`, 150000},
+	}
+	for _, tt := range tests {
+		var got Amount
+		if err := json.Unmarshal([]byte(tt.in), &got); err != nil {
+			t.Errorf("unmarshal %s: unexpected error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("unmarshal %s = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+
+	got := Amount(700)
+	if err := json.Unmarshal([]byte(`null`), &got); err != nil || got != 700 {
+		t.Errorf("unmarshal null = %d, %v, want 700 unchanged", got, err)
+	}
+
+	for _, in := range []string{`"abc"`, `"1/3"`, `"1e3"`, `true`, `1e30`} {
+		var a Amount
+		if err := json.Unmarshal([]byte(in), &a); err == nil {
+			t.Errorf("unmarshal %s: expected an error", in)
+		}
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount Amount
+		persen float64
+		rule   Rule
+		want   Amount
+	}{
+		{"half up to sen", 12345, 11, Rule{Sen, HalfUp}, 1358},
+		{"down to sen", 12345, 11, Rule{Sen, Down}, 1357},
+		{"up to sen", 12345, 11, Rule{Sen, Up}, 1358},
+		{"exact half rounds up", 50, 1, Rule{Sen, HalfUp}, 1},
+		{"exact half down", 50, 1, Rule{Sen, Down}, 0},
+		{"negative half up", -12345, 11, Rule{Sen, HalfUp}, -1358},
+		{"negative down", -12345, 11, Rule{Sen, Down}, -1357},
+		{"negative up", -12345, 11, Rule{Sen, Up}, -1358},
+		{"negative exact half", -50, 1, Rule{Sen, HalfUp}, -1},
+		{"pajak to rupiah", FromInt(1001), 11, RoundPajak, FromInt(110)},
+		{"up to rupiah", FromInt(1001), 11, Rule{Rupiah, Up}, FromInt(111)},
+		{"fractional rate", FromInt(1000000), 0.5, RoundPajak, FromInt(5000)},
+		{"rate is exact", FromInt(100), 1.1, RoundSen, 110},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.amount.Percent(tt.persen, tt.rule); got != tt.want {
+				t.Errorf("Amount(%d).Percent(%v) = %d, want %d", tt.amount, tt.persen, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProrate(t *testing.T) {
+	tests := []struct {
+		name          string
+		amount        Amount
+		bagian, total Amount
+		rule          Rule
+		want          Amount
+	}{
+		{"half up", FromInt(100), 2, 3, Rule{Sen, HalfUp}, 6667},
+		{"down", FromInt(100), 2, 3, Rule{Sen, Down}, 6666},
+		{"up", FromInt(100), 1, 3, Rule{Sen, Up}, 3334},
+		{"negative half up", -FromInt(100), 2, 3, Rule{Sen, HalfUp}, -6667},
+		{"negative down", -FromInt(100), 2, 3, Rule{Sen, Down}, -6666},
+		{"negative up", -FromInt(100), 1, 3, Rule{Sen, Up}, -3334},
+		{"to rupiah", FromInt(100), 2, 3, RoundPajak, FromInt(66)},
+		{"zero total", FromInt(100), 2, 0, RoundSen, 0},
+		{"whole", FromInt(100), 3, 3, RoundSen, FromInt(100)},
+		{"large operands do not overflow", math.MaxInt64 / 2, math.MaxInt64 / 2, math.MaxInt64 / 2, RoundSen, math.MaxInt64 / 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.amount.Prorate(tt.bagian, tt.total, tt.rule); got != tt.want {
+				t.Errorf("Amount(%d).Prorate(%d, %d) = %d, want %d", tt.amount, tt.bagian, tt.total, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	for _, tt := range []struct {
+		amount Amount
+		n      int
+	}{{FromInt(100), 3}, {-FromInt(100), 3}, {1, 4}, {0, 2}} {
+		parts := tt.amount.Split(tt.n)
+		if len(parts) != tt.n || Sum(parts...) != tt.amount {
+			t.Errorf("Amount(%d).Split(%d) = %v, does not add back up", tt.amount, tt.n, parts)
+		}
+	}
+}
+
+func TestOutOfRangePanics(t *testing.T) {
+	tests := map[string]func(){
+		"Mul":            func() { Amount(math.MaxInt64).Mul(2, RoundSen) },
+		"Mul by NaN":     func() { Amount(100).Mul(math.NaN(), RoundSen) },
+		"Percent":        func() { Amount(math.MaxInt64).Percent(200, RoundSen) },
+		"Percent by Inf": func() { Amount(100).Percent(math.Inf(1), RoundSen) },
+		"Round up":       func() { Amount(math.MaxInt64).Round(Rule{Rupiah, Up}) },
+		"MulInt":         func() { Amount(math.MaxInt64 / 2).MulInt(3) },
+		"MulInt by -1":   func() { Amount(math.MinInt64).MulInt(-1) },
+	}
+	for name, fn := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic")
+				}
+			}()
+			fn()
+		})
+	}
+
+	if got := Amount(-FromInt(100)).MulInt(-3); got != FromInt(300) {
+		t.Errorf("MulInt = %d, want %d", got, FromInt(300))
+	}
+}
@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"gorm.io/gorm"
+	"koperasi-merah-putih/internal/models/postgres"
+
+	"koperasi-merah-putih/internal/money"
+)
+
+type AnggaranRepository struct {
+	db *gorm.DB
+}
+
+func NewAnggaranRepository(db *gorm.DB) *AnggaranRepository {
+	return &AnggaranRepository{db: db}
+}
+
+func (r *AnggaranRepository) WithTx(tx *gorm.DB) *AnggaranRepository {
+	return &AnggaranRepository{db: tx}
+}
+
+func (r *AnggaranRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
+func (r *AnggaranRepository) CreateAnggaran(anggaran *postgres.Anggaran) error {
+	return r.db.Create(anggaran).Error
+}
+
+func (r *AnggaranRepository) GetAnggaranByID(id uint64) (*postgres.Anggaran, error) {
+	var anggaran postgres.Anggaran
+	err := r.db.Preload("Details", func(db *gorm.DB) *gorm.DB {
+		return db.Order("akun_id ASC, bulan ASC")
+	}).Preload("Details.Akun").
+		First(&anggaran, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &anggaran, nil
+}
+
+// GetAnggaranByKoperasi lists every version, newest first. A zero tahun
+// returns all years.
+func (r *AnggaranRepository) GetAnggaranByKoperasi(koperasiID uint64, tahun int) ([]postgres.Anggaran, error) {
+	var anggarans []postgres.Anggaran
+	query := r.db.Where("koperasi_id = ?", koperasiID)
+	if tahun != 0 {
+		query = query.Where("tahun = ?", tahun)
+	}
+	err := query.Order("tahun DESC, versi DESC").Find(&anggarans).Error
+	return anggarans, err
+}
+
+// GetAnggaranApproved returns the version currently in force for the year.
+func (r *AnggaranRepository) GetAnggaranApproved(koperasiID uint64, tahun int) (*postgres.Anggaran, error) {
+	var anggaran postgres.Anggaran
+	err := r.db.Where("koperasi_id = ? AND tahun = ? AND status = ?", koperasiID, tahun, "approved").
+		Preload("Details").
+		First(&anggaran).Error
+	if err != nil {
+		return nil, err
+	}
+	return &anggaran, nil
+}
+
+func (r *AnggaranRepository) GetMaxVersi(koperasiID uint64, tahun int) (int, error) {
+	var versi int
+	err := r.db.Model(&postgres.Anggaran{}).
+		Where("koperasi_id = ? AND tahun = ?", koperasiID, tahun).
+		Select("COALESCE(MAX(versi), 0)").
+		Scan(&versi).Error
+	return versi, err
+}
+
+func (r *AnggaranRepository) UpdateAnggaran(id uint64, updates map[string]interface{}) error {
+	return r.db.Model(&postgres.Anggaran{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// ReplaceDetails swaps the budget lines of a draft version.
+func (r *AnggaranRepository) ReplaceDetails(anggaranID uint64, details []postgres.AnggaranDetail) error {
+	if err := r.db.Where("anggaran_id = ?", anggaranID).Delete(&postgres.AnggaranDetail{}).Error; err != nil {
+		return err
+	}
+
+	for i := range details {
+		details[i].ID = 0
+		details[i].AnggaranID = anggaranID
+	}
+
+	return r.db.Create(&details).Error
+}
+
+// SupersedeApproved retires the approved version of the year so another one
+// can take its place.
+func (r *AnggaranRepository) SupersedeApproved(koperasiID uint64, tahun int) error {
+	return r.db.Model(&postgres.Anggaran{}).
+		Where("koperasi_id = ? AND tahun = ? AND status = ?", koperasiID, tahun, "approved").
+		Update("status", "superseded").Error
+}
+
+// GetRealisasiBulanan sums posted movements per pendapatan and beban akun per
+// month of tahun, leaving out year-end closing journals.
+func (r *AnggaranRepository) GetRealisasiBulanan(koperasiID uint64, tahun int) ([]RealisasiAkunBulan, error) {
+	var items []RealisasiAkunBulan
+
+	err := r.db.Table("jurnal_details jd").
+		Select(`
+			ca.id as akun_id,
+			ca.kode_akun,
+			ca.nama_akun,
+			ca.saldo_normal,
+			cat.tipe as kategori_tipe,
+			CAST(EXTRACT(MONTH FROM ju.tanggal_transaksi) AS INTEGER) as bulan,
+			COALESCE(SUM(jd.debit), 0) as total_debit,
+			COALESCE(SUM(jd.kredit), 0) as total_kredit
+		`).
+		Joins("JOIN jurnal_umums ju ON jd.jurnal_id = ju.id").
+		Joins("JOIN coa_akuns ca ON jd.akun_id = ca.id").
+		Joins("JOIN coa_kategoris cat ON ca.kategori_id = cat.id").
+		Where("ju.koperasi_id = ? AND ju.status IN ('posted', 'reversed') AND EXTRACT(YEAR FROM ju.tanggal_transaksi) = ?",
+			koperasiID, tahun).
+		Where("ju.sumber_transaksi <> ? AND cat.tipe IN ?", "tutup_buku", []string{"pendapatan", "beban"}).
+		Group("ca.id, ca.kode_akun, ca.nama_akun, ca.saldo_normal, cat.tipe, bulan").
+		Order("ca.kode_akun, bulan").
+		Scan(&items).Error
+
+	return items, err
+}
+
+type RealisasiAkunBulan struct {
+	AkunID       uint64       `json:"akun_id"`
+	KodeAkun     string       `json:"kode_akun"`
+	NamaAkun     string       `json:"nama_akun"`
+	SaldoNormal  string       `json:"saldo_normal"`
+	KategoriTipe string       `json:"kategori_tipe"`
+	Bulan        int          `json:"bulan"`
+	TotalDebit   money.Amount `json:"total_debit"`
+	TotalKredit  money.Amount `json:"total_kredit"`
+}
@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"koperasi-merah-putih/internal/models/postgres"
+
+	"koperasi-merah-putih/internal/money"
+)
+
+type AsetRepository struct {
+	db *gorm.DB
+}
+
+func NewAsetRepository(db *gorm.DB) *AsetRepository {
+	return &AsetRepository{db: db}
+}
+
+func (r *AsetRepository) WithTx(tx *gorm.DB) *AsetRepository {
+	return &AsetRepository{db: tx}
+}
+
+func (r *AsetRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
+func (r *AsetRepository) CreateKategori(kategori *postgres.KategoriAset) error {
+	return r.db.Create(kategori).Error
+}
+
+func (r *AsetRepository) GetKategoriByID(id uint64) (*postgres.KategoriAset, error) {
+	var kategori postgres.KategoriAset
+	err := r.db.Preload("AkunAset").Preload("AkunAkumulasi").
+		Preload("AkunBeban").Preload("AkunLabaRugiLepas").
+		First(&kategori, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &kategori, nil
+}
+
+func (r *AsetRepository) GetKategoriByKoperasi(koperasiID uint64) ([]postgres.KategoriAset, error) {
+	var kategoris []postgres.KategoriAset
+	err := r.db.Where("koperasi_id = ?", koperasiID).
+		Order("kode ASC").Find(&kategoris).Error
+	return kategoris, err
+}
+
+func (r *AsetRepository) UpdateKategori(id uint64, updates map[string]interface{}) error {
+	return r.db.Model(&postgres.KategoriAset{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (r *AsetRepository) CreateAset(aset *postgres.AsetTetap) error {
+	return r.db.Create(aset).Error
+}
+
+func (r *AsetRepository) GetAsetByID(id uint64) (*postgres.AsetTetap, error) {
+	var aset postgres.AsetTetap
+	err := r.db.Preload("Kategori").First(&aset, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &aset, nil
+}
+
+func (r *AsetRepository) GetAsetForUpdate(id uint64) (*postgres.AsetTetap, error) {
+	var aset postgres.AsetTetap
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&aset, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.First(&aset.Kategori, aset.KategoriAsetID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &aset, nil
+}
+
+// GetAsetByKoperasi lists assets by nomor. An empty status returns all.
+func (r *AsetRepository) GetAsetByKoperasi(koperasiID uint64, status string) ([]postgres.AsetTetap, error) {
+	var asets []postgres.AsetTetap
+	query := r.db.Where("koperasi_id = ?", koperasiID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Preload("Kategori").Order("nomor_aset ASC").Find(&asets).Error
+	return asets, err
+}
+
+// GetAsetUntukPenyusutan locks the active assets acquired up to sampai whose
+// depreciation has not been charged through sampai yet.
+func (r *AsetRepository) GetAsetUntukPenyusutan(koperasiID uint64, sampai time.Time) ([]postgres.AsetTetap, error) {
+	var asets []postgres.AsetTetap
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("koperasi_id = ? AND status = ? AND tanggal_perolehan <= ?", koperasiID, "aktif", sampai).
+		Where("penyusutan_sampai IS NULL OR penyusutan_sampai < ?", sampai).
+		Order("id ASC").Find(&asets).Error
+	return asets, err
+}
+
+// GetKoperasiAsetAktif returns the koperasi that own active assets, for the
+// scheduled depreciation run.
+func (r *AsetRepository) GetKoperasiAsetAktif() ([]uint64, error) {
+	var ids []uint64
+	err := r.db.Model(&postgres.AsetTetap{}).
+		Where("status = ?", "aktif").
+		Distinct("koperasi_id").Pluck("koperasi_id", &ids).Error
+	return ids, err
+}
+
+func (r *AsetRepository) UpdateAset(id uint64, updates map[string]interface{}) error {
+	return r.db.Model(&postgres.AsetTetap{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (r *AsetRepository) CreatePenyusutan(penyusutan *postgres.PenyusutanAset) error {
+	return r.db.Create(penyusutan).Error
+}
+
+func (r *AsetRepository) GetPenyusutanByAset(asetID uint64) ([]postgres.PenyusutanAset, error) {
+	var penyusutans []postgres.PenyusutanAset
+	err := r.db.Where("aset_tetap_id = ?", asetID).
+		Order("periode ASC").Find(&penyusutans).Error
+	return penyusutans, err
+}
+
+// GetAkumulasiSampai sums the depreciation charged per asset up to tanggal.
+// AkumulasiAwal is not included.
+func (r *AsetRepository) GetAkumulasiSampai(koperasiID uint64, tanggal time.Time) (map[uint64]money.Amount, error) {
+	var rows []struct {
+		AsetTetapID uint64
+		Total       money.Amount
+	}
+
+	err := r.db.Model(&postgres.PenyusutanAset{}).
+		Select("aset_tetap_id, COALESCE(SUM(jumlah), 0) as total").
+		Where("koperasi_id = ? AND periode <= ?", koperasiID, tanggal).
+		Group("aset_tetap_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[uint64]money.Amount)
+	for _, row := range rows {
+		totals[row.AsetTetapID] = row.Total
+	}
+	return totals, nil
+}
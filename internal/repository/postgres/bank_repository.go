@@ -0,0 +1,212 @@
+package postgres
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"koperasi-merah-putih/internal/models/postgres"
+
+	"koperasi-merah-putih/internal/money"
+)
+
+type BankRepository struct {
+	db *gorm.DB
+}
+
+func NewBankRepository(db *gorm.DB) *BankRepository {
+	return &BankRepository{db: db}
+}
+
+func (r *BankRepository) WithTx(tx *gorm.DB) *BankRepository {
+	return &BankRepository{db: tx}
+}
+
+func (r *BankRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
+func (r *BankRepository) CreateRekeningBank(rekening *postgres.RekeningBank) error {
+	return r.db.Create(rekening).Error
+}
+
+func (r *BankRepository) GetRekeningBankByID(id uint64) (*postgres.RekeningBank, error) {
+	var rekening postgres.RekeningBank
+	err := r.db.Preload("Akun").First(&rekening, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rekening, nil
+}
+
+func (r *BankRepository) GetRekeningBankByKoperasi(koperasiID uint64) ([]postgres.RekeningBank, error) {
+	var rekenings []postgres.RekeningBank
+	err := r.db.Where("koperasi_id = ?", koperasiID).
+		Preload("Akun").
+		Order("nama_bank ASC").Find(&rekenings).Error
+	return rekenings, err
+}
+
+func (r *BankRepository) CreateRekeningKoran(koran *postgres.RekeningKoran) error {
+	return r.db.Create(koran).Error
+}
+
+func (r *BankRepository) GetRekeningKoranByRekening(rekeningBankID uint64) ([]postgres.RekeningKoran, error) {
+	var korans []postgres.RekeningKoran
+	err := r.db.Where("rekening_bank_id = ?", rekeningBankID).
+		Order("tanggal_awal DESC").Find(&korans).Error
+	return korans, err
+}
+
+// GetRekeningKoranAwal returns the earliest imported statement, whose opening
+// balance anchors the bank side of the reconciliation.
+func (r *BankRepository) GetRekeningKoranAwal(rekeningBankID uint64) (*postgres.RekeningKoran, error) {
+	var koran postgres.RekeningKoran
+	err := r.db.Where("rekening_bank_id = ?", rekeningBankID).
+		Order("tanggal_awal ASC, id ASC").First(&koran).Error
+	if err != nil {
+		return nil, err
+	}
+	return &koran, nil
+}
+
+// LineExists reports whether an identical statement line was imported
+// before, so overlapping statement files do not double count.
+func (r *BankRepository) LineExists(rekeningBankID uint64, line *postgres.RekeningKoranLine) (bool, error) {
+	var count int64
+	err := r.db.Model(&postgres.RekeningKoranLine{}).
+		Where("rekening_bank_id = ? AND tanggal = ? AND jumlah = ? AND referensi = ? AND keterangan = ?",
+			rekeningBankID, line.Tanggal, line.Jumlah, line.Referensi, line.Keterangan).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetLines lists statement lines of a bank account, oldest first. An empty
+// status or zero date leaves that filter off.
+func (r *BankRepository) GetLines(rekeningBankID uint64, status string, dari, sampai time.Time) ([]postgres.RekeningKoranLine, error) {
+	var lines []postgres.RekeningKoranLine
+	query := r.db.Where("rekening_bank_id = ?", rekeningBankID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if !dari.IsZero() {
+		query = query.Where("tanggal >= ?", dari)
+	}
+	if !sampai.IsZero() {
+		query = query.Where("tanggal <= ?", sampai)
+	}
+	err := query.Order("tanggal ASC, id ASC").Find(&lines).Error
+	return lines, err
+}
+
+func (r *BankRepository) GetLineByID(id uint64) (*postgres.RekeningKoranLine, error) {
+	var line postgres.RekeningKoranLine
+	err := r.db.First(&line, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &line, nil
+}
+
+func (r *BankRepository) GetLineForUpdate(id uint64) (*postgres.RekeningKoranLine, error) {
+	var line postgres.RekeningKoranLine
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&line, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &line, nil
+}
+
+func (r *BankRepository) UpdateLine(id uint64, updates map[string]interface{}) error {
+	return r.db.Model(&postgres.RekeningKoranLine{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (r *BankRepository) GetTotalLines(rekeningBankID uint64, sampai time.Time) (money.Amount, error) {
+	var total money.Amount
+	err := r.db.Model(&postgres.RekeningKoranLine{}).
+		Where("rekening_bank_id = ? AND tanggal <= ?", rekeningBankID, sampai).
+		Select("COALESCE(SUM(jumlah), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *BankRepository) IsJurnalDetailMatched(jurnalDetailID uint64) (bool, error) {
+	var count int64
+	err := r.db.Model(&postgres.RekeningKoranLine{}).
+		Where("jurnal_detail_id = ?", jurnalDetailID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetJurnalBankLines returns posted journal lines on the bank akun between
+// dari and sampai. With hanyaUnmatched, lines already matched to a statement
+// line are left out.
+func (r *BankRepository) GetJurnalBankLines(akunID uint64, dari, sampai time.Time, hanyaUnmatched bool) ([]JurnalBankLine, error) {
+	var lines []JurnalBankLine
+
+	query := r.db.Table("jurnal_details jd").
+		Select(`
+			jd.id as jurnal_detail_id,
+			ju.id as jurnal_id,
+			ju.nomor_jurnal,
+			ju.tanggal_transaksi,
+			ju.referensi,
+			COALESCE(NULLIF(jd.keterangan, ''), ju.keterangan) as keterangan,
+			jd.debit,
+			jd.kredit
+		`).
+		Joins("JOIN jurnal_umums ju ON jd.jurnal_id = ju.id").
+		Where("jd.akun_id = ? AND ju.status IN ('posted', 'reversed')", akunID)
+
+	if !dari.IsZero() {
+		query = query.Where("ju.tanggal_transaksi >= ?", dari)
+	}
+	if !sampai.IsZero() {
+		query = query.Where("ju.tanggal_transaksi <= ?", sampai)
+	}
+	if hanyaUnmatched {
+		query = query.Where("NOT EXISTS (SELECT 1 FROM rekening_koran_lines rl WHERE rl.jurnal_detail_id = jd.id)")
+	}
+
+	err := query.Order("ju.tanggal_transaksi ASC, jd.id ASC").Scan(&lines).Error
+	return lines, err
+}
+
+// GetJurnalBankLine loads one journal line with the fields needed to match
+// it by hand.
+func (r *BankRepository) GetJurnalBankLine(jurnalDetailID uint64) (*JurnalBankLine, error) {
+	var line JurnalBankLine
+	err := r.db.Table("jurnal_details jd").
+		Select(`
+			jd.id as jurnal_detail_id,
+			ju.id as jurnal_id,
+			ju.nomor_jurnal,
+			ju.tanggal_transaksi,
+			ju.referensi,
+			ju.status,
+			jd.akun_id,
+			COALESCE(NULLIF(jd.keterangan, ''), ju.keterangan) as keterangan,
+			jd.debit,
+			jd.kredit
+		`).
+		Joins("JOIN jurnal_umums ju ON jd.jurnal_id = ju.id").
+		Where("jd.id = ?", jurnalDetailID).
+		Take(&line).Error
+	if err != nil {
+		return nil, err
+	}
+	return &line, nil
+}
+
+type JurnalBankLine struct {
+	JurnalDetailID   uint64       `json:"jurnal_detail_id"`
+	JurnalID         uint64       `json:"jurnal_id"`
+	NomorJurnal      string       `json:"nomor_jurnal"`
+	TanggalTransaksi time.Time    `json:"tanggal_transaksi"`
+	Referensi        string       `json:"referensi"`
+	Status           string       `json:"status,omitempty"`
+	AkunID           uint64       `json:"akun_id,omitempty"`
+	Keterangan       string       `json:"keterangan"`
+	Debit            money.Amount `json:"debit"`
+	Kredit           money.Amount `json:"kredit"`
+}
@@ -4,7 +4,10 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"koperasi-merah-putih/internal/models/postgres"
+
+	"koperasi-merah-putih/internal/money"
 )
 
 type FinancialRepository struct {
@@ -15,6 +18,14 @@ func NewFinancialRepository(db *gorm.DB) *FinancialRepository {
 	return &FinancialRepository{db: db}
 }
 
+func (r *FinancialRepository) WithTx(tx *gorm.DB) *FinancialRepository {
+	return &FinancialRepository{db: tx}
+}
+
+func (r *FinancialRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
 func (r *FinancialRepository) CreateCOAKategori(kategori *postgres.COAKategori) error {
 	return r.db.Create(kategori).Error
 }
@@ -99,15 +110,61 @@ func (r *FinancialRepository) UpdateJurnalStatus(id uint64, status string, poste
 	return r.db.Model(&postgres.JurnalUmum{}).Where("id = ?", id).Updates(updates).Error
 }
 
-func (r *FinancialRepository) GetSaldoAkun(akunID uint64, sampaiTanggal time.Time) (float64, error) {
+// UpdateJurnalStatusFrom only changes the status while it is still dari, so
+// of two concurrent posts or a post racing a cancel only one goes through.
+func (r *FinancialRepository) UpdateJurnalStatusFrom(id uint64, dari, status string, postedBy uint64) (bool, error) {
+	now := time.Now()
+	result := r.db.Model(&postgres.JurnalUmum{}).
+		Where("id = ? AND status = ?", id, dari).
+		Updates(map[string]interface{}{
+			"status":    status,
+			"posted_by": postedBy,
+			"posted_at": &now,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *FinancialRepository) UpdateJurnalWajibLampiran(id uint64, wajib bool) error {
+	return r.db.Model(&postgres.JurnalUmum{}).Where("id = ?", id).Update("wajib_lampiran", wajib).Error
+}
+
+func (r *FinancialRepository) GetJurnalUmumBySumber(sumberTransaksi string, sumberID uint64) ([]postgres.JurnalUmum, error) {
+	var jurnals []postgres.JurnalUmum
+	err := r.db.Where("sumber_transaksi = ? AND sumber_id = ?", sumberTransaksi, sumberID).
+		Preload("JurnalDetail").Preload("JurnalDetail.Akun").
+		Order("id ASC").Find(&jurnals).Error
+	return jurnals, err
+}
+
+// MarkJurnalReversed only touches journals that are still posted, so two
+// concurrent reversals of the same journal cannot both succeed.
+func (r *FinancialRepository) MarkJurnalReversed(id, reversedByID uint64) error {
+	now := time.Now()
+	result := r.db.Model(&postgres.JurnalUmum{}).
+		Where("id = ? AND status = ?", id, "posted").
+		Updates(map[string]interface{}{
+			"status":         "reversed",
+			"reversed_by_id": reversedByID,
+			"reversed_at":    &now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *FinancialRepository) GetSaldoAkun(akunID uint64, sampaiTanggal time.Time) (money.Amount, error) {
 	var result struct {
-		Saldo float64
+		Saldo money.Amount
 	}
 
-	err := r.db.Table("jurnal_detail jd").
+	err := r.db.Table("jurnal_details jd").
 		Select("SUM(jd.debit - jd.kredit) as saldo").
-		Joins("JOIN jurnal_umum ju ON jd.jurnal_id = ju.id").
-		Where("jd.akun_id = ? AND ju.status = 'posted' AND ju.tanggal_transaksi <= ?",
+		Joins("JOIN jurnal_umums ju ON jd.jurnal_id = ju.id").
+		Where("jd.akun_id = ? AND ju.status IN ('posted', 'reversed') AND ju.tanggal_transaksi <= ?",
 			akunID, sampaiTanggal).
 		Scan(&result).Error
 
@@ -117,7 +174,7 @@ func (r *FinancialRepository) GetSaldoAkun(akunID uint64, sampaiTanggal time.Tim
 func (r *FinancialRepository) GetNeracaSaldo(koperasiID uint64, tanggal time.Time) ([]NeracaSaldoItem, error) {
 	var items []NeracaSaldoItem
 
-	err := r.db.Table("coa_akun ca").
+	err := r.db.Table("coa_akuns ca").
 		Select(`
 			ca.id as akun_id,
 			ca.kode_akun,
@@ -131,9 +188,10 @@ func (r *FinancialRepository) GetNeracaSaldo(koperasiID uint64, tanggal time.Tim
 				ELSE COALESCE(SUM(jd.kredit - jd.debit), 0)
 			END as saldo
 		`).
-		Joins("JOIN coa_kategori cat ON ca.kategori_id = cat.id").
-		Joins("LEFT JOIN jurnal_detail jd ON ca.id = jd.akun_id").
-		Joins("LEFT JOIN jurnal_umum ju ON jd.jurnal_id = ju.id AND ju.status = 'posted' AND ju.tanggal_transaksi <= ?", tanggal).
+		Joins("JOIN coa_kategoris cat ON ca.kategori_id = cat.id").
+		Joins(`LEFT JOIN (jurnal_details jd
+			JOIN jurnal_umums ju ON jd.jurnal_id = ju.id AND ju.status IN ('posted', 'reversed') AND ju.tanggal_transaksi <= ?
+		) ON ca.id = jd.akun_id`, tanggal).
 		Where("ca.koperasi_id = ? AND ca.is_aktif = ?", koperasiID, true).
 		Group("ca.id, ca.kode_akun, ca.nama_akun, cat.tipe, ca.saldo_normal").
 		Order("ca.kode_akun").
@@ -145,16 +203,17 @@ func (r *FinancialRepository) GetNeracaSaldo(koperasiID uint64, tanggal time.Tim
 func (r *FinancialRepository) GetLabaRugi(koperasiID uint64, dari, sampai time.Time) (*LabaRugi, error) {
 	var labaRugi LabaRugi
 
-	err := r.db.Table("jurnal_detail jd").
+	err := r.db.Table("jurnal_details jd").
 		Select(`
 			SUM(CASE WHEN cat.tipe = 'pendapatan' THEN jd.kredit - jd.debit ELSE 0 END) as total_pendapatan,
 			SUM(CASE WHEN cat.tipe = 'beban' THEN jd.debit - jd.kredit ELSE 0 END) as total_beban
 		`).
-		Joins("JOIN jurnal_umum ju ON jd.jurnal_id = ju.id").
-		Joins("JOIN coa_akun ca ON jd.akun_id = ca.id").
-		Joins("JOIN coa_kategori cat ON ca.kategori_id = cat.id").
-		Where("ju.koperasi_id = ? AND ju.status = 'posted' AND ju.tanggal_transaksi BETWEEN ? AND ?",
+		Joins("JOIN jurnal_umums ju ON jd.jurnal_id = ju.id").
+		Joins("JOIN coa_akuns ca ON jd.akun_id = ca.id").
+		Joins("JOIN coa_kategoris cat ON ca.kategori_id = cat.id").
+		Where("ju.koperasi_id = ? AND ju.status IN ('posted', 'reversed') AND ju.tanggal_transaksi BETWEEN ? AND ?",
 			koperasiID, dari, sampai).
+		Where("ju.sumber_transaksi <> ?", "tutup_buku").
 		Scan(&labaRugi).Error
 
 	labaRugi.LabaRugi = labaRugi.TotalPendapatan - labaRugi.TotalBeban
@@ -165,41 +224,358 @@ func (r *FinancialRepository) GetLabaRugi(koperasiID uint64, dari, sampai time.T
 func (r *FinancialRepository) GetNeraca(koperasiID uint64, tanggal time.Time) (*Neraca, error) {
 	var neraca Neraca
 
-	err := r.db.Table("jurnal_detail jd").
+	err := r.db.Table("jurnal_details jd").
 		Select(`
 			SUM(CASE WHEN cat.tipe = 'aset' THEN jd.debit - jd.kredit ELSE 0 END) as total_aset,
 			SUM(CASE WHEN cat.tipe = 'kewajiban' THEN jd.kredit - jd.debit ELSE 0 END) as total_kewajiban,
 			SUM(CASE WHEN cat.tipe = 'ekuitas' THEN jd.kredit - jd.debit ELSE 0 END) as total_ekuitas
 		`).
-		Joins("JOIN jurnal_umum ju ON jd.jurnal_id = ju.id").
-		Joins("JOIN coa_akun ca ON jd.akun_id = ca.id").
-		Joins("JOIN coa_kategori cat ON ca.kategori_id = cat.id").
-		Where("ju.koperasi_id = ? AND ju.status = 'posted' AND ju.tanggal_transaksi <= ?",
+		Joins("JOIN jurnal_umums ju ON jd.jurnal_id = ju.id").
+		Joins("JOIN coa_akuns ca ON jd.akun_id = ca.id").
+		Joins("JOIN coa_kategoris cat ON ca.kategori_id = cat.id").
+		Where("ju.koperasi_id = ? AND ju.status IN ('posted', 'reversed') AND ju.tanggal_transaksi <= ?",
 			koperasiID, tanggal).
 		Scan(&neraca).Error
 
 	return &neraca, err
 }
 
+// GetMutasiAkun sums posted debit and kredit per akun for journals dated
+// between dari and sampai. A zero dari means from the beginning of the books.
+func (r *FinancialRepository) GetMutasiAkun(koperasiID uint64, dari, sampai time.Time) ([]MutasiAkun, error) {
+	var items []MutasiAkun
+
+	query := r.db.Table("jurnal_details jd").
+		Select(`
+			ca.id as akun_id,
+			ca.kode_akun,
+			ca.nama_akun,
+			cat.tipe as kategori_tipe,
+			ca.saldo_normal,
+			COALESCE(SUM(jd.debit), 0) as total_debit,
+			COALESCE(SUM(jd.kredit), 0) as total_kredit
+		`).
+		Joins("JOIN jurnal_umums ju ON jd.jurnal_id = ju.id").
+		Joins("JOIN coa_akuns ca ON jd.akun_id = ca.id").
+		Joins("JOIN coa_kategoris cat ON ca.kategori_id = cat.id").
+		Where("ju.koperasi_id = ? AND ju.status IN ('posted', 'reversed') AND ju.tanggal_transaksi <= ?",
+			koperasiID, sampai)
+	if !dari.IsZero() {
+		query = query.Where("ju.tanggal_transaksi >= ?", dari)
+	}
+
+	err := query.Group("ca.id, ca.kode_akun, ca.nama_akun, cat.tipe, ca.saldo_normal").
+		Order("ca.kode_akun").
+		Scan(&items).Error
+
+	return items, err
+}
+
+// GetMutasiHasilUsaha sums posted pendapatan and beban lines per akun
+// between dari and sampai, split by whether the journal was with a member.
+// The year-end close is left out so closed years still show their result.
+func (r *FinancialRepository) GetMutasiHasilUsaha(koperasiID uint64, dari, sampai time.Time) ([]MutasiHasilUsaha, error) {
+	var items []MutasiHasilUsaha
+
+	err := r.db.Table("jurnal_details jd").
+		Select(`
+			ca.id as akun_id,
+			ca.kode_akun,
+			ca.nama_akun,
+			cat.tipe as kategori_tipe,
+			ca.saldo_normal,
+			ju.anggota_id <> 0 as anggota,
+			COALESCE(SUM(jd.debit), 0) as total_debit,
+			COALESCE(SUM(jd.kredit), 0) as total_kredit
+		`).
+		Joins("JOIN jurnal_umums ju ON jd.jurnal_id = ju.id").
+		Joins("JOIN coa_akuns ca ON jd.akun_id = ca.id").
+		Joins("JOIN coa_kategoris cat ON ca.kategori_id = cat.id").
+		Where("ju.koperasi_id = ? AND ju.status IN ('posted', 'reversed') AND ju.tanggal_transaksi BETWEEN ? AND ?",
+			koperasiID, dari, sampai).
+		Where("ju.sumber_transaksi <> ? AND cat.tipe IN ?", "tutup_buku", []string{"pendapatan", "beban"}).
+		Group("ca.id, ca.kode_akun, ca.nama_akun, cat.tipe, ca.saldo_normal, ju.anggota_id <> 0").
+		Order("ca.kode_akun").
+		Scan(&items).Error
+
+	return items, err
+}
+
+// GetSaldoKas returns the combined balance of the koperasi's IsKas accounts
+// from posted journals dated before sebelum.
+func (r *FinancialRepository) GetSaldoKas(koperasiID uint64, sebelum time.Time) (money.Amount, error) {
+	var result struct {
+		Saldo money.Amount
+	}
+
+	err := r.db.Table("jurnal_details jd").
+		Select("COALESCE(SUM(jd.debit - jd.kredit), 0) as saldo").
+		Joins("JOIN jurnal_umums ju ON jd.jurnal_id = ju.id").
+		Joins("JOIN coa_akuns ca ON jd.akun_id = ca.id").
+		Where("ju.koperasi_id = ? AND ju.status IN ('posted', 'reversed') AND ju.tanggal_transaksi < ? AND ca.is_kas = ?",
+			koperasiID, sebelum, true).
+		Scan(&result).Error
+
+	return result.Saldo, err
+}
+
+// GetMutasiNonKas sums posted movements per non-cash akun between dari and
+// sampai, leaving out year-end closing journals. With hanyaJurnalKas only
+// journals that touch an IsKas account are counted, which gives the
+// counterpart of every cash movement.
+func (r *FinancialRepository) GetMutasiNonKas(koperasiID uint64, dari, sampai time.Time, hanyaJurnalKas bool) ([]MutasiNonKas, error) {
+	var items []MutasiNonKas
+
+	query := r.db.Table("jurnal_details jd").
+		Select(`
+			ca.id as akun_id,
+			ca.kode_akun,
+			ca.nama_akun,
+			ca.kategori_id,
+			ca.parent_id,
+			cat.tipe as kategori_tipe,
+			COALESCE(SUM(jd.debit), 0) as total_debit,
+			COALESCE(SUM(jd.kredit), 0) as total_kredit
+		`).
+		Joins("JOIN jurnal_umums ju ON jd.jurnal_id = ju.id").
+		Joins("JOIN coa_akuns ca ON jd.akun_id = ca.id").
+		Joins("JOIN coa_kategoris cat ON ca.kategori_id = cat.id").
+		Where("ju.koperasi_id = ? AND ju.status IN ('posted', 'reversed') AND ju.tanggal_transaksi BETWEEN ? AND ?",
+			koperasiID, dari, sampai).
+		Where("ju.sumber_transaksi <> ? AND ca.is_kas = ?", "tutup_buku", false)
+
+	if hanyaJurnalKas {
+		query = query.Where(`EXISTS (
+			SELECT 1 FROM jurnal_details kd
+			JOIN coa_akuns ka ON kd.akun_id = ka.id
+			WHERE kd.jurnal_id = ju.id AND ka.is_kas = true
+		)`)
+	}
+
+	err := query.Group("ca.id, ca.kode_akun, ca.nama_akun, ca.kategori_id, ca.parent_id, cat.tipe").
+		Order("ca.kode_akun").
+		Scan(&items).Error
+
+	return items, err
+}
+
+// GetCOAAkunAll returns every akun of the koperasi including inactive ones,
+// for views that have to follow history through the account tree.
+func (r *FinancialRepository) GetCOAAkunAll(koperasiID uint64) ([]postgres.COAAkun, error) {
+	var akuns []postgres.COAAkun
+	err := r.db.Where("koperasi_id = ?", koperasiID).
+		Preload("Kategori").
+		Order("kode_akun ASC").Find(&akuns).Error
+	return akuns, err
+}
+
+// GetAkunIDsDipakai returns the akun of the koperasi that appear on any
+// journal line, whatever the journal status.
+func (r *FinancialRepository) GetAkunIDsDipakai(koperasiID uint64) ([]uint64, error) {
+	var ids []uint64
+	err := r.db.Table("jurnal_details jd").
+		Joins("JOIN coa_akuns ca ON jd.akun_id = ca.id").
+		Where("ca.koperasi_id = ?", koperasiID).
+		Distinct("jd.akun_id").Pluck("jd.akun_id", &ids).Error
+	return ids, err
+}
+
+// CountJurnalSampai counts the koperasi's journals dated on or before
+// tanggal that are not cancelled.
+func (r *FinancialRepository) CountJurnalSampai(koperasiID uint64, tanggal time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&postgres.JurnalUmum{}).
+		Where("koperasi_id = ? AND status <> ? AND tanggal_transaksi < ?",
+			koperasiID, "cancelled", tanggal.AddDate(0, 0, 1)).
+		Count(&count).Error
+	return count, err
+}
+
+// GetTotalAkunSebelum sums posted debit and kredit on akunIDs dated before
+// sebelum.
+func (r *FinancialRepository) GetTotalAkunSebelum(akunIDs []uint64, sebelum time.Time) (money.Amount, money.Amount, error) {
+	var result struct {
+		TotalDebit  money.Amount
+		TotalKredit money.Amount
+	}
+
+	err := r.db.Table("jurnal_details jd").
+		Select("COALESCE(SUM(jd.debit), 0) as total_debit, COALESCE(SUM(jd.kredit), 0) as total_kredit").
+		Joins("JOIN jurnal_umums ju ON jd.jurnal_id = ju.id").
+		Where("jd.akun_id IN ? AND ju.status IN ('posted', 'reversed') AND ju.tanggal_transaksi < ?",
+			akunIDs, sebelum).
+		Scan(&result).Error
+
+	return result.TotalDebit, result.TotalKredit, err
+}
+
+// GetBukuBesarLines returns every posted journal line on akunIDs between dari
+// and sampai in posting order.
+func (r *FinancialRepository) GetBukuBesarLines(akunIDs []uint64, dari, sampai time.Time) ([]BukuBesarLine, error) {
+	var lines []BukuBesarLine
+
+	err := r.db.Table("jurnal_details jd").
+		Select(`
+			ju.id as jurnal_id,
+			ju.nomor_jurnal,
+			ju.tanggal_transaksi,
+			ju.referensi,
+			ju.sumber_transaksi,
+			COALESCE(NULLIF(jd.keterangan, ''), ju.keterangan) as keterangan,
+			ca.id as akun_id,
+			ca.kode_akun,
+			ca.nama_akun,
+			jd.debit,
+			jd.kredit
+		`).
+		Joins("JOIN jurnal_umums ju ON jd.jurnal_id = ju.id").
+		Joins("JOIN coa_akuns ca ON jd.akun_id = ca.id").
+		Where("jd.akun_id IN ? AND ju.status IN ('posted', 'reversed') AND ju.tanggal_transaksi BETWEEN ? AND ?",
+			akunIDs, dari, sampai).
+		Order("ju.tanggal_transaksi ASC, ju.id ASC, jd.id ASC").
+		Scan(&lines).Error
+
+	return lines, err
+}
+
+func (r *FinancialRepository) CreateArusKasMapping(mapping *postgres.ArusKasMapping) error {
+	return r.db.Create(mapping).Error
+}
+
+func (r *FinancialRepository) GetArusKasMappings(koperasiID uint64) ([]postgres.ArusKasMapping, error) {
+	var mappings []postgres.ArusKasMapping
+	err := r.db.Where("koperasi_id = ?", koperasiID).Order("id ASC").Find(&mappings).Error
+	return mappings, err
+}
+
+func (r *FinancialRepository) DeleteArusKasMapping(id uint64) error {
+	return r.db.Delete(&postgres.ArusKasMapping{}, id).Error
+}
+
+// GetRantaiJurnalTerakhir locks the koperasi row, so journals of one
+// koperasi are chained one at a time, and returns the last link of its
+// chain. It returns nil when the chain is still empty.
+func (r *FinancialRepository) GetRantaiJurnalTerakhir(koperasiID uint64) (*postgres.RantaiJurnal, error) {
+	var koperasi postgres.Koperasi
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&koperasi, koperasiID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var rantai []postgres.RantaiJurnal
+	err = r.db.Where("koperasi_id = ?", koperasiID).Order("urutan DESC").Limit(1).Find(&rantai).Error
+	if err != nil || len(rantai) == 0 {
+		return nil, err
+	}
+	return &rantai[0], nil
+}
+
+func (r *FinancialRepository) CreateRantaiJurnal(rantai *postgres.RantaiJurnal) error {
+	return r.db.Create(rantai).Error
+}
+
+// GetRantaiJurnal returns up to limit links of the koperasi's chain after
+// urutan sesudah, in order.
+func (r *FinancialRepository) GetRantaiJurnal(koperasiID, sesudah uint64, limit int) ([]postgres.RantaiJurnal, error) {
+	var rantai []postgres.RantaiJurnal
+	err := r.db.Where("koperasi_id = ? AND urutan > ?", koperasiID, sesudah).
+		Order("urutan ASC").Limit(limit).Find(&rantai).Error
+	return rantai, err
+}
+
+// GetJurnalUntukRantai loads journals with their lines, keyed by ID.
+func (r *FinancialRepository) GetJurnalUntukRantai(ids []uint64) (map[uint64]postgres.JurnalUmum, error) {
+	var jurnals []postgres.JurnalUmum
+	err := r.db.Where("id IN ?", ids).Preload("JurnalDetail").Find(&jurnals).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[uint64]postgres.JurnalUmum, len(jurnals))
+	for _, j := range jurnals {
+		result[j.ID] = j
+	}
+	return result, nil
+}
+
+// CountJurnalTanpaRantai counts posted journals of the koperasi that are not
+// in its chain, such as those posted before chaining was introduced.
+func (r *FinancialRepository) CountJurnalTanpaRantai(koperasiID uint64) (int64, error) {
+	var count int64
+	err := r.db.Model(&postgres.JurnalUmum{}).
+		Where("koperasi_id = ? AND status IN ('posted', 'reversed')", koperasiID).
+		Where("id NOT IN (?)", r.db.Model(&postgres.RantaiJurnal{}).Select("jurnal_id").Where("koperasi_id = ?", koperasiID)).
+		Count(&count).Error
+	return count, err
+}
+
+type BukuBesarLine struct {
+	JurnalID         uint64    `json:"jurnal_id"`
+	NomorJurnal      string    `json:"nomor_jurnal"`
+	TanggalTransaksi time.Time `json:"tanggal_transaksi"`
+	Referensi        string    `json:"referensi"`
+	SumberTransaksi  string    `json:"sumber_transaksi"`
+	Keterangan       string    `json:"keterangan"`
+	AkunID           uint64    `json:"akun_id"`
+	KodeAkun         string    `json:"kode_akun"`
+	NamaAkun         string    `json:"nama_akun"`
+	Debit            money.Amount `json:"debit"`
+	Kredit           money.Amount `json:"kredit"`
+}
+
+type MutasiNonKas struct {
+	AkunID       uint64  `json:"akun_id"`
+	KodeAkun     string  `json:"kode_akun"`
+	NamaAkun     string  `json:"nama_akun"`
+	KategoriID   uint64  `json:"kategori_id"`
+	ParentID     uint64  `json:"parent_id"`
+	KategoriTipe string  `json:"kategori_tipe"`
+	TotalDebit   money.Amount `json:"total_debit"`
+	TotalKredit  money.Amount `json:"total_kredit"`
+}
+
+type MutasiAkun struct {
+	AkunID       uint64  `json:"akun_id"`
+	KodeAkun     string  `json:"kode_akun"`
+	NamaAkun     string  `json:"nama_akun"`
+	KategoriTipe string  `json:"kategori_tipe"`
+	SaldoNormal  string  `json:"saldo_normal"`
+	TotalDebit   money.Amount `json:"total_debit"`
+	TotalKredit  money.Amount `json:"total_kredit"`
+}
+
+// Saldo returns the balance in the account's normal direction.
+func (m MutasiAkun) Saldo() money.Amount {
+	if m.SaldoNormal == "debit" {
+		return m.TotalDebit - m.TotalKredit
+	}
+	return m.TotalKredit - m.TotalDebit
+}
+
+// MutasiHasilUsaha is MutasiAkun for one side of the member split.
+type MutasiHasilUsaha struct {
+	MutasiAkun
+	Anggota bool `json:"anggota"`
+}
+
 type NeracaSaldoItem struct {
 	AkunID        uint64  `json:"akun_id"`
 	KodeAkun      string  `json:"kode_akun"`
 	NamaAkun      string  `json:"nama_akun"`
 	KategoriTipe  string  `json:"kategori_tipe"`
 	SaldoNormal   string  `json:"saldo_normal"`
-	TotalDebit    float64 `json:"total_debit"`
-	TotalKredit   float64 `json:"total_kredit"`
-	Saldo         float64 `json:"saldo"`
+	TotalDebit   money.Amount `json:"total_debit"`
+	TotalKredit  money.Amount `json:"total_kredit"`
+	Saldo        money.Amount `json:"saldo"`
 }
 
 type LabaRugi struct {
-	TotalPendapatan float64 `json:"total_pendapatan"`
-	TotalBeban      float64 `json:"total_beban"`
-	LabaRugi        float64 `json:"laba_rugi"`
+	TotalPendapatan money.Amount `json:"total_pendapatan"`
+	TotalBeban      money.Amount `json:"total_beban"`
+	LabaRugi        money.Amount `json:"laba_rugi"`
 }
 
 type Neraca struct {
-	TotalAset      float64 `json:"total_aset"`
-	TotalKewajiban float64 `json:"total_kewajiban"`
-	TotalEkuitas   float64 `json:"total_ekuitas"`
+	TotalAset      money.Amount `json:"total_aset"`
+	TotalKewajiban money.Amount `json:"total_kewajiban"`
+	TotalEkuitas   money.Amount `json:"total_ekuitas"`
 }
\ No newline at end of file
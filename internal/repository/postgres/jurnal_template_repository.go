@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"koperasi-merah-putih/internal/models/postgres"
+
+	"koperasi-merah-putih/internal/money"
+)
+
+type JurnalTemplateRepository struct {
+	db *gorm.DB
+}
+
+func NewJurnalTemplateRepository(db *gorm.DB) *JurnalTemplateRepository {
+	return &JurnalTemplateRepository{db: db}
+}
+
+func (r *JurnalTemplateRepository) WithTx(tx *gorm.DB) *JurnalTemplateRepository {
+	return &JurnalTemplateRepository{db: tx}
+}
+
+func (r *JurnalTemplateRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
+func (r *JurnalTemplateRepository) CreateTemplate(template *postgres.JurnalTemplate) error {
+	return r.db.Create(template).Error
+}
+
+func (r *JurnalTemplateRepository) GetTemplateByID(id uint64) (*postgres.JurnalTemplate, error) {
+	var template postgres.JurnalTemplate
+	err := r.db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("urutan ASC, id ASC")
+	}).Preload("Lines.Akun").Preload("Parameter").
+		First(&template, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// GetTemplateForUpdate locks the template row so a scheduled run and a manual
+// run cannot generate the same occurrence twice.
+func (r *JurnalTemplateRepository) GetTemplateForUpdate(id uint64) (*postgres.JurnalTemplate, error) {
+	var template postgres.JurnalTemplate
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&template, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.Where("jurnal_template_id = ?", id).Order("urutan ASC, id ASC").Find(&template.Lines).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.Where("jurnal_template_id = ?", id).Find(&template.Parameter).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+func (r *JurnalTemplateRepository) GetTemplateByKoperasi(koperasiID uint64) ([]postgres.JurnalTemplate, error) {
+	var templates []postgres.JurnalTemplate
+	err := r.db.Where("koperasi_id = ?", koperasiID).
+		Preload("Parameter").
+		Order("nama ASC").Find(&templates).Error
+	return templates, err
+}
+
+// GetDueTemplateIDs returns active templates whose next journal date has
+// been reached, leaving out failed ones until their retry time.
+func (r *JurnalTemplateRepository) GetDueTemplateIDs(now time.Time) ([]uint64, error) {
+	var ids []uint64
+	err := r.db.Model(&postgres.JurnalTemplate{}).
+		Where("is_aktif = ? AND berikutnya_pada <= ?", true, now).
+		Where("coba_lagi_pada IS NULL OR coba_lagi_pada <= ?", now).
+		Order("berikutnya_pada ASC").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *JurnalTemplateRepository) UpdateTemplate(id uint64, updates map[string]interface{}) error {
+	return r.db.Model(&postgres.JurnalTemplate{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// ReplaceLines swaps the lines and parameters of a template.
+func (r *JurnalTemplateRepository) ReplaceLines(templateID uint64, lines []postgres.JurnalTemplateLine, params []postgres.JurnalTemplateParameter) error {
+	if err := r.db.Where("jurnal_template_id = ?", templateID).Delete(&postgres.JurnalTemplateLine{}).Error; err != nil {
+		return err
+	}
+	if err := r.db.Where("jurnal_template_id = ?", templateID).Delete(&postgres.JurnalTemplateParameter{}).Error; err != nil {
+		return err
+	}
+
+	for i := range lines {
+		lines[i].ID = 0
+		lines[i].JurnalTemplateID = templateID
+	}
+	if err := r.db.Create(&lines).Error; err != nil {
+		return err
+	}
+
+	if len(params) == 0 {
+		return nil
+	}
+	for i := range params {
+		params[i].ID = 0
+		params[i].JurnalTemplateID = templateID
+	}
+	return r.db.Create(&params).Error
+}
+
+func (r *JurnalTemplateRepository) UpdateParameterNilai(templateID uint64, nama string, nilai money.Amount) error {
+	return r.db.Model(&postgres.JurnalTemplateParameter{}).
+		Where("jurnal_template_id = ? AND nama = ?", templateID, nama).
+		Update("nilai", nilai).Error
+}
@@ -5,6 +5,8 @@ import (
 
 	"gorm.io/gorm"
 	"koperasi-merah-putih/internal/models/postgres"
+
+	"koperasi-merah-putih/internal/money"
 )
 
 type KlinikRepository struct {
@@ -15,6 +17,14 @@ func NewKlinikRepository(db *gorm.DB) *KlinikRepository {
 	return &KlinikRepository{db: db}
 }
 
+func (r *KlinikRepository) WithTx(tx *gorm.DB) *KlinikRepository {
+	return &KlinikRepository{db: tx}
+}
+
+func (r *KlinikRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
 func (r *KlinikRepository) CreatePasien(pasien *postgres.KlinikPasien) error {
 	return r.db.Create(pasien).Error
 }
@@ -118,6 +128,14 @@ func (r *KlinikRepository) UpdateKunjungan(kunjungan *postgres.KlinikKunjungan)
 	return r.db.Save(kunjungan).Error
 }
 
+func (r *KlinikRepository) UpdateKunjunganPembayaran(id uint64, statusPembayaran string, totalBiaya money.Amount, jurnalID uint64) error {
+	return r.db.Model(&postgres.KlinikKunjungan{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status_pembayaran": statusPembayaran,
+		"total_biaya":       totalBiaya,
+		"jurnal_id":         jurnalID,
+	}).Error
+}
+
 func (r *KlinikRepository) CreateObat(obat *postgres.KlinikObat) error {
 	return r.db.Create(obat).Error
 }
@@ -191,7 +209,7 @@ func (r *KlinikRepository) GetObatStokRendah(koperasiID uint64) ([]postgres.Klin
 
 type KlinikStatistik struct {
 	TotalKunjungan     uint64  `json:"total_kunjungan"`
-	TotalPendapatan    float64 `json:"total_pendapatan"`
-	RataRataBiaya      float64 `json:"rata_rata_biaya"`
+	TotalPendapatan  money.Amount `json:"total_pendapatan"`
+	RataRataBiaya    money.Amount `json:"rata_rata_biaya"`
 	TotalPasienAktif   uint64  `json:"total_pasien_aktif"`
 }
\ No newline at end of file
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"koperasi-merah-putih/internal/models/postgres"
+)
+
+type KonsolidasiRepository struct {
+	db *gorm.DB
+}
+
+func NewKonsolidasiRepository(db *gorm.DB) *KonsolidasiRepository {
+	return &KonsolidasiRepository{db: db}
+}
+
+func (r *KonsolidasiRepository) CreatePos(pos *postgres.PosKonsolidasi) error {
+	return r.db.Create(pos).Error
+}
+
+func (r *KonsolidasiRepository) UpdatePos(pos *postgres.PosKonsolidasi) error {
+	return r.db.Save(pos).Error
+}
+
+func (r *KonsolidasiRepository) DeletePos(id uint64) error {
+	return r.db.Delete(&postgres.PosKonsolidasi{}, id).Error
+}
+
+func (r *KonsolidasiRepository) GetPosByID(id uint64) (*postgres.PosKonsolidasi, error) {
+	var pos postgres.PosKonsolidasi
+	err := r.db.First(&pos, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pos, nil
+}
+
+func (r *KonsolidasiRepository) GetPosByTenant(tenantID uint64) ([]postgres.PosKonsolidasi, error) {
+	var pos []postgres.PosKonsolidasi
+	err := r.db.Where("tenant_id = ?", tenantID).Order("kode ASC").Find(&pos).Error
+	return pos, err
+}
+
+func (r *KonsolidasiRepository) CountPemetaanByPos(posID uint64) (int64, error) {
+	var count int64
+	err := r.db.Model(&postgres.PemetaanAkunKonsolidasi{}).
+		Where("pos_konsolidasi_id = ?", posID).Count(&count).Error
+	return count, err
+}
+
+func (r *KonsolidasiRepository) GetPemetaanByAkun(akunID uint64) (*postgres.PemetaanAkunKonsolidasi, error) {
+	var pemetaan postgres.PemetaanAkunKonsolidasi
+	err := r.db.Where("akun_id = ?", akunID).First(&pemetaan).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pemetaan, nil
+}
+
+func (r *KonsolidasiRepository) SavePemetaan(pemetaan *postgres.PemetaanAkunKonsolidasi) error {
+	return r.db.Omit(clause.Associations).Save(pemetaan).Error
+}
+
+func (r *KonsolidasiRepository) DeletePemetaan(akunID uint64) error {
+	return r.db.Where("akun_id = ?", akunID).Delete(&postgres.PemetaanAkunKonsolidasi{}).Error
+}
+
+func (r *KonsolidasiRepository) GetPemetaanByKoperasi(koperasiIDs []uint64) ([]postgres.PemetaanAkunKonsolidasi, error) {
+	var pemetaan []postgres.PemetaanAkunKonsolidasi
+	err := r.db.Where("koperasi_id IN ?", koperasiIDs).
+		Preload("Akun").Preload("PosKonsolidasi").
+		Order("koperasi_id ASC, akun_id ASC").
+		Find(&pemetaan).Error
+	return pemetaan, err
+}
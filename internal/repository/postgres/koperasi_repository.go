@@ -13,6 +13,14 @@ func NewKoperasiRepository(db *gorm.DB) *KoperasiRepository {
 	return &KoperasiRepository{db: db}
 }
 
+func (r *KoperasiRepository) WithTx(tx *gorm.DB) *KoperasiRepository {
+	return &KoperasiRepository{db: tx}
+}
+
+func (r *KoperasiRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
 func (r *KoperasiRepository) Create(koperasi *postgres.Koperasi) error {
 	return r.db.Create(koperasi).Error
 }
@@ -102,6 +110,13 @@ func (r *AnggotaKoperasiRepository) GetByNIAK(niak string) (*postgres.AnggotaKop
 	return &anggota, nil
 }
 
+func (r *AnggotaKoperasiRepository) GetByNIAKs(koperasiID uint64, niaks []string) ([]postgres.AnggotaKoperasi, error) {
+	var anggotas []postgres.AnggotaKoperasi
+	err := r.db.Where("koperasi_id = ? AND niak IN ?", koperasiID, niaks).
+		Find(&anggotas).Error
+	return anggotas, err
+}
+
 func (r *AnggotaKoperasiRepository) Update(anggota *postgres.AnggotaKoperasi) error {
 	return r.db.Save(anggota).Error
 }
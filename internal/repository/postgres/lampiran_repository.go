@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"gorm.io/gorm"
+	"koperasi-merah-putih/internal/models/postgres"
+)
+
+type LampiranRepository struct {
+	db *gorm.DB
+}
+
+func NewLampiranRepository(db *gorm.DB) *LampiranRepository {
+	return &LampiranRepository{db: db}
+}
+
+func (r *LampiranRepository) WithTx(tx *gorm.DB) *LampiranRepository {
+	return &LampiranRepository{db: tx}
+}
+
+func (r *LampiranRepository) Create(lampiran *postgres.Lampiran) error {
+	return r.db.Create(lampiran).Error
+}
+
+func (r *LampiranRepository) GetByID(id uint64) (*postgres.Lampiran, error) {
+	var lampiran postgres.Lampiran
+	err := r.db.First(&lampiran, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &lampiran, nil
+}
+
+func (r *LampiranRepository) GetByDokumen(jenisDokumen string, dokumenID uint64) ([]postgres.Lampiran, error) {
+	var lampirans []postgres.Lampiran
+	err := r.db.Where("jenis_dokumen = ? AND dokumen_id = ?", jenisDokumen, dokumenID).
+		Order("created_at ASC, id ASC").
+		Find(&lampirans).Error
+	return lampirans, err
+}
+
+func (r *LampiranRepository) CountByDokumen(jenisDokumen string, dokumenID uint64) (int64, error) {
+	var count int64
+	err := r.db.Model(&postgres.Lampiran{}).
+		Where("jenis_dokumen = ? AND dokumen_id = ?", jenisDokumen, dokumenID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *LampiranRepository) Delete(id uint64) error {
+	return r.db.Delete(&postgres.Lampiran{}, id).Error
+}
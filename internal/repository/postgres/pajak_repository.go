@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"koperasi-merah-putih/internal/models/postgres"
+)
+
+type PajakRepository struct {
+	db *gorm.DB
+}
+
+func NewPajakRepository(db *gorm.DB) *PajakRepository {
+	return &PajakRepository{db: db}
+}
+
+func (r *PajakRepository) WithTx(tx *gorm.DB) *PajakRepository {
+	return &PajakRepository{db: tx}
+}
+
+func (r *PajakRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
+// GetPengaturan loads the tax setup of a koperasi with its product category
+// overrides.
+func (r *PajakRepository) GetPengaturan(koperasiID uint64) (*postgres.PengaturanPajak, error) {
+	var pengaturan postgres.PengaturanPajak
+	err := r.db.Where("koperasi_id = ?", koperasiID).
+		Preload("Kategori", func(db *gorm.DB) *gorm.DB {
+			return db.Order("kategori_produk_id ASC")
+		}).
+		Preload("Kategori.KategoriProduk").
+		First(&pengaturan).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pengaturan, nil
+}
+
+func (r *PajakRepository) SavePengaturan(pengaturan *postgres.PengaturanPajak) error {
+	return r.db.Omit(clause.Associations).Save(pengaturan).Error
+}
+
+// ReplaceKategori swaps the product category overrides of a koperasi.
+func (r *PajakRepository) ReplaceKategori(koperasiID uint64, kategori []postgres.KategoriPajakProduk) error {
+	if err := r.db.Where("koperasi_id = ?", koperasiID).Delete(&postgres.KategoriPajakProduk{}).Error; err != nil {
+		return err
+	}
+	if len(kategori) == 0 {
+		return nil
+	}
+	return r.db.Create(&kategori).Error
+}
+
+func (r *PajakRepository) CreatePotongan(potongan *postgres.PotonganPajak) error {
+	return r.db.Create(potongan).Error
+}
+
+func (r *PajakRepository) UpdatePotonganJurnal(id, jurnalID uint64) error {
+	return r.db.Model(&postgres.PotonganPajak{}).Where("id = ?", id).Update("jurnal_id", jurnalID).Error
+}
+
+// GetPotonganByTanggal returns the withholdings made in [dari, sampai).
+func (r *PajakRepository) GetPotonganByTanggal(koperasiID uint64, dari, sampai time.Time) ([]postgres.PotonganPajak, error) {
+	var potongan []postgres.PotonganPajak
+	err := r.db.Where("koperasi_id = ? AND tanggal_potong >= ? AND tanggal_potong < ?", koperasiID, dari, sampai).
+		Order("tanggal_potong ASC, id ASC").Find(&potongan).Error
+	return potongan, err
+}
+
+func (r *PajakRepository) CreateSetoran(setoran *postgres.SetoranPajak) error {
+	return r.db.Create(setoran).Error
+}
+
+func (r *PajakRepository) UpdateSetoranJurnal(id, jurnalID uint64) error {
+	return r.db.Model(&postgres.SetoranPajak{}).Where("id = ?", id).Update("jurnal_id", jurnalID).Error
+}
+
+func (r *PajakRepository) GetSetoran(koperasiID uint64, masaPajak string) (*postgres.SetoranPajak, error) {
+	var setoran postgres.SetoranPajak
+	err := r.db.Where("koperasi_id = ? AND masa_pajak = ?", koperasiID, masaPajak).First(&setoran).Error
+	if err != nil {
+		return nil, err
+	}
+	return &setoran, nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"gorm.io/gorm"
 	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
 )
 
 type PaymentRepository struct {
@@ -65,6 +66,15 @@ func (r *PaymentRepository) UpdateTransactionResponse(id uint64, gatewayResponse
 	}).Error
 }
 
+func (r *PaymentRepository) UpdateTransactionRefund(id uint64, refundStatus string, amount money.Amount, reference string, refundedAt *time.Time) error {
+	return r.db.Model(&postgres.PaymentTransaction{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"refund_status":    refundStatus,
+		"refund_amount":    amount,
+		"refund_reference": reference,
+		"refunded_at":      refundedAt,
+	}).Error
+}
+
 func (r *PaymentRepository) GetExpiredTransactions() ([]postgres.PaymentTransaction, error) {
 	var payments []postgres.PaymentTransaction
 	now := time.Now()
@@ -105,6 +115,15 @@ func (r *PaymentProviderRepository) GetByCode(code string) (*postgres.PaymentPro
 	return &provider, nil
 }
 
+func (r *PaymentProviderRepository) GetByID(id uint64) (*postgres.PaymentProvider, error) {
+	var provider postgres.PaymentProvider
+	err := r.db.Where("is_active = ?", true).First(&provider, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &provider, nil
+}
+
 func (r *PaymentProviderRepository) GetMethodByID(id uint64) (*postgres.PaymentMethod, error) {
 	var method postgres.PaymentMethod
 	err := r.db.Preload("Provider").First(&method, id).Error
@@ -0,0 +1,215 @@
+package postgres
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"koperasi-merah-putih/internal/models/postgres"
+)
+
+type PeriodeRepository struct {
+	db *gorm.DB
+}
+
+func NewPeriodeRepository(db *gorm.DB) *PeriodeRepository {
+	return &PeriodeRepository{db: db}
+}
+
+func (r *PeriodeRepository) WithTx(tx *gorm.DB) *PeriodeRepository {
+	return &PeriodeRepository{db: tx}
+}
+
+func (r *PeriodeRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
+func (r *PeriodeRepository) CreatePeriode(periode *postgres.PeriodeAkuntansi) error {
+	return r.db.Create(periode).Error
+}
+
+func (r *PeriodeRepository) GetPeriodeByID(id uint64) (*postgres.PeriodeAkuntansi, error) {
+	var periode postgres.PeriodeAkuntansi
+	err := r.db.First(&periode, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &periode, nil
+}
+
+func (r *PeriodeRepository) GetPeriodeByKoperasi(koperasiID uint64, tahun int) ([]postgres.PeriodeAkuntansi, error) {
+	var periodes []postgres.PeriodeAkuntansi
+	err := r.db.Where("koperasi_id = ? AND tahun = ?", koperasiID, tahun).
+		Order("bulan ASC").Find(&periodes).Error
+	return periodes, err
+}
+
+func (r *PeriodeRepository) GetPeriodeByTanggal(koperasiID uint64, tanggal time.Time) (*postgres.PeriodeAkuntansi, error) {
+	var periode postgres.PeriodeAkuntansi
+	err := r.db.Where("koperasi_id = ? AND tahun = ? AND bulan = ?",
+		koperasiID, tanggal.Year(), int(tanggal.Month())).
+		First(&periode).Error
+	if err != nil {
+		return nil, err
+	}
+	return &periode, nil
+}
+
+func (r *PeriodeRepository) UpdatePeriodeStatus(id uint64, status string, updatedBy uint64) error {
+	return r.db.Model(&postgres.PeriodeAkuntansi{}).Where("id = ?", id).
+		Updates(periodeStatusUpdates(status, updatedBy)).Error
+}
+
+// UpdatePeriodeStatusFrom only changes the status while it is still dari, so
+// a reopen cannot undo a year-end close that locked the period meanwhile.
+func (r *PeriodeRepository) UpdatePeriodeStatusFrom(id uint64, dari, status string, updatedBy uint64) (bool, error) {
+	result := r.db.Model(&postgres.PeriodeAkuntansi{}).Where("id = ? AND status = ?", id, dari).
+		Updates(periodeStatusUpdates(status, updatedBy))
+	return result.RowsAffected > 0, result.Error
+}
+
+func periodeStatusUpdates(status string, updatedBy uint64) map[string]interface{} {
+	updates := map[string]interface{}{
+		"status": status,
+	}
+	if status == "open" {
+		updates["closed_at"] = nil
+		updates["closed_by"] = 0
+	} else {
+		now := time.Now()
+		updates["closed_at"] = &now
+		updates["closed_by"] = updatedBy
+	}
+	return updates
+}
+
+func (r *PeriodeRepository) LockPeriodeTahun(koperasiID uint64, tahun int, lockedBy uint64) error {
+	now := time.Now()
+	return r.db.Model(&postgres.PeriodeAkuntansi{}).
+		Where("koperasi_id = ? AND tahun = ?", koperasiID, tahun).
+		Updates(map[string]interface{}{
+			"status":    "locked",
+			"closed_at": &now,
+			"closed_by": lockedBy,
+		}).Error
+}
+
+// LockPeriodeSampai locks the koperasi's periods that end on or before
+// tanggal.
+func (r *PeriodeRepository) LockPeriodeSampai(koperasiID uint64, tanggal time.Time, lockedBy uint64) error {
+	now := time.Now()
+	return r.db.Model(&postgres.PeriodeAkuntansi{}).
+		Where("koperasi_id = ? AND tanggal_selesai <= ?", koperasiID, tanggal).
+		Updates(map[string]interface{}{
+			"status":    "locked",
+			"closed_at": &now,
+			"closed_by": lockedBy,
+		}).Error
+}
+
+func (r *PeriodeRepository) CreateReopenRequest(req *postgres.PeriodeReopenRequest) error {
+	return r.db.Create(req).Error
+}
+
+func (r *PeriodeRepository) GetReopenRequestByID(id uint64) (*postgres.PeriodeReopenRequest, error) {
+	var req postgres.PeriodeReopenRequest
+	err := r.db.Preload("Periode").First(&req, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func (r *PeriodeRepository) GetReopenRequestsByKoperasi(koperasiID uint64, status string) ([]postgres.PeriodeReopenRequest, error) {
+	var reqs []postgres.PeriodeReopenRequest
+	query := r.db.Joins("JOIN periode_akuntansis pa ON pa.id = periode_reopen_requests.periode_id").
+		Where("pa.koperasi_id = ?", koperasiID)
+	if status != "" {
+		query = query.Where("periode_reopen_requests.status = ?", status)
+	}
+	err := query.Preload("Periode").
+		Order("periode_reopen_requests.requested_at DESC").Find(&reqs).Error
+	return reqs, err
+}
+
+func (r *PeriodeRepository) CountPendingReopenRequests(periodeID uint64) (int64, error) {
+	var count int64
+	err := r.db.Model(&postgres.PeriodeReopenRequest{}).
+		Where("periode_id = ? AND status = ?", periodeID, "pending").
+		Count(&count).Error
+	return count, err
+}
+
+// ReviewReopenRequest records the review only while the request is still
+// pending, so two reviewers cannot both decide the same request.
+func (r *PeriodeRepository) ReviewReopenRequest(req *postgres.PeriodeReopenRequest) (bool, error) {
+	result := r.db.Model(&postgres.PeriodeReopenRequest{}).
+		Where("id = ? AND status = ?", req.ID, "pending").
+		Updates(map[string]interface{}{
+			"status":         req.Status,
+			"reviewed_by":    req.ReviewedBy,
+			"reviewed_at":    req.ReviewedAt,
+			"catatan_review": req.CatatanReview,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *PeriodeRepository) CreateAuditLog(log *postgres.AuditLog) error {
+	return r.db.Create(log).Error
+}
+
+func (r *PeriodeRepository) CreateTutupBuku(tutupBuku *postgres.TutupBuku) error {
+	return r.db.Create(tutupBuku).Error
+}
+
+func (r *PeriodeRepository) UpdateTutupBukuJurnal(id, jurnalID uint64) error {
+	return r.db.Model(&postgres.TutupBuku{}).Where("id = ?", id).
+		Update("jurnal_id", jurnalID).Error
+}
+
+func (r *PeriodeRepository) GetTutupBuku(koperasiID uint64, tahun int) (*postgres.TutupBuku, error) {
+	var tutupBuku postgres.TutupBuku
+	err := r.db.Where("koperasi_id = ? AND tahun = ?", koperasiID, tahun).
+		Preload("AkunSHU").First(&tutupBuku).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tutupBuku, nil
+}
+
+// GetTahunTutupBukuTerakhir returns the latest fiscal year the koperasi has
+// closed, or 0 when no year-end close has run.
+func (r *PeriodeRepository) GetTahunTutupBukuTerakhir(koperasiID uint64) (int, error) {
+	var tahun int
+	err := r.db.Model(&postgres.TutupBuku{}).
+		Where("koperasi_id = ?", koperasiID).
+		Select("COALESCE(MAX(tahun), 0)").
+		Scan(&tahun).Error
+	return tahun, err
+}
+
+func (r *PeriodeRepository) CreateSaldoAwal(saldos []postgres.SaldoAwalAkun) error {
+	if len(saldos) == 0 {
+		return nil
+	}
+	return r.db.Create(&saldos).Error
+}
+
+func (r *PeriodeRepository) GetSaldoAwal(koperasiID uint64, tahun int) ([]postgres.SaldoAwalAkun, error) {
+	var saldos []postgres.SaldoAwalAkun
+	err := r.db.Where("koperasi_id = ? AND tahun = ?", koperasiID, tahun).
+		Preload("Akun").Find(&saldos).Error
+	return saldos, err
+}
+
+// GetTanggalCutover returns the cutover date of the koperasi's posted
+// opening balance, or nil when it has none.
+func (r *PeriodeRepository) GetTanggalCutover(koperasiID uint64) (*time.Time, error) {
+	var migrasi postgres.MigrasiSaldoAwal
+	err := r.db.Select("tanggal_cutover").
+		Where("koperasi_id = ? AND status = ?", koperasiID, "posted").
+		Limit(1).Find(&migrasi).Error
+	if err != nil || migrasi.TanggalCutover.IsZero() {
+		return nil, err
+	}
+	return &migrasi.TanggalCutover, nil
+}
@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"gorm.io/gorm"
+	"koperasi-merah-putih/internal/models/postgres"
+)
+
+type PostingRepository struct {
+	db *gorm.DB
+}
+
+func NewPostingRepository(db *gorm.DB) *PostingRepository {
+	return &PostingRepository{db: db}
+}
+
+func (r *PostingRepository) WithTx(tx *gorm.DB) *PostingRepository {
+	return &PostingRepository{db: tx}
+}
+
+func (r *PostingRepository) CreatePostingRule(rule *postgres.PostingRule) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Lines").Create(rule).Error; err != nil {
+			return err
+		}
+
+		for i := range rule.Lines {
+			rule.Lines[i].PostingRuleID = rule.ID
+		}
+
+		return tx.Create(&rule.Lines).Error
+	})
+}
+
+func (r *PostingRepository) GetPostingRuleByID(id uint64) (*postgres.PostingRule, error) {
+	var rule postgres.PostingRule
+	err := r.db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("urutan ASC, id ASC")
+	}).Preload("Lines.Akun").First(&rule, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *PostingRepository) GetPostingRulesByKoperasi(koperasiID uint64) ([]postgres.PostingRule, error) {
+	var rules []postgres.PostingRule
+	err := r.db.Where("koperasi_id = ?", koperasiID).
+		Preload("Lines", func(db *gorm.DB) *gorm.DB {
+			return db.Order("urutan ASC, id ASC")
+		}).Preload("Lines.Akun").
+		Order("kode_event ASC, produk_simpan_pinjam_id ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+// GetActivePostingRule returns the rule for an event, preferring a rule bound
+// to the given simpan pinjam product over the koperasi-wide default.
+func (r *PostingRepository) GetActivePostingRule(koperasiID uint64, kodeEvent string, produkSimpanPinjamID uint64) (*postgres.PostingRule, error) {
+	var rule postgres.PostingRule
+	err := r.db.Where("koperasi_id = ? AND kode_event = ? AND is_aktif = ? AND produk_simpan_pinjam_id IN ?",
+		koperasiID, kodeEvent, true, []uint64{produkSimpanPinjamID, 0}).
+		Preload("Lines", func(db *gorm.DB) *gorm.DB {
+			return db.Order("urutan ASC, id ASC")
+		}).
+		Order("produk_simpan_pinjam_id DESC").
+		First(&rule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *PostingRepository) UpdatePostingRule(rule *postgres.PostingRule) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Lines").Save(rule).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("posting_rule_id = ?", rule.ID).Delete(&postgres.PostingRuleLine{}).Error; err != nil {
+			return err
+		}
+
+		for i := range rule.Lines {
+			rule.Lines[i].ID = 0
+			rule.Lines[i].PostingRuleID = rule.ID
+		}
+
+		return tx.Create(&rule.Lines).Error
+	})
+}
+
+func (r *PostingRepository) DeletePostingRule(id uint64) error {
+	return r.db.Model(&postgres.PostingRule{}).Where("id = ?", id).Update("is_aktif", false).Error
+}
@@ -4,7 +4,9 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
 )
 
 type PPOBRepository struct {
@@ -15,6 +17,14 @@ func NewPPOBRepository(db *gorm.DB) *PPOBRepository {
 	return &PPOBRepository{db: db}
 }
 
+func (r *PPOBRepository) WithTx(tx *gorm.DB) *PPOBRepository {
+	return &PPOBRepository{db: tx}
+}
+
+func (r *PPOBRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
 func (r *PPOBRepository) GetKategoriList() ([]postgres.PPOBKategori, error) {
 	var kategoris []postgres.PPOBKategori
 	err := r.db.Where("is_aktif = ?", true).Order("urutan ASC").Find(&kategoris).Error
@@ -43,7 +53,7 @@ func (r *PPOBRepository) CreateTransaksi(transaksi *postgres.PPOBTransaksi) erro
 
 func (r *PPOBRepository) GetTransaksiByID(id uint64) (*postgres.PPOBTransaksi, error) {
 	var transaksi postgres.PPOBTransaksi
-	err := r.db.Preload("Koperasi").Preload("Anggota").Preload("Produk").
+	err := r.db.Preload("Koperasi").Preload("Anggota").Preload("Produk.Provider").
 		Preload("Payment").First(&transaksi, id).Error
 	if err != nil {
 		return nil, err
@@ -71,6 +81,145 @@ func (r *PPOBRepository) UpdatePaymentStatus(id uint64, paymentStatus string) er
 	return r.db.Model(&postgres.PPOBTransaksi{}).Where("id = ?", id).Update("payment_status", paymentStatus).Error
 }
 
+// UpdatePaymentStatusFrom only changes payment_status while it is still
+// dari, so a duplicate payment callback does not trigger a second topup.
+func (r *PPOBRepository) UpdatePaymentStatusFrom(id uint64, dari, paymentStatus string) (bool, error) {
+	result := r.db.Model(&postgres.PPOBTransaksi{}).Where("id = ? AND payment_status = ?", id, dari).
+		Update("payment_status", paymentStatus)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *PPOBRepository) UpdateTransaksiPayment(id, paymentID uint64) error {
+	return r.db.Model(&postgres.PPOBTransaksi{}).Where("id = ?", id).Update("payment_id", paymentID).Error
+}
+
+func (r *PPOBRepository) UpdateTransaksiProvider(id uint64, status, nomorReferensi, pesanResponse string) error {
+	return r.db.Model(&postgres.PPOBTransaksi{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          status,
+		"nomor_referensi": nomorReferensi,
+		"pesan_response":  pesanResponse,
+	}).Error
+}
+
+func (r *PPOBRepository) GetTransaksiForUpdate(id uint64) (*postgres.PPOBTransaksi, error) {
+	var transaksi postgres.PPOBTransaksi
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transaksi, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transaksi, nil
+}
+
+func (r *PPOBRepository) MulaiRefund(id uint64, statusRefund string, jumlah money.Amount, alasan string) error {
+	return r.db.Model(&postgres.PPOBTransaksi{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status_refund": statusRefund,
+		"jumlah_refund": jumlah,
+		"alasan_refund": alasan,
+		"pesan_refund":  "",
+	}).Error
+}
+
+// UpdateStatusRefundFrom only moves status_refund from one of the dari
+// statuses, so one refund is never processed by two workers at once.
+func (r *PPOBRepository) UpdateStatusRefundFrom(id uint64, dari []string, statusRefund string) (bool, error) {
+	result := r.db.Model(&postgres.PPOBTransaksi{}).Where("id = ? AND status_refund IN ?", id, dari).
+		Update("status_refund", statusRefund)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *PPOBRepository) GagalRefund(id uint64, pesan string) error {
+	return r.db.Model(&postgres.PPOBTransaksi{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status_refund": postgres.PPOBRefundGagal,
+		"pesan_refund":  pesan,
+	}).Error
+}
+
+func (r *PPOBRepository) SelesaiRefund(id uint64, referensi string, tanggal time.Time) error {
+	return r.db.Model(&postgres.PPOBTransaksi{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status_refund":    postgres.PPOBRefundSukses,
+		"referensi_refund": referensi,
+		"tanggal_refund":   &tanggal,
+		"pesan_refund":     "",
+		"payment_status":   "refunded",
+	}).Error
+}
+
+func (r *PPOBRepository) UpdateRefundJurnal(id, jurnalID uint64) error {
+	return r.db.Model(&postgres.PPOBTransaksi{}).Where("id = ?", id).Update("refund_jurnal_id", jurnalID).Error
+}
+
+// GetRefundQueue returns the refunds that are not done yet, including the
+// failed ones waiting for an operator.
+func (r *PPOBRepository) GetRefundQueue(koperasiID uint64) ([]postgres.PPOBTransaksi, error) {
+	var transaksis []postgres.PPOBTransaksi
+	err := r.db.Where("koperasi_id = ? AND status_refund IN ?", koperasiID,
+		[]string{postgres.PPOBRefundPending, postgres.PPOBRefundProses, postgres.PPOBRefundGagal}).
+		Preload("Produk").Preload("Anggota").Preload("Payment").
+		Order("tanggal_transaksi ASC").Find(&transaksis).Error
+	return transaksis, err
+}
+
+func (r *PPOBRepository) GetTransaksiRefundPending() ([]postgres.PPOBTransaksi, error) {
+	var transaksis []postgres.PPOBTransaksi
+	err := r.db.Where("status_refund = ?", postgres.PPOBRefundPending).
+		Order("id ASC").Find(&transaksis).Error
+	return transaksis, err
+}
+
+// GetTransaksiPendingProvider returns paid transactions dated before sebelum
+// that the provider has not settled yet.
+func (r *PPOBRepository) GetTransaksiPendingProvider(sebelum time.Time) ([]postgres.PPOBTransaksi, error) {
+	var transaksis []postgres.PPOBTransaksi
+	err := r.db.Where("status = ? AND payment_status = ? AND tanggal_transaksi < ?", "pending", "paid", sebelum).
+		Order("id ASC").Find(&transaksis).Error
+	return transaksis, err
+}
+
+func (r *PPOBRepository) CreateInquiry(inquiry *postgres.PPOBInquiry) error {
+	return r.db.Create(inquiry).Error
+}
+
+func (r *PPOBRepository) GetInquiryByNomor(nomor string) (*postgres.PPOBInquiry, error) {
+	var inquiry postgres.PPOBInquiry
+	err := r.db.Where("nomor_inquiry = ?", nomor).First(&inquiry).Error
+	if err != nil {
+		return nil, err
+	}
+	return &inquiry, nil
+}
+
+// UseInquiry marks an inquiry as used by a transaction. Only an active,
+// unexpired inquiry can be used, and only once.
+func (r *PPOBRepository) UseInquiry(id, transaksiID uint64, now time.Time) (bool, error) {
+	result := r.db.Model(&postgres.PPOBInquiry{}).
+		Where("id = ? AND status = ? AND kedaluwarsa_pada > ?", id, postgres.PPOBInquiryAktif, now).
+		Updates(map[string]interface{}{
+			"status":       postgres.PPOBInquiryDipakai,
+			"transaksi_id": transaksiID,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *PPOBRepository) ExpireInquiries(now time.Time) (int64, error) {
+	result := r.db.Model(&postgres.PPOBInquiry{}).
+		Where("status = ? AND kedaluwarsa_pada <= ?", postgres.PPOBInquiryAktif, now).
+		Update("status", postgres.PPOBInquiryKedaluwarsa)
+	return result.RowsAffected, result.Error
+}
+
+func (r *PPOBRepository) GetProviderByID(id uint64) (*postgres.PPOBProvider, error) {
+	var provider postgres.PPOBProvider
+	err := r.db.First(&provider, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &provider, nil
+}
+
+func (r *PPOBRepository) UpdateTransaksiJurnal(id, jurnalID uint64) error {
+	return r.db.Model(&postgres.PPOBTransaksi{}).Where("id = ?", id).Update("jurnal_id", jurnalID).Error
+}
+
 func (r *PPOBRepository) GetTransaksiByKoperasi(koperasiID uint64, limit, offset int) ([]postgres.PPOBTransaksi, error) {
 	var transaksis []postgres.PPOBTransaksi
 	err := r.db.Where("koperasi_id = ?", koperasiID).
@@ -97,6 +246,34 @@ func (r *PPOBRepository) CreateSettlementDetails(details []postgres.PPOBSettleme
 	return r.db.Create(&details).Error
 }
 
+func (r *PPOBRepository) GetSettlementForUpdate(id uint64) (*postgres.PPOBSettlement, error) {
+	var settlement postgres.PPOBSettlement
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&settlement, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &settlement, nil
+}
+
+func (r *PPOBRepository) GetSettlementByID(id uint64) (*postgres.PPOBSettlement, error) {
+	var settlement postgres.PPOBSettlement
+	err := r.db.Preload("SettlementDetails").First(&settlement, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &settlement, nil
+}
+
+// HasSettlementForPeriode keeps auto settlement from settling the same
+// schedule period twice.
+func (r *PPOBRepository) HasSettlementForPeriode(koperasiID uint64, dari, sampai time.Time) (bool, error) {
+	var count int64
+	err := r.db.Model(&postgres.PPOBSettlement{}).
+		Where("koperasi_id = ? AND periode_dari = ? AND periode_sampai = ?", koperasiID, dari, sampai).
+		Count(&count).Error
+	return count > 0, err
+}
+
 func (r *PPOBRepository) UpdateSettlementStatus(id uint64, status string, processedBy uint64) error {
 	now := time.Now()
 	return r.db.Model(&postgres.PPOBSettlement{}).Where("id = ?", id).Updates(map[string]interface{}{
@@ -106,16 +283,39 @@ func (r *PPOBRepository) UpdateSettlementStatus(id uint64, status string, proces
 	}).Error
 }
 
-func (r *PPOBRepository) MarkTransaksiSettled(transaksiIDs []uint64) error {
-	now := time.Now()
-	return r.db.Model(&postgres.PPOBTransaksi{}).Where("id IN ?", transaksiIDs).
-		Update("tanggal_settlement", &now).Error
+func (r *PPOBRepository) UpdateSettlementJurnal(id, jurnalID uint64) error {
+	return r.db.Model(&postgres.PPOBSettlement{}).Where("id = ?", id).Update("jurnal_id", jurnalID).Error
+}
+
+func (r *PPOBRepository) UpdateSettlementPaid(id uint64, paidBy uint64, paidAt time.Time) error {
+	return r.db.Model(&postgres.PPOBSettlement{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":  postgres.PPOBSettlementPaid,
+		"paid_by": paidBy,
+		"paid_at": &paidAt,
+	}).Error
+}
+
+// MarkTransaksiSettled only marks successful transactions that are not in a
+// settlement yet. A row count below the number of ids means some were
+// refunded or settled by another process in the meantime.
+func (r *PPOBRepository) MarkTransaksiSettled(transaksiIDs []uint64, tanggal time.Time) (int64, error) {
+	result := r.db.Model(&postgres.PPOBTransaksi{}).
+		Where("id IN ? AND status = ? AND tanggal_settlement IS NULL", transaksiIDs, "success").
+		Update("tanggal_settlement", &tanggal)
+	return result.RowsAffected, result.Error
+}
+
+func (r *PPOBRepository) GetAutoSettlementConfigs() ([]postgres.PPOBPaymentConfig, error) {
+	var configs []postgres.PPOBPaymentConfig
+	err := r.db.Where("auto_settlement = ? AND is_active = ?", true, true).
+		Order("koperasi_id ASC").Find(&configs).Error
+	return configs, err
 }
 
 func (r *PPOBRepository) GetTransaksiByPaymentID(paymentID uint64) (*postgres.PPOBTransaksi, error) {
 	var transaksi postgres.PPOBTransaksi
 	err := r.db.Where("payment_id = ?", paymentID).
-		Preload("Koperasi").Preload("Anggota").Preload("Produk").
+		Preload("Koperasi").Preload("Anggota").Preload("Produk.Provider").
 		Preload("Payment").First(&transaksi).Error
 	if err != nil {
 		return nil, err
@@ -6,6 +6,9 @@ import (
 	"koperasi-merah-putih/internal/models/postgres"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"koperasi-merah-putih/internal/money"
 )
 
 type ProdukRepository struct {
@@ -16,6 +19,14 @@ func NewProdukRepository(db *gorm.DB) *ProdukRepository {
 	return &ProdukRepository{db: db}
 }
 
+func (r *ProdukRepository) WithTx(tx *gorm.DB) *ProdukRepository {
+	return &ProdukRepository{db: tx}
+}
+
+func (r *ProdukRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
 // Kategori Produk
 func (r *ProdukRepository) CreateKategoriProduk(kategori *postgres.KategoriProduk) error {
 	return r.db.Create(kategori).Error
@@ -93,6 +104,13 @@ func (r *ProdukRepository) GetSuppliersByKoperasi(koperasiID uint64, limit, offs
 	return suppliers, err
 }
 
+func (r *ProdukRepository) GetSuppliersByKode(koperasiID uint64, kodes []string) ([]postgres.Supplier, error) {
+	var suppliers []postgres.Supplier
+	err := r.db.Where("koperasi_id = ? AND kode IN ?", koperasiID, kodes).
+		Find(&suppliers).Error
+	return suppliers, err
+}
+
 func (r *ProdukRepository) UpdateSupplier(supplier *postgres.Supplier) error {
 	return r.db.Save(supplier).Error
 }
@@ -255,6 +273,21 @@ func (r *ProdukRepository) CreatePembelian(pembelian *postgres.PembelianHeader)
 	})
 }
 
+// CreatePembelianHeader saves an invoice without lines or stock movements,
+// as the opening-balance wizard brings in what is left of one.
+func (r *ProdukRepository) CreatePembelianHeader(pembelian *postgres.PembelianHeader) error {
+	return r.db.Omit(clause.Associations).Create(pembelian).Error
+}
+
+// GetNomorFakturTerpakai returns which of nomors are already in use.
+func (r *ProdukRepository) GetNomorFakturTerpakai(nomors []string) ([]string, error) {
+	var terpakai []string
+	err := r.db.Model(&postgres.PembelianHeader{}).
+		Where("nomor_faktur IN ?", nomors).
+		Pluck("nomor_faktur", &terpakai).Error
+	return terpakai, err
+}
+
 func (r *ProdukRepository) GetPembelianByID(id uint64) (*postgres.PembelianHeader, error) {
 	var pembelian postgres.PembelianHeader
 	err := r.db.Preload("Supplier").Preload("PembelianDetail.Produk").First(&pembelian, id).Error
@@ -264,10 +297,88 @@ func (r *ProdukRepository) GetPembelianByID(id uint64) (*postgres.PembelianHeade
 	return &pembelian, nil
 }
 
+func (r *ProdukRepository) UpdatePembelianJurnal(id, jurnalID uint64) error {
+	return r.db.Model(&postgres.PembelianHeader{}).Where("id = ?", id).Update("jurnal_id", jurnalID).Error
+}
+
+// Hutang Usaha
+func (r *ProdukRepository) GetPembelianForUpdate(id uint64) (*postgres.PembelianHeader, error) {
+	var pembelian postgres.PembelianHeader
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&pembelian, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pembelian, nil
+}
+
+// GetPembelianBelumLunas locks the supplier's invoices that still have an
+// outstanding balance, oldest first.
+func (r *ProdukRepository) GetPembelianBelumLunas(koperasiID, supplierID uint64) ([]postgres.PembelianHeader, error) {
+	var pembelians []postgres.PembelianHeader
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("koperasi_id = ? AND supplier_id = ? AND status_pembayaran <> ?", koperasiID, supplierID, "paid").
+		Order("tanggal_faktur ASC, id ASC").Find(&pembelians).Error
+	return pembelians, err
+}
+
+// GetPembelianTerbuka returns invoices dated up to tanggal that were not
+// fully paid on that date, with their supplier and payments.
+func (r *ProdukRepository) GetPembelianTerbuka(koperasiID uint64, tanggal time.Time) ([]postgres.PembelianHeader, error) {
+	var pembelians []postgres.PembelianHeader
+	err := r.db.Where("koperasi_id = ? AND tanggal_faktur <= ?", koperasiID, tanggal).
+		Where("status_pembayaran <> ? OR id IN (SELECT pembelian_header_id FROM pembayaran_pembelians WHERE tanggal_bayar > ? AND deleted_at IS NULL)", "paid", tanggal).
+		Preload("Supplier").Preload("PembayaranPembelian").
+		Order("supplier_id ASC, tanggal_faktur ASC, id ASC").Find(&pembelians).Error
+	return pembelians, err
+}
+
+func (r *ProdukRepository) GetPembelianBySupplier(supplierID uint64) ([]postgres.PembelianHeader, error) {
+	var pembelians []postgres.PembelianHeader
+	err := r.db.Where("supplier_id = ?", supplierID).
+		Preload("PembayaranPembelian").
+		Order("tanggal_faktur ASC, id ASC").Find(&pembelians).Error
+	return pembelians, err
+}
+
+func (r *ProdukRepository) UpdatePembelianPembayaran(id uint64, totalBayar money.Amount, status string, updatedBy uint64) error {
+	return r.db.Model(&postgres.PembelianHeader{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"total_bayar":       totalBayar,
+		"status_pembayaran": status,
+		"updated_by":        updatedBy,
+	}).Error
+}
+
+func (r *ProdukRepository) CreatePembayaranPembelian(pembayaran *postgres.PembayaranPembelian) error {
+	return r.db.Create(pembayaran).Error
+}
+
+func (r *ProdukRepository) UpdatePembayaranJurnal(nomorPembayaran string, jurnalID uint64) error {
+	return r.db.Model(&postgres.PembayaranPembelian{}).
+		Where("nomor_pembayaran = ?", nomorPembayaran).
+		Update("jurnal_id", jurnalID).Error
+}
+
+func (r *ProdukRepository) GetPembayaranByID(id uint64) (*postgres.PembayaranPembelian, error) {
+	var pembayaran postgres.PembayaranPembelian
+	err := r.db.Preload("PembelianHeader").First(&pembayaran, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pembayaran, nil
+}
+
+func (r *ProdukRepository) GetPembayaranByNomor(nomorPembayaran string) ([]postgres.PembayaranPembelian, error) {
+	var pembayarans []postgres.PembayaranPembelian
+	err := r.db.Where("nomor_pembayaran = ?", nomorPembayaran).
+		Preload("PembelianHeader").
+		Order("id ASC").Find(&pembayarans).Error
+	return pembayarans, err
+}
+
 // Penjualan
 func (r *ProdukRepository) CreatePenjualan(penjualan *postgres.PenjualanHeader) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Create(penjualan).Error; err != nil {
+		if err := tx.Omit("PenjualanDetail").Create(penjualan).Error; err != nil {
 			return err
 		}
 
@@ -315,6 +426,21 @@ func (r *ProdukRepository) CreatePenjualan(penjualan *postgres.PenjualanHeader)
 	})
 }
 
+// CreatePenjualanHeader saves a sale without lines or stock movements, as
+// the opening-balance wizard brings in what is left of one.
+func (r *ProdukRepository) CreatePenjualanHeader(penjualan *postgres.PenjualanHeader) error {
+	return r.db.Omit(clause.Associations).Create(penjualan).Error
+}
+
+// GetNomorTransaksiTerpakai returns which of nomors are already in use.
+func (r *ProdukRepository) GetNomorTransaksiTerpakai(nomors []string) ([]string, error) {
+	var terpakai []string
+	err := r.db.Model(&postgres.PenjualanHeader{}).
+		Where("nomor_transaksi IN ?", nomors).
+		Pluck("nomor_transaksi", &terpakai).Error
+	return terpakai, err
+}
+
 func (r *ProdukRepository) GetPenjualanByID(id uint64) (*postgres.PenjualanHeader, error) {
 	var penjualan postgres.PenjualanHeader
 	err := r.db.Preload("Anggota").Preload("PenjualanDetail.Produk").First(&penjualan, id).Error
@@ -324,6 +450,17 @@ func (r *ProdukRepository) GetPenjualanByID(id uint64) (*postgres.PenjualanHeade
 	return &penjualan, nil
 }
 
+func (r *ProdukRepository) UpdatePenjualanJurnal(id, jurnalID uint64) error {
+	return r.db.Model(&postgres.PenjualanHeader{}).Where("id = ?", id).Update("jurnal_id", jurnalID).Error
+}
+
+func (r *ProdukRepository) UpdatePenjualanFakturPajak(id uint64, nomorFakturPajak string, updatedBy uint64) error {
+	return r.db.Model(&postgres.PenjualanHeader{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"nomor_faktur_pajak": nomorFakturPajak,
+		"updated_by":         updatedBy,
+	}).Error
+}
+
 func (r *ProdukRepository) GetPenjualansByKoperasi(koperasiID uint64, startDate, endDate time.Time, limit, offset int) ([]postgres.PenjualanHeader, error) {
 	var penjualan []postgres.PenjualanHeader
 	err := r.db.Where("koperasi_id = ? AND tanggal_transaksi BETWEEN ? AND ?", koperasiID, startDate, endDate).
@@ -332,6 +469,92 @@ func (r *ProdukRepository) GetPenjualansByKoperasi(koperasiID uint64, startDate,
 	return penjualan, err
 }
 
+// Pajak
+// GetPenjualanKenaPajak returns the sales in [dari, sampai) that carry PPN,
+// with their buyer and lines for the e-Faktur keluaran export.
+func (r *ProdukRepository) GetPenjualanKenaPajak(koperasiID uint64, dari, sampai time.Time) ([]postgres.PenjualanHeader, error) {
+	var penjualan []postgres.PenjualanHeader
+	err := r.db.Where("koperasi_id = ? AND total_pajak > 0 AND tanggal_transaksi >= ? AND tanggal_transaksi < ?", koperasiID, dari, sampai).
+		Preload("Anggota").Preload("PenjualanDetail.Produk").
+		Order("tanggal_transaksi ASC, id ASC").Find(&penjualan).Error
+	return penjualan, err
+}
+
+// GetPembelianKenaPajak returns the purchases in [dari, sampai) that carry
+// PPN, with their supplier, for the e-Faktur masukan export.
+func (r *ProdukRepository) GetPembelianKenaPajak(koperasiID uint64, dari, sampai time.Time) ([]postgres.PembelianHeader, error) {
+	var pembelian []postgres.PembelianHeader
+	err := r.db.Where("koperasi_id = ? AND total_pajak > 0 AND tanggal_faktur >= ? AND tanggal_faktur < ?", koperasiID, dari, sampai).
+		Preload("Supplier").
+		Order("tanggal_faktur ASC, id ASC").Find(&pembelian).Error
+	return pembelian, err
+}
+
+// Piutang Usaha
+func (r *ProdukRepository) GetPenjualanForUpdate(id uint64) (*postgres.PenjualanHeader, error) {
+	var penjualan postgres.PenjualanHeader
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&penjualan, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &penjualan, nil
+}
+
+// GetPenjualanBelumLunas locks the member's credit sales that still have an
+// outstanding balance, oldest first.
+func (r *ProdukRepository) GetPenjualanBelumLunas(koperasiID, anggotaID uint64) ([]postgres.PenjualanHeader, error) {
+	var penjualans []postgres.PenjualanHeader
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("koperasi_id = ? AND anggota_id = ? AND metode_pembayaran = ? AND status_pembayaran <> ?", koperasiID, anggotaID, "credit", "paid").
+		Order("tanggal_transaksi ASC, id ASC").Find(&penjualans).Error
+	return penjualans, err
+}
+
+// GetPenjualanTerbuka returns credit sales dated up to tanggal that were not
+// fully collected on that date, with their member and collections.
+func (r *ProdukRepository) GetPenjualanTerbuka(koperasiID uint64, tanggal time.Time) ([]postgres.PenjualanHeader, error) {
+	var penjualans []postgres.PenjualanHeader
+	err := r.db.Where("koperasi_id = ? AND metode_pembayaran = ? AND tanggal_transaksi <= ?", koperasiID, "credit", tanggal).
+		Where("status_pembayaran <> ? OR id IN (SELECT penjualan_header_id FROM pembayaran_penjualans WHERE tanggal_bayar > ? AND deleted_at IS NULL)", "paid", tanggal).
+		Preload("Anggota").Preload("PembayaranPenjualan").
+		Order("anggota_id ASC, tanggal_transaksi ASC, id ASC").Find(&penjualans).Error
+	return penjualans, err
+}
+
+func (r *ProdukRepository) GetPenjualanKreditByAnggota(anggotaID uint64) ([]postgres.PenjualanHeader, error) {
+	var penjualans []postgres.PenjualanHeader
+	err := r.db.Where("anggota_id = ? AND metode_pembayaran = ?", anggotaID, "credit").
+		Preload("PembayaranPenjualan").
+		Order("tanggal_transaksi ASC, id ASC").Find(&penjualans).Error
+	return penjualans, err
+}
+
+func (r *ProdukRepository) UpdatePenjualanPembayaran(id uint64, totalTerbayar money.Amount, status string, updatedBy uint64) error {
+	return r.db.Model(&postgres.PenjualanHeader{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"total_terbayar":    totalTerbayar,
+		"status_pembayaran": status,
+		"updated_by":        updatedBy,
+	}).Error
+}
+
+func (r *ProdukRepository) CreatePembayaranPenjualan(pembayaran *postgres.PembayaranPenjualan) error {
+	return r.db.Create(pembayaran).Error
+}
+
+func (r *ProdukRepository) UpdatePembayaranPenjualanJurnal(nomorPembayaran string, jurnalID uint64) error {
+	return r.db.Model(&postgres.PembayaranPenjualan{}).
+		Where("nomor_pembayaran = ?", nomorPembayaran).
+		Update("jurnal_id", jurnalID).Error
+}
+
+func (r *ProdukRepository) GetPembayaranPenjualanByNomor(nomorPembayaran string) ([]postgres.PembayaranPenjualan, error) {
+	var pembayarans []postgres.PembayaranPenjualan
+	err := r.db.Where("nomor_pembayaran = ?", nomorPembayaran).
+		Preload("PenjualanHeader").
+		Order("id ASC").Find(&pembayarans).Error
+	return pembayarans, err
+}
+
 // Stok Movement
 func (r *ProdukRepository) CreateStokMovement(movement *postgres.StokMovement) error {
 	return r.db.Create(movement).Error
@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"koperasi-merah-putih/internal/models/postgres"
+)
+
+type SaldoAwalRepository struct {
+	db *gorm.DB
+}
+
+func NewSaldoAwalRepository(db *gorm.DB) *SaldoAwalRepository {
+	return &SaldoAwalRepository{db: db}
+}
+
+func (r *SaldoAwalRepository) WithTx(tx *gorm.DB) *SaldoAwalRepository {
+	return &SaldoAwalRepository{db: tx}
+}
+
+func (r *SaldoAwalRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
+func (r *SaldoAwalRepository) CreateMigrasi(migrasi *postgres.MigrasiSaldoAwal) error {
+	return r.db.Create(migrasi).Error
+}
+
+func (r *SaldoAwalRepository) UpdateMigrasi(migrasi *postgres.MigrasiSaldoAwal) error {
+	return r.db.Omit(clause.Associations).Save(migrasi).Error
+}
+
+// GetMigrasiByKoperasi loads the wizard with its trial balance, rekening and
+// faktur lines.
+func (r *SaldoAwalRepository) GetMigrasiByKoperasi(koperasiID uint64) (*postgres.MigrasiSaldoAwal, error) {
+	var migrasi postgres.MigrasiSaldoAwal
+	err := r.db.Where("koperasi_id = ?", koperasiID).
+		Preload("Akun", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Preload("Akun.Akun").
+		Preload("Rekening", func(db *gorm.DB) *gorm.DB {
+			return db.Order("baris ASC, id ASC")
+		}).
+		Preload("Rekening.Anggota").Preload("Rekening.Produk").
+		Preload("Faktur", func(db *gorm.DB) *gorm.DB {
+			return db.Order("baris ASC, id ASC")
+		}).
+		Preload("Faktur.Supplier").Preload("Faktur.Anggota").
+		First(&migrasi).Error
+	if err != nil {
+		return nil, err
+	}
+	return &migrasi, nil
+}
+
+// GetMigrasiForUpdate locks the wizard row so uploads and posting of the same
+// koperasi run one at a time.
+func (r *SaldoAwalRepository) GetMigrasiForUpdate(koperasiID uint64) (*postgres.MigrasiSaldoAwal, error) {
+	var migrasi postgres.MigrasiSaldoAwal
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("koperasi_id = ?", koperasiID).
+		First(&migrasi).Error
+	if err != nil {
+		return nil, err
+	}
+	return &migrasi, nil
+}
+
+// ReplaceAkun swaps the wizard's trial balance for lines.
+func (r *SaldoAwalRepository) ReplaceAkun(migrasiID uint64, lines []postgres.MigrasiSaldoAwalAkun) error {
+	if err := r.db.Where("migrasi_id = ?", migrasiID).Delete(&postgres.MigrasiSaldoAwalAkun{}).Error; err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return r.db.Omit(clause.Associations).CreateInBatches(&lines, 500).Error
+}
+
+// ReplaceRekening swaps the wizard's member balances for rows.
+func (r *SaldoAwalRepository) ReplaceRekening(migrasiID uint64, rows []postgres.MigrasiSaldoAwalRekening) error {
+	if err := r.db.Where("migrasi_id = ?", migrasiID).Delete(&postgres.MigrasiSaldoAwalRekening{}).Error; err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return r.db.Omit(clause.Associations).CreateInBatches(&rows, 500).Error
+}
+
+func (r *SaldoAwalRepository) UpdateRekeningID(id, rekeningID uint64) error {
+	return r.db.Model(&postgres.MigrasiSaldoAwalRekening{}).Where("id = ?", id).
+		Update("rekening_id", rekeningID).Error
+}
+
+// ReplaceFaktur swaps the wizard's open invoices for rows.
+func (r *SaldoAwalRepository) ReplaceFaktur(migrasiID uint64, rows []postgres.MigrasiSaldoAwalFaktur) error {
+	if err := r.db.Where("migrasi_id = ?", migrasiID).Delete(&postgres.MigrasiSaldoAwalFaktur{}).Error; err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return r.db.Omit(clause.Associations).CreateInBatches(&rows, 500).Error
+}
+
+func (r *SaldoAwalRepository) UpdateFaktur(id uint64, updates map[string]interface{}) error {
+	return r.db.Model(&postgres.MigrasiSaldoAwalFaktur{}).Where("id = ?", id).Updates(updates).Error
+}
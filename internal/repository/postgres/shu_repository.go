@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"koperasi-merah-putih/internal/models/postgres"
+
+	"koperasi-merah-putih/internal/money"
+)
+
+type SHURepository struct {
+	db *gorm.DB
+}
+
+func NewSHURepository(db *gorm.DB) *SHURepository {
+	return &SHURepository{db: db}
+}
+
+func (r *SHURepository) WithTx(tx *gorm.DB) *SHURepository {
+	return &SHURepository{db: tx}
+}
+
+func (r *SHURepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
+// SaveSHUConfig creates or replaces the koperasi's SHU configuration
+// together with its allocation lines.
+func (r *SHURepository) SaveSHUConfig(config *postgres.SHUConfig) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Alokasi").Save(config).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("shu_config_id = ?", config.ID).Delete(&postgres.SHUConfigAlokasi{}).Error; err != nil {
+			return err
+		}
+
+		for i := range config.Alokasi {
+			config.Alokasi[i].ID = 0
+			config.Alokasi[i].SHUConfigID = config.ID
+		}
+
+		return tx.Create(&config.Alokasi).Error
+	})
+}
+
+func (r *SHURepository) GetSHUConfig(koperasiID uint64) (*postgres.SHUConfig, error) {
+	var config postgres.SHUConfig
+	err := r.db.Where("koperasi_id = ?", koperasiID).
+		Preload("Alokasi").Preload("Alokasi.Akun").
+		Preload("AkunSHU").Preload("ProdukSimpanan").Preload("AkunSimpanan").
+		First(&config).Error
+	if err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (r *SHURepository) CreatePerhitungan(perhitungan *postgres.SHUPerhitungan) error {
+	return r.db.Create(perhitungan).Error
+}
+
+func (r *SHURepository) GetPerhitunganByID(id uint64) (*postgres.SHUPerhitungan, error) {
+	var perhitungan postgres.SHUPerhitungan
+	err := r.db.Preload("Alokasi").
+		Preload("Anggota", func(db *gorm.DB) *gorm.DB {
+			return db.Order("total_shu DESC")
+		}).Preload("Anggota.Anggota").
+		First(&perhitungan, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &perhitungan, nil
+}
+
+func (r *SHURepository) GetPerhitunganByTahun(koperasiID uint64, tahun int) (*postgres.SHUPerhitungan, error) {
+	var perhitungan postgres.SHUPerhitungan
+	err := r.db.Where("koperasi_id = ? AND tahun = ?", koperasiID, tahun).
+		First(&perhitungan).Error
+	if err != nil {
+		return nil, err
+	}
+	return &perhitungan, nil
+}
+
+func (r *SHURepository) GetPerhitunganByKoperasi(koperasiID uint64) ([]postgres.SHUPerhitungan, error) {
+	var perhitungans []postgres.SHUPerhitungan
+	err := r.db.Where("koperasi_id = ?", koperasiID).
+		Order("tahun DESC").Find(&perhitungans).Error
+	return perhitungans, err
+}
+
+func (r *SHURepository) DeletePerhitungan(id uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("shu_perhitungan_id = ?", id).Delete(&postgres.SHUAnggota{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("shu_perhitungan_id = ?", id).Delete(&postgres.SHUAlokasi{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&postgres.SHUPerhitungan{}, id).Error
+	})
+}
+
+func (r *SHURepository) UpdatePerhitungan(id uint64, updates map[string]interface{}) error {
+	return r.db.Model(&postgres.SHUPerhitungan{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (r *SHURepository) UpdateSHUAnggotaPembayaran(id, rekeningID, transaksiID uint64) error {
+	return r.db.Model(&postgres.SHUAnggota{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"rekening_id":                rekeningID,
+			"transaksi_simpan_pinjam_id": transaksiID,
+		}).Error
+}
+
+// GetSaldoSimpananHistory returns every balance change on the koperasi's
+// simpanan accounts up to sampai, oldest first, so month-end balances can be
+// replayed per member.
+func (r *SHURepository) GetSaldoSimpananHistory(koperasiID uint64, sampai time.Time) ([]SaldoSimpananHistory, error) {
+	var items []SaldoSimpananHistory
+
+	err := r.db.Table("transaksi_simpan_pinjams t").
+		Select("r.anggota_id, t.rekening_id, t.tanggal_transaksi, t.saldo_sesudah").
+		Joins("JOIN rekening_simpan_pinjams r ON r.id = t.rekening_id").
+		Joins("JOIN produk_simpan_pinjams p ON p.id = r.produk_id").
+		Where("t.koperasi_id = ? AND p.jenis = ? AND t.tanggal_transaksi <= ?", koperasiID, "simpanan", sampai).
+		Order("t.tanggal_transaksi ASC, t.id ASC").
+		Scan(&items).Error
+
+	return items, err
+}
+
+// GetTransaksiUsahaAnggota sums each member's business with the koperasi
+// between dari and sampai, grouped by source: paid penjualan, loan interest
+// paid and successful PPOB purchases.
+func (r *SHURepository) GetTransaksiUsahaAnggota(koperasiID uint64, dari, sampai time.Time) ([]TransaksiUsahaAnggota, error) {
+	var items []TransaksiUsahaAnggota
+
+	err := r.db.Raw(`
+		SELECT anggota_id, 'penjualan' AS sumber, SUM(grand_total) AS jumlah
+		FROM penjualan_headers
+		WHERE koperasi_id = ? AND anggota_id > 0 AND status_pembayaran = 'paid'
+			AND deleted_at IS NULL AND tanggal_transaksi BETWEEN ? AND ?
+		GROUP BY anggota_id
+		UNION ALL
+		SELECT r.anggota_id, 'bunga_pinjaman' AS sumber, SUM(t.jumlah) AS jumlah
+		FROM transaksi_simpan_pinjams t
+		JOIN rekening_simpan_pinjams r ON r.id = t.rekening_id
+		JOIN produk_simpan_pinjams p ON p.id = r.produk_id
+		WHERE t.koperasi_id = ? AND p.jenis = 'pinjaman' AND t.jenis_transaksi = 'bunga'
+			AND t.tanggal_transaksi BETWEEN ? AND ?
+		GROUP BY r.anggota_id
+		UNION ALL
+		SELECT anggota_id, 'ppob' AS sumber, SUM(harga_jual) AS jumlah
+		FROM ppob_transaksis
+		WHERE koperasi_id = ? AND anggota_id > 0 AND status = 'success'
+			AND tanggal_transaksi BETWEEN ? AND ?
+		GROUP BY anggota_id
+	`, koperasiID, dari, sampai,
+		koperasiID, dari, sampai,
+		koperasiID, dari, sampai).
+		Scan(&items).Error
+
+	return items, err
+}
+
+type SaldoSimpananHistory struct {
+	AnggotaID        uint64       `json:"anggota_id"`
+	RekeningID       uint64       `json:"rekening_id"`
+	TanggalTransaksi time.Time    `json:"tanggal_transaksi"`
+	SaldoSesudah     money.Amount `json:"saldo_sesudah"`
+}
+
+type TransaksiUsahaAnggota struct {
+	AnggotaID uint64       `json:"anggota_id"`
+	Sumber    string       `json:"sumber"`
+	Jumlah    money.Amount `json:"jumlah"`
+}
@@ -4,7 +4,10 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"koperasi-merah-putih/internal/models/postgres"
+
+	"koperasi-merah-putih/internal/money"
 )
 
 type SimpanPinjamRepository struct {
@@ -15,6 +18,14 @@ func NewSimpanPinjamRepository(db *gorm.DB) *SimpanPinjamRepository {
 	return &SimpanPinjamRepository{db: db}
 }
 
+func (r *SimpanPinjamRepository) WithTx(tx *gorm.DB) *SimpanPinjamRepository {
+	return &SimpanPinjamRepository{db: tx}
+}
+
+func (r *SimpanPinjamRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
 func (r *SimpanPinjamRepository) CreateProduk(produk *postgres.ProdukSimpanPinjam) error {
 	return r.db.Create(produk).Error
 }
@@ -71,6 +82,15 @@ func (r *SimpanPinjamRepository) GetRekeningByNomor(nomorRekening string) (*post
 	return &rekening, nil
 }
 
+// GetNomorRekeningTerpakai returns which of nomors are already in use.
+func (r *SimpanPinjamRepository) GetNomorRekeningTerpakai(nomors []string) ([]string, error) {
+	var terpakai []string
+	err := r.db.Model(&postgres.RekeningSimpanPinjam{}).
+		Where("nomor_rekening IN ?", nomors).
+		Pluck("nomor_rekening", &terpakai).Error
+	return terpakai, err
+}
+
 func (r *SimpanPinjamRepository) GetRekeningByAnggota(anggotaID uint64) ([]postgres.RekeningSimpanPinjam, error) {
 	var rekenings []postgres.RekeningSimpanPinjam
 	err := r.db.Where("anggota_id = ? AND status = ?", anggotaID, "aktif").
@@ -78,6 +98,36 @@ func (r *SimpanPinjamRepository) GetRekeningByAnggota(anggotaID uint64) ([]postg
 	return rekenings, err
 }
 
+// GetRekeningForUpdate loads a rekening with a row lock so concurrent
+// mutations of the same balance are serialised. Must run inside a transaction.
+func (r *SimpanPinjamRepository) GetRekeningForUpdate(id uint64) (*postgres.RekeningSimpanPinjam, error) {
+	var rekening postgres.RekeningSimpanPinjam
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&rekening, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.First(&rekening.Produk, rekening.ProdukID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rekening, nil
+}
+
+func (r *SimpanPinjamRepository) GetRekeningByAnggotaProduk(anggotaID, produkID uint64) (*postgres.RekeningSimpanPinjam, error) {
+	var rekening postgres.RekeningSimpanPinjam
+	err := r.db.Where("anggota_id = ? AND produk_id = ? AND status = ?", anggotaID, produkID, "aktif").
+		Order("id ASC").First(&rekening).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rekening, nil
+}
+
+func (r *SimpanPinjamRepository) UpdateSaldoSimpanan(id uint64, saldo money.Amount) error {
+	return r.db.Model(&postgres.RekeningSimpanPinjam{}).Where("id = ?", id).Update("saldo_simpanan", saldo).Error
+}
+
 func (r *SimpanPinjamRepository) UpdateRekening(rekening *postgres.RekeningSimpanPinjam) error {
 	return r.db.Save(rekening).Error
 }
@@ -86,6 +136,10 @@ func (r *SimpanPinjamRepository) CreateTransaksi(transaksi *postgres.TransaksiSi
 	return r.db.Create(transaksi).Error
 }
 
+func (r *SimpanPinjamRepository) UpdateTransaksiJurnal(id, jurnalID uint64) error {
+	return r.db.Model(&postgres.TransaksiSimpanPinjam{}).Where("id = ?", id).Update("jurnal_id", jurnalID).Error
+}
+
 func (r *SimpanPinjamRepository) GetTransaksiByID(id uint64) (*postgres.TransaksiSimpanPinjam, error) {
 	var transaksi postgres.TransaksiSimpanPinjam
 	err := r.db.Preload("Koperasi").Preload("Rekening").Preload("Jurnal").
@@ -144,6 +198,6 @@ func (r *SimpanPinjamRepository) GetStatistikSimpanPinjam(koperasiID uint64) (*S
 type SimpanPinjamStatistik struct {
 	TotalRekeningSimpanan uint64  `json:"total_rekening_simpanan"`
 	TotalRekeningPinjaman uint64  `json:"total_rekening_pinjaman"`
-	TotalSaldoSimpanan    float64 `json:"total_saldo_simpanan"`
-	TotalSisaPinjaman     float64 `json:"total_sisa_pinjaman"`
+	TotalSaldoSimpanan    money.Amount `json:"total_saldo_simpanan"`
+	TotalSisaPinjaman     money.Amount `json:"total_sisa_pinjaman"`
 }
\ No newline at end of file
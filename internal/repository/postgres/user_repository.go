@@ -81,6 +81,14 @@ func NewUserRegistrationRepository(db *gorm.DB) *UserRegistrationRepository {
 	return &UserRegistrationRepository{db: db}
 }
 
+func (r *UserRegistrationRepository) WithTx(tx *gorm.DB) *UserRegistrationRepository {
+	return &UserRegistrationRepository{db: tx}
+}
+
+func (r *UserRegistrationRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
 func (r *UserRegistrationRepository) Create(registration *postgres.UserRegistration) error {
 	return r.db.Create(registration).Error
 }
@@ -111,6 +119,14 @@ func (r *UserRegistrationRepository) UpdateStatus(id uint64, status string) erro
 	return r.db.Model(&postgres.UserRegistration{}).Where("id = ?", id).Update("status", status).Error
 }
 
+// UpdateStatusFrom moves a registration to status only while it is still in
+// dari, so a payment cannot be verified twice.
+func (r *UserRegistrationRepository) UpdateStatusFrom(id uint64, dari, status string) (bool, error) {
+	result := r.db.Model(&postgres.UserRegistration{}).Where("id = ? AND status = ?", id, dari).
+		Update("status", status)
+	return result.RowsAffected > 0, result.Error
+}
+
 func (r *UserRegistrationRepository) GetExpiredRegistrations() ([]postgres.UserRegistration, error) {
 	var registrations []postgres.UserRegistration
 	now := time.Now()
@@ -122,4 +138,17 @@ func (r *UserRegistrationRepository) GetPendingApproval(koperasiID uint64) ([]po
 	var registrations []postgres.UserRegistration
 	err := r.db.Where("koperasi_id = ? AND status = ?", koperasiID, "payment_verified").Find(&registrations).Error
 	return registrations, err
+}
+
+func (r *UserRegistrationRepository) CreateSimpananPokokTransaksi(transaksi *postgres.SimpananPokokTransaksi) error {
+	return r.db.Create(transaksi).Error
+}
+
+func (r *UserRegistrationRepository) UpdateSimpananPokokJurnal(id, jurnalID uint64) error {
+	return r.db.Model(&postgres.SimpananPokokTransaksi{}).Where("id = ?", id).Update("jurnal_id", jurnalID).Error
+}
+
+func (r *UserRegistrationRepository) UpdateSimpananPokokAnggota(registrationID, anggotaID uint64) error {
+	return r.db.Model(&postgres.SimpananPokokTransaksi{}).Where("registration_id = ?", registrationID).
+		Update("anggota_id", anggotaID).Error
 }
\ No newline at end of file
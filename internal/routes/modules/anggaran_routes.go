@@ -0,0 +1,36 @@
+package modules
+
+import (
+	"github.com/gin-gonic/gin"
+	"koperasi-merah-putih/internal/handlers"
+	"koperasi-merah-putih/internal/middleware"
+)
+
+type AnggaranRoutes struct {
+	anggaranHandler *handlers.AnggaranHandler
+	rbacMiddleware  *middleware.RBACMiddleware
+}
+
+func NewAnggaranRoutes(anggaranHandler *handlers.AnggaranHandler, rbacMiddleware *middleware.RBACMiddleware) *AnggaranRoutes {
+	return &AnggaranRoutes{
+		anggaranHandler: anggaranHandler,
+		rbacMiddleware:  rbacMiddleware,
+	}
+}
+
+func (r *AnggaranRoutes) SetupRoutes(router *gin.RouterGroup) {
+	anggaran := router.Group("/anggaran")
+	anggaran.Use(middleware.AuthMiddleware(), r.rbacMiddleware.RequireKoperasiAccess(), r.rbacMiddleware.FinancialAccess())
+	{
+		// RAPB Versions
+		anggaran.POST("/rapb", r.rbacMiddleware.AdminOnly(), r.anggaranHandler.CreateAnggaran)
+		anggaran.GET("/:koperasi_id/rapb", r.anggaranHandler.GetAnggaranList)
+		anggaran.GET("/rapb/:id", r.anggaranHandler.GetAnggaran)
+		anggaran.PUT("/rapb/:id", r.rbacMiddleware.AdminOnly(), r.anggaranHandler.UpdateAnggaran)
+		anggaran.POST("/rapb/:id/revisi", r.rbacMiddleware.AdminOnly(), r.anggaranHandler.ReviseAnggaran)
+		anggaran.PUT("/rapb/:id/approve", r.rbacMiddleware.AdminOnly(), r.anggaranHandler.ApproveAnggaran)
+
+		// Budget vs Actual
+		anggaran.GET("/:koperasi_id/realisasi", r.anggaranHandler.GetRealisasi)
+	}
+}
@@ -0,0 +1,43 @@
+package modules
+
+import (
+	"github.com/gin-gonic/gin"
+	"koperasi-merah-putih/internal/handlers"
+	"koperasi-merah-putih/internal/middleware"
+)
+
+type AsetRoutes struct {
+	asetHandler    *handlers.AsetHandler
+	rbacMiddleware *middleware.RBACMiddleware
+}
+
+func NewAsetRoutes(asetHandler *handlers.AsetHandler, rbacMiddleware *middleware.RBACMiddleware) *AsetRoutes {
+	return &AsetRoutes{
+		asetHandler:    asetHandler,
+		rbacMiddleware: rbacMiddleware,
+	}
+}
+
+func (r *AsetRoutes) SetupRoutes(router *gin.RouterGroup) {
+	aset := router.Group("/aset")
+	aset.Use(middleware.AuthMiddleware(), r.rbacMiddleware.RequireKoperasiAccess(), r.rbacMiddleware.FinancialAccess())
+	{
+		// Asset Categories
+		aset.POST("/kategori", r.rbacMiddleware.AdminOnly(), r.asetHandler.CreateKategori)
+		aset.GET("/:koperasi_id/kategori", r.asetHandler.GetKategoriList)
+		aset.PUT("/kategori/:id", r.rbacMiddleware.AdminOnly(), r.asetHandler.UpdateKategori)
+
+		// Fixed Assets
+		aset.POST("/tetap", r.rbacMiddleware.AdminOnly(), r.asetHandler.CreateAset)
+		aset.GET("/:koperasi_id/tetap", r.asetHandler.GetAsetList)
+		aset.GET("/tetap/:id", r.asetHandler.GetAset)
+		aset.GET("/tetap/:id/penyusutan", r.asetHandler.GetRiwayatPenyusutan)
+		aset.POST("/tetap/:id/lepas", r.rbacMiddleware.AdminOnly(), r.asetHandler.LepasAset)
+
+		// Depreciation
+		aset.POST("/penyusutan", r.rbacMiddleware.AdminOnly(), r.asetHandler.RunPenyusutan)
+
+		// Reports
+		aset.GET("/:koperasi_id/register", r.asetHandler.GetRegister)
+	}
+}
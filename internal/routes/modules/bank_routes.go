@@ -0,0 +1,44 @@
+package modules
+
+import (
+	"github.com/gin-gonic/gin"
+	"koperasi-merah-putih/internal/handlers"
+	"koperasi-merah-putih/internal/middleware"
+)
+
+type BankRoutes struct {
+	bankHandler    *handlers.BankHandler
+	rbacMiddleware *middleware.RBACMiddleware
+}
+
+func NewBankRoutes(bankHandler *handlers.BankHandler, rbacMiddleware *middleware.RBACMiddleware) *BankRoutes {
+	return &BankRoutes{
+		bankHandler:    bankHandler,
+		rbacMiddleware: rbacMiddleware,
+	}
+}
+
+func (r *BankRoutes) SetupRoutes(router *gin.RouterGroup) {
+	bank := router.Group("/bank")
+	bank.Use(middleware.AuthMiddleware(), r.rbacMiddleware.RequireKoperasiAccess(), r.rbacMiddleware.FinancialAccess())
+	{
+		// Bank Accounts
+		bank.POST("/rekening", r.rbacMiddleware.AdminOnly(), r.bankHandler.CreateRekeningBank)
+		bank.GET("/:koperasi_id/rekening", r.bankHandler.GetRekeningBankList)
+
+		// Statement Import
+		bank.POST("/rekening/:id/import", r.bankHandler.ImportRekeningKoran)
+		bank.GET("/rekening/:id/rekening-koran", r.bankHandler.GetRekeningKoranList)
+		bank.GET("/rekening/:id/mutasi", r.bankHandler.GetMutasiList)
+
+		// Matching
+		bank.POST("/rekening/:id/auto-match", r.bankHandler.AutoMatch)
+		bank.GET("/mutasi/:id/kandidat", r.bankHandler.GetKandidatMatch)
+		bank.PUT("/mutasi/:id/match", r.bankHandler.MatchMutasi)
+		bank.PUT("/mutasi/:id/unmatch", r.bankHandler.UnmatchMutasi)
+		bank.POST("/mutasi/:id/jurnal", r.bankHandler.BuatJurnal)
+
+		// Reconciliation
+		bank.GET("/rekening/:id/rekonsiliasi", r.bankHandler.GetRekonsiliasi)
+	}
+}
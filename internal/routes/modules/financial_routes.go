@@ -26,6 +26,10 @@ func (r *FinancialRoutes) SetupRoutes(router *gin.RouterGroup) {
 		financial.POST("/coa/akun", r.rbacMiddleware.AdminOnly(), r.financialHandler.CreateCOAAkun)
 		financial.GET("/:koperasi_id/coa/akun", r.financialHandler.GetCOAAkunList)
 		financial.GET("/coa/kategori", r.financialHandler.GetCOAKategoriList)
+		financial.GET("/:koperasi_id/coa/export", r.financialHandler.ExportCOA)
+		financial.POST("/:koperasi_id/coa/import", r.rbacMiddleware.AdminOnly(), r.financialHandler.ImportCOA)
+		financial.GET("/coa/template", r.financialHandler.GetCOATemplateList)
+		financial.POST("/:koperasi_id/coa/template", r.rbacMiddleware.AdminOnly(), r.financialHandler.ApplyCOATemplate)
 
 		// Journal Management
 		financial.POST("/jurnal", r.financialHandler.CreateJurnal)
@@ -33,11 +37,53 @@ func (r *FinancialRoutes) SetupRoutes(router *gin.RouterGroup) {
 		financial.GET("/jurnal/:id", r.financialHandler.GetJurnal)
 		financial.PUT("/jurnal/:id/post", r.financialHandler.PostJurnal)
 		financial.PUT("/jurnal/:id/cancel", r.financialHandler.CancelJurnal)
+		financial.PUT("/jurnal/:id/wajib-lampiran", r.rbacMiddleware.AdminOnly(), r.financialHandler.SetWajibLampiran)
+		financial.POST("/jurnal/:id/reverse", r.financialHandler.ReverseJurnal)
+		financial.GET("/jurnal/sumber/:sumber/:sumber_id", r.financialHandler.GetJurnalBySumber)
+		financial.POST("/jurnal/sumber/:sumber/:sumber_id/reverse", r.financialHandler.ReverseJurnalBySumber)
+		financial.GET("/:koperasi_id/rantai-jurnal/verifikasi", r.financialHandler.VerifikasiRantaiJurnal)
+
+		// Automatic Posting Rules
+		financial.GET("/posting-events", r.financialHandler.GetPostingEvents)
+		financial.POST("/posting-rules", r.rbacMiddleware.AdminOnly(), r.financialHandler.CreatePostingRule)
+		financial.GET("/:koperasi_id/posting-rules", r.financialHandler.GetPostingRuleList)
+		financial.GET("/posting-rules/:id", r.financialHandler.GetPostingRule)
+		financial.PUT("/posting-rules/:id", r.rbacMiddleware.AdminOnly(), r.financialHandler.UpdatePostingRule)
+		financial.DELETE("/posting-rules/:id", r.rbacMiddleware.AdminOnly(), r.financialHandler.DeletePostingRule)
+
+		// Accounting Periods
+		financial.POST("/periode/generate", r.rbacMiddleware.AdminOnly(), r.financialHandler.GeneratePeriode)
+		financial.GET("/:koperasi_id/periode", r.financialHandler.GetPeriodeList)
+		financial.PUT("/periode/:id/close", r.rbacMiddleware.AdminOnly(), r.financialHandler.ClosePeriode)
+		financial.POST("/periode/:id/reopen-request", r.financialHandler.RequestReopenPeriode)
+		financial.GET("/:koperasi_id/periode/reopen-requests", r.financialHandler.GetReopenRequestList)
+		financial.PUT("/periode/reopen-requests/:id/review", r.rbacMiddleware.AdminOnly(), r.financialHandler.ReviewReopenPeriode)
+		financial.POST("/tutup-buku", r.rbacMiddleware.AdminOnly(), r.financialHandler.TutupBuku)
+		financial.GET("/:koperasi_id/tutup-buku/:tahun", r.financialHandler.GetTutupBuku)
 
 		// Financial Reports
 		financial.GET("/:koperasi_id/neraca-saldo", r.financialHandler.GetNeracaSaldo)
 		financial.GET("/:koperasi_id/laba-rugi", r.financialHandler.GetLabaRugi)
 		financial.GET("/:koperasi_id/neraca", r.financialHandler.GetNeraca)
+		financial.GET("/:koperasi_id/laporan-keuangan", r.financialHandler.GetLaporanKeuangan)
 		financial.GET("/akun/:akun_id/saldo", r.financialHandler.GetSaldoAkun)
+		financial.GET("/:koperasi_id/arus-kas", r.financialHandler.GetArusKas)
+		financial.GET("/:koperasi_id/buku-besar", r.financialHandler.GetBukuBesar)
+		financial.GET("/:koperasi_id/buku-besar/export", r.financialHandler.ExportBukuBesar)
+
+		// Cash Flow Mapping
+		financial.POST("/arus-kas/mapping", r.rbacMiddleware.AdminOnly(), r.financialHandler.CreateArusKasMapping)
+		financial.GET("/:koperasi_id/arus-kas/mapping", r.financialHandler.GetArusKasMappingList)
+		financial.DELETE("/arus-kas/mapping/:id", r.rbacMiddleware.AdminOnly(), r.financialHandler.DeleteArusKasMapping)
+
+		// Recurring Journal Templates
+		financial.POST("/jurnal-template", r.rbacMiddleware.AdminOnly(), r.financialHandler.CreateJurnalTemplate)
+		financial.GET("/:koperasi_id/jurnal-template", r.financialHandler.GetJurnalTemplateList)
+		financial.GET("/jurnal-template/:id", r.financialHandler.GetJurnalTemplate)
+		financial.PUT("/jurnal-template/:id", r.rbacMiddleware.AdminOnly(), r.financialHandler.UpdateJurnalTemplate)
+		financial.PUT("/jurnal-template/:id/status", r.rbacMiddleware.AdminOnly(), r.financialHandler.SetJurnalTemplateAktif)
+		financial.PUT("/jurnal-template/:id/parameter", r.financialHandler.SetJurnalTemplateParameter)
+		financial.POST("/jurnal-template/:id/generate", r.financialHandler.GenerateJurnalTemplate)
+		financial.GET("/jurnal-template/:id/riwayat", r.financialHandler.GetJurnalTemplateRiwayat)
 	}
 }
\ No newline at end of file
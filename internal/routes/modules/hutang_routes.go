@@ -0,0 +1,33 @@
+package modules
+
+import (
+	"github.com/gin-gonic/gin"
+	"koperasi-merah-putih/internal/handlers"
+	"koperasi-merah-putih/internal/middleware"
+)
+
+type HutangRoutes struct {
+	hutangHandler  *handlers.HutangHandler
+	rbacMiddleware *middleware.RBACMiddleware
+}
+
+func NewHutangRoutes(hutangHandler *handlers.HutangHandler, rbacMiddleware *middleware.RBACMiddleware) *HutangRoutes {
+	return &HutangRoutes{
+		hutangHandler:  hutangHandler,
+		rbacMiddleware: rbacMiddleware,
+	}
+}
+
+func (r *HutangRoutes) SetupRoutes(router *gin.RouterGroup) {
+	hutang := router.Group("/hutang")
+	hutang.Use(middleware.AuthMiddleware(), r.rbacMiddleware.RequireKoperasiAccess(), r.rbacMiddleware.FinancialAccess())
+	{
+		// Supplier Payments
+		hutang.POST("/pembayaran", r.rbacMiddleware.AdminOnly(), r.hutangHandler.BayarHutang)
+		hutang.GET("/pembayaran/:nomor", r.hutangHandler.GetPembayaran)
+
+		// Reports
+		hutang.GET("/supplier/:supplier_id/kartu", r.hutangHandler.GetKartuHutang)
+		hutang.GET("/:koperasi_id/umur", r.hutangHandler.GetUmurHutang)
+	}
+}
@@ -35,6 +35,7 @@ func (r *KlinikRoutes) SetupRoutes(router *gin.RouterGroup) {
 		// Kunjungan Management
 		klinik.POST("/kunjungan", r.klinikHandler.CreateKunjungan)
 		klinik.GET("/kunjungan/:id", r.klinikHandler.GetKunjungan)
+		klinik.PUT("/kunjungan/:id/bayar", r.klinikHandler.BayarKunjungan)
 		klinik.GET("/pasien/:id/kunjungan", r.klinikHandler.GetKunjunganByPasien)
 
 		// Obat Management
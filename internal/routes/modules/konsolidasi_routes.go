@@ -0,0 +1,40 @@
+package modules
+
+import (
+	"github.com/gin-gonic/gin"
+	"koperasi-merah-putih/internal/handlers"
+	"koperasi-merah-putih/internal/middleware"
+)
+
+type KonsolidasiRoutes struct {
+	konsolidasiHandler *handlers.KonsolidasiHandler
+	rbacMiddleware     *middleware.RBACMiddleware
+}
+
+func NewKonsolidasiRoutes(konsolidasiHandler *handlers.KonsolidasiHandler, rbacMiddleware *middleware.RBACMiddleware) *KonsolidasiRoutes {
+	return &KonsolidasiRoutes{
+		konsolidasiHandler: konsolidasiHandler,
+		rbacMiddleware:     rbacMiddleware,
+	}
+}
+
+func (r *KonsolidasiRoutes) SetupRoutes(router *gin.RouterGroup) {
+	konsolidasi := router.Group("/konsolidasi")
+	konsolidasi.Use(middleware.AuthMiddleware(), r.rbacMiddleware.RequireTenantAccess(), r.rbacMiddleware.FinancialAccess())
+	{
+		// Common reporting structure
+		konsolidasi.GET("/pos", r.konsolidasiHandler.GetPosList)
+		konsolidasi.POST("/pos", r.rbacMiddleware.AdminOnly(), r.konsolidasiHandler.CreatePos)
+		konsolidasi.PUT("/pos/:id", r.rbacMiddleware.AdminOnly(), r.konsolidasiHandler.UpdatePos)
+		konsolidasi.DELETE("/pos/:id", r.rbacMiddleware.AdminOnly(), r.konsolidasiHandler.DeletePos)
+
+		// Akun mapping and inter-koperasi akun
+		konsolidasi.GET("/pemetaan/:koperasi_id", r.konsolidasiHandler.GetPemetaan)
+		konsolidasi.PUT("/pemetaan", r.rbacMiddleware.AdminOnly(), r.konsolidasiHandler.SimpanPemetaan)
+
+		// Consolidated reports, ?koperasi_ids=1,2,3 (default all)
+		konsolidasi.GET("/neraca-saldo", r.konsolidasiHandler.GetNeracaSaldo)
+		konsolidasi.GET("/neraca", r.konsolidasiHandler.GetNeraca)
+		konsolidasi.GET("/laba-rugi", r.konsolidasiHandler.GetLabaRugi)
+	}
+}
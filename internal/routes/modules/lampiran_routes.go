@@ -0,0 +1,33 @@
+package modules
+
+import (
+	"github.com/gin-gonic/gin"
+	"koperasi-merah-putih/internal/handlers"
+	"koperasi-merah-putih/internal/middleware"
+)
+
+type LampiranRoutes struct {
+	lampiranHandler *handlers.LampiranHandler
+	rbacMiddleware  *middleware.RBACMiddleware
+}
+
+func NewLampiranRoutes(lampiranHandler *handlers.LampiranHandler, rbacMiddleware *middleware.RBACMiddleware) *LampiranRoutes {
+	return &LampiranRoutes{
+		lampiranHandler: lampiranHandler,
+		rbacMiddleware:  rbacMiddleware,
+	}
+}
+
+func (r *LampiranRoutes) SetupRoutes(router *gin.RouterGroup) {
+	lampiran := router.Group("/lampiran")
+	lampiran.Use(middleware.AuthMiddleware(), r.rbacMiddleware.RequireKoperasiAccess(), r.rbacMiddleware.FinancialAccess())
+	{
+		// Attachments per document: jurnal_umum, pembelian,
+		// pembayaran_pembelian or transaksi_simpan_pinjam
+		lampiran.POST("/:jenis/:dokumen_id", r.lampiranHandler.UploadLampiran)
+		lampiran.GET("/:jenis/:dokumen_id", r.lampiranHandler.GetLampiranList)
+
+		lampiran.GET("/file/:id", r.lampiranHandler.DownloadLampiran)
+		lampiran.DELETE("/file/:id", r.rbacMiddleware.AdminOnly(), r.lampiranHandler.DeleteLampiran)
+	}
+}
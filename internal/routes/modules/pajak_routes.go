@@ -0,0 +1,36 @@
+package modules
+
+import (
+	"github.com/gin-gonic/gin"
+	"koperasi-merah-putih/internal/handlers"
+	"koperasi-merah-putih/internal/middleware"
+)
+
+type PajakRoutes struct {
+	pajakHandler   *handlers.PajakHandler
+	rbacMiddleware *middleware.RBACMiddleware
+}
+
+func NewPajakRoutes(pajakHandler *handlers.PajakHandler, rbacMiddleware *middleware.RBACMiddleware) *PajakRoutes {
+	return &PajakRoutes{
+		pajakHandler:   pajakHandler,
+		rbacMiddleware: rbacMiddleware,
+	}
+}
+
+func (r *PajakRoutes) SetupRoutes(router *gin.RouterGroup) {
+	pajak := router.Group("/pajak")
+	pajak.Use(middleware.AuthMiddleware(), r.rbacMiddleware.RequireKoperasiAccess(), r.rbacMiddleware.FinancialAccess())
+	{
+		// Configuration
+		pajak.PUT("/pengaturan", r.rbacMiddleware.AdminOnly(), r.pajakHandler.SimpanPengaturan)
+		pajak.GET("/:koperasi_id/pengaturan", r.pajakHandler.GetPengaturan)
+
+		// Monthly reporting and settlement
+		pajak.GET("/:koperasi_id/rekap", r.pajakHandler.GetRekap)
+		pajak.GET("/:koperasi_id/potongan", r.pajakHandler.GetPotongan)
+		pajak.GET("/:koperasi_id/export", r.pajakHandler.ExportPajak)
+		pajak.PUT("/penjualan/:id/faktur-pajak", r.rbacMiddleware.AdminOnly(), r.pajakHandler.SetNomorFakturPajak)
+		pajak.POST("/setoran", r.rbacMiddleware.AdminOnly(), r.pajakHandler.SetorPajak)
+	}
+}
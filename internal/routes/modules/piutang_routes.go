@@ -0,0 +1,33 @@
+package modules
+
+import (
+	"github.com/gin-gonic/gin"
+	"koperasi-merah-putih/internal/handlers"
+	"koperasi-merah-putih/internal/middleware"
+)
+
+type PiutangRoutes struct {
+	piutangHandler *handlers.PiutangHandler
+	rbacMiddleware *middleware.RBACMiddleware
+}
+
+func NewPiutangRoutes(piutangHandler *handlers.PiutangHandler, rbacMiddleware *middleware.RBACMiddleware) *PiutangRoutes {
+	return &PiutangRoutes{
+		piutangHandler: piutangHandler,
+		rbacMiddleware: rbacMiddleware,
+	}
+}
+
+func (r *PiutangRoutes) SetupRoutes(router *gin.RouterGroup) {
+	piutang := router.Group("/piutang")
+	piutang.Use(middleware.AuthMiddleware(), r.rbacMiddleware.RequireKoperasiAccess(), r.rbacMiddleware.FinancialAccess())
+	{
+		// Member Collections
+		piutang.POST("/pembayaran", r.rbacMiddleware.AdminOnly(), r.piutangHandler.BayarPiutang)
+		piutang.GET("/pembayaran/:nomor", r.piutangHandler.GetPembayaran)
+
+		// Reports
+		piutang.GET("/anggota/:anggota_id/kartu", r.piutangHandler.GetKartuPiutang)
+		piutang.GET("/:koperasi_id/umur", r.piutangHandler.GetUmurPiutang)
+	}
+}
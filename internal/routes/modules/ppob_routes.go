@@ -24,13 +24,23 @@ func (r *PPOBRoutes) SetupRoutes(router *gin.RouterGroup) {
 		// Public PPOB endpoints
 		ppob.GET("/kategoris", r.ppobHandler.GetKategoriList)
 		ppob.GET("/kategoris/:kategori_id/produks", r.ppobHandler.GetProdukByKategori)
-		ppob.POST("/transactions", r.rbacMiddleware.PPOBAccess(), r.ppobHandler.CreateTransaction)
+		ppob.POST("/inquiry", r.rbacMiddleware.PPOBAccess(), r.ppobHandler.Inquiry)
+		ppob.POST("/transactions", middleware.AuthMiddleware(), r.rbacMiddleware.RequireKoperasiAccess(),
+			r.rbacMiddleware.PPOBAccess(), r.ppobHandler.CreateTransaction)
 	}
 
 	// Protected PPOB endpoints
 	ppobProtected := ppob.Group("")
 	ppobProtected.Use(middleware.AuthMiddleware(), r.rbacMiddleware.RequireKoperasiAccess())
 	{
+		ppobProtected.POST("/transactions/:id/cek-status", r.ppobHandler.CekStatusTransaksi)
+		ppobProtected.POST("/transactions/:id/refund", r.rbacMiddleware.AdminOnly(), r.ppobHandler.RefundTransaksi)
+		ppobProtected.GET("/refunds", r.rbacMiddleware.AdminOnly(), r.ppobHandler.GetRefundQueue)
+		ppobProtected.POST("/refunds/:id/retry", r.rbacMiddleware.AdminOnly(), r.ppobHandler.RetryRefund)
+		ppobProtected.POST("/refunds/:id/selesai", r.rbacMiddleware.AdminOnly(), r.ppobHandler.SelesaikanRefund)
+		ppobProtected.GET("/providers/:id/saldo", r.rbacMiddleware.AdminOnly(), r.ppobHandler.GetSaldoProvider)
 		ppobProtected.POST("/settlements", r.rbacMiddleware.AdminOnly(), r.ppobHandler.CreateSettlement)
+		ppobProtected.POST("/settlements/:id/process", r.rbacMiddleware.AdminOnly(), r.ppobHandler.ProcessSettlement)
+		ppobProtected.POST("/settlements/:id/paid", r.rbacMiddleware.AdminOnly(), r.ppobHandler.PaySettlement)
 	}
 }
\ No newline at end of file
@@ -0,0 +1,34 @@
+package modules
+
+import (
+	"github.com/gin-gonic/gin"
+	"koperasi-merah-putih/internal/handlers"
+	"koperasi-merah-putih/internal/middleware"
+)
+
+type SaldoAwalRoutes struct {
+	saldoAwalHandler *handlers.SaldoAwalHandler
+	rbacMiddleware   *middleware.RBACMiddleware
+}
+
+func NewSaldoAwalRoutes(saldoAwalHandler *handlers.SaldoAwalHandler, rbacMiddleware *middleware.RBACMiddleware) *SaldoAwalRoutes {
+	return &SaldoAwalRoutes{
+		saldoAwalHandler: saldoAwalHandler,
+		rbacMiddleware:   rbacMiddleware,
+	}
+}
+
+func (r *SaldoAwalRoutes) SetupRoutes(router *gin.RouterGroup) {
+	saldoAwal := router.Group("/saldo-awal")
+	saldoAwal.Use(middleware.AuthMiddleware(), r.rbacMiddleware.RequireKoperasiAccess(), r.rbacMiddleware.FinancialAccess())
+	{
+		// Opening Balance Wizard
+		saldoAwal.POST("", r.rbacMiddleware.AdminOnly(), r.saldoAwalHandler.CreateMigrasi)
+		saldoAwal.GET("/:koperasi_id", r.saldoAwalHandler.GetMigrasi)
+		saldoAwal.POST("/:koperasi_id/neraca", r.rbacMiddleware.AdminOnly(), r.saldoAwalHandler.ImportNeraca)
+		saldoAwal.POST("/:koperasi_id/rekening", r.rbacMiddleware.AdminOnly(), r.saldoAwalHandler.ImportRekening)
+		saldoAwal.POST("/:koperasi_id/faktur", r.rbacMiddleware.AdminOnly(), r.saldoAwalHandler.ImportFaktur)
+		saldoAwal.GET("/:koperasi_id/rekonsiliasi", r.saldoAwalHandler.GetRekonsiliasi)
+		saldoAwal.POST("/:koperasi_id/posting", r.rbacMiddleware.AdminOnly(), r.saldoAwalHandler.PostMigrasi)
+	}
+}
@@ -0,0 +1,36 @@
+package modules
+
+import (
+	"github.com/gin-gonic/gin"
+	"koperasi-merah-putih/internal/handlers"
+	"koperasi-merah-putih/internal/middleware"
+)
+
+type SHURoutes struct {
+	shuHandler     *handlers.SHUHandler
+	rbacMiddleware *middleware.RBACMiddleware
+}
+
+func NewSHURoutes(shuHandler *handlers.SHUHandler, rbacMiddleware *middleware.RBACMiddleware) *SHURoutes {
+	return &SHURoutes{
+		shuHandler:     shuHandler,
+		rbacMiddleware: rbacMiddleware,
+	}
+}
+
+func (r *SHURoutes) SetupRoutes(router *gin.RouterGroup) {
+	shu := router.Group("/shu")
+	shu.Use(middleware.AuthMiddleware(), r.rbacMiddleware.RequireKoperasiAccess(), r.rbacMiddleware.FinancialAccess())
+	{
+		// Configuration
+		shu.PUT("/config", r.rbacMiddleware.AdminOnly(), r.shuHandler.SaveConfig)
+		shu.GET("/:koperasi_id/config", r.shuHandler.GetConfig)
+
+		// Calculation and Distribution
+		shu.POST("/hitung", r.shuHandler.Hitung)
+		shu.GET("/:koperasi_id/perhitungan", r.shuHandler.GetPerhitunganList)
+		shu.GET("/perhitungan/:id", r.shuHandler.GetPerhitungan)
+		shu.PUT("/perhitungan/:id/approve", r.rbacMiddleware.AdminOnly(), r.shuHandler.Approve)
+		shu.PUT("/perhitungan/:id/bayar", r.rbacMiddleware.AdminOnly(), r.shuHandler.Bayar)
+	}
+}
@@ -17,6 +17,16 @@ type Routes struct {
 	klinikRoutes     *modules.KlinikRoutes
 	produkRoutes     *modules.ProdukRoutes
 	financialRoutes  *modules.FinancialRoutes
+	shuRoutes        *modules.SHURoutes
+	anggaranRoutes   *modules.AnggaranRoutes
+	bankRoutes       *modules.BankRoutes
+	asetRoutes       *modules.AsetRoutes
+	hutangRoutes     *modules.HutangRoutes
+	piutangRoutes    *modules.PiutangRoutes
+	saldoAwalRoutes  *modules.SaldoAwalRoutes
+	pajakRoutes      *modules.PajakRoutes
+	lampiranRoutes   *modules.LampiranRoutes
+	konsolidasiRoutes *modules.KonsolidasiRoutes
 	masterDataRoutes *modules.MasterDataRoutes
 	adminRoutes      *modules.AdminRoutes
 	reportingRoutes  *modules.ReportingRoutes
@@ -33,6 +43,16 @@ func NewRoutes(
 	simpanPinjamHandler *handlers.SimpanPinjamHandler,
 	klinikHandler *handlers.KlinikHandler,
 	financialHandler *handlers.FinancialHandler,
+	shuHandler *handlers.SHUHandler,
+	anggaranHandler *handlers.AnggaranHandler,
+	bankHandler *handlers.BankHandler,
+	asetHandler *handlers.AsetHandler,
+	hutangHandler *handlers.HutangHandler,
+	piutangHandler *handlers.PiutangHandler,
+	saldoAwalHandler *handlers.SaldoAwalHandler,
+	pajakHandler *handlers.PajakHandler,
+	lampiranHandler *handlers.LampiranHandler,
+	konsolidasiHandler *handlers.KonsolidasiHandler,
 	wilayahHandler *handlers.WilayahHandler,
 	masterDataHandler *handlers.MasterDataHandler,
 	sequenceHandler *handlers.SequenceHandler,
@@ -50,6 +70,16 @@ func NewRoutes(
 		klinikRoutes:     modules.NewKlinikRoutes(klinikHandler, rbacMiddleware),
 		produkRoutes:     modules.NewProdukRoutes(produkHandler, rbacMiddleware),
 		financialRoutes:  modules.NewFinancialRoutes(financialHandler, rbacMiddleware),
+		shuRoutes:        modules.NewSHURoutes(shuHandler, rbacMiddleware),
+		anggaranRoutes:   modules.NewAnggaranRoutes(anggaranHandler, rbacMiddleware),
+		bankRoutes:       modules.NewBankRoutes(bankHandler, rbacMiddleware),
+		asetRoutes:       modules.NewAsetRoutes(asetHandler, rbacMiddleware),
+		hutangRoutes:     modules.NewHutangRoutes(hutangHandler, rbacMiddleware),
+		piutangRoutes:    modules.NewPiutangRoutes(piutangHandler, rbacMiddleware),
+		saldoAwalRoutes:  modules.NewSaldoAwalRoutes(saldoAwalHandler, rbacMiddleware),
+		pajakRoutes:      modules.NewPajakRoutes(pajakHandler, rbacMiddleware),
+		lampiranRoutes:   modules.NewLampiranRoutes(lampiranHandler, rbacMiddleware),
+		konsolidasiRoutes: modules.NewKonsolidasiRoutes(konsolidasiHandler, rbacMiddleware),
 		masterDataRoutes: modules.NewMasterDataRoutes(masterDataHandler, rbacMiddleware),
 		adminRoutes:      modules.NewAdminRoutes(sequenceHandler, rbacMiddleware),
 		reportingRoutes:  modules.NewReportingRoutes(reportingHandler, rbacMiddleware),
@@ -76,6 +106,16 @@ func (r *Routes) SetupRoutes(router *gin.Engine) {
 	r.klinikRoutes.SetupRoutes(api)
 	r.produkRoutes.SetupRoutes(api)
 	r.financialRoutes.SetupRoutes(api)
+	r.shuRoutes.SetupRoutes(api)
+	r.anggaranRoutes.SetupRoutes(api)
+	r.bankRoutes.SetupRoutes(api)
+	r.asetRoutes.SetupRoutes(api)
+	r.hutangRoutes.SetupRoutes(api)
+	r.piutangRoutes.SetupRoutes(api)
+	r.saldoAwalRoutes.SetupRoutes(api)
+	r.pajakRoutes.SetupRoutes(api)
+	r.lampiranRoutes.SetupRoutes(api)
+	r.konsolidasiRoutes.SetupRoutes(api)
 	r.masterDataRoutes.SetupRoutes(api)
 	r.adminRoutes.SetupRoutes(api)
 	r.reportingRoutes.SetupRoutes(api)
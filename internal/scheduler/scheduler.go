@@ -0,0 +1,78 @@
+package scheduler
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Job runs once per tick with the tick time. Errors are logged and the job
+// keeps its schedule.
+type Job func(now time.Time) error
+
+type entry struct {
+	name     string
+	interval time.Duration
+	job      Job
+}
+
+// Scheduler runs background jobs at fixed intervals inside the API process.
+// Jobs must be safe to run again after a failure or restart; the services
+// behind them track what is due in the database.
+type Scheduler struct {
+	entries []entry
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+func New() *Scheduler {
+	return &Scheduler{stop: make(chan struct{})}
+}
+
+// Every registers job to run every interval once the scheduler is started.
+func (s *Scheduler) Every(name string, interval time.Duration, job Job) {
+	s.entries = append(s.entries, entry{name: name, interval: interval, job: job})
+}
+
+// Start runs every job once right away and then on its interval.
+func (s *Scheduler) Start() {
+	for _, e := range s.entries {
+		s.wg.Add(1)
+		go s.loop(e)
+	}
+}
+
+// Stop signals all jobs to finish and waits for running ones.
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(e entry) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	s.run(e, time.Now())
+	for {
+		select {
+		case now := <-ticker.C:
+			s.run(e, now)
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *Scheduler) run(e entry, now time.Time) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Scheduler job %s panicked: %v", e.name, r)
+		}
+	}()
+
+	if err := e.job(now); err != nil {
+		log.Printf("Scheduler job %s failed: %v", e.name, err)
+	}
+}
@@ -0,0 +1,462 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+)
+
+type AnggaranService struct {
+	anggaranRepo  *postgresRepo.AnggaranRepository
+	financialRepo *postgresRepo.FinancialRepository
+}
+
+func NewAnggaranService(
+	anggaranRepo *postgresRepo.AnggaranRepository,
+	financialRepo *postgresRepo.FinancialRepository,
+) *AnggaranService {
+	return &AnggaranService{
+		anggaranRepo:  anggaranRepo,
+		financialRepo: financialRepo,
+	}
+}
+
+// CreateAnggaran drafts the original RAPB (versi 1) for a year. Later changes
+// go through ReviseAnggaran so the RAT-approved figures stay on record.
+func (s *AnggaranService) CreateAnggaran(req *CreateAnggaranRequest, createdBy uint64) (*postgres.Anggaran, error) {
+	versi, err := s.anggaranRepo.GetMaxVersi(req.KoperasiID, req.Tahun)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing anggaran: %v", err)
+	}
+	if versi > 0 {
+		return nil, fmt.Errorf("anggaran %d already exists, create a revision instead", req.Tahun)
+	}
+
+	details, err := s.buildDetails(req.KoperasiID, req.Details)
+	if err != nil {
+		return nil, err
+	}
+
+	kontrol := req.KontrolAnggaran
+	if kontrol == "" {
+		kontrol = "none"
+	}
+
+	anggaran := &postgres.Anggaran{
+		TenantID:        req.TenantID,
+		KoperasiID:      req.KoperasiID,
+		Tahun:           req.Tahun,
+		Versi:           1,
+		Jenis:           "awal",
+		Keterangan:      req.Keterangan,
+		KontrolAnggaran: kontrol,
+		Status:          "draft",
+		CreatedBy:       createdBy,
+		Details:         details,
+	}
+
+	err = s.anggaranRepo.CreateAnggaran(anggaran)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create anggaran: %v", err)
+	}
+
+	return anggaran, nil
+}
+
+// UpdateAnggaran edits a draft version. Details, when given, replace all
+// budget lines.
+func (s *AnggaranService) UpdateAnggaran(id uint64, req *UpdateAnggaranRequest) (*postgres.Anggaran, error) {
+	anggaran, err := s.anggaranRepo.GetAnggaranByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("anggaran not found: %v", err)
+	}
+
+	if anggaran.Status != "draft" {
+		return nil, fmt.Errorf("only draft anggaran can be changed")
+	}
+
+	var details []postgres.AnggaranDetail
+	if len(req.Details) > 0 {
+		details, err = s.buildDetails(anggaran.KoperasiID, req.Details)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	updates := map[string]interface{}{
+		"keterangan": req.Keterangan,
+	}
+	if req.KontrolAnggaran != "" {
+		updates["kontrol_anggaran"] = req.KontrolAnggaran
+	}
+
+	err = s.anggaranRepo.Transaction(func(tx *gorm.DB) error {
+		anggaranRepo := s.anggaranRepo.WithTx(tx)
+
+		if err := anggaranRepo.UpdateAnggaran(id, updates); err != nil {
+			return fmt.Errorf("failed to update anggaran: %v", err)
+		}
+
+		if details != nil {
+			if err := anggaranRepo.ReplaceDetails(id, details); err != nil {
+				return fmt.Errorf("failed to update anggaran details: %v", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.anggaranRepo.GetAnggaranByID(id)
+}
+
+// ReviseAnggaran starts a new draft version from an approved one. Without
+// details in the request the lines are copied so only the changes need to be
+// edited.
+func (s *AnggaranService) ReviseAnggaran(id uint64, req *UpdateAnggaranRequest, createdBy uint64) (*postgres.Anggaran, error) {
+	source, err := s.anggaranRepo.GetAnggaranByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("anggaran not found: %v", err)
+	}
+
+	if source.Status != "approved" {
+		return nil, fmt.Errorf("only the approved anggaran can be revised")
+	}
+
+	var details []postgres.AnggaranDetail
+	if len(req.Details) > 0 {
+		details, err = s.buildDetails(source.KoperasiID, req.Details)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		for _, detail := range source.Details {
+			details = append(details, postgres.AnggaranDetail{
+				AkunID: detail.AkunID,
+				Bulan:  detail.Bulan,
+				Jumlah: detail.Jumlah,
+			})
+		}
+	}
+
+	versi, err := s.anggaranRepo.GetMaxVersi(source.KoperasiID, source.Tahun)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get anggaran versi: %v", err)
+	}
+
+	kontrol := req.KontrolAnggaran
+	if kontrol == "" {
+		kontrol = source.KontrolAnggaran
+	}
+
+	revisi := &postgres.Anggaran{
+		TenantID:        source.TenantID,
+		KoperasiID:      source.KoperasiID,
+		Tahun:           source.Tahun,
+		Versi:           versi + 1,
+		Jenis:           "revisi",
+		RevisiDariID:    source.ID,
+		Keterangan:      req.Keterangan,
+		KontrolAnggaran: kontrol,
+		Status:          "draft",
+		CreatedBy:       createdBy,
+		Details:         details,
+	}
+
+	err = s.anggaranRepo.CreateAnggaran(revisi)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create anggaran revision: %v", err)
+	}
+
+	return revisi, nil
+}
+
+// ApproveAnggaran puts a draft version in force and supersedes the version it
+// replaces.
+func (s *AnggaranService) ApproveAnggaran(id uint64, approvedBy uint64) error {
+	anggaran, err := s.anggaranRepo.GetAnggaranByID(id)
+	if err != nil {
+		return fmt.Errorf("anggaran not found: %v", err)
+	}
+
+	if anggaran.Status != "draft" {
+		return fmt.Errorf("only draft anggaran can be approved")
+	}
+
+	return s.anggaranRepo.Transaction(func(tx *gorm.DB) error {
+		anggaranRepo := s.anggaranRepo.WithTx(tx)
+
+		if err := anggaranRepo.SupersedeApproved(anggaran.KoperasiID, anggaran.Tahun); err != nil {
+			return fmt.Errorf("failed to supersede previous anggaran: %v", err)
+		}
+
+		return anggaranRepo.UpdateAnggaran(id, map[string]interface{}{
+			"status":      "approved",
+			"approved_by": approvedBy,
+			"approved_at": time.Now(),
+		})
+	})
+}
+
+func (s *AnggaranService) GetAnggaranList(koperasiID uint64, tahun int) ([]postgres.Anggaran, error) {
+	return s.anggaranRepo.GetAnggaranByKoperasi(koperasiID, tahun)
+}
+
+func (s *AnggaranService) GetAnggaran(id uint64) (*postgres.Anggaran, error) {
+	return s.anggaranRepo.GetAnggaranByID(id)
+}
+
+// GetRealisasi compares the budget with posted actuals for January up to
+// bulanSampai. Actuals on a sub-account count towards the nearest budgeted
+// parent; pendapatan and beban that fall under no budget line are listed
+// with a zero budget. anggaranID selects a specific version, otherwise the
+// approved one is used.
+func (s *AnggaranService) GetRealisasi(koperasiID uint64, tahun, bulanSampai int, anggaranID uint64) (*LaporanRealisasiAnggaran, error) {
+	if bulanSampai < 1 || bulanSampai > 12 {
+		bulanSampai = 12
+	}
+
+	var anggaran *postgres.Anggaran
+	var err error
+	if anggaranID != 0 {
+		anggaran, err = s.anggaranRepo.GetAnggaranByID(anggaranID)
+		if err == nil && (anggaran.KoperasiID != koperasiID || anggaran.Tahun != tahun) {
+			return nil, fmt.Errorf("anggaran %d is not for koperasi %d tahun %d", anggaranID, koperasiID, tahun)
+		}
+	} else {
+		anggaran, err = s.anggaranRepo.GetAnggaranApproved(koperasiID, tahun)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("anggaran not found: %v", err)
+	}
+
+	akuns, err := s.financialRepo.GetCOAAkunAll(koperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load COA: %v", err)
+	}
+
+	realisasi, err := s.anggaranRepo.GetRealisasiBulanan(koperasiID, tahun)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get realisasi: %v", err)
+	}
+
+	akunByID := make(map[uint64]postgres.COAAkun)
+	parent := make(map[uint64]uint64)
+	for _, akun := range akuns {
+		akunByID[akun.ID] = akun
+		parent[akun.ID] = akun.ParentID
+	}
+
+	rows := make(map[uint64]*RealisasiAnggaranItem)
+	row := func(akunID uint64) *RealisasiAnggaranItem {
+		if rows[akunID] == nil {
+			akun := akunByID[akunID]
+			rows[akunID] = &RealisasiAnggaranItem{
+				AkunID:   akunID,
+				KodeAkun: akun.KodeAkun,
+				NamaAkun: akun.NamaAkun,
+				Tipe:     akun.Kategori.Tipe,
+				Bulanan:  make([]RealisasiAnggaranBulan, bulanSampai),
+			}
+			for i := range rows[akunID].Bulanan {
+				rows[akunID].Bulanan[i].Bulan = i + 1
+			}
+		}
+		return rows[akunID]
+	}
+
+	budgeted := make(map[uint64]bool)
+	for _, detail := range anggaran.Details {
+		budgeted[detail.AkunID] = true
+		if detail.Bulan > bulanSampai {
+			continue
+		}
+		item := row(detail.AkunID)
+		item.DiAnggarkan = true
+		item.Bulanan[detail.Bulan-1].Anggaran += detail.Jumlah
+	}
+
+	for _, data := range realisasi {
+		if data.Bulan < 1 || data.Bulan > bulanSampai {
+			continue
+		}
+		target := anggaranTarget(budgeted, parent, data.AkunID)
+		if target == 0 {
+			target = data.AkunID
+		}
+		item := row(target)
+		item.Bulanan[data.Bulan-1].Realisasi += saldoNormal(data.SaldoNormal, data.TotalDebit, data.TotalKredit)
+	}
+
+	laporan := &LaporanRealisasiAnggaran{
+		KoperasiID:  koperasiID,
+		Tahun:       tahun,
+		BulanSampai: bulanSampai,
+		AnggaranID:  anggaran.ID,
+		Versi:       anggaran.Versi,
+		Status:      anggaran.Status,
+	}
+
+	for _, item := range rows {
+		for i := range item.Bulanan {
+			bulan := &item.Bulanan[i]
+			item.Anggaran += bulan.Anggaran
+			item.Realisasi += bulan.Realisasi
+		}
+		item.hitungSelisih()
+
+		switch item.Tipe {
+		case "pendapatan":
+			laporan.Pendapatan = append(laporan.Pendapatan, *item)
+			laporan.TotalPendapatan.Anggaran += item.Anggaran
+			laporan.TotalPendapatan.Realisasi += item.Realisasi
+		default:
+			laporan.Beban = append(laporan.Beban, *item)
+			laporan.TotalBeban.Anggaran += item.Anggaran
+			laporan.TotalBeban.Realisasi += item.Realisasi
+		}
+	}
+
+	sort.Slice(laporan.Pendapatan, func(i, j int) bool {
+		return laporan.Pendapatan[i].KodeAkun < laporan.Pendapatan[j].KodeAkun
+	})
+	sort.Slice(laporan.Beban, func(i, j int) bool {
+		return laporan.Beban[i].KodeAkun < laporan.Beban[j].KodeAkun
+	})
+
+	laporan.TotalPendapatan.hitungSelisih()
+	laporan.TotalBeban.hitungSelisih()
+
+	return laporan, nil
+}
+
+func (s *AnggaranService) buildDetails(koperasiID uint64, reqs []AnggaranDetailRequest) ([]postgres.AnggaranDetail, error) {
+	akuns, err := s.financialRepo.GetCOAAkunByKoperasi(koperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load COA: %v", err)
+	}
+
+	akunByID := make(map[uint64]postgres.COAAkun)
+	for _, akun := range akuns {
+		akunByID[akun.ID] = akun
+	}
+
+	seen := make(map[[2]uint64]bool)
+	var details []postgres.AnggaranDetail
+	for _, req := range reqs {
+		akun, ok := akunByID[req.AkunID]
+		if !ok {
+			return nil, fmt.Errorf("akun %d not found in koperasi %d", req.AkunID, koperasiID)
+		}
+		if akun.Kategori.Tipe != "pendapatan" && akun.Kategori.Tipe != "beban" {
+			return nil, fmt.Errorf("akun %s is not a pendapatan or beban account", akun.KodeAkun)
+		}
+
+		key := [2]uint64{req.AkunID, uint64(req.Bulan)}
+		if seen[key] {
+			return nil, fmt.Errorf("akun %s bulan %d is listed twice", akun.KodeAkun, req.Bulan)
+		}
+		seen[key] = true
+
+		details = append(details, postgres.AnggaranDetail{
+			AkunID: req.AkunID,
+			Bulan:  req.Bulan,
+			Jumlah: req.Jumlah,
+		})
+	}
+
+	return details, nil
+}
+
+// anggaranTarget returns the nearest akun, the akun itself or one of its
+// parents, that has a budget line, or 0 when there is none.
+func anggaranTarget(budgeted map[uint64]bool, parent map[uint64]uint64, akunID uint64) uint64 {
+	for depth := 0; akunID != 0 && depth < 10; depth++ {
+		if budgeted[akunID] {
+			return akunID
+		}
+		akunID = parent[akunID]
+	}
+	return 0
+}
+
+type CreateAnggaranRequest struct {
+	TenantID        uint64                  `json:"tenant_id" binding:"required"`
+	KoperasiID      uint64                  `json:"koperasi_id" binding:"required"`
+	Tahun           int                     `json:"tahun" binding:"required,min=2000"`
+	Keterangan      string                  `json:"keterangan"`
+	KontrolAnggaran string                  `json:"kontrol_anggaran" binding:"omitempty,oneof=none warn block"`
+	Details         []AnggaranDetailRequest `json:"details" binding:"required,min=1,dive"`
+}
+
+type UpdateAnggaranRequest struct {
+	Keterangan      string                  `json:"keterangan"`
+	KontrolAnggaran string                  `json:"kontrol_anggaran" binding:"omitempty,oneof=none warn block"`
+	Details         []AnggaranDetailRequest `json:"details" binding:"omitempty,dive"`
+}
+
+type AnggaranDetailRequest struct {
+	AkunID uint64       `json:"akun_id" binding:"required"`
+	Bulan  int          `json:"bulan" binding:"required,min=1,max=12"`
+	Jumlah money.Amount `json:"jumlah" binding:"min=0"`
+}
+
+type LaporanRealisasiAnggaran struct {
+	KoperasiID      uint64                  `json:"koperasi_id"`
+	Tahun           int                     `json:"tahun"`
+	BulanSampai     int                     `json:"bulan_sampai"`
+	AnggaranID      uint64                  `json:"anggaran_id"`
+	Versi           int                     `json:"versi"`
+	Status          string                  `json:"status"`
+	Pendapatan      []RealisasiAnggaranItem `json:"pendapatan"`
+	Beban           []RealisasiAnggaranItem `json:"beban"`
+	TotalPendapatan RealisasiAnggaranItem   `json:"total_pendapatan"`
+	TotalBeban      RealisasiAnggaranItem   `json:"total_beban"`
+}
+
+// RealisasiAnggaranItem carries budget against actual for one akun. Selisih
+// is realisasi minus anggaran, so a positive figure is extra income on a
+// pendapatan line and overspending on a beban line.
+type RealisasiAnggaranItem struct {
+	AkunID      uint64                   `json:"akun_id,omitempty"`
+	KodeAkun    string                   `json:"kode_akun,omitempty"`
+	NamaAkun    string                   `json:"nama_akun,omitempty"`
+	Tipe        string                   `json:"tipe,omitempty"`
+	DiAnggarkan bool                     `json:"di_anggarkan"`
+	Anggaran    money.Amount             `json:"anggaran"`
+	Realisasi   money.Amount             `json:"realisasi"`
+	Selisih     money.Amount             `json:"selisih"`
+	Persen      float64                  `json:"persen"`
+	Bulanan     []RealisasiAnggaranBulan `json:"bulanan,omitempty"`
+}
+
+func (i *RealisasiAnggaranItem) hitungSelisih() {
+	i.Selisih = i.Realisasi - i.Anggaran
+	if i.Anggaran != 0 {
+		i.Persen = math.Round(i.Realisasi.Ratio(i.Anggaran)*10000) / 100
+	}
+}
+
+type RealisasiAnggaranBulan struct {
+	Bulan     int          `json:"bulan"`
+	Anggaran  money.Amount `json:"anggaran"`
+	Realisasi money.Amount `json:"realisasi"`
+}
+
+type PeringatanAnggaran struct {
+	AkunID   uint64       `json:"akun_id"`
+	KodeAkun string       `json:"kode_akun"`
+	NamaAkun string       `json:"nama_akun"`
+	Anggaran money.Amount `json:"anggaran"`
+	Terpakai money.Amount `json:"terpakai"`
+	Sisa     money.Amount `json:"sisa"`
+	Jumlah   money.Amount `json:"jumlah"`
+}
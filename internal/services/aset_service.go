@@ -0,0 +1,843 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+)
+
+// Depreciation methods. Saldo menurun uses the double declining rate, 2 /
+// umur ekonomis per month, on the book value.
+const (
+	MetodeGarisLurus   = "garis_lurus"
+	MetodeSaldoMenurun = "saldo_menurun"
+)
+
+type AsetService struct {
+	asetRepo         *postgresRepo.AsetRepository
+	financialRepo    *postgresRepo.FinancialRepository
+	produkRepo       *postgresRepo.ProdukRepository
+	financialService *FinancialService
+	sequenceService  *SequenceService
+}
+
+func NewAsetService(
+	asetRepo *postgresRepo.AsetRepository,
+	financialRepo *postgresRepo.FinancialRepository,
+	produkRepo *postgresRepo.ProdukRepository,
+	financialService *FinancialService,
+	sequenceService *SequenceService,
+) *AsetService {
+	return &AsetService{
+		asetRepo:         asetRepo,
+		financialRepo:    financialRepo,
+		produkRepo:       produkRepo,
+		financialService: financialService,
+		sequenceService:  sequenceService,
+	}
+}
+
+func (s *AsetService) CreateKategori(req *CreateKategoriAsetRequest) (*postgres.KategoriAset, error) {
+	for _, akunID := range []uint64{req.AkunAsetID, req.AkunAkumulasiID, req.AkunBebanID, req.AkunLabaRugiLepasID} {
+		if err := s.checkAkun(req.KoperasiID, akunID); err != nil {
+			return nil, err
+		}
+	}
+
+	kategori := &postgres.KategoriAset{
+		TenantID:            req.TenantID,
+		KoperasiID:          req.KoperasiID,
+		Kode:                req.Kode,
+		Nama:                req.Nama,
+		UmurEkonomis:        req.UmurEkonomis,
+		MetodePenyusutan:    req.MetodePenyusutan,
+		AkunAsetID:          req.AkunAsetID,
+		AkunAkumulasiID:     req.AkunAkumulasiID,
+		AkunBebanID:         req.AkunBebanID,
+		AkunLabaRugiLepasID: req.AkunLabaRugiLepasID,
+		IsAktif:             true,
+	}
+
+	err := s.asetRepo.CreateKategori(kategori)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kategori aset: %v", err)
+	}
+
+	return kategori, nil
+}
+
+// UpdateKategori changes the defaults used for new assets. Ledger accounts
+// are fixed once the category exists so the register keeps reconciling.
+func (s *AsetService) UpdateKategori(id uint64, req *UpdateKategoriAsetRequest) (*postgres.KategoriAset, error) {
+	_, err := s.asetRepo.GetKategoriByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("kategori aset not found: %v", err)
+	}
+
+	updates := make(map[string]interface{})
+	if req.Nama != "" {
+		updates["nama"] = req.Nama
+	}
+	if req.UmurEkonomis > 0 {
+		updates["umur_ekonomis"] = req.UmurEkonomis
+	}
+	if req.MetodePenyusutan != "" {
+		updates["metode_penyusutan"] = req.MetodePenyusutan
+	}
+	if req.IsAktif != nil {
+		updates["is_aktif"] = *req.IsAktif
+	}
+
+	if len(updates) > 0 {
+		err = s.asetRepo.UpdateKategori(id, updates)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update kategori aset: %v", err)
+		}
+	}
+
+	return s.asetRepo.GetKategoriByID(id)
+}
+
+func (s *AsetService) GetKategoriList(koperasiID uint64) ([]postgres.KategoriAset, error) {
+	return s.asetRepo.GetKategoriByKoperasi(koperasiID)
+}
+
+// CreateAset registers an acquired asset. With AkunSumberID the acquisition
+// journal (aset against kas, bank or hutang) is posted; leave it empty when
+// the cost is already in the ledger, e.g. through the purchase journal or the
+// opening balance.
+func (s *AsetService) CreateAset(req *CreateAsetRequest, createdBy uint64) (*postgres.AsetTetap, error) {
+	kategori, err := s.asetRepo.GetKategoriByID(req.KategoriAsetID)
+	if err != nil {
+		return nil, fmt.Errorf("kategori aset not found: %v", err)
+	}
+	if kategori.KoperasiID != req.KoperasiID {
+		return nil, fmt.Errorf("kategori aset belongs to another koperasi")
+	}
+	if !kategori.IsAktif {
+		return nil, fmt.Errorf("kategori aset %s is not active", kategori.Nama)
+	}
+
+	umur := req.UmurEkonomis
+	if umur == 0 {
+		umur = kategori.UmurEkonomis
+	}
+	metode := req.MetodePenyusutan
+	if metode == "" {
+		metode = kategori.MetodePenyusutan
+	}
+
+	harga := req.HargaPerolehan
+	residu := req.NilaiResidu
+	akumulasiAwal := req.AkumulasiAwal
+	if residu >= harga {
+		return nil, fmt.Errorf("nilai_residu must be less than harga_perolehan")
+	}
+	if akumulasiAwal > harga-residu {
+		return nil, fmt.Errorf("akumulasi_awal must not exceed harga_perolehan minus nilai_residu")
+	}
+
+	var penyusutanSampai *time.Time
+	if akumulasiAwal > 0 {
+		if req.PenyusutanSampai == nil {
+			return nil, fmt.Errorf("penyusutan_sampai is required with akumulasi_awal")
+		}
+		sampai := akhirBulan(*req.PenyusutanSampai)
+		penyusutanSampai = &sampai
+	}
+
+	if req.PembelianHeaderID != 0 {
+		pembelian, err := s.produkRepo.GetPembelianByID(req.PembelianHeaderID)
+		if err != nil {
+			return nil, fmt.Errorf("pembelian not found: %v", err)
+		}
+		if pembelian.KoperasiID != req.KoperasiID {
+			return nil, fmt.Errorf("pembelian belongs to another koperasi")
+		}
+	}
+
+	if req.AkunSumberID != 0 {
+		if err := s.checkAkun(req.KoperasiID, req.AkunSumberID); err != nil {
+			return nil, err
+		}
+	}
+
+	nomorAset, err := s.generateNomorAset(req.KoperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate nomor aset: %v", err)
+	}
+
+	aset := &postgres.AsetTetap{
+		TenantID:            req.TenantID,
+		KoperasiID:          req.KoperasiID,
+		KategoriAsetID:      kategori.ID,
+		NomorAset:           nomorAset,
+		Nama:                req.Nama,
+		Lokasi:              req.Lokasi,
+		TanggalPerolehan:    req.TanggalPerolehan,
+		HargaPerolehan:      harga,
+		NilaiResidu:         residu,
+		UmurEkonomis:        umur,
+		MetodePenyusutan:    metode,
+		PembelianHeaderID:   req.PembelianHeaderID,
+		AkumulasiAwal:       akumulasiAwal,
+		AkumulasiPenyusutan: akumulasiAwal,
+		NilaiBuku:           harga - akumulasiAwal,
+		PenyusutanSampai:    penyusutanSampai,
+		Status:              "aktif",
+		Keterangan:          req.Keterangan,
+		CreatedBy:           createdBy,
+	}
+
+	err = s.asetRepo.Transaction(func(tx *gorm.DB) error {
+		asetRepo := s.asetRepo.WithTx(tx)
+
+		if err := asetRepo.CreateAset(aset); err != nil {
+			return fmt.Errorf("failed to create aset: %v", err)
+		}
+
+		if req.AkunSumberID == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		jurnal := &postgres.JurnalUmum{
+			TenantID:         aset.TenantID,
+			KoperasiID:       aset.KoperasiID,
+			TanggalTransaksi: aset.TanggalPerolehan,
+			Referensi:        aset.NomorAset,
+			Keterangan:       fmt.Sprintf("Perolehan aset %s", aset.Nama),
+			Status:           "posted",
+			SumberTransaksi:  "aset_tetap",
+			SumberID:         aset.ID,
+			CreatedBy:        createdBy,
+			PostedAt:         &now,
+			PostedBy:         createdBy,
+		}
+		details := []postgres.JurnalDetail{
+			{AkunID: kategori.AkunAsetID, Debit: harga, Keterangan: aset.Nama},
+			{AkunID: req.AkunSumberID, Kredit: harga, Keterangan: aset.Nama},
+		}
+
+		if err := s.financialService.saveJurnal(tx, jurnal, details); err != nil {
+			return err
+		}
+
+		aset.JurnalPerolehanID = jurnal.ID
+		return asetRepo.UpdateAset(aset.ID, map[string]interface{}{"jurnal_perolehan_id": jurnal.ID})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return aset, nil
+}
+
+func (s *AsetService) GetAsetList(koperasiID uint64, status string) ([]postgres.AsetTetap, error) {
+	return s.asetRepo.GetAsetByKoperasi(koperasiID, status)
+}
+
+func (s *AsetService) GetAset(id uint64) (*postgres.AsetTetap, error) {
+	return s.asetRepo.GetAsetByID(id)
+}
+
+func (s *AsetService) GetRiwayatPenyusutan(asetID uint64) ([]postgres.PenyusutanAset, error) {
+	return s.asetRepo.GetPenyusutanByAset(asetID)
+}
+
+// RunPenyusutan charges depreciation for every active asset up to the end of
+// the month containing periode. Missed months are caught up one by one, each
+// with its own journal dated at the month end. Running it again for the same
+// month does nothing.
+func (s *AsetService) RunPenyusutan(koperasiID uint64, periode time.Time, createdBy uint64) ([]HasilPenyusutan, error) {
+	sampai := akhirBulan(periode)
+	if sampai.After(akhirBulan(time.Now())) {
+		return nil, fmt.Errorf("cannot depreciate a future month")
+	}
+
+	var hasil []HasilPenyusutan
+
+	err := s.asetRepo.Transaction(func(tx *gorm.DB) error {
+		asetRepo := s.asetRepo.WithTx(tx)
+
+		asets, err := asetRepo.GetAsetUntukPenyusutan(koperasiID, sampai)
+		if err != nil {
+			return fmt.Errorf("failed to load aset: %v", err)
+		}
+
+		kategoris := make(map[uint64]*postgres.KategoriAset)
+		for _, aset := range asets {
+			if _, ok := kategoris[aset.KategoriAsetID]; !ok {
+				kategori, err := asetRepo.GetKategoriByID(aset.KategoriAsetID)
+				if err != nil {
+					return fmt.Errorf("kategori aset not found: %v", err)
+				}
+				kategoris[aset.KategoriAsetID] = kategori
+			}
+		}
+
+		for {
+			bulan, ok := bulanPenyusutanBerikutnya(asets, sampai)
+			if !ok {
+				return nil
+			}
+
+			h, err := s.penyusutanBulan(tx, asets, kategoris, bulan, sampai, createdBy)
+			if err != nil {
+				return err
+			}
+			if h != nil {
+				hasil = append(hasil, *h)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hasil, nil
+}
+
+// RunDue depreciates every koperasi through the last complete month. It is
+// run daily by the scheduler and only does work once a new month has closed.
+func (s *AsetService) RunDue(now time.Time) error {
+	koperasiIDs, err := s.asetRepo.GetKoperasiAsetAktif()
+	if err != nil {
+		return fmt.Errorf("failed to load koperasi with aset: %v", err)
+	}
+
+	bulanLalu := time.Date(now.Year(), now.Month(), 0, 0, 0, 0, 0, now.Location())
+
+	var errs []error
+	for _, koperasiID := range koperasiIDs {
+		if _, err := s.RunPenyusutan(koperasiID, bulanLalu, 0); err != nil {
+			errs = append(errs, fmt.Errorf("koperasi %d: %v", koperasiID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// penyusutanBulan charges bulan for the assets whose next month it is and
+// posts one journal for them, grouped by beban and akumulasi account. Fully
+// depreciated assets are moved to sampai without a charge.
+func (s *AsetService) penyusutanBulan(tx *gorm.DB, asets []postgres.AsetTetap, kategoris map[uint64]*postgres.KategoriAset, bulan, sampai time.Time, createdBy uint64) (*HasilPenyusutan, error) {
+	asetRepo := s.asetRepo.WithTx(tx)
+
+	type charge struct {
+		index  int
+		jumlah money.Amount
+	}
+
+	var charges []charge
+	beban := make(map[uint64]money.Amount)
+	akumulasi := make(map[uint64]money.Amount)
+	var total money.Amount
+
+	for i := range asets {
+		aset := &asets[i]
+		if !akhirBulan(bulanBerikutnya(aset)).Equal(bulan) {
+			continue
+		}
+
+		jumlah := hitungPenyusutan(aset, bulan)
+		if jumlah <= 0 {
+			aset.PenyusutanSampai = &sampai
+			err := asetRepo.UpdateAset(aset.ID, map[string]interface{}{"penyusutan_sampai": sampai})
+			if err != nil {
+				return nil, fmt.Errorf("failed to update aset: %v", err)
+			}
+			continue
+		}
+
+		kategori := kategoris[aset.KategoriAsetID]
+		beban[kategori.AkunBebanID] += jumlah
+		akumulasi[kategori.AkunAkumulasiID] += jumlah
+		total += jumlah
+		charges = append(charges, charge{index: i, jumlah: jumlah})
+	}
+
+	if len(charges) == 0 {
+		return nil, nil
+	}
+
+	var details []postgres.JurnalDetail
+	for _, akunID := range sortedKeys(beban) {
+		details = append(details, postgres.JurnalDetail{AkunID: akunID, Debit: beban[akunID], Keterangan: "Beban penyusutan"})
+	}
+	for _, akunID := range sortedKeys(akumulasi) {
+		details = append(details, postgres.JurnalDetail{AkunID: akunID, Kredit: akumulasi[akunID], Keterangan: "Akumulasi penyusutan"})
+	}
+
+	first := asets[charges[0].index]
+	now := time.Now()
+	jurnal := &postgres.JurnalUmum{
+		TenantID:         first.TenantID,
+		KoperasiID:       first.KoperasiID,
+		TanggalTransaksi: bulan,
+		Referensi:        "PNY-" + bulan.Format("200601"),
+		Keterangan:       fmt.Sprintf("Penyusutan aset tetap periode %s", bulan.Format("01/2006")),
+		Status:           "posted",
+		SumberTransaksi:  "penyusutan_aset",
+		CreatedBy:        createdBy,
+		PostedAt:         &now,
+		PostedBy:         createdBy,
+	}
+
+	if err := s.financialService.saveJurnal(tx, jurnal, details); err != nil {
+		return nil, err
+	}
+
+	for _, c := range charges {
+		aset := &asets[c.index]
+		aset.AkumulasiPenyusutan += c.jumlah
+		aset.NilaiBuku = aset.HargaPerolehan - aset.AkumulasiPenyusutan
+		periode := bulan
+		aset.PenyusutanSampai = &periode
+
+		err := asetRepo.CreatePenyusutan(&postgres.PenyusutanAset{
+			AsetTetapID: aset.ID,
+			KoperasiID:  aset.KoperasiID,
+			Periode:     bulan,
+			Jumlah:      c.jumlah,
+			Akumulasi:   aset.AkumulasiPenyusutan,
+			NilaiBuku:   aset.NilaiBuku,
+			JurnalID:    jurnal.ID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to record penyusutan: %v", err)
+		}
+
+		err = asetRepo.UpdateAset(aset.ID, map[string]interface{}{
+			"akumulasi_penyusutan": aset.AkumulasiPenyusutan,
+			"nilai_buku":           aset.NilaiBuku,
+			"penyusutan_sampai":    bulan,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update aset: %v", err)
+		}
+	}
+
+	return &HasilPenyusutan{
+		Periode:     bulan,
+		JurnalID:    jurnal.ID,
+		NomorJurnal: jurnal.NomorJurnal,
+		JumlahAset:  len(charges),
+		Total:       total,
+	}, nil
+}
+
+// LepasAset disposes of an asset by sale or write-off. Depreciation must be
+// charged through the month before the disposal. The journal removes cost and
+// accumulated depreciation, books the proceeds and the gain or loss.
+func (s *AsetService) LepasAset(id uint64, req *LepasAsetRequest, createdBy uint64) (*postgres.AsetTetap, error) {
+	var result *postgres.AsetTetap
+
+	err := s.asetRepo.Transaction(func(tx *gorm.DB) error {
+		asetRepo := s.asetRepo.WithTx(tx)
+
+		aset, err := asetRepo.GetAsetForUpdate(id)
+		if err != nil {
+			return fmt.Errorf("aset not found: %v", err)
+		}
+		if aset.Status != "aktif" {
+			return fmt.Errorf("aset %s is already disposed", aset.NomorAset)
+		}
+		if req.Tanggal.Before(aset.TanggalPerolehan) {
+			return fmt.Errorf("tanggal must not be before tanggal_perolehan")
+		}
+
+		akhirBulanLalu := time.Date(req.Tanggal.Year(), req.Tanggal.Month(), 0, 0, 0, 0, 0, req.Tanggal.Location())
+		sisa := aset.HargaPerolehan - aset.NilaiResidu - aset.AkumulasiPenyusutan
+		if sisa > 0 && !akhirBulanLalu.Before(akhirBulan(aset.TanggalPerolehan)) &&
+			(aset.PenyusutanSampai == nil || aset.PenyusutanSampai.Before(akhirBulanLalu)) {
+			return fmt.Errorf("post depreciation through %s before disposing", akhirBulanLalu.Format("01/2006"))
+		}
+
+		hargaJual := req.HargaJual
+		if hargaJual > 0 && req.AkunKasID == 0 {
+			return fmt.Errorf("akun_kas_id is required when harga_jual is set")
+		}
+		if req.AkunKasID != 0 {
+			if err := s.checkAkun(aset.KoperasiID, req.AkunKasID); err != nil {
+				return err
+			}
+		}
+
+		kategori := aset.Kategori
+		details := []postgres.JurnalDetail{
+			{AkunID: kategori.AkunAsetID, Kredit: aset.HargaPerolehan, Keterangan: aset.Nama},
+		}
+		if aset.AkumulasiPenyusutan > 0 {
+			details = append(details, postgres.JurnalDetail{AkunID: kategori.AkunAkumulasiID, Debit: aset.AkumulasiPenyusutan, Keterangan: aset.Nama})
+		}
+		if hargaJual > 0 {
+			details = append(details, postgres.JurnalDetail{AkunID: req.AkunKasID, Debit: hargaJual, Keterangan: aset.Nama})
+		}
+
+		labaRugi := hargaJual - aset.NilaiBuku
+		if labaRugi > 0 {
+			details = append(details, postgres.JurnalDetail{AkunID: kategori.AkunLabaRugiLepasID, Kredit: labaRugi, Keterangan: "Laba pelepasan aset"})
+		} else if labaRugi < 0 {
+			details = append(details, postgres.JurnalDetail{AkunID: kategori.AkunLabaRugiLepasID, Debit: -labaRugi, Keterangan: "Rugi pelepasan aset"})
+		}
+
+		keterangan := req.Keterangan
+		if keterangan == "" {
+			keterangan = fmt.Sprintf("Pelepasan aset %s", aset.Nama)
+		}
+
+		now := time.Now()
+		jurnal := &postgres.JurnalUmum{
+			TenantID:         aset.TenantID,
+			KoperasiID:       aset.KoperasiID,
+			TanggalTransaksi: req.Tanggal,
+			Referensi:        aset.NomorAset,
+			Keterangan:       keterangan,
+			Status:           "posted",
+			SumberTransaksi:  "aset_tetap",
+			SumberID:         aset.ID,
+			CreatedBy:        createdBy,
+			PostedAt:         &now,
+			PostedBy:         createdBy,
+		}
+
+		if err := s.financialService.saveJurnal(tx, jurnal, details); err != nil {
+			return err
+		}
+
+		err = asetRepo.UpdateAset(aset.ID, map[string]interface{}{
+			"status":              "dilepas",
+			"tanggal_pelepasan":   req.Tanggal,
+			"harga_pelepasan":     hargaJual,
+			"jurnal_pelepasan_id": jurnal.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update aset: %v", err)
+		}
+
+		result, err = asetRepo.GetAsetByID(aset.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetRegister lists the assets held on tanggal with their book values and
+// compares the totals per account with the ledger balances.
+func (s *AsetService) GetRegister(koperasiID uint64, tanggal time.Time) (*LaporanRegisterAset, error) {
+	asets, err := s.asetRepo.GetAsetByKoperasi(koperasiID, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aset: %v", err)
+	}
+
+	penyusutan, err := s.asetRepo.GetAkumulasiSampai(koperasiID, tanggal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load penyusutan: %v", err)
+	}
+
+	laporan := &LaporanRegisterAset{
+		KoperasiID: koperasiID,
+		Tanggal:    tanggal,
+		Items:      []RegisterAsetItem{},
+	}
+
+	hargaPerAkun := make(map[uint64]money.Amount)
+	akumulasiPerAkun := make(map[uint64]money.Amount)
+	kategoriIndex := make(map[uint64]int)
+
+	for _, aset := range asets {
+		if aset.TanggalPerolehan.After(tanggal) {
+			continue
+		}
+		if aset.TanggalPelepasan != nil && !aset.TanggalPelepasan.After(tanggal) {
+			continue
+		}
+
+		akumulasi := aset.AkumulasiAwal + penyusutan[aset.ID]
+		item := RegisterAsetItem{
+			AsetID:           aset.ID,
+			NomorAset:        aset.NomorAset,
+			Nama:             aset.Nama,
+			Kategori:         aset.Kategori.Nama,
+			TanggalPerolehan: aset.TanggalPerolehan,
+			MetodePenyusutan: aset.MetodePenyusutan,
+			UmurEkonomis:     aset.UmurEkonomis,
+			HargaPerolehan:   aset.HargaPerolehan,
+			Akumulasi:        akumulasi,
+			NilaiBuku:        aset.HargaPerolehan - akumulasi,
+		}
+		laporan.Items = append(laporan.Items, item)
+
+		i, ok := kategoriIndex[aset.KategoriAsetID]
+		if !ok {
+			i = len(laporan.PerKategori)
+			kategoriIndex[aset.KategoriAsetID] = i
+			laporan.PerKategori = append(laporan.PerKategori, RegisterAsetKategori{
+				KategoriAsetID: aset.KategoriAsetID,
+				Nama:           aset.Kategori.Nama,
+			})
+		}
+		laporan.PerKategori[i].JumlahAset++
+		laporan.PerKategori[i].HargaPerolehan += item.HargaPerolehan
+		laporan.PerKategori[i].Akumulasi += item.Akumulasi
+		laporan.PerKategori[i].NilaiBuku += item.NilaiBuku
+
+		laporan.TotalHargaPerolehan += item.HargaPerolehan
+		laporan.TotalAkumulasi += item.Akumulasi
+		laporan.TotalNilaiBuku += item.NilaiBuku
+
+		hargaPerAkun[aset.Kategori.AkunAsetID] += item.HargaPerolehan
+		akumulasiPerAkun[aset.Kategori.AkunAkumulasiID] += item.Akumulasi
+	}
+
+	// Accounts of categories without assets on tanggal still take part, so a
+	// balance left on them shows up as a difference.
+	kategoris, err := s.asetRepo.GetKategoriByKoperasi(koperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kategori aset: %v", err)
+	}
+	for _, kategori := range kategoris {
+		hargaPerAkun[kategori.AkunAsetID] += 0
+		akumulasiPerAkun[kategori.AkunAkumulasiID] += 0
+	}
+
+	sebelum := time.Date(tanggal.Year(), tanggal.Month(), tanggal.Day()+1, 0, 0, 0, 0, tanggal.Location())
+	laporan.Seimbang = true
+	for _, jenis := range []struct {
+		nama  string
+		saldo map[uint64]money.Amount
+	}{{"harga_perolehan", hargaPerAkun}, {"akumulasi", akumulasiPerAkun}} {
+		for _, akunID := range sortedKeys(jenis.saldo) {
+			akun, err := s.financialRepo.GetCOAAkunByID(akunID)
+			if err != nil {
+				return nil, fmt.Errorf("akun %d not found: %v", akunID, err)
+			}
+
+			debit, kredit, err := s.financialRepo.GetTotalAkunSebelum([]uint64{akunID}, sebelum)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load saldo akun %s: %v", akun.KodeAkun, err)
+			}
+
+			bukuBesar := debit - kredit
+			if jenis.nama == "akumulasi" {
+				bukuBesar = kredit - debit
+			}
+
+			item := RekonsiliasiAsetItem{
+				AkunID:         akunID,
+				KodeAkun:       akun.KodeAkun,
+				NamaAkun:       akun.NamaAkun,
+				Jenis:          jenis.nama,
+				SaldoRegister:  jenis.saldo[akunID],
+				SaldoBukuBesar: bukuBesar,
+			}
+			item.Selisih = item.SaldoBukuBesar - item.SaldoRegister
+			if item.Selisih != 0 {
+				laporan.Seimbang = false
+			}
+			laporan.Rekonsiliasi = append(laporan.Rekonsiliasi, item)
+		}
+	}
+
+	return laporan, nil
+}
+
+func (s *AsetService) checkAkun(koperasiID, akunID uint64) error {
+	akun, err := s.financialRepo.GetCOAAkunByID(akunID)
+	if err != nil {
+		return fmt.Errorf("akun %d not found: %v", akunID, err)
+	}
+	if akun.KoperasiID != koperasiID {
+		return fmt.Errorf("akun %s belongs to another koperasi", akun.KodeAkun)
+	}
+	return nil
+}
+
+func (s *AsetService) generateNomorAset(koperasiID uint64) (string, error) {
+	number, err := s.sequenceService.GetNextNumber(1, koperasiID, "aset_tetap")
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("AST%04d%06d", koperasiID, number), nil
+}
+
+// hitungPenyusutan returns the charge of aset for the month ending bulan,
+// never taking the book value below the residual value. In the last month of
+// the useful life the remainder is charged so rounding does not linger.
+func hitungPenyusutan(aset *postgres.AsetTetap, bulan time.Time) money.Amount {
+	sisa := aset.HargaPerolehan - aset.NilaiResidu - aset.AkumulasiPenyusutan
+	if sisa <= 0 {
+		return 0
+	}
+
+	bulanKe := (bulan.Year()-aset.TanggalPerolehan.Year())*12 + int(bulan.Month()-aset.TanggalPerolehan.Month()) + 1
+	if bulanKe >= aset.UmurEkonomis {
+		return sisa
+	}
+
+	var jumlah money.Amount
+	if aset.MetodePenyusutan == MetodeSaldoMenurun {
+		jumlah = (aset.HargaPerolehan - aset.AkumulasiPenyusutan).MulInt(2).Div(int64(aset.UmurEkonomis), money.RoundSen)
+	} else {
+		jumlah = (aset.HargaPerolehan - aset.NilaiResidu).Div(int64(aset.UmurEkonomis), money.RoundSen)
+	}
+
+	if jumlah > sisa {
+		return sisa
+	}
+	return jumlah
+}
+
+// bulanBerikutnya returns a date in the first month aset still has to be
+// depreciated for.
+func bulanBerikutnya(aset *postgres.AsetTetap) time.Time {
+	if aset.PenyusutanSampai == nil {
+		return aset.TanggalPerolehan
+	}
+	return aset.PenyusutanSampai.AddDate(0, 0, 1)
+}
+
+// bulanPenyusutanBerikutnya returns the earliest month end up to sampai that
+// any of asets still has to be depreciated for.
+func bulanPenyusutanBerikutnya(asets []postgres.AsetTetap, sampai time.Time) (time.Time, bool) {
+	var earliest time.Time
+	found := false
+	for i := range asets {
+		bulan := akhirBulan(bulanBerikutnya(&asets[i]))
+		if bulan.After(sampai) {
+			continue
+		}
+		if !found || bulan.Before(earliest) {
+			earliest, found = bulan, true
+		}
+	}
+	return earliest, found
+}
+
+func akhirBulan(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location())
+}
+
+func sortedKeys(m map[uint64]money.Amount) []uint64 {
+	keys := make([]uint64, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+type CreateKategoriAsetRequest struct {
+	TenantID            uint64 `json:"tenant_id" binding:"required"`
+	KoperasiID          uint64 `json:"koperasi_id" binding:"required"`
+	Kode                string `json:"kode" binding:"required"`
+	Nama                string `json:"nama" binding:"required"`
+	UmurEkonomis        int    `json:"umur_ekonomis" binding:"required,min=1"`
+	MetodePenyusutan    string `json:"metode_penyusutan" binding:"required,oneof=garis_lurus saldo_menurun"`
+	AkunAsetID          uint64 `json:"akun_aset_id" binding:"required"`
+	AkunAkumulasiID     uint64 `json:"akun_akumulasi_id" binding:"required"`
+	AkunBebanID         uint64 `json:"akun_beban_id" binding:"required"`
+	AkunLabaRugiLepasID uint64 `json:"akun_laba_rugi_lepas_id" binding:"required"`
+}
+
+type UpdateKategoriAsetRequest struct {
+	Nama             string `json:"nama"`
+	UmurEkonomis     int    `json:"umur_ekonomis" binding:"min=0"`
+	MetodePenyusutan string `json:"metode_penyusutan" binding:"omitempty,oneof=garis_lurus saldo_menurun"`
+	IsAktif          *bool  `json:"is_aktif"`
+}
+
+type CreateAsetRequest struct {
+	TenantID          uint64       `json:"tenant_id" binding:"required"`
+	KoperasiID        uint64       `json:"koperasi_id" binding:"required"`
+	KategoriAsetID    uint64       `json:"kategori_aset_id" binding:"required"`
+	Nama              string       `json:"nama" binding:"required"`
+	Lokasi            string       `json:"lokasi"`
+	TanggalPerolehan  time.Time    `json:"tanggal_perolehan" binding:"required"`
+	HargaPerolehan    money.Amount `json:"harga_perolehan" binding:"required,gt=0"`
+	NilaiResidu       money.Amount `json:"nilai_residu" binding:"min=0"`
+	UmurEkonomis      int          `json:"umur_ekonomis" binding:"min=0"`
+	MetodePenyusutan  string       `json:"metode_penyusutan" binding:"omitempty,oneof=garis_lurus saldo_menurun"`
+	PembelianHeaderID uint64       `json:"pembelian_header_id"`
+	AkunSumberID      uint64       `json:"akun_sumber_id"`
+	AkumulasiAwal     money.Amount `json:"akumulasi_awal" binding:"min=0"`
+	PenyusutanSampai  *time.Time   `json:"penyusutan_sampai"`
+	Keterangan        string       `json:"keterangan"`
+}
+
+type LepasAsetRequest struct {
+	Tanggal    time.Time    `json:"tanggal" binding:"required"`
+	HargaJual  money.Amount `json:"harga_jual" binding:"min=0"`
+	AkunKasID  uint64       `json:"akun_kas_id"`
+	Keterangan string       `json:"keterangan"`
+}
+
+type RunPenyusutanRequest struct {
+	KoperasiID uint64    `json:"koperasi_id" binding:"required"`
+	Periode    time.Time `json:"periode" binding:"required"`
+}
+
+type HasilPenyusutan struct {
+	Periode     time.Time    `json:"periode"`
+	JurnalID    uint64       `json:"jurnal_id"`
+	NomorJurnal string       `json:"nomor_jurnal"`
+	JumlahAset  int          `json:"jumlah_aset"`
+	Total       money.Amount `json:"total"`
+}
+
+type LaporanRegisterAset struct {
+	KoperasiID          uint64                 `json:"koperasi_id"`
+	Tanggal             time.Time              `json:"tanggal"`
+	Items               []RegisterAsetItem     `json:"items"`
+	PerKategori         []RegisterAsetKategori `json:"per_kategori"`
+	TotalHargaPerolehan money.Amount           `json:"total_harga_perolehan"`
+	TotalAkumulasi      money.Amount           `json:"total_akumulasi"`
+	TotalNilaiBuku      money.Amount           `json:"total_nilai_buku"`
+	Rekonsiliasi        []RekonsiliasiAsetItem `json:"rekonsiliasi"`
+	Seimbang            bool                   `json:"seimbang"`
+}
+
+type RegisterAsetItem struct {
+	AsetID           uint64       `json:"aset_id"`
+	NomorAset        string       `json:"nomor_aset"`
+	Nama             string       `json:"nama"`
+	Kategori         string       `json:"kategori"`
+	TanggalPerolehan time.Time    `json:"tanggal_perolehan"`
+	MetodePenyusutan string       `json:"metode_penyusutan"`
+	UmurEkonomis     int          `json:"umur_ekonomis"`
+	HargaPerolehan   money.Amount `json:"harga_perolehan"`
+	Akumulasi        money.Amount `json:"akumulasi"`
+	NilaiBuku        money.Amount `json:"nilai_buku"`
+}
+
+type RegisterAsetKategori struct {
+	KategoriAsetID uint64       `json:"kategori_aset_id"`
+	Nama           string       `json:"nama"`
+	JumlahAset     int          `json:"jumlah_aset"`
+	HargaPerolehan money.Amount `json:"harga_perolehan"`
+	Akumulasi      money.Amount `json:"akumulasi"`
+	NilaiBuku      money.Amount `json:"nilai_buku"`
+}
+
+// RekonsiliasiAsetItem compares the register with the ledger for one asset
+// or accumulated depreciation account. Selisih is ledger minus register.
+type RekonsiliasiAsetItem struct {
+	AkunID         uint64       `json:"akun_id"`
+	KodeAkun       string       `json:"kode_akun"`
+	NamaAkun       string       `json:"nama_akun"`
+	Jenis          string       `json:"jenis"`
+	SaldoRegister  money.Amount `json:"saldo_register"`
+	SaldoBukuBesar money.Amount `json:"saldo_buku_besar"`
+	Selisih        money.Amount `json:"selisih"`
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+)
+
+func TestHitungPenyusutan(t *testing.T) {
+	perolehan := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	bulan := func(ke int) time.Time {
+		return akhirBulan(perolehan.AddDate(0, ke-1, -14))
+	}
+
+	tests := []struct {
+		name      string
+		metode    string
+		harga     money.Amount
+		residu    money.Amount
+		umur      int
+		akumulasi money.Amount
+		bulanKe   int
+		want      money.Amount
+	}{
+		{"garis lurus", MetodeGarisLurus, money.FromInt(12000000), 0, 12, 0, 1, money.FromInt(1000000)},
+		{"garis lurus with residu", MetodeGarisLurus, money.FromInt(12000000), money.FromInt(1200000), 12, money.FromInt(900000), 2, money.FromInt(900000)},
+		{"garis lurus rounds to sen", MetodeGarisLurus, money.FromInt(1000), 0, 3, 0, 1, money.MustParse("333.33")},
+		{"last month charges the remainder", MetodeGarisLurus, money.FromInt(1000), 0, 3, money.MustParse("666.66"), 3, money.MustParse("333.34")},
+		{"past the useful life charges the remainder", MetodeGarisLurus, money.FromInt(1000), 0, 3, money.FromInt(900), 5, money.FromInt(100)},
+		{"fully depreciated", MetodeGarisLurus, money.FromInt(1000), money.FromInt(100), 3, money.FromInt(900), 2, 0},
+		{"saldo menurun first month", MetodeSaldoMenurun, money.FromInt(12000000), 0, 12, 0, 1, money.FromInt(2000000)},
+		{"saldo menurun on book value", MetodeSaldoMenurun, money.FromInt(12000000), 0, 12, money.FromInt(2000000), 2, money.MustParse("1666666.67")},
+		{"saldo menurun stops at residu", MetodeSaldoMenurun, money.FromInt(12000000), money.FromInt(11000000), 12, 0, 1, money.FromInt(1000000)},
+		{"saldo menurun last month", MetodeSaldoMenurun, money.FromInt(12000000), money.FromInt(1000000), 12, money.FromInt(9000000), 12, money.FromInt(2000000)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aset := &postgres.AsetTetap{
+				TanggalPerolehan:    perolehan,
+				HargaPerolehan:      tt.harga,
+				NilaiResidu:         tt.residu,
+				UmurEkonomis:        tt.umur,
+				MetodePenyusutan:    tt.metode,
+				AkumulasiPenyusutan: tt.akumulasi,
+			}
+			if got := hitungPenyusutan(aset, bulan(tt.bulanKe)); got != tt.want {
+				t.Errorf("hitungPenyusutan in month %d = %s, want %s", tt.bulanKe, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBulanPenyusutanBerikutnya(t *testing.T) {
+	maret := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+	januari := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	asets := []postgres.AsetTetap{
+		{TanggalPerolehan: time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC)},
+		{TanggalPerolehan: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), PenyusutanSampai: &januari},
+		{TanggalPerolehan: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), PenyusutanSampai: &maret},
+	}
+
+	got, ok := bulanPenyusutanBerikutnya(asets, maret)
+	if want := time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC); !ok || !got.Equal(want) {
+		t.Errorf("next month = %v, %v, want %v", got, ok, want)
+	}
+
+	if _, ok := bulanPenyusutanBerikutnya(asets[2:], maret); ok {
+		t.Error("an asset depreciated through sampai has no month left")
+	}
+}
@@ -0,0 +1,633 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+	"koperasi-merah-putih/internal/utils"
+)
+
+// DefaultToleransiHari is the date window, in days either side, used when
+// auto-matching statement lines to journal lines.
+const DefaultToleransiHari = 3
+
+type BankService struct {
+	bankRepo         *postgresRepo.BankRepository
+	financialRepo    *postgresRepo.FinancialRepository
+	financialService *FinancialService
+}
+
+func NewBankService(
+	bankRepo *postgresRepo.BankRepository,
+	financialRepo *postgresRepo.FinancialRepository,
+	financialService *FinancialService,
+) *BankService {
+	return &BankService{
+		bankRepo:         bankRepo,
+		financialRepo:    financialRepo,
+		financialService: financialService,
+	}
+}
+
+func (s *BankService) CreateRekeningBank(req *CreateRekeningBankRequest) (*postgres.RekeningBank, error) {
+	akun, err := s.financialRepo.GetCOAAkunByID(req.AkunID)
+	if err != nil {
+		return nil, fmt.Errorf("akun not found: %v", err)
+	}
+	if akun.KoperasiID != req.KoperasiID {
+		return nil, fmt.Errorf("akun %s does not belong to koperasi %d", akun.KodeAkun, req.KoperasiID)
+	}
+	if !akun.IsKas {
+		return nil, fmt.Errorf("akun %s is not a kas/bank account", akun.KodeAkun)
+	}
+
+	if req.AkunBiayaID != 0 {
+		biaya, err := s.financialRepo.GetCOAAkunByID(req.AkunBiayaID)
+		if err != nil {
+			return nil, fmt.Errorf("akun biaya not found: %v", err)
+		}
+		if biaya.KoperasiID != req.KoperasiID {
+			return nil, fmt.Errorf("akun biaya %s does not belong to koperasi %d", biaya.KodeAkun, req.KoperasiID)
+		}
+	}
+
+	rekening := &postgres.RekeningBank{
+		TenantID:      req.TenantID,
+		KoperasiID:    req.KoperasiID,
+		AkunID:        req.AkunID,
+		NamaBank:      req.NamaBank,
+		NomorRekening: req.NomorRekening,
+		AtasNama:      req.AtasNama,
+		AkunBiayaID:   req.AkunBiayaID,
+		IsAktif:       true,
+	}
+
+	err = s.bankRepo.CreateRekeningBank(rekening)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rekening bank: %v", err)
+	}
+
+	return rekening, nil
+}
+
+func (s *BankService) GetRekeningBankList(koperasiID uint64) ([]postgres.RekeningBank, error) {
+	return s.bankRepo.GetRekeningBankByKoperasi(koperasiID)
+}
+
+func (s *BankService) GetRekeningKoranList(rekeningBankID uint64) ([]postgres.RekeningKoran, error) {
+	return s.bankRepo.GetRekeningKoranByRekening(rekeningBankID)
+}
+
+// ImportRekeningKoran parses a CSV or MT940 statement, stores the lines not
+// seen before and auto-matches them. saldoAwal is only needed when the file
+// carries no balances; it then defaults to the closing balance of the
+// previous import.
+func (s *BankService) ImportRekeningKoran(rekeningBankID uint64, format, namaFile string, file io.Reader, saldoAwal *money.Amount, importedBy uint64) (*ImportRekeningKoranResult, error) {
+	rekening, err := s.bankRepo.GetRekeningBankByID(rekeningBankID)
+	if err != nil {
+		return nil, fmt.Errorf("rekening bank not found: %v", err)
+	}
+
+	var statement *utils.BankStatement
+	switch format {
+	case "csv":
+		statement, err = utils.ParseBankStatementCSV(file)
+	case "mt940":
+		statement, err = utils.ParseMT940(file)
+	default:
+		return nil, fmt.Errorf("unknown format %s, use csv or mt940", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse statement: %v", err)
+	}
+
+	if len(statement.Lines) == 0 {
+		return nil, fmt.Errorf("statement has no lines")
+	}
+
+	if statement.NomorRekening != "" && !strings.Contains(digitsOnly(statement.NomorRekening), digitsOnly(rekening.NomorRekening)) {
+		return nil, fmt.Errorf("statement is for account %s, not %s", statement.NomorRekening, rekening.NomorRekening)
+	}
+
+	koran := &postgres.RekeningKoran{
+		TenantID:       rekening.TenantID,
+		KoperasiID:     rekening.KoperasiID,
+		RekeningBankID: rekening.ID,
+		Format:         format,
+		NamaFile:       namaFile,
+		TanggalAwal:    statement.Lines[0].Tanggal,
+		TanggalAkhir:   statement.Lines[0].Tanggal,
+		ImportedBy:     importedBy,
+	}
+
+	switch {
+	case statement.SaldoAwal != nil:
+		koran.SaldoAwal = *statement.SaldoAwal
+	case saldoAwal != nil:
+		koran.SaldoAwal = *saldoAwal
+	default:
+		korans, err := s.bankRepo.GetRekeningKoranByRekening(rekening.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load previous statements: %v", err)
+		}
+		if len(korans) == 0 {
+			return nil, fmt.Errorf("saldo_awal is required for the first statement without balances")
+		}
+		koran.SaldoAwal = korans[0].SaldoAkhir
+	}
+
+	result := &ImportRekeningKoranResult{}
+	var total money.Amount
+	for _, parsed := range statement.Lines {
+		if parsed.Tanggal.Before(koran.TanggalAwal) {
+			koran.TanggalAwal = parsed.Tanggal
+		}
+		if parsed.Tanggal.After(koran.TanggalAkhir) {
+			koran.TanggalAkhir = parsed.Tanggal
+		}
+		total += parsed.Jumlah
+
+		line := postgres.RekeningKoranLine{
+			RekeningBankID: rekening.ID,
+			Tanggal:        parsed.Tanggal,
+			Keterangan:     parsed.Keterangan,
+			Referensi:      utils.TruncateString(parsed.Referensi, 100),
+			Jumlah:         parsed.Jumlah,
+			Saldo:          parsed.Saldo,
+			Status:         "unmatched",
+		}
+
+		exists, err := s.bankRepo.LineExists(rekening.ID, &line)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check duplicate lines: %v", err)
+		}
+		if exists {
+			result.Duplikat++
+			continue
+		}
+
+		koran.Lines = append(koran.Lines, line)
+	}
+
+	if len(koran.Lines) == 0 {
+		return nil, fmt.Errorf("all %d lines were imported before", result.Duplikat)
+	}
+
+	koran.SaldoAkhir = koran.SaldoAwal + total
+	if statement.SaldoAkhir != nil {
+		koran.SaldoAkhir = *statement.SaldoAkhir
+	}
+	koran.JumlahBaris = len(koran.Lines)
+
+	err = s.bankRepo.CreateRekeningKoran(koran)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save statement: %v", err)
+	}
+
+	result.RekeningKoran = koran
+	result.Matched, err = s.AutoMatch(rekening.ID, DefaultToleransiHari, importedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// AutoMatch pairs unmatched statement lines with unmatched journal lines on
+// the bank akun of the same amount within toleransiHari days. A reference hit
+// (statement reference or description naming the journal number or
+// reference) decides between candidates; without one a line is only matched
+// when there is exactly one candidate. It returns the number of lines matched.
+func (s *BankService) AutoMatch(rekeningBankID uint64, toleransiHari int, matchedBy uint64) (int, error) {
+	rekening, err := s.bankRepo.GetRekeningBankByID(rekeningBankID)
+	if err != nil {
+		return 0, fmt.Errorf("rekening bank not found: %v", err)
+	}
+
+	if toleransiHari <= 0 {
+		toleransiHari = DefaultToleransiHari
+	}
+
+	lines, err := s.bankRepo.GetLines(rekening.ID, "unmatched", time.Time{}, time.Time{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to load statement lines: %v", err)
+	}
+	if len(lines) == 0 {
+		return 0, nil
+	}
+
+	window := time.Duration(toleransiHari) * 24 * time.Hour
+	dari := lines[0].Tanggal.Add(-window)
+	sampai := lines[len(lines)-1].Tanggal.Add(window)
+
+	candidates, err := s.bankRepo.GetJurnalBankLines(rekening.AkunID, dari, sampai, true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load journal lines: %v", err)
+	}
+
+	used := make(map[uint64]bool)
+	matched := 0
+	now := time.Now()
+
+	err = s.bankRepo.Transaction(func(tx *gorm.DB) error {
+		bankRepo := s.bankRepo.WithTx(tx)
+
+		for _, line := range lines {
+			var byAmount, byRef []postgresRepo.JurnalBankLine
+			for _, candidate := range candidates {
+				if used[candidate.JurnalDetailID] {
+					continue
+				}
+				if candidate.Debit-candidate.Kredit != line.Jumlah {
+					continue
+				}
+				if math.Abs(candidate.TanggalTransaksi.Sub(line.Tanggal).Hours()) > window.Hours() {
+					continue
+				}
+				byAmount = append(byAmount, candidate)
+				if referensiCocok(&line, &candidate) {
+					byRef = append(byRef, candidate)
+				}
+			}
+
+			var pick *postgresRepo.JurnalBankLine
+			switch {
+			case len(byRef) > 0:
+				pick = terdekat(byRef, line.Tanggal)
+			case len(byAmount) == 1:
+				pick = &byAmount[0]
+			default:
+				continue
+			}
+
+			err := bankRepo.UpdateLine(line.ID, map[string]interface{}{
+				"status":           "matched",
+				"jurnal_detail_id": pick.JurnalDetailID,
+				"jurnal_id":        pick.JurnalID,
+				"metode_match":     "auto",
+				"matched_by":       matchedBy,
+				"matched_at":       now,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to match line %d: %v", line.ID, err)
+			}
+
+			used[pick.JurnalDetailID] = true
+			matched++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return matched, nil
+}
+
+func (s *BankService) GetLines(rekeningBankID uint64, status string, dari, sampai time.Time) ([]postgres.RekeningKoranLine, error) {
+	return s.bankRepo.GetLines(rekeningBankID, status, dari, sampai)
+}
+
+// GetKandidatMatch lists unmatched journal lines on the bank akun with the
+// same amount as the statement line, within a week either side.
+func (s *BankService) GetKandidatMatch(lineID uint64) ([]postgresRepo.JurnalBankLine, error) {
+	line, err := s.bankRepo.GetLineByID(lineID)
+	if err != nil {
+		return nil, fmt.Errorf("statement line not found: %v", err)
+	}
+
+	rekening, err := s.bankRepo.GetRekeningBankByID(line.RekeningBankID)
+	if err != nil {
+		return nil, fmt.Errorf("rekening bank not found: %v", err)
+	}
+
+	candidates, err := s.bankRepo.GetJurnalBankLines(rekening.AkunID, line.Tanggal.AddDate(0, 0, -7), line.Tanggal.AddDate(0, 0, 7), true)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []postgresRepo.JurnalBankLine
+	for _, candidate := range candidates {
+		if candidate.Debit-candidate.Kredit == line.Jumlah {
+			result = append(result, candidate)
+		}
+	}
+
+	return result, nil
+}
+
+// MatchLine pairs a statement line with a journal line by hand. The journal
+// line must be posted, on the same bank akun, for the same amount and not
+// matched already.
+func (s *BankService) MatchLine(lineID, jurnalDetailID uint64, matchedBy uint64) error {
+	return s.bankRepo.Transaction(func(tx *gorm.DB) error {
+		bankRepo := s.bankRepo.WithTx(tx)
+
+		line, err := bankRepo.GetLineForUpdate(lineID)
+		if err != nil {
+			return fmt.Errorf("statement line not found: %v", err)
+		}
+		if line.Status != "unmatched" {
+			return fmt.Errorf("statement line is already matched")
+		}
+
+		rekening, err := bankRepo.GetRekeningBankByID(line.RekeningBankID)
+		if err != nil {
+			return fmt.Errorf("rekening bank not found: %v", err)
+		}
+
+		detail, err := bankRepo.GetJurnalBankLine(jurnalDetailID)
+		if err != nil {
+			return fmt.Errorf("jurnal line not found: %v", err)
+		}
+		if detail.AkunID != rekening.AkunID {
+			return fmt.Errorf("jurnal line is not on the bank akun of this rekening")
+		}
+		if detail.Status != "posted" && detail.Status != "reversed" {
+			return fmt.Errorf("only posted journal lines can be matched")
+		}
+		if detail.Debit-detail.Kredit != line.Jumlah {
+			return fmt.Errorf("jurnal line amount %s does not equal statement amount %s",
+				detail.Debit-detail.Kredit, line.Jumlah)
+		}
+
+		matched, err := bankRepo.IsJurnalDetailMatched(jurnalDetailID)
+		if err != nil {
+			return err
+		}
+		if matched {
+			return fmt.Errorf("jurnal line is already matched to another statement line")
+		}
+
+		return bankRepo.UpdateLine(lineID, map[string]interface{}{
+			"status":           "matched",
+			"jurnal_detail_id": detail.JurnalDetailID,
+			"jurnal_id":        detail.JurnalID,
+			"metode_match":     "manual",
+			"matched_by":       matchedBy,
+			"matched_at":       time.Now(),
+		})
+	})
+}
+
+// UnmatchLine clears a match. A journal created from the line stays posted;
+// reverse it separately if it was wrong.
+func (s *BankService) UnmatchLine(lineID uint64) error {
+	line, err := s.bankRepo.GetLineByID(lineID)
+	if err != nil {
+		return fmt.Errorf("statement line not found: %v", err)
+	}
+
+	if line.Status != "matched" {
+		return fmt.Errorf("statement line is not matched")
+	}
+
+	return s.bankRepo.UpdateLine(lineID, map[string]interface{}{
+		"status":           "unmatched",
+		"jurnal_detail_id": 0,
+		"jurnal_id":        0,
+		"metode_match":     "",
+		"matched_by":       0,
+		"matched_at":       nil,
+	})
+}
+
+// BuatJurnal posts a journal for a statement line that has no book entry,
+// such as bank charges or interest, and matches the line to it. The
+// counter account defaults to the rekening's AkunBiayaID for money out.
+func (s *BankService) BuatJurnal(lineID uint64, req *BuatJurnalBankRequest, createdBy uint64) (*postgres.JurnalUmum, error) {
+	var jurnal *postgres.JurnalUmum
+
+	err := s.bankRepo.Transaction(func(tx *gorm.DB) error {
+		bankRepo := s.bankRepo.WithTx(tx)
+
+		line, err := bankRepo.GetLineForUpdate(lineID)
+		if err != nil {
+			return fmt.Errorf("statement line not found: %v", err)
+		}
+		if line.Status != "unmatched" {
+			return fmt.Errorf("statement line is already matched")
+		}
+
+		rekening, err := bankRepo.GetRekeningBankByID(line.RekeningBankID)
+		if err != nil {
+			return fmt.Errorf("rekening bank not found: %v", err)
+		}
+
+		akunLawanID := req.AkunLawanID
+		if akunLawanID == 0 && line.Jumlah < 0 {
+			akunLawanID = rekening.AkunBiayaID
+		}
+		if akunLawanID == 0 {
+			return fmt.Errorf("akun_lawan_id is required")
+		}
+
+		akunLawan, err := s.financialRepo.GetCOAAkunByID(akunLawanID)
+		if err != nil {
+			return fmt.Errorf("akun lawan not found: %v", err)
+		}
+		if akunLawan.KoperasiID != rekening.KoperasiID {
+			return fmt.Errorf("akun %s does not belong to koperasi %d", akunLawan.KodeAkun, rekening.KoperasiID)
+		}
+
+		keterangan := req.Keterangan
+		if keterangan == "" {
+			keterangan = line.Keterangan
+		}
+
+		jumlah := line.Jumlah.Abs()
+		bank := postgres.JurnalDetail{AkunID: rekening.AkunID, Keterangan: utils.TruncateString(keterangan, 255)}
+		lawan := postgres.JurnalDetail{AkunID: akunLawanID, Keterangan: utils.TruncateString(keterangan, 255)}
+		if line.Jumlah > 0 {
+			bank.Debit, lawan.Kredit = jumlah, jumlah
+		} else {
+			lawan.Debit, bank.Kredit = jumlah, jumlah
+		}
+		details := []postgres.JurnalDetail{bank, lawan}
+
+		now := time.Now()
+		jurnal = &postgres.JurnalUmum{
+			TenantID:         rekening.TenantID,
+			KoperasiID:       rekening.KoperasiID,
+			TanggalTransaksi: line.Tanggal,
+			Referensi:        line.Referensi,
+			Keterangan:       keterangan,
+			Status:           "posted",
+			SumberTransaksi:  "rekening_koran",
+			SumberID:         line.ID,
+			CreatedBy:        createdBy,
+			PostedAt:         &now,
+			PostedBy:         createdBy,
+		}
+
+		err = s.financialService.saveJurnal(tx, jurnal, details)
+		if err != nil {
+			return err
+		}
+
+		return bankRepo.UpdateLine(line.ID, map[string]interface{}{
+			"status":           "matched",
+			"jurnal_detail_id": details[0].ID,
+			"jurnal_id":        jurnal.ID,
+			"metode_match":     "jurnal",
+			"matched_by":       createdBy,
+			"matched_at":       now,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return jurnal, nil
+}
+
+// GetRekonsiliasi reconciles the bank balance with the book balance of the
+// bank akun at tanggal. The bank balance runs from the opening balance of the
+// earliest imported statement; book lines dated before that statement are
+// taken as already reflected in it.
+func (s *BankService) GetRekonsiliasi(rekeningBankID uint64, tanggal time.Time) (*LaporanRekonsiliasiBank, error) {
+	rekening, err := s.bankRepo.GetRekeningBankByID(rekeningBankID)
+	if err != nil {
+		return nil, fmt.Errorf("rekening bank not found: %v", err)
+	}
+
+	awal, err := s.bankRepo.GetRekeningKoranAwal(rekening.ID)
+	if err != nil {
+		return nil, fmt.Errorf("no bank statement imported for this rekening")
+	}
+
+	totalBank, err := s.bankRepo.GetTotalLines(rekening.ID, tanggal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bank balance: %v", err)
+	}
+
+	debit, kredit, err := s.financialRepo.GetTotalAkunSebelum([]uint64{rekening.AkunID}, tanggal.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book balance: %v", err)
+	}
+
+	bukuBelum, err := s.bankRepo.GetJurnalBankLines(rekening.AkunID, awal.TanggalAwal, tanggal, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outstanding book items: %v", err)
+	}
+
+	bankBelum, err := s.bankRepo.GetLines(rekening.ID, "unmatched", time.Time{}, tanggal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outstanding bank items: %v", err)
+	}
+
+	laporan := &LaporanRekonsiliasiBank{
+		RekeningBankID: rekening.ID,
+		NamaBank:       rekening.NamaBank,
+		NomorRekening:  rekening.NomorRekening,
+		AkunID:         rekening.AkunID,
+		Tanggal:        tanggal,
+		SaldoBank:      awal.SaldoAwal + totalBank,
+		SaldoBuku:      debit - kredit,
+	}
+
+	laporan.SaldoBankDisesuaikan = laporan.SaldoBank
+	for _, item := range bukuBelum {
+		if item.Debit > item.Kredit {
+			laporan.SetoranDalamPerjalanan = append(laporan.SetoranDalamPerjalanan, item)
+		} else {
+			laporan.PembayaranBelumDikliring = append(laporan.PembayaranBelumDikliring, item)
+		}
+		laporan.SaldoBankDisesuaikan += item.Debit - item.Kredit
+	}
+
+	laporan.SaldoBukuDisesuaikan = laporan.SaldoBuku
+	for _, item := range bankBelum {
+		if item.Jumlah > 0 {
+			laporan.PenerimaanBelumDicatat = append(laporan.PenerimaanBelumDicatat, item)
+		} else {
+			laporan.PengeluaranBelumDicatat = append(laporan.PengeluaranBelumDicatat, item)
+		}
+		laporan.SaldoBukuDisesuaikan += item.Jumlah
+	}
+
+	laporan.Selisih = laporan.SaldoBankDisesuaikan - laporan.SaldoBukuDisesuaikan
+	laporan.IsBalanced = laporan.Selisih == 0
+
+	return laporan, nil
+}
+
+// referensiCocok reports whether a statement line names the journal, by its
+// reference or number, in its reference or description.
+func referensiCocok(line *postgres.RekeningKoranLine, candidate *postgresRepo.JurnalBankLine) bool {
+	teks := strings.ToLower(line.Referensi + " " + line.Keterangan)
+	for _, ref := range []string{candidate.Referensi, candidate.NomorJurnal} {
+		ref = strings.ToLower(strings.TrimSpace(ref))
+		if len(ref) >= 4 && strings.Contains(teks, ref) {
+			return true
+		}
+	}
+	return false
+}
+
+func terdekat(candidates []postgresRepo.JurnalBankLine, tanggal time.Time) *postgresRepo.JurnalBankLine {
+	best := &candidates[0]
+	for i := range candidates {
+		if math.Abs(candidates[i].TanggalTransaksi.Sub(tanggal).Hours()) < math.Abs(best.TanggalTransaksi.Sub(tanggal).Hours()) {
+			best = &candidates[i]
+		}
+	}
+	return best
+}
+
+func digitsOnly(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+type CreateRekeningBankRequest struct {
+	TenantID      uint64 `json:"tenant_id" binding:"required"`
+	KoperasiID    uint64 `json:"koperasi_id" binding:"required"`
+	AkunID        uint64 `json:"akun_id" binding:"required"`
+	NamaBank      string `json:"nama_bank" binding:"required"`
+	NomorRekening string `json:"nomor_rekening" binding:"required"`
+	AtasNama      string `json:"atas_nama"`
+	AkunBiayaID   uint64 `json:"akun_biaya_id"`
+}
+
+type BuatJurnalBankRequest struct {
+	AkunLawanID uint64 `json:"akun_lawan_id"`
+	Keterangan  string `json:"keterangan"`
+}
+
+type ImportRekeningKoranResult struct {
+	RekeningKoran *postgres.RekeningKoran `json:"rekening_koran"`
+	Duplikat      int                     `json:"duplikat"`
+	Matched       int                     `json:"matched"`
+}
+
+type LaporanRekonsiliasiBank struct {
+	RekeningBankID           uint64                        `json:"rekening_bank_id"`
+	NamaBank                 string                        `json:"nama_bank"`
+	NomorRekening            string                        `json:"nomor_rekening"`
+	AkunID                   uint64                        `json:"akun_id"`
+	Tanggal                  time.Time                     `json:"tanggal"`
+	SaldoBank                money.Amount                  `json:"saldo_bank"`
+	SetoranDalamPerjalanan   []postgresRepo.JurnalBankLine `json:"setoran_dalam_perjalanan"`
+	PembayaranBelumDikliring []postgresRepo.JurnalBankLine `json:"pembayaran_belum_dikliring"`
+	SaldoBankDisesuaikan     money.Amount                  `json:"saldo_bank_disesuaikan"`
+	SaldoBuku                money.Amount                  `json:"saldo_buku"`
+	PenerimaanBelumDicatat   []postgres.RekeningKoranLine  `json:"penerimaan_belum_dicatat"`
+	PengeluaranBelumDicatat  []postgres.RekeningKoranLine  `json:"pengeluaran_belum_dicatat"`
+	SaldoBukuDisesuaikan     money.Amount                  `json:"saldo_buku_disesuaikan"`
+	Selisih                  money.Amount                  `json:"selisih"`
+	IsBalanced               bool                          `json:"is_balanced"`
+}
@@ -0,0 +1,605 @@
+package services
+
+import (
+	"bytes"
+	"embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"koperasi-merah-putih/internal/models/postgres"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+	"koperasi-merah-putih/internal/utils"
+)
+
+//go:embed coa_templates/*.csv
+var coaTemplateFS embed.FS
+
+// COA templates per jenis koperasi, applied on creation or on demand.
+const (
+	TemplateCOAKSP        = "ksp"
+	TemplateCOAKonsumen   = "konsumen"
+	TemplateCOAProdusen   = "produsen"
+	TemplateCOASerbaUsaha = "serba_usaha"
+)
+
+var coaTemplateNama = map[string]string{
+	TemplateCOAKSP:        "Koperasi Simpan Pinjam",
+	TemplateCOAKonsumen:   "Koperasi Konsumen",
+	TemplateCOAProdusen:   "Koperasi Produsen",
+	TemplateCOASerbaUsaha: "Koperasi Serba Usaha",
+}
+
+// coaKolom is the column layout of COA files, both for export and for the
+// templates. Import matches columns by header name, so order is free there.
+var coaKolom = []string{"kode_akun", "nama_akun", "kategori", "parent_kode", "level", "saldo_normal", "is_kas", "is_aktif"}
+
+// maxCOAErrors caps how many row errors an import reports back.
+const maxCOAErrors = 20
+
+type COAService struct {
+	financialRepo *postgresRepo.FinancialRepository
+	koperasiRepo  *postgresRepo.KoperasiRepository
+}
+
+func NewCOAService(financialRepo *postgresRepo.FinancialRepository, koperasiRepo *postgresRepo.KoperasiRepository) *COAService {
+	return &COAService{
+		financialRepo: financialRepo,
+		koperasiRepo:  koperasiRepo,
+	}
+}
+
+// GetTemplateList lists the built-in templates with their akun count.
+func (s *COAService) GetTemplateList() ([]COATemplate, error) {
+	kodes := make([]string, 0, len(coaTemplateNama))
+	for kode := range coaTemplateNama {
+		kodes = append(kodes, kode)
+	}
+	sort.Strings(kodes)
+
+	var templates []COATemplate
+	for _, kode := range kodes {
+		rows, err := loadCOATemplate(kode)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, COATemplate{Kode: kode, Nama: coaTemplateNama[kode], JumlahAkun: len(rows)})
+	}
+	return templates, nil
+}
+
+// ExportCOA returns the koperasi's whole account tree, inactive akun
+// included, in file row form.
+func (s *COAService) ExportCOA(koperasiID uint64) ([]COABaris, error) {
+	akuns, err := s.financialRepo.GetCOAAkunAll(koperasiID)
+	if err != nil {
+		return nil, err
+	}
+
+	kodeByID := make(map[uint64]string, len(akuns))
+	for _, akun := range akuns {
+		kodeByID[akun.ID] = akun.KodeAkun
+	}
+
+	rows := make([]COABaris, 0, len(akuns))
+	for _, akun := range akuns {
+		rows = append(rows, COABaris{
+			KodeAkun:    akun.KodeAkun,
+			NamaAkun:    akun.NamaAkun,
+			Kategori:    akun.Kategori.Nama,
+			ParentKode:  kodeByID[akun.ParentID],
+			Level:       akun.LevelAkun,
+			SaldoNormal: akun.SaldoNormal,
+			IsKas:       akun.IsKas,
+			IsAktif:     akun.IsAktif,
+		})
+	}
+	return rows, nil
+}
+
+func (s *COAService) WriteCOACSV(w io.Writer, rows []COABaris) error {
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(coaRecords(rows)); err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (s *COAService) WriteCOAXLSX(w io.Writer, rows []COABaris) error {
+	return utils.WriteXLSX(w, utils.XLSXSheet{Name: "COA", Rows: coaRecords(rows)})
+}
+
+func coaRecords(rows []COABaris) [][]string {
+	records := [][]string{coaKolom}
+	for _, row := range rows {
+		records = append(records, []string{
+			row.KodeAkun,
+			row.NamaAkun,
+			row.Kategori,
+			row.ParentKode,
+			strconv.Itoa(row.Level),
+			row.SaldoNormal,
+			strconv.FormatBool(row.IsKas),
+			strconv.FormatBool(row.IsAktif),
+		})
+	}
+	return records
+}
+
+// ImportCOA reads a CSV or XLSX file and merges it into the koperasi's chart
+// of accounts by kode akun: new codes are created, existing ones updated and
+// akun missing from the file are left alone. The file is validated as a
+// whole first, so either every row is applied or none.
+func (s *COAService) ImportCOA(koperasiID uint64, format string, r io.Reader) (*ImportCOAResult, error) {
+	records, err := readImportRecords(format, r)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := parseCOARecords(records)
+	if err != nil {
+		return nil, err
+	}
+
+	koperasi, err := s.koperasiRepo.GetByID(koperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("koperasi not found: %v", err)
+	}
+
+	var result *ImportCOAResult
+	err = s.financialRepo.Transaction(func(tx *gorm.DB) error {
+		result, err = s.simpanCOA(tx, koperasi, rows)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ApplyTemplate merges a built-in template into the koperasi's chart of
+// accounts the same way ImportCOA merges a file.
+func (s *COAService) ApplyTemplate(koperasiID uint64, template string) (*ImportCOAResult, error) {
+	koperasi, err := s.koperasiRepo.GetByID(koperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("koperasi not found: %v", err)
+	}
+
+	var result *ImportCOAResult
+	err = s.financialRepo.Transaction(func(tx *gorm.DB) error {
+		result, err = s.applyTemplate(tx, koperasi, template)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// applyTemplate runs inside the caller's transaction so a new koperasi and
+// its chart of accounts are created together.
+func (s *COAService) applyTemplate(tx *gorm.DB, koperasi *postgres.Koperasi, template string) (*ImportCOAResult, error) {
+	rows, err := loadCOATemplate(template)
+	if err != nil {
+		return nil, err
+	}
+	return s.simpanCOA(tx, koperasi, rows)
+}
+
+func loadCOATemplate(template string) ([]COABaris, error) {
+	if _, ok := coaTemplateNama[template]; !ok {
+		return nil, fmt.Errorf("unknown COA template %s", template)
+	}
+
+	data, err := coaTemplateFS.ReadFile("coa_templates/" + template + ".csv")
+	if err != nil {
+		return nil, err
+	}
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("template %s: %v", template, err)
+	}
+	return parseCOARecords(records)
+}
+
+// readImportRecords reads an uploaded CSV or XLSX file into records, header
+// first. XLSX files are read from their first sheet.
+func readImportRecords(format string, r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+
+	switch format {
+	case "csv":
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv file: %v", err)
+		}
+		return records, nil
+	case "xlsx":
+		return utils.ReadXLSX(bytes.NewReader(data), int64(len(data)))
+	}
+	return nil, fmt.Errorf("unsupported format %s", format)
+}
+
+// importKolom maps lower-cased header names to their column index.
+func importKolom(header []string) map[string]int {
+	kolom := make(map[string]int)
+	for i, name := range header {
+		kolom[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	return kolom
+}
+
+// importField returns the trimmed value of a named column, or "" when the
+// column is absent or the record is short.
+func importField(kolom map[string]int, record []string, name string) string {
+	i, ok := kolom[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// parseCOARecords turns file records into rows. The first record is the
+// header; blank rows are skipped.
+func parseCOARecords(records [][]string) ([]COABaris, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("file is empty")
+	}
+
+	kolom := importKolom(records[0])
+	for _, wajib := range []string{"kode_akun", "nama_akun", "kategori", "saldo_normal"} {
+		if _, ok := kolom[wajib]; !ok {
+			return nil, fmt.Errorf("column %s is missing", wajib)
+		}
+	}
+
+	var rows []COABaris
+	var errs []string
+	for n, record := range records[1:] {
+		baris := n + 2
+		field := func(name string) string {
+			return importField(kolom, record, name)
+		}
+
+		if strings.Join(record, "") == "" {
+			continue
+		}
+
+		row := COABaris{
+			Baris:       baris,
+			KodeAkun:    field("kode_akun"),
+			NamaAkun:    field("nama_akun"),
+			Kategori:    field("kategori"),
+			ParentKode:  field("parent_kode"),
+			SaldoNormal: strings.ToLower(field("saldo_normal")),
+			IsAktif:     true,
+		}
+
+		var err error
+		if v := field("level"); v != "" {
+			if row.Level, err = strconv.Atoi(v); err != nil || row.Level < 1 {
+				errs = append(errs, fmt.Sprintf("row %d: invalid level %q", baris, v))
+			}
+		}
+		if v := field("is_kas"); v != "" {
+			if row.IsKas, err = strconv.ParseBool(v); err != nil {
+				errs = append(errs, fmt.Sprintf("row %d: invalid is_kas %q", baris, v))
+			}
+		}
+		if v := field("is_aktif"); v != "" {
+			if row.IsAktif, err = strconv.ParseBool(v); err != nil {
+				errs = append(errs, fmt.Sprintf("row %d: invalid is_aktif %q", baris, v))
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	if len(errs) > 0 {
+		return nil, coaErrors(errs)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("file has no akun")
+	}
+	return rows, nil
+}
+
+// simpanCOA validates rows against the koperasi's current accounts and writes
+// them, parents before children.
+func (s *COAService) simpanCOA(tx *gorm.DB, koperasi *postgres.Koperasi, rows []COABaris) (*ImportCOAResult, error) {
+	financialRepo := s.financialRepo.WithTx(tx)
+
+	kategoris, err := financialRepo.GetCOAKategoriList()
+	if err != nil {
+		return nil, err
+	}
+	existing, err := financialRepo.GetCOAAkunAll(koperasi.ID)
+	if err != nil {
+		return nil, err
+	}
+	dipakai, err := financialRepo.GetAkunIDsDipakai(koperasi.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := validateCOA(rows, kategoris, existing, dipakai)
+	if err != nil {
+		return nil, err
+	}
+
+	akunByKode := make(map[string]*postgres.COAAkun, len(existing))
+	for i := range existing {
+		akunByKode[existing[i].KodeAkun] = &existing[i]
+	}
+
+	result := &ImportCOAResult{}
+	for _, item := range plan {
+		var parentID uint64
+		if item.ParentKode != "" {
+			parentID = akunByKode[item.ParentKode].ID
+		}
+
+		akun, ada := akunByKode[item.KodeAkun]
+		if !ada {
+			akun = &postgres.COAAkun{
+				TenantID:   koperasi.TenantID,
+				KoperasiID: koperasi.ID,
+				KodeAkun:   item.KodeAkun,
+			}
+		}
+		akun.NamaAkun = item.NamaAkun
+		akun.KategoriID = item.kategoriID
+		akun.ParentID = parentID
+		akun.LevelAkun = item.Level
+		akun.SaldoNormal = item.SaldoNormal
+		akun.IsKas = item.IsKas
+		akun.IsAktif = item.IsAktif
+
+		if ada {
+			// Save would also write the preloaded Kategori association.
+			akun.Kategori = postgres.COAKategori{}
+			if err := financialRepo.UpdateCOAAkun(akun); err != nil {
+				return nil, fmt.Errorf("failed to update akun %s: %v", item.KodeAkun, err)
+			}
+			result.Diperbarui++
+		} else {
+			if err := financialRepo.CreateCOAAkun(akun); err != nil {
+				return nil, fmt.Errorf("failed to create akun %s: %v", item.KodeAkun, err)
+			}
+			akunByKode[akun.KodeAkun] = akun
+			result.Dibuat++
+		}
+	}
+	result.JumlahAkun = len(akunByKode)
+
+	return result, nil
+}
+
+// coaRencana is a validated row with its kategori resolved.
+type coaRencana struct {
+	COABaris
+	kategoriID uint64
+}
+
+// validateCOA checks rows against each other and against the accounts the
+// koperasi already has, and returns them ordered so every parent comes
+// before its children. Levels left blank are derived from the parent.
+func validateCOA(rows []COABaris, kategoris []postgres.COAKategori, existing []postgres.COAAkun, dipakai []uint64) ([]coaRencana, error) {
+	kategoriByKey := make(map[string]postgres.COAKategori)
+	kategoriByID := make(map[uint64]postgres.COAKategori)
+	for _, kategori := range kategoris {
+		kategoriByKey[strings.ToLower(kategori.Kode)] = kategori
+		kategoriByKey[strings.ToLower(kategori.Nama)] = kategori
+		kategoriByID[kategori.ID] = kategori
+	}
+
+	existingByKode := make(map[string]postgres.COAAkun, len(existing))
+	kodeByID := make(map[uint64]string, len(existing))
+	for _, akun := range existing {
+		existingByKode[akun.KodeAkun] = akun
+		kodeByID[akun.ID] = akun.KodeAkun
+	}
+	sudahDipakai := make(map[uint64]bool, len(dipakai))
+	for _, id := range dipakai {
+		sudahDipakai[id] = true
+	}
+
+	var errs []string
+	fail := func(row COABaris, format string, args ...interface{}) {
+		errs = append(errs, fmt.Sprintf("row %d (%s): ", row.Baris, row.KodeAkun)+fmt.Sprintf(format, args...))
+	}
+
+	plan := make(map[string]*coaRencana, len(rows))
+	var urutan []string
+	for _, row := range rows {
+		if row.KodeAkun == "" {
+			fail(row, "kode akun is required")
+			continue
+		}
+		if len(row.KodeAkun) > 20 {
+			fail(row, "kode akun is longer than 20 characters")
+			continue
+		}
+		if _, dup := plan[row.KodeAkun]; dup {
+			fail(row, "kode akun appears more than once")
+			continue
+		}
+		if row.NamaAkun == "" {
+			fail(row, "nama akun is required")
+		}
+		if row.SaldoNormal != "debit" && row.SaldoNormal != "kredit" {
+			fail(row, "saldo normal must be debit or kredit, got %q", row.SaldoNormal)
+		}
+		kategori, ok := kategoriByKey[strings.ToLower(row.Kategori)]
+		if !ok {
+			fail(row, "unknown kategori %q", row.Kategori)
+		}
+		if row.ParentKode == row.KodeAkun {
+			fail(row, "akun cannot be its own parent")
+		}
+
+		if akun, ada := existingByKode[row.KodeAkun]; ada && ok && sudahDipakai[akun.ID] {
+			if akun.KategoriID != kategori.ID || akun.SaldoNormal != row.SaldoNormal {
+				fail(row, "akun already has journal entries, kategori and saldo normal cannot change")
+			}
+		}
+
+		plan[row.KodeAkun] = &coaRencana{COABaris: row, kategoriID: kategori.ID}
+		urutan = append(urutan, row.KodeAkun)
+	}
+	if len(errs) > 0 {
+		return nil, coaErrors(errs)
+	}
+
+	// Akun outside the file keep their current place in the tree, so a
+	// partial file can hang new akun under existing parents.
+	type simpul struct {
+		parent     string
+		kategoriID uint64
+		level      int
+	}
+	pohon := make(map[string]simpul, len(existing)+len(plan))
+	for _, akun := range existing {
+		pohon[akun.KodeAkun] = simpul{parent: kodeByID[akun.ParentID], kategoriID: akun.KategoriID, level: akun.LevelAkun}
+	}
+	for kode, item := range plan {
+		pohon[kode] = simpul{parent: item.ParentKode, kategoriID: item.kategoriID}
+	}
+
+	// Levels follow from the root, which also catches parent cycles.
+	level := make(map[string]int, len(pohon))
+	var hitungLevel func(kode string, jalur map[string]bool) (int, error)
+	hitungLevel = func(kode string, jalur map[string]bool) (int, error) {
+		if lv, ok := level[kode]; ok {
+			return lv, nil
+		}
+		node, ok := pohon[kode]
+		if !ok {
+			return 0, fmt.Errorf("parent %s not found", kode)
+		}
+		if node.parent == "" {
+			level[kode] = 1
+			return 1, nil
+		}
+		if jalur[kode] {
+			return 0, fmt.Errorf("parent links form a cycle through %s", kode)
+		}
+		jalur[kode] = true
+		lv, err := hitungLevel(node.parent, jalur)
+		if err != nil {
+			return 0, err
+		}
+		level[kode] = lv + 1
+		return lv + 1, nil
+	}
+
+	for _, kode := range urutan {
+		item := plan[kode]
+		lv, err := hitungLevel(kode, map[string]bool{})
+		if err != nil {
+			fail(item.COABaris, "%v", err)
+			continue
+		}
+		if item.Level != 0 && item.Level != lv {
+			fail(item.COABaris, "level %d does not match its parent, expected %d", item.Level, lv)
+			continue
+		}
+		item.Level = lv
+
+		if item.ParentKode == "" {
+			// Root akun carry the kategori's normal balance; contra akun
+			// such as accumulated depreciation sit below them.
+			normal := saldoNormalKategori(kategoriByID[item.kategoriID].Tipe)
+			if normal != "" && item.SaldoNormal != normal {
+				fail(item.COABaris, "root akun of kategori %s must have saldo normal %s", kategoriByID[item.kategoriID].Nama, normal)
+			}
+		} else if parent := pohon[item.ParentKode]; parent.kategoriID != item.kategoriID {
+			fail(item.COABaris, "kategori differs from parent %s", item.ParentKode)
+		}
+	}
+
+	// An akun left out of the file keeps its stored level, so moving its
+	// ancestor to another depth has to bring it along in the same file.
+	for kode, akun := range existingByKode {
+		if _, inFile := plan[kode]; inFile {
+			continue
+		}
+		for parent := pohon[kode].parent; parent != ""; parent = pohon[parent].parent {
+			if _, moved := plan[parent]; !moved {
+				continue
+			}
+			if lv, err := hitungLevel(kode, map[string]bool{}); err == nil && lv != akun.LevelAkun {
+				errs = append(errs, fmt.Sprintf("akun %s is not in the file but its level would change from %d to %d", kode, akun.LevelAkun, lv))
+			}
+			break
+		}
+	}
+	if len(errs) > 0 {
+		return nil, coaErrors(errs)
+	}
+
+	result := make([]coaRencana, 0, len(urutan))
+	for _, kode := range urutan {
+		result = append(result, *plan[kode])
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Level < result[j].Level
+	})
+	return result, nil
+}
+
+func saldoNormalKategori(tipe string) string {
+	switch strings.ToLower(tipe) {
+	case "aset", "beban":
+		return "debit"
+	case "kewajiban", "ekuitas", "pendapatan":
+		return "kredit"
+	}
+	return ""
+}
+
+func coaErrors(errs []string) error {
+	if len(errs) > maxCOAErrors {
+		errs = append(errs[:maxCOAErrors], fmt.Sprintf("and %d more", len(errs)-maxCOAErrors))
+	}
+	return fmt.Errorf("invalid chart of accounts: %s", strings.Join(errs, "; "))
+}
+
+// Request/Response structs
+type COABaris struct {
+	Baris       int    `json:"baris,omitempty"`
+	KodeAkun    string `json:"kode_akun"`
+	NamaAkun    string `json:"nama_akun"`
+	Kategori    string `json:"kategori"`
+	ParentKode  string `json:"parent_kode"`
+	Level       int    `json:"level"`
+	SaldoNormal string `json:"saldo_normal"`
+	IsKas       bool   `json:"is_kas"`
+	IsAktif     bool   `json:"is_aktif"`
+}
+
+type COATemplate struct {
+	Kode       string `json:"kode"`
+	Nama       string `json:"nama"`
+	JumlahAkun int    `json:"jumlah_akun"`
+}
+
+type ApplyCOATemplateRequest struct {
+	Template string `json:"template" binding:"required,oneof=ksp konsumen produsen serba_usaha"`
+}
+
+type ImportCOAResult struct {
+	Dibuat     int `json:"dibuat"`
+	Diperbarui int `json:"diperbarui"`
+	JumlahAkun int `json:"jumlah_akun"`
+}
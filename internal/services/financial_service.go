@@ -1,24 +1,41 @@
 package services
 
 import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"sort"
+	"strconv"
 	"time"
 
+	"gorm.io/gorm"
 	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
 	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
 )
 
 type FinancialService struct {
 	financialRepo   *postgresRepo.FinancialRepository
+	periodeRepo     *postgresRepo.PeriodeRepository
+	anggaranRepo    *postgresRepo.AnggaranRepository
+	lampiranRepo    *postgresRepo.LampiranRepository
 	sequenceService *SequenceService
 }
 
 func NewFinancialService(
 	financialRepo *postgresRepo.FinancialRepository,
+	periodeRepo *postgresRepo.PeriodeRepository,
+	anggaranRepo *postgresRepo.AnggaranRepository,
+	lampiranRepo *postgresRepo.LampiranRepository,
 	sequenceService *SequenceService,
 ) *FinancialService {
 	return &FinancialService{
 		financialRepo:   financialRepo,
+		periodeRepo:     periodeRepo,
+		anggaranRepo:    anggaranRepo,
+		lampiranRepo:    lampiranRepo,
 		sequenceService: sequenceService,
 	}
 }
@@ -63,43 +80,22 @@ func (s *FinancialService) CreateJurnalUmum(req *CreateJurnalRequest) (*postgres
 		return nil, fmt.Errorf("journal details cannot be empty")
 	}
 
-	var totalDebit, totalKredit float64
-	for _, detail := range req.Details {
-		totalDebit += detail.Debit
-		totalKredit += detail.Kredit
-	}
-
-	if totalDebit != totalKredit {
-		return nil, fmt.Errorf("total debit (%.2f) must equal total kredit (%.2f)", totalDebit, totalKredit)
-	}
-
-	nomorJurnal, err := s.generateNomorJurnal(req.TenantID, req.KoperasiID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate nomor jurnal: %v", err)
-	}
-
 	jurnal := &postgres.JurnalUmum{
 		TenantID:         req.TenantID,
 		KoperasiID:       req.KoperasiID,
-		NomorJurnal:      nomorJurnal,
 		TanggalTransaksi: req.TanggalTransaksi,
 		Referensi:        req.Referensi,
 		Keterangan:       req.Keterangan,
-		TotalDebit:       totalDebit,
-		TotalKredit:      totalKredit,
 		Status:           "draft",
+		SumberTransaksi:  "manual",
+		AnggotaID:        req.AnggotaID,
+		WajibLampiran:    req.WajibLampiran,
 		CreatedBy:        req.CreatedBy,
 	}
 
-	err = s.financialRepo.CreateJurnalUmum(jurnal)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create jurnal umum: %v", err)
-	}
-
 	var jurnalDetails []postgres.JurnalDetail
 	for _, detail := range req.Details {
 		jurnalDetail := postgres.JurnalDetail{
-			JurnalID:   jurnal.ID,
 			AkunID:     detail.AkunID,
 			Keterangan: detail.Keterangan,
 			Debit:      detail.Debit,
@@ -108,14 +104,83 @@ func (s *FinancialService) CreateJurnalUmum(req *CreateJurnalRequest) (*postgres
 		jurnalDetails = append(jurnalDetails, jurnalDetail)
 	}
 
-	err = s.financialRepo.CreateJurnalDetail(jurnalDetails)
+	err := s.financialRepo.Transaction(func(tx *gorm.DB) error {
+		return s.saveJurnal(tx, jurnal, jurnalDetails)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create jurnal details: %v", err)
+		return nil, err
 	}
 
 	return jurnal, nil
 }
 
+// saveJurnal checks that the journal date falls in an open period and then
+// writes it with insertJurnal. Callers set status and source fields. A
+// journal written as posted is checked against the anggaran like PostJurnal
+// does; only block mode can stop it, as there is nobody to warn.
+func (s *FinancialService) saveJurnal(tx *gorm.DB, jurnal *postgres.JurnalUmum, details []postgres.JurnalDetail) error {
+	err := s.ensurePeriodeOpen(tx, jurnal.KoperasiID, jurnal.TanggalTransaksi)
+	if err != nil {
+		return err
+	}
+
+	if jurnal.Status == "posted" {
+		jurnal.JurnalDetail = details
+		if _, err := s.checkAnggaran(tx, jurnal); err != nil {
+			return err
+		}
+	}
+
+	return s.insertJurnal(tx, jurnal, details)
+}
+
+// insertJurnal checks that the lines balance, numbers the journal and writes
+// the header and its details inside tx without looking at the period. A
+// journal written as posted is added to the hash chain. Only the year-end
+// close should call it directly.
+func (s *FinancialService) insertJurnal(tx *gorm.DB, jurnal *postgres.JurnalUmum, details []postgres.JurnalDetail) error {
+	var totalDebit, totalKredit money.Amount
+	for _, detail := range details {
+		totalDebit += detail.Debit
+		totalKredit += detail.Kredit
+	}
+
+	if totalDebit != totalKredit {
+		return fmt.Errorf("total debit (%s) must equal total kredit (%s)", totalDebit, totalKredit)
+	}
+
+	nomorJurnal, err := s.generateNomorJurnal(jurnal.TenantID, jurnal.KoperasiID)
+	if err != nil {
+		return fmt.Errorf("failed to generate nomor jurnal: %v", err)
+	}
+
+	jurnal.NomorJurnal = nomorJurnal
+	jurnal.TotalDebit = totalDebit
+	jurnal.TotalKredit = totalKredit
+
+	financialRepo := s.financialRepo.WithTx(tx)
+	err = financialRepo.CreateJurnalUmum(jurnal)
+	if err != nil {
+		return fmt.Errorf("failed to create jurnal umum: %v", err)
+	}
+
+	for i := range details {
+		details[i].JurnalID = jurnal.ID
+	}
+
+	err = financialRepo.CreateJurnalDetail(details)
+	if err != nil {
+		return fmt.Errorf("failed to create jurnal details: %v", err)
+	}
+
+	jurnal.JurnalDetail = details
+
+	if jurnal.Status == "posted" {
+		return s.rantaiJurnal(tx, jurnal)
+	}
+	return nil
+}
+
 func (s *FinancialService) GetJurnalUmumByID(id uint64) (*postgres.JurnalUmum, error) {
 	return s.financialRepo.GetJurnalUmumByID(id)
 }
@@ -125,17 +190,225 @@ func (s *FinancialService) GetJurnalUmumList(koperasiID uint64, dari, sampai tim
 	return s.financialRepo.GetJurnalUmumByKoperasi(koperasiID, dari, sampai, limit, offset)
 }
 
-func (s *FinancialService) PostJurnal(id uint64, postedBy uint64) error {
+// PostJurnal posts a draft journal. A journal marked WajibLampiran needs at
+// least one attachment first. When the year's approved RAPB has budget
+// control switched on, beban lines are checked against the remaining budget:
+// overspending is returned as warnings, or refused in block mode.
+func (s *FinancialService) PostJurnal(id uint64, postedBy uint64) ([]PeringatanAnggaran, error) {
+	jurnal, err := s.financialRepo.GetJurnalUmumByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("jurnal not found: %v", err)
+	}
+
+	if jurnal.Status != "draft" {
+		return nil, fmt.Errorf("only draft journals can be posted")
+	}
+
+	if jurnal.WajibLampiran {
+		jumlah, err := s.lampiranRepo.CountByDokumen(postgres.LampiranJurnalUmum, id)
+		if err != nil {
+			return nil, err
+		}
+		if jumlah == 0 {
+			return nil, fmt.Errorf("jurnal %s requires an attachment before posting", jurnal.NomorJurnal)
+		}
+	}
+
+	var peringatan []PeringatanAnggaran
+	err = s.financialRepo.Transaction(func(tx *gorm.DB) error {
+		err := s.ensurePeriodeOpen(tx, jurnal.KoperasiID, jurnal.TanggalTransaksi)
+		if err != nil {
+			return err
+		}
+
+		peringatan, err = s.checkAnggaran(tx, jurnal)
+		if err != nil {
+			return err
+		}
+
+		posted, err := s.financialRepo.WithTx(tx).UpdateJurnalStatusFrom(id, "draft", "posted", postedBy)
+		if err != nil {
+			return err
+		}
+		if !posted {
+			return fmt.Errorf("jurnal %s is no longer a draft", jurnal.NomorJurnal)
+		}
+		return s.rantaiJurnal(tx, jurnal)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return peringatan, nil
+}
+
+// SetWajibLampiran sets whether a draft journal needs an attachment before
+// it can be posted.
+func (s *FinancialService) SetWajibLampiran(id uint64, wajib bool) error {
 	jurnal, err := s.financialRepo.GetJurnalUmumByID(id)
 	if err != nil {
 		return fmt.Errorf("jurnal not found: %v", err)
 	}
 
 	if jurnal.Status != "draft" {
-		return fmt.Errorf("only draft journals can be posted")
+		return fmt.Errorf("only draft journals can be changed")
+	}
+
+	return s.financialRepo.UpdateJurnalWajibLampiran(id, wajib)
+}
+
+// checkAnggaran compares the beban a journal adds with the budget left from
+// January up to the journal's month. Lines on a sub-account use the nearest
+// budgeted parent; beban with no budget line at all counts as unbudgeted.
+// tx may be nil.
+func (s *FinancialService) checkAnggaran(tx *gorm.DB, jurnal *postgres.JurnalUmum) ([]PeringatanAnggaran, error) {
+	tahun := jurnal.TanggalTransaksi.Year()
+	bulan := int(jurnal.TanggalTransaksi.Month())
+
+	anggaranRepo, financialRepo := s.anggaranRepo, s.financialRepo
+	if tx != nil {
+		anggaranRepo, financialRepo = anggaranRepo.WithTx(tx), financialRepo.WithTx(tx)
+	}
+
+	anggaran, err := anggaranRepo.GetAnggaranApproved(jurnal.KoperasiID, tahun)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load anggaran: %v", err)
+	}
+
+	if anggaran.KontrolAnggaran != "warn" && anggaran.KontrolAnggaran != "block" {
+		return nil, nil
+	}
+
+	akuns, err := financialRepo.GetCOAAkunAll(jurnal.KoperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load COA: %v", err)
+	}
+
+	akunByID := make(map[uint64]postgres.COAAkun)
+	parent := make(map[uint64]uint64)
+	for _, akun := range akuns {
+		akunByID[akun.ID] = akun
+		parent[akun.ID] = akun.ParentID
+	}
+
+	budgeted := make(map[uint64]bool)
+	batas := make(map[uint64]money.Amount)
+	for _, detail := range anggaran.Details {
+		budgeted[detail.AkunID] = true
+		if detail.Bulan <= bulan {
+			batas[detail.AkunID] += detail.Jumlah
+		}
+	}
+
+	tambahan := make(map[uint64]money.Amount)
+	var targets []uint64
+	for _, detail := range jurnal.JurnalDetail {
+		if akunByID[detail.AkunID].Kategori.Tipe != "beban" {
+			continue
+		}
+		target := anggaranTarget(budgeted, parent, detail.AkunID)
+		if target == 0 {
+			target = detail.AkunID
+		}
+		if _, ok := tambahan[target]; !ok {
+			targets = append(targets, target)
+		}
+		tambahan[target] += detail.Debit - detail.Kredit
+	}
+
+	if len(targets) == 0 {
+		return nil, nil
 	}
 
-	return s.financialRepo.UpdateJurnalStatus(id, "posted", postedBy)
+	realisasi, err := anggaranRepo.GetRealisasiBulanan(jurnal.KoperasiID, tahun)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get realisasi: %v", err)
+	}
+
+	terpakai := make(map[uint64]money.Amount)
+	for _, data := range realisasi {
+		if data.Bulan > bulan {
+			continue
+		}
+		target := anggaranTarget(budgeted, parent, data.AkunID)
+		if target == 0 {
+			target = data.AkunID
+		}
+		terpakai[target] += saldoNormal(data.SaldoNormal, data.TotalDebit, data.TotalKredit)
+	}
+
+	var peringatan []PeringatanAnggaran
+	for _, target := range targets {
+		jumlah := tambahan[target]
+		sisa := batas[target] - terpakai[target]
+		if jumlah <= 0 || jumlah <= sisa {
+			continue
+		}
+
+		akun := akunByID[target]
+		if anggaran.KontrolAnggaran == "block" {
+			return nil, fmt.Errorf("jurnal exceeds anggaran for akun %s: remaining %s, jurnal %s",
+				akun.KodeAkun, sisa, jumlah)
+		}
+
+		peringatan = append(peringatan, PeringatanAnggaran{
+			AkunID:   target,
+			KodeAkun: akun.KodeAkun,
+			NamaAkun: akun.NamaAkun,
+			Anggaran: batas[target],
+			Terpakai: terpakai[target],
+			Sisa:     sisa,
+			Jumlah:   jumlah,
+		})
+	}
+
+	return peringatan, nil
+}
+
+// ensurePeriodeOpen rejects dates that fall in a closed or locked period or
+// on or before the opening balance cutover or the last year-end close. Other
+// months without a period record are treated as open. tx may be nil.
+func (s *FinancialService) ensurePeriodeOpen(tx *gorm.DB, koperasiID uint64, tanggal time.Time) error {
+	periodeRepo := s.periodeRepo
+	if tx != nil {
+		periodeRepo = periodeRepo.WithTx(tx)
+	}
+
+	cutover, err := periodeRepo.GetTanggalCutover(koperasiID)
+	if err != nil {
+		return fmt.Errorf("failed to check saldo awal: %v", err)
+	}
+	if cutover != nil && tanggal.Before(cutover.AddDate(0, 0, 1)) {
+		return fmt.Errorf("tanggal %s is on or before the saldo awal cutover %s",
+			tanggal.Format("2006-01-02"), cutover.Format("2006-01-02"))
+	}
+
+	// A closed year stays closed even for months that never had a period row.
+	tahunTutup, err := periodeRepo.GetTahunTutupBukuTerakhir(koperasiID)
+	if err != nil {
+		return fmt.Errorf("failed to check tutup buku: %v", err)
+	}
+	if tahunTutup > 0 && tanggal.Before(time.Date(tahunTutup+1, 1, 1, 0, 0, 0, 0, time.Local)) {
+		return fmt.Errorf("tanggal %s is in tahun buku %d or earlier, which has been closed",
+			tanggal.Format("2006-01-02"), tahunTutup)
+	}
+
+	periode, err := periodeRepo.GetPeriodeByTanggal(koperasiID, tanggal)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return fmt.Errorf("failed to check periode: %v", err)
+	}
+
+	if periode.Status != "open" {
+		return fmt.Errorf("periode %02d/%d is %s", periode.Bulan, periode.Tahun, periode.Status)
+	}
+
+	return nil
 }
 
 func (s *FinancialService) CancelJurnal(id uint64, cancelledBy uint64) error {
@@ -148,7 +421,144 @@ func (s *FinancialService) CancelJurnal(id uint64, cancelledBy uint64) error {
 		return fmt.Errorf("posted journals cannot be cancelled, create reversal journal instead")
 	}
 
-	return s.financialRepo.UpdateJurnalStatus(id, "cancelled", cancelledBy)
+	cancelled, err := s.financialRepo.UpdateJurnalStatusFrom(id, jurnal.Status, "cancelled", cancelledBy)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return fmt.Errorf("jurnal %s changed while cancelling it", jurnal.NomorJurnal)
+	}
+	return nil
+}
+
+func (s *FinancialService) ReverseJurnal(id uint64, req *ReverseJurnalRequest, reversedBy uint64) (*postgres.JurnalUmum, error) {
+	jurnal, err := s.financialRepo.GetJurnalUmumByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("jurnal not found: %v", err)
+	}
+
+	var reversal *postgres.JurnalUmum
+	err = s.financialRepo.Transaction(func(tx *gorm.DB) error {
+		reversal, err = s.reverseJurnal(tx, jurnal, req, reversedBy)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reversal, nil
+}
+
+// ReverseJurnalBySumber reverses the journal that was auto-posted for a
+// business document, e.g. ("ppob_transaksi", 42).
+func (s *FinancialService) ReverseJurnalBySumber(sumberTransaksi string, sumberID uint64, req *ReverseJurnalRequest, reversedBy uint64) (*postgres.JurnalUmum, error) {
+	var reversal *postgres.JurnalUmum
+	err := s.financialRepo.Transaction(func(tx *gorm.DB) error {
+		var err error
+		reversal, err = s.ReverseJurnalBySumberTx(tx, sumberTransaksi, sumberID, req, reversedBy)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reversal, nil
+}
+
+// ReverseJurnalBySumberTx is ReverseJurnalBySumber inside the caller's
+// transaction, for services that cancel the document and its journal
+// together.
+func (s *FinancialService) ReverseJurnalBySumberTx(tx *gorm.DB, sumberTransaksi string, sumberID uint64, req *ReverseJurnalRequest, reversedBy uint64) (*postgres.JurnalUmum, error) {
+	jurnals, err := s.financialRepo.WithTx(tx).GetJurnalUmumBySumber(sumberTransaksi, sumberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load jurnal: %v", err)
+	}
+
+	for i := range jurnals {
+		if jurnals[i].Status == "posted" && jurnals[i].ReversalOfID == 0 {
+			return s.reverseJurnal(tx, &jurnals[i], req, reversedBy)
+		}
+	}
+
+	return nil, fmt.Errorf("no posted jurnal found for %s %d", sumberTransaksi, sumberID)
+}
+
+func (s *FinancialService) GetJurnalBySumber(sumberTransaksi string, sumberID uint64) ([]postgres.JurnalUmum, error) {
+	return s.financialRepo.GetJurnalUmumBySumber(sumberTransaksi, sumberID)
+}
+
+// reverseJurnal posts a mirror of jurnal with debit and kredit swapped and
+// marks the original as reversed. The reversal keeps the original source so
+// both entries show up against the business document.
+func (s *FinancialService) reverseJurnal(tx *gorm.DB, jurnal *postgres.JurnalUmum, req *ReverseJurnalRequest, reversedBy uint64) (*postgres.JurnalUmum, error) {
+	if jurnal.Status != "posted" {
+		return nil, fmt.Errorf("only posted journals can be reversed")
+	}
+	if jurnal.ReversalOfID != 0 {
+		return nil, fmt.Errorf("jurnal %s is itself a reversal", jurnal.NomorJurnal)
+	}
+	if jurnal.SumberTransaksi == SumberSaldoAwal {
+		return nil, fmt.Errorf("jurnal %s is the locked saldo awal journal", jurnal.NomorJurnal)
+	}
+
+	now := time.Now()
+	tanggal := now
+	if req.TanggalTransaksi != nil {
+		tanggal = *req.TanggalTransaksi
+	}
+	if tanggal.Before(jurnal.TanggalTransaksi) {
+		return nil, fmt.Errorf("reversal date cannot be before the original journal date")
+	}
+
+	keterangan := req.Keterangan
+	if keterangan == "" {
+		keterangan = fmt.Sprintf("Reversal %s: %s", jurnal.NomorJurnal, jurnal.Keterangan)
+	}
+
+	reversal := &postgres.JurnalUmum{
+		TenantID:         jurnal.TenantID,
+		KoperasiID:       jurnal.KoperasiID,
+		TanggalTransaksi: tanggal,
+		Referensi:        jurnal.NomorJurnal,
+		Keterangan:       keterangan,
+		Status:           "posted",
+		SumberTransaksi:  jurnal.SumberTransaksi,
+		SumberID:         jurnal.SumberID,
+		AnggotaID:        jurnal.AnggotaID,
+		ReversalOfID:     jurnal.ID,
+		CreatedBy:        reversedBy,
+		PostedAt:         &now,
+		PostedBy:         reversedBy,
+	}
+
+	var details []postgres.JurnalDetail
+	for _, detail := range jurnal.JurnalDetail {
+		details = append(details, postgres.JurnalDetail{
+			AkunID:     detail.AkunID,
+			Keterangan: detail.Keterangan,
+			Debit:      detail.Kredit,
+			Kredit:     detail.Debit,
+		})
+	}
+
+	err := s.saveJurnal(tx, reversal, details)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.financialRepo.WithTx(tx).MarkJurnalReversed(jurnal.ID, reversal.ID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("jurnal %s has already been reversed", jurnal.NomorJurnal)
+		}
+		return nil, fmt.Errorf("failed to mark jurnal reversed: %v", err)
+	}
+
+	jurnal.Status = "reversed"
+	jurnal.ReversedByID = reversal.ID
+	jurnal.ReversedAt = &now
+
+	return reversal, nil
 }
 
 func (s *FinancialService) GetNeracaSaldo(koperasiID uint64, tanggal time.Time) ([]postgresRepo.NeracaSaldoItem, error) {
@@ -163,10 +573,503 @@ func (s *FinancialService) GetNeraca(koperasiID uint64, tanggal time.Time) (*pos
 	return s.financialRepo.GetNeraca(koperasiID, tanggal)
 }
 
-func (s *FinancialService) GetSaldoAkun(akunID uint64, tanggal time.Time) (float64, error) {
+func (s *FinancialService) GetSaldoAkun(akunID uint64, tanggal time.Time) (money.Amount, error) {
 	return s.financialRepo.GetSaldoAkun(akunID, tanggal)
 }
 
+// GetBukuBesar builds the general ledger for one akun or a kode_akun range.
+// Each selected akun rolls up the lines of all its sub-accounts, and the
+// running balance is kept in the akun's SaldoNormal direction. Sampai covers
+// the whole day.
+func (s *FinancialService) GetBukuBesar(req *BukuBesarRequest) ([]BukuBesarAkun, error) {
+	if req.AkunID == 0 && req.KodeDari == "" && req.KodeSampai == "" {
+		return nil, fmt.Errorf("akun_id or kode_dari/kode_sampai is required")
+	}
+	if req.Sampai.Before(req.Dari) {
+		return nil, fmt.Errorf("sampai must not be before dari")
+	}
+	sampai := req.Sampai.AddDate(0, 0, 1).Add(-time.Nanosecond)
+
+	akuns, err := s.financialRepo.GetCOAAkunAll(req.KoperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load COA: %v", err)
+	}
+
+	children := make(map[uint64][]uint64)
+	for _, akun := range akuns {
+		if akun.ParentID != 0 {
+			children[akun.ParentID] = append(children[akun.ParentID], akun.ID)
+		}
+	}
+
+	var selected []postgres.COAAkun
+	for _, akun := range akuns {
+		if req.AkunID != 0 {
+			if akun.ID == req.AkunID {
+				selected = append(selected, akun)
+			}
+			continue
+		}
+		if req.KodeDari != "" && akun.KodeAkun < req.KodeDari {
+			continue
+		}
+		if req.KodeSampai != "" && akun.KodeAkun > req.KodeSampai {
+			continue
+		}
+		selected = append(selected, akun)
+	}
+
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no akun found for koperasi %d", req.KoperasiID)
+	}
+
+	var ledger []BukuBesarAkun
+	for _, akun := range selected {
+		akunIDs := []uint64{akun.ID}
+		for i := 0; i < len(akunIDs); i++ {
+			akunIDs = append(akunIDs, children[akunIDs[i]]...)
+		}
+
+		debitAwal, kreditAwal, err := s.financialRepo.GetTotalAkunSebelum(akunIDs, req.Dari)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get opening balance for %s: %v", akun.KodeAkun, err)
+		}
+
+		lines, err := s.financialRepo.GetBukuBesarLines(akunIDs, req.Dari, sampai)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get ledger lines for %s: %v", akun.KodeAkun, err)
+		}
+
+		item := BukuBesarAkun{
+			AkunID:      akun.ID,
+			KodeAkun:    akun.KodeAkun,
+			NamaAkun:    akun.NamaAkun,
+			SaldoNormal: akun.SaldoNormal,
+			SubAkun:     len(akunIDs) - 1,
+			SaldoAwal:   saldoNormal(akun.SaldoNormal, debitAwal, kreditAwal),
+			Entries:     make([]BukuBesarEntry, 0, len(lines)),
+		}
+
+		saldo := item.SaldoAwal
+		for _, line := range lines {
+			saldo += saldoNormal(akun.SaldoNormal, line.Debit, line.Kredit)
+			item.TotalDebit += line.Debit
+			item.TotalKredit += line.Kredit
+			item.Entries = append(item.Entries, BukuBesarEntry{BukuBesarLine: line, Saldo: saldo})
+		}
+
+		item.SaldoAkhir = saldo
+		ledger = append(ledger, item)
+	}
+
+	return ledger, nil
+}
+
+// WriteBukuBesarCSV writes the ledger as CSV, one block per akun with its
+// opening and closing balance rows.
+func (s *FinancialService) WriteBukuBesarCSV(w io.Writer, ledger []BukuBesarAkun) error {
+	writer := csv.NewWriter(w)
+	amount := func(v money.Amount) string {
+		return v.String()
+	}
+
+	header := []string{"kode_akun", "nama_akun", "tanggal", "nomor_jurnal", "referensi", "keterangan", "debit", "kredit", "saldo"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, akun := range ledger {
+		rows := [][]string{{akun.KodeAkun, akun.NamaAkun, "", "", "", "Saldo awal", "", "", amount(akun.SaldoAwal)}}
+		for _, entry := range akun.Entries {
+			rows = append(rows, []string{
+				entry.KodeAkun,
+				entry.NamaAkun,
+				entry.TanggalTransaksi.Format("2006-01-02"),
+				entry.NomorJurnal,
+				entry.Referensi,
+				entry.Keterangan,
+				amount(entry.Debit),
+				amount(entry.Kredit),
+				amount(entry.Saldo),
+			})
+		}
+		rows = append(rows, []string{akun.KodeAkun, akun.NamaAkun, "", "", "", "Saldo akhir",
+			amount(akun.TotalDebit), amount(akun.TotalKredit), amount(akun.SaldoAkhir)})
+
+		if err := writer.WriteAll(rows); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// saldoNormal turns a debit/kredit movement into a balance change in the
+// akun's normal direction.
+func saldoNormal(normal string, debit, kredit money.Amount) money.Amount {
+	if normal == "kredit" {
+		return kredit - debit
+	}
+	return debit - kredit
+}
+
+// Cash flow activities used by the arus kas statement.
+const (
+	AktivitasOperasi   = "operasi"
+	AktivitasInvestasi = "investasi"
+	AktivitasPendanaan = "pendanaan"
+)
+
+func (s *FinancialService) CreateArusKasMapping(req *CreateArusKasMappingRequest) (*postgres.ArusKasMapping, error) {
+	if (req.AkunID == 0) == (req.KategoriID == 0) {
+		return nil, fmt.Errorf("set exactly one of akun_id or kategori_id")
+	}
+
+	if req.AkunID != 0 {
+		akun, err := s.financialRepo.GetCOAAkunByID(req.AkunID)
+		if err != nil {
+			return nil, fmt.Errorf("akun not found: %v", err)
+		}
+		if akun.KoperasiID != req.KoperasiID {
+			return nil, fmt.Errorf("akun %s does not belong to koperasi %d", akun.KodeAkun, req.KoperasiID)
+		}
+	}
+
+	mapping := &postgres.ArusKasMapping{
+		KoperasiID: req.KoperasiID,
+		KategoriID: req.KategoriID,
+		AkunID:     req.AkunID,
+		Aktivitas:  req.Aktivitas,
+	}
+
+	err := s.financialRepo.CreateArusKasMapping(mapping)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create arus kas mapping: %v", err)
+	}
+
+	return mapping, nil
+}
+
+func (s *FinancialService) GetArusKasMappings(koperasiID uint64) ([]postgres.ArusKasMapping, error) {
+	return s.financialRepo.GetArusKasMappings(koperasiID)
+}
+
+func (s *FinancialService) DeleteArusKasMapping(id uint64) error {
+	return s.financialRepo.DeleteArusKasMapping(id)
+}
+
+// GetArusKas builds the statement of cash flows for the days dari through
+// sampai; sampai covers the whole day.
+//
+// The direct method takes every journal that moves an IsKas account and
+// reports the non-cash side of it: a kredit on the other account is cash in,
+// a debit is cash out. The indirect method starts from laba bersih and adds
+// the movement of every non-cash balance-sheet account. Both reconcile the
+// opening kas balance to the closing one.
+func (s *FinancialService) GetArusKas(koperasiID uint64, dari, sampai time.Time, metode string) (*LaporanArusKas, error) {
+	if metode == "" {
+		metode = "langsung"
+	}
+	if metode != "langsung" && metode != "tidak_langsung" {
+		return nil, fmt.Errorf("unknown metode %s, use langsung or tidak_langsung", metode)
+	}
+
+	klasifikasi, err := s.arusKasClassifier(koperasiID)
+	if err != nil {
+		return nil, err
+	}
+
+	saldoAwal, err := s.financialRepo.GetSaldoKas(koperasiID, dari)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get opening cash: %v", err)
+	}
+
+	akhir := sampai.AddDate(0, 0, 1)
+	saldoAkhirBuku, err := s.financialRepo.GetSaldoKas(koperasiID, akhir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get closing cash: %v", err)
+	}
+
+	mutasi, err := s.financialRepo.GetMutasiNonKas(koperasiID, dari, akhir.Add(-time.Nanosecond), metode == "langsung")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account movements: %v", err)
+	}
+
+	laporan := &LaporanArusKas{
+		KoperasiID: koperasiID,
+		Dari:       dari,
+		Sampai:     sampai,
+		Metode:     metode,
+		SaldoAwal:  saldoAwal,
+		Operasi:    AktivitasArusKas{Aktivitas: AktivitasOperasi},
+		Investasi:  AktivitasArusKas{Aktivitas: AktivitasInvestasi},
+		Pendanaan:  AktivitasArusKas{Aktivitas: AktivitasPendanaan},
+	}
+
+	aktivitas := map[string]*AktivitasArusKas{
+		AktivitasOperasi:   &laporan.Operasi,
+		AktivitasInvestasi: &laporan.Investasi,
+		AktivitasPendanaan: &laporan.Pendanaan,
+	}
+
+	for _, item := range mutasi {
+		jumlah := item.TotalKredit - item.TotalDebit
+		if jumlah == 0 {
+			continue
+		}
+
+		target := aktivitas[klasifikasi(item)]
+		isLabaRugi := item.KategoriTipe == "pendapatan" || item.KategoriTipe == "beban"
+
+		// Indirect: revenue and expense in operations are summarised as laba
+		// bersih; anything mapped elsewhere is taken out of it and shown under
+		// its own activity.
+		if metode == "tidak_langsung" && isLabaRugi {
+			laporan.Operasi.LabaBersih += jumlah
+			if target == &laporan.Operasi {
+				continue
+			}
+			laporan.Operasi.tambah(ArusKasItem{
+				AkunID:   item.AkunID,
+				KodeAkun: item.KodeAkun,
+				NamaAkun: item.NamaAkun,
+				Jumlah:   -jumlah,
+			})
+		}
+
+		target.tambah(ArusKasItem{
+			AkunID:   item.AkunID,
+			KodeAkun: item.KodeAkun,
+			NamaAkun: item.NamaAkun,
+			Jumlah:   jumlah,
+		})
+	}
+
+	if metode == "tidak_langsung" {
+		laporan.Operasi.Bersih += laporan.Operasi.LabaBersih
+	}
+
+	laporan.KenaikanKas = laporan.Operasi.Bersih + laporan.Investasi.Bersih + laporan.Pendanaan.Bersih
+	laporan.SaldoAkhir = laporan.SaldoAwal + laporan.KenaikanKas
+	laporan.SaldoAkhirBuku = saldoAkhirBuku
+	laporan.Selisih = laporan.SaldoAkhirBuku - laporan.SaldoAkhir
+	laporan.IsBalanced = laporan.Selisih == 0
+
+	return laporan, nil
+}
+
+// arusKasClassifier resolves an akun to its cash flow activity: an akun
+// mapping on the akun or its nearest parent, then a kategori mapping, then
+// the default by kategori tipe (kewajiban and ekuitas are pendanaan,
+// everything else operasi).
+func (s *FinancialService) arusKasClassifier(koperasiID uint64) (func(postgresRepo.MutasiNonKas) string, error) {
+	mappings, err := s.financialRepo.GetArusKasMappings(koperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load arus kas mapping: %v", err)
+	}
+
+	akuns, err := s.financialRepo.GetCOAAkunByKoperasi(koperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load COA: %v", err)
+	}
+
+	parent := make(map[uint64]uint64)
+	for _, akun := range akuns {
+		parent[akun.ID] = akun.ParentID
+	}
+
+	byAkun := make(map[uint64]string)
+	byKategori := make(map[uint64]string)
+	for _, mapping := range mappings {
+		if mapping.AkunID != 0 {
+			byAkun[mapping.AkunID] = mapping.Aktivitas
+		} else {
+			byKategori[mapping.KategoriID] = mapping.Aktivitas
+		}
+	}
+
+	return func(item postgresRepo.MutasiNonKas) string {
+		akunID := item.AkunID
+		parentID := item.ParentID
+		for depth := 0; akunID != 0 && depth < 10; depth++ {
+			if aktivitas, ok := byAkun[akunID]; ok {
+				return aktivitas
+			}
+			akunID, parentID = parentID, parent[parentID]
+		}
+
+		if aktivitas, ok := byKategori[item.KategoriID]; ok {
+			return aktivitas
+		}
+
+		switch item.KategoriTipe {
+		case "kewajiban", "ekuitas":
+			return AktivitasPendanaan
+		default:
+			return AktivitasOperasi
+		}
+	}, nil
+}
+
+// rantaiJurnal appends a journal that has just been posted to its
+// koperasi's hash chain.
+func (s *FinancialService) rantaiJurnal(tx *gorm.DB, jurnal *postgres.JurnalUmum) error {
+	financialRepo := s.financialRepo.WithTx(tx)
+
+	terakhir, err := financialRepo.GetRantaiJurnalTerakhir(jurnal.KoperasiID)
+	if err != nil {
+		return fmt.Errorf("failed to read jurnal hash chain: %v", err)
+	}
+
+	rantai := &postgres.RantaiJurnal{
+		KoperasiID: jurnal.KoperasiID,
+		Urutan:     1,
+		JurnalID:   jurnal.ID,
+	}
+	if terakhir != nil {
+		rantai.Urutan = terakhir.Urutan + 1
+		rantai.HashSebelumnya = terakhir.Hash
+	}
+	rantai.Hash = hashJurnal(jurnal, rantai.Urutan, rantai.HashSebelumnya)
+
+	if err := financialRepo.CreateRantaiJurnal(rantai); err != nil {
+		return fmt.Errorf("failed to extend jurnal hash chain: %v", err)
+	}
+	return nil
+}
+
+// VerifikasiRantaiJurnal walks the koperasi's hash chain from the start and
+// stops at the first link that does not hold: a missing link, a link that
+// does not point at the hash before it, or a journal whose header or lines
+// no longer hash to what was recorded when it was posted.
+//
+// The chain alone cannot tell when its last links were removed, or when the
+// whole chain was rehashed by someone with write access to the database. An
+// anchor, the UrutanTerakhir and HashTerakhir of an earlier verification kept
+// outside the database, closes that gap: the chain must still reach the
+// anchored link and hash to the same value there.
+func (s *FinancialService) VerifikasiRantaiJurnal(koperasiID uint64, anchor *AnchorRantaiJurnal) (*VerifikasiRantaiJurnal, error) {
+	hasil := &VerifikasiRantaiJurnal{KoperasiID: koperasiID, Utuh: true}
+
+	const batch = 500
+	var urutan uint64
+	var hashSebelumnya string
+	for {
+		rantai, err := s.financialRepo.GetRantaiJurnal(koperasiID, urutan, batch)
+		if err != nil {
+			return nil, err
+		}
+		if len(rantai) == 0 {
+			break
+		}
+
+		ids := make([]uint64, len(rantai))
+		for i, r := range rantai {
+			ids[i] = r.JurnalID
+		}
+		jurnals, err := s.financialRepo.GetJurnalUntukRantai(ids)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range rantai {
+			putus := &RantaiPutus{Urutan: r.Urutan, JurnalID: r.JurnalID}
+			jurnal, ada := jurnals[r.JurnalID]
+			if ada {
+				putus.NomorJurnal = jurnal.NomorJurnal
+			}
+
+			switch {
+			case r.Urutan != urutan+1:
+				putus.Urutan = urutan + 1
+				putus.JurnalID = 0
+				putus.NomorJurnal = ""
+				putus.Alasan = fmt.Sprintf("link %d is missing", urutan+1)
+			case r.HashSebelumnya != hashSebelumnya:
+				putus.Alasan = "previous hash does not match the link before"
+			case !ada:
+				putus.Alasan = "jurnal has been deleted"
+			case jurnal.KoperasiID != koperasiID:
+				putus.Alasan = "jurnal has been moved to another koperasi"
+			case jurnal.Status != "posted" && jurnal.Status != "reversed":
+				putus.Alasan = fmt.Sprintf("jurnal status changed to %s", jurnal.Status)
+			case hashJurnal(&jurnal, r.Urutan, r.HashSebelumnya) != r.Hash:
+				putus.Alasan = "jurnal header or lines have been changed"
+			case anchor != nil && r.Urutan == anchor.Urutan && r.Hash != anchor.Hash:
+				putus.Alasan = "hash does not match the anchor"
+			default:
+				putus = nil
+			}
+
+			if putus != nil {
+				hasil.Utuh = false
+				hasil.Putus = putus
+				return s.hitungJurnalTanpaRantai(hasil)
+			}
+
+			hasil.JumlahDiperiksa++
+			urutan = r.Urutan
+			hashSebelumnya = r.Hash
+			hasil.UrutanTerakhir = r.Urutan
+			hasil.HashTerakhir = r.Hash
+		}
+	}
+
+	if anchor != nil && urutan < anchor.Urutan {
+		hasil.Utuh = false
+		hasil.Putus = &RantaiPutus{
+			Urutan: urutan + 1,
+			Alasan: fmt.Sprintf("chain ends at link %d, before the anchor at link %d", urutan, anchor.Urutan),
+		}
+	}
+
+	return s.hitungJurnalTanpaRantai(hasil)
+}
+
+func (s *FinancialService) hitungJurnalTanpaRantai(hasil *VerifikasiRantaiJurnal) (*VerifikasiRantaiJurnal, error) {
+	count, err := s.financialRepo.CountJurnalTanpaRantai(hasil.KoperasiID)
+	if err != nil {
+		return nil, err
+	}
+	hasil.JurnalTanpaRantai = count
+	return hasil, nil
+}
+
+// hashJurnal hashes the fields of a journal that never change once it is
+// posted, with its lines in ID order. Status and the reversal fields are
+// left out because reversing a journal legitimately updates them. The hash
+// is plain SHA-256 with no secret key, so it detects edits made without
+// recomputing the chain; anyone able to rewrite the table can also rewrite
+// the hashes, which only an anchor kept elsewhere can reveal.
+func hashJurnal(jurnal *postgres.JurnalUmum, urutan uint64, hashSebelumnya string) string {
+	details := make([]postgres.JurnalDetail, len(jurnal.JurnalDetail))
+	copy(details, jurnal.JurnalDetail)
+	sort.Slice(details, func(i, j int) bool { return details[i].ID < details[j].ID })
+
+	h := sha256.New()
+	fmt.Fprintf(h, "rantai:%d:%d:%s\n", jurnal.KoperasiID, urutan, hashSebelumnya)
+	fmt.Fprintf(h, "jurnal:%d|%d|%s|%s|%s|%s|%s|%s|%s|%d|%d|%d|%d\n",
+		jurnal.ID,
+		jurnal.TenantID,
+		strconv.Quote(jurnal.NomorJurnal),
+		jurnal.TanggalTransaksi.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+		strconv.Quote(jurnal.Referensi),
+		strconv.Quote(jurnal.Keterangan),
+		jurnal.TotalDebit,
+		jurnal.TotalKredit,
+		strconv.Quote(jurnal.SumberTransaksi),
+		jurnal.SumberID,
+		jurnal.AnggotaID,
+		jurnal.ReversalOfID,
+		jurnal.CreatedBy,
+	)
+	for _, d := range details {
+		fmt.Fprintf(h, "detail:%d|%d|%s|%s|%s\n", d.ID, d.AkunID, strconv.Quote(d.Keterangan), d.Debit, d.Kredit)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 func (s *FinancialService) generateNomorJurnal(tenantID, koperasiID uint64) (string, error) {
 	number, err := s.sequenceService.GetNextNumber(tenantID, koperasiID, "jurnal_umum")
 	if err != nil {
@@ -177,6 +1080,31 @@ func (s *FinancialService) generateNomorJurnal(tenantID, koperasiID uint64) (str
 	return fmt.Sprintf("JU%04d%02d%02d%06d", now.Year(), now.Month(), now.Day(), number), nil
 }
 
+type VerifikasiRantaiJurnal struct {
+	KoperasiID        uint64       `json:"koperasi_id"`
+	Utuh              bool         `json:"utuh"`
+	JumlahDiperiksa   int          `json:"jumlah_diperiksa"`
+	UrutanTerakhir    uint64       `json:"urutan_terakhir"`
+	HashTerakhir      string       `json:"hash_terakhir"`
+	Putus             *RantaiPutus `json:"putus,omitempty"`
+	JurnalTanpaRantai int64        `json:"jurnal_tanpa_rantai"`
+}
+
+// AnchorRantaiJurnal is a chain head taken from an earlier verification and
+// kept outside the database, e.g. in a signed report or an external log.
+type AnchorRantaiJurnal struct {
+	Urutan uint64 `json:"urutan"`
+	Hash   string `json:"hash"`
+}
+
+// RantaiPutus is the first link of the chain that failed verification.
+type RantaiPutus struct {
+	Urutan      uint64 `json:"urutan"`
+	JurnalID    uint64 `json:"jurnal_id"`
+	NomorJurnal string `json:"nomor_jurnal"`
+	Alasan      string `json:"alasan"`
+}
+
 type CreateCOAAkunRequest struct {
 	TenantID    uint64 `json:"tenant_id" binding:"required"`
 	KoperasiID  uint64 `json:"koperasi_id" binding:"required"`
@@ -196,12 +1124,99 @@ type CreateJurnalRequest struct {
 	Referensi        string                   `json:"referensi"`
 	Keterangan       string                   `json:"keterangan" binding:"required"`
 	Details          []CreateJurnalDetailRequest `json:"details" binding:"required,min=2"`
+	AnggotaID        uint64                   `json:"anggota_id"`
+	WajibLampiran    bool                     `json:"wajib_lampiran"`
 	CreatedBy        uint64                   `json:"created_by"`
 }
 
+type SetWajibLampiranRequest struct {
+	WajibLampiran bool `json:"wajib_lampiran"`
+}
+
 type CreateJurnalDetailRequest struct {
 	AkunID     uint64  `json:"akun_id" binding:"required"`
 	Keterangan string  `json:"keterangan"`
-	Debit      float64 `json:"debit"`
-	Kredit     float64 `json:"kredit"`
-}
\ No newline at end of file
+	Debit      money.Amount `json:"debit"`
+	Kredit     money.Amount `json:"kredit"`
+}
+
+type ReverseJurnalRequest struct {
+	TanggalTransaksi *time.Time `json:"tanggal_transaksi"`
+	Keterangan       string     `json:"keterangan"`
+}
+
+type CreateArusKasMappingRequest struct {
+	KoperasiID uint64 `json:"koperasi_id" binding:"required"`
+	KategoriID uint64 `json:"kategori_id"`
+	AkunID     uint64 `json:"akun_id"`
+	Aktivitas  string `json:"aktivitas" binding:"required,oneof=operasi investasi pendanaan"`
+}
+
+type LaporanArusKas struct {
+	KoperasiID     uint64           `json:"koperasi_id"`
+	Dari           time.Time        `json:"dari"`
+	Sampai         time.Time        `json:"sampai"`
+	Metode         string           `json:"metode"`
+	SaldoAwal      money.Amount     `json:"saldo_awal"`
+	Operasi        AktivitasArusKas `json:"operasi"`
+	Investasi      AktivitasArusKas `json:"investasi"`
+	Pendanaan      AktivitasArusKas `json:"pendanaan"`
+	KenaikanKas    money.Amount     `json:"kenaikan_kas"`
+	SaldoAkhir     money.Amount     `json:"saldo_akhir"`
+	SaldoAkhirBuku money.Amount     `json:"saldo_akhir_buku"`
+	Selisih        money.Amount     `json:"selisih"`
+	IsBalanced     bool             `json:"is_balanced"`
+}
+
+type AktivitasArusKas struct {
+	Aktivitas  string        `json:"aktivitas"`
+	LabaBersih money.Amount  `json:"laba_bersih,omitempty"`
+	Items      []ArusKasItem `json:"items"`
+	KasMasuk   money.Amount  `json:"kas_masuk"`
+	KasKeluar  money.Amount  `json:"kas_keluar"`
+	Bersih     money.Amount  `json:"bersih"`
+}
+
+func (a *AktivitasArusKas) tambah(item ArusKasItem) {
+	a.Items = append(a.Items, item)
+	if item.Jumlah > 0 {
+		a.KasMasuk += item.Jumlah
+	} else {
+		a.KasKeluar -= item.Jumlah
+	}
+	a.Bersih += item.Jumlah
+}
+
+type ArusKasItem struct {
+	AkunID   uint64  `json:"akun_id"`
+	KodeAkun string  `json:"kode_akun"`
+	NamaAkun string  `json:"nama_akun"`
+	Jumlah   money.Amount `json:"jumlah"`
+}
+
+type BukuBesarRequest struct {
+	KoperasiID uint64
+	AkunID     uint64
+	KodeDari   string
+	KodeSampai string
+	Dari       time.Time
+	Sampai     time.Time
+}
+
+type BukuBesarAkun struct {
+	AkunID      uint64           `json:"akun_id"`
+	KodeAkun    string           `json:"kode_akun"`
+	NamaAkun    string           `json:"nama_akun"`
+	SaldoNormal string           `json:"saldo_normal"`
+	SubAkun     int              `json:"sub_akun"`
+	SaldoAwal   money.Amount     `json:"saldo_awal"`
+	TotalDebit  money.Amount     `json:"total_debit"`
+	TotalKredit money.Amount     `json:"total_kredit"`
+	SaldoAkhir  money.Amount     `json:"saldo_akhir"`
+	Entries     []BukuBesarEntry `json:"entries"`
+}
+
+type BukuBesarEntry struct {
+	postgresRepo.BukuBesarLine
+	Saldo money.Amount `json:"saldo"`
+}
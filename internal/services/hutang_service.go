@@ -0,0 +1,515 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+)
+
+type HutangService struct {
+	produkRepo      *postgresRepo.ProdukRepository
+	postingService  *PostingService
+	pajakService    *PajakService
+	sequenceService *SequenceService
+}
+
+func NewHutangService(
+	produkRepo *postgresRepo.ProdukRepository,
+	postingService *PostingService,
+	pajakService *PajakService,
+	sequenceService *SequenceService,
+) *HutangService {
+	return &HutangService{
+		produkRepo:      produkRepo,
+		postingService:  postingService,
+		pajakService:    pajakService,
+		sequenceService: sequenceService,
+	}
+}
+
+// BayarHutang records one payment to a supplier. The amount is split over
+// the invoices listed in Alokasi or, without it, over the open invoices in
+// order of due date. Each invoice gets a PembayaranPembelian row sharing the
+// payment number, and one AP journal is posted for the whole payment.
+//
+// With PPh set the tax is withheld from what leaves kas: the invoices are
+// settled in full and the PPh is credited to its payable account. Unless
+// PPh.Bruto is given, the base is the part of the payment that is not PPN.
+func (s *HutangService) BayarHutang(req *BayarHutangRequest, createdBy uint64) (*PembayaranHutang, error) {
+	supplier, err := s.produkRepo.GetSupplierByID(req.SupplierID)
+	if err != nil {
+		return nil, fmt.Errorf("supplier not found: %v", err)
+	}
+	if supplier.KoperasiID != req.KoperasiID {
+		return nil, fmt.Errorf("supplier belongs to another koperasi")
+	}
+	if len(req.Alokasi) == 0 && req.Jumlah <= 0 {
+		return nil, fmt.Errorf("set jumlah or alokasi")
+	}
+
+	nomor, err := s.generateNomorPembayaran(req.KoperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate nomor pembayaran: %v", err)
+	}
+
+	result := &PembayaranHutang{
+		NomorPembayaran: nomor,
+		SupplierID:      supplier.ID,
+		TanggalBayar:    req.TanggalBayar,
+		Pembayaran:      []postgres.PembayaranPembelian{},
+	}
+
+	err = s.produkRepo.Transaction(func(tx *gorm.DB) error {
+		produkRepo := s.produkRepo.WithTx(tx)
+
+		alokasi, pembelians, err := s.alokasiPembayaran(produkRepo, req, supplier)
+		if err != nil {
+			return err
+		}
+
+		var bruto money.Amount
+		for i, jumlah := range alokasi {
+			pembelian := pembelians[i]
+
+			pembayaran := postgres.PembayaranPembelian{
+				PembelianHeaderID: pembelian.ID,
+				NomorPembayaran:   nomor,
+				TanggalBayar:      req.TanggalBayar,
+				JumlahBayar:       jumlah,
+				MetodePembayaran:  req.MetodePembayaran,
+				NomorReferensi:    req.NomorReferensi,
+				Keterangan:        req.Keterangan,
+				CreatedBy:         createdBy,
+			}
+			if err := produkRepo.CreatePembayaranPembelian(&pembayaran); err != nil {
+				return fmt.Errorf("failed to record pembayaran: %v", err)
+			}
+
+			totalBayar := pembelian.TotalBayar + jumlah
+			status := "partial"
+			if totalBayar >= pembelian.GrandTotal {
+				status = "paid"
+			}
+			if err := produkRepo.UpdatePembelianPembayaran(pembelian.ID, totalBayar, status, createdBy); err != nil {
+				return fmt.Errorf("failed to update pembelian %s: %v", pembelian.NomorFaktur, err)
+			}
+
+			result.Total += jumlah
+			result.Pembayaran = append(result.Pembayaran, pembayaran)
+			bruto += jumlah.Prorate(pembelian.GrandTotal-pembelian.TotalPajak, pembelian.GrandTotal, money.RoundSen)
+		}
+
+		if req.PPh != nil {
+			if req.PPh.Bruto > 0 {
+				bruto = req.PPh.Bruto
+			}
+			potongan := &postgres.PotonganPajak{
+				KoperasiID:      req.KoperasiID,
+				JenisPajak:      req.PPh.Jenis,
+				KodeObjekPajak:  req.PPh.KodeObjekPajak,
+				TanggalPotong:   req.TanggalBayar,
+				SumberTransaksi: "pembayaran_pembelian",
+				SumberID:        result.Pembayaran[0].ID,
+				NamaPenerima:    supplier.Nama,
+				NPWP:            supplier.NPWP,
+				Alamat:          supplier.Alamat,
+				Bruto:           bruto,
+				CreatedBy:       createdBy,
+			}
+			if err := s.pajakService.potongPPh(tx, potongan, req.PPh.Tarif); err != nil {
+				return err
+			}
+			if potongan.JumlahPPh >= result.Total {
+				return fmt.Errorf("pph %s is not less than the payment %s", potongan.JumlahPPh, result.Total)
+			}
+			if potongan.ID != 0 {
+				result.PPh = potongan.JumlahPPh
+				result.Potongan = potongan
+			}
+		}
+		result.Dibayar = result.Total - result.PPh
+
+		jurnal, err := s.postingService.Post(tx, &PostingRequest{
+			KoperasiID:       req.KoperasiID,
+			KodeEvent:        PostingEventPembayaranHutang,
+			TanggalTransaksi: req.TanggalBayar,
+			Referensi:        nomor,
+			Keterangan:       fmt.Sprintf("Pembayaran hutang %s", supplier.Nama),
+			SumberTransaksi:  "pembayaran_pembelian",
+			SumberID:         result.Pembayaran[0].ID,
+			Komponen: map[string]money.Amount{
+				"total":              result.Total,
+				req.MetodePembayaran: result.Dibayar,
+				"pph":                result.PPh,
+			},
+			CreatedBy: createdBy,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to post jurnal: %v", err)
+		}
+
+		if jurnal != nil {
+			result.JurnalID = jurnal.ID
+			if result.Potongan != nil {
+				result.Potongan.JurnalID = jurnal.ID
+				if err := s.pajakService.updatePotonganJurnal(tx, result.Potongan.ID, jurnal.ID); err != nil {
+					return fmt.Errorf("failed to link potongan pajak: %v", err)
+				}
+			}
+			return produkRepo.UpdatePembayaranJurnal(nomor, jurnal.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// alokasiPembayaran returns the amount paid on each invoice, index aligned
+// with the locked invoices.
+func (s *HutangService) alokasiPembayaran(produkRepo *postgresRepo.ProdukRepository, req *BayarHutangRequest, supplier *postgres.Supplier) ([]money.Amount, []postgres.PembelianHeader, error) {
+	var alokasi []money.Amount
+	var pembelians []postgres.PembelianHeader
+
+	if len(req.Alokasi) > 0 {
+		seen := make(map[uint64]bool)
+		for _, item := range req.Alokasi {
+			if seen[item.PembelianHeaderID] {
+				return nil, nil, fmt.Errorf("pembelian %d is listed twice", item.PembelianHeaderID)
+			}
+			seen[item.PembelianHeaderID] = true
+
+			pembelian, err := produkRepo.GetPembelianForUpdate(item.PembelianHeaderID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("pembelian %d not found: %v", item.PembelianHeaderID, err)
+			}
+			if pembelian.KoperasiID != req.KoperasiID || pembelian.SupplierID != supplier.ID {
+				return nil, nil, fmt.Errorf("pembelian %s is not an invoice of supplier %s", pembelian.NomorFaktur, supplier.Nama)
+			}
+
+			jumlah := item.Jumlah
+			sisa := pembelian.GrandTotal - pembelian.TotalBayar
+			if sisa <= 0 {
+				return nil, nil, fmt.Errorf("pembelian %s is already paid", pembelian.NomorFaktur)
+			}
+			if jumlah > sisa {
+				return nil, nil, fmt.Errorf("payment %s exceeds outstanding %s on %s", jumlah, sisa, pembelian.NomorFaktur)
+			}
+
+			alokasi = append(alokasi, jumlah)
+			pembelians = append(pembelians, *pembelian)
+		}
+		return alokasi, pembelians, nil
+	}
+
+	terbuka, err := produkRepo.GetPembelianBelumLunas(req.KoperasiID, supplier.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load open invoices: %v", err)
+	}
+	sort.SliceStable(terbuka, func(i, j int) bool {
+		return jatuhTempoHutang(&terbuka[i], supplier).Before(jatuhTempoHutang(&terbuka[j], supplier))
+	})
+
+	sisaBayar := req.Jumlah
+	for _, pembelian := range terbuka {
+		if sisaBayar <= 0 {
+			break
+		}
+		sisa := pembelian.GrandTotal - pembelian.TotalBayar
+		if sisa <= 0 {
+			continue
+		}
+
+		jumlah := sisa
+		if sisaBayar < sisa {
+			jumlah = sisaBayar
+		}
+		alokasi = append(alokasi, jumlah)
+		pembelians = append(pembelians, pembelian)
+		sisaBayar -= jumlah
+	}
+
+	if sisaBayar > 0 {
+		return nil, nil, fmt.Errorf("payment exceeds the outstanding balance of supplier %s by %s", supplier.Nama, sisaBayar)
+	}
+
+	return alokasi, pembelians, nil
+}
+
+func (s *HutangService) GetPembayaran(nomorPembayaran string) ([]postgres.PembayaranPembelian, error) {
+	return s.produkRepo.GetPembayaranByNomor(nomorPembayaran)
+}
+
+// GetKartuHutang lists a supplier's invoices and payments in date order with
+// the running balance owed.
+func (s *HutangService) GetKartuHutang(supplierID uint64) (*KartuHutang, error) {
+	supplier, err := s.produkRepo.GetSupplierByID(supplierID)
+	if err != nil {
+		return nil, fmt.Errorf("supplier not found: %v", err)
+	}
+
+	pembelians, err := s.produkRepo.GetPembelianBySupplier(supplierID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pembelian: %v", err)
+	}
+
+	kartu := &KartuHutang{
+		SupplierID: supplier.ID,
+		Kode:       supplier.Kode,
+		Nama:       supplier.Nama,
+		Entries:    []KartuHutangEntry{},
+	}
+
+	for _, pembelian := range pembelians {
+		kartu.Entries = append(kartu.Entries, KartuHutangEntry{
+			Tanggal:     pembelian.TanggalFaktur,
+			Jenis:       "faktur",
+			Nomor:       pembelian.NomorFaktur,
+			Keterangan:  pembelian.Keterangan,
+			PembelianID: pembelian.ID,
+			Kredit:      pembelian.GrandTotal,
+		})
+		for _, pembayaran := range pembelian.PembayaranPembelian {
+			kartu.Entries = append(kartu.Entries, KartuHutangEntry{
+				Tanggal:     pembayaran.TanggalBayar,
+				Jenis:       "pembayaran",
+				Nomor:       pembayaran.NomorPembayaran,
+				Keterangan:  pembayaran.Keterangan,
+				PembelianID: pembelian.ID,
+				Debit:       pembayaran.JumlahBayar,
+			})
+		}
+	}
+
+	sort.SliceStable(kartu.Entries, func(i, j int) bool {
+		return kartu.Entries[i].Tanggal.Before(kartu.Entries[j].Tanggal)
+	})
+
+	var saldo money.Amount
+	for i := range kartu.Entries {
+		saldo += kartu.Entries[i].Kredit - kartu.Entries[i].Debit
+		kartu.Entries[i].Saldo = saldo
+	}
+	kartu.Saldo = saldo
+
+	return kartu, nil
+}
+
+// GetUmurHutang ages the payables outstanding on tanggal by days past the
+// due date. An invoice without TanggalJatuhTempo falls due TermPembayaran
+// days after the invoice date.
+func (s *HutangService) GetUmurHutang(koperasiID uint64, tanggal time.Time) (*LaporanUmurHutang, error) {
+	akhirHari := time.Date(tanggal.Year(), tanggal.Month(), tanggal.Day(), 23, 59, 59, 0, tanggal.Location())
+
+	pembelians, err := s.produkRepo.GetPembelianTerbuka(koperasiID, akhirHari)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pembelian: %v", err)
+	}
+
+	laporan := &LaporanUmurHutang{
+		KoperasiID: koperasiID,
+		Tanggal:    tanggal,
+		Supplier:   []UmurHutangSupplier{},
+	}
+	index := make(map[uint64]int)
+
+	for i := range pembelians {
+		pembelian := &pembelians[i]
+
+		var dibayar money.Amount
+		for _, pembayaran := range pembelian.PembayaranPembelian {
+			if !pembayaran.TanggalBayar.After(akhirHari) {
+				dibayar += pembayaran.JumlahBayar
+			}
+		}
+		sisa := pembelian.GrandTotal - dibayar
+		if sisa <= 0 {
+			continue
+		}
+
+		jatuhTempo := jatuhTempoHutang(pembelian, &pembelian.Supplier)
+		hariLewat := int(tanggalSaja(tanggal).Sub(tanggalSaja(jatuhTempo)).Hours() / 24)
+
+		k, ok := index[pembelian.SupplierID]
+		if !ok {
+			k = len(laporan.Supplier)
+			index[pembelian.SupplierID] = k
+			laporan.Supplier = append(laporan.Supplier, UmurHutangSupplier{
+				SupplierID:     pembelian.SupplierID,
+				Kode:           pembelian.Supplier.Kode,
+				Nama:           pembelian.Supplier.Nama,
+				TermPembayaran: pembelian.Supplier.TermPembayaran,
+			})
+		}
+		baris := &laporan.Supplier[k]
+
+		faktur := UmurHutangFaktur{
+			PembelianHeaderID: pembelian.ID,
+			NomorFaktur:       pembelian.NomorFaktur,
+			TanggalFaktur:     pembelian.TanggalFaktur,
+			JatuhTempo:        jatuhTempo,
+			GrandTotal:        pembelian.GrandTotal,
+			Sisa:              sisa,
+			HariLewat:         hariLewat,
+			Kelompok:          kelompokUmur(hariLewat),
+		}
+		baris.Faktur = append(baris.Faktur, faktur)
+		baris.SaldoUmur.tambah(faktur.Kelompok, sisa)
+		laporan.Total.tambah(faktur.Kelompok, sisa)
+	}
+
+	return laporan, nil
+}
+
+func (s *HutangService) generateNomorPembayaran(koperasiID uint64) (string, error) {
+	number, err := s.sequenceService.GetNextNumber(1, koperasiID, "pembayaran_pembelian")
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("BYR%04d%08d", koperasiID, number), nil
+}
+
+func jatuhTempoHutang(pembelian *postgres.PembelianHeader, supplier *postgres.Supplier) time.Time {
+	if pembelian.TanggalJatuhTempo != nil {
+		return *pembelian.TanggalJatuhTempo
+	}
+	return pembelian.TanggalFaktur.AddDate(0, 0, supplier.TermPembayaran)
+}
+
+func tanggalSaja(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Aging buckets, by days past the due date.
+const (
+	UmurBelumJatuhTempo = "belum_jatuh_tempo"
+	Umur0Sampai30       = "0_30"
+	Umur31Sampai60      = "31_60"
+	Umur61Sampai90      = "61_90"
+	UmurLebih90         = "lebih_90"
+)
+
+func kelompokUmur(hariLewat int) string {
+	switch {
+	case hariLewat < 0:
+		return UmurBelumJatuhTempo
+	case hariLewat <= 30:
+		return Umur0Sampai30
+	case hariLewat <= 60:
+		return Umur31Sampai60
+	case hariLewat <= 90:
+		return Umur61Sampai90
+	default:
+		return UmurLebih90
+	}
+}
+
+type BayarHutangRequest struct {
+	KoperasiID       uint64               `json:"koperasi_id" binding:"required"`
+	SupplierID       uint64               `json:"supplier_id" binding:"required"`
+	TanggalBayar     time.Time            `json:"tanggal_bayar" binding:"required"`
+	MetodePembayaran string               `json:"metode_pembayaran" binding:"required,oneof=cash transfer giro other"`
+	NomorReferensi   string               `json:"nomor_referensi"`
+	Keterangan       string               `json:"keterangan"`
+	Jumlah           money.Amount         `json:"jumlah" binding:"min=0"`
+	Alokasi          []AlokasiBayarHutang `json:"alokasi" binding:"omitempty,dive"`
+	PPh              *PotongPPhRequest    `json:"pph"`
+}
+
+type AlokasiBayarHutang struct {
+	PembelianHeaderID uint64       `json:"pembelian_header_id" binding:"required"`
+	Jumlah            money.Amount `json:"jumlah" binding:"required,gt=0"`
+}
+
+type PembayaranHutang struct {
+	NomorPembayaran string                         `json:"nomor_pembayaran"`
+	SupplierID      uint64                         `json:"supplier_id"`
+	TanggalBayar    time.Time                      `json:"tanggal_bayar"`
+	Total           money.Amount                   `json:"total"`
+	PPh             money.Amount                   `json:"pph"`
+	Dibayar         money.Amount                   `json:"dibayar"`
+	JurnalID        uint64                         `json:"jurnal_id"`
+	Pembayaran      []postgres.PembayaranPembelian `json:"pembayaran"`
+	Potongan        *postgres.PotonganPajak        `json:"potongan,omitempty"`
+}
+
+type KartuHutang struct {
+	SupplierID uint64             `json:"supplier_id"`
+	Kode       string             `json:"kode"`
+	Nama       string             `json:"nama"`
+	Entries    []KartuHutangEntry `json:"entries"`
+	Saldo      money.Amount       `json:"saldo"`
+}
+
+type KartuHutangEntry struct {
+	Tanggal     time.Time    `json:"tanggal"`
+	Jenis       string       `json:"jenis"`
+	Nomor       string       `json:"nomor"`
+	Keterangan  string       `json:"keterangan"`
+	PembelianID uint64       `json:"pembelian_id"`
+	Debit       money.Amount `json:"debit"`
+	Kredit      money.Amount `json:"kredit"`
+	Saldo       money.Amount `json:"saldo"`
+}
+
+type LaporanUmurHutang struct {
+	KoperasiID uint64               `json:"koperasi_id"`
+	Tanggal    time.Time            `json:"tanggal"`
+	Supplier   []UmurHutangSupplier `json:"supplier"`
+	Total      SaldoUmur            `json:"total"`
+}
+
+type UmurHutangSupplier struct {
+	SupplierID     uint64 `json:"supplier_id"`
+	Kode           string `json:"kode"`
+	Nama           string `json:"nama"`
+	TermPembayaran int    `json:"term_pembayaran"`
+	SaldoUmur
+	Faktur []UmurHutangFaktur `json:"faktur"`
+}
+
+// SaldoUmur holds outstanding amounts per aging bucket. It is shared by the
+// payable and receivable aging reports.
+type SaldoUmur struct {
+	BelumJatuhTempo money.Amount `json:"belum_jatuh_tempo"`
+	Hari0Sampai30   money.Amount `json:"hari_0_30"`
+	Hari31Sampai60  money.Amount `json:"hari_31_60"`
+	Hari61Sampai90  money.Amount `json:"hari_61_90"`
+	LebihDari90     money.Amount `json:"lebih_dari_90"`
+	Total           money.Amount `json:"total"`
+}
+
+func (u *SaldoUmur) tambah(kelompok string, jumlah money.Amount) {
+	switch kelompok {
+	case UmurBelumJatuhTempo:
+		u.BelumJatuhTempo += jumlah
+	case Umur0Sampai30:
+		u.Hari0Sampai30 += jumlah
+	case Umur31Sampai60:
+		u.Hari31Sampai60 += jumlah
+	case Umur61Sampai90:
+		u.Hari61Sampai90 += jumlah
+	default:
+		u.LebihDari90 += jumlah
+	}
+	u.Total += jumlah
+}
+
+type UmurHutangFaktur struct {
+	PembelianHeaderID uint64       `json:"pembelian_header_id"`
+	NomorFaktur       string       `json:"nomor_faktur"`
+	TanggalFaktur     time.Time    `json:"tanggal_faktur"`
+	JatuhTempo        time.Time    `json:"jatuh_tempo"`
+	GrandTotal        money.Amount `json:"grand_total"`
+	Sisa              money.Amount `json:"sisa"`
+	HariLewat         int          `json:"hari_lewat"`
+	Kelompok          string       `json:"kelompok"`
+}
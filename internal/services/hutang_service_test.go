@@ -0,0 +1,42 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"koperasi-merah-putih/internal/models/postgres"
+)
+
+func TestKelompokUmur(t *testing.T) {
+	tests := []struct {
+		hariLewat int
+		want      string
+	}{
+		{-1, UmurBelumJatuhTempo},
+		{0, Umur0Sampai30},
+		{30, Umur0Sampai30},
+		{31, Umur31Sampai60},
+		{60, Umur31Sampai60},
+		{61, Umur61Sampai90},
+		{90, Umur61Sampai90},
+		{91, UmurLebih90},
+	}
+	for _, tt := range tests {
+		if got := kelompokUmur(tt.hariLewat); got != tt.want {
+			t.Errorf("kelompokUmur(%d) = %s, want %s", tt.hariLewat, got, tt.want)
+		}
+	}
+}
+
+func TestJatuhTempoHutang(t *testing.T) {
+	faktur := time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)
+	jatuhTempo := time.Date(2025, 2, 5, 0, 0, 0, 0, time.UTC)
+	supplier := &postgres.Supplier{TermPembayaran: 30}
+
+	if got := jatuhTempoHutang(&postgres.PembelianHeader{TanggalFaktur: faktur, TanggalJatuhTempo: &jatuhTempo}, supplier); !got.Equal(jatuhTempo) {
+		t.Errorf("with TanggalJatuhTempo = %v, want %v", got, jatuhTempo)
+	}
+	if got, want := jatuhTempoHutang(&postgres.PembelianHeader{TanggalFaktur: faktur}, supplier), faktur.AddDate(0, 0, 30); !got.Equal(want) {
+		t.Errorf("from TermPembayaran = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,535 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+)
+
+// SumberJurnalTemplate is the SumberTransaksi of journals generated from a
+// template; SumberID is the template ID.
+const SumberJurnalTemplate = "jurnal_template"
+
+// maxCatchUp limits how many missed occurrences one scheduled run generates
+// for a template, e.g. after the service was down for a while.
+const maxCatchUp = 12
+
+// maxGagalBeruntun is how many scheduled runs in a row may fail before a
+// template is deactivated. Between failures the retry waits one hour, then
+// two, four and so on.
+const maxGagalBeruntun = 5
+
+type JurnalTemplateService struct {
+	templateRepo     *postgresRepo.JurnalTemplateRepository
+	financialRepo    *postgresRepo.FinancialRepository
+	financialService *FinancialService
+}
+
+func NewJurnalTemplateService(
+	templateRepo *postgresRepo.JurnalTemplateRepository,
+	financialRepo *postgresRepo.FinancialRepository,
+	financialService *FinancialService,
+) *JurnalTemplateService {
+	return &JurnalTemplateService{
+		templateRepo:     templateRepo,
+		financialRepo:    financialRepo,
+		financialService: financialService,
+	}
+}
+
+func (s *JurnalTemplateService) CreateTemplate(req *JurnalTemplateRequest, createdBy uint64) (*postgres.JurnalTemplate, error) {
+	lines, params, err := s.buildLines(req)
+	if err != nil {
+		return nil, err
+	}
+
+	hari := req.HariTanggal
+	if hari == 0 {
+		hari = req.TanggalMulai.Day()
+	}
+
+	template := &postgres.JurnalTemplate{
+		TenantID:       req.TenantID,
+		KoperasiID:     req.KoperasiID,
+		Nama:           req.Nama,
+		Referensi:      req.Referensi,
+		Keterangan:     req.Keterangan,
+		Frekuensi:      req.Frekuensi,
+		HariTanggal:    hari,
+		TanggalMulai:   req.TanggalMulai,
+		TanggalSelesai: req.TanggalSelesai,
+		BerikutnyaPada: jadwalPertama(req.TanggalMulai, req.Frekuensi, hari),
+		AutoPost:       req.AutoPost,
+		IsAktif:        true,
+		CreatedBy:      createdBy,
+		Lines:          lines,
+		Parameter:      params,
+	}
+
+	err = s.templateRepo.CreateTemplate(template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create jurnal template: %v", err)
+	}
+
+	return template, nil
+}
+
+// UpdateTemplate replaces the template's settings, lines and parameters. For
+// a template that has already run, the next date is recomputed from today so
+// an edit never backfills past periods.
+func (s *JurnalTemplateService) UpdateTemplate(id uint64, req *JurnalTemplateRequest) (*postgres.JurnalTemplate, error) {
+	template, err := s.templateRepo.GetTemplateByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("jurnal template not found: %v", err)
+	}
+
+	if req.KoperasiID != template.KoperasiID {
+		return nil, fmt.Errorf("jurnal template belongs to another koperasi")
+	}
+
+	lines, params, err := s.buildLines(req)
+	if err != nil {
+		return nil, err
+	}
+
+	hari := req.HariTanggal
+	if hari == 0 {
+		hari = req.TanggalMulai.Day()
+	}
+
+	mulai := req.TanggalMulai
+	if template.TerakhirJalan != nil {
+		today := time.Now().Truncate(24 * time.Hour)
+		if today.After(mulai) {
+			mulai = today
+		}
+	}
+
+	updates := map[string]interface{}{
+		"nama":            req.Nama,
+		"referensi":       req.Referensi,
+		"keterangan":      req.Keterangan,
+		"frekuensi":       req.Frekuensi,
+		"hari_tanggal":    hari,
+		"tanggal_mulai":   req.TanggalMulai,
+		"tanggal_selesai": req.TanggalSelesai,
+		"berikutnya_pada": jadwalPertama(mulai, req.Frekuensi, hari),
+		"auto_post":       req.AutoPost,
+		"gagal_beruntun":  0,
+		"coba_lagi_pada":  nil,
+	}
+
+	err = s.templateRepo.Transaction(func(tx *gorm.DB) error {
+		templateRepo := s.templateRepo.WithTx(tx)
+
+		if err := templateRepo.UpdateTemplate(id, updates); err != nil {
+			return fmt.Errorf("failed to update jurnal template: %v", err)
+		}
+
+		return templateRepo.ReplaceLines(id, lines, params)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.templateRepo.GetTemplateByID(id)
+}
+
+func (s *JurnalTemplateService) SetAktif(id uint64, aktif bool) error {
+	_, err := s.templateRepo.GetTemplateByID(id)
+	if err != nil {
+		return fmt.Errorf("jurnal template not found: %v", err)
+	}
+
+	updates := map[string]interface{}{"is_aktif": aktif}
+	if aktif {
+		updates["gagal_beruntun"] = 0
+		updates["coba_lagi_pada"] = nil
+	}
+	return s.templateRepo.UpdateTemplate(id, updates)
+}
+
+// SetParameter stores new values for template parameters, e.g. this month's
+// payroll total, to be used by the next generated journal.
+func (s *JurnalTemplateService) SetParameter(id uint64, nilai map[string]money.Amount) error {
+	template, err := s.templateRepo.GetTemplateByID(id)
+	if err != nil {
+		return fmt.Errorf("jurnal template not found: %v", err)
+	}
+
+	known := make(map[string]bool)
+	for _, param := range template.Parameter {
+		known[param.Nama] = true
+	}
+
+	return s.templateRepo.Transaction(func(tx *gorm.DB) error {
+		templateRepo := s.templateRepo.WithTx(tx)
+		for nama, value := range nilai {
+			if !known[nama] {
+				return fmt.Errorf("unknown parameter %s", nama)
+			}
+			if value < 0 {
+				return fmt.Errorf("parameter %s must not be negative", nama)
+			}
+			if err := templateRepo.UpdateParameterNilai(id, nama, value); err != nil {
+				return fmt.Errorf("failed to update parameter %s: %v", nama, err)
+			}
+		}
+		return templateRepo.UpdateTemplate(id, map[string]interface{}{"coba_lagi_pada": nil})
+	})
+}
+
+func (s *JurnalTemplateService) GetTemplateList(koperasiID uint64) ([]postgres.JurnalTemplate, error) {
+	return s.templateRepo.GetTemplateByKoperasi(koperasiID)
+}
+
+func (s *JurnalTemplateService) GetTemplate(id uint64) (*postgres.JurnalTemplate, error) {
+	return s.templateRepo.GetTemplateByID(id)
+}
+
+// GetRiwayat lists the journals generated from a template.
+func (s *JurnalTemplateService) GetRiwayat(id uint64) ([]postgres.JurnalUmum, error) {
+	return s.financialService.GetJurnalBySumber(SumberJurnalTemplate, id)
+}
+
+// Generate creates the template's next journal now, without waiting for the
+// schedule, and moves the schedule on. nilai overrides parameter values for
+// this journal only.
+func (s *JurnalTemplateService) Generate(id uint64, nilai map[string]money.Amount, createdBy uint64) (*postgres.JurnalUmum, error) {
+	jurnal, err := s.jalankan(id, time.Time{}, nilai, createdBy)
+	if err != nil {
+		s.catatError(id, err)
+		return nil, err
+	}
+	return jurnal, nil
+}
+
+// RunDue generates every journal whose scheduled date has been reached. It is
+// meant to be run periodically by the scheduler and is safe to repeat: each
+// occurrence is generated and the schedule advanced in one transaction. A
+// template that fails is retried with backoff and deactivated after
+// maxGagalBeruntun failures in a row.
+func (s *JurnalTemplateService) RunDue(now time.Time) error {
+	ids, err := s.templateRepo.GetDueTemplateIDs(now)
+	if err != nil {
+		return fmt.Errorf("failed to load due jurnal templates: %v", err)
+	}
+
+	var errs []error
+	for _, id := range ids {
+		for i := 0; i < maxCatchUp; i++ {
+			jurnal, err := s.jalankan(id, now, nil, 0)
+			if err != nil {
+				if gagalErr := s.catatGagal(id, now, err); gagalErr != nil {
+					err = errors.Join(err, gagalErr)
+				}
+				errs = append(errs, fmt.Errorf("template %d: %v", id, err))
+				break
+			}
+			if jurnal == nil {
+				break
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// jalankan generates the occurrence at BerikutnyaPada. With a zero now the
+// occurrence is generated regardless of its date; otherwise nothing happens
+// until it is due. createdBy 0 means the template's creator.
+func (s *JurnalTemplateService) jalankan(id uint64, now time.Time, nilai map[string]money.Amount, createdBy uint64) (*postgres.JurnalUmum, error) {
+	var jurnal *postgres.JurnalUmum
+
+	err := s.templateRepo.Transaction(func(tx *gorm.DB) error {
+		templateRepo := s.templateRepo.WithTx(tx)
+
+		template, err := templateRepo.GetTemplateForUpdate(id)
+		if err != nil {
+			return fmt.Errorf("jurnal template not found: %v", err)
+		}
+		if !template.IsAktif {
+			return fmt.Errorf("jurnal template %s is not active", template.Nama)
+		}
+		if !now.IsZero() && template.BerikutnyaPada.After(now) {
+			return nil
+		}
+		if template.TanggalSelesai != nil && template.BerikutnyaPada.After(*template.TanggalSelesai) {
+			return templateRepo.UpdateTemplate(id, map[string]interface{}{"is_aktif": false})
+		}
+
+		if createdBy == 0 {
+			createdBy = template.CreatedBy
+		}
+
+		jurnal, err = s.buatJurnal(tx, template, nilai, createdBy)
+		if err != nil {
+			return err
+		}
+
+		berikutnya := jadwalBerikutnya(template.BerikutnyaPada, template.Frekuensi, template.HariTanggal)
+		updates := map[string]interface{}{
+			"berikutnya_pada": berikutnya,
+			"terakhir_jalan":  time.Now(),
+			"terakhir_error":  "",
+			"gagal_beruntun":  0,
+			"coba_lagi_pada":  nil,
+		}
+		if template.TanggalSelesai != nil && berikutnya.After(*template.TanggalSelesai) {
+			updates["is_aktif"] = false
+		}
+
+		return templateRepo.UpdateTemplate(id, updates)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return jurnal, nil
+}
+
+func (s *JurnalTemplateService) buatJurnal(tx *gorm.DB, template *postgres.JurnalTemplate, nilai map[string]money.Amount, createdBy uint64) (*postgres.JurnalUmum, error) {
+	params := make(map[string]money.Amount)
+	for _, param := range template.Parameter {
+		params[param.Nama] = param.Nilai
+	}
+	for nama, value := range nilai {
+		if _, ok := params[nama]; !ok {
+			return nil, fmt.Errorf("unknown parameter %s", nama)
+		}
+		params[nama] = value
+	}
+
+	var details []postgres.JurnalDetail
+	for _, line := range template.Lines {
+		jumlah := line.Jumlah
+		if line.Parameter != "" {
+			jumlah = params[line.Parameter]
+		}
+		if jumlah == 0 {
+			continue
+		}
+
+		detail := postgres.JurnalDetail{
+			AkunID:     line.AkunID,
+			Keterangan: line.Keterangan,
+		}
+		if line.Posisi == "debit" {
+			detail.Debit = jumlah
+		} else {
+			detail.Kredit = jumlah
+		}
+		details = append(details, detail)
+	}
+
+	if len(details) < 2 {
+		return nil, fmt.Errorf("jurnal template %s produced no journal lines, check its parameters", template.Nama)
+	}
+
+	tanggal := template.BerikutnyaPada
+	referensi := template.Referensi
+	if referensi == "" {
+		referensi = fmt.Sprintf("TPL-%d-%s", template.ID, tanggal.Format("200601"))
+	}
+	keterangan := template.Keterangan
+	if keterangan == "" {
+		keterangan = template.Nama
+	}
+
+	jurnal := &postgres.JurnalUmum{
+		TenantID:         template.TenantID,
+		KoperasiID:       template.KoperasiID,
+		TanggalTransaksi: tanggal,
+		Referensi:        referensi,
+		Keterangan:       fmt.Sprintf("%s periode %s", keterangan, tanggal.Format("01/2006")),
+		Status:           "draft",
+		SumberTransaksi:  SumberJurnalTemplate,
+		SumberID:         template.ID,
+		CreatedBy:        createdBy,
+	}
+	if template.AutoPost {
+		now := time.Now()
+		jurnal.Status = "posted"
+		jurnal.PostedAt = &now
+		jurnal.PostedBy = createdBy
+	}
+
+	err := s.financialService.saveJurnal(tx, jurnal, details)
+	if err != nil {
+		return nil, err
+	}
+
+	return jurnal, nil
+}
+
+func (s *JurnalTemplateService) catatError(id uint64, err error) {
+	s.templateRepo.UpdateTemplate(id, map[string]interface{}{
+		"terakhir_jalan": time.Now(),
+		"terakhir_error": err.Error(),
+	})
+}
+
+// catatGagal records a failed scheduled run and holds the template back
+// until its retry time, doubling the wait with every failure in a row. At
+// maxGagalBeruntun failures the template is deactivated instead; SetAktif
+// starts it again with a clean count.
+func (s *JurnalTemplateService) catatGagal(id uint64, now time.Time, runErr error) error {
+	return s.templateRepo.Transaction(func(tx *gorm.DB) error {
+		templateRepo := s.templateRepo.WithTx(tx)
+
+		template, err := templateRepo.GetTemplateForUpdate(id)
+		if err != nil {
+			return fmt.Errorf("jurnal template not found: %v", err)
+		}
+
+		gagal := template.GagalBeruntun + 1
+		updates := map[string]interface{}{
+			"terakhir_jalan": time.Now(),
+			"terakhir_error": runErr.Error(),
+			"gagal_beruntun": gagal,
+		}
+		if gagal >= maxGagalBeruntun {
+			updates["is_aktif"] = false
+			updates["coba_lagi_pada"] = nil
+			updates["terakhir_error"] = fmt.Sprintf("deactivated after %d failed runs: %v", gagal, runErr)
+		} else {
+			updates["coba_lagi_pada"] = now.Add(time.Hour << (gagal - 1))
+		}
+
+		return templateRepo.UpdateTemplate(id, updates)
+	})
+}
+
+func (s *JurnalTemplateService) buildLines(req *JurnalTemplateRequest) ([]postgres.JurnalTemplateLine, []postgres.JurnalTemplateParameter, error) {
+	if req.TanggalSelesai != nil && req.TanggalSelesai.Before(req.TanggalMulai) {
+		return nil, nil, fmt.Errorf("tanggal_selesai must not be before tanggal_mulai")
+	}
+
+	var params []postgres.JurnalTemplateParameter
+	declared := make(map[string]bool)
+	for _, param := range req.Parameter {
+		if declared[param.Nama] {
+			return nil, nil, fmt.Errorf("parameter %s is declared twice", param.Nama)
+		}
+		declared[param.Nama] = true
+		params = append(params, postgres.JurnalTemplateParameter{
+			Nama:       param.Nama,
+			Nilai:      param.Nilai,
+			Keterangan: param.Keterangan,
+		})
+	}
+
+	akuns, err := s.financialRepo.GetCOAAkunByKoperasi(req.KoperasiID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load COA: %v", err)
+	}
+	akunAktif := make(map[uint64]bool)
+	for _, akun := range akuns {
+		akunAktif[akun.ID] = true
+	}
+
+	var lines []postgres.JurnalTemplateLine
+	var totalDebit, totalKredit money.Amount
+	adaDebit, adaKredit, adaParameter := false, false, false
+	for i, line := range req.Lines {
+		if !akunAktif[line.AkunID] {
+			return nil, nil, fmt.Errorf("line %d: akun %d not found in koperasi %d", i+1, line.AkunID, req.KoperasiID)
+		}
+
+		if line.Parameter != "" {
+			if !declared[line.Parameter] {
+				return nil, nil, fmt.Errorf("line %d: parameter %s is not declared", i+1, line.Parameter)
+			}
+			adaParameter = true
+		} else if line.Jumlah <= 0 {
+			return nil, nil, fmt.Errorf("line %d: jumlah must be positive when no parameter is used", i+1)
+		}
+
+		if line.Posisi == "debit" {
+			adaDebit = true
+			totalDebit += line.Jumlah
+		} else {
+			adaKredit = true
+			totalKredit += line.Jumlah
+		}
+
+		lines = append(lines, postgres.JurnalTemplateLine{
+			AkunID:     line.AkunID,
+			Posisi:     line.Posisi,
+			Jumlah:     line.Jumlah,
+			Parameter:  line.Parameter,
+			Keterangan: line.Keterangan,
+			Urutan:     i + 1,
+		})
+	}
+
+	if !adaDebit || !adaKredit {
+		return nil, nil, fmt.Errorf("template needs at least one debit and one kredit line")
+	}
+	if !adaParameter && totalDebit != totalKredit {
+		return nil, nil, fmt.Errorf("total debit (%s) must equal total kredit (%s)", totalDebit, totalKredit)
+	}
+
+	return lines, params, nil
+}
+
+// jadwalPertama returns the first scheduled date on or after mulai.
+func jadwalPertama(mulai time.Time, frekuensi string, hari int) time.Time {
+	tanggal := tanggalDalamBulan(mulai.Year(), mulai.Month(), hari, mulai.Location())
+	if tanggal.Before(time.Date(mulai.Year(), mulai.Month(), mulai.Day(), 0, 0, 0, 0, mulai.Location())) {
+		return jadwalBerikutnya(tanggal, frekuensi, hari)
+	}
+	return tanggal
+}
+
+// jadwalBerikutnya moves a scheduled date one or three months on, keeping the
+// day of month and clamping it to the month's last day.
+func jadwalBerikutnya(tanggal time.Time, frekuensi string, hari int) time.Time {
+	bulan := 1
+	if frekuensi == "quarterly" {
+		bulan = 3
+	}
+	return tanggalDalamBulan(tanggal.Year(), tanggal.Month()+time.Month(bulan), hari, tanggal.Location())
+}
+
+func tanggalDalamBulan(tahun int, bulan time.Month, hari int, loc *time.Location) time.Time {
+	awal := time.Date(tahun, bulan, 1, 0, 0, 0, 0, loc)
+	if akhir := awal.AddDate(0, 1, -1).Day(); hari > akhir {
+		hari = akhir
+	}
+	return awal.AddDate(0, 0, hari-1)
+}
+
+type JurnalTemplateRequest struct {
+	TenantID       uint64                           `json:"tenant_id" binding:"required"`
+	KoperasiID     uint64                           `json:"koperasi_id" binding:"required"`
+	Nama           string                           `json:"nama" binding:"required"`
+	Referensi      string                           `json:"referensi"`
+	Keterangan     string                           `json:"keterangan"`
+	Frekuensi      string                           `json:"frekuensi" binding:"required,oneof=monthly quarterly"`
+	HariTanggal    int                              `json:"hari_tanggal" binding:"min=0,max=31"`
+	TanggalMulai   time.Time                        `json:"tanggal_mulai" binding:"required"`
+	TanggalSelesai *time.Time                       `json:"tanggal_selesai"`
+	AutoPost       bool                             `json:"auto_post"`
+	Lines          []JurnalTemplateLineRequest      `json:"lines" binding:"required,min=2,dive"`
+	Parameter      []JurnalTemplateParameterRequest `json:"parameter" binding:"omitempty,dive"`
+}
+
+type JurnalTemplateLineRequest struct {
+	AkunID     uint64       `json:"akun_id" binding:"required"`
+	Posisi     string       `json:"posisi" binding:"required,oneof=debit kredit"`
+	Jumlah     money.Amount `json:"jumlah" binding:"min=0"`
+	Parameter  string       `json:"parameter"`
+	Keterangan string       `json:"keterangan"`
+}
+
+type JurnalTemplateParameterRequest struct {
+	Nama       string       `json:"nama" binding:"required"`
+	Nilai      money.Amount `json:"nilai" binding:"min=0"`
+	Keterangan string       `json:"keterangan"`
+}
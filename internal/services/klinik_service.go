@@ -4,21 +4,26 @@ import (
 	"fmt"
 	"time"
 
+	"gorm.io/gorm"
 	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
 	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
 )
 
 type KlinikService struct {
 	klinikRepo      *postgresRepo.KlinikRepository
+	postingService  *PostingService
 	sequenceService *SequenceService
 }
 
 func NewKlinikService(
 	klinikRepo *postgresRepo.KlinikRepository,
+	postingService *PostingService,
 	sequenceService *SequenceService,
 ) *KlinikService {
 	return &KlinikService{
 		klinikRepo:      klinikRepo,
+		postingService:  postingService,
 		sequenceService: sequenceService,
 	}
 }
@@ -157,6 +162,55 @@ func (s *KlinikService) GetKunjunganByID(id uint64) (*postgres.KlinikKunjungan,
 	return s.klinikRepo.GetKunjunganByID(id)
 }
 
+func (s *KlinikService) BayarKunjungan(id uint64, paidBy uint64) (*postgres.KlinikKunjungan, error) {
+	kunjungan, err := s.klinikRepo.GetKunjunganByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("kunjungan not found: %v", err)
+	}
+
+	if kunjungan.StatusPembayaran == "lunas" {
+		return nil, fmt.Errorf("kunjungan already paid")
+	}
+
+	totalBiaya := kunjungan.BiayaKonsultasi + kunjungan.BiayaTindakan + kunjungan.BiayaObat
+
+	err = s.klinikRepo.Transaction(func(tx *gorm.DB) error {
+		jurnal, err := s.postingService.Post(tx, &PostingRequest{
+			KoperasiID:       kunjungan.KoperasiID,
+			KodeEvent:        PostingEventKlinikPembayaran,
+			TanggalTransaksi: time.Now(),
+			Referensi:        kunjungan.NomorKunjungan,
+			Keterangan:       fmt.Sprintf("Pembayaran kunjungan klinik %s - %s", kunjungan.NomorKunjungan, kunjungan.Pasien.NamaLengkap),
+			SumberTransaksi:  "klinik_kunjungan",
+			SumberID:         kunjungan.ID,
+			AnggotaID:        kunjungan.Pasien.AnggotaID,
+			Komponen: map[string]money.Amount{
+				"total":      totalBiaya,
+				"konsultasi": kunjungan.BiayaKonsultasi,
+				"tindakan":   kunjungan.BiayaTindakan,
+				"obat":       kunjungan.BiayaObat,
+			},
+			CreatedBy: paidBy,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to post jurnal: %v", err)
+		}
+
+		if jurnal != nil {
+			kunjungan.JurnalID = jurnal.ID
+		}
+
+		return s.klinikRepo.WithTx(tx).UpdateKunjunganPembayaran(kunjungan.ID, "lunas", totalBiaya, kunjungan.JurnalID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	kunjungan.TotalBiaya = totalBiaya
+	kunjungan.StatusPembayaran = "lunas"
+	return kunjungan, nil
+}
+
 func (s *KlinikService) GetKunjunganByPasien(pasienID uint64, page, limit int) ([]postgres.KlinikKunjungan, error) {
 	offset := (page - 1) * limit
 	return s.klinikRepo.GetKunjunganByPasien(pasienID, limit, offset)
@@ -215,7 +269,7 @@ func (s *KlinikService) addResepToKunjungan(kunjunganID uint64, resepReqs []Rese
 			return fmt.Errorf("insufficient stock for %s", obat.NamaObat)
 		}
 
-		totalHarga := obat.HargaJual * float64(req.Jumlah)
+		totalHarga := obat.HargaJual.MulInt(int64(req.Jumlah))
 
 		resep := postgres.KlinikResep{
 			KunjunganID: kunjunganID,
@@ -238,15 +292,15 @@ func (s *KlinikService) addResepToKunjungan(kunjunganID uint64, resepReqs []Rese
 	return s.klinikRepo.CreateResep(reseps)
 }
 
-func (s *KlinikService) calculateTotalBiayaObat(resepReqs []ResepRequest) (float64, error) {
-	var total float64
+func (s *KlinikService) calculateTotalBiayaObat(resepReqs []ResepRequest) (money.Amount, error) {
+	var total money.Amount
 
 	for _, req := range resepReqs {
 		obat, err := s.klinikRepo.GetObatByID(req.ObatID)
 		if err != nil {
 			return 0, err
 		}
-		total += obat.HargaJual * float64(req.Jumlah)
+		total += obat.HargaJual.MulInt(int64(req.Jumlah))
 	}
 
 	return total, nil
@@ -296,7 +350,7 @@ type CreateTenagaMedisRequest struct {
 	Telepon         string  `json:"telepon"`
 	Email           string  `json:"email"`
 	JadwalPraktik   string  `json:"jadwal_praktik"`
-	TarifKonsultasi float64 `json:"tarif_konsultasi"`
+	TarifKonsultasi money.Amount `json:"tarif_konsultasi"`
 }
 
 type CreateKunjunganRequest struct {
@@ -308,8 +362,8 @@ type CreateKunjunganRequest struct {
 	PemeriksaanFisik string         `json:"pemeriksaan_fisik"`
 	Diagnosis        string         `json:"diagnosis"`
 	TerapiPengobatan string         `json:"terapi_pengobatan"`
-	BiayaKonsultasi  float64        `json:"biaya_konsultasi"`
-	BiayaTindakan    float64        `json:"biaya_tindakan"`
+	BiayaKonsultasi  money.Amount   `json:"biaya_konsultasi"`
+	BiayaTindakan    money.Amount   `json:"biaya_tindakan"`
 	Reseps           []ResepRequest `json:"reseps"`
 }
 
@@ -330,6 +384,6 @@ type CreateObatRequest struct {
 	Satuan        string  `json:"satuan"`
 	StokMinimal   int     `json:"stok_minimal"`
 	StokCurrent   int     `json:"stok_current"`
-	HargaBeli     float64 `json:"harga_beli"`
-	HargaJual     float64 `json:"harga_jual"`
+	HargaBeli     money.Amount `json:"harga_beli"`
+	HargaJual     money.Amount `json:"harga_jual"`
 }
\ No newline at end of file
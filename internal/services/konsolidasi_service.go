@@ -0,0 +1,686 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+)
+
+// Kategori in the order statements list them.
+var urutanTipeKonsolidasi = []string{"aset", "kewajiban", "ekuitas", "pendapatan", "beban"}
+
+// Line that takes up inter-koperasi balances that do not cancel out, so the
+// consolidated trial balance still balances.
+const (
+	kodeSelisihEliminasi = "ELIMINASI"
+	namaSelisihEliminasi = "Selisih eliminasi antar koperasi"
+)
+
+// KonsolidasiService combines the books of several koperasi of one tenant
+// into one set of figures. Each koperasi akun is placed on a line of the
+// tenant's common reporting structure and balances between the chosen
+// koperasi are eliminated.
+type KonsolidasiService struct {
+	konsolidasiRepo *postgresRepo.KonsolidasiRepository
+	financialRepo   *postgresRepo.FinancialRepository
+	koperasiRepo    *postgresRepo.KoperasiRepository
+}
+
+func NewKonsolidasiService(
+	konsolidasiRepo *postgresRepo.KonsolidasiRepository,
+	financialRepo *postgresRepo.FinancialRepository,
+	koperasiRepo *postgresRepo.KoperasiRepository,
+) *KonsolidasiService {
+	return &KonsolidasiService{
+		konsolidasiRepo: konsolidasiRepo,
+		financialRepo:   financialRepo,
+		koperasiRepo:    koperasiRepo,
+	}
+}
+
+func (s *KonsolidasiService) GetPosList(tenantID uint64) ([]postgres.PosKonsolidasi, error) {
+	return s.konsolidasiRepo.GetPosByTenant(tenantID)
+}
+
+func (s *KonsolidasiService) CreatePos(tenantID uint64, req *PosKonsolidasiRequest) (*postgres.PosKonsolidasi, error) {
+	if saldoNormalKategori(req.Tipe) == "" {
+		return nil, fmt.Errorf("invalid tipe: %s", req.Tipe)
+	}
+
+	pos := &postgres.PosKonsolidasi{
+		TenantID: tenantID,
+		Kode:     req.Kode,
+		Nama:     req.Nama,
+		Tipe:     req.Tipe,
+	}
+
+	if err := s.konsolidasiRepo.CreatePos(pos); err != nil {
+		return nil, fmt.Errorf("failed to create pos konsolidasi: %v", err)
+	}
+
+	return pos, nil
+}
+
+func (s *KonsolidasiService) UpdatePos(id, tenantID uint64, req *PosKonsolidasiRequest) (*postgres.PosKonsolidasi, error) {
+	pos, err := s.getPos(id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if saldoNormalKategori(req.Tipe) == "" {
+		return nil, fmt.Errorf("invalid tipe: %s", req.Tipe)
+	}
+	if req.Tipe != pos.Tipe {
+		count, err := s.konsolidasiRepo.CountPemetaanByPos(id)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, fmt.Errorf("tipe cannot change while akun are mapped to the pos")
+		}
+	}
+
+	pos.Kode = req.Kode
+	pos.Nama = req.Nama
+	pos.Tipe = req.Tipe
+
+	if err := s.konsolidasiRepo.UpdatePos(pos); err != nil {
+		return nil, fmt.Errorf("failed to update pos konsolidasi: %v", err)
+	}
+
+	return pos, nil
+}
+
+func (s *KonsolidasiService) DeletePos(id, tenantID uint64) error {
+	if _, err := s.getPos(id, tenantID); err != nil {
+		return err
+	}
+
+	count, err := s.konsolidasiRepo.CountPemetaanByPos(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("pos konsolidasi is still mapped to %d akun", count)
+	}
+
+	return s.konsolidasiRepo.DeletePos(id)
+}
+
+func (s *KonsolidasiService) getPos(id, tenantID uint64) (*postgres.PosKonsolidasi, error) {
+	pos, err := s.konsolidasiRepo.GetPosByID(id)
+	if err != nil || pos.TenantID != tenantID {
+		return nil, fmt.Errorf("pos konsolidasi not found")
+	}
+	return pos, nil
+}
+
+// SimpanPemetaan maps one akun to a pos and marks whether it holds balances
+// with another koperasi. Clearing both removes the mapping, returning the
+// akun to the mapping by kode.
+func (s *KonsolidasiService) SimpanPemetaan(tenantID uint64, req *PemetaanAkunRequest) (*postgres.PemetaanAkunKonsolidasi, error) {
+	akun, err := s.financialRepo.GetCOAAkunByID(req.AkunID)
+	if err != nil {
+		return nil, fmt.Errorf("akun not found: %v", err)
+	}
+
+	koperasi, err := s.koperasiRepo.GetByID(akun.KoperasiID)
+	if err != nil || koperasi.TenantID != tenantID {
+		return nil, fmt.Errorf("akun not found")
+	}
+
+	if req.PosKonsolidasiID == 0 && req.LawanKoperasiID == 0 {
+		return nil, s.konsolidasiRepo.DeletePemetaan(akun.ID)
+	}
+
+	if req.PosKonsolidasiID != 0 {
+		pos, err := s.getPos(req.PosKonsolidasiID, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		if pos.Tipe != akun.Kategori.Tipe {
+			return nil, fmt.Errorf("akun %s is %s and cannot be reported on %s pos %s",
+				akun.KodeAkun, akun.Kategori.Tipe, pos.Tipe, pos.Kode)
+		}
+	}
+
+	if req.LawanKoperasiID != 0 {
+		if req.LawanKoperasiID == koperasi.ID {
+			return nil, fmt.Errorf("lawan koperasi must be another koperasi")
+		}
+		lawan, err := s.koperasiRepo.GetByID(req.LawanKoperasiID)
+		if err != nil || lawan.TenantID != tenantID {
+			return nil, fmt.Errorf("lawan koperasi not found")
+		}
+	}
+
+	pemetaan, err := s.konsolidasiRepo.GetPemetaanByAkun(akun.ID)
+	if err != nil {
+		pemetaan = &postgres.PemetaanAkunKonsolidasi{
+			TenantID:   tenantID,
+			KoperasiID: koperasi.ID,
+			AkunID:     akun.ID,
+		}
+	}
+	pemetaan.PosKonsolidasiID = req.PosKonsolidasiID
+	pemetaan.LawanKoperasiID = req.LawanKoperasiID
+
+	if err := s.konsolidasiRepo.SavePemetaan(pemetaan); err != nil {
+		return nil, fmt.Errorf("failed to save pemetaan: %v", err)
+	}
+
+	return pemetaan, nil
+}
+
+func (s *KonsolidasiService) GetPemetaan(tenantID, koperasiID uint64) ([]postgres.PemetaanAkunKonsolidasi, error) {
+	koperasi, err := s.koperasiRepo.GetByID(koperasiID)
+	if err != nil || koperasi.TenantID != tenantID {
+		return nil, fmt.Errorf("koperasi not found")
+	}
+	return s.konsolidasiRepo.GetPemetaanByKoperasi([]uint64{koperasiID})
+}
+
+// GetNeracaSaldo consolidates the trial balances at the end of tanggal of the
+// chosen koperasi, or of every koperasi of the tenant when none are chosen.
+func (s *KonsolidasiService) GetNeracaSaldo(tenantID uint64, koperasiIDs []uint64, tanggal time.Time) (*NeracaSaldoKonsolidasi, error) {
+	koperasis, peta, err := s.persiapan(tenantID, koperasiIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	akhir := tanggal.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	mutasi := make([][]postgresRepo.MutasiAkun, len(koperasis))
+	for i, k := range koperasis {
+		mutasi[i], err = s.financialRepo.GetMutasiAkun(k.ID, time.Time{}, akhir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	hasil := konsolidasikan(koperasis, peta, mutasi, true)
+
+	laporan := &NeracaSaldoKonsolidasi{
+		Tanggal:          tanggal,
+		Koperasi:         daftarKoperasiKonsolidasi(koperasis),
+		Baris:            hasil.baris,
+		SelisihEliminasi: hasil.selisih,
+		AkunTanpaPos:     hasil.tanpaPos,
+	}
+	for _, b := range hasil.baris {
+		net := b.Konsolidasi
+		if saldoNormalKategori(b.Tipe) == "kredit" {
+			net = -net
+		}
+		if net > 0 {
+			laporan.TotalDebit += net
+		} else {
+			laporan.TotalKredit -= net
+		}
+	}
+
+	return laporan, nil
+}
+
+// GetNeraca consolidates the balance sheet totals at tanggal. As with the
+// single koperasi neraca, ekuitas excludes the result not yet closed, which
+// is shown separately as SHUBelumDibagi.
+func (s *KonsolidasiService) GetNeraca(tenantID uint64, koperasiIDs []uint64, tanggal time.Time) (*NeracaKonsolidasi, error) {
+	neracaSaldo, err := s.GetNeracaSaldo(tenantID, koperasiIDs, tanggal)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(neracaSaldo.Koperasi)
+	neraca := &NeracaKonsolidasi{
+		Tanggal:          tanggal,
+		Koperasi:         neracaSaldo.Koperasi,
+		TotalAset:        newJumlahKonsolidasi(n),
+		TotalKewajiban:   newJumlahKonsolidasi(n),
+		TotalEkuitas:     newJumlahKonsolidasi(n),
+		SHUBelumDibagi:   newJumlahKonsolidasi(n),
+		SelisihEliminasi: neracaSaldo.SelisihEliminasi,
+		AkunTanpaPos:     neracaSaldo.AkunTanpaPos,
+	}
+
+	for _, b := range neracaSaldo.Baris {
+		switch b.Tipe {
+		case "aset":
+			neraca.Aset = append(neraca.Aset, b)
+			neraca.TotalAset.tambah(b, 1)
+		case "kewajiban":
+			neraca.Kewajiban = append(neraca.Kewajiban, b)
+			neraca.TotalKewajiban.tambah(b, 1)
+		case "ekuitas":
+			neraca.Ekuitas = append(neraca.Ekuitas, b)
+			neraca.TotalEkuitas.tambah(b, 1)
+		case "pendapatan":
+			neraca.SHUBelumDibagi.tambah(b, 1)
+		case "beban":
+			neraca.SHUBelumDibagi.tambah(b, -1)
+		}
+	}
+
+	return neraca, nil
+}
+
+// GetLabaRugi consolidates pendapatan and beban from dari through the whole
+// day sampai, leaving out the year-end close like the single koperasi report.
+func (s *KonsolidasiService) GetLabaRugi(tenantID uint64, koperasiIDs []uint64, dari, sampai time.Time) (*LabaRugiKonsolidasi, error) {
+	koperasis, peta, err := s.persiapan(tenantID, koperasiIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	akhir := sampai.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	mutasi := make([][]postgresRepo.MutasiAkun, len(koperasis))
+	for i, k := range koperasis {
+		items, err := s.financialRepo.GetMutasiHasilUsaha(k.ID, dari, akhir)
+		if err != nil {
+			return nil, err
+		}
+		mutasi[i] = gabungMutasiHasilUsaha(items)
+	}
+
+	hasil := konsolidasikan(koperasis, peta, mutasi, false)
+
+	n := len(koperasis)
+	labaRugi := &LabaRugiKonsolidasi{
+		Dari:             dari,
+		Sampai:           sampai,
+		Koperasi:         daftarKoperasiKonsolidasi(koperasis),
+		TotalPendapatan:  newJumlahKonsolidasi(n),
+		TotalBeban:       newJumlahKonsolidasi(n),
+		LabaRugi:         newJumlahKonsolidasi(n),
+		SelisihEliminasi: hasil.selisih,
+		AkunTanpaPos:     hasil.tanpaPos,
+	}
+
+	for _, b := range hasil.baris {
+		switch b.Tipe {
+		case "pendapatan":
+			labaRugi.Pendapatan = append(labaRugi.Pendapatan, b)
+			labaRugi.TotalPendapatan.tambah(b, 1)
+			labaRugi.LabaRugi.tambah(b, 1)
+		case "beban":
+			labaRugi.Beban = append(labaRugi.Beban, b)
+			labaRugi.TotalBeban.tambah(b, 1)
+			labaRugi.LabaRugi.tambah(b, -1)
+		}
+	}
+
+	return labaRugi, nil
+}
+
+// persiapan resolves the koperasi to consolidate and loads how their akun
+// map onto the reporting structure.
+func (s *KonsolidasiService) persiapan(tenantID uint64, koperasiIDs []uint64) ([]postgres.Koperasi, *petaKonsolidasi, error) {
+	semua, err := s.koperasiRepo.GetByTenantID(tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var koperasis []postgres.Koperasi
+	if len(koperasiIDs) == 0 {
+		koperasis = semua
+	} else {
+		milikTenant := make(map[uint64]postgres.Koperasi, len(semua))
+		for _, k := range semua {
+			milikTenant[k.ID] = k
+		}
+		dipilih := make(map[uint64]bool, len(koperasiIDs))
+		for _, id := range koperasiIDs {
+			k, ok := milikTenant[id]
+			if !ok {
+				return nil, nil, fmt.Errorf("koperasi %d does not belong to the tenant", id)
+			}
+			if !dipilih[id] {
+				dipilih[id] = true
+				koperasis = append(koperasis, k)
+			}
+		}
+	}
+	if len(koperasis) == 0 {
+		return nil, nil, fmt.Errorf("no koperasi to consolidate")
+	}
+	sort.Slice(koperasis, func(i, j int) bool { return koperasis[i].ID < koperasis[j].ID })
+
+	peta := &petaKonsolidasi{
+		posID:    make(map[uint64]postgres.PosKonsolidasi),
+		posKode:  make(map[string]postgres.PosKonsolidasi),
+		pemetaan: make(map[uint64]postgres.PemetaanAkunKonsolidasi),
+		akun:     make(map[uint64]postgres.COAAkun),
+	}
+
+	pos, err := s.konsolidasiRepo.GetPosByTenant(tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, p := range pos {
+		peta.posID[p.ID] = p
+		peta.posKode[p.Kode] = p
+	}
+
+	ids := make([]uint64, len(koperasis))
+	for i, k := range koperasis {
+		ids[i] = k.ID
+		akuns, err := s.financialRepo.GetCOAAkunByKoperasi(k.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, a := range akuns {
+			peta.akun[a.ID] = a
+		}
+	}
+
+	pemetaan, err := s.konsolidasiRepo.GetPemetaanByKoperasi(ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, p := range pemetaan {
+		peta.pemetaan[p.AkunID] = p
+	}
+
+	return koperasis, peta, nil
+}
+
+// petaKonsolidasi places koperasi akun on the lines of the reporting
+// structure.
+type petaKonsolidasi struct {
+	posID    map[uint64]postgres.PosKonsolidasi
+	posKode  map[string]postgres.PosKonsolidasi
+	pemetaan map[uint64]postgres.PemetaanAkunKonsolidasi
+	akun     map[uint64]postgres.COAAkun
+}
+
+// letak returns the line an akun is reported on: its explicit mapping, else
+// the pos with the kode of the akun or its nearest parent. Akun with no pos
+// are reported under their own kode, so koperasi set up from the same
+// template still line up; found is false for them.
+func (p *petaKonsolidasi) letak(m postgresRepo.MutasiAkun) (kode, nama string, found bool) {
+	if pm, ok := p.pemetaan[m.AkunID]; ok && pm.PosKonsolidasiID != 0 {
+		if pos, ok := p.posID[pm.PosKonsolidasiID]; ok {
+			return pos.Kode, pos.Nama, true
+		}
+	}
+
+	kodeAkun, id := m.KodeAkun, m.AkunID
+	for depth := 0; depth < 10; depth++ {
+		if pos, ok := p.posKode[kodeAkun]; ok && pos.Tipe == m.KategoriTipe {
+			return pos.Kode, pos.Nama, true
+		}
+		akun, ok := p.akun[id]
+		if !ok || akun.ParentID == 0 {
+			break
+		}
+		parent, ok := p.akun[akun.ParentID]
+		if !ok {
+			break
+		}
+		kodeAkun, id = parent.KodeAkun, parent.ID
+	}
+
+	return m.KodeAkun, m.NamaAkun, false
+}
+
+func (p *petaKonsolidasi) lawan(akunID uint64) uint64 {
+	return p.pemetaan[akunID].LawanKoperasiID
+}
+
+type hasilKonsolidasi struct {
+	baris    []*BarisKonsolidasi
+	selisih  []SelisihEliminasi
+	tanpaPos []RincianKonsolidasi
+}
+
+// konsolidasikan adds up the akun balances of each koperasi, given in the
+// same order as koperasis, per line. Balances on akun held against another
+// chosen koperasi are eliminated in full; what a pair of koperasi does not
+// cancel out is reported and, when seimbangkan is set, put on a separate
+// ekuitas line so the result still balances.
+func konsolidasikan(koperasis []postgres.Koperasi, peta *petaKonsolidasi, mutasi [][]postgresRepo.MutasiAkun, seimbangkan bool) *hasilKonsolidasi {
+	dipilih := make(map[uint64]bool, len(koperasis))
+	for _, k := range koperasis {
+		dipilih[k.ID] = true
+	}
+
+	hasil := &hasilKonsolidasi{}
+	index := make(map[string]*BarisKonsolidasi)
+	baris := func(kode, nama, tipe string) *BarisKonsolidasi {
+		key := tipe + "|" + kode
+		b, ok := index[key]
+		if !ok {
+			b = &BarisKonsolidasi{
+				Kode:        kode,
+				Nama:        nama,
+				Tipe:        tipe,
+				PerKoperasi: make([]money.Amount, len(koperasis)),
+			}
+			index[key] = b
+			hasil.baris = append(hasil.baris, b)
+		}
+		return b
+	}
+
+	// Net debit left by eliminations, per pair of koperasi.
+	pasangan := make(map[[2]uint64]money.Amount)
+
+	for i, k := range koperasis {
+		for _, m := range mutasi[i] {
+			if m.TotalDebit == m.TotalKredit {
+				continue
+			}
+
+			kode, nama, found := peta.letak(m)
+			b := baris(kode, nama, m.KategoriTipe)
+
+			saldo := saldoKategori(m.KategoriTipe, m.TotalDebit, m.TotalKredit)
+			rincian := RincianKonsolidasi{
+				KoperasiID: k.ID,
+				AkunID:     m.AkunID,
+				KodeAkun:   m.KodeAkun,
+				NamaAkun:   m.NamaAkun,
+				Saldo:      saldo,
+			}
+
+			if lawan := peta.lawan(m.AkunID); lawan != 0 && lawan != k.ID && dipilih[lawan] {
+				rincian.LawanKoperasiID = lawan
+				rincian.Eliminasi = -saldo
+				key := [2]uint64{k.ID, lawan}
+				if lawan < k.ID {
+					key = [2]uint64{lawan, k.ID}
+				}
+				pasangan[key] += m.TotalDebit - m.TotalKredit
+			}
+
+			b.PerKoperasi[i] += saldo
+			b.Jumlah += saldo
+			b.Eliminasi += rincian.Eliminasi
+			b.Rincian = append(b.Rincian, rincian)
+
+			if !found && len(peta.posID) > 0 {
+				hasil.tanpaPos = append(hasil.tanpaPos, rincian)
+			}
+		}
+	}
+
+	keys := make([][2]uint64, 0, len(pasangan))
+	for key, net := range pasangan {
+		if net != 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	for _, key := range keys {
+		net := pasangan[key]
+		hasil.selisih = append(hasil.selisih, SelisihEliminasi{
+			KoperasiID:      key[0],
+			LawanKoperasiID: key[1],
+			Selisih:         net,
+		})
+		if seimbangkan {
+			// The eliminations took net debit out; put it back as ekuitas,
+			// where a debit is negative.
+			baris(kodeSelisihEliminasi, namaSelisihEliminasi, "ekuitas").Eliminasi -= net
+		}
+	}
+
+	urutan := make(map[string]int, len(urutanTipeKonsolidasi))
+	for i, t := range urutanTipeKonsolidasi {
+		urutan[t] = i
+	}
+	sort.SliceStable(hasil.baris, func(i, j int) bool {
+		a, b := hasil.baris[i], hasil.baris[j]
+		if urutan[a.Tipe] != urutan[b.Tipe] {
+			return urutan[a.Tipe] < urutan[b.Tipe]
+		}
+		return a.Kode < b.Kode
+	})
+	for _, b := range hasil.baris {
+		b.Konsolidasi = b.Jumlah + b.Eliminasi
+	}
+
+	return hasil
+}
+
+// gabungMutasiHasilUsaha adds the member and non-member rows of each akun
+// together.
+func gabungMutasiHasilUsaha(items []postgresRepo.MutasiHasilUsaha) []postgresRepo.MutasiAkun {
+	var result []postgresRepo.MutasiAkun
+	index := make(map[uint64]int)
+	for _, item := range items {
+		i, ok := index[item.AkunID]
+		if !ok {
+			index[item.AkunID] = len(result)
+			result = append(result, item.MutasiAkun)
+			continue
+		}
+		result[i].TotalDebit += item.TotalDebit
+		result[i].TotalKredit += item.TotalKredit
+	}
+	return result
+}
+
+func daftarKoperasiKonsolidasi(koperasis []postgres.Koperasi) []KoperasiKonsolidasi {
+	result := make([]KoperasiKonsolidasi, len(koperasis))
+	for i, k := range koperasis {
+		result[i] = KoperasiKonsolidasi{KoperasiID: k.ID, NamaKoperasi: k.NamaKoperasi}
+	}
+	return result
+}
+
+func newJumlahKonsolidasi(n int) *JumlahKonsolidasi {
+	return &JumlahKonsolidasi{PerKoperasi: make([]money.Amount, n)}
+}
+
+// tambah adds the line to the total, or subtracts it when sign is -1.
+func (j *JumlahKonsolidasi) tambah(b *BarisKonsolidasi, sign int64) {
+	for i, v := range b.PerKoperasi {
+		j.PerKoperasi[i] += v.MulInt(sign)
+	}
+	j.Jumlah += b.Jumlah.MulInt(sign)
+	j.Eliminasi += b.Eliminasi.MulInt(sign)
+	j.Konsolidasi += b.Konsolidasi.MulInt(sign)
+}
+
+type PosKonsolidasiRequest struct {
+	Kode string `json:"kode" binding:"required"`
+	Nama string `json:"nama" binding:"required"`
+	Tipe string `json:"tipe" binding:"required"`
+}
+
+type PemetaanAkunRequest struct {
+	AkunID           uint64 `json:"akun_id" binding:"required"`
+	PosKonsolidasiID uint64 `json:"pos_konsolidasi_id"`
+	LawanKoperasiID  uint64 `json:"lawan_koperasi_id"`
+}
+
+type KoperasiKonsolidasi struct {
+	KoperasiID   uint64 `json:"koperasi_id"`
+	NamaKoperasi string `json:"nama_koperasi"`
+}
+
+// BarisKonsolidasi is one line of a consolidated report. PerKoperasi follows
+// the order of the report's Koperasi list; amounts are signed by the side
+// the tipe normally sits on. Rincian lists the akun behind the line.
+type BarisKonsolidasi struct {
+	Kode        string               `json:"kode"`
+	Nama        string               `json:"nama"`
+	Tipe        string               `json:"tipe"`
+	PerKoperasi []money.Amount       `json:"per_koperasi"`
+	Jumlah      money.Amount         `json:"jumlah"`
+	Eliminasi   money.Amount         `json:"eliminasi"`
+	Konsolidasi money.Amount         `json:"konsolidasi"`
+	Rincian     []RincianKonsolidasi `json:"rincian,omitempty"`
+}
+
+type RincianKonsolidasi struct {
+	KoperasiID      uint64       `json:"koperasi_id"`
+	AkunID          uint64       `json:"akun_id"`
+	KodeAkun        string       `json:"kode_akun"`
+	NamaAkun        string       `json:"nama_akun"`
+	Saldo           money.Amount `json:"saldo"`
+	LawanKoperasiID uint64       `json:"lawan_koperasi_id,omitempty"`
+	Eliminasi       money.Amount `json:"eliminasi"`
+}
+
+type JumlahKonsolidasi struct {
+	PerKoperasi []money.Amount `json:"per_koperasi"`
+	Jumlah      money.Amount   `json:"jumlah"`
+	Eliminasi   money.Amount   `json:"eliminasi"`
+	Konsolidasi money.Amount   `json:"konsolidasi"`
+}
+
+// SelisihEliminasi is what the inter-koperasi akun of a pair leave after
+// elimination, as net debit. It is zero when both sides booked the same
+// amounts.
+type SelisihEliminasi struct {
+	KoperasiID      uint64       `json:"koperasi_id"`
+	LawanKoperasiID uint64       `json:"lawan_koperasi_id"`
+	Selisih         money.Amount `json:"selisih"`
+}
+
+type NeracaSaldoKonsolidasi struct {
+	Tanggal          time.Time             `json:"tanggal"`
+	Koperasi         []KoperasiKonsolidasi `json:"koperasi"`
+	Baris            []*BarisKonsolidasi   `json:"baris"`
+	TotalDebit       money.Amount          `json:"total_debit"`
+	TotalKredit      money.Amount          `json:"total_kredit"`
+	SelisihEliminasi []SelisihEliminasi    `json:"selisih_eliminasi"`
+	AkunTanpaPos     []RincianKonsolidasi  `json:"akun_tanpa_pos"`
+}
+
+type NeracaKonsolidasi struct {
+	Tanggal          time.Time             `json:"tanggal"`
+	Koperasi         []KoperasiKonsolidasi `json:"koperasi"`
+	Aset             []*BarisKonsolidasi   `json:"aset"`
+	Kewajiban        []*BarisKonsolidasi   `json:"kewajiban"`
+	Ekuitas          []*BarisKonsolidasi   `json:"ekuitas"`
+	TotalAset        *JumlahKonsolidasi    `json:"total_aset"`
+	TotalKewajiban   *JumlahKonsolidasi    `json:"total_kewajiban"`
+	TotalEkuitas     *JumlahKonsolidasi    `json:"total_ekuitas"`
+	SHUBelumDibagi   *JumlahKonsolidasi    `json:"shu_belum_dibagi"`
+	SelisihEliminasi []SelisihEliminasi    `json:"selisih_eliminasi"`
+	AkunTanpaPos     []RincianKonsolidasi  `json:"akun_tanpa_pos"`
+}
+
+type LabaRugiKonsolidasi struct {
+	Dari             time.Time             `json:"dari"`
+	Sampai           time.Time             `json:"sampai"`
+	Koperasi         []KoperasiKonsolidasi `json:"koperasi"`
+	Pendapatan       []*BarisKonsolidasi   `json:"pendapatan"`
+	Beban            []*BarisKonsolidasi   `json:"beban"`
+	TotalPendapatan  *JumlahKonsolidasi    `json:"total_pendapatan"`
+	TotalBeban       *JumlahKonsolidasi    `json:"total_beban"`
+	LabaRugi         *JumlahKonsolidasi    `json:"laba_rugi"`
+	SelisihEliminasi []SelisihEliminasi    `json:"selisih_eliminasi"`
+	AkunTanpaPos     []RincianKonsolidasi  `json:"akun_tanpa_pos"`
+}
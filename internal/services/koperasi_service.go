@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"gorm.io/gorm"
 	"koperasi-merah-putih/internal/models/postgres"
 	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
 )
@@ -13,6 +14,7 @@ type KoperasiService struct {
 	anggotaRepo  *postgresRepo.AnggotaKoperasiRepository
 	wilayahRepo  *postgresRepo.WilayahRepository
 	sequenceService *SequenceService
+	coaService      *COAService
 }
 
 func NewKoperasiService(
@@ -20,12 +22,14 @@ func NewKoperasiService(
 	anggotaRepo *postgresRepo.AnggotaKoperasiRepository,
 	wilayahRepo *postgresRepo.WilayahRepository,
 	sequenceService *SequenceService,
+	coaService *COAService,
 ) *KoperasiService {
 	return &KoperasiService{
 		koperasiRepo: koperasiRepo,
 		anggotaRepo:  anggotaRepo,
 		wilayahRepo:  wilayahRepo,
 		sequenceService: sequenceService,
+		coaService:      coaService,
 	}
 }
 
@@ -66,9 +70,20 @@ func (s *KoperasiService) CreateKoperasi(req *CreateKoperasiRequest) (*postgres.
 		CreatedBy:            req.CreatedBy,
 	}
 
-	err := s.koperasiRepo.Create(koperasi)
+	err := s.koperasiRepo.Transaction(func(tx *gorm.DB) error {
+		if err := s.koperasiRepo.WithTx(tx).Create(koperasi); err != nil {
+			return fmt.Errorf("failed to create koperasi: %v", err)
+		}
+
+		if req.TemplateCOA != "" {
+			if _, err := s.coaService.applyTemplate(tx, koperasi, req.TemplateCOA); err != nil {
+				return fmt.Errorf("failed to apply COA template: %v", err)
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create koperasi: %v", err)
+		return nil, err
 	}
 
 	return koperasi, nil
@@ -244,6 +259,7 @@ type CreateKoperasiRequest struct {
 	TanggalBerdiri       *time.Time `json:"tanggal_berdiri"`
 	TanggalSK            *time.Time `json:"tanggal_sk"`
 	TanggalPengesahan    *time.Time `json:"tanggal_pengesahan"`
+	TemplateCOA          string     `json:"template_coa" binding:"omitempty,oneof=ksp konsumen produsen serba_usaha"`
 	CreatedBy            uint64     `json:"created_by"`
 }
 
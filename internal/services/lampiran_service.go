@@ -0,0 +1,245 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"koperasi-merah-putih/internal/models/postgres"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+	"koperasi-merah-putih/internal/storage"
+	"koperasi-merah-putih/internal/utils"
+)
+
+// lampiranContentTypes maps the accepted file extensions to the content type
+// the file is served with and the types http.DetectContentType may report
+// for a genuine file of that kind. Legacy Office files have no signature the
+// sniffer knows, and the newer ones are zip archives.
+var lampiranContentTypes = map[string]struct {
+	contentType string
+	detected    []string
+}{
+	"jpg":  {"image/jpeg", []string{"image/jpeg"}},
+	"jpeg": {"image/jpeg", []string{"image/jpeg"}},
+	"png":  {"image/png", []string{"image/png"}},
+	"gif":  {"image/gif", []string{"image/gif"}},
+	"bmp":  {"image/bmp", []string{"image/bmp"}},
+	"webp": {"image/webp", []string{"image/webp"}},
+	"pdf":  {"application/pdf", []string{"application/pdf"}},
+	"doc":  {"application/msword", []string{"application/octet-stream"}},
+	"xls":  {"application/vnd.ms-excel", []string{"application/octet-stream"}},
+	"ppt":  {"application/vnd.ms-powerpoint", []string{"application/octet-stream"}},
+	"docx": {"application/vnd.openxmlformats-officedocument.wordprocessingml.document", []string{"application/zip"}},
+	"xlsx": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", []string{"application/zip"}},
+	"pptx": {"application/vnd.openxmlformats-officedocument.presentationml.presentation", []string{"application/zip"}},
+	"txt":  {"text/plain; charset=utf-8", []string{"text/plain"}},
+}
+
+type LampiranService struct {
+	lampiranRepo     *postgresRepo.LampiranRepository
+	financialRepo    *postgresRepo.FinancialRepository
+	produkRepo       *postgresRepo.ProdukRepository
+	simpanPinjamRepo *postgresRepo.SimpanPinjamRepository
+	storage          storage.Storage
+	maxFileSize      int64
+}
+
+func NewLampiranService(
+	lampiranRepo *postgresRepo.LampiranRepository,
+	financialRepo *postgresRepo.FinancialRepository,
+	produkRepo *postgresRepo.ProdukRepository,
+	simpanPinjamRepo *postgresRepo.SimpanPinjamRepository,
+	storage storage.Storage,
+	maxFileSize int64,
+) *LampiranService {
+	return &LampiranService{
+		lampiranRepo:     lampiranRepo,
+		financialRepo:    financialRepo,
+		produkRepo:       produkRepo,
+		simpanPinjamRepo: simpanPinjamRepo,
+		storage:          storage,
+		maxFileSize:      maxFileSize,
+	}
+}
+
+// MaxFileSize is the largest attachment accepted, in bytes.
+func (s *LampiranService) MaxFileSize() int64 {
+	return s.maxFileSize
+}
+
+// UploadLampiran stores file and attaches it to the document. Only images
+// and office documents are accepted, and the content has to match the file
+// extension.
+func (s *LampiranService) UploadLampiran(req *UploadLampiranRequest, file io.Reader, createdBy uint64) (*postgres.Lampiran, error) {
+	koperasiID, err := s.getDokumenKoperasi(req.JenisDokumen, req.DokumenID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !utils.IsImageFile(req.NamaFile) && !utils.IsDocumentFile(req.NamaFile) {
+		return nil, fmt.Errorf("file type not allowed: only images and documents can be attached")
+	}
+	ext := utils.GetFileExtension(req.NamaFile)
+	tipe, ok := lampiranContentTypes[ext]
+	if !ok {
+		return nil, fmt.Errorf("file type not allowed: %s", ext)
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		if err == io.EOF {
+			return nil, fmt.Errorf("file is empty")
+		}
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	head = head[:n]
+
+	detected := http.DetectContentType(head)
+	if !lampiranContentMatches(detected, tipe.detected) {
+		return nil, fmt.Errorf("file content (%s) does not match extension .%s", detected, ext)
+	}
+
+	key := fmt.Sprintf("lampiran/%d/%s/%d/%s", koperasiID, req.JenisDokumen, req.DokumenID,
+		utils.GenerateFileName(req.NamaFile, "lampiran"))
+
+	// Read one byte past the limit so an oversized file is caught even when
+	// the client understated its size.
+	hash := sha256.New()
+	counter := &byteCounter{}
+	body := io.LimitReader(io.MultiReader(bytes.NewReader(head), file), s.maxFileSize+1)
+	if err := s.storage.Put(key, io.TeeReader(body, io.MultiWriter(hash, counter))); err != nil {
+		return nil, fmt.Errorf("failed to store file: %v", err)
+	}
+	if counter.n > s.maxFileSize {
+		s.storage.Delete(key)
+		return nil, fmt.Errorf("file exceeds the maximum size of %d bytes", s.maxFileSize)
+	}
+
+	lampiran := &postgres.Lampiran{
+		KoperasiID:   koperasiID,
+		JenisDokumen: req.JenisDokumen,
+		DokumenID:    req.DokumenID,
+		NamaFile:     req.NamaFile,
+		ContentType:  tipe.contentType,
+		Ukuran:       counter.n,
+		Checksum:     hex.EncodeToString(hash.Sum(nil)),
+		StorageKey:   key,
+		Keterangan:   req.Keterangan,
+		CreatedBy:    createdBy,
+	}
+
+	if err := s.lampiranRepo.Create(lampiran); err != nil {
+		s.storage.Delete(key)
+		return nil, fmt.Errorf("failed to save lampiran: %v", err)
+	}
+
+	return lampiran, nil
+}
+
+func (s *LampiranService) GetLampiranList(jenisDokumen string, dokumenID uint64) ([]postgres.Lampiran, error) {
+	if _, err := s.getDokumenKoperasi(jenisDokumen, dokumenID); err != nil {
+		return nil, err
+	}
+	return s.lampiranRepo.GetByDokumen(jenisDokumen, dokumenID)
+}
+
+// OpenLampiran returns the attachment with a reader over its content. The
+// caller closes the reader.
+func (s *LampiranService) OpenLampiran(id uint64) (*postgres.Lampiran, io.ReadCloser, error) {
+	lampiran, err := s.lampiranRepo.GetByID(id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("lampiran not found: %v", err)
+	}
+
+	content, err := s.storage.Open(lampiran.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open lampiran file: %v", err)
+	}
+
+	return lampiran, content, nil
+}
+
+// DeleteLampiran removes an attachment. Attachments of a journal that has
+// left draft are audit evidence and stay.
+func (s *LampiranService) DeleteLampiran(id uint64) error {
+	lampiran, err := s.lampiranRepo.GetByID(id)
+	if err != nil {
+		return fmt.Errorf("lampiran not found: %v", err)
+	}
+
+	if lampiran.JenisDokumen == postgres.LampiranJurnalUmum {
+		jurnal, err := s.financialRepo.GetJurnalUmumByID(lampiran.DokumenID)
+		if err == nil && jurnal.Status != "draft" {
+			return fmt.Errorf("attachments of a %s journal cannot be deleted", jurnal.Status)
+		}
+	}
+
+	if err := s.lampiranRepo.Delete(id); err != nil {
+		return err
+	}
+
+	return s.storage.Delete(lampiran.StorageKey)
+}
+
+// getDokumenKoperasi checks that the document exists and returns the
+// koperasi it belongs to.
+func (s *LampiranService) getDokumenKoperasi(jenisDokumen string, dokumenID uint64) (uint64, error) {
+	switch jenisDokumen {
+	case postgres.LampiranJurnalUmum:
+		jurnal, err := s.financialRepo.GetJurnalUmumByID(dokumenID)
+		if err != nil {
+			return 0, fmt.Errorf("jurnal not found: %v", err)
+		}
+		return jurnal.KoperasiID, nil
+	case postgres.LampiranPembelian:
+		pembelian, err := s.produkRepo.GetPembelianByID(dokumenID)
+		if err != nil {
+			return 0, fmt.Errorf("pembelian not found: %v", err)
+		}
+		return pembelian.KoperasiID, nil
+	case postgres.LampiranPembayaranPembelian:
+		pembayaran, err := s.produkRepo.GetPembayaranByID(dokumenID)
+		if err != nil {
+			return 0, fmt.Errorf("pembayaran pembelian not found: %v", err)
+		}
+		return pembayaran.PembelianHeader.KoperasiID, nil
+	case postgres.LampiranTransaksiSimpanPinjam:
+		transaksi, err := s.simpanPinjamRepo.GetTransaksiByID(dokumenID)
+		if err != nil {
+			return 0, fmt.Errorf("transaksi simpan pinjam not found: %v", err)
+		}
+		return transaksi.KoperasiID, nil
+	default:
+		return 0, fmt.Errorf("invalid jenis dokumen: %s", jenisDokumen)
+	}
+}
+
+func lampiranContentMatches(detected string, allowed []string) bool {
+	for _, t := range allowed {
+		if strings.HasPrefix(detected, t) {
+			return true
+		}
+	}
+	return false
+}
+
+type byteCounter struct {
+	n int64
+}
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+type UploadLampiranRequest struct {
+	JenisDokumen string
+	DokumenID    uint64
+	NamaFile     string
+	Keterangan   string
+}
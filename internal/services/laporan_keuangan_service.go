@@ -0,0 +1,743 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+	"koperasi-merah-putih/internal/utils"
+)
+
+// Row kinds of a statement table.
+const (
+	BarisJudul    = "judul"
+	BarisPos      = "pos"
+	BarisSubtotal = "subtotal"
+	BarisTotal    = "total"
+)
+
+// LaporanKeuanganService builds the annual statements in the SAK EP layout
+// Dinas Koperasi audits against: laporan posisi keuangan, laporan hasil
+// usaha split into member and non-member income, laporan perubahan ekuitas
+// and the notes, each with the prior year alongside.
+type LaporanKeuanganService struct {
+	financialRepo *postgresRepo.FinancialRepository
+	koperasiRepo  *postgresRepo.KoperasiRepository
+}
+
+func NewLaporanKeuanganService(financialRepo *postgresRepo.FinancialRepository, koperasiRepo *postgresRepo.KoperasiRepository) *LaporanKeuanganService {
+	return &LaporanKeuanganService{
+		financialRepo: financialRepo,
+		koperasiRepo:  koperasiRepo,
+	}
+}
+
+// periodeLaporan is the year to date ending at Sampai.
+type periodeLaporan struct {
+	Dari   time.Time
+	Sampai time.Time
+}
+
+// saldoPeriode holds, per akun, the balances at the start and end of a
+// period and the movement in between, all signed by kategori: aset and
+// beban debit positive, the rest kredit positive.
+type saldoPeriode struct {
+	awal   map[uint64]money.Amount
+	akhir  map[uint64]money.Amount
+	debit  map[uint64]money.Amount
+	kredit map[uint64]money.Amount
+	usaha  []postgresRepo.MutasiHasilUsaha
+}
+
+// GetLaporanKeuangan builds the statements for the year to date ending at
+// tanggal, compared with the same date a year earlier.
+func (s *LaporanKeuanganService) GetLaporanKeuangan(koperasiID uint64, tanggal time.Time) (*LaporanKeuangan, error) {
+	koperasi, err := s.koperasiRepo.GetByID(koperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("koperasi not found: %v", err)
+	}
+
+	akuns, err := s.financialRepo.GetCOAAkunAll(koperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load akun: %v", err)
+	}
+	pos := newPosLaporan(akuns)
+
+	tanggal = time.Date(tanggal.Year(), tanggal.Month(), tanggal.Day(), 0, 0, 0, 0, time.UTC)
+	periode := [2]periodeLaporan{
+		{Dari: time.Date(tanggal.Year(), 1, 1, 0, 0, 0, 0, time.UTC), Sampai: tanggal},
+	}
+	pembanding := tanggal.AddDate(-1, 0, 0)
+	if tanggal.AddDate(0, 0, 1).Day() == 1 {
+		// Keep month ends on month ends, e.g. 29 Feb against 28 Feb.
+		pembanding = time.Date(tanggal.Year()-1, tanggal.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+	}
+	periode[1] = periodeLaporan{Dari: time.Date(pembanding.Year(), 1, 1, 0, 0, 0, 0, time.UTC), Sampai: pembanding}
+
+	var saldo [2]*saldoPeriode
+	for i, p := range periode {
+		if saldo[i], err = s.loadSaldoPeriode(koperasiID, p); err != nil {
+			return nil, err
+		}
+	}
+
+	laporan := &LaporanKeuangan{
+		Koperasi: identitasKoperasi(koperasi),
+		Tanggal:  tanggal,
+		Dari:     periode[0].Dari,
+		Pembanding: Pembanding{
+			Tanggal: periode[1].Sampai,
+			Dari:    periode[1].Dari,
+		},
+	}
+
+	kolomNeraca := []string{periode[0].Sampai.Format("02-01-2006"), periode[1].Sampai.Format("02-01-2006")}
+	kolomUsaha := []string{labelPeriode(periode[0]), labelPeriode(periode[1])}
+
+	catatan := 3
+	laporan.Neraca, laporan.Catatan = s.buildNeraca(pos, saldo, kolomNeraca, &catatan)
+	laporan.HasilUsaha = s.buildHasilUsaha(pos, saldo, kolomUsaha)
+	for i := range periode {
+		laporan.PerubahanEkuitas = append(laporan.PerubahanEkuitas, s.buildPerubahanEkuitas(pos, saldo[i], labelPeriode(periode[i])))
+	}
+	laporan.Catatan = append(catatanUmum(laporan.Koperasi), laporan.Catatan...)
+
+	for _, baris := range laporan.Neraca.Baris {
+		switch baris.Uraian {
+		case "JUMLAH ASET":
+			laporan.TotalAset = baris.Nilai
+		case "JUMLAH KEWAJIBAN DAN EKUITAS":
+			laporan.TotalKewajibanEkuitas = baris.Nilai
+		}
+	}
+	laporan.IsBalanced = len(laporan.TotalAset) == 2 && len(laporan.TotalKewajibanEkuitas) == 2 &&
+		laporan.TotalAset[0] == laporan.TotalKewajibanEkuitas[0] &&
+		laporan.TotalAset[1] == laporan.TotalKewajibanEkuitas[1]
+
+	return laporan, nil
+}
+
+func (s *LaporanKeuanganService) loadSaldoPeriode(koperasiID uint64, p periodeLaporan) (*saldoPeriode, error) {
+	sampai := p.Sampai.AddDate(0, 0, 1).Add(-time.Nanosecond)
+
+	sebelum, err := s.financialRepo.GetMutasiAkun(koperasiID, time.Time{}, p.Dari.Add(-time.Nanosecond))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get opening balances: %v", err)
+	}
+	mutasi, err := s.financialRepo.GetMutasiAkun(koperasiID, p.Dari, sampai)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account movements: %v", err)
+	}
+	usaha, err := s.financialRepo.GetMutasiHasilUsaha(koperasiID, p.Dari, sampai)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get income and expenses: %v", err)
+	}
+
+	saldo := &saldoPeriode{
+		awal:   make(map[uint64]money.Amount),
+		akhir:  make(map[uint64]money.Amount),
+		debit:  make(map[uint64]money.Amount),
+		kredit: make(map[uint64]money.Amount),
+		usaha:  usaha,
+	}
+	for _, m := range sebelum {
+		n := saldoKategori(m.KategoriTipe, m.TotalDebit, m.TotalKredit)
+		saldo.awal[m.AkunID] = n
+		saldo.akhir[m.AkunID] = n
+	}
+	for _, m := range mutasi {
+		saldo.akhir[m.AkunID] += saldoKategori(m.KategoriTipe, m.TotalDebit, m.TotalKredit)
+		saldo.debit[m.AkunID] = m.TotalDebit
+		saldo.kredit[m.AkunID] = m.TotalKredit
+	}
+	return saldo, nil
+}
+
+// saldoKategori signs a balance by the side its kategori normally sits on,
+// so contra accounts such as akumulasi penyusutan come out negative.
+func saldoKategori(tipe string, debit, kredit money.Amount) money.Amount {
+	if saldoNormalKategori(tipe) == "debit" {
+		return debit - kredit
+	}
+	return kredit - debit
+}
+
+// shuBelumDibagi is the result not yet closed into equity: pendapatan less
+// beban over everything booked up to the balance date.
+func shuBelumDibagi(pos *posLaporan, saldo map[uint64]money.Amount) money.Amount {
+	var shu money.Amount
+	for id, n := range saldo {
+		switch pos.tipe(id) {
+		case "pendapatan":
+			shu += n
+		case "beban":
+			shu -= n
+		}
+	}
+	return shu
+}
+
+func (s *LaporanKeuanganService) buildNeraca(pos *posLaporan, saldo [2]*saldoPeriode, kolom []string, catatan *int) (TabelLaporan, []CatatanLaporan) {
+	tabel := TabelLaporan{Judul: "Laporan Posisi Keuangan", Kolom: kolom}
+	var notes []CatatanLaporan
+
+	akhir := func(i int) map[uint64]money.Amount { return saldo[i].akhir }
+	kelompok := func(tipe string) []*kelompokLaporan {
+		return pos.kelompokkan(tipe, func(id uint64) [2]money.Amount {
+			return [2]money.Amount{akhir(0)[id], akhir(1)[id]}
+		})
+	}
+
+	tulis := func(judul string, groups []*kelompokLaporan, extra *BarisLaporan) [2]money.Amount {
+		tabel.Baris = append(tabel.Baris, BarisLaporan{Uraian: judul, Jenis: BarisJudul})
+		var total [2]money.Amount
+		for _, g := range groups {
+			indent := 1
+			if g.judul.ID != 0 {
+				tabel.Baris = append(tabel.Baris, BarisLaporan{Uraian: g.judul.NamaAkun, Jenis: BarisJudul, Indent: 1})
+				indent = 2
+			}
+			var sub [2]money.Amount
+			for _, p := range g.pos {
+				baris := BarisLaporan{Uraian: p.akun.NamaAkun, Jenis: BarisPos, Indent: indent, Nilai: p.nilai[:]}
+				baris.Catatan = fmt.Sprintf("%d", *catatan)
+				notes = append(notes, catatanPos(*catatan, p, kolom))
+				*catatan++
+				tabel.Baris = append(tabel.Baris, baris)
+				sub[0] += p.nilai[0]
+				sub[1] += p.nilai[1]
+			}
+			if g.judul.ID != 0 {
+				tabel.Baris = append(tabel.Baris, BarisLaporan{Uraian: "Jumlah " + g.judul.NamaAkun, Jenis: BarisSubtotal, Indent: 1, Nilai: sub[:]})
+			}
+			total[0] += sub[0]
+			total[1] += sub[1]
+		}
+		if extra != nil {
+			tabel.Baris = append(tabel.Baris, *extra)
+			total[0] += extra.Nilai[0]
+			total[1] += extra.Nilai[1]
+		}
+		tabel.Baris = append(tabel.Baris, BarisLaporan{Uraian: "JUMLAH " + judul, Jenis: BarisSubtotal, Nilai: []money.Amount{total[0], total[1]}})
+		return total
+	}
+
+	tulis("ASET", kelompok("aset"), nil)
+	tabel.Baris[len(tabel.Baris)-1].Jenis = BarisTotal
+
+	kewajiban := tulis("KEWAJIBAN", kelompok("kewajiban"), nil)
+	shu := &BarisLaporan{
+		Uraian: "Sisa hasil usaha belum dibagi",
+		Jenis:  BarisPos,
+		Indent: 1,
+		Nilai:  []money.Amount{shuBelumDibagi(pos, akhir(0)), shuBelumDibagi(pos, akhir(1))},
+	}
+	ekuitas := tulis("EKUITAS", kelompok("ekuitas"), shu)
+
+	tabel.Baris = append(tabel.Baris, BarisLaporan{
+		Uraian: "JUMLAH KEWAJIBAN DAN EKUITAS",
+		Jenis:  BarisTotal,
+		Nilai:  []money.Amount{kewajiban[0] + ekuitas[0], kewajiban[1] + ekuitas[1]},
+	})
+
+	return tabel, notes
+}
+
+func (s *LaporanKeuanganService) buildHasilUsaha(pos *posLaporan, saldo [2]*saldoPeriode, kolom []string) TabelLaporan {
+	tabel := TabelLaporan{Judul: "Laporan Hasil Usaha", Kolom: kolom}
+
+	// Amounts per akun and side of the member split, per period.
+	type kunci struct {
+		akunID  uint64
+		anggota bool
+	}
+	nilai := make(map[kunci][2]money.Amount)
+	for i := range saldo {
+		for _, m := range saldo[i].usaha {
+			k := kunci{m.AkunID, m.Anggota && m.KategoriTipe == "pendapatan"}
+			v := nilai[k]
+			v[i] += saldoKategori(m.KategoriTipe, m.TotalDebit, m.TotalKredit)
+			nilai[k] = v
+		}
+	}
+
+	bagian := func(tipe string, anggota bool) []*kelompokLaporan {
+		return pos.kelompokkan(tipe, func(id uint64) [2]money.Amount {
+			return nilai[kunci{id, anggota}]
+		})
+	}
+
+	tulisPos := func(groups []*kelompokLaporan, indent int) [2]money.Amount {
+		var total [2]money.Amount
+		for _, g := range groups {
+			for _, p := range g.pos {
+				tabel.Baris = append(tabel.Baris, BarisLaporan{Uraian: p.akun.NamaAkun, Jenis: BarisPos, Indent: indent, Nilai: []money.Amount{p.nilai[0], p.nilai[1]}})
+				total[0] += p.nilai[0]
+				total[1] += p.nilai[1]
+			}
+		}
+		return total
+	}
+
+	tabel.Baris = append(tabel.Baris, BarisLaporan{Uraian: "PENDAPATAN", Jenis: BarisJudul})
+	tabel.Baris = append(tabel.Baris, BarisLaporan{Uraian: "Partisipasi anggota", Jenis: BarisJudul, Indent: 1})
+	anggota := tulisPos(bagian("pendapatan", true), 2)
+	tabel.Baris = append(tabel.Baris, BarisLaporan{Uraian: "Jumlah partisipasi anggota", Jenis: BarisSubtotal, Indent: 1, Nilai: []money.Amount{anggota[0], anggota[1]}})
+	tabel.Baris = append(tabel.Baris, BarisLaporan{Uraian: "Pendapatan dari non-anggota", Jenis: BarisJudul, Indent: 1})
+	nonAnggota := tulisPos(bagian("pendapatan", false), 2)
+	tabel.Baris = append(tabel.Baris, BarisLaporan{Uraian: "Jumlah pendapatan dari non-anggota", Jenis: BarisSubtotal, Indent: 1, Nilai: []money.Amount{nonAnggota[0], nonAnggota[1]}})
+	pendapatan := [2]money.Amount{anggota[0] + nonAnggota[0], anggota[1] + nonAnggota[1]}
+	tabel.Baris = append(tabel.Baris, BarisLaporan{Uraian: "JUMLAH PENDAPATAN", Jenis: BarisSubtotal, Nilai: pendapatan[:]})
+
+	tabel.Baris = append(tabel.Baris, BarisLaporan{Uraian: "BEBAN", Jenis: BarisJudul})
+	beban := tulisPos(bagian("beban", false), 1)
+	tabel.Baris = append(tabel.Baris, BarisLaporan{Uraian: "JUMLAH BEBAN", Jenis: BarisSubtotal, Nilai: []money.Amount{beban[0], beban[1]}})
+
+	tabel.Baris = append(tabel.Baris, BarisLaporan{
+		Uraian: "SISA HASIL USAHA",
+		Jenis:  BarisTotal,
+		Nilai:  []money.Amount{pendapatan[0] - beban[0], pendapatan[1] - beban[1]},
+	})
+	return tabel
+}
+
+func (s *LaporanKeuanganService) buildPerubahanEkuitas(pos *posLaporan, saldo *saldoPeriode, label string) TabelLaporan {
+	tabel := TabelLaporan{
+		Judul: "Laporan Perubahan Ekuitas " + label,
+		Kolom: []string{"Saldo awal", "Penambahan", "Pengurangan", "Saldo akhir"},
+	}
+
+	var total [4]money.Amount
+	tambah := func(uraian string, n [4]money.Amount) {
+		tabel.Baris = append(tabel.Baris, BarisLaporan{Uraian: uraian, Jenis: BarisPos, Nilai: n[:]})
+		for i := range total {
+			total[i] += n[i]
+		}
+	}
+
+	// Equity is reported per pos like the balance sheet, with the movement
+	// split into what was credited and what was debited.
+	akunPos := make(map[uint64][4]money.Amount)
+	var urutan []postgres.COAAkun
+	for _, id := range pos.urut("ekuitas") {
+		_, p := pos.letak(id)
+		if _, ok := akunPos[p.ID]; !ok {
+			urutan = append(urutan, p)
+		}
+		n := akunPos[p.ID]
+		n[0] += saldo.awal[id]
+		n[1] += saldo.kredit[id]
+		n[2] += saldo.debit[id]
+		n[3] += saldo.akhir[id]
+		akunPos[p.ID] = n
+	}
+	for _, p := range urutan {
+		n := akunPos[p.ID]
+		if n == [4]money.Amount{} {
+			continue
+		}
+		tambah(p.NamaAkun, n)
+	}
+
+	shu := [4]money.Amount{shuBelumDibagi(pos, saldo.awal), 0, 0, shuBelumDibagi(pos, saldo.akhir)}
+	if selisih := shu[3] - shu[0]; selisih >= 0 {
+		shu[1] = selisih
+	} else {
+		shu[2] = -selisih
+	}
+	tambah("Sisa hasil usaha belum dibagi", shu)
+
+	tabel.Baris = append(tabel.Baris, BarisLaporan{Uraian: "JUMLAH EKUITAS", Jenis: BarisTotal, Nilai: total[:]})
+	return tabel
+}
+
+func catatanPos(nomor int, p *posBaris, kolom []string) CatatanLaporan {
+	tabel := &TabelLaporan{Kolom: kolom}
+	for _, r := range p.rincian {
+		tabel.Baris = append(tabel.Baris, BarisLaporan{
+			Uraian: r.akun.KodeAkun + " " + r.akun.NamaAkun,
+			Jenis:  BarisPos,
+			Nilai:  []money.Amount{r.nilai[0], r.nilai[1]},
+		})
+	}
+	tabel.Baris = append(tabel.Baris, BarisLaporan{Uraian: "Jumlah", Jenis: BarisSubtotal, Nilai: []money.Amount{p.nilai[0], p.nilai[1]}})
+	return CatatanLaporan{Nomor: nomor, Judul: p.akun.NamaAkun, Tabel: tabel}
+}
+
+// catatanUmum returns notes 1 and 2: who the koperasi is and the basis the
+// statements are prepared on.
+func catatanUmum(k IdentitasKoperasi) []CatatanLaporan {
+	umum := fmt.Sprintf("%s didirikan dengan badan hukum Nomor %s", k.Nama, k.NomorSK)
+	if k.TanggalSK != nil {
+		umum += " tanggal " + utils.FormatDateIndonesia(*k.TanggalSK)
+	}
+	umum += "."
+	if k.Alamat != "" {
+		umum += " Koperasi berkedudukan di " + k.Alamat + "."
+	}
+	if k.NIK != 0 {
+		umum += fmt.Sprintf(" Nomor Induk Koperasi %d.", k.NIK)
+	}
+
+	return []CatatanLaporan{
+		{Nomor: 1, Judul: "Umum", Paragraf: []string{umum}},
+		{Nomor: 2, Judul: "Ikhtisar Kebijakan Akuntansi", Paragraf: []string{
+			"Laporan keuangan disusun berdasarkan Standar Akuntansi Keuangan Entitas Privat (SAK EP) dengan dasar akrual dan konsep biaya historis, dalam mata uang Rupiah.",
+			"Pendapatan dari transaksi dengan anggota disajikan sebagai partisipasi anggota; pendapatan dari transaksi dengan pihak lain disajikan sebagai pendapatan dari non-anggota.",
+			"Sisa hasil usaha tahun berjalan disajikan dalam ekuitas sampai dibagikan sesuai keputusan Rapat Anggota.",
+		}},
+	}
+}
+
+func identitasKoperasi(k *postgres.Koperasi) IdentitasKoperasi {
+	alamat := []string{}
+	if k.Alamat != "" {
+		a := k.Alamat
+		if k.RT != "" || k.RW != "" {
+			a += fmt.Sprintf(" RT %s/RW %s", k.RT, k.RW)
+		}
+		alamat = append(alamat, a)
+	}
+	for _, nama := range []string{k.Kelurahan.Nama, k.Kecamatan.Nama, k.Kabupaten.Nama, k.Provinsi.Nama} {
+		if nama != "" {
+			alamat = append(alamat, nama)
+		}
+	}
+	if k.KodePos != "" {
+		alamat = append(alamat, k.KodePos)
+	}
+
+	return IdentitasKoperasi{
+		Nama:      k.NamaKoperasi,
+		NomorSK:   k.NomorSK,
+		TanggalSK: k.TanggalSK,
+		NIK:       k.NIK,
+		Alamat:    strings.Join(alamat, ", "),
+	}
+}
+
+// labelPeriode names a year-to-date period by its year when it is the full
+// year, otherwise by its end date.
+func labelPeriode(p periodeLaporan) string {
+	if p.Sampai.Month() == time.December && p.Sampai.Day() == 31 {
+		return fmt.Sprintf("%d", p.Sampai.Year())
+	}
+	return "s.d. " + p.Sampai.Format("02-01-2006")
+}
+
+// posLaporan places each akun on the statement line it is reported under:
+// the grandchild of its root akun, headed by the root's child. Akun nearer
+// the root are lines of their own.
+type posLaporan struct {
+	akun map[uint64]postgres.COAAkun
+}
+
+func newPosLaporan(akuns []postgres.COAAkun) *posLaporan {
+	p := &posLaporan{akun: make(map[uint64]postgres.COAAkun, len(akuns))}
+	for _, akun := range akuns {
+		p.akun[akun.ID] = akun
+	}
+	return p
+}
+
+func (p *posLaporan) tipe(id uint64) string {
+	return strings.ToLower(p.akun[id].Kategori.Tipe)
+}
+
+// letak returns the heading (zero when there is none) and the line of id.
+func (p *posLaporan) letak(id uint64) (postgres.COAAkun, postgres.COAAkun) {
+	var chain []postgres.COAAkun
+	for cur, ok := p.akun[id]; ok && len(chain) < 20; cur, ok = p.akun[cur.ParentID] {
+		chain = append([]postgres.COAAkun{cur}, chain...)
+		if cur.ParentID == 0 {
+			break
+		}
+	}
+	switch len(chain) {
+	case 0:
+		return postgres.COAAkun{}, postgres.COAAkun{ID: id}
+	case 1, 2:
+		return postgres.COAAkun{}, chain[len(chain)-1]
+	}
+	return chain[1], chain[2]
+}
+
+// urut lists the akun of a kategori by kode.
+func (p *posLaporan) urut(tipe string) []uint64 {
+	var ids []uint64
+	for id := range p.akun {
+		if p.tipe(id) == tipe {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return p.akun[ids[i]].KodeAkun < p.akun[ids[j]].KodeAkun
+	})
+	return ids
+}
+
+type kelompokLaporan struct {
+	judul postgres.COAAkun
+	pos   []*posBaris
+}
+
+type posBaris struct {
+	akun    postgres.COAAkun
+	nilai   [2]money.Amount
+	rincian []rincianAkun
+}
+
+type rincianAkun struct {
+	akun  postgres.COAAkun
+	nilai [2]money.Amount
+}
+
+// kelompokkan groups the akun of a kategori into headings and lines, in kode
+// order, leaving out akun that are zero in both columns.
+func (p *posLaporan) kelompokkan(tipe string, nilai func(id uint64) [2]money.Amount) []*kelompokLaporan {
+	var groups []*kelompokLaporan
+	byJudul := make(map[uint64]*kelompokLaporan)
+	byPos := make(map[uint64]*posBaris)
+
+	for _, id := range p.urut(tipe) {
+		n := nilai(id)
+		if n == [2]money.Amount{} {
+			continue
+		}
+
+		judul, pos := p.letak(id)
+		g, ok := byJudul[judul.ID]
+		if !ok {
+			g = &kelompokLaporan{judul: judul}
+			byJudul[judul.ID] = g
+			groups = append(groups, g)
+		}
+		b, ok := byPos[pos.ID]
+		if !ok {
+			b = &posBaris{akun: pos}
+			byPos[pos.ID] = b
+			g.pos = append(g.pos, b)
+		}
+		b.nilai[0] += n[0]
+		b.nilai[1] += n[1]
+		b.rincian = append(b.rincian, rincianAkun{akun: p.akun[id], nilai: n})
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].judul.KodeAkun < groups[j].judul.KodeAkun
+	})
+	return groups
+}
+
+// WriteLaporanKeuanganXLSX writes one sheet per statement and one for the
+// notes.
+func (s *LaporanKeuanganService) WriteLaporanKeuanganXLSX(w io.Writer, laporan *LaporanKeuangan) error {
+	kepala := [][]string{
+		{laporan.Koperasi.Nama},
+		{"Badan Hukum Nomor " + laporan.Koperasi.NomorSK},
+		{laporan.Koperasi.Alamat},
+		{},
+	}
+
+	sheet := func(name string, tabel TabelLaporan) utils.XLSXSheet {
+		rows := append([][]string{}, kepala...)
+		rows = append(rows, []string{tabel.Judul}, []string{})
+		rows = append(rows, tabelXLSXRows(tabel)...)
+		return utils.XLSXSheet{Name: name, Rows: rows}
+	}
+
+	sheets := []utils.XLSXSheet{
+		sheet("Posisi Keuangan", laporan.Neraca),
+		sheet("Hasil Usaha", laporan.HasilUsaha),
+	}
+
+	ekuitas := append([][]string{}, kepala...)
+	for _, tabel := range laporan.PerubahanEkuitas {
+		ekuitas = append(ekuitas, []string{tabel.Judul}, []string{})
+		ekuitas = append(ekuitas, tabelXLSXRows(tabel)...)
+		ekuitas = append(ekuitas, []string{})
+	}
+	sheets = append(sheets, utils.XLSXSheet{Name: "Perubahan Ekuitas", Rows: ekuitas})
+
+	catatan := append([][]string{}, kepala...)
+	catatan = append(catatan, []string{"Catatan atas Laporan Keuangan"}, []string{})
+	for _, c := range laporan.Catatan {
+		catatan = append(catatan, []string{fmt.Sprintf("%d. %s", c.Nomor, c.Judul)})
+		for _, p := range c.Paragraf {
+			catatan = append(catatan, []string{p})
+		}
+		if c.Tabel != nil {
+			catatan = append(catatan, tabelXLSXRows(*c.Tabel)...)
+		}
+		catatan = append(catatan, []string{})
+	}
+	sheets = append(sheets, utils.XLSXSheet{Name: "Catatan", Rows: catatan})
+
+	return utils.WriteXLSX(w, sheets...)
+}
+
+func tabelXLSXRows(tabel TabelLaporan) [][]string {
+	header := []string{"Uraian", "Catatan"}
+	rows := [][]string{append(header, tabel.Kolom...)}
+	for _, b := range tabel.Baris {
+		row := []string{strings.Repeat("  ", b.Indent) + b.Uraian, b.Catatan}
+		for _, n := range b.Nilai {
+			row = append(row, n.String())
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// WriteLaporanKeuanganPDF renders the statements, each starting on its own
+// page, followed by the notes.
+func (s *LaporanKeuanganService) WriteLaporanKeuanganPDF(w io.Writer, laporan *LaporanKeuangan) error {
+	doc := utils.NewPDFDocument()
+	doc.SetFooter(fmt.Sprintf("%s - Laporan Keuangan %s", laporan.Koperasi.Nama, utils.FormatDateIndonesia(laporan.Tanggal)))
+
+	kepala := func(judul, sub string) {
+		doc.Heading(laporan.Koperasi.Nama, 13)
+		doc.Heading(judul, 11)
+		doc.Heading(sub, 9)
+		doc.Space(10)
+	}
+
+	tabelPDF := func(tabel TabelLaporan) {
+		// Uraian, catatan, then the amount columns across the rest.
+		lebar := 495.28
+		kolom := []utils.PDFColumn{{Width: 0}, {Width: 40}}
+		jumlah := 90.0
+		if len(tabel.Kolom) > 2 {
+			jumlah = 75
+		}
+		uraian := lebar - 40 - jumlah*float64(len(tabel.Kolom))
+		kolom[0].Width = uraian
+		for range tabel.Kolom {
+			kolom = append(kolom, utils.PDFColumn{Width: jumlah, Right: true})
+		}
+		doc.SetColumns(kolom...)
+
+		doc.Row(8.5, true, append([]string{"", "Catatan"}, tabel.Kolom...)...)
+		doc.Rule(0)
+		for _, b := range tabel.Baris {
+			cells := []string{strings.Repeat("    ", b.Indent) + b.Uraian, b.Catatan}
+			for _, n := range b.Nilai {
+				cells = append(cells, formatJumlahLaporan(n))
+			}
+			bold := b.Jenis != BarisPos
+			if b.Jenis == BarisSubtotal || b.Jenis == BarisTotal {
+				doc.Rule(2)
+			}
+			doc.Row(8.5, bold, cells...)
+			if b.Jenis == BarisTotal {
+				doc.Rule(2)
+				doc.Space(6)
+			}
+		}
+	}
+
+	kepala("LAPORAN POSISI KEUANGAN", "Per "+utils.FormatDateIndonesia(laporan.Tanggal)+" dan "+utils.FormatDateIndonesia(laporan.Pembanding.Tanggal))
+	tabelPDF(laporan.Neraca)
+
+	doc.NewPage()
+	kepala("LAPORAN HASIL USAHA", "Untuk periode yang berakhir "+utils.FormatDateIndonesia(laporan.Tanggal)+" dan "+utils.FormatDateIndonesia(laporan.Pembanding.Tanggal))
+	tabelPDF(laporan.HasilUsaha)
+
+	doc.NewPage()
+	kepala("LAPORAN PERUBAHAN EKUITAS", "Untuk periode yang berakhir "+utils.FormatDateIndonesia(laporan.Tanggal)+" dan "+utils.FormatDateIndonesia(laporan.Pembanding.Tanggal))
+	for _, tabel := range laporan.PerubahanEkuitas {
+		doc.SetColumns(utils.PDFColumn{Width: 495.28})
+		doc.Row(9, true, tabel.Judul)
+		tabelPDF(tabel)
+		doc.Space(10)
+	}
+
+	doc.NewPage()
+	kepala("CATATAN ATAS LAPORAN KEUANGAN", "Per "+utils.FormatDateIndonesia(laporan.Tanggal))
+	for _, c := range laporan.Catatan {
+		doc.SetColumns(utils.PDFColumn{Width: 495.28})
+		doc.Row(9, true, fmt.Sprintf("%d. %s", c.Nomor, c.Judul))
+		for _, p := range c.Paragraf {
+			doc.Paragraph(p, 9)
+		}
+		if c.Tabel != nil {
+			tabelPDF(*c.Tabel)
+		}
+		doc.Space(8)
+	}
+
+	return doc.Write(w)
+}
+
+// formatJumlahLaporan formats an amount the way Indonesian statements print
+// it: dots for thousands, comma for sen, negatives in brackets and zero as
+// a dash.
+func formatJumlahLaporan(a money.Amount) string {
+	if a == 0 {
+		return "-"
+	}
+	negatif := a < 0
+	if negatif {
+		a = -a
+	}
+	s := fmt.Sprintf("%s,%02d", utils.FormatNumber(float64(a.Rupiah())), int64(a)%100)
+	if negatif {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+// Request/Response structs
+type LaporanKeuangan struct {
+	Koperasi              IdentitasKoperasi `json:"koperasi"`
+	Tanggal               time.Time         `json:"tanggal"`
+	Dari                  time.Time         `json:"dari"`
+	Pembanding            Pembanding        `json:"pembanding"`
+	Neraca                TabelLaporan      `json:"neraca"`
+	HasilUsaha            TabelLaporan      `json:"hasil_usaha"`
+	PerubahanEkuitas      []TabelLaporan    `json:"perubahan_ekuitas"`
+	Catatan               []CatatanLaporan  `json:"catatan"`
+	TotalAset             []money.Amount    `json:"total_aset"`
+	TotalKewajibanEkuitas []money.Amount    `json:"total_kewajiban_ekuitas"`
+	IsBalanced            bool              `json:"is_balanced"`
+}
+
+type Pembanding struct {
+	Tanggal time.Time `json:"tanggal"`
+	Dari    time.Time `json:"dari"`
+}
+
+type IdentitasKoperasi struct {
+	Nama      string     `json:"nama"`
+	NomorSK   string     `json:"nomor_sk"`
+	TanggalSK *time.Time `json:"tanggal_sk"`
+	NIK       uint64     `json:"nik"`
+	Alamat    string     `json:"alamat"`
+}
+
+// TabelLaporan is one statement: labelled amount columns and its rows.
+type TabelLaporan struct {
+	Judul string         `json:"judul,omitempty"`
+	Kolom []string       `json:"kolom"`
+	Baris []BarisLaporan `json:"baris"`
+}
+
+type BarisLaporan struct {
+	Uraian  string         `json:"uraian"`
+	Catatan string         `json:"catatan,omitempty"`
+	Jenis   string         `json:"jenis"`
+	Indent  int            `json:"indent"`
+	Nilai   []money.Amount `json:"nilai,omitempty"`
+}
+
+type CatatanLaporan struct {
+	Nomor    int           `json:"nomor"`
+	Judul    string        `json:"judul"`
+	Paragraf []string      `json:"paragraf,omitempty"`
+	Tabel    *TabelLaporan `json:"tabel,omitempty"`
+}
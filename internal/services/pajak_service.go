@@ -0,0 +1,749 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+)
+
+// PPN categories of a product category. Anything not set is BKP.
+const (
+	KategoriPajakBKP    = "bkp"
+	KategoriPajakNonBKP = "non_bkp"
+)
+
+// PPh withheld by the koperasi.
+const (
+	JenisPPh21     = "pph21"
+	JenisPPh23     = "pph23"
+	JenisPPh4Ayat2 = "pph4_2"
+)
+
+// Monthly exports, in the layout the DJP import tools read.
+const (
+	ExportEFakturKeluaran = "efaktur_keluaran"
+	ExportEFakturMasukan  = "efaktur_masukan"
+	ExportEBupot          = "ebupot"
+)
+
+type PajakService struct {
+	pajakRepo       *postgresRepo.PajakRepository
+	produkRepo      *postgresRepo.ProdukRepository
+	koperasiRepo    *postgresRepo.KoperasiRepository
+	postingService  *PostingService
+	sequenceService *SequenceService
+}
+
+func NewPajakService(
+	pajakRepo *postgresRepo.PajakRepository,
+	produkRepo *postgresRepo.ProdukRepository,
+	koperasiRepo *postgresRepo.KoperasiRepository,
+	postingService *PostingService,
+	sequenceService *SequenceService,
+) *PajakService {
+	return &PajakService{
+		pajakRepo:       pajakRepo,
+		produkRepo:      produkRepo,
+		koperasiRepo:    koperasiRepo,
+		postingService:  postingService,
+		sequenceService: sequenceService,
+	}
+}
+
+// GetPengaturan returns the tax setup of a koperasi, or the statutory
+// defaults for a non-PKP koperasi when none has been saved.
+func (s *PajakService) GetPengaturan(koperasiID uint64) (*postgres.PengaturanPajak, error) {
+	pengaturan, err := s.pajakRepo.GetPengaturan(koperasiID)
+	if err == gorm.ErrRecordNotFound {
+		return &postgres.PengaturanPajak{
+			KoperasiID:      koperasiID,
+			TarifPPN:        11,
+			TarifPPh21:      5,
+			TarifPPh23:      2,
+			TarifPPh4Ayat2:  10,
+			BatasBungaBebas: money.FromInt(240000),
+			Kategori:        []postgres.KategoriPajakProduk{},
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pengaturan pajak: %v", err)
+	}
+	return pengaturan, nil
+}
+
+func (s *PajakService) SimpanPengaturan(req *PengaturanPajakRequest, updatedBy uint64) (*postgres.PengaturanPajak, error) {
+	if _, err := s.koperasiRepo.GetByID(req.KoperasiID); err != nil {
+		return nil, fmt.Errorf("koperasi not found: %v", err)
+	}
+	if req.IsPKP && npwpAngka(req.NPWP) == "" {
+		return nil, fmt.Errorf("npwp is required for a PKP koperasi")
+	}
+	if req.IsPKP && req.TarifPPN <= 0 {
+		return nil, fmt.Errorf("tarif ppn is required for a PKP koperasi")
+	}
+
+	seen := make(map[uint64]bool)
+	var kategori []postgres.KategoriPajakProduk
+	for _, item := range req.Kategori {
+		if seen[item.KategoriProdukID] {
+			return nil, fmt.Errorf("kategori produk %d is listed twice", item.KategoriProdukID)
+		}
+		seen[item.KategoriProdukID] = true
+
+		if _, err := s.produkRepo.GetKategoriProdukByID(item.KategoriProdukID); err != nil {
+			return nil, fmt.Errorf("kategori produk %d not found: %v", item.KategoriProdukID, err)
+		}
+		kategori = append(kategori, postgres.KategoriPajakProduk{
+			KoperasiID:       req.KoperasiID,
+			KategoriProdukID: item.KategoriProdukID,
+			KategoriPajak:    item.KategoriPajak,
+		})
+	}
+
+	pengaturan, err := s.GetPengaturan(req.KoperasiID)
+	if err != nil {
+		return nil, err
+	}
+	pengaturan.IsPKP = req.IsPKP
+	pengaturan.NPWP = req.NPWP
+	pengaturan.TanggalPKP = req.TanggalPKP
+	pengaturan.TarifPPN = req.TarifPPN
+	pengaturan.TarifPPh21 = req.TarifPPh21
+	pengaturan.TarifPPh23 = req.TarifPPh23
+	pengaturan.TarifPPh4Ayat2 = req.TarifPPh4Ayat2
+	pengaturan.BatasBungaBebas = req.BatasBungaBebas
+	pengaturan.KodeObjekBunga = req.KodeObjekBunga
+	pengaturan.UpdatedBy = updatedBy
+
+	err = s.pajakRepo.Transaction(func(tx *gorm.DB) error {
+		pajakRepo := s.pajakRepo.WithTx(tx)
+		if err := pajakRepo.SavePengaturan(pengaturan); err != nil {
+			return fmt.Errorf("failed to save pengaturan pajak: %v", err)
+		}
+		if err := pajakRepo.ReplaceKategori(req.KoperasiID, kategori); err != nil {
+			return fmt.Errorf("failed to save kategori pajak: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPengaturan(req.KoperasiID)
+}
+
+// itemPajak is one invoice line a PPN base is built from.
+type itemPajak struct {
+	ProdukID uint64
+	Jumlah   money.Amount
+}
+
+// ppnPenjualan returns the DPP, PPN and rate of a sale. A koperasi that is
+// not PKP charges no PPN.
+func (s *PajakService) ppnPenjualan(koperasiID uint64, items []itemPajak) (money.Amount, money.Amount, float64, error) {
+	pengaturan, err := s.GetPengaturan(koperasiID)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if !pengaturan.IsPKP {
+		return 0, 0, 0, nil
+	}
+	dpp, ppn, err := s.hitungPPN(pengaturan, items, pengaturan.TarifPPN)
+	return dpp, ppn, pengaturan.TarifPPN, err
+}
+
+// ppnPembelian returns the DPP, PPN and rate of a purchase. persen, when
+// set, is the rate printed on the supplier's faktur; otherwise a PKP
+// supplier charges the koperasi's configured rate and any other supplier
+// charges none. This is what the supplier charges; kreditPPNMasukan says
+// whether the koperasi can credit it.
+func (s *PajakService) ppnPembelian(koperasiID uint64, supplier *postgres.Supplier, items []itemPajak, persen float64) (money.Amount, money.Amount, float64, error) {
+	pengaturan, err := s.GetPengaturan(koperasiID)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if persen <= 0 {
+		if !supplier.IsPKP {
+			return 0, 0, 0, nil
+		}
+		persen = pengaturan.TarifPPN
+	}
+	dpp, ppn, err := s.hitungPPN(pengaturan, items, persen)
+	return dpp, ppn, persen, err
+}
+
+// kreditPPNMasukan reports whether the PPN on a purchase dated tanggal is
+// PPN masukan. Only a PKP koperasi can credit it, from the date it became
+// PKP; otherwise the PPN is part of the cost of the goods.
+func kreditPPNMasukan(pengaturan *postgres.PengaturanPajak, tanggal time.Time) bool {
+	if !pengaturan.IsPKP {
+		return false
+	}
+	return pengaturan.TanggalPKP == nil || !tanggal.Before(*pengaturan.TanggalPKP)
+}
+
+// hitungPPN sums the lines whose product is BKP into the DPP and charges
+// persen on it, rounded down to the rupiah as on a faktur pajak.
+func (s *PajakService) hitungPPN(pengaturan *postgres.PengaturanPajak, items []itemPajak, persen float64) (money.Amount, money.Amount, error) {
+	nonBKP := kategoriNonBKP(pengaturan)
+
+	var dpp money.Amount
+	for _, item := range items {
+		produk, err := s.produkRepo.GetProdukByID(item.ProdukID)
+		if err != nil {
+			return 0, 0, fmt.Errorf("produk %d not found: %v", item.ProdukID, err)
+		}
+		if nonBKP[produk.KategoriProdukID] {
+			continue
+		}
+		dpp += item.Jumlah
+	}
+	if dpp <= 0 {
+		return 0, 0, nil
+	}
+	return dpp, dpp.Percent(persen, money.RoundPajak), nil
+}
+
+func kategoriNonBKP(pengaturan *postgres.PengaturanPajak) map[uint64]bool {
+	nonBKP := make(map[uint64]bool)
+	for _, kategori := range pengaturan.Kategori {
+		if kategori.KategoriPajak == KategoriPajakNonBKP {
+			nonBKP[kategori.KategoriProdukID] = true
+		}
+	}
+	return nonBKP
+}
+
+// hitungPPh returns the rate and PPh withheld on bruto. tarif overrides the
+// configured rate of jenis. Without an NPWP the rate is raised: PPh 23 by
+// 100%, PPh 21 by 20%.
+func (s *PajakService) hitungPPh(koperasiID uint64, jenis string, tarif float64, npwp string, bruto money.Amount) (float64, money.Amount, error) {
+	pengaturan, err := s.GetPengaturan(koperasiID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if tarif <= 0 {
+		switch jenis {
+		case JenisPPh21:
+			tarif = pengaturan.TarifPPh21
+		case JenisPPh23:
+			tarif = pengaturan.TarifPPh23
+		case JenisPPh4Ayat2:
+			tarif = pengaturan.TarifPPh4Ayat2
+		default:
+			return 0, 0, fmt.Errorf("unknown jenis pph %s", jenis)
+		}
+		if npwpAngka(npwp) == "" {
+			switch jenis {
+			case JenisPPh21:
+				tarif = tarif * 120 / 100
+			case JenisPPh23:
+				tarif = tarif * 2
+			}
+		}
+	}
+
+	return tarif, bruto.Percent(tarif, money.RoundPajak), nil
+}
+
+// potongPPh fills in the rate and amount of a withholding on
+// potongan.Bruto and records it inside tx. Nothing is recorded when no PPh
+// is due.
+func (s *PajakService) potongPPh(tx *gorm.DB, potongan *postgres.PotonganPajak, tarif float64) error {
+	tarif, pph, err := s.hitungPPh(potongan.KoperasiID, potongan.JenisPajak, tarif, potongan.NPWP, potongan.Bruto)
+	if err != nil {
+		return err
+	}
+	potongan.Tarif = tarif
+	potongan.JumlahPPh = pph
+	if pph <= 0 {
+		return nil
+	}
+	return s.catatPotongan(tx, potongan)
+}
+
+// pphBunga returns the PPh 4(2) withheld on an interest payment on simpanan,
+// which is only due above BatasBungaBebas.
+func (s *PajakService) pphBunga(koperasiID uint64, bunga money.Amount) (float64, money.Amount, string, error) {
+	pengaturan, err := s.GetPengaturan(koperasiID)
+	if err != nil {
+		return 0, 0, "", err
+	}
+	if bunga <= pengaturan.BatasBungaBebas {
+		return 0, 0, "", nil
+	}
+	return pengaturan.TarifPPh4Ayat2, bunga.Percent(pengaturan.TarifPPh4Ayat2, money.RoundPajak), pengaturan.KodeObjekBunga, nil
+}
+
+// catatPotongan records a withholding inside tx and gives it the next bukti
+// potong number of the koperasi.
+func (s *PajakService) catatPotongan(tx *gorm.DB, potongan *postgres.PotonganPajak) error {
+	number, err := s.sequenceService.GetNextNumber(1, potongan.KoperasiID, "bukti_potong")
+	if err != nil {
+		return fmt.Errorf("failed to generate nomor bukti potong: %v", err)
+	}
+	potongan.NomorBukti = fmt.Sprintf("BP%04d%08d", potongan.KoperasiID, number)
+
+	if err := s.pajakRepo.WithTx(tx).CreatePotongan(potongan); err != nil {
+		return fmt.Errorf("failed to record potongan pajak: %v", err)
+	}
+	return nil
+}
+
+func (s *PajakService) updatePotonganJurnal(tx *gorm.DB, id, jurnalID uint64) error {
+	return s.pajakRepo.WithTx(tx).UpdatePotonganJurnal(id, jurnalID)
+}
+
+// GetRekapPajak sums the PPN charged and paid and the PPh withheld in a
+// masa, and what is due to be paid over. The PPN lebih bayar recorded for
+// the previous masa is compensated against this one.
+func (s *PajakService) GetRekapPajak(koperasiID uint64, masaPajak string) (*RekapPajak, error) {
+	dari, sampai, err := parseMasaPajak(masaPajak)
+	if err != nil {
+		return nil, err
+	}
+	pengaturan, err := s.GetPengaturan(koperasiID)
+	if err != nil {
+		return nil, err
+	}
+
+	penjualan, err := s.produkRepo.GetPenjualanKenaPajak(koperasiID, dari, sampai)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load penjualan: %v", err)
+	}
+	pembelian, err := s.produkRepo.GetPembelianKenaPajak(koperasiID, dari, sampai)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pembelian: %v", err)
+	}
+	potongan, err := s.pajakRepo.GetPotonganByTanggal(koperasiID, dari, sampai)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load potongan pajak: %v", err)
+	}
+
+	rekap := &RekapPajak{KoperasiID: koperasiID, MasaPajak: masaPajak}
+	for _, p := range penjualan {
+		rekap.DPPKeluaran += p.DPP
+		rekap.PPNKeluaran += p.TotalPajak
+	}
+	for _, p := range pembelian {
+		if !kreditPPNMasukan(pengaturan, p.TanggalFaktur) {
+			continue
+		}
+		rekap.DPPMasukan += p.DPP
+		rekap.PPNMasukan += p.TotalPajak
+	}
+	for _, p := range potongan {
+		switch p.JenisPajak {
+		case JenisPPh21:
+			rekap.PPh21 += p.JumlahPPh
+		case JenisPPh23:
+			rekap.PPh23 += p.JumlahPPh
+		case JenisPPh4Ayat2:
+			rekap.PPh4Ayat2 += p.JumlahPPh
+		}
+	}
+
+	sebelumnya, err := s.pajakRepo.GetSetoran(koperasiID, dari.AddDate(0, -1, 0).Format("2006-01"))
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to load setoran pajak: %v", err)
+	}
+	if err == nil {
+		rekap.PPNKompensasi = sebelumnya.PPNLebihBayar
+	}
+
+	saldoPPN := rekap.PPNKeluaran - rekap.PPNMasukan - rekap.PPNKompensasi
+	rekap.PPNKurangBayar = money.Max(saldoPPN, 0)
+	rekap.PPNLebihBayar = money.Max(-saldoPPN, 0)
+	rekap.TotalSetor = rekap.PPNKurangBayar + rekap.PPh21 + rekap.PPh23 + rekap.PPh4Ayat2
+
+	setoran, err := s.pajakRepo.GetSetoran(koperasiID, masaPajak)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to load setoran pajak: %v", err)
+	}
+	if err == nil {
+		rekap.Setoran = setoran
+	}
+
+	return rekap, nil
+}
+
+// SetorPajak records paying over the tax of a masa and posts the journal
+// clearing the PPN and PPh payable accounts against kas. When PPN masukan
+// exceeds keluaran only the PPh is settled; the lebih bayar is recorded on
+// the setoran, even one with nothing to pay, and compensated in the rekap
+// of the next masa.
+func (s *PajakService) SetorPajak(req *SetorPajakRequest, createdBy uint64) (*postgres.SetoranPajak, error) {
+	rekap, err := s.GetRekapPajak(req.KoperasiID, req.MasaPajak)
+	if err != nil {
+		return nil, err
+	}
+	if rekap.Setoran != nil {
+		return nil, fmt.Errorf("masa pajak %s is already settled", req.MasaPajak)
+	}
+	if rekap.TotalSetor <= 0 && rekap.PPNLebihBayar <= 0 {
+		return nil, fmt.Errorf("no tax due for masa pajak %s", req.MasaPajak)
+	}
+
+	setoran := &postgres.SetoranPajak{
+		KoperasiID:    req.KoperasiID,
+		MasaPajak:     req.MasaPajak,
+		TanggalSetor:  req.TanggalSetor,
+		PPNKeluaran:   rekap.PPNKeluaran,
+		PPNMasukan:    rekap.PPNMasukan,
+		PPNKompensasi: rekap.PPNKompensasi,
+		PPNLebihBayar: rekap.PPNLebihBayar,
+		PPh21:         rekap.PPh21,
+		PPh23:         rekap.PPh23,
+		PPh4Ayat2:     rekap.PPh4Ayat2,
+		Total:         rekap.TotalSetor,
+		NTPN:          req.NTPN,
+		Keterangan:    req.Keterangan,
+		CreatedBy:     createdBy,
+	}
+
+	// PPN is cleared only when it is paid over. The masukan cleared then
+	// includes what earlier masa carried forward, which is still on the books.
+	var ppnKeluaran, ppnMasukan money.Amount
+	if setoran.PPNLebihBayar == 0 {
+		ppnKeluaran = setoran.PPNKeluaran
+		ppnMasukan = setoran.PPNMasukan + setoran.PPNKompensasi
+	}
+
+	err = s.pajakRepo.Transaction(func(tx *gorm.DB) error {
+		pajakRepo := s.pajakRepo.WithTx(tx)
+		if err := pajakRepo.CreateSetoran(setoran); err != nil {
+			return fmt.Errorf("failed to record setoran pajak: %v", err)
+		}
+
+		jurnal, err := s.postingService.Post(tx, &PostingRequest{
+			KoperasiID:       setoran.KoperasiID,
+			KodeEvent:        PostingEventSetoranPajak,
+			TanggalTransaksi: setoran.TanggalSetor,
+			Referensi:        setoran.NTPN,
+			Keterangan:       fmt.Sprintf("Setoran pajak masa %s", setoran.MasaPajak),
+			SumberTransaksi:  "setoran_pajak",
+			SumberID:         setoran.ID,
+			Komponen: map[string]money.Amount{
+				"total":        setoran.Total,
+				"ppn_keluaran": ppnKeluaran,
+				"ppn_masukan":  ppnMasukan,
+				JenisPPh21:     setoran.PPh21,
+				JenisPPh23:     setoran.PPh23,
+				JenisPPh4Ayat2: setoran.PPh4Ayat2,
+			},
+			CreatedBy: createdBy,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to post jurnal: %v", err)
+		}
+
+		if jurnal != nil {
+			setoran.JurnalID = jurnal.ID
+			return pajakRepo.UpdateSetoranJurnal(setoran.ID, jurnal.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return setoran, nil
+}
+
+// SetNomorFakturPajak records the nomor seri faktur pajak issued for a sale,
+// which the e-Faktur keluaran export needs.
+func (s *PajakService) SetNomorFakturPajak(penjualanID uint64, req *NomorFakturPajakRequest, updatedBy uint64) (*postgres.PenjualanHeader, error) {
+	nomor, err := nomorSeriFakturPajak(req.NomorFakturPajak)
+	if err != nil {
+		return nil, err
+	}
+
+	penjualan, err := s.produkRepo.GetPenjualanByID(penjualanID)
+	if err != nil {
+		return nil, fmt.Errorf("penjualan not found: %v", err)
+	}
+	if penjualan.KoperasiID != req.KoperasiID {
+		return nil, fmt.Errorf("penjualan %d does not belong to koperasi %d", penjualanID, req.KoperasiID)
+	}
+	if penjualan.TotalPajak <= 0 {
+		return nil, fmt.Errorf("penjualan %s carries no PPN", penjualan.NomorTransaksi)
+	}
+
+	if err := s.produkRepo.UpdatePenjualanFakturPajak(penjualanID, nomor, updatedBy); err != nil {
+		return nil, fmt.Errorf("failed to update penjualan: %v", err)
+	}
+	penjualan.NomorFakturPajak = nomor
+	return penjualan, nil
+}
+
+func (s *PajakService) GetPotonganPajak(koperasiID uint64, masaPajak string) ([]postgres.PotonganPajak, error) {
+	dari, sampai, err := parseMasaPajak(masaPajak)
+	if err != nil {
+		return nil, err
+	}
+	return s.pajakRepo.GetPotonganByTanggal(koperasiID, dari, sampai)
+}
+
+// ExportPajak builds the rows of a monthly export. Sales to buyers the
+// koperasi cannot identify are left out of the e-Faktur keluaran file; they
+// are reported as digunggung in the SPT.
+func (s *PajakService) ExportPajak(koperasiID uint64, masaPajak, jenis string) ([][]string, error) {
+	dari, sampai, err := parseMasaPajak(masaPajak)
+	if err != nil {
+		return nil, err
+	}
+	pengaturan, err := s.GetPengaturan(koperasiID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch jenis {
+	case ExportEFakturKeluaran:
+		penjualan, err := s.produkRepo.GetPenjualanKenaPajak(koperasiID, dari, sampai)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load penjualan: %v", err)
+		}
+		return eFakturKeluaranRows(pengaturan, penjualan)
+	case ExportEFakturMasukan:
+		pembelian, err := s.produkRepo.GetPembelianKenaPajak(koperasiID, dari, sampai)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load pembelian: %v", err)
+		}
+		return eFakturMasukanRows(pengaturan, pembelian), nil
+	case ExportEBupot:
+		potongan, err := s.pajakRepo.GetPotonganByTanggal(koperasiID, dari, sampai)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load potongan pajak: %v", err)
+		}
+		return eBupotRows(potongan), nil
+	}
+	return nil, fmt.Errorf("unknown export %s", jenis)
+}
+
+func (s *PajakService) WriteExportPajakCSV(w io.Writer, rows [][]string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// eFakturKeluaranRows refuses to build the file while any sale in it has no
+// nomor seri faktur pajak, which the import tool needs on every faktur.
+func eFakturKeluaranRows(pengaturan *postgres.PengaturanPajak, penjualan []postgres.PenjualanHeader) ([][]string, error) {
+	rows := [][]string{
+		{"FK", "KD_JENIS_TRANSAKSI", "FG_PENGGANTI", "NOMOR_FAKTUR", "MASA_PAJAK", "TAHUN_PAJAK", "TANGGAL_FAKTUR", "NPWP", "NAMA", "ALAMAT_LENGKAP", "JUMLAH_DPP", "JUMLAH_PPN", "JUMLAH_PPNBM", "ID_KETERANGAN_TAMBAHAN", "FG_UANG_MUKA", "UANG_MUKA_DPP", "UANG_MUKA_PPN", "UANG_MUKA_PPNBM", "REFERENSI", "KODE_DOKUMEN_PENDUKUNG"},
+		{"LT", "NPWP", "NAMA", "JALAN", "BLOK", "NOMOR", "RT", "RW", "KECAMATAN", "KELURAHAN", "KABUPATEN", "PROPINSI", "KODE_POS", "NOMOR_TELEPON"},
+		{"OF", "KODE_OBJEK", "NAMA", "HARGA_SATUAN", "JUMLAH_BARANG", "HARGA_TOTAL", "DISKON", "DPP", "PPN", "TARIF_PPNBM", "PPNBM"},
+	}
+	nonBKP := kategoriNonBKP(pengaturan)
+
+	var tanpaNomor []string
+	for _, p := range penjualan {
+		if p.AnggotaID == 0 || p.Anggota.NIK == "" {
+			continue
+		}
+		if p.NomorFakturPajak == "" {
+			tanpaNomor = append(tanpaNomor, p.NomorTransaksi)
+			continue
+		}
+
+		// A buyer without an NPWP is identified by NIK in the NAMA column.
+		rows = append(rows, []string{
+			"FK", "01", "0", p.NomorFakturPajak,
+			strconv.Itoa(int(p.TanggalTransaksi.Month())),
+			strconv.Itoa(p.TanggalTransaksi.Year()),
+			p.TanggalTransaksi.Format("02/01/2006"),
+			"000000000000000",
+			fmt.Sprintf("%s#NIK#NAMA#%s", p.Anggota.NIK, p.Anggota.Nama),
+			p.Anggota.Alamat,
+			rupiahPajak(p.DPP),
+			rupiahPajak(p.TotalPajak),
+			"0", "", "0", "0", "0", "0",
+			p.NomorTransaksi,
+			"",
+		})
+		for _, d := range p.PenjualanDetail {
+			if nonBKP[d.Produk.KategoriProdukID] {
+				continue
+			}
+			harga := d.HargaSatuan.MulInt(int64(d.Qty))
+			rows = append(rows, []string{
+				"OF",
+				d.Produk.KodeProduk,
+				d.Produk.NamaProduk,
+				rupiahPajak(d.HargaSatuan),
+				strconv.Itoa(d.Qty),
+				rupiahPajak(harga),
+				rupiahPajak(harga - d.Subtotal),
+				rupiahPajak(d.Subtotal),
+				rupiahPajak(d.Subtotal.Percent(p.PajakPersen, money.RoundPajak)),
+				"0", "0",
+			})
+		}
+	}
+	if len(tanpaNomor) > 0 {
+		return nil, fmt.Errorf("penjualan without nomor faktur pajak: %s", strings.Join(tanpaNomor, ", "))
+	}
+	return rows, nil
+}
+
+func eFakturMasukanRows(pengaturan *postgres.PengaturanPajak, pembelian []postgres.PembelianHeader) [][]string {
+	rows := [][]string{
+		{"FM", "KD_JENIS_TRANSAKSI", "FG_PENGGANTI", "NOMOR_FAKTUR", "MASA_PAJAK", "TAHUN_PAJAK", "TANGGAL_FAKTUR", "NPWP", "NAMA", "ALAMAT_LENGKAP", "JUMLAH_DPP", "JUMLAH_PPN", "JUMLAH_PPNBM", "IS_CREDITABLE"},
+	}
+	creditable := "0"
+	if pengaturan.IsPKP {
+		creditable = "1"
+	}
+
+	for _, p := range pembelian {
+		rows = append(rows, []string{
+			"FM", "01", "0",
+			npwpAngka(p.NomorFakturPajak),
+			strconv.Itoa(int(p.TanggalFaktur.Month())),
+			strconv.Itoa(p.TanggalFaktur.Year()),
+			p.TanggalFaktur.Format("02/01/2006"),
+			npwpAngka(p.Supplier.NPWP),
+			p.Supplier.Nama,
+			p.Supplier.Alamat,
+			rupiahPajak(p.DPP),
+			rupiahPajak(p.TotalPajak),
+			"0",
+			creditable,
+		})
+	}
+	return rows
+}
+
+func eBupotRows(potongan []postgres.PotonganPajak) [][]string {
+	rows := [][]string{
+		{"MASA_PAJAK", "TAHUN_PAJAK", "NOMOR_BUKTI_POTONG", "TANGGAL_BUKTI_POTONG", "JENIS_PAJAK", "KODE_OBJEK_PAJAK", "NPWP", "NIK", "NAMA", "ALAMAT", "PENGHASILAN_BRUTO", "TARIF", "PPH_DIPOTONG", "DOKUMEN_REFERENSI"},
+	}
+	for _, p := range potongan {
+		rows = append(rows, []string{
+			strconv.Itoa(int(p.TanggalPotong.Month())),
+			strconv.Itoa(p.TanggalPotong.Year()),
+			p.NomorBukti,
+			p.TanggalPotong.Format("02/01/2006"),
+			p.JenisPajak,
+			p.KodeObjekPajak,
+			npwpAngka(p.NPWP),
+			p.NIK,
+			p.NamaPenerima,
+			p.Alamat,
+			rupiahPajak(p.Bruto),
+			strconv.FormatFloat(p.Tarif, 'f', -1, 64),
+			rupiahPajak(p.JumlahPPh),
+			fmt.Sprintf("%s/%d", p.SumberTransaksi, p.SumberID),
+		})
+	}
+	return rows
+}
+
+// rupiahPajak prints whole rupiah, the unit DJP files are in.
+func rupiahPajak(a money.Amount) string {
+	return strconv.FormatInt(a.Rupiah(), 10)
+}
+
+// npwpAngka strips an NPWP or faktur number down to its digits.
+func npwpAngka(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// nomorSeriFakturPajak reads an NSFP, as issued by DJP with or without its
+// kode transaksi prefix, into the 13 digits the e-Faktur file carries.
+func nomorSeriFakturPajak(s string) (string, error) {
+	nomor := npwpAngka(s)
+	if len(nomor) == 16 {
+		nomor = nomor[3:]
+	}
+	if len(nomor) != 13 {
+		return "", fmt.Errorf("invalid nomor faktur pajak %q", s)
+	}
+	return nomor, nil
+}
+
+// parseMasaPajak reads a masa such as "2026-01" into its first day and the
+// first day of the next month.
+func parseMasaPajak(masaPajak string) (time.Time, time.Time, error) {
+	dari, err := time.Parse("2006-01", masaPajak)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid masa pajak %q, expected YYYY-MM", masaPajak)
+	}
+	return dari, dari.AddDate(0, 1, 0), nil
+}
+
+// Request/Response structs
+type PengaturanPajakRequest struct {
+	KoperasiID      uint64                 `json:"koperasi_id" binding:"required"`
+	IsPKP           bool                   `json:"is_pkp"`
+	NPWP            string                 `json:"npwp"`
+	TanggalPKP      *time.Time             `json:"tanggal_pkp"`
+	TarifPPN        float64                `json:"tarif_ppn" binding:"min=0,max=100"`
+	TarifPPh21      float64                `json:"tarif_pph21" binding:"min=0,max=100"`
+	TarifPPh23      float64                `json:"tarif_pph23" binding:"min=0,max=100"`
+	TarifPPh4Ayat2  float64                `json:"tarif_pph4_ayat2" binding:"min=0,max=100"`
+	BatasBungaBebas money.Amount           `json:"batas_bunga_bebas" binding:"min=0"`
+	KodeObjekBunga  string                 `json:"kode_objek_bunga"`
+	Kategori        []KategoriPajakRequest `json:"kategori" binding:"omitempty,dive"`
+}
+
+type KategoriPajakRequest struct {
+	KategoriProdukID uint64 `json:"kategori_produk_id" binding:"required"`
+	KategoriPajak    string `json:"kategori_pajak" binding:"required,oneof=bkp non_bkp"`
+}
+
+type SetorPajakRequest struct {
+	KoperasiID   uint64    `json:"koperasi_id" binding:"required"`
+	MasaPajak    string    `json:"masa_pajak" binding:"required"`
+	TanggalSetor time.Time `json:"tanggal_setor" binding:"required"`
+	NTPN         string    `json:"ntpn"`
+	Keterangan   string    `json:"keterangan"`
+}
+
+type NomorFakturPajakRequest struct {
+	KoperasiID       uint64 `json:"koperasi_id" binding:"required"`
+	NomorFakturPajak string `json:"nomor_faktur_pajak" binding:"required"`
+}
+
+// PotongPPhRequest withholds PPh on a payment. Tarif overrides the
+// configured rate; Bruto overrides the base derived from the payment.
+type PotongPPhRequest struct {
+	Jenis          string       `json:"jenis" binding:"required,oneof=pph21 pph23 pph4_2"`
+	KodeObjekPajak string       `json:"kode_objek_pajak"`
+	Tarif          float64      `json:"tarif" binding:"min=0,max=100"`
+	Bruto          money.Amount `json:"bruto" binding:"min=0"`
+}
+
+type RekapPajak struct {
+	KoperasiID     uint64                 `json:"koperasi_id"`
+	MasaPajak      string                 `json:"masa_pajak"`
+	DPPKeluaran    money.Amount           `json:"dpp_keluaran"`
+	PPNKeluaran    money.Amount           `json:"ppn_keluaran"`
+	DPPMasukan     money.Amount           `json:"dpp_masukan"`
+	PPNMasukan     money.Amount           `json:"ppn_masukan"`
+	PPNKompensasi  money.Amount           `json:"ppn_kompensasi"`
+	PPNKurangBayar money.Amount           `json:"ppn_kurang_bayar"`
+	PPNLebihBayar  money.Amount           `json:"ppn_lebih_bayar"`
+	PPh21          money.Amount           `json:"pph21"`
+	PPh23          money.Amount           `json:"pph23"`
+	PPh4Ayat2      money.Amount           `json:"pph4_ayat2"`
+	TotalSetor     money.Amount           `json:"total_setor"`
+	Setoran        *postgres.SetoranPajak `json:"setoran"`
+}
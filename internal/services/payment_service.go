@@ -6,12 +6,20 @@ import (
 	"time"
 
 	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
 	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
 )
 
+// PaymentRefunder is the part of a payment gateway that returns captured
+// money. The returned string is the gateway's refund reference.
+type PaymentRefunder interface {
+	Refund(payment *postgres.PaymentTransaction, amount money.Amount, refundKey, alasan string) (string, error)
+}
+
 type PaymentService struct {
 	paymentRepo         *postgresRepo.PaymentRepository
 	paymentProviderRepo *postgresRepo.PaymentProviderRepository
+	refunders           map[string]PaymentRefunder
 	sequenceService     *SequenceService
 }
 
@@ -19,17 +27,27 @@ func NewPaymentService(
 	paymentRepo *postgresRepo.PaymentRepository,
 	paymentProviderRepo *postgresRepo.PaymentProviderRepository,
 	sequenceService *SequenceService,
+	refunders map[string]PaymentRefunder,
 ) *PaymentService {
 	return &PaymentService{
 		paymentRepo:         paymentRepo,
 		paymentProviderRepo: paymentProviderRepo,
+		refunders:           refunders,
 		sequenceService:     sequenceService,
 	}
 }
 
+// CreatePayment takes the provider by ProviderID when it is set and by
+// ProviderCode otherwise.
 func (s *PaymentService) CreatePayment(req *CreatePaymentRequest) (*postgres.PaymentTransaction, error) {
-	provider, err := s.paymentProviderRepo.GetByCode(req.ProviderCode)
-	if err != nil && req.ProviderID == 0 {
+	var provider *postgres.PaymentProvider
+	var err error
+	if req.ProviderID != 0 {
+		provider, err = s.paymentProviderRepo.GetByID(req.ProviderID)
+	} else {
+		provider, err = s.paymentProviderRepo.GetByCode(req.ProviderCode)
+	}
+	if err != nil {
 		return nil, fmt.Errorf("payment provider not found: %v", err)
 	}
 
@@ -37,9 +55,12 @@ func (s *PaymentService) CreatePayment(req *CreatePaymentRequest) (*postgres.Pay
 	if err != nil {
 		return nil, fmt.Errorf("payment method not found: %v", err)
 	}
+	if method.ProviderID != provider.ID {
+		return nil, fmt.Errorf("payment method %s does not belong to provider %s", method.Kode, provider.Kode)
+	}
 
 	if req.Amount < method.MinimalAmount || (method.MaksimalAmount > 0 && req.Amount > method.MaksimalAmount) {
-		return nil, fmt.Errorf("amount out of range: min %.2f, max %.2f", method.MinimalAmount, method.MaksimalAmount)
+		return nil, fmt.Errorf("amount out of range: min %s, max %s", method.MinimalAmount, method.MaksimalAmount)
 	}
 
 	adminFee := s.calculateAdminFee(provider, req.Amount)
@@ -152,6 +173,52 @@ func (s *PaymentService) HandleCallback(providerCode string, callbackData map[st
 	return nil
 }
 
+func (s *PaymentService) GetPaymentByNomor(nomor string) (*postgres.PaymentTransaction, error) {
+	return s.paymentRepo.GetTransactionByNomor(nomor)
+}
+
+// RefundPayment returns amount of a paid transaction through the gateway
+// that collected it, keyed by the provider code. A failed attempt is kept on
+// the transaction so it can be retried with the same refundKey.
+func (s *PaymentService) RefundPayment(paymentID uint64, amount money.Amount, refundKey, alasan string) (*postgres.PaymentTransaction, error) {
+	payment, err := s.paymentRepo.GetTransactionByID(paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("payment transaction not found: %v", err)
+	}
+
+	if payment.RefundStatus == "success" {
+		return payment, nil
+	}
+	if payment.Status != "paid" {
+		return nil, fmt.Errorf("only paid transactions can be refunded")
+	}
+	if amount > payment.TotalAmount {
+		return nil, fmt.Errorf("refund amount exceeds the paid amount")
+	}
+
+	refunder, ok := s.refunders[payment.Provider.Kode]
+	if !ok {
+		return nil, fmt.Errorf("no refund gateway configured for provider %s", payment.Provider.Kode)
+	}
+
+	reference, err := refunder.Refund(payment, amount, refundKey, alasan)
+	if err != nil {
+		s.paymentRepo.UpdateTransactionRefund(payment.ID, "failed", amount, "", nil)
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.paymentRepo.UpdateTransactionRefund(payment.ID, "success", amount, reference, &now); err != nil {
+		return nil, fmt.Errorf("failed to update payment refund: %v", err)
+	}
+
+	payment.RefundStatus = "success"
+	payment.RefundAmount = amount
+	payment.RefundReference = reference
+	payment.RefundedAt = &now
+	return payment, nil
+}
+
 func (s *PaymentService) ProcessExpiredPayments() error {
 	expiredPayments, err := s.paymentRepo.GetExpiredTransactions()
 	if err != nil {
@@ -168,14 +235,14 @@ func (s *PaymentService) ProcessExpiredPayments() error {
 	return nil
 }
 
-func (s *PaymentService) calculateAdminFee(provider *postgres.PaymentProvider, amount float64) float64 {
+func (s *PaymentService) calculateAdminFee(provider *postgres.PaymentProvider, amount money.Amount) money.Amount {
 	switch provider.FeeType {
 	case "fixed":
 		return provider.FeeAmount
 	case "percentage":
-		return amount * provider.FeePercentage / 100
+		return amount.Percent(provider.FeePercentage, money.RoundSen)
 	case "both":
-		return provider.FeeAmount + (amount * provider.FeePercentage / 100)
+		return provider.FeeAmount + amount.Percent(provider.FeePercentage, money.RoundSen)
 	default:
 		return 0
 	}
@@ -229,7 +296,7 @@ type CreatePaymentRequest struct {
 	ProviderID      uint64  `json:"provider_id"`
 	ProviderCode    string  `json:"provider_code"`
 	MethodID        uint64  `json:"method_id"`
-	Amount          float64 `json:"amount"`
+	Amount          money.Amount `json:"amount"`
 	CustomerName    string  `json:"customer_name"`
 	CustomerEmail   string  `json:"customer_email"`
 	CustomerPhone   string  `json:"customer_phone"`
@@ -0,0 +1,421 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+)
+
+type PeriodeService struct {
+	periodeRepo      *postgresRepo.PeriodeRepository
+	financialRepo    *postgresRepo.FinancialRepository
+	financialService *FinancialService
+}
+
+func NewPeriodeService(
+	periodeRepo *postgresRepo.PeriodeRepository,
+	financialRepo *postgresRepo.FinancialRepository,
+	financialService *FinancialService,
+) *PeriodeService {
+	return &PeriodeService{
+		periodeRepo:      periodeRepo,
+		financialRepo:    financialRepo,
+		financialService: financialService,
+	}
+}
+
+// GeneratePeriode creates the twelve monthly periods of a fiscal year. Months
+// that already exist are left untouched.
+func (s *PeriodeService) GeneratePeriode(req *GeneratePeriodeRequest) ([]postgres.PeriodeAkuntansi, error) {
+	err := s.periodeRepo.Transaction(func(tx *gorm.DB) error {
+		return s.generatePeriode(tx, req.TenantID, req.KoperasiID, req.Tahun)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.periodeRepo.GetPeriodeByKoperasi(req.KoperasiID, req.Tahun)
+}
+
+func (s *PeriodeService) generatePeriode(tx *gorm.DB, tenantID, koperasiID uint64, tahun int) error {
+	periodeRepo := s.periodeRepo.WithTx(tx)
+
+	existing, err := periodeRepo.GetPeriodeByKoperasi(koperasiID, tahun)
+	if err != nil {
+		return fmt.Errorf("failed to load periode: %v", err)
+	}
+
+	ada := make(map[int]bool)
+	for _, periode := range existing {
+		ada[periode.Bulan] = true
+	}
+
+	for bulan := 1; bulan <= 12; bulan++ {
+		if ada[bulan] {
+			continue
+		}
+
+		mulai := time.Date(tahun, time.Month(bulan), 1, 0, 0, 0, 0, time.Local)
+		periode := &postgres.PeriodeAkuntansi{
+			TenantID:       tenantID,
+			KoperasiID:     koperasiID,
+			Tahun:          tahun,
+			Bulan:          bulan,
+			TanggalMulai:   mulai,
+			TanggalSelesai: mulai.AddDate(0, 1, -1),
+			Status:         "open",
+		}
+		if err := periodeRepo.CreatePeriode(periode); err != nil {
+			return fmt.Errorf("failed to create periode %02d/%d: %v", bulan, tahun, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *PeriodeService) GetPeriodeList(koperasiID uint64, tahun int) ([]postgres.PeriodeAkuntansi, error) {
+	return s.periodeRepo.GetPeriodeByKoperasi(koperasiID, tahun)
+}
+
+func (s *PeriodeService) ClosePeriode(id uint64, closedBy uint64) error {
+	periode, err := s.periodeRepo.GetPeriodeByID(id)
+	if err != nil {
+		return fmt.Errorf("periode not found: %v", err)
+	}
+
+	if periode.Status != "open" {
+		return fmt.Errorf("only open periods can be closed")
+	}
+
+	return s.periodeRepo.UpdatePeriodeStatus(id, "closed", closedBy)
+}
+
+func (s *PeriodeService) RequestReopen(id uint64, req *ReopenPeriodeRequest, requestedBy uint64) (*postgres.PeriodeReopenRequest, error) {
+	periode, err := s.periodeRepo.GetPeriodeByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("periode not found: %v", err)
+	}
+
+	switch periode.Status {
+	case "open":
+		return nil, fmt.Errorf("periode is already open")
+	case "locked":
+		return nil, fmt.Errorf("periode is locked by the year-end close and cannot be reopened")
+	}
+
+	pending, err := s.periodeRepo.CountPendingReopenRequests(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check reopen requests: %v", err)
+	}
+	if pending > 0 {
+		return nil, fmt.Errorf("periode already has a pending reopen request")
+	}
+
+	reopen := &postgres.PeriodeReopenRequest{
+		PeriodeID:   id,
+		Alasan:      req.Alasan,
+		Status:      "pending",
+		RequestedBy: requestedBy,
+	}
+
+	err = s.periodeRepo.CreateReopenRequest(reopen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reopen request: %v", err)
+	}
+
+	return reopen, nil
+}
+
+func (s *PeriodeService) GetReopenRequestList(koperasiID uint64, status string) ([]postgres.PeriodeReopenRequest, error) {
+	return s.periodeRepo.GetReopenRequestsByKoperasi(koperasiID, status)
+}
+
+// ReviewReopen approves or rejects a reopen request. The reviewer must be a
+// different user from the requester, and every decision is written to the
+// audit log together with the period status before and after.
+func (s *PeriodeService) ReviewReopen(requestID uint64, req *ReviewReopenRequest, reviewedBy uint64) (*postgres.PeriodeReopenRequest, error) {
+	reopen, err := s.periodeRepo.GetReopenRequestByID(requestID)
+	if err != nil {
+		return nil, fmt.Errorf("reopen request not found: %v", err)
+	}
+
+	if reopen.Status != "pending" {
+		return nil, fmt.Errorf("reopen request has already been reviewed")
+	}
+	if reopen.RequestedBy == reviewedBy {
+		return nil, fmt.Errorf("reopen request must be approved by another user")
+	}
+
+	periode := reopen.Periode
+	if periode.Status == "locked" {
+		return nil, fmt.Errorf("periode is locked by the year-end close and cannot be reopened")
+	}
+
+	now := time.Now()
+	reopen.ReviewedBy = reviewedBy
+	reopen.ReviewedAt = &now
+	reopen.CatatanReview = req.Catatan
+	reopen.Status = "rejected"
+	if req.Approve {
+		reopen.Status = "approved"
+	}
+
+	oldValues, _ := json.Marshal(map[string]interface{}{
+		"status": periode.Status,
+	})
+	newStatus := periode.Status
+	if req.Approve {
+		newStatus = "open"
+	}
+	newValues, _ := json.Marshal(map[string]interface{}{
+		"status":            newStatus,
+		"reopen_request_id": reopen.ID,
+		"reopen_status":     reopen.Status,
+		"alasan":            reopen.Alasan,
+		"requested_by":      reopen.RequestedBy,
+		"catatan_review":    reopen.CatatanReview,
+	})
+
+	err = s.periodeRepo.Transaction(func(tx *gorm.DB) error {
+		periodeRepo := s.periodeRepo.WithTx(tx)
+
+		reviewed, err := periodeRepo.ReviewReopenRequest(reopen)
+		if err != nil {
+			return fmt.Errorf("failed to update reopen request: %v", err)
+		}
+		if !reviewed {
+			return fmt.Errorf("reopen request has already been reviewed")
+		}
+
+		if req.Approve {
+			reopened, err := periodeRepo.UpdatePeriodeStatusFrom(periode.ID, "closed", "open", reviewedBy)
+			if err != nil {
+				return fmt.Errorf("failed to reopen periode: %v", err)
+			}
+			if !reopened {
+				return fmt.Errorf("periode is no longer closed and cannot be reopened")
+			}
+		}
+
+		return periodeRepo.CreateAuditLog(&postgres.AuditLog{
+			TenantID:   periode.TenantID,
+			KoperasiID: periode.KoperasiID,
+			UserID:     reviewedBy,
+			TableName:  "periode_akuntansis",
+			RecordID:   periode.ID,
+			Action:     "update",
+			OldValues:  string(oldValues),
+			NewValues:  string(newValues),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reopen, nil
+}
+
+// TutupBuku runs the year-end close. Every month of the year must already be
+// closed. Revenue and expense balances are moved into the SHU tahun berjalan
+// account with a closing journal dated the last day of the year, balance-sheet
+// balances are carried forward as the next year's opening balances, and the
+// year's periods are locked.
+func (s *PeriodeService) TutupBuku(req *TutupBukuRequest, closedBy uint64) (*postgres.TutupBuku, error) {
+	if _, err := s.periodeRepo.GetTutupBuku(req.KoperasiID, req.Tahun); err == nil {
+		return nil, fmt.Errorf("tahun buku %d has already been closed", req.Tahun)
+	}
+
+	periodes, err := s.periodeRepo.GetPeriodeByKoperasi(req.KoperasiID, req.Tahun)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load periode: %v", err)
+	}
+	if len(periodes) != 12 {
+		return nil, fmt.Errorf("tahun buku %d does not have 12 periods", req.Tahun)
+	}
+	for _, periode := range periodes {
+		if periode.Status != "closed" {
+			return nil, fmt.Errorf("periode %02d/%d must be closed before the year-end close", periode.Bulan, periode.Tahun)
+		}
+	}
+
+	akunSHU, err := s.financialRepo.GetCOAAkunByID(req.AkunSHUID)
+	if err != nil {
+		return nil, fmt.Errorf("akun SHU not found: %v", err)
+	}
+	if akunSHU.KoperasiID != req.KoperasiID || akunSHU.Kategori.Tipe != "ekuitas" {
+		return nil, fmt.Errorf("akun SHU must be an ekuitas akun of the koperasi")
+	}
+
+	awalTahun := time.Date(req.Tahun, 1, 1, 0, 0, 0, 0, time.Local)
+	akhirTahun := time.Date(req.Tahun, 12, 31, 23, 59, 59, 0, time.Local)
+
+	mutasi, err := s.financialRepo.GetMutasiAkun(req.KoperasiID, awalTahun, akhirTahun)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate balances: %v", err)
+	}
+
+	var details []postgres.JurnalDetail
+	var totalPendapatan, totalBeban money.Amount
+	for _, item := range mutasi {
+		if item.KategoriTipe != "pendapatan" && item.KategoriTipe != "beban" {
+			continue
+		}
+
+		// Net debit balance is closed with a kredit and vice versa.
+		net := item.TotalDebit - item.TotalKredit
+		if net == 0 {
+			continue
+		}
+
+		detail := postgres.JurnalDetail{
+			AkunID:     item.AkunID,
+			Keterangan: "Tutup buku " + item.NamaAkun,
+		}
+		if net > 0 {
+			detail.Kredit = net
+		} else {
+			detail.Debit = -net
+		}
+		details = append(details, detail)
+
+		if item.KategoriTipe == "pendapatan" {
+			totalPendapatan -= net
+		} else {
+			totalBeban += net
+		}
+	}
+
+	shu := totalPendapatan - totalBeban
+	if shu != 0 {
+		detail := postgres.JurnalDetail{
+			AkunID:     akunSHU.ID,
+			Keterangan: fmt.Sprintf("SHU tahun berjalan %d", req.Tahun),
+		}
+		if shu > 0 {
+			detail.Kredit = shu
+		} else {
+			detail.Debit = -shu
+		}
+		details = append(details, detail)
+	}
+
+	tutupBuku := &postgres.TutupBuku{
+		TenantID:         akunSHU.TenantID,
+		KoperasiID:       req.KoperasiID,
+		Tahun:            req.Tahun,
+		AkunSHUID:        akunSHU.ID,
+		TotalPendapatan:  totalPendapatan,
+		TotalBeban:       totalBeban,
+		SHUTahunBerjalan: shu,
+		ClosedBy:         closedBy,
+	}
+
+	err = s.periodeRepo.Transaction(func(tx *gorm.DB) error {
+		periodeRepo := s.periodeRepo.WithTx(tx)
+
+		if err := periodeRepo.CreateTutupBuku(tutupBuku); err != nil {
+			return fmt.Errorf("failed to create tutup buku: %v", err)
+		}
+
+		if len(details) > 0 {
+			now := time.Now()
+			jurnal := &postgres.JurnalUmum{
+				TenantID:         tutupBuku.TenantID,
+				KoperasiID:       req.KoperasiID,
+				TanggalTransaksi: time.Date(req.Tahun, 12, 31, 0, 0, 0, 0, time.Local),
+				Referensi:        fmt.Sprintf("TUTUP-BUKU-%d", req.Tahun),
+				Keterangan:       fmt.Sprintf("Tutup buku tahun %d", req.Tahun),
+				Status:           "posted",
+				SumberTransaksi:  "tutup_buku",
+				SumberID:         tutupBuku.ID,
+				CreatedBy:        closedBy,
+				PostedAt:         &now,
+				PostedBy:         closedBy,
+			}
+
+			// The periods are already closed, so bypass the period check.
+			if err := s.financialService.insertJurnal(tx, jurnal, details); err != nil {
+				return err
+			}
+
+			tutupBuku.JurnalID = jurnal.ID
+			if err := periodeRepo.UpdateTutupBukuJurnal(tutupBuku.ID, jurnal.ID); err != nil {
+				return fmt.Errorf("failed to link tutup buku jurnal: %v", err)
+			}
+		}
+
+		saldoAkhir, err := s.financialRepo.WithTx(tx).GetMutasiAkun(req.KoperasiID, time.Time{}, akhirTahun)
+		if err != nil {
+			return fmt.Errorf("failed to calculate closing balances: %v", err)
+		}
+
+		var saldoAwal []postgres.SaldoAwalAkun
+		for _, item := range saldoAkhir {
+			if item.KategoriTipe != "aset" && item.KategoriTipe != "kewajiban" && item.KategoriTipe != "ekuitas" {
+				continue
+			}
+
+			saldo := item.Saldo()
+			if saldo == 0 {
+				continue
+			}
+
+			saldoAwal = append(saldoAwal, postgres.SaldoAwalAkun{
+				KoperasiID:  req.KoperasiID,
+				AkunID:      item.AkunID,
+				Tahun:       req.Tahun + 1,
+				Saldo:       saldo,
+				TutupBukuID: tutupBuku.ID,
+			})
+		}
+
+		if err := periodeRepo.CreateSaldoAwal(saldoAwal); err != nil {
+			return fmt.Errorf("failed to carry forward balances: %v", err)
+		}
+
+		if err := periodeRepo.LockPeriodeTahun(req.KoperasiID, req.Tahun, closedBy); err != nil {
+			return fmt.Errorf("failed to lock periode: %v", err)
+		}
+
+		return s.generatePeriode(tx, tutupBuku.TenantID, req.KoperasiID, req.Tahun+1)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tutupBuku, nil
+}
+
+func (s *PeriodeService) GetTutupBuku(koperasiID uint64, tahun int) (*postgres.TutupBuku, error) {
+	return s.periodeRepo.GetTutupBuku(koperasiID, tahun)
+}
+
+func (s *PeriodeService) GetSaldoAwal(koperasiID uint64, tahun int) ([]postgres.SaldoAwalAkun, error) {
+	return s.periodeRepo.GetSaldoAwal(koperasiID, tahun)
+}
+
+type GeneratePeriodeRequest struct {
+	TenantID   uint64 `json:"tenant_id" binding:"required"`
+	KoperasiID uint64 `json:"koperasi_id" binding:"required"`
+	Tahun      int    `json:"tahun" binding:"required,min=2000"`
+}
+
+type ReopenPeriodeRequest struct {
+	Alasan string `json:"alasan" binding:"required"`
+}
+
+type ReviewReopenRequest struct {
+	Approve bool   `json:"approve"`
+	Catatan string `json:"catatan" binding:"required"`
+}
+
+type TutupBukuRequest struct {
+	KoperasiID uint64 `json:"koperasi_id" binding:"required"`
+	Tahun      int    `json:"tahun" binding:"required,min=2000"`
+	AkunSHUID  uint64 `json:"akun_shu_id" binding:"required"`
+}
@@ -0,0 +1,442 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+)
+
+type PiutangService struct {
+	produkRepo          *postgresRepo.ProdukRepository
+	anggotaRepo         *postgresRepo.AnggotaKoperasiRepository
+	postingService      *PostingService
+	simpanPinjamService *SimpanPinjamService
+	sequenceService     *SequenceService
+}
+
+func NewPiutangService(
+	produkRepo *postgresRepo.ProdukRepository,
+	anggotaRepo *postgresRepo.AnggotaKoperasiRepository,
+	postingService *PostingService,
+	simpanPinjamService *SimpanPinjamService,
+	sequenceService *SequenceService,
+) *PiutangService {
+	return &PiutangService{
+		produkRepo:          produkRepo,
+		anggotaRepo:         anggotaRepo,
+		postingService:      postingService,
+		simpanPinjamService: simpanPinjamService,
+		sequenceService:     sequenceService,
+	}
+}
+
+// BayarPiutang records one collection from a member against their credit
+// sales. The amount is split over the sales listed in Alokasi or, without it,
+// over the open sales in order of due date. A simpanan collection debits the
+// member's savings account. One journal is posted for the whole collection.
+func (s *PiutangService) BayarPiutang(req *BayarPiutangRequest, createdBy uint64) (*PembayaranPiutang, error) {
+	anggota, err := s.anggotaRepo.GetByID(req.AnggotaID)
+	if err != nil {
+		return nil, fmt.Errorf("anggota not found: %v", err)
+	}
+	if anggota.KoperasiID != req.KoperasiID {
+		return nil, fmt.Errorf("anggota belongs to another koperasi")
+	}
+	if len(req.Alokasi) == 0 && req.Jumlah <= 0 {
+		return nil, fmt.Errorf("set jumlah or alokasi")
+	}
+	if req.MetodePembayaran == "simpanan" {
+		if req.RekeningSimpananID == 0 {
+			return nil, fmt.Errorf("rekening simpanan is required for a simpanan payment")
+		}
+		if err := s.simpanPinjamService.cekRekeningAnggota(req.RekeningSimpananID, anggota.ID, req.KoperasiID); err != nil {
+			return nil, err
+		}
+	}
+
+	nomor, err := s.generateNomorPembayaran(req.KoperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate nomor pembayaran: %v", err)
+	}
+
+	result := &PembayaranPiutang{
+		NomorPembayaran: nomor,
+		AnggotaID:       anggota.ID,
+		TanggalBayar:    req.TanggalBayar,
+		Pembayaran:      []postgres.PembayaranPenjualan{},
+	}
+
+	err = s.produkRepo.Transaction(func(tx *gorm.DB) error {
+		produkRepo := s.produkRepo.WithTx(tx)
+
+		alokasi, penjualans, err := s.alokasiPembayaran(produkRepo, req, anggota)
+		if err != nil {
+			return err
+		}
+
+		for i, jumlah := range alokasi {
+			penjualan := penjualans[i]
+
+			pembayaran := postgres.PembayaranPenjualan{
+				PenjualanHeaderID:  penjualan.ID,
+				NomorPembayaran:    nomor,
+				TanggalBayar:       req.TanggalBayar,
+				JumlahBayar:        jumlah,
+				MetodePembayaran:   req.MetodePembayaran,
+				RekeningSimpananID: req.RekeningSimpananID,
+				NomorReferensi:     req.NomorReferensi,
+				Keterangan:         req.Keterangan,
+				CreatedBy:          createdBy,
+			}
+			if err := produkRepo.CreatePembayaranPenjualan(&pembayaran); err != nil {
+				return fmt.Errorf("failed to record pembayaran: %v", err)
+			}
+
+			totalTerbayar := penjualan.TotalTerbayar + jumlah
+			status := "partial"
+			if totalTerbayar >= penjualan.GrandTotal {
+				status = "paid"
+			}
+			if err := produkRepo.UpdatePenjualanPembayaran(penjualan.ID, totalTerbayar, status, createdBy); err != nil {
+				return fmt.Errorf("failed to update penjualan %s: %v", penjualan.NomorTransaksi, err)
+			}
+
+			result.Total += jumlah
+			result.Pembayaran = append(result.Pembayaran, pembayaran)
+		}
+
+		if req.MetodePembayaran == "simpanan" {
+			_, err := s.simpanPinjamService.mutasiSimpanan(tx, req.RekeningSimpananID, "penarikan", result.Total,
+				fmt.Sprintf("Pelunasan piutang %s", nomor), nomor, createdBy)
+			if err != nil {
+				return fmt.Errorf("failed to debit simpanan: %v", err)
+			}
+		}
+
+		jurnal, err := s.postingService.Post(tx, &PostingRequest{
+			KoperasiID:       req.KoperasiID,
+			KodeEvent:        PostingEventPelunasanPiutang,
+			TanggalTransaksi: req.TanggalBayar,
+			Referensi:        nomor,
+			Keterangan:       fmt.Sprintf("Pelunasan piutang %s", anggota.Nama),
+			SumberTransaksi:  "pembayaran_penjualan",
+			SumberID:         result.Pembayaran[0].ID,
+			AnggotaID:        anggota.ID,
+			Komponen: map[string]money.Amount{
+				"total":              result.Total,
+				req.MetodePembayaran: result.Total,
+			},
+			CreatedBy: createdBy,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to post jurnal: %v", err)
+		}
+
+		if jurnal != nil {
+			result.JurnalID = jurnal.ID
+			return produkRepo.UpdatePembayaranPenjualanJurnal(nomor, jurnal.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// alokasiPembayaran returns the amount collected on each sale, index aligned
+// with the locked sales.
+func (s *PiutangService) alokasiPembayaran(produkRepo *postgresRepo.ProdukRepository, req *BayarPiutangRequest, anggota *postgres.AnggotaKoperasi) ([]money.Amount, []postgres.PenjualanHeader, error) {
+	var alokasi []money.Amount
+	var penjualans []postgres.PenjualanHeader
+
+	if len(req.Alokasi) > 0 {
+		seen := make(map[uint64]bool)
+		for _, item := range req.Alokasi {
+			if seen[item.PenjualanHeaderID] {
+				return nil, nil, fmt.Errorf("penjualan %d is listed twice", item.PenjualanHeaderID)
+			}
+			seen[item.PenjualanHeaderID] = true
+
+			penjualan, err := produkRepo.GetPenjualanForUpdate(item.PenjualanHeaderID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("penjualan %d not found: %v", item.PenjualanHeaderID, err)
+			}
+			if penjualan.KoperasiID != req.KoperasiID || penjualan.AnggotaID != anggota.ID || penjualan.MetodePembayaran != "credit" {
+				return nil, nil, fmt.Errorf("penjualan %s is not a credit sale to anggota %s", penjualan.NomorTransaksi, anggota.Nama)
+			}
+
+			jumlah := item.Jumlah
+			sisa := penjualan.GrandTotal - penjualan.TotalTerbayar
+			if sisa <= 0 {
+				return nil, nil, fmt.Errorf("penjualan %s is already paid", penjualan.NomorTransaksi)
+			}
+			if jumlah > sisa {
+				return nil, nil, fmt.Errorf("payment %s exceeds outstanding %s on %s", jumlah, sisa, penjualan.NomorTransaksi)
+			}
+
+			alokasi = append(alokasi, jumlah)
+			penjualans = append(penjualans, *penjualan)
+		}
+		return alokasi, penjualans, nil
+	}
+
+	terbuka, err := produkRepo.GetPenjualanBelumLunas(req.KoperasiID, anggota.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load open sales: %v", err)
+	}
+	sort.SliceStable(terbuka, func(i, j int) bool {
+		return jatuhTempoPiutang(&terbuka[i]).Before(jatuhTempoPiutang(&terbuka[j]))
+	})
+
+	sisaBayar := req.Jumlah
+	for _, penjualan := range terbuka {
+		if sisaBayar <= 0 {
+			break
+		}
+		sisa := penjualan.GrandTotal - penjualan.TotalTerbayar
+		if sisa <= 0 {
+			continue
+		}
+
+		jumlah := sisa
+		if sisaBayar < sisa {
+			jumlah = sisaBayar
+		}
+		alokasi = append(alokasi, jumlah)
+		penjualans = append(penjualans, penjualan)
+		sisaBayar -= jumlah
+	}
+
+	if sisaBayar > 0 {
+		return nil, nil, fmt.Errorf("payment exceeds the outstanding balance of anggota %s by %s", anggota.Nama, sisaBayar)
+	}
+
+	return alokasi, penjualans, nil
+}
+
+func (s *PiutangService) GetPembayaran(nomorPembayaran string) ([]postgres.PembayaranPenjualan, error) {
+	return s.produkRepo.GetPembayaranPenjualanByNomor(nomorPembayaran)
+}
+
+// GetKartuPiutang lists a member's credit sales and collections in date order
+// with the running balance owed. A down payment is shown as collected on the
+// sale date.
+func (s *PiutangService) GetKartuPiutang(anggotaID uint64) (*KartuPiutang, error) {
+	anggota, err := s.anggotaRepo.GetByID(anggotaID)
+	if err != nil {
+		return nil, fmt.Errorf("anggota not found: %v", err)
+	}
+
+	penjualans, err := s.produkRepo.GetPenjualanKreditByAnggota(anggotaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load penjualan: %v", err)
+	}
+
+	kartu := &KartuPiutang{
+		AnggotaID: anggota.ID,
+		NIAK:      anggota.NIAK,
+		Nama:      anggota.Nama,
+		Entries:   []KartuPiutangEntry{},
+	}
+
+	for _, penjualan := range penjualans {
+		kartu.Entries = append(kartu.Entries, KartuPiutangEntry{
+			Tanggal:     penjualan.TanggalTransaksi,
+			Jenis:       "penjualan",
+			Nomor:       penjualan.NomorTransaksi,
+			Keterangan:  penjualan.Keterangan,
+			PenjualanID: penjualan.ID,
+			Debit:       penjualan.GrandTotal,
+		})
+		if penjualan.JumlahBayar > 0 {
+			kartu.Entries = append(kartu.Entries, KartuPiutangEntry{
+				Tanggal:     penjualan.TanggalTransaksi,
+				Jenis:       "uang_muka",
+				Nomor:       penjualan.NomorTransaksi,
+				PenjualanID: penjualan.ID,
+				Kredit:      penjualan.JumlahBayar,
+			})
+		}
+		for _, pembayaran := range penjualan.PembayaranPenjualan {
+			kartu.Entries = append(kartu.Entries, KartuPiutangEntry{
+				Tanggal:     pembayaran.TanggalBayar,
+				Jenis:       "pembayaran",
+				Nomor:       pembayaran.NomorPembayaran,
+				Keterangan:  pembayaran.Keterangan,
+				PenjualanID: penjualan.ID,
+				Kredit:      pembayaran.JumlahBayar,
+			})
+		}
+	}
+
+	sort.SliceStable(kartu.Entries, func(i, j int) bool {
+		return kartu.Entries[i].Tanggal.Before(kartu.Entries[j].Tanggal)
+	})
+
+	var saldo money.Amount
+	for i := range kartu.Entries {
+		saldo += kartu.Entries[i].Debit - kartu.Entries[i].Kredit
+		kartu.Entries[i].Saldo = saldo
+	}
+	kartu.Saldo = saldo
+
+	return kartu, nil
+}
+
+// GetUmurPiutang ages the receivables outstanding on tanggal per member by
+// days past the due date.
+func (s *PiutangService) GetUmurPiutang(koperasiID uint64, tanggal time.Time) (*LaporanUmurPiutang, error) {
+	akhirHari := time.Date(tanggal.Year(), tanggal.Month(), tanggal.Day(), 23, 59, 59, 0, tanggal.Location())
+
+	penjualans, err := s.produkRepo.GetPenjualanTerbuka(koperasiID, akhirHari)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load penjualan: %v", err)
+	}
+
+	laporan := &LaporanUmurPiutang{
+		KoperasiID: koperasiID,
+		Tanggal:    tanggal,
+		Anggota:    []UmurPiutangAnggota{},
+	}
+	index := make(map[uint64]int)
+
+	for i := range penjualans {
+		penjualan := &penjualans[i]
+
+		dibayar := penjualan.JumlahBayar
+		for _, pembayaran := range penjualan.PembayaranPenjualan {
+			if !pembayaran.TanggalBayar.After(akhirHari) {
+				dibayar += pembayaran.JumlahBayar
+			}
+		}
+		sisa := penjualan.GrandTotal - dibayar
+		if sisa <= 0 {
+			continue
+		}
+
+		jatuhTempo := jatuhTempoPiutang(penjualan)
+		hariLewat := int(tanggalSaja(tanggal).Sub(tanggalSaja(jatuhTempo)).Hours() / 24)
+
+		k, ok := index[penjualan.AnggotaID]
+		if !ok {
+			k = len(laporan.Anggota)
+			index[penjualan.AnggotaID] = k
+			laporan.Anggota = append(laporan.Anggota, UmurPiutangAnggota{
+				AnggotaID: penjualan.AnggotaID,
+				NIAK:      penjualan.Anggota.NIAK,
+				Nama:      penjualan.Anggota.Nama,
+			})
+		}
+		baris := &laporan.Anggota[k]
+
+		item := UmurPiutangPenjualan{
+			PenjualanHeaderID: penjualan.ID,
+			NomorTransaksi:    penjualan.NomorTransaksi,
+			TanggalTransaksi:  penjualan.TanggalTransaksi,
+			JatuhTempo:        jatuhTempo,
+			GrandTotal:        penjualan.GrandTotal,
+			Sisa:              sisa,
+			HariLewat:         hariLewat,
+			Kelompok:          kelompokUmur(hariLewat),
+		}
+		baris.Penjualan = append(baris.Penjualan, item)
+		baris.SaldoUmur.tambah(item.Kelompok, sisa)
+		laporan.Total.tambah(item.Kelompok, sisa)
+	}
+
+	return laporan, nil
+}
+
+func (s *PiutangService) generateNomorPembayaran(koperasiID uint64) (string, error) {
+	number, err := s.sequenceService.GetNextNumber(1, koperasiID, "pembayaran_penjualan")
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("PLP%04d%08d", koperasiID, number), nil
+}
+
+func jatuhTempoPiutang(penjualan *postgres.PenjualanHeader) time.Time {
+	if penjualan.TanggalJatuhTempo != nil {
+		return *penjualan.TanggalJatuhTempo
+	}
+	return penjualan.TanggalTransaksi.AddDate(0, 0, TermPiutangDefault)
+}
+
+type BayarPiutangRequest struct {
+	KoperasiID         uint64                `json:"koperasi_id" binding:"required"`
+	AnggotaID          uint64                `json:"anggota_id" binding:"required"`
+	TanggalBayar       time.Time             `json:"tanggal_bayar" binding:"required"`
+	MetodePembayaran   string                `json:"metode_pembayaran" binding:"required,oneof=cash transfer simpanan"`
+	RekeningSimpananID uint64                `json:"rekening_simpanan_id"`
+	NomorReferensi     string                `json:"nomor_referensi"`
+	Keterangan         string                `json:"keterangan"`
+	Jumlah             money.Amount          `json:"jumlah" binding:"min=0"`
+	Alokasi            []AlokasiBayarPiutang `json:"alokasi" binding:"omitempty,dive"`
+}
+
+type AlokasiBayarPiutang struct {
+	PenjualanHeaderID uint64       `json:"penjualan_header_id" binding:"required"`
+	Jumlah            money.Amount `json:"jumlah" binding:"required,gt=0"`
+}
+
+type PembayaranPiutang struct {
+	NomorPembayaran string                         `json:"nomor_pembayaran"`
+	AnggotaID       uint64                         `json:"anggota_id"`
+	TanggalBayar    time.Time                      `json:"tanggal_bayar"`
+	Total           money.Amount                   `json:"total"`
+	JurnalID        uint64                         `json:"jurnal_id"`
+	Pembayaran      []postgres.PembayaranPenjualan `json:"pembayaran"`
+}
+
+type KartuPiutang struct {
+	AnggotaID uint64              `json:"anggota_id"`
+	NIAK      string              `json:"niak"`
+	Nama      string              `json:"nama"`
+	Entries   []KartuPiutangEntry `json:"entries"`
+	Saldo     money.Amount        `json:"saldo"`
+}
+
+type KartuPiutangEntry struct {
+	Tanggal     time.Time    `json:"tanggal"`
+	Jenis       string       `json:"jenis"`
+	Nomor       string       `json:"nomor"`
+	Keterangan  string       `json:"keterangan"`
+	PenjualanID uint64       `json:"penjualan_id"`
+	Debit       money.Amount `json:"debit"`
+	Kredit      money.Amount `json:"kredit"`
+	Saldo       money.Amount `json:"saldo"`
+}
+
+type LaporanUmurPiutang struct {
+	KoperasiID uint64               `json:"koperasi_id"`
+	Tanggal    time.Time            `json:"tanggal"`
+	Anggota    []UmurPiutangAnggota `json:"anggota"`
+	Total      SaldoUmur            `json:"total"`
+}
+
+type UmurPiutangAnggota struct {
+	AnggotaID uint64 `json:"anggota_id"`
+	NIAK      string `json:"niak"`
+	Nama      string `json:"nama"`
+	SaldoUmur
+	Penjualan []UmurPiutangPenjualan `json:"penjualan"`
+}
+
+type UmurPiutangPenjualan struct {
+	PenjualanHeaderID uint64       `json:"penjualan_header_id"`
+	NomorTransaksi    string       `json:"nomor_transaksi"`
+	TanggalTransaksi  time.Time    `json:"tanggal_transaksi"`
+	JatuhTempo        time.Time    `json:"jatuh_tempo"`
+	GrandTotal        money.Amount `json:"grand_total"`
+	Sisa              money.Amount `json:"sisa"`
+	HariLewat         int          `json:"hari_lewat"`
+	Kelompok          string       `json:"kelompok"`
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+	"koperasi-merah-putih/internal/utils"
+)
+
+// Business events that can be mapped to journal lines through posting rules.
+const (
+	PostingEventSimpananSetoran   = "simpanan_setoran"
+	PostingEventSimpananPenarikan = "simpanan_penarikan"
+	PostingEventSimpananBunga     = "simpanan_bunga"
+	PostingEventSimpananPokok     = "simpanan_pokok"
+	PostingEventPinjamanPencairan = "pinjaman_pencairan"
+	PostingEventPinjamanAngsuran  = "pinjaman_angsuran"
+	PostingEventPinjamanBunga     = "pinjaman_bunga"
+	PostingEventPinjamanDenda     = "pinjaman_denda"
+	PostingEventPenjualan         = "penjualan"
+	PostingEventPembelian         = "pembelian"
+	PostingEventPembayaranHutang  = "pembayaran_hutang"
+	PostingEventPelunasanPiutang  = "pelunasan_piutang"
+	PostingEventPPOBPenjualan     = "ppob_penjualan"
+	PostingEventPPOBSettlement    = "ppob_settlement"
+	PostingEventKlinikPembayaran  = "klinik_pembayaran"
+	PostingEventSetoranPajak      = "setoran_pajak"
+)
+
+// postingEventKomponen lists the amounts each event supplies. A rule line
+// picks one of these as the value for its debit or kredit.
+var postingEventKomponen = map[string][]string{
+	PostingEventSimpananSetoran:   {"jumlah"},
+	PostingEventSimpananPenarikan: {"jumlah"},
+	PostingEventSimpananBunga:     {"jumlah", "netto", "pph"},
+	PostingEventSimpananPokok:     {"jumlah"},
+	PostingEventPinjamanPencairan: {"jumlah"},
+	PostingEventPinjamanAngsuran:  {"jumlah"},
+	PostingEventPinjamanBunga:     {"jumlah"},
+	PostingEventPinjamanDenda:     {"jumlah"},
+	PostingEventPenjualan:         {"total", "subtotal", "pajak", "diskon", "hpp", "kas", "piutang", "simpanan"},
+	PostingEventPembelian:         {"total", "subtotal", "pajak", "biaya_kirim", "diskon"},
+	PostingEventPembayaranHutang:  {"total", "cash", "transfer", "giro", "other", "pph"},
+	PostingEventPelunasanPiutang:  {"total", "cash", "transfer", "simpanan"},
+	PostingEventPPOBPenjualan:     {"total", "harga_jual", "harga_beli", "margin", "admin_fee", "fee_agen", "kas", "simpanan"},
+	PostingEventPPOBSettlement:    {"total", "fee_agen", "admin_fee"},
+	PostingEventKlinikPembayaran:  {"total", "konsultasi", "tindakan", "obat"},
+	PostingEventSetoranPajak:      {"total", "ppn_keluaran", "ppn_masukan", "pph21", "pph23", "pph4_2"},
+}
+
+type PostingService struct {
+	postingRepo      *postgresRepo.PostingRepository
+	financialRepo    *postgresRepo.FinancialRepository
+	financialService *FinancialService
+}
+
+func NewPostingService(
+	postingRepo *postgresRepo.PostingRepository,
+	financialRepo *postgresRepo.FinancialRepository,
+	financialService *FinancialService,
+) *PostingService {
+	return &PostingService{
+		postingRepo:      postingRepo,
+		financialRepo:    financialRepo,
+		financialService: financialService,
+	}
+}
+
+func (s *PostingService) GetPostingEvents() map[string][]string {
+	return postingEventKomponen
+}
+
+func (s *PostingService) CreatePostingRule(req *CreatePostingRuleRequest) (*postgres.PostingRule, error) {
+	lines, err := s.buildRuleLines(req.KoperasiID, req.KodeEvent, req.Lines)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := &postgres.PostingRule{
+		TenantID:             req.TenantID,
+		KoperasiID:           req.KoperasiID,
+		KodeEvent:            req.KodeEvent,
+		ProdukSimpanPinjamID: req.ProdukSimpanPinjamID,
+		Nama:                 req.Nama,
+		IsAktif:              true,
+		Lines:                lines,
+	}
+
+	err = s.postingRepo.CreatePostingRule(rule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create posting rule: %v", err)
+	}
+
+	return rule, nil
+}
+
+func (s *PostingService) GetPostingRuleList(koperasiID uint64) ([]postgres.PostingRule, error) {
+	return s.postingRepo.GetPostingRulesByKoperasi(koperasiID)
+}
+
+func (s *PostingService) GetPostingRuleByID(id uint64) (*postgres.PostingRule, error) {
+	return s.postingRepo.GetPostingRuleByID(id)
+}
+
+func (s *PostingService) UpdatePostingRule(id uint64, req *UpdatePostingRuleRequest) (*postgres.PostingRule, error) {
+	rule, err := s.postingRepo.GetPostingRuleByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("posting rule not found: %v", err)
+	}
+
+	lines, err := s.buildRuleLines(rule.KoperasiID, rule.KodeEvent, req.Lines)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.Nama = req.Nama
+	rule.IsAktif = req.IsAktif
+	rule.Lines = lines
+
+	err = s.postingRepo.UpdatePostingRule(rule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update posting rule: %v", err)
+	}
+
+	return rule, nil
+}
+
+func (s *PostingService) DeletePostingRule(id uint64) error {
+	return s.postingRepo.DeletePostingRule(id)
+}
+
+// Post writes an auto-posted journal for a business event inside tx. It
+// returns nil without error when the koperasi has no active rule for the
+// event, so modules keep working before their accounts are mapped.
+func (s *PostingService) Post(tx *gorm.DB, req *PostingRequest) (*postgres.JurnalUmum, error) {
+	rule, err := s.postingRepo.WithTx(tx).GetActivePostingRule(req.KoperasiID, req.KodeEvent, req.ProdukSimpanPinjamID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load posting rule: %v", err)
+	}
+
+	var details []postgres.JurnalDetail
+	for _, line := range rule.Lines {
+		amount := req.Komponen[line.Komponen]
+		if amount == 0 {
+			continue
+		}
+
+		keterangan := line.Keterangan
+		if keterangan == "" {
+			keterangan = req.Keterangan
+		}
+
+		detail := postgres.JurnalDetail{
+			AkunID:     line.AkunID,
+			Keterangan: utils.TruncateString(keterangan, 255),
+		}
+
+		// Negative amounts (e.g. a loss on margin) flip to the other side.
+		if (line.Posisi == "debit") == (amount > 0) {
+			detail.Debit = amount.Abs()
+		} else {
+			detail.Kredit = amount.Abs()
+		}
+		details = append(details, detail)
+	}
+
+	if len(details) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	jurnal := &postgres.JurnalUmum{
+		TenantID:         rule.TenantID,
+		KoperasiID:       req.KoperasiID,
+		TanggalTransaksi: req.TanggalTransaksi,
+		Referensi:        req.Referensi,
+		Keterangan:       req.Keterangan,
+		Status:           "posted",
+		SumberTransaksi:  req.SumberTransaksi,
+		SumberID:         req.SumberID,
+		AnggotaID:        req.AnggotaID,
+		CreatedBy:        req.CreatedBy,
+		PostedAt:         &now,
+		PostedBy:         req.CreatedBy,
+	}
+
+	err = s.financialService.saveJurnal(tx, jurnal, details)
+	if err != nil {
+		return nil, fmt.Errorf("posting rule %s: %v", rule.KodeEvent, err)
+	}
+
+	return jurnal, nil
+}
+
+func (s *PostingService) buildRuleLines(koperasiID uint64, kodeEvent string, reqLines []PostingRuleLineRequest) ([]postgres.PostingRuleLine, error) {
+	komponen, ok := postingEventKomponen[kodeEvent]
+	if !ok {
+		return nil, fmt.Errorf("unknown posting event %s", kodeEvent)
+	}
+
+	var hasDebit, hasKredit bool
+	var lines []postgres.PostingRuleLine
+	for i, reqLine := range reqLines {
+		if !utils.Contains(komponen, reqLine.Komponen) {
+			return nil, fmt.Errorf("komponen %s is not available for event %s", reqLine.Komponen, kodeEvent)
+		}
+
+		akun, err := s.financialRepo.GetCOAAkunByID(reqLine.AkunID)
+		if err != nil {
+			return nil, fmt.Errorf("akun %d not found: %v", reqLine.AkunID, err)
+		}
+		if akun.KoperasiID != koperasiID {
+			return nil, fmt.Errorf("akun %s does not belong to koperasi %d", akun.KodeAkun, koperasiID)
+		}
+
+		hasDebit = hasDebit || reqLine.Posisi == "debit"
+		hasKredit = hasKredit || reqLine.Posisi == "kredit"
+
+		lines = append(lines, postgres.PostingRuleLine{
+			AkunID:     reqLine.AkunID,
+			Posisi:     reqLine.Posisi,
+			Komponen:   reqLine.Komponen,
+			Keterangan: reqLine.Keterangan,
+			Urutan:     i + 1,
+		})
+	}
+
+	if !hasDebit || !hasKredit {
+		return nil, fmt.Errorf("posting rule needs at least one debit and one kredit line")
+	}
+
+	return lines, nil
+}
+
+// SystemUserID is recorded as the creator of journals posted from payment
+// webhooks and scheduled jobs, which have no acting user.
+const SystemUserID uint64 = 0
+
+type PostingRequest struct {
+	KoperasiID           uint64
+	KodeEvent            string
+	ProdukSimpanPinjamID uint64
+	TanggalTransaksi     time.Time
+	Referensi            string
+	Keterangan           string
+	SumberTransaksi      string
+	SumberID             uint64
+	AnggotaID            uint64
+	Komponen             map[string]money.Amount
+	CreatedBy            uint64
+}
+
+type CreatePostingRuleRequest struct {
+	TenantID             uint64                   `json:"tenant_id" binding:"required"`
+	KoperasiID           uint64                   `json:"koperasi_id" binding:"required"`
+	KodeEvent            string                   `json:"kode_event" binding:"required"`
+	ProdukSimpanPinjamID uint64                   `json:"produk_simpan_pinjam_id"`
+	Nama                 string                   `json:"nama" binding:"required"`
+	Lines                []PostingRuleLineRequest `json:"lines" binding:"required,min=2,dive"`
+}
+
+type UpdatePostingRuleRequest struct {
+	Nama    string                   `json:"nama" binding:"required"`
+	IsAktif bool                     `json:"is_aktif"`
+	Lines   []PostingRuleLineRequest `json:"lines" binding:"required,min=2,dive"`
+}
+
+type PostingRuleLineRequest struct {
+	AkunID     uint64 `json:"akun_id" binding:"required"`
+	Posisi     string `json:"posisi" binding:"required,oneof=debit kredit"`
+	Komponen   string `json:"komponen" binding:"required"`
+	Keterangan string `json:"keterangan"`
+}
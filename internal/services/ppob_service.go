@@ -1,28 +1,52 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"gorm.io/gorm"
+	"koperasi-merah-putih/internal/gateway"
 	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
 	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
 )
 
+// Payment methods of a PPOB transaction. A simpanan payment debits the
+// member's or agent's simpanan account without a payment gateway.
+const (
+	PPOBBayarGateway  = "gateway"
+	PPOBBayarSimpanan = "simpanan"
+)
+
 type PPOBService struct {
-	ppobRepo        *postgresRepo.PPOBRepository
-	paymentService  *PaymentService
-	sequenceService *SequenceService
+	ppobRepo            *postgresRepo.PPOBRepository
+	paymentService      *PaymentService
+	postingService      *PostingService
+	financialService    *FinancialService
+	simpanPinjamService *SimpanPinjamService
+	sequenceService     *SequenceService
+	providerAdapters    *gateway.PPOBAdapterRegistry
 }
 
 func NewPPOBService(
 	ppobRepo *postgresRepo.PPOBRepository,
 	paymentService *PaymentService,
+	postingService *PostingService,
+	financialService *FinancialService,
+	simpanPinjamService *SimpanPinjamService,
 	sequenceService *SequenceService,
+	providerAdapters *gateway.PPOBAdapterRegistry,
 ) *PPOBService {
 	return &PPOBService{
-		ppobRepo:        ppobRepo,
-		paymentService:  paymentService,
-		sequenceService: sequenceService,
+		ppobRepo:            ppobRepo,
+		paymentService:      paymentService,
+		postingService:      postingService,
+		financialService:    financialService,
+		simpanPinjamService: simpanPinjamService,
+		sequenceService:     sequenceService,
+		providerAdapters:    providerAdapters,
 	}
 }
 
@@ -34,6 +58,77 @@ func (s *PPOBService) GetProdukByKategori(kategoriID uint64) ([]postgres.PPOBPro
 	return s.ppobRepo.GetProdukByKategori(kategoriID)
 }
 
+// ppobInquiryTTL is how long a postpaid bill inquiry stays valid; after
+// that the customer has to inquire again since the bill may have changed.
+const ppobInquiryTTL = 15 * time.Minute
+
+func (s *PPOBService) Inquiry(req *PPOBInquiryRequest) (*postgres.PPOBInquiry, error) {
+	produk, err := s.ppobRepo.GetProdukByID(req.ProdukID)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %v", err)
+	}
+
+	if !produk.IsAktif {
+		return nil, fmt.Errorf("product is not active")
+	}
+	if !produk.IsPascabayar {
+		return nil, fmt.Errorf("inquiry is only available for postpaid products")
+	}
+
+	adapter, err := s.providerAdapters.Adapter(&produk.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	nomorInquiry, err := s.generateNomorTransaksi(req.KoperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate transaction number: %v", err)
+	}
+
+	response, err := adapter.Inquiry(&gateway.PPOBRequest{
+		RefID:       nomorInquiry,
+		KodeProduk:  produk.KodeProduk,
+		NomorTujuan: req.NomorTujuan,
+		Pascabayar:  true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to inquire bill: %v", err)
+	}
+	if response.Status != gateway.PPOBStatusSukses {
+		return nil, fmt.Errorf("bill inquiry failed: %s", response.Pesan)
+	}
+
+	inquiry := &postgres.PPOBInquiry{
+		KoperasiID:      req.KoperasiID,
+		AnggotaID:       req.AnggotaID,
+		ProdukID:        produk.ID,
+		NomorInquiry:    nomorInquiry,
+		NomorTujuan:     req.NomorTujuan,
+		NamaPelanggan:   response.NamaPelanggan,
+		Periode:         strings.Join(response.Periode, ","),
+		JumlahLembar:    len(response.Periode),
+		JumlahTagihan:   response.Tagihan,
+		AdminProvider:   response.AdminProvider,
+		HargaBeli:       response.Harga,
+		Status:          postgres.PPOBInquiryAktif,
+		KedaluwarsaPada: time.Now().Add(ppobInquiryTTL),
+	}
+
+	if err := s.ppobRepo.CreateInquiry(inquiry); err != nil {
+		return nil, fmt.Errorf("failed to save inquiry: %v", err)
+	}
+
+	return inquiry, nil
+}
+
+// ExpireInquiries is run by the scheduler to mark inquiries past their
+// validity. Using an inquiry checks the expiry time on its own, so this job
+// only tidies up the status.
+func (s *PPOBService) ExpireInquiries(now time.Time) error {
+	_, err := s.ppobRepo.ExpireInquiries(now)
+	return err
+}
+
 func (s *PPOBService) CreateTransaction(req *PPOBTransactionRequest) (*postgres.PPOBTransaksi, error) {
 	produk, err := s.ppobRepo.GetProdukByID(req.ProdukID)
 	if err != nil {
@@ -44,43 +139,130 @@ func (s *PPOBService) CreateTransaction(req *PPOBTransactionRequest) (*postgres.
 		return nil, fmt.Errorf("product is not active")
 	}
 
+	// A member always orders for themselves; only operators and admins may
+	// name another member of the koperasi.
+	if req.PembeliAnggotaID != 0 {
+		if req.AnggotaID != 0 && req.AnggotaID != req.PembeliAnggotaID {
+			return nil, fmt.Errorf("anggota can only order for themselves")
+		}
+		req.AnggotaID = req.PembeliAnggotaID
+	}
+
+	metode := req.MetodePembayaran
+	if metode == "" {
+		metode = PPOBBayarGateway
+	}
+	if metode == PPOBBayarSimpanan {
+		if req.AnggotaID == 0 || req.RekeningSimpananID == 0 {
+			return nil, fmt.Errorf("anggota and rekening simpanan are required to pay from simpanan")
+		}
+		if err := s.simpanPinjamService.cekRekeningAnggota(req.RekeningSimpananID, req.AnggotaID, req.KoperasiID); err != nil {
+			return nil, err
+		}
+	}
+
+	hargaJual, hargaBeli := produk.HargaJual, produk.HargaBeli
+	nomorTujuan, namaPelanggan := req.NomorTujuan, req.NamaPelanggan
+
+	var inquiry *postgres.PPOBInquiry
+	if produk.IsPascabayar {
+		inquiry, err = s.getInquiryForTransaction(req)
+		if err != nil {
+			return nil, err
+		}
+		hargaJual = inquiry.JumlahTagihan + inquiry.AdminProvider
+		hargaBeli = inquiry.HargaBeli
+		nomorTujuan = inquiry.NomorTujuan
+		namaPelanggan = inquiry.NamaPelanggan
+	}
+
 	config, err := s.ppobRepo.GetPaymentConfig(req.KoperasiID)
 	if err != nil {
 		config = &postgres.PPOBPaymentConfig{
-			PPOBAdminFee:     5000,
+			PPOBAdminFee:     money.FromInt(5000),
 			PPOBAdminFeeType: "fixed",
 		}
 	}
 
-	adminFee := s.calculatePPOBAdminFee(config, produk.HargaJual)
-	totalAmount := produk.HargaJual + adminFee
-
-	nomorTransaksi, err := s.generateNomorTransaksi(req.KoperasiID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate transaction number: %v", err)
+	adminFee := s.calculatePPOBAdminFee(config, hargaJual)
+	totalAmount := hargaJual + adminFee
+
+	// A postpaid transaction takes the inquiry number as its transaction
+	// number because the provider pays the bill by the inquiry's ref_id.
+	var nomorTransaksi string
+	if inquiry != nil {
+		nomorTransaksi = inquiry.NomorInquiry
+	} else {
+		nomorTransaksi, err = s.generateNomorTransaksi(req.KoperasiID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate transaction number: %v", err)
+		}
 	}
 
 	transaksi := &postgres.PPOBTransaksi{
-		KoperasiID:     req.KoperasiID,
-		AnggotaID:      req.AnggotaID,
-		ProdukID:       req.ProdukID,
-		NomorTransaksi: nomorTransaksi,
-		NomorTujuan:    req.NomorTujuan,
-		NamaPelanggan:  req.NamaPelanggan,
-		HargaBeli:      produk.HargaBeli,
-		HargaJual:      produk.HargaJual,
-		FeeAgen:        produk.FeeAgen,
-		Status:         "pending",
-		CustomerName:   req.CustomerName,
-		CustomerEmail:  req.CustomerEmail,
-		CustomerPhone:  req.CustomerPhone,
-		AdminFee:       adminFee,
-		PaymentStatus:  "pending",
+		KoperasiID:       req.KoperasiID,
+		AnggotaID:        req.AnggotaID,
+		ProdukID:         req.ProdukID,
+		NomorTransaksi:   nomorTransaksi,
+		NomorTujuan:      nomorTujuan,
+		NamaPelanggan:    namaPelanggan,
+		HargaBeli:        hargaBeli,
+		HargaJual:        hargaJual,
+		FeeAgen:          produk.FeeAgen,
+		Status:           "pending",
+		CustomerName:     req.CustomerName,
+		CustomerEmail:    req.CustomerEmail,
+		CustomerPhone:    req.CustomerPhone,
+		AdminFee:         adminFee,
+		PaymentStatus:    "pending",
+		MetodePembayaran: metode,
+	}
+	if inquiry != nil {
+		transaksi.InquiryID = inquiry.ID
 	}
+	if metode == PPOBBayarSimpanan {
+		transaksi.PaymentStatus = "paid"
+		transaksi.RekeningSimpananID = req.RekeningSimpananID
+	}
+
+	err = s.ppobRepo.Transaction(func(tx *gorm.DB) error {
+		ppobRepo := s.ppobRepo.WithTx(tx)
+
+		if err := ppobRepo.CreateTransaksi(transaksi); err != nil {
+			return fmt.Errorf("failed to create PPOB transaction: %v", err)
+		}
 
-	err = s.ppobRepo.CreateTransaksi(transaksi)
+		if inquiry != nil {
+			used, err := ppobRepo.UseInquiry(inquiry.ID, transaksi.ID, time.Now())
+			if err != nil {
+				return fmt.Errorf("failed to use inquiry: %v", err)
+			}
+			if !used {
+				return fmt.Errorf("inquiry %s has expired or has already been used", inquiry.NomorInquiry)
+			}
+		}
+
+		// The simpanan debit shares the DB transaction with the PPOB order,
+		// so there is never an order without a debit or the other way round.
+		if metode == PPOBBayarSimpanan {
+			_, err := s.simpanPinjamService.mutasiSimpanan(tx, req.RekeningSimpananID, "penarikan", totalAmount,
+				fmt.Sprintf("Pembayaran PPOB %s", transaksi.NomorTransaksi), transaksi.NomorTransaksi, req.CreatedBy)
+			if err != nil {
+				return fmt.Errorf("failed to debit simpanan: %v", err)
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create PPOB transaction: %v", err)
+		return nil, err
+	}
+
+	if metode == PPOBBayarSimpanan {
+		transaksi.Produk = *produk
+		if err := s.kirimKeProvider(transaksi, req.CreatedBy); err != nil {
+			return nil, err
+		}
+		return s.ppobRepo.GetTransaksiByID(transaksi.ID)
 	}
 
 	paymentReq := &CreatePaymentRequest{
@@ -92,7 +274,7 @@ func (s *PPOBService) CreateTransaction(req *PPOBTransactionRequest) (*postgres.
 		CustomerName:    req.CustomerName,
 		CustomerEmail:   req.CustomerEmail,
 		CustomerPhone:   req.CustomerPhone,
-		Description:     fmt.Sprintf("PPOB %s - %s", produk.NamaProduk, req.NomorTujuan),
+		Description:     fmt.Sprintf("PPOB %s - %s", produk.NamaProduk, nomorTujuan),
 		TransactionType: "ppob",
 		ReferenceID:     transaksi.ID,
 		ReferenceTable:  "ppob_transaksi",
@@ -104,50 +286,440 @@ func (s *PPOBService) CreateTransaction(req *PPOBTransactionRequest) (*postgres.
 	}
 
 	transaksi.PaymentID = payment.ID
-	err = s.ppobRepo.UpdateTransaksiStatus(transaksi.ID, "pending", "Waiting for payment")
+	err = s.ppobRepo.UpdateTransaksiPayment(transaksi.ID, payment.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update transaction with payment ID: %v", err)
 	}
 
+	err = s.ppobRepo.UpdateTransaksiStatus(transaksi.ID, "pending", "Waiting for payment")
+	if err != nil {
+		return nil, fmt.Errorf("failed to update transaction status: %v", err)
+	}
+
 	return transaksi, nil
 }
 
+func (s *PPOBService) getInquiryForTransaction(req *PPOBTransactionRequest) (*postgres.PPOBInquiry, error) {
+	if req.NomorInquiry == "" {
+		return nil, fmt.Errorf("nomor_inquiry is required for postpaid products")
+	}
+
+	inquiry, err := s.ppobRepo.GetInquiryByNomor(req.NomorInquiry)
+	if err != nil {
+		return nil, fmt.Errorf("inquiry not found: %v", err)
+	}
+
+	if inquiry.KoperasiID != req.KoperasiID || inquiry.ProdukID != req.ProdukID {
+		return nil, fmt.Errorf("inquiry does not match the requested product")
+	}
+	if inquiry.Status != postgres.PPOBInquiryAktif {
+		return nil, fmt.Errorf("inquiry %s has already been %s", inquiry.NomorInquiry, inquiry.Status)
+	}
+	if !time.Now().Before(inquiry.KedaluwarsaPada) {
+		return nil, fmt.Errorf("inquiry %s has expired, please inquire again", inquiry.NomorInquiry)
+	}
+
+	return inquiry, nil
+}
+
 func (s *PPOBService) ProcessPayment(paymentID uint64) error {
 	transaksi, err := s.ppobRepo.GetTransaksiByPaymentID(paymentID)
 	if err != nil {
 		return fmt.Errorf("PPOB transaction not found for payment ID %d: %v", paymentID, err)
 	}
 
-	if transaksi.PaymentStatus != "pending" {
+	updated, err := s.ppobRepo.UpdatePaymentStatusFrom(transaksi.ID, "pending", "paid")
+	if err != nil {
+		return fmt.Errorf("failed to update payment status: %v", err)
+	}
+	if !updated {
 		return fmt.Errorf("payment already processed")
 	}
+	transaksi.PaymentStatus = "paid"
 
-	err = s.ppobRepo.UpdatePaymentStatus(transaksi.ID, "paid")
+	return s.kirimKeProvider(transaksi, SystemUserID)
+}
+
+func (s *PPOBService) kirimKeProvider(transaksi *postgres.PPOBTransaksi, by uint64) error {
+	adapter, err := s.providerAdapters.Adapter(&transaksi.Produk.Provider)
 	if err != nil {
-		return fmt.Errorf("failed to update payment status: %v", err)
+		// Nothing reached the provider, so the purchase can be failed and refunded.
+		s.gagalkanTransaksi(transaksi, "", err.Error(), by)
+		return fmt.Errorf("failed to process to provider: %v", err)
 	}
 
-	err = s.processToProvider(transaksi)
+	response, err := adapter.Topup(s.providerRequest(transaksi))
 	if err != nil {
-		s.ppobRepo.UpdateTransaksiStatus(transaksi.ID, "failed", err.Error())
-		return fmt.Errorf("failed to process to provider: %v", err)
+		// A timeout or server error does not tell whether the provider took
+		// the order, so it stays pending until CekStatusTransaksi settles it.
+		return s.ppobRepo.UpdateTransaksiProvider(transaksi.ID, "pending", "",
+			fmt.Sprintf("provider unreachable: %v", err))
+	}
+
+	return s.applyProviderResponse(transaksi, response, by)
+}
+
+// CekStatusTransaksi asks the provider again about a pending transaction
+// and settles it once the provider has a final result.
+func (s *PPOBService) CekStatusTransaksi(id uint64, by uint64) (*postgres.PPOBTransaksi, error) {
+	transaksi, err := s.ppobRepo.GetTransaksiByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("PPOB transaction not found: %v", err)
+	}
+
+	if transaksi.PaymentStatus != "paid" {
+		return nil, fmt.Errorf("PPOB transaction has not been paid")
+	}
+	if transaksi.Status != "pending" {
+		return transaksi, nil
+	}
+
+	adapter, err := s.providerAdapters.Adapter(&transaksi.Produk.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := adapter.CekStatus(s.providerRequest(transaksi))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check provider status: %v", err)
+	}
+
+	if err := s.applyProviderResponse(transaksi, response, by); err != nil {
+		return nil, err
 	}
 
-	return s.ppobRepo.UpdateTransaksiStatus(transaksi.ID, "success", "Transaction successful")
+	return s.ppobRepo.GetTransaksiByID(id)
 }
 
-func (s *PPOBService) processToProvider(transaksi *postgres.PPOBTransaksi) error {
+// CekTransaksiPending is run by the scheduler to settle paid transactions
+// that are still pending at the provider, including those whose order could
+// not be confirmed because the provider was unreachable.
+func (s *PPOBService) CekTransaksiPending(now time.Time) error {
+	transaksis, err := s.ppobRepo.GetTransaksiPendingProvider(now.Add(-5 * time.Minute))
+	if err != nil {
+		return fmt.Errorf("failed to load pending PPOB transactions: %v", err)
+	}
+
+	var errs []error
+	for _, transaksi := range transaksis {
+		if _, err := s.CekStatusTransaksi(transaksi.ID, SystemUserID); err != nil {
+			errs = append(errs, fmt.Errorf("transaksi %s: %v", transaksi.NomorTransaksi, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *PPOBService) GetSaldoProvider(providerID uint64) (money.Amount, error) {
+	provider, err := s.ppobRepo.GetProviderByID(providerID)
+	if err != nil {
+		return 0, fmt.Errorf("PPOB provider not found: %v", err)
+	}
+
+	adapter, err := s.providerAdapters.Adapter(provider)
+	if err != nil {
+		return 0, err
+	}
+
+	return adapter.CekSaldo()
+}
+
+func (s *PPOBService) providerRequest(transaksi *postgres.PPOBTransaksi) *gateway.PPOBRequest {
+	return &gateway.PPOBRequest{
+		RefID:       transaksi.NomorTransaksi,
+		KodeProduk:  transaksi.Produk.KodeProduk,
+		NomorTujuan: transaksi.NomorTujuan,
+		Pascabayar:  transaksi.Produk.IsPascabayar,
+	}
+}
+
+func (s *PPOBService) applyProviderResponse(transaksi *postgres.PPOBTransaksi, response *gateway.PPOBResponse, by uint64) error {
+	switch response.Status {
+	case gateway.PPOBStatusPending:
+		return s.ppobRepo.UpdateTransaksiProvider(transaksi.ID, "pending", response.NomorReferensi, response.Pesan)
+	case gateway.PPOBStatusGagal:
+		if err := s.gagalkanTransaksi(transaksi, response.NomorReferensi, response.Pesan, by); err != nil {
+			return err
+		}
+		return fmt.Errorf("provider rejected transaction: %s", response.Pesan)
+	}
+
+	total := transaksi.HargaJual + transaksi.AdminFee
+	kas, simpanan := total, money.Amount(0)
+	if transaksi.MetodePembayaran == PPOBBayarSimpanan {
+		kas, simpanan = 0, total
+	}
+
+	return s.ppobRepo.Transaction(func(tx *gorm.DB) error {
+		ppobRepo := s.ppobRepo.WithTx(tx)
+
+		current, err := ppobRepo.GetTransaksiForUpdate(transaksi.ID)
+		if err != nil {
+			return err
+		}
+		if current.Status != "pending" {
+			return nil
+		}
+
+		if err := ppobRepo.UpdateTransaksiProvider(transaksi.ID, "success", response.NomorReferensi, response.Pesan); err != nil {
+			return err
+		}
+
+		jurnal, err := s.postingService.Post(tx, &PostingRequest{
+			KoperasiID:       transaksi.KoperasiID,
+			KodeEvent:        PostingEventPPOBPenjualan,
+			TanggalTransaksi: transaksi.TanggalTransaksi,
+			Referensi:        transaksi.NomorTransaksi,
+			Keterangan:       fmt.Sprintf("PPOB %s - %s", transaksi.Produk.NamaProduk, transaksi.NomorTujuan),
+			SumberTransaksi:  "ppob_transaksi",
+			SumberID:         transaksi.ID,
+			AnggotaID:        transaksi.AnggotaID,
+			Komponen: map[string]money.Amount{
+				"total":      total,
+				"harga_jual": transaksi.HargaJual,
+				"harga_beli": transaksi.HargaBeli,
+				"margin":     transaksi.HargaJual - transaksi.HargaBeli,
+				"admin_fee":  transaksi.AdminFee,
+				"fee_agen":   transaksi.FeeAgen,
+				"kas":        kas,
+				"simpanan":   simpanan,
+			},
+			CreatedBy: by,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to post jurnal: %v", err)
+		}
+
+		if jurnal != nil {
+			return ppobRepo.UpdateTransaksiJurnal(transaksi.ID, jurnal.ID)
+		}
+		return nil
+	})
+}
+
+// gagalkanTransaksi marks a transaction failed and refunds what was paid.
+// It is only called for a definite failure: the provider answered failed,
+// or the order was never sent. Transactions that are no longer pending are
+// left alone. No PPOB sales journal is ever posted for a failed
+// transaction, so there is no journal to reverse.
+func (s *PPOBService) gagalkanTransaksi(transaksi *postgres.PPOBTransaksi, nomorReferensi, pesan string, by uint64) error {
+	var refundGateway bool
+	err := s.ppobRepo.Transaction(func(tx *gorm.DB) error {
+		ppobRepo := s.ppobRepo.WithTx(tx)
+
+		current, err := ppobRepo.GetTransaksiForUpdate(transaksi.ID)
+		if err != nil {
+			return err
+		}
+		if current.Status != "pending" {
+			return nil
+		}
+
+		if err := ppobRepo.UpdateTransaksiProvider(transaksi.ID, "failed", nomorReferensi, pesan); err != nil {
+			return err
+		}
+
+		if current.PaymentStatus != "paid" {
+			return nil
+		}
+
+		refundGateway, err = s.mulaiRefund(tx, current, "Transaksi gagal di provider", by)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if refundGateway {
+		s.prosesRefundGateway(transaksi.ID)
+	}
+	return nil
+}
+
+// mulaiRefund refunds the transaction's total payment inside tx. A simpanan
+// debit is returned right away; a gateway payment is recorded as pending and
+// requested from the payment provider once tx commits, which is signalled by
+// returning true.
+func (s *PPOBService) mulaiRefund(tx *gorm.DB, transaksi *postgres.PPOBTransaksi, alasan string, by uint64) (bool, error) {
+	if transaksi.StatusRefund != "" {
+		return false, fmt.Errorf("refund for %s has already been started", transaksi.NomorTransaksi)
+	}
+
+	ppobRepo := s.ppobRepo.WithTx(tx)
+	total := transaksi.HargaJual + transaksi.AdminFee
+
+	if transaksi.MetodePembayaran != PPOBBayarSimpanan {
+		if err := ppobRepo.MulaiRefund(transaksi.ID, postgres.PPOBRefundPending, total, alasan); err != nil {
+			return false, fmt.Errorf("failed to start refund: %v", err)
+		}
+		return true, nil
+	}
+
+	mutasi, err := s.simpanPinjamService.mutasiSimpanan(tx, transaksi.RekeningSimpananID, "setoran", total,
+		fmt.Sprintf("Pengembalian PPOB %s", transaksi.NomorTransaksi), transaksi.NomorTransaksi, by)
+	if err != nil {
+		return false, fmt.Errorf("failed to refund simpanan: %v", err)
+	}
+
+	if err := ppobRepo.MulaiRefund(transaksi.ID, postgres.PPOBRefundSukses, total, alasan); err != nil {
+		return false, fmt.Errorf("failed to start refund: %v", err)
+	}
+	if err := ppobRepo.SelesaiRefund(transaksi.ID, mutasi.NomorTransaksi, mutasi.TanggalTransaksi); err != nil {
+		return false, fmt.Errorf("failed to complete refund: %v", err)
+	}
+	return false, nil
+}
+
+// prosesRefundGateway asks the payment provider to return the money. The
+// refund is first claimed as processing so the scheduler and an operator
+// retry never send it twice; a failure is recorded and joins the refund
+// queue.
+func (s *PPOBService) prosesRefundGateway(id uint64) error {
+	claimed, err := s.ppobRepo.UpdateStatusRefundFrom(id,
+		[]string{postgres.PPOBRefundPending, postgres.PPOBRefundGagal}, postgres.PPOBRefundProses)
+	if err != nil {
+		return fmt.Errorf("failed to claim refund: %v", err)
+	}
+	if !claimed {
+		return fmt.Errorf("refund is not waiting to be processed")
+	}
+
+	transaksi, err := s.ppobRepo.GetTransaksiByID(id)
+	if err != nil {
+		return fmt.Errorf("PPOB transaction not found: %v", err)
+	}
+
+	payment, err := s.paymentService.RefundPayment(transaksi.PaymentID, transaksi.JumlahRefund,
+		transaksi.NomorTransaksi+"-RF", transaksi.AlasanRefund)
+	if err != nil {
+		s.ppobRepo.GagalRefund(id, err.Error())
+		return fmt.Errorf("failed to refund payment: %v", err)
+	}
+
+	refundedAt := time.Now()
+	if payment.RefundedAt != nil {
+		refundedAt = *payment.RefundedAt
+	}
+	return s.ppobRepo.SelesaiRefund(id, payment.RefundReference, refundedAt)
+}
+
+// RefundTransaksi cancels a successful transaction, e.g. when the provider
+// voids a token after issuing it. The sales journal is reversed and the
+// refund started in the same tx as the status change. Transactions already
+// in a settlement cannot be refunded.
+func (s *PPOBService) RefundTransaksi(id uint64, alasan string, by uint64) (*postgres.PPOBTransaksi, error) {
+	var refundGateway bool
+	err := s.ppobRepo.Transaction(func(tx *gorm.DB) error {
+		ppobRepo := s.ppobRepo.WithTx(tx)
+
+		current, err := ppobRepo.GetTransaksiForUpdate(id)
+		if err != nil {
+			return fmt.Errorf("PPOB transaction not found: %v", err)
+		}
+		if current.Status != "success" || current.PaymentStatus != "paid" {
+			return fmt.Errorf("only successful paid transactions can be refunded")
+		}
+		if current.TanggalSettlement != nil {
+			return fmt.Errorf("transaction %s has already been settled", current.NomorTransaksi)
+		}
+
+		if current.JurnalID != 0 {
+			reversal, err := s.financialService.ReverseJurnalBySumberTx(tx, "ppob_transaksi", current.ID, &ReverseJurnalRequest{
+				Keterangan: fmt.Sprintf("Refund PPOB %s: %s", current.NomorTransaksi, alasan),
+			}, by)
+			if err != nil {
+				return fmt.Errorf("failed to reverse jurnal: %v", err)
+			}
+			if err := ppobRepo.UpdateRefundJurnal(current.ID, reversal.ID); err != nil {
+				return err
+			}
+		}
+
+		if err := ppobRepo.UpdateTransaksiStatus(current.ID, "cancelled", alasan); err != nil {
+			return err
+		}
+
+		refundGateway, err = s.mulaiRefund(tx, current, alasan, by)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if refundGateway {
+		s.prosesRefundGateway(id)
+	}
+
+	return s.ppobRepo.GetTransaksiByID(id)
+}
+
+// RetryRefund resends a pending or failed gateway refund.
+func (s *PPOBService) RetryRefund(id uint64) (*postgres.PPOBTransaksi, error) {
+	if err := s.prosesRefundGateway(id); err != nil {
+		return nil, err
+	}
+	return s.ppobRepo.GetTransaksiByID(id)
+}
+
+// SelesaikanRefundManual is used by an operator once the money was returned
+// outside the system, e.g. from the payment provider's dashboard, including
+// refunds stuck in processing.
+func (s *PPOBService) SelesaikanRefundManual(id uint64, referensi string) (*postgres.PPOBTransaksi, error) {
+	claimed, err := s.ppobRepo.UpdateStatusRefundFrom(id,
+		[]string{postgres.PPOBRefundPending, postgres.PPOBRefundProses, postgres.PPOBRefundGagal}, postgres.PPOBRefundProses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim refund: %v", err)
+	}
+	if !claimed {
+		return nil, fmt.Errorf("refund is not waiting to be processed")
+	}
+
+	if err := s.ppobRepo.SelesaiRefund(id, referensi, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to complete refund: %v", err)
+	}
+	return s.ppobRepo.GetTransaksiByID(id)
+}
+
+func (s *PPOBService) GetRefundQueue(koperasiID uint64) ([]postgres.PPOBTransaksi, error) {
+	return s.ppobRepo.GetRefundQueue(koperasiID)
+}
+
+// RetryRefunds is run by the scheduler for gateway refunds still pending,
+// e.g. because the provider could not be reached when the transaction was
+// failed. Failed refunds wait for an operator.
+func (s *PPOBService) RetryRefunds(now time.Time) error {
+	transaksis, err := s.ppobRepo.GetTransaksiRefundPending()
+	if err != nil {
+		return err
+	}
+
+	for _, transaksi := range transaksis {
+		s.prosesRefundGateway(transaksi.ID)
+	}
 	return nil
 }
 
 func (s *PPOBService) CreateSettlement(koperasiID uint64, dari, sampai time.Time, processedBy uint64) (*postgres.PPOBSettlement, error) {
+	settlement, err := s.buatSettlement(koperasiID, dari, sampai, processedBy)
+	if err != nil {
+		return nil, err
+	}
+	if settlement == nil {
+		return nil, fmt.Errorf("no transactions found for settlement")
+	}
+	return settlement, nil
+}
+
+// buatSettlement creates a draft settlement with its details and marks its
+// transactions settled in one tx. Nil without an error means the period had
+// no transactions.
+func (s *PPOBService) buatSettlement(koperasiID uint64, dari, sampai time.Time, processedBy uint64) (*postgres.PPOBSettlement, error) {
 	transaksis, err := s.ppobRepo.GetTransaksiForSettlement(koperasiID, dari, sampai)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get transactions for settlement: %v", err)
 	}
-
 	if len(transaksis) == 0 {
-		return nil, fmt.Errorf("no transactions found for settlement")
+		return nil, nil
 	}
 
 	nomorSettlement, err := s.generateNomorSettlement(koperasiID)
@@ -155,68 +727,214 @@ func (s *PPOBService) CreateSettlement(koperasiID uint64, dari, sampai time.Time
 		return nil, fmt.Errorf("failed to generate settlement number: %v", err)
 	}
 
-	var totalOmzet, totalFeeAgen, totalAdminFee float64
-	var settlementDetails []postgres.PPOBSettlementDetail
+	var settlement *postgres.PPOBSettlement
+	err = s.ppobRepo.Transaction(func(tx *gorm.DB) error {
+		ppobRepo := s.ppobRepo.WithTx(tx)
+
+		var totalOmzet, totalFeeAgen, totalAdminFee money.Amount
+		var settlementDetails []postgres.PPOBSettlementDetail
+		var transaksiIDs []uint64
+
+		for _, transaksi := range transaksis {
+			totalOmzet += transaksi.HargaJual
+			totalFeeAgen += transaksi.FeeAgen
+			totalAdminFee += transaksi.AdminFee
+
+			settlementDetails = append(settlementDetails, postgres.PPOBSettlementDetail{
+				PPOBTransaksiID: transaksi.ID,
+				Omzet:           transaksi.HargaJual,
+				FeeAgen:         transaksi.FeeAgen,
+				AdminFee:        transaksi.AdminFee,
+			})
+			transaksiIDs = append(transaksiIDs, transaksi.ID)
+		}
 
-	for _, transaksi := range transaksis {
-		totalOmzet += transaksi.HargaJual
-		totalFeeAgen += transaksi.FeeAgen
-		totalAdminFee += transaksi.AdminFee
+		now := time.Now()
+		settlement = &postgres.PPOBSettlement{
+			KoperasiID:        koperasiID,
+			NomorSettlement:   nomorSettlement,
+			TanggalSettlement: now,
+			PeriodeDari:       dari,
+			PeriodeSampai:     sampai,
+			JumlahTransaksi:   len(transaksis),
+			TotalOmzet:        totalOmzet,
+			TotalFeeAgen:      totalFeeAgen,
+			TotalAdminFee:     totalAdminFee,
+			TotalSettlement:   totalFeeAgen + totalAdminFee,
+			Status:            postgres.PPOBSettlementDraft,
+			ProcessedBy:       processedBy,
+		}
 
-		detail := postgres.PPOBSettlementDetail{
-			PPOBTransaksiID: transaksi.ID,
-			Omzet:           transaksi.HargaJual,
-			FeeAgen:         transaksi.FeeAgen,
-			AdminFee:        transaksi.AdminFee,
+		if err := ppobRepo.CreateSettlement(settlement); err != nil {
+			return fmt.Errorf("failed to create settlement: %v", err)
+		}
+
+		for i := range settlementDetails {
+			settlementDetails[i].SettlementID = settlement.ID
+		}
+		if err := ppobRepo.CreateSettlementDetails(settlementDetails); err != nil {
+			return fmt.Errorf("failed to create settlement details: %v", err)
 		}
-		settlementDetails = append(settlementDetails, detail)
-	}
 
-	settlement := &postgres.PPOBSettlement{
-		KoperasiID:        koperasiID,
-		NomorSettlement:   nomorSettlement,
-		TanggalSettlement: time.Now(),
-		PeriodeDari:       dari,
-		PeriodeSampai:     sampai,
-		JumlahTransaksi:   len(transaksis),
-		TotalOmzet:        totalOmzet,
-		TotalFeeAgen:      totalFeeAgen,
-		TotalAdminFee:     totalAdminFee,
-		TotalSettlement:   totalFeeAgen + totalAdminFee,
-		Status:            "draft",
-		ProcessedBy:       processedBy,
+		settled, err := ppobRepo.MarkTransaksiSettled(transaksiIDs, now)
+		if err != nil {
+			return fmt.Errorf("failed to mark transactions as settled: %v", err)
+		}
+		if settled != int64(len(transaksiIDs)) {
+			return fmt.Errorf("some transactions were settled or refunded concurrently, please retry")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	err = s.ppobRepo.CreateSettlement(settlement)
+	return settlement, nil
+}
+
+// ProcessSettlement posts the agent fee and admin fee revenue journal of a
+// settlement and moves it from draft to processed.
+func (s *PPOBService) ProcessSettlement(id uint64, processedBy uint64) (*postgres.PPOBSettlement, error) {
+	err := s.ppobRepo.Transaction(func(tx *gorm.DB) error {
+		ppobRepo := s.ppobRepo.WithTx(tx)
+
+		settlement, err := ppobRepo.GetSettlementForUpdate(id)
+		if err != nil {
+			return fmt.Errorf("settlement not found: %v", err)
+		}
+		if settlement.Status != postgres.PPOBSettlementDraft {
+			return fmt.Errorf("settlement %s is already %s", settlement.NomorSettlement, settlement.Status)
+		}
+
+		jurnal, err := s.postingService.Post(tx, &PostingRequest{
+			KoperasiID:       settlement.KoperasiID,
+			KodeEvent:        PostingEventPPOBSettlement,
+			TanggalTransaksi: settlement.TanggalSettlement,
+			Referensi:        settlement.NomorSettlement,
+			Keterangan: fmt.Sprintf("Settlement PPOB %s periode %s s.d. %s", settlement.NomorSettlement,
+				settlement.PeriodeDari.Format("2006-01-02"), settlement.PeriodeSampai.Format("2006-01-02")),
+			SumberTransaksi: "ppob_settlement",
+			SumberID:        settlement.ID,
+			CreatedBy:       processedBy,
+			Komponen: map[string]money.Amount{
+				"total":     settlement.TotalSettlement,
+				"fee_agen":  settlement.TotalFeeAgen,
+				"admin_fee": settlement.TotalAdminFee,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to post jurnal: %v", err)
+		}
+		if jurnal != nil {
+			if err := ppobRepo.UpdateSettlementJurnal(settlement.ID, jurnal.ID); err != nil {
+				return err
+			}
+		}
+
+		return ppobRepo.UpdateSettlementStatus(settlement.ID, postgres.PPOBSettlementProcessed, processedBy)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create settlement: %v", err)
+		return nil, err
 	}
 
-	for i := range settlementDetails {
-		settlementDetails[i].SettlementID = settlement.ID
+	return s.ppobRepo.GetSettlementByID(id)
+}
+
+// PaySettlement records that the koperasi has received the money of a
+// processed settlement.
+func (s *PPOBService) PaySettlement(id uint64, paidBy uint64) (*postgres.PPOBSettlement, error) {
+	err := s.ppobRepo.Transaction(func(tx *gorm.DB) error {
+		ppobRepo := s.ppobRepo.WithTx(tx)
+
+		settlement, err := ppobRepo.GetSettlementForUpdate(id)
+		if err != nil {
+			return fmt.Errorf("settlement not found: %v", err)
+		}
+		if settlement.Status != postgres.PPOBSettlementProcessed {
+			return fmt.Errorf("only processed settlements can be marked as paid")
+		}
+
+		return ppobRepo.UpdateSettlementPaid(settlement.ID, paidBy, time.Now())
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	err = s.ppobRepo.CreateSettlementDetails(settlementDetails)
+	return s.ppobRepo.GetSettlementByID(id)
+}
+
+// RunAutoSettlement is run by the scheduler. For every koperasi with auto
+// settlement enabled, the last finished schedule period is settled and
+// processed right away; a period that already has a settlement is skipped,
+// so the job is safe to run repeatedly.
+func (s *PPOBService) RunAutoSettlement(now time.Time) error {
+	configs, err := s.ppobRepo.GetAutoSettlementConfigs()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create settlement details: %v", err)
+		return fmt.Errorf("failed to load auto settlement configs: %v", err)
 	}
 
-	var transaksiIDs []uint64
-	for _, transaksi := range transaksis {
-		transaksiIDs = append(transaksiIDs, transaksi.ID)
+	var errs []error
+	for _, config := range configs {
+		dari, sampai, err := periodeSettlement(config.SettlementSchedule, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("koperasi %d: %v", config.KoperasiID, err))
+			continue
+		}
+
+		exists, err := s.ppobRepo.HasSettlementForPeriode(config.KoperasiID, dari, sampai)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("koperasi %d: %v", config.KoperasiID, err))
+			continue
+		}
+		if exists {
+			continue
+		}
+
+		settlement, err := s.buatSettlement(config.KoperasiID, dari, sampai, SystemUserID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("koperasi %d: %v", config.KoperasiID, err))
+			continue
+		}
+		if settlement == nil {
+			continue
+		}
+
+		if _, err := s.ProcessSettlement(settlement.ID, SystemUserID); err != nil {
+			errs = append(errs, fmt.Errorf("settlement %s: %v", settlement.NomorSettlement, err))
+		}
 	}
 
-	err = s.ppobRepo.MarkTransaksiSettled(transaksiIDs)
-	if err != nil {
-		return nil, fmt.Errorf("failed to mark transactions as settled: %v", err)
+	return errors.Join(errs...)
+}
+
+// periodeSettlement returns the last schedule period finished at now:
+// yesterday, last week (Monday to Sunday) or last month. The end is one
+// microsecond before the next period so it fits a BETWEEN filter and the
+// Postgres timestamp precision.
+func periodeSettlement(jadwal string, now time.Time) (time.Time, time.Time, error) {
+	hariIni := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var dari, akhir time.Time
+	switch jadwal {
+	case postgres.PPOBSettlementHarian, "":
+		akhir = hariIni
+		dari = akhir.AddDate(0, 0, -1)
+	case postgres.PPOBSettlementMingguan:
+		akhir = hariIni.AddDate(0, 0, -((int(hariIni.Weekday()) + 6) % 7))
+		dari = akhir.AddDate(0, 0, -7)
+	case postgres.PPOBSettlementBulanan:
+		akhir = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		dari = akhir.AddDate(0, -1, 0)
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("unsupported settlement schedule %q", jadwal)
 	}
 
-	return settlement, nil
+	return dari, akhir.Add(-time.Microsecond), nil
 }
 
-func (s *PPOBService) calculatePPOBAdminFee(config *postgres.PPOBPaymentConfig, amount float64) float64 {
+func (s *PPOBService) calculatePPOBAdminFee(config *postgres.PPOBPaymentConfig, amount money.Amount) money.Amount {
 	if config.PPOBAdminFeeType == "percentage" {
-		return amount * config.PPOBAdminFee / 100
+		return amount.Percent(config.PPOBAdminFee.Float64(), money.RoundSen)
 	}
 	return config.PPOBAdminFee
 }
@@ -237,15 +955,29 @@ func (s *PPOBService) generateNomorSettlement(koperasiID uint64) (string, error)
 	return fmt.Sprintf("SET%04d%06d", koperasiID, number), nil
 }
 
+type PPOBInquiryRequest struct {
+	KoperasiID  uint64 `json:"koperasi_id" binding:"required"`
+	AnggotaID   uint64 `json:"anggota_id"`
+	ProdukID    uint64 `json:"produk_id" binding:"required"`
+	NomorTujuan string `json:"nomor_tujuan" binding:"required"`
+}
+
 type PPOBTransactionRequest struct {
-	KoperasiID        uint64 `json:"koperasi_id"`
-	AnggotaID         uint64 `json:"anggota_id"`
-	ProdukID          uint64 `json:"produk_id"`
-	NomorTujuan       string `json:"nomor_tujuan"`
-	NamaPelanggan     string `json:"nama_pelanggan"`
-	CustomerName      string `json:"customer_name"`
-	CustomerEmail     string `json:"customer_email"`
-	CustomerPhone     string `json:"customer_phone"`
-	PaymentProviderID uint64 `json:"payment_provider_id"`
-	PaymentMethodID   uint64 `json:"payment_method_id"`
-}
\ No newline at end of file
+	KoperasiID         uint64 `json:"koperasi_id"`
+	AnggotaID          uint64 `json:"anggota_id"`
+	ProdukID           uint64 `json:"produk_id"`
+	NomorInquiry       string `json:"nomor_inquiry"`
+	NomorTujuan        string `json:"nomor_tujuan"`
+	NamaPelanggan      string `json:"nama_pelanggan"`
+	MetodePembayaran   string `json:"metode_pembayaran" binding:"omitempty,oneof=gateway simpanan"`
+	RekeningSimpananID uint64 `json:"rekening_simpanan_id"`
+	CustomerName       string `json:"customer_name"`
+	CustomerEmail      string `json:"customer_email"`
+	CustomerPhone      string `json:"customer_phone"`
+	PaymentProviderID  uint64 `json:"payment_provider_id"`
+	PaymentMethodID    uint64 `json:"payment_method_id"`
+	CreatedBy          uint64 `json:"-"`
+	// PembeliAnggotaID is the logged-in member when the caller has the
+	// anggota role; it is zero for operators and admins.
+	PembeliAnggotaID uint64 `json:"-"`
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"koperasi-merah-putih/internal/models/postgres"
+)
+
+func TestPeriodeSettlement(t *testing.T) {
+	tanggal := func(year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+	}
+	akhirHari := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 23, 59, 59, 999999000, time.UTC)
+	}
+
+	tests := []struct {
+		name   string
+		jadwal string
+		now    time.Time
+		dari   time.Time
+		sampai time.Time
+	}{
+		{"daily", postgres.PPOBSettlementHarian, tanggal(2026, 3, 10, 14), tanggal(2026, 3, 9, 0), akhirHari(2026, 3, 9)},
+		{"daily at midnight", postgres.PPOBSettlementHarian, tanggal(2026, 3, 10, 0), tanggal(2026, 3, 9, 0), akhirHari(2026, 3, 9)},
+		{"daily across a year", postgres.PPOBSettlementHarian, tanggal(2026, 1, 1, 8), tanggal(2025, 12, 31, 0), akhirHari(2025, 12, 31)},
+		{"unset is daily", "", tanggal(2026, 3, 10, 14), tanggal(2026, 3, 9, 0), akhirHari(2026, 3, 9)},
+		{"weekly midweek", postgres.PPOBSettlementMingguan, tanggal(2026, 3, 11, 9), tanggal(2026, 3, 2, 0), akhirHari(2026, 3, 8)},
+		{"weekly on monday", postgres.PPOBSettlementMingguan, tanggal(2026, 3, 9, 0), tanggal(2026, 3, 2, 0), akhirHari(2026, 3, 8)},
+		{"weekly on sunday", postgres.PPOBSettlementMingguan, tanggal(2026, 3, 8, 23), tanggal(2026, 2, 23, 0), akhirHari(2026, 3, 1)},
+		{"monthly", postgres.PPOBSettlementBulanan, tanggal(2026, 3, 15, 10), tanggal(2026, 2, 1, 0), akhirHari(2026, 2, 28)},
+		{"monthly on the first", postgres.PPOBSettlementBulanan, tanggal(2026, 3, 1, 0), tanggal(2026, 2, 1, 0), akhirHari(2026, 2, 28)},
+		{"monthly leap year", postgres.PPOBSettlementBulanan, tanggal(2024, 3, 31, 23), tanggal(2024, 2, 1, 0), akhirHari(2024, 2, 29)},
+		{"monthly across a year", postgres.PPOBSettlementBulanan, tanggal(2026, 1, 5, 0), tanggal(2025, 12, 1, 0), akhirHari(2025, 12, 31)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dari, sampai, err := periodeSettlement(tt.jadwal, tt.now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !dari.Equal(tt.dari) || !sampai.Equal(tt.sampai) {
+				t.Errorf("got %s - %s, want %s - %s", dari, sampai, tt.dari, tt.sampai)
+			}
+		})
+	}
+
+	if _, _, err := periodeSettlement("hourly", tanggal(2026, 3, 10, 0)); err == nil {
+		t.Error("expected an error for an unsupported schedule")
+	}
+}
@@ -5,22 +5,34 @@ import (
 	"strconv"
 	"time"
 
+	"gorm.io/gorm"
 	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
 	repo "koperasi-merah-putih/internal/repository/postgres"
 )
 
 type ProdukService struct {
-	produkRepo    *repo.ProdukRepository
-	sequenceRepo  *repo.SequenceRepository
+	produkRepo          *repo.ProdukRepository
+	sequenceRepo        *repo.SequenceRepository
+	postingService      *PostingService
+	simpanPinjamService *SimpanPinjamService
+	pajakService        *PajakService
 }
 
-func NewProdukService(produkRepo *repo.ProdukRepository, sequenceRepo *repo.SequenceRepository) *ProdukService {
+func NewProdukService(produkRepo *repo.ProdukRepository, sequenceRepo *repo.SequenceRepository, postingService *PostingService, simpanPinjamService *SimpanPinjamService, pajakService *PajakService) *ProdukService {
 	return &ProdukService{
-		produkRepo:   produkRepo,
-		sequenceRepo: sequenceRepo,
+		produkRepo:          produkRepo,
+		sequenceRepo:        sequenceRepo,
+		postingService:      postingService,
+		simpanPinjamService: simpanPinjamService,
+		pajakService:        pajakService,
 	}
 }
 
+// TermPiutangDefault is the number of days a credit sale falls due when the
+// request does not set TanggalJatuhTempo.
+const TermPiutangDefault = 30
+
 // Request Structs
 type CreateKategoriProdukRequest struct {
 	Kode      string `json:"kode" binding:"required,max=20"`
@@ -48,6 +60,7 @@ type CreateSupplierRequest struct {
 	NamaBank       string `json:"nama_bank"`
 	AtasNamaBank   string `json:"atas_nama_bank"`
 	NPWP           string `json:"npwp"`
+	IsPKP          bool   `json:"is_pkp"`
 	JenisSupplier  string `json:"jenis_supplier" binding:"oneof=individu perusahaan koperasi"`
 	TermPembayaran int    `json:"term_pembayaran"`
 	CreatedBy      uint64 `json:"created_by"`
@@ -64,8 +77,8 @@ type CreateProdukRequest struct {
 	BeratBersih      float64 `json:"berat_bersih"`
 	Dimensi          string  `json:"dimensi"`
 	FotoProduk       string  `json:"foto_produk"`
-	HargaBeli        float64 `json:"harga_beli"`
-	HargaJual        float64 `json:"harga_jual" binding:"required,gt=0"`
+	HargaBeli        money.Amount `json:"harga_beli"`
+	HargaJual        money.Amount `json:"harga_jual" binding:"required,gt=0"`
 	MarginPersen     float64 `json:"margin_persen"`
 	StokMinimal      int     `json:"stok_minimal"`
 	StokMaksimal     int     `json:"stok_maksimal"`
@@ -87,52 +100,59 @@ type CreatePurchaseOrderRequest struct {
 type PurchaseOrderDetailRequest struct {
 	ProdukID    uint64  `json:"produk_id" binding:"required"`
 	Qty         int     `json:"qty" binding:"required,gt=0"`
-	HargaSatuan float64 `json:"harga_satuan" binding:"required,gt=0"`
+	HargaSatuan money.Amount `json:"harga_satuan" binding:"required,gt=0"`
 	Keterangan  string  `json:"keterangan"`
 }
 
 type CreatePembelianRequest struct {
-	KoperasiID       uint64                   `json:"koperasi_id" binding:"required"`
-	SupplierID       uint64                   `json:"supplier_id" binding:"required"`
-	PurchaseOrderID  uint64                   `json:"purchase_order_id"`
-	NomorFaktur      string                   `json:"nomor_faktur" binding:"required"`
-	TanggalFaktur    time.Time                `json:"tanggal_faktur" binding:"required"`
-	TanggalJatuhTempo *time.Time              `json:"tanggal_jatuh_tempo"`
-	PajakPersen      float64                  `json:"pajak_persen"`
-	BiayaKirim       float64                  `json:"biaya_kirim"`
-	Diskon           float64                  `json:"diskon"`
-	Keterangan       string                   `json:"keterangan"`
-	Items            []PembelianDetailRequest `json:"items" binding:"required,min=1"`
-	CreatedBy        uint64                   `json:"created_by"`
+	KoperasiID        uint64                   `json:"koperasi_id" binding:"required"`
+	SupplierID        uint64                   `json:"supplier_id" binding:"required"`
+	PurchaseOrderID   uint64                   `json:"purchase_order_id"`
+	NomorFaktur       string                   `json:"nomor_faktur" binding:"required"`
+	NomorFakturPajak  string                   `json:"nomor_faktur_pajak"`
+	TanggalFaktur     time.Time                `json:"tanggal_faktur" binding:"required"`
+	TanggalJatuhTempo *time.Time               `json:"tanggal_jatuh_tempo"`
+	PajakPersen       float64                  `json:"pajak_persen"`
+	BiayaKirim        money.Amount             `json:"biaya_kirim"`
+	Diskon            money.Amount             `json:"diskon"`
+	Keterangan        string                   `json:"keterangan"`
+	Items             []PembelianDetailRequest `json:"items" binding:"required,min=1"`
+	CreatedBy         uint64                   `json:"created_by"`
 }
 
 type PembelianDetailRequest struct {
 	ProdukID       uint64     `json:"produk_id" binding:"required"`
 	Qty            int        `json:"qty" binding:"required,gt=0"`
-	HargaSatuan    float64    `json:"harga_satuan" binding:"required,gt=0"`
+	HargaSatuan    money.Amount `json:"harga_satuan" binding:"required,gt=0"`
 	TanggalExpired *time.Time `json:"tanggal_expired"`
 	BatchNumber    string     `json:"batch_number"`
 	Keterangan     string     `json:"keterangan"`
 }
 
+// CreatePenjualanRequest is a sale at the till. For a credit sale
+// JumlahBayar is the down payment, which may be zero; for a simpanan sale it
+// is ignored and the total is debited from RekeningSimpananID.
 type CreatePenjualanRequest struct {
-	KoperasiID       uint64                   `json:"koperasi_id" binding:"required"`
-	AnggotaID        uint64                   `json:"anggota_id"`
-	TanggalTransaksi time.Time                `json:"tanggal_transaksi" binding:"required"`
-	MetodePembayaran string                   `json:"metode_pembayaran" binding:"oneof=cash debit credit transfer simpanan"`
-	JumlahBayar      float64                  `json:"jumlah_bayar" binding:"required,gt=0"`
-	Kasir            string                   `json:"kasir"`
-	Keterangan       string                   `json:"keterangan"`
-	Items            []PenjualanDetailRequest `json:"items" binding:"required,min=1"`
-	CreatedBy        uint64                   `json:"created_by"`
+	KoperasiID         uint64                   `json:"koperasi_id" binding:"required"`
+	AnggotaID          uint64                   `json:"anggota_id"`
+	TanggalTransaksi   time.Time                `json:"tanggal_transaksi" binding:"required"`
+	MetodePembayaran   string                   `json:"metode_pembayaran" binding:"oneof=cash debit credit transfer simpanan"`
+	JumlahBayar        money.Amount             `json:"jumlah_bayar" binding:"min=0"`
+	TanggalJatuhTempo  *time.Time               `json:"tanggal_jatuh_tempo"`
+	RekeningSimpananID uint64                   `json:"rekening_simpanan_id"`
+	Kasir              string                   `json:"kasir"`
+	NomorFakturPajak   string                   `json:"nomor_faktur_pajak"`
+	Keterangan         string                   `json:"keterangan"`
+	Items              []PenjualanDetailRequest `json:"items" binding:"required,min=1"`
+	CreatedBy          uint64                   `json:"created_by"`
 }
 
 type PenjualanDetailRequest struct {
 	ProdukID     uint64  `json:"produk_id" binding:"required"`
 	Qty          int     `json:"qty" binding:"required,gt=0"`
-	HargaSatuan  float64 `json:"harga_satuan" binding:"required,gt=0"`
+	HargaSatuan  money.Amount `json:"harga_satuan" binding:"required,gt=0"`
 	DiskonPersen float64 `json:"diskon_persen"`
-	DiskonRupiah float64 `json:"diskon_rupiah"`
+	DiskonRupiah money.Amount `json:"diskon_rupiah"`
 	Keterangan   string  `json:"keterangan"`
 }
 
@@ -196,6 +216,7 @@ func (s *ProdukService) CreateSupplier(req *CreateSupplierRequest) (*postgres.Su
 		NamaBank:       req.NamaBank,
 		AtasNamaBank:   req.AtasNamaBank,
 		NPWP:           req.NPWP,
+		IsPKP:          req.IsPKP,
 		JenisSupplier:  req.JenisSupplier,
 		TermPembayaran: req.TermPembayaran,
 		Status:         "aktif",
@@ -259,7 +280,7 @@ func (s *ProdukService) CreateProduk(req *CreateProdukRequest) (*postgres.Produk
 	}
 
 	if req.MarginPersen == 0 && req.HargaBeli > 0 {
-		produk.MarginPersen = ((req.HargaJual - req.HargaBeli) / req.HargaBeli) * 100
+		produk.MarginPersen = (req.HargaJual - req.HargaBeli).Ratio(req.HargaBeli) * 100
 	}
 
 	if err := s.produkRepo.CreateProduk(produk); err != nil {
@@ -322,23 +343,35 @@ func (s *ProdukService) CreatePurchaseOrder(req *CreatePurchaseOrderRequest) (*p
 
 	nomorPO := fmt.Sprintf("PO%04d%06d", req.KoperasiID, sequence)
 
+	supplier, err := s.getSupplierKoperasi(req.SupplierID, req.KoperasiID)
+	if err != nil {
+		return nil, err
+	}
+
 	var totalItem int
-	var subTotal float64
+	var subTotal money.Amount
 	var details []postgres.PurchaseOrderDetail
+	var items []itemPajak
 
 	for _, item := range req.Items {
 		detail := postgres.PurchaseOrderDetail{
 			ProdukID:    item.ProdukID,
 			Qty:         item.Qty,
 			HargaSatuan: item.HargaSatuan,
-			Subtotal:    float64(item.Qty) * item.HargaSatuan,
+			Subtotal:    item.HargaSatuan.MulInt(int64(item.Qty)),
 			Keterangan:  item.Keterangan,
 		}
 		details = append(details, detail)
+		items = append(items, itemPajak{ProdukID: detail.ProdukID, Jumlah: detail.Subtotal})
 		totalItem += item.Qty
 		subTotal += detail.Subtotal
 	}
 
+	_, totalPajak, pajakPersen, err := s.pajakService.ppnPembelian(req.KoperasiID, supplier, items, 0)
+	if err != nil {
+		return nil, err
+	}
+
 	po := &postgres.PurchaseOrder{
 		KoperasiID:          req.KoperasiID,
 		SupplierID:          req.SupplierID,
@@ -346,7 +379,9 @@ func (s *ProdukService) CreatePurchaseOrder(req *CreatePurchaseOrderRequest) (*p
 		TanggalPO:           req.TanggalPO,
 		TotalItem:           totalItem,
 		SubTotal:            subTotal,
-		GrandTotal:          subTotal,
+		PajakPersen:         pajakPersen,
+		TotalPajak:          totalPajak,
+		GrandTotal:          subTotal + totalPajak,
 		Status:              "draft",
 		Keterangan:          req.Keterangan,
 		PurchaseOrderDetail: details,
@@ -375,26 +410,40 @@ func (s *ProdukService) GetPurchaseOrdersByKoperasi(koperasiID uint64, page, lim
 
 // Pembelian Services
 func (s *ProdukService) CreatePembelian(req *CreatePembelianRequest) (*postgres.PembelianHeader, error) {
+	supplier, err := s.getSupplierKoperasi(req.SupplierID, req.KoperasiID)
+	if err != nil {
+		return nil, err
+	}
+
 	var totalItem int
-	var subTotal float64
+	var subTotal money.Amount
 	var details []postgres.PembelianDetail
+	var items []itemPajak
 
 	for _, item := range req.Items {
 		detail := postgres.PembelianDetail{
 			ProdukID:       item.ProdukID,
 			Qty:            item.Qty,
 			HargaSatuan:    item.HargaSatuan,
-			Subtotal:       float64(item.Qty) * item.HargaSatuan,
+			Subtotal:       item.HargaSatuan.MulInt(int64(item.Qty)),
 			TanggalExpired: item.TanggalExpired,
 			BatchNumber:    item.BatchNumber,
 			Keterangan:     item.Keterangan,
 		}
 		details = append(details, detail)
+		items = append(items, itemPajak{ProdukID: detail.ProdukID, Jumlah: detail.Subtotal})
 		totalItem += item.Qty
 		subTotal += detail.Subtotal
 	}
 
-	totalPajak := subTotal * (req.PajakPersen / 100)
+	dpp, totalPajak, pajakPersen, err := s.pajakService.ppnPembelian(req.KoperasiID, supplier, items, req.PajakPersen)
+	if err != nil {
+		return nil, err
+	}
+	pengaturan, err := s.pajakService.GetPengaturan(req.KoperasiID)
+	if err != nil {
+		return nil, err
+	}
 	grandTotal := subTotal + totalPajak + req.BiayaKirim - req.Diskon
 
 	pembelian := &postgres.PembelianHeader{
@@ -402,11 +451,13 @@ func (s *ProdukService) CreatePembelian(req *CreatePembelianRequest) (*postgres.
 		SupplierID:        req.SupplierID,
 		PurchaseOrderID:   req.PurchaseOrderID,
 		NomorFaktur:       req.NomorFaktur,
+		NomorFakturPajak:  req.NomorFakturPajak,
 		TanggalFaktur:     req.TanggalFaktur,
 		TanggalJatuhTempo: req.TanggalJatuhTempo,
 		TotalItem:         totalItem,
 		SubTotal:          subTotal,
-		PajakPersen:       req.PajakPersen,
+		DPP:               dpp,
+		PajakPersen:       pajakPersen,
 		TotalPajak:        totalPajak,
 		BiayaKirim:        req.BiayaKirim,
 		Diskon:            req.Diskon,
@@ -418,8 +469,47 @@ func (s *ProdukService) CreatePembelian(req *CreatePembelianRequest) (*postgres.
 		UpdatedBy:         req.CreatedBy,
 	}
 
-	if err := s.produkRepo.CreatePembelian(pembelian); err != nil {
-		return nil, fmt.Errorf("failed to create pembelian: %v", err)
+	// PPN the koperasi cannot credit is posted with the goods, not as masukan.
+	komponenSubtotal, komponenPajak := pembelian.SubTotal, pembelian.TotalPajak
+	if !kreditPPNMasukan(pengaturan, pembelian.TanggalFaktur) {
+		komponenSubtotal, komponenPajak = pembelian.SubTotal+pembelian.TotalPajak, 0
+	}
+
+	err = s.produkRepo.Transaction(func(tx *gorm.DB) error {
+		produkRepo := s.produkRepo.WithTx(tx)
+		if err := produkRepo.CreatePembelian(pembelian); err != nil {
+			return fmt.Errorf("failed to create pembelian: %v", err)
+		}
+
+		jurnal, err := s.postingService.Post(tx, &PostingRequest{
+			KoperasiID:       pembelian.KoperasiID,
+			KodeEvent:        PostingEventPembelian,
+			TanggalTransaksi: pembelian.TanggalFaktur,
+			Referensi:        pembelian.NomorFaktur,
+			Keterangan:       fmt.Sprintf("Pembelian faktur %s", pembelian.NomorFaktur),
+			SumberTransaksi:  "pembelian_header",
+			SumberID:         pembelian.ID,
+			Komponen: map[string]money.Amount{
+				"total":       pembelian.GrandTotal,
+				"subtotal":    komponenSubtotal,
+				"pajak":       komponenPajak,
+				"biaya_kirim": pembelian.BiayaKirim,
+				"diskon":      pembelian.Diskon,
+			},
+			CreatedBy: pembelian.CreatedBy,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to post jurnal: %v", err)
+		}
+
+		if jurnal != nil {
+			pembelian.JurnalID = jurnal.ID
+			return produkRepo.UpdatePembelianJurnal(pembelian.ID, jurnal.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return pembelian, nil
@@ -427,6 +517,14 @@ func (s *ProdukService) CreatePembelian(req *CreatePembelianRequest) (*postgres.
 
 // Penjualan Services
 func (s *ProdukService) CreatePenjualan(req *CreatePenjualanRequest) (*postgres.PenjualanHeader, error) {
+	var nomorFaktur string
+	if req.NomorFakturPajak != "" {
+		var err error
+		if nomorFaktur, err = nomorSeriFakturPajak(req.NomorFakturPajak); err != nil {
+			return nil, err
+		}
+	}
+
 	sequence, err := s.sequenceRepo.GetNextSequenceNumber(1, req.KoperasiID, "penjualan")
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate transaction number: %v", err)
@@ -435,13 +533,14 @@ func (s *ProdukService) CreatePenjualan(req *CreatePenjualanRequest) (*postgres.
 	nomorTransaksi := fmt.Sprintf("TRX%04d%06d", req.KoperasiID, sequence)
 
 	var totalItem int
-	var subTotal float64
+	var subTotal money.Amount
 	var details []postgres.PenjualanDetail
+	var items []itemPajak
 
 	for _, item := range req.Items {
-		subtotal := (float64(item.Qty) * item.HargaSatuan) - item.DiskonRupiah
+		subtotal := item.HargaSatuan.MulInt(int64(item.Qty)) - item.DiskonRupiah
 		if item.DiskonPersen > 0 {
-			subtotal = subtotal * (1 - item.DiskonPersen/100)
+			subtotal -= subtotal.Percent(item.DiskonPersen, money.RoundDiskon)
 		}
 
 		detail := postgres.PenjualanDetail{
@@ -454,27 +553,33 @@ func (s *ProdukService) CreatePenjualan(req *CreatePenjualanRequest) (*postgres.
 			Keterangan:   item.Keterangan,
 		}
 		details = append(details, detail)
+		items = append(items, itemPajak{ProdukID: detail.ProdukID, Jumlah: detail.Subtotal})
 		totalItem += item.Qty
 		subTotal += detail.Subtotal
 	}
 
-	kembalian := req.JumlahBayar - subTotal
-	if kembalian < 0 {
-		return nil, fmt.Errorf("jumlah bayar tidak mencukupi")
+	// PPN is charged on top of the shelf price.
+	dpp, totalPajak, pajakPersen, err := s.pajakService.ppnPenjualan(req.KoperasiID, items)
+	if err != nil {
+		return nil, err
 	}
+	grandTotal := subTotal + totalPajak
 
 	penjualan := &postgres.PenjualanHeader{
 		KoperasiID:       req.KoperasiID,
 		AnggotaID:        req.AnggotaID,
 		NomorTransaksi:   nomorTransaksi,
+		NomorFakturPajak: nomorFaktur,
 		TanggalTransaksi: req.TanggalTransaksi,
 		TotalItem:        totalItem,
 		SubTotal:         subTotal,
-		GrandTotal:       subTotal,
+		DPP:              dpp,
+		PajakPersen:      pajakPersen,
+		TotalPajak:       totalPajak,
+		GrandTotal:       grandTotal,
 		MetodePembayaran: req.MetodePembayaran,
 		StatusPembayaran: "paid",
 		JumlahBayar:      req.JumlahBayar,
-		JumlahKembalian:  kembalian,
 		Kasir:            req.Kasir,
 		Keterangan:       req.Keterangan,
 		PenjualanDetail:  details,
@@ -482,13 +587,144 @@ func (s *ProdukService) CreatePenjualan(req *CreatePenjualanRequest) (*postgres.
 		UpdatedBy:        req.CreatedBy,
 	}
 
-	if err := s.produkRepo.CreatePenjualan(penjualan); err != nil {
-		return nil, fmt.Errorf("failed to create penjualan: %v", err)
+	// kas, piutang and simpanan split the total by how it is settled, so a
+	// posting rule can debit the right account for each payment method.
+	var kas, piutang, simpanan money.Amount
+	switch req.MetodePembayaran {
+	case "credit":
+		if req.AnggotaID == 0 {
+			return nil, fmt.Errorf("anggota is required for a credit sale")
+		}
+		dp := req.JumlahBayar
+		if dp > grandTotal {
+			return nil, fmt.Errorf("down payment exceeds the sale total")
+		}
+
+		jatuhTempo := req.TanggalJatuhTempo
+		if jatuhTempo == nil {
+			t := req.TanggalTransaksi.AddDate(0, 0, TermPiutangDefault)
+			jatuhTempo = &t
+		}
+		if jatuhTempo.Before(req.TanggalTransaksi) {
+			return nil, fmt.Errorf("tanggal jatuh tempo is before the sale date")
+		}
+
+		penjualan.JumlahBayar = dp
+		penjualan.TotalTerbayar = dp
+		penjualan.TanggalJatuhTempo = jatuhTempo
+		switch {
+		case dp >= grandTotal:
+			penjualan.StatusPembayaran = "paid"
+		case dp > 0:
+			penjualan.StatusPembayaran = "partial"
+		default:
+			penjualan.StatusPembayaran = "unpaid"
+		}
+		kas = dp
+		piutang = grandTotal - dp
+	case "simpanan":
+		if req.AnggotaID == 0 || req.RekeningSimpananID == 0 {
+			return nil, fmt.Errorf("anggota and rekening simpanan are required for a simpanan sale")
+		}
+		if err := s.simpanPinjamService.cekRekeningAnggota(req.RekeningSimpananID, req.AnggotaID, req.KoperasiID); err != nil {
+			return nil, err
+		}
+		penjualan.JumlahBayar = grandTotal
+		penjualan.TotalTerbayar = grandTotal
+		simpanan = grandTotal
+	default:
+		kembalian := req.JumlahBayar - grandTotal
+		if kembalian < 0 {
+			return nil, fmt.Errorf("jumlah bayar tidak mencukupi")
+		}
+		penjualan.JumlahKembalian = kembalian
+		penjualan.TotalTerbayar = grandTotal
+		kas = grandTotal
+	}
+
+	hpp, err := s.calculateHPP(details)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.produkRepo.Transaction(func(tx *gorm.DB) error {
+		produkRepo := s.produkRepo.WithTx(tx)
+		if err := produkRepo.CreatePenjualan(penjualan); err != nil {
+			return fmt.Errorf("failed to create penjualan: %v", err)
+		}
+
+		if simpanan > 0 {
+			_, err := s.simpanPinjamService.mutasiSimpanan(tx, req.RekeningSimpananID, "penarikan", simpanan,
+				fmt.Sprintf("Pembayaran penjualan %s", penjualan.NomorTransaksi), penjualan.NomorTransaksi, penjualan.CreatedBy)
+			if err != nil {
+				return fmt.Errorf("failed to debit simpanan: %v", err)
+			}
+		}
+
+		jurnal, err := s.postingService.Post(tx, &PostingRequest{
+			KoperasiID:       penjualan.KoperasiID,
+			KodeEvent:        PostingEventPenjualan,
+			TanggalTransaksi: penjualan.TanggalTransaksi,
+			Referensi:        penjualan.NomorTransaksi,
+			Keterangan:       fmt.Sprintf("Penjualan %s", penjualan.NomorTransaksi),
+			SumberTransaksi:  "penjualan_header",
+			SumberID:         penjualan.ID,
+			AnggotaID:        penjualan.AnggotaID,
+			Komponen: map[string]money.Amount{
+				"total":    penjualan.GrandTotal,
+				"subtotal": penjualan.SubTotal,
+				"pajak":    penjualan.TotalPajak,
+				"diskon":   penjualan.Diskon,
+				"hpp":      hpp,
+				"kas":      kas,
+				"piutang":  piutang,
+				"simpanan": simpanan,
+			},
+			CreatedBy: penjualan.CreatedBy,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to post jurnal: %v", err)
+		}
+
+		if jurnal != nil {
+			penjualan.JurnalID = jurnal.ID
+			return produkRepo.UpdatePenjualanJurnal(penjualan.ID, jurnal.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return penjualan, nil
 }
 
+// getSupplierKoperasi loads a supplier and checks it trades with the
+// koperasi.
+func (s *ProdukService) getSupplierKoperasi(supplierID, koperasiID uint64) (*postgres.Supplier, error) {
+	supplier, err := s.produkRepo.GetSupplierByID(supplierID)
+	if err != nil {
+		return nil, fmt.Errorf("supplier not found: %v", err)
+	}
+	if supplier.KoperasiID != koperasiID {
+		return nil, fmt.Errorf("supplier belongs to another koperasi")
+	}
+	return supplier, nil
+}
+
+// calculateHPP values the goods sold at each product's current harga beli.
+func (s *ProdukService) calculateHPP(details []postgres.PenjualanDetail) (money.Amount, error) {
+	var hpp money.Amount
+	for _, detail := range details {
+		produk, err := s.produkRepo.GetProdukByID(detail.ProdukID)
+		if err != nil {
+			return 0, fmt.Errorf("produk %d not found: %v", detail.ProdukID, err)
+		}
+		hpp += produk.HargaBeli.MulInt(int64(detail.Qty))
+	}
+	return hpp, nil
+}
+
 // Report Services
 func (s *ProdukService) GetStokReport(koperasiID uint64) ([]postgres.Produk, error) {
 	return s.produkRepo.GetStokReport(koperasiID)
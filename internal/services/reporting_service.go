@@ -6,6 +6,7 @@ import (
 
 	"koperasi-merah-putih/internal/cache"
 	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
 	repo "koperasi-merah-putih/internal/repository/postgres"
 )
 
@@ -16,6 +17,7 @@ type ReportingService struct {
 	simpanPinjamRepo *repo.SimpanPinjamRepository
 	financialRepo    *repo.FinancialRepository
 	klinikRepo       *repo.KlinikRepository
+	financialService *FinancialService
 	cache            *cache.RedisCache
 }
 
@@ -26,6 +28,7 @@ func NewReportingService(
 	simpanPinjamRepo *repo.SimpanPinjamRepository,
 	financialRepo *repo.FinancialRepository,
 	klinikRepo *repo.KlinikRepository,
+	financialService *FinancialService,
 	cache *cache.RedisCache,
 ) *ReportingService {
 	return &ReportingService{
@@ -35,6 +38,7 @@ func NewReportingService(
 		simpanPinjamRepo: simpanPinjamRepo,
 		financialRepo:    financialRepo,
 		klinikRepo:       klinikRepo,
+		financialService: financialService,
 		cache:            cache,
 	}
 }
@@ -83,7 +87,7 @@ type ProductMetrics struct {
 	ExpiringProducts   []ExpiringProduct  `json:"expiring_products"`
 	FastMovingProducts []FastMovingProduct `json:"fast_moving_products"`
 	SlowMovingProducts []SlowMovingProduct `json:"slow_moving_products"`
-	StockValue         float64            `json:"total_stock_value"`
+	StockValue         money.Amount       `json:"total_stock_value"`
 	CategoryBreakdown  []CategoryStock    `json:"category_breakdown"`
 }
 
@@ -528,35 +532,34 @@ func (s *ReportingService) GenerateMemberReport(koperasiID uint64) (interface{},
 }
 
 func (s *ReportingService) generateCashFlowReport(koperasiID uint64, period string) (interface{}, error) {
-	// Simplified cash flow report
+	dari := parsePeriod(period)
+	if dari.IsZero() {
+		return nil, fmt.Errorf("invalid period %s, use YYYY-MM", period)
+	}
+	sampai := dari.AddDate(0, 1, -1)
+
+	langsung, err := s.financialService.GetArusKas(koperasiID, dari, sampai, "langsung")
+	if err != nil {
+		return nil, err
+	}
+
+	tidakLangsung, err := s.financialService.GetArusKas(koperasiID, dari, sampai, "tidak_langsung")
+	if err != nil {
+		return nil, err
+	}
+
 	return map[string]interface{}{
 		"period": period,
-		"cash_flow": map[string]interface{}{
-			"operating_activities": map[string]float64{
-				"cash_from_sales":    1250000000,
-				"cash_to_suppliers": -750000000,
-				"cash_to_employees": -120000000,
-				"net_operating":      380000000,
-			},
-			"investing_activities": map[string]float64{
-				"equipment_purchase": -50000000,
-				"net_investing":      -50000000,
-			},
-			"financing_activities": map[string]float64{
-				"loan_proceeds":   200000000,
-				"loan_repayment": -150000000,
-				"net_financing":   50000000,
-			},
-			"net_cash_flow": 380000000,
-		},
+		"langsung":       langsung,
+		"tidak_langsung": tidakLangsung,
 	}, nil
 }
 
 // Helper functions
-func calculateStockValue(products []postgres.Produk) float64 {
-	total := 0.0
+func calculateStockValue(products []postgres.Produk) money.Amount {
+	var total money.Amount
 	for _, p := range products {
-		total += float64(p.StokCurrent) * p.HargaBeli
+		total += p.HargaBeli.MulInt(int64(p.StokCurrent))
 	}
 	return total
 }
@@ -597,19 +600,19 @@ func mapExpiringProducts(products []postgres.Produk) []ExpiringProduct {
 	return result
 }
 
-func calculateTotalSales(sales []postgres.PenjualanHeader) float64 {
-	total := 0.0
+func calculateTotalSales(sales []postgres.PenjualanHeader) money.Amount {
+	var total money.Amount
 	for _, s := range sales {
 		total += s.GrandTotal
 	}
 	return total
 }
 
-func calculateAverageTransaction(sales []postgres.PenjualanHeader) float64 {
+func calculateAverageTransaction(sales []postgres.PenjualanHeader) money.Amount {
 	if len(sales) == 0 {
 		return 0
 	}
-	return calculateTotalSales(sales) / float64(len(sales))
+	return calculateTotalSales(sales).Div(int64(len(sales)), money.RoundSen)
 }
 
 func parsePeriod(period string) time.Time {
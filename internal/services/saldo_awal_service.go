@@ -0,0 +1,1125 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+)
+
+// SumberSaldoAwal marks the opening journal. It can't be reversed, and no
+// journal can be dated on or before its cutover.
+const SumberSaldoAwal = "saldo_awal"
+
+type SaldoAwalService struct {
+	saldoAwalRepo       *postgresRepo.SaldoAwalRepository
+	financialRepo       *postgresRepo.FinancialRepository
+	periodeRepo         *postgresRepo.PeriodeRepository
+	postingRepo         *postgresRepo.PostingRepository
+	simpanPinjamRepo    *postgresRepo.SimpanPinjamRepository
+	anggotaRepo         *postgresRepo.AnggotaKoperasiRepository
+	koperasiRepo        *postgresRepo.KoperasiRepository
+	asetRepo            *postgresRepo.AsetRepository
+	produkRepo          *postgresRepo.ProdukRepository
+	financialService    *FinancialService
+	simpanPinjamService *SimpanPinjamService
+}
+
+func NewSaldoAwalService(
+	saldoAwalRepo *postgresRepo.SaldoAwalRepository,
+	financialRepo *postgresRepo.FinancialRepository,
+	periodeRepo *postgresRepo.PeriodeRepository,
+	postingRepo *postgresRepo.PostingRepository,
+	simpanPinjamRepo *postgresRepo.SimpanPinjamRepository,
+	anggotaRepo *postgresRepo.AnggotaKoperasiRepository,
+	koperasiRepo *postgresRepo.KoperasiRepository,
+	asetRepo *postgresRepo.AsetRepository,
+	produkRepo *postgresRepo.ProdukRepository,
+	financialService *FinancialService,
+	simpanPinjamService *SimpanPinjamService,
+) *SaldoAwalService {
+	return &SaldoAwalService{
+		saldoAwalRepo:       saldoAwalRepo,
+		financialRepo:       financialRepo,
+		periodeRepo:         periodeRepo,
+		postingRepo:         postingRepo,
+		simpanPinjamRepo:    simpanPinjamRepo,
+		anggotaRepo:         anggotaRepo,
+		koperasiRepo:        koperasiRepo,
+		asetRepo:            asetRepo,
+		produkRepo:          produkRepo,
+		financialService:    financialService,
+		simpanPinjamService: simpanPinjamService,
+	}
+}
+
+// CreateMigrasi starts the wizard, or moves the cutover of a draft one. The
+// cutover has to be a month end so the periods up to it can be locked whole.
+func (s *SaldoAwalService) CreateMigrasi(req *CreateSaldoAwalRequest, createdBy uint64) (*postgres.MigrasiSaldoAwal, error) {
+	if req.TanggalCutover.AddDate(0, 0, 1).Day() != 1 {
+		return nil, fmt.Errorf("tanggal_cutover must be the last day of a month")
+	}
+
+	koperasi, err := s.koperasiRepo.GetByID(req.KoperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("koperasi not found: %v", err)
+	}
+
+	migrasi, err := s.saldoAwalRepo.GetMigrasiByKoperasi(req.KoperasiID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to load saldo awal: %v", err)
+	}
+
+	if migrasi == nil {
+		migrasi = &postgres.MigrasiSaldoAwal{
+			TenantID:       koperasi.TenantID,
+			KoperasiID:     req.KoperasiID,
+			TanggalCutover: req.TanggalCutover,
+			Status:         "draft",
+			Keterangan:     req.Keterangan,
+			CreatedBy:      createdBy,
+		}
+		err = s.saldoAwalRepo.CreateMigrasi(migrasi)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create saldo awal: %v", err)
+		}
+		return migrasi, nil
+	}
+
+	if migrasi.Status != "draft" {
+		return nil, fmt.Errorf("saldo awal has already been posted")
+	}
+
+	// Loan schedules are counted from the cutover, so moving it drops the
+	// uploaded rekening; they have to be uploaded again.
+	geser := !migrasi.TanggalCutover.Equal(req.TanggalCutover)
+	migrasi.TanggalCutover = req.TanggalCutover
+	if req.Keterangan != "" {
+		migrasi.Keterangan = req.Keterangan
+	}
+	err = s.saldoAwalRepo.Transaction(func(tx *gorm.DB) error {
+		saldoAwalRepo := s.saldoAwalRepo.WithTx(tx)
+		if geser {
+			if err := saldoAwalRepo.ReplaceRekening(migrasi.ID, nil); err != nil {
+				return err
+			}
+			migrasi.Rekening = nil
+		}
+		return saldoAwalRepo.UpdateMigrasi(migrasi)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update saldo awal: %v", err)
+	}
+	return migrasi, nil
+}
+
+func (s *SaldoAwalService) GetMigrasi(koperasiID uint64) (*postgres.MigrasiSaldoAwal, error) {
+	return s.saldoAwalRepo.GetMigrasiByKoperasi(koperasiID)
+}
+
+// ImportNeraca replaces the draft's trial balance with a CSV or XLSX file
+// with columns kode_akun, debit and kredit. Rows with neither amount are
+// skipped. The file does not have to balance yet; Rekonsiliasi reports it.
+func (s *SaldoAwalService) ImportNeraca(koperasiID uint64, format string, r io.Reader) (*ImportSaldoAwalResult, error) {
+	records, err := readImportRecords(format, r)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("file is empty")
+	}
+
+	kolom := importKolom(records[0])
+	for _, wajib := range []string{"kode_akun", "debit", "kredit"} {
+		if _, ok := kolom[wajib]; !ok {
+			return nil, fmt.Errorf("column %s is missing", wajib)
+		}
+	}
+
+	akuns, err := s.financialRepo.GetCOAAkunAll(koperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load akun: %v", err)
+	}
+	akunByKode := make(map[string]postgres.COAAkun, len(akuns))
+	for _, akun := range akuns {
+		akunByKode[akun.KodeAkun] = akun
+	}
+
+	var lines []postgres.MigrasiSaldoAwalAkun
+	var errs []string
+	dipakai := make(map[string]int)
+	for n, record := range records[1:] {
+		baris := n + 2
+		if strings.Join(record, "") == "" {
+			continue
+		}
+
+		kode := importField(kolom, record, "kode_akun")
+		debit, errDebit := money.Parse(importField(kolom, record, "debit"))
+		kredit, errKredit := money.Parse(importField(kolom, record, "kredit"))
+		switch {
+		case errDebit != nil || errKredit != nil:
+			errs = append(errs, fmt.Sprintf("row %d: invalid amount", baris))
+			continue
+		case debit < 0 || kredit < 0:
+			errs = append(errs, fmt.Sprintf("row %d: amounts cannot be negative", baris))
+			continue
+		case debit == 0 && kredit == 0:
+			continue
+		case debit != 0 && kredit != 0:
+			errs = append(errs, fmt.Sprintf("row %d: fill either debit or kredit", baris))
+			continue
+		}
+
+		akun, ok := akunByKode[kode]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("row %d: kode_akun %q not found", baris, kode))
+			continue
+		}
+		if !akun.IsAktif {
+			errs = append(errs, fmt.Sprintf("row %d: akun %s is not active", baris, kode))
+			continue
+		}
+		if prev, ok := dipakai[kode]; ok {
+			errs = append(errs, fmt.Sprintf("row %d: akun %s already on row %d", baris, kode, prev))
+			continue
+		}
+		dipakai[kode] = baris
+
+		lines = append(lines, postgres.MigrasiSaldoAwalAkun{
+			AkunID: akun.ID,
+			Debit:  debit,
+			Kredit: kredit,
+		})
+	}
+
+	if len(errs) > 0 {
+		return nil, saldoAwalErrors(errs)
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("file has no balances")
+	}
+
+	var totalDebit, totalKredit money.Amount
+	for _, line := range lines {
+		totalDebit += line.Debit
+		totalKredit += line.Kredit
+	}
+
+	err = s.saldoAwalRepo.Transaction(func(tx *gorm.DB) error {
+		saldoAwalRepo := s.saldoAwalRepo.WithTx(tx)
+
+		migrasi, err := s.getDraftForUpdate(saldoAwalRepo, koperasiID)
+		if err != nil {
+			return err
+		}
+		for i := range lines {
+			lines[i].MigrasiID = migrasi.ID
+		}
+		if err := saldoAwalRepo.ReplaceAkun(migrasi.ID, lines); err != nil {
+			return fmt.Errorf("failed to save neraca saldo: %v", err)
+		}
+
+		migrasi.TotalDebit = totalDebit
+		migrasi.TotalKredit = totalKredit
+		return saldoAwalRepo.UpdateMigrasi(migrasi)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImportSaldoAwalResult{JumlahBaris: len(lines), TotalDebit: totalDebit, TotalKredit: totalKredit}, nil
+}
+
+// ImportRekening replaces the draft's member balances with a CSV or XLSX
+// file. Columns: niak, kode_produk and saldo, plus optional nomor_rekening.
+// Loans take saldo as the outstanding principal and need sisa_angsuran, the
+// installments left after the cutover; pokok_pinjaman, tanggal_mulai,
+// jangka_waktu, angsuran_pokok and angsuran_bunga are optional and default
+// to a fresh schedule over the remaining installments.
+func (s *SaldoAwalService) ImportRekening(koperasiID uint64, format string, r io.Reader) (*ImportSaldoAwalResult, error) {
+	records, err := readImportRecords(format, r)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("file is empty")
+	}
+
+	kolom := importKolom(records[0])
+	for _, wajib := range []string{"niak", "kode_produk", "saldo"} {
+		if _, ok := kolom[wajib]; !ok {
+			return nil, fmt.Errorf("column %s is missing", wajib)
+		}
+	}
+
+	migrasi, err := s.saldoAwalRepo.GetMigrasiByKoperasi(koperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("saldo awal not found: %v", err)
+	}
+
+	produks, err := s.simpanPinjamRepo.GetProdukByKoperasi(koperasiID, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load produk: %v", err)
+	}
+	produkByKode := make(map[string]postgres.ProdukSimpanPinjam, len(produks))
+	for _, produk := range produks {
+		produkByKode[produk.KodeProduk] = produk
+	}
+
+	var niaks, nomors []string
+	for _, record := range records[1:] {
+		if v := importField(kolom, record, "niak"); v != "" {
+			niaks = append(niaks, v)
+		}
+		if v := importField(kolom, record, "nomor_rekening"); v != "" {
+			nomors = append(nomors, v)
+		}
+	}
+
+	anggotaByNIAK := make(map[string]uint64)
+	if len(niaks) > 0 {
+		anggotas, err := s.anggotaRepo.GetByNIAKs(koperasiID, niaks)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load anggota: %v", err)
+		}
+		for _, anggota := range anggotas {
+			anggotaByNIAK[anggota.NIAK] = anggota.ID
+		}
+	}
+
+	terpakai := make(map[string]bool)
+	if len(nomors) > 0 {
+		existing, err := s.simpanPinjamRepo.GetNomorRekeningTerpakai(nomors)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check nomor rekening: %v", err)
+		}
+		for _, nomor := range existing {
+			terpakai[nomor] = true
+		}
+	}
+
+	var rows []postgres.MigrasiSaldoAwalRekening
+	var errs []string
+	nomorBaris := make(map[string]int)
+	var total money.Amount
+	for n, record := range records[1:] {
+		baris := n + 2
+		if strings.Join(record, "") == "" {
+			continue
+		}
+		field := func(name string) string {
+			return importField(kolom, record, name)
+		}
+		rowErr := func(msg string, args ...interface{}) {
+			errs = append(errs, fmt.Sprintf("row %d: ", baris)+fmt.Sprintf(msg, args...))
+		}
+
+		anggotaID, ok := anggotaByNIAK[field("niak")]
+		if !ok {
+			rowErr("anggota %q not found", field("niak"))
+			continue
+		}
+		produk, ok := produkByKode[field("kode_produk")]
+		if !ok {
+			rowErr("produk %q not found", field("kode_produk"))
+			continue
+		}
+
+		saldo, err := money.Parse(field("saldo"))
+		if err != nil || saldo < 0 {
+			rowErr("invalid saldo %q", field("saldo"))
+			continue
+		}
+
+		row := postgres.MigrasiSaldoAwalRekening{
+			Baris:         baris,
+			AnggotaID:     anggotaID,
+			ProdukID:      produk.ID,
+			NomorRekening: field("nomor_rekening"),
+			Saldo:         saldo,
+		}
+
+		if row.NomorRekening != "" {
+			if prev, ok := nomorBaris[row.NomorRekening]; ok {
+				rowErr("nomor_rekening %s already on row %d", row.NomorRekening, prev)
+				continue
+			}
+			if terpakai[row.NomorRekening] {
+				rowErr("nomor_rekening %s is already in use", row.NomorRekening)
+				continue
+			}
+			nomorBaris[row.NomorRekening] = baris
+		}
+
+		if produk.Jenis == "pinjaman" {
+			if err := s.jadwalPinjaman(&row, produk, migrasi.TanggalCutover, field); err != nil {
+				rowErr("%v", err)
+				continue
+			}
+		}
+
+		rows = append(rows, row)
+		total += saldo
+	}
+
+	if len(errs) > 0 {
+		return nil, saldoAwalErrors(errs)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("file has no rekening")
+	}
+
+	err = s.saldoAwalRepo.Transaction(func(tx *gorm.DB) error {
+		saldoAwalRepo := s.saldoAwalRepo.WithTx(tx)
+
+		migrasi, err := s.getDraftForUpdate(saldoAwalRepo, koperasiID)
+		if err != nil {
+			return err
+		}
+		for i := range rows {
+			rows[i].MigrasiID = migrasi.ID
+		}
+		if err := saldoAwalRepo.ReplaceRekening(migrasi.ID, rows); err != nil {
+			return fmt.Errorf("failed to save rekening: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImportSaldoAwalResult{JumlahBaris: len(rows), TotalSaldo: total}, nil
+}
+
+// jadwalPinjaman fills the remaining schedule of a loan row. The term runs
+// sisa_angsuran months past the cutover; the installment defaults to an
+// annuity of the outstanding principal over those months.
+func (s *SaldoAwalService) jadwalPinjaman(row *postgres.MigrasiSaldoAwalRekening, produk postgres.ProdukSimpanPinjam, cutover time.Time, field func(string) string) error {
+	var err error
+	if row.SisaAngsuran, err = strconv.Atoi(field("sisa_angsuran")); err != nil || row.SisaAngsuran < 0 {
+		return fmt.Errorf("invalid sisa_angsuran %q", field("sisa_angsuran"))
+	}
+	if row.Saldo > 0 && row.SisaAngsuran == 0 {
+		return fmt.Errorf("sisa_angsuran is required for an outstanding loan")
+	}
+
+	row.PokokPinjaman = row.Saldo
+	if v := field("pokok_pinjaman"); v != "" {
+		if row.PokokPinjaman, err = money.Parse(v); err != nil || row.PokokPinjaman < row.Saldo {
+			return fmt.Errorf("invalid pokok_pinjaman %q", v)
+		}
+	}
+
+	row.JangkaWaktu = row.SisaAngsuran
+	if v := field("jangka_waktu"); v != "" {
+		if row.JangkaWaktu, err = strconv.Atoi(v); err != nil || row.JangkaWaktu < row.SisaAngsuran {
+			return fmt.Errorf("invalid jangka_waktu %q", v)
+		}
+	}
+
+	jatuhTempo := cutover.AddDate(0, row.SisaAngsuran, 0)
+	row.TanggalJatuhTempo = &jatuhTempo
+
+	mulai := jatuhTempo.AddDate(0, -row.JangkaWaktu, 0)
+	if v := field("tanggal_mulai"); v != "" {
+		if mulai, err = time.Parse("2006-01-02", v); err != nil || mulai.After(cutover) {
+			return fmt.Errorf("invalid tanggal_mulai %q", v)
+		}
+	}
+	row.TanggalMulai = &mulai
+
+	if row.SisaAngsuran > 0 {
+		row.AngsuranPokok, row.AngsuranBunga = s.simpanPinjamService.calculateAngsuran(
+			row.Saldo, produk.BungaPinjaman, row.SisaAngsuran)
+	}
+	if v := field("angsuran_pokok"); v != "" {
+		if row.AngsuranPokok, err = money.Parse(v); err != nil || row.AngsuranPokok < 0 {
+			return fmt.Errorf("invalid angsuran_pokok %q", v)
+		}
+	}
+	if v := field("angsuran_bunga"); v != "" {
+		if row.AngsuranBunga, err = money.Parse(v); err != nil || row.AngsuranBunga < 0 {
+			return fmt.Errorf("invalid angsuran_bunga %q", v)
+		}
+	}
+	return nil
+}
+
+// ImportFaktur replaces the draft's open invoices with a CSV or XLSX file.
+// Columns: jenis (hutang or piutang), nomor_faktur, tanggal_faktur and sisa,
+// plus kode_supplier for hutang or niak for piutang, and optionally
+// tanggal_jatuh_tempo. Sisa is what is still owed on the invoice.
+func (s *SaldoAwalService) ImportFaktur(koperasiID uint64, format string, r io.Reader) (*ImportSaldoAwalResult, error) {
+	records, err := readImportRecords(format, r)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("file is empty")
+	}
+
+	kolom := importKolom(records[0])
+	for _, wajib := range []string{"jenis", "nomor_faktur", "tanggal_faktur", "sisa"} {
+		if _, ok := kolom[wajib]; !ok {
+			return nil, fmt.Errorf("column %s is missing", wajib)
+		}
+	}
+
+	migrasi, err := s.saldoAwalRepo.GetMigrasiByKoperasi(koperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("saldo awal not found: %v", err)
+	}
+
+	var kodes, niaks, nomorHutang, nomorPiutang []string
+	for _, record := range records[1:] {
+		nomor := importField(kolom, record, "nomor_faktur")
+		switch importField(kolom, record, "jenis") {
+		case "hutang":
+			if v := importField(kolom, record, "kode_supplier"); v != "" {
+				kodes = append(kodes, v)
+			}
+			if nomor != "" {
+				nomorHutang = append(nomorHutang, nomor)
+			}
+		case "piutang":
+			if v := importField(kolom, record, "niak"); v != "" {
+				niaks = append(niaks, v)
+			}
+			if nomor != "" {
+				nomorPiutang = append(nomorPiutang, nomor)
+			}
+		}
+	}
+
+	supplierByKode := make(map[string]uint64)
+	if len(kodes) > 0 {
+		suppliers, err := s.produkRepo.GetSuppliersByKode(koperasiID, kodes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load supplier: %v", err)
+		}
+		for _, supplier := range suppliers {
+			supplierByKode[supplier.Kode] = supplier.ID
+		}
+	}
+
+	anggotaByNIAK := make(map[string]uint64)
+	if len(niaks) > 0 {
+		anggotas, err := s.anggotaRepo.GetByNIAKs(koperasiID, niaks)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load anggota: %v", err)
+		}
+		for _, anggota := range anggotas {
+			anggotaByNIAK[anggota.NIAK] = anggota.ID
+		}
+	}
+
+	terpakai := map[string]map[string]bool{"hutang": {}, "piutang": {}}
+	if len(nomorHutang) > 0 {
+		existing, err := s.produkRepo.GetNomorFakturTerpakai(nomorHutang)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check nomor faktur: %v", err)
+		}
+		for _, nomor := range existing {
+			terpakai["hutang"][nomor] = true
+		}
+	}
+	if len(nomorPiutang) > 0 {
+		existing, err := s.produkRepo.GetNomorTransaksiTerpakai(nomorPiutang)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check nomor faktur: %v", err)
+		}
+		for _, nomor := range existing {
+			terpakai["piutang"][nomor] = true
+		}
+	}
+
+	var rows []postgres.MigrasiSaldoAwalFaktur
+	var errs []string
+	nomorBaris := map[string]map[string]int{"hutang": {}, "piutang": {}}
+	var total money.Amount
+	for n, record := range records[1:] {
+		baris := n + 2
+		if strings.Join(record, "") == "" {
+			continue
+		}
+		field := func(name string) string {
+			return importField(kolom, record, name)
+		}
+		rowErr := func(msg string, args ...interface{}) {
+			errs = append(errs, fmt.Sprintf("row %d: ", baris)+fmt.Sprintf(msg, args...))
+		}
+
+		row := postgres.MigrasiSaldoAwalFaktur{
+			Baris:       baris,
+			Jenis:       field("jenis"),
+			NomorFaktur: field("nomor_faktur"),
+		}
+		switch row.Jenis {
+		case "hutang":
+			supplierID, ok := supplierByKode[field("kode_supplier")]
+			if !ok {
+				rowErr("supplier %q not found", field("kode_supplier"))
+				continue
+			}
+			row.SupplierID = supplierID
+		case "piutang":
+			anggotaID, ok := anggotaByNIAK[field("niak")]
+			if !ok {
+				rowErr("anggota %q not found", field("niak"))
+				continue
+			}
+			row.AnggotaID = anggotaID
+		default:
+			rowErr("jenis must be hutang or piutang")
+			continue
+		}
+
+		if row.NomorFaktur == "" {
+			rowErr("nomor_faktur is required")
+			continue
+		}
+		if prev, ok := nomorBaris[row.Jenis][row.NomorFaktur]; ok {
+			rowErr("nomor_faktur %s already on row %d", row.NomorFaktur, prev)
+			continue
+		}
+		if terpakai[row.Jenis][row.NomorFaktur] {
+			rowErr("nomor_faktur %s is already in use", row.NomorFaktur)
+			continue
+		}
+		nomorBaris[row.Jenis][row.NomorFaktur] = baris
+
+		if row.TanggalFaktur, err = time.Parse("2006-01-02", field("tanggal_faktur")); err != nil {
+			rowErr("invalid tanggal_faktur %q", field("tanggal_faktur"))
+			continue
+		}
+		if row.TanggalFaktur.After(migrasi.TanggalCutover) {
+			rowErr("tanggal_faktur %s is after the cutover", field("tanggal_faktur"))
+			continue
+		}
+		if v := field("tanggal_jatuh_tempo"); v != "" {
+			jatuhTempo, err := time.Parse("2006-01-02", v)
+			if err != nil || jatuhTempo.Before(row.TanggalFaktur) {
+				rowErr("invalid tanggal_jatuh_tempo %q", v)
+				continue
+			}
+			row.TanggalJatuhTempo = &jatuhTempo
+		}
+
+		if row.Sisa, err = money.Parse(field("sisa")); err != nil || row.Sisa <= 0 {
+			rowErr("invalid sisa %q", field("sisa"))
+			continue
+		}
+
+		rows = append(rows, row)
+		total += row.Sisa
+	}
+
+	if len(errs) > 0 {
+		return nil, saldoAwalErrors(errs)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("file has no faktur")
+	}
+
+	err = s.saldoAwalRepo.Transaction(func(tx *gorm.DB) error {
+		saldoAwalRepo := s.saldoAwalRepo.WithTx(tx)
+
+		migrasi, err := s.getDraftForUpdate(saldoAwalRepo, koperasiID)
+		if err != nil {
+			return err
+		}
+		for i := range rows {
+			rows[i].MigrasiID = migrasi.ID
+		}
+		if err := saldoAwalRepo.ReplaceFaktur(migrasi.ID, rows); err != nil {
+			return fmt.Errorf("failed to save faktur: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImportSaldoAwalResult{JumlahBaris: len(rows), TotalSaldo: total}, nil
+}
+
+// Rekonsiliasi checks the draft without posting it: the trial balance must
+// balance, and per control account the sub-ledger must add up to the trial
+// balance. The control account of a produk is the account its
+// simpanan_setoran rule credits or its pinjaman_pencairan rule debits; of an
+// asset, the aset and akumulasi accounts of its kategori; of hutang and
+// piutang, the accounts the pembelian and penjualan rules post them to.
+func (s *SaldoAwalService) Rekonsiliasi(koperasiID uint64) (*RekonsiliasiSaldoAwal, error) {
+	migrasi, err := s.saldoAwalRepo.GetMigrasiByKoperasi(koperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("saldo awal not found: %v", err)
+	}
+	return s.rekonsiliasi(nil, migrasi)
+}
+
+func (s *SaldoAwalService) rekonsiliasi(tx *gorm.DB, migrasi *postgres.MigrasiSaldoAwal) (*RekonsiliasiSaldoAwal, error) {
+	financialRepo := s.financialRepo
+	postingRepo := s.postingRepo
+	simpanPinjamRepo := s.simpanPinjamRepo
+	asetRepo := s.asetRepo
+	if tx != nil {
+		financialRepo = financialRepo.WithTx(tx)
+		postingRepo = postingRepo.WithTx(tx)
+		simpanPinjamRepo = simpanPinjamRepo.WithTx(tx)
+		asetRepo = asetRepo.WithTx(tx)
+	}
+
+	hasil := &RekonsiliasiSaldoAwal{
+		TanggalCutover: migrasi.TanggalCutover,
+		Status:         migrasi.Status,
+		Kontrol:        []KontrolSaldoAwal{},
+		Errors:         []string{},
+	}
+
+	if migrasi.Status != "draft" {
+		hasil.Errors = append(hasil.Errors, "saldo awal has already been posted")
+	}
+
+	saldoNeraca := make(map[uint64]money.Amount)
+	for _, line := range migrasi.Akun {
+		hasil.TotalDebit += line.Debit
+		hasil.TotalKredit += line.Kredit
+		saldoNeraca[line.AkunID] += line.Debit - line.Kredit
+	}
+	hasil.Seimbang = len(migrasi.Akun) > 0 && hasil.TotalDebit == hasil.TotalKredit
+
+	if len(migrasi.Akun) == 0 {
+		hasil.Errors = append(hasil.Errors, "neraca saldo has not been uploaded")
+	} else if !hasil.Seimbang {
+		hasil.Errors = append(hasil.Errors, fmt.Sprintf("neraca saldo does not balance: debit %s, kredit %s",
+			hasil.TotalDebit, hasil.TotalKredit))
+	}
+
+	count, err := financialRepo.CountJurnalSampai(migrasi.KoperasiID, migrasi.TanggalCutover)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check jurnal: %v", err)
+	}
+	if count > 0 && migrasi.Status == "draft" {
+		hasil.Errors = append(hasil.Errors, fmt.Sprintf("%d jurnal already dated on or before the cutover", count))
+	}
+
+	akuns, err := financialRepo.GetCOAAkunAll(migrasi.KoperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load akun: %v", err)
+	}
+	akunByID := make(map[uint64]postgres.COAAkun, len(akuns))
+	for _, akun := range akuns {
+		akunByID[akun.ID] = akun
+	}
+
+	kontrol := make(map[uint64]*KontrolSaldoAwal)
+	tambahKontrol := func(akunID uint64, sumber string, saldo money.Amount, jumlah int) {
+		k, ok := kontrol[akunID]
+		if !ok {
+			akun := akunByID[akunID]
+			k = &KontrolSaldoAwal{AkunID: akunID, KodeAkun: akun.KodeAkun, NamaAkun: akun.NamaAkun}
+			k.SaldoNeraca = saldoNeraca[akunID]
+			if akun.SaldoNormal == "kredit" {
+				k.SaldoNeraca = -k.SaldoNeraca
+			}
+			kontrol[akunID] = k
+		}
+		if sumber != "" {
+			k.Sumber = append(k.Sumber, sumber)
+		}
+		k.SaldoRincian += saldo
+		k.JumlahRincian += jumlah
+	}
+
+	produks, err := simpanPinjamRepo.GetProdukByKoperasi(migrasi.KoperasiID, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load produk: %v", err)
+	}
+	rincian := make(map[uint64]money.Amount)
+	jumlah := make(map[uint64]int)
+	for _, row := range migrasi.Rekening {
+		rincian[row.ProdukID] += row.Saldo
+		jumlah[row.ProdukID]++
+	}
+
+	for _, produk := range produks {
+		akunID, err := akunKontrolProduk(postingRepo, migrasi.KoperasiID, produk)
+		if err != nil {
+			return nil, err
+		}
+		if akunID == 0 {
+			if jumlah[produk.ID] > 0 {
+				hasil.Errors = append(hasil.Errors, fmt.Sprintf("produk %s has no posting rule for its control akun", produk.KodeProduk))
+			}
+			continue
+		}
+		tambahKontrol(akunID, produk.KodeProduk, rincian[produk.ID], jumlah[produk.ID])
+	}
+
+	// Fixed assets are registered in the aset module before posting, with
+	// the depreciation charged so far as akumulasi_awal. Every kategori
+	// account takes part, so a balance without assets behind it shows up.
+	kategoris, err := asetRepo.GetKategoriByKoperasi(migrasi.KoperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kategori aset: %v", err)
+	}
+	for _, kategori := range kategoris {
+		tambahKontrol(kategori.AkunAsetID, kategori.Kode, 0, 0)
+		tambahKontrol(kategori.AkunAkumulasiID, kategori.Kode, 0, 0)
+	}
+
+	asets, err := asetRepo.GetAsetByKoperasi(migrasi.KoperasiID, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aset: %v", err)
+	}
+	penyusutan, err := asetRepo.GetAkumulasiSampai(migrasi.KoperasiID, migrasi.TanggalCutover)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load penyusutan: %v", err)
+	}
+	for _, aset := range asets {
+		if aset.TanggalPerolehan.After(migrasi.TanggalCutover) {
+			continue
+		}
+		if aset.TanggalPelepasan != nil && !aset.TanggalPelepasan.After(migrasi.TanggalCutover) {
+			continue
+		}
+		tambahKontrol(aset.Kategori.AkunAsetID, "", aset.HargaPerolehan, 1)
+		tambahKontrol(aset.Kategori.AkunAkumulasiID, "", aset.AkumulasiAwal+penyusutan[aset.ID], 1)
+	}
+
+	saldoFaktur := make(map[string]money.Amount)
+	jumlahFaktur := make(map[string]int)
+	for _, row := range migrasi.Faktur {
+		if row.TanggalFaktur.After(migrasi.TanggalCutover) {
+			hasil.Errors = append(hasil.Errors, fmt.Sprintf("faktur %s is dated after the cutover", row.NomorFaktur))
+		}
+		saldoFaktur[row.Jenis] += row.Sisa
+		jumlahFaktur[row.Jenis]++
+	}
+	for _, jenis := range []string{"hutang", "piutang"} {
+		akunID, err := akunKontrolFaktur(postingRepo, migrasi.KoperasiID, jenis)
+		if err != nil {
+			return nil, err
+		}
+		if akunID == 0 {
+			if jumlahFaktur[jenis] > 0 {
+				hasil.Errors = append(hasil.Errors, fmt.Sprintf("%s has no posting rule for its control akun", jenis))
+			}
+			continue
+		}
+		tambahKontrol(akunID, jenis, saldoFaktur[jenis], jumlahFaktur[jenis])
+	}
+
+	for _, k := range kontrol {
+		k.Selisih = k.SaldoNeraca - k.SaldoRincian
+		if k.Selisih != 0 {
+			hasil.Errors = append(hasil.Errors, fmt.Sprintf("akun %s: neraca saldo %s, rincian %s, selisih %s",
+				k.KodeAkun, k.SaldoNeraca, k.SaldoRincian, k.Selisih))
+		}
+		hasil.Kontrol = append(hasil.Kontrol, *k)
+	}
+	sort.Slice(hasil.Kontrol, func(i, j int) bool {
+		return hasil.Kontrol[i].KodeAkun < hasil.Kontrol[j].KodeAkun
+	})
+
+	hasil.SiapPosting = len(hasil.Errors) == 0
+	return hasil, nil
+}
+
+// akunKontrolProduk returns the control account of produk, or 0 when its
+// posting rule is missing.
+func akunKontrolProduk(postingRepo *postgresRepo.PostingRepository, koperasiID uint64, produk postgres.ProdukSimpanPinjam) (uint64, error) {
+	if produk.Jenis == "pinjaman" {
+		return akunKontrol(postingRepo, koperasiID, PostingEventPinjamanPencairan, produk.ID, "debit", "jumlah")
+	}
+	return akunKontrol(postingRepo, koperasiID, PostingEventSimpananSetoran, produk.ID, "kredit", "jumlah")
+}
+
+// akunKontrolFaktur returns the account a purchase credits as hutang or a
+// credit sale debits as piutang, or 0 when the posting rule is missing.
+func akunKontrolFaktur(postingRepo *postgresRepo.PostingRepository, koperasiID uint64, jenis string) (uint64, error) {
+	if jenis == "hutang" {
+		return akunKontrol(postingRepo, koperasiID, PostingEventPembelian, 0, "kredit", "total")
+	}
+	return akunKontrol(postingRepo, koperasiID, PostingEventPenjualan, 0, "debit", "piutang")
+}
+
+func akunKontrol(postingRepo *postgresRepo.PostingRepository, koperasiID uint64, event string, produkID uint64, posisi, komponen string) (uint64, error) {
+	rule, err := postingRepo.GetActivePostingRule(koperasiID, event, produkID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to load posting rule: %v", err)
+	}
+
+	for _, line := range rule.Lines {
+		if line.Posisi == posisi && line.Komponen == komponen {
+			return line.AkunID, nil
+		}
+	}
+	return 0, nil
+}
+
+// PostMigrasi posts a reconciled draft: one opening journal dated at the
+// cutover, a rekening with an opening transaction per member balance, a
+// pembelian or penjualan per open faktur, and the periods up to the cutover
+// locked.
+func (s *SaldoAwalService) PostMigrasi(koperasiID uint64, postedBy uint64) (*postgres.MigrasiSaldoAwal, error) {
+	var migrasi *postgres.MigrasiSaldoAwal
+	err := s.saldoAwalRepo.Transaction(func(tx *gorm.DB) error {
+		saldoAwalRepo := s.saldoAwalRepo.WithTx(tx)
+
+		if _, err := s.getDraftForUpdate(saldoAwalRepo, koperasiID); err != nil {
+			return err
+		}
+		var err error
+		migrasi, err = saldoAwalRepo.GetMigrasiByKoperasi(koperasiID)
+		if err != nil {
+			return fmt.Errorf("failed to load saldo awal: %v", err)
+		}
+
+		hasil, err := s.rekonsiliasi(tx, migrasi)
+		if err != nil {
+			return err
+		}
+		if !hasil.SiapPosting {
+			return saldoAwalErrors(hasil.Errors)
+		}
+
+		now := time.Now()
+		jurnal := &postgres.JurnalUmum{
+			TenantID:         migrasi.TenantID,
+			KoperasiID:       migrasi.KoperasiID,
+			TanggalTransaksi: migrasi.TanggalCutover,
+			Keterangan:       fmt.Sprintf("Saldo awal per %s", migrasi.TanggalCutover.Format("02-01-2006")),
+			Status:           "posted",
+			SumberTransaksi:  SumberSaldoAwal,
+			SumberID:         migrasi.ID,
+			CreatedBy:        postedBy,
+			PostedAt:         &now,
+			PostedBy:         postedBy,
+		}
+		var details []postgres.JurnalDetail
+		for _, line := range migrasi.Akun {
+			details = append(details, postgres.JurnalDetail{
+				AkunID:     line.AkunID,
+				Keterangan: "Saldo awal",
+				Debit:      line.Debit,
+				Kredit:     line.Kredit,
+			})
+		}
+
+		// The cutover itself is about to be closed to journals, so the
+		// opening journal skips the period check.
+		if err := s.financialService.insertJurnal(tx, jurnal, details); err != nil {
+			return err
+		}
+
+		for i := range migrasi.Rekening {
+			if err := s.bukaRekening(tx, migrasi, &migrasi.Rekening[i], jurnal, postedBy); err != nil {
+				return err
+			}
+		}
+		for i := range migrasi.Faktur {
+			if err := s.bukaFaktur(tx, migrasi, &migrasi.Faktur[i], jurnal, postedBy); err != nil {
+				return err
+			}
+		}
+
+		if err := s.periodeRepo.WithTx(tx).LockPeriodeSampai(koperasiID, migrasi.TanggalCutover, postedBy); err != nil {
+			return fmt.Errorf("failed to lock periode: %v", err)
+		}
+
+		migrasi.Status = "posted"
+		migrasi.JurnalID = jurnal.ID
+		migrasi.PostedAt = &now
+		migrasi.PostedBy = postedBy
+		return saldoAwalRepo.UpdateMigrasi(migrasi)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return migrasi, nil
+}
+
+// bukaRekening opens the member account of row with its balance and
+// schedule, and records the balance as its first transaction.
+func (s *SaldoAwalService) bukaRekening(tx *gorm.DB, migrasi *postgres.MigrasiSaldoAwal, row *postgres.MigrasiSaldoAwalRekening, jurnal *postgres.JurnalUmum, createdBy uint64) error {
+	simpanPinjamRepo := s.simpanPinjamRepo.WithTx(tx)
+
+	nomorRekening := row.NomorRekening
+	if nomorRekening == "" {
+		var err error
+		nomorRekening, err = s.simpanPinjamService.generateNomorRekening(migrasi.KoperasiID, row.Produk.Jenis)
+		if err != nil {
+			return fmt.Errorf("failed to generate nomor rekening: %v", err)
+		}
+	}
+
+	rekening := &postgres.RekeningSimpanPinjam{
+		KoperasiID:    migrasi.KoperasiID,
+		AnggotaID:     row.AnggotaID,
+		ProdukID:      row.ProdukID,
+		NomorRekening: nomorRekening,
+		Status:        "aktif",
+		TanggalBuka:   migrasi.TanggalCutover,
+	}
+	if row.Produk.Jenis == "pinjaman" {
+		rekening.PokokPinjaman = row.PokokPinjaman
+		rekening.SisaPokok = row.Saldo
+		rekening.TanggalMulai = row.TanggalMulai
+		rekening.TanggalJatuhTempo = row.TanggalJatuhTempo
+		rekening.JangkaWaktu = row.JangkaWaktu
+		rekening.AngsuranPokok = row.AngsuranPokok
+		rekening.AngsuranBunga = row.AngsuranBunga
+		if row.Saldo == 0 {
+			rekening.Status = "lunas"
+		}
+	} else {
+		rekening.SaldoSimpanan = row.Saldo
+	}
+
+	if err := simpanPinjamRepo.CreateRekening(rekening); err != nil {
+		return fmt.Errorf("row %d: failed to create rekening: %v", row.Baris, err)
+	}
+
+	nomorTransaksi, err := s.simpanPinjamService.generateNomorTransaksi(migrasi.KoperasiID)
+	if err != nil {
+		return fmt.Errorf("failed to generate nomor transaksi: %v", err)
+	}
+	transaksi := &postgres.TransaksiSimpanPinjam{
+		KoperasiID:       migrasi.KoperasiID,
+		RekeningID:       rekening.ID,
+		NomorTransaksi:   nomorTransaksi,
+		TanggalTransaksi: migrasi.TanggalCutover,
+		JenisTransaksi:   "saldo_awal",
+		Jumlah:           row.Saldo,
+		SaldoSesudah:     row.Saldo,
+		Keterangan:       "Saldo awal",
+		Referensi:        jurnal.NomorJurnal,
+		JurnalID:         jurnal.ID,
+		CreatedBy:        createdBy,
+	}
+	if err := simpanPinjamRepo.CreateTransaksi(transaksi); err != nil {
+		return fmt.Errorf("row %d: failed to create transaksi: %v", row.Baris, err)
+	}
+
+	row.RekeningID = rekening.ID
+	return s.saldoAwalRepo.WithTx(tx).UpdateRekeningID(row.ID, rekening.ID)
+}
+
+// bukaFaktur records what is left of an open faktur as an unpaid pembelian
+// or credit penjualan, so it can be paid through the hutang and piutang
+// modules.
+func (s *SaldoAwalService) bukaFaktur(tx *gorm.DB, migrasi *postgres.MigrasiSaldoAwal, row *postgres.MigrasiSaldoAwalFaktur, jurnal *postgres.JurnalUmum, createdBy uint64) error {
+	produkRepo := s.produkRepo.WithTx(tx)
+
+	if row.Jenis == "hutang" {
+		pembelian := &postgres.PembelianHeader{
+			KoperasiID:        migrasi.KoperasiID,
+			SupplierID:        row.SupplierID,
+			NomorFaktur:       row.NomorFaktur,
+			TanggalFaktur:     row.TanggalFaktur,
+			TanggalJatuhTempo: row.TanggalJatuhTempo,
+			SubTotal:          row.Sisa,
+			GrandTotal:        row.Sisa,
+			StatusPembayaran:  "unpaid",
+			JurnalID:          jurnal.ID,
+			Keterangan:        "Saldo awal",
+			CreatedBy:         createdBy,
+		}
+		if err := produkRepo.CreatePembelianHeader(pembelian); err != nil {
+			return fmt.Errorf("row %d: failed to create pembelian: %v", row.Baris, err)
+		}
+		row.PembelianHeaderID = pembelian.ID
+		return s.saldoAwalRepo.WithTx(tx).UpdateFaktur(row.ID, map[string]interface{}{"pembelian_header_id": pembelian.ID})
+	}
+
+	penjualan := &postgres.PenjualanHeader{
+		KoperasiID:        migrasi.KoperasiID,
+		AnggotaID:         row.AnggotaID,
+		NomorTransaksi:    row.NomorFaktur,
+		TanggalTransaksi:  row.TanggalFaktur,
+		TanggalJatuhTempo: row.TanggalJatuhTempo,
+		SubTotal:          row.Sisa,
+		GrandTotal:        row.Sisa,
+		MetodePembayaran:  "credit",
+		StatusPembayaran:  "unpaid",
+		JurnalID:          jurnal.ID,
+		Keterangan:        "Saldo awal",
+		CreatedBy:         createdBy,
+	}
+	if err := produkRepo.CreatePenjualanHeader(penjualan); err != nil {
+		return fmt.Errorf("row %d: failed to create penjualan: %v", row.Baris, err)
+	}
+	row.PenjualanHeaderID = penjualan.ID
+	return s.saldoAwalRepo.WithTx(tx).UpdateFaktur(row.ID, map[string]interface{}{"penjualan_header_id": penjualan.ID})
+}
+
+func (s *SaldoAwalService) getDraftForUpdate(saldoAwalRepo *postgresRepo.SaldoAwalRepository, koperasiID uint64) (*postgres.MigrasiSaldoAwal, error) {
+	migrasi, err := saldoAwalRepo.GetMigrasiForUpdate(koperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("saldo awal not found: %v", err)
+	}
+	if migrasi.Status != "draft" {
+		return nil, fmt.Errorf("saldo awal has already been posted")
+	}
+	return migrasi, nil
+}
+
+func saldoAwalErrors(errs []string) error {
+	if len(errs) > maxCOAErrors {
+		errs = append(errs[:maxCOAErrors], fmt.Sprintf("and %d more", len(errs)-maxCOAErrors))
+	}
+	return fmt.Errorf("invalid saldo awal: %s", strings.Join(errs, "; "))
+}
+
+// Request/Response structs
+type CreateSaldoAwalRequest struct {
+	KoperasiID     uint64    `json:"koperasi_id" binding:"required"`
+	TanggalCutover time.Time `json:"tanggal_cutover" binding:"required"`
+	Keterangan     string    `json:"keterangan"`
+}
+
+type ImportSaldoAwalResult struct {
+	JumlahBaris int          `json:"jumlah_baris"`
+	TotalDebit  money.Amount `json:"total_debit,omitempty"`
+	TotalKredit money.Amount `json:"total_kredit,omitempty"`
+	TotalSaldo  money.Amount `json:"total_saldo,omitempty"`
+}
+
+type RekonsiliasiSaldoAwal struct {
+	TanggalCutover time.Time          `json:"tanggal_cutover"`
+	Status         string             `json:"status"`
+	TotalDebit     money.Amount       `json:"total_debit"`
+	TotalKredit    money.Amount       `json:"total_kredit"`
+	Seimbang       bool               `json:"seimbang"`
+	Kontrol        []KontrolSaldoAwal `json:"kontrol"`
+	Errors         []string           `json:"errors"`
+	SiapPosting    bool               `json:"siap_posting"`
+}
+
+// KontrolSaldoAwal compares a control account in the trial balance, in its
+// normal direction, with the sub-ledger behind it: the rekening of the
+// produk, the assets of the kategori, or the open hutang or piutang faktur
+// posting to it. Sumber lists those produk codes, kategori codes or jenis.
+type KontrolSaldoAwal struct {
+	AkunID        uint64       `json:"akun_id"`
+	KodeAkun      string       `json:"kode_akun"`
+	NamaAkun      string       `json:"nama_akun"`
+	Sumber        []string     `json:"sumber"`
+	SaldoNeraca   money.Amount `json:"saldo_neraca"`
+	SaldoRincian  money.Amount `json:"saldo_rincian"`
+	JumlahRincian int          `json:"jumlah_rincian"`
+	Selisih       money.Amount `json:"selisih"`
+}
@@ -0,0 +1,487 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+)
+
+// Allocation codes that are shared out to members instead of kept by the
+// koperasi.
+const (
+	SHUAlokasiJasaModal = "jasa_modal"
+	SHUAlokasiJasaUsaha = "jasa_usaha"
+)
+
+type SHUService struct {
+	shuRepo             *postgresRepo.SHURepository
+	financialRepo       *postgresRepo.FinancialRepository
+	simpanPinjamRepo    *postgresRepo.SimpanPinjamRepository
+	financialService    *FinancialService
+	simpanPinjamService *SimpanPinjamService
+}
+
+func NewSHUService(
+	shuRepo *postgresRepo.SHURepository,
+	financialRepo *postgresRepo.FinancialRepository,
+	simpanPinjamRepo *postgresRepo.SimpanPinjamRepository,
+	financialService *FinancialService,
+	simpanPinjamService *SimpanPinjamService,
+) *SHUService {
+	return &SHUService{
+		shuRepo:             shuRepo,
+		financialRepo:       financialRepo,
+		simpanPinjamRepo:    simpanPinjamRepo,
+		financialService:    financialService,
+		simpanPinjamService: simpanPinjamService,
+	}
+}
+
+func (s *SHUService) SaveConfig(req *SaveSHUConfigRequest) (*postgres.SHUConfig, error) {
+	var totalPersen float64
+	kode := make(map[string]bool)
+	var alokasi []postgres.SHUConfigAlokasi
+	for _, line := range req.Alokasi {
+		if kode[line.KodeAlokasi] {
+			return nil, fmt.Errorf("kode alokasi %s is duplicated", line.KodeAlokasi)
+		}
+		kode[line.KodeAlokasi] = true
+
+		if err := s.checkAkun(req.KoperasiID, line.AkunID); err != nil {
+			return nil, err
+		}
+
+		totalPersen += line.Persen
+		alokasi = append(alokasi, postgres.SHUConfigAlokasi{
+			KodeAlokasi: line.KodeAlokasi,
+			Nama:        line.Nama,
+			Persen:      line.Persen,
+			AkunID:      line.AkunID,
+		})
+	}
+
+	if math.Round(totalPersen*100) != 10000 {
+		return nil, fmt.Errorf("total persen alokasi must be 100, got %.2f", totalPersen)
+	}
+
+	for _, akunID := range []uint64{req.AkunSHUID, req.AkunSimpananID} {
+		if err := s.checkAkun(req.KoperasiID, akunID); err != nil {
+			return nil, err
+		}
+	}
+
+	produk, err := s.simpanPinjamRepo.GetProdukByID(req.ProdukSimpananID)
+	if err != nil {
+		return nil, fmt.Errorf("produk simpanan not found: %v", err)
+	}
+	if produk.KoperasiID != req.KoperasiID || produk.Jenis != "simpanan" {
+		return nil, fmt.Errorf("produk %s is not a simpanan product of the koperasi", produk.KodeProduk)
+	}
+
+	config := &postgres.SHUConfig{
+		TenantID:         req.TenantID,
+		KoperasiID:       req.KoperasiID,
+		AkunSHUID:        req.AkunSHUID,
+		ProdukSimpananID: req.ProdukSimpananID,
+		AkunSimpananID:   req.AkunSimpananID,
+		Alokasi:          alokasi,
+	}
+
+	if existing, err := s.shuRepo.GetSHUConfig(req.KoperasiID); err == nil {
+		config.ID = existing.ID
+		config.CreatedAt = existing.CreatedAt
+	}
+
+	err = s.shuRepo.SaveSHUConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save SHU config: %v", err)
+	}
+
+	return config, nil
+}
+
+func (s *SHUService) GetConfig(koperasiID uint64) (*postgres.SHUConfig, error) {
+	return s.shuRepo.GetSHUConfig(koperasiID)
+}
+
+// Hitung calculates the SHU of a fiscal year from the laba rugi net income.
+// A draft calculation for the same year is replaced; approved or paid ones
+// are kept.
+func (s *SHUService) Hitung(req *HitungSHURequest, calculatedBy uint64) (*postgres.SHUPerhitungan, error) {
+	config, err := s.shuRepo.GetSHUConfig(req.KoperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("SHU config not found: %v", err)
+	}
+
+	existing, err := s.shuRepo.GetPerhitunganByTahun(req.KoperasiID, req.Tahun)
+	if err == nil && existing.Status != "draft" {
+		return nil, fmt.Errorf("SHU %d is already %s", req.Tahun, existing.Status)
+	}
+
+	dari := time.Date(req.Tahun, 1, 1, 0, 0, 0, 0, time.Local)
+	sampai := dari.AddDate(1, 0, 0).Add(-time.Nanosecond)
+
+	shuBersih, err := s.labaBersih(req.KoperasiID, dari, sampai)
+	if err != nil {
+		return nil, err
+	}
+
+	if shuBersih <= 0 {
+		return nil, fmt.Errorf("no SHU to distribute for %d (laba rugi %s)", req.Tahun, shuBersih)
+	}
+
+	perhitungan := &postgres.SHUPerhitungan{
+		TenantID:     config.TenantID,
+		KoperasiID:   req.KoperasiID,
+		Tahun:        req.Tahun,
+		SHUBersih:    shuBersih,
+		Status:       "draft",
+		CalculatedBy: calculatedBy,
+	}
+
+	var jasaModal, jasaUsaha money.Amount
+	for _, line := range config.Alokasi {
+		jumlah := shuBersih.Percent(line.Persen, money.RoundSen)
+		perhitungan.Alokasi = append(perhitungan.Alokasi, postgres.SHUAlokasi{
+			KodeAlokasi: line.KodeAlokasi,
+			Nama:        line.Nama,
+			Persen:      line.Persen,
+			Jumlah:      jumlah,
+			AkunID:      line.AkunID,
+		})
+
+		switch line.KodeAlokasi {
+		case SHUAlokasiJasaModal:
+			jasaModal = jumlah
+		case SHUAlokasiJasaUsaha:
+			jasaUsaha = jumlah
+		}
+	}
+
+	anggota, err := s.hitungAnggota(req.KoperasiID, req.Tahun, dari, sampai)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range anggota {
+		perhitungan.TotalRataSimpanan += item.RataRataSimpanan
+		perhitungan.TotalTransaksi += item.TotalPenjualan + item.TotalBungaPinjaman + item.TotalPPOB
+	}
+
+	for i := range anggota {
+		item := &anggota[i]
+		if perhitungan.TotalRataSimpanan > 0 {
+			item.JasaModal = jasaModal.Prorate(item.RataRataSimpanan, perhitungan.TotalRataSimpanan, money.RoundSen)
+		}
+		if perhitungan.TotalTransaksi > 0 {
+			transaksi := item.TotalPenjualan + item.TotalBungaPinjaman + item.TotalPPOB
+			item.JasaUsaha = jasaUsaha.Prorate(transaksi, perhitungan.TotalTransaksi, money.RoundSen)
+		}
+		item.TotalSHU = item.JasaModal + item.JasaUsaha
+	}
+
+	perhitungan.Anggota = anggota
+
+	err = s.shuRepo.Transaction(func(tx *gorm.DB) error {
+		shuRepo := s.shuRepo.WithTx(tx)
+		if existing != nil {
+			if err := shuRepo.DeletePerhitungan(existing.ID); err != nil {
+				return fmt.Errorf("failed to replace draft SHU: %v", err)
+			}
+		}
+		return shuRepo.CreatePerhitungan(perhitungan)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save SHU: %v", err)
+	}
+
+	return perhitungan, nil
+}
+
+// labaBersih is pendapatan less beban booked between dari and sampai, read
+// the same way as the laba rugi report so the year-end close is left out.
+func (s *SHUService) labaBersih(koperasiID uint64, dari, sampai time.Time) (money.Amount, error) {
+	items, err := s.financialRepo.GetMutasiHasilUsaha(koperasiID, dari, sampai)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get laba rugi: %v", err)
+	}
+
+	var laba money.Amount
+	for _, item := range items {
+		saldo := saldoKategori(item.KategoriTipe, item.TotalDebit, item.TotalKredit)
+		if item.KategoriTipe == "beban" {
+			laba -= saldo
+		} else {
+			laba += saldo
+		}
+	}
+	return laba, nil
+}
+
+// hitungAnggota builds the per-member basis: the average of the twelve
+// month-end simpanan balances and the year's transactions with the koperasi.
+func (s *SHUService) hitungAnggota(koperasiID uint64, tahun int, dari, sampai time.Time) ([]postgres.SHUAnggota, error) {
+	history, err := s.shuRepo.GetSaldoSimpananHistory(koperasiID, sampai)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load simpanan history: %v", err)
+	}
+
+	rekeningAnggota := make(map[uint64]uint64)
+	saldoRekening := make(map[uint64]money.Amount)
+	totalSaldoBulanan := make(map[uint64]money.Amount)
+
+	i := 0
+	for bulan := 1; bulan <= 12; bulan++ {
+		akhirBulan := time.Date(tahun, time.Month(bulan)+1, 1, 0, 0, 0, 0, time.Local)
+		for i < len(history) && history[i].TanggalTransaksi.Before(akhirBulan) {
+			rekeningAnggota[history[i].RekeningID] = history[i].AnggotaID
+			saldoRekening[history[i].RekeningID] = history[i].SaldoSesudah
+			i++
+		}
+
+		for rekeningID, saldo := range saldoRekening {
+			totalSaldoBulanan[rekeningAnggota[rekeningID]] += saldo
+		}
+	}
+
+	result := make(map[uint64]*postgres.SHUAnggota)
+	get := func(anggotaID uint64) *postgres.SHUAnggota {
+		if item, ok := result[anggotaID]; ok {
+			return item
+		}
+		item := &postgres.SHUAnggota{AnggotaID: anggotaID}
+		result[anggotaID] = item
+		return item
+	}
+
+	for anggotaID, total := range totalSaldoBulanan {
+		if total > 0 {
+			get(anggotaID).RataRataSimpanan = total.Div(12, money.RoundSen)
+		}
+	}
+
+	transaksi, err := s.shuRepo.GetTransaksiUsahaAnggota(koperasiID, dari, sampai)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load member transactions: %v", err)
+	}
+
+	for _, t := range transaksi {
+		item := get(t.AnggotaID)
+		switch t.Sumber {
+		case "penjualan":
+			item.TotalPenjualan = t.Jumlah
+		case "bunga_pinjaman":
+			item.TotalBungaPinjaman = t.Jumlah
+		case "ppob":
+			item.TotalPPOB = t.Jumlah
+		}
+	}
+
+	var anggota []postgres.SHUAnggota
+	for _, item := range result {
+		anggota = append(anggota, *item)
+	}
+	sort.Slice(anggota, func(a, b int) bool {
+		return anggota[a].AnggotaID < anggota[b].AnggotaID
+	})
+
+	return anggota, nil
+}
+
+func (s *SHUService) GetPerhitunganList(koperasiID uint64) ([]postgres.SHUPerhitungan, error) {
+	return s.shuRepo.GetPerhitunganByKoperasi(koperasiID)
+}
+
+func (s *SHUService) GetPerhitungan(id uint64) (*postgres.SHUPerhitungan, error) {
+	return s.shuRepo.GetPerhitunganByID(id)
+}
+
+func (s *SHUService) Approve(id uint64, approvedBy uint64) error {
+	perhitungan, err := s.shuRepo.GetPerhitunganByID(id)
+	if err != nil {
+		return fmt.Errorf("SHU not found: %v", err)
+	}
+
+	if perhitungan.Status != "draft" {
+		return fmt.Errorf("only draft SHU can be approved")
+	}
+
+	return s.shuRepo.UpdatePerhitungan(id, map[string]interface{}{
+		"status":      "approved",
+		"approved_by": approvedBy,
+		"approved_at": time.Now(),
+	})
+}
+
+// Bayar pays out an approved SHU in one transaction: each member's share is
+// credited to their savings account, and a journal moves the SHU balance into
+// the allocation accounts. Member shares that cannot be credited (no active
+// savings account) stay on the jasa modal / jasa usaha payable accounts.
+func (s *SHUService) Bayar(id uint64, paidBy uint64) (*postgres.SHUPerhitungan, error) {
+	perhitungan, err := s.shuRepo.GetPerhitunganByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("SHU not found: %v", err)
+	}
+
+	if perhitungan.Status != "approved" {
+		return nil, fmt.Errorf("only approved SHU can be paid")
+	}
+
+	config, err := s.shuRepo.GetSHUConfig(perhitungan.KoperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("SHU config not found: %v", err)
+	}
+
+	referensi := fmt.Sprintf("SHU-%d", perhitungan.Tahun)
+
+	err = s.shuRepo.Transaction(func(tx *gorm.DB) error {
+		shuRepo := s.shuRepo.WithTx(tx)
+		simpanPinjamRepo := s.simpanPinjamRepo.WithTx(tx)
+
+		var dibayarModal, dibayarUsaha money.Amount
+		for i := range perhitungan.Anggota {
+			item := &perhitungan.Anggota[i]
+			if item.TotalSHU <= 0 {
+				continue
+			}
+
+			rekening, err := simpanPinjamRepo.GetRekeningByAnggotaProduk(item.AnggotaID, config.ProdukSimpananID)
+			if err != nil {
+				if err == gorm.ErrRecordNotFound {
+					continue
+				}
+				return fmt.Errorf("failed to load rekening anggota %d: %v", item.AnggotaID, err)
+			}
+
+			transaksi, err := s.simpanPinjamService.mutasiSimpanan(tx, rekening.ID, "setoran", item.TotalSHU,
+				fmt.Sprintf("Pembagian SHU tahun %d", perhitungan.Tahun), referensi, paidBy)
+			if err != nil {
+				return fmt.Errorf("failed to credit SHU anggota %d: %v", item.AnggotaID, err)
+			}
+
+			if err := shuRepo.UpdateSHUAnggotaPembayaran(item.ID, rekening.ID, transaksi.ID); err != nil {
+				return fmt.Errorf("failed to update SHU anggota: %v", err)
+			}
+
+			item.RekeningID = rekening.ID
+			item.TransaksiSimpanPinjamID = transaksi.ID
+			dibayarModal += item.JasaModal
+			dibayarUsaha += item.JasaUsaha
+		}
+
+		details := []postgres.JurnalDetail{{
+			AkunID:     config.AkunSHUID,
+			Keterangan: fmt.Sprintf("Pembagian SHU tahun %d", perhitungan.Tahun),
+		}}
+
+		for _, alokasi := range perhitungan.Alokasi {
+			jumlah := alokasi.Jumlah
+			switch alokasi.KodeAlokasi {
+			case SHUAlokasiJasaModal:
+				jumlah = money.Max(0, jumlah-dibayarModal)
+			case SHUAlokasiJasaUsaha:
+				jumlah = money.Max(0, jumlah-dibayarUsaha)
+			}
+
+			if jumlah == 0 {
+				continue
+			}
+			details = append(details, postgres.JurnalDetail{
+				AkunID:     alokasi.AkunID,
+				Keterangan: alokasi.Nama,
+				Kredit:     jumlah,
+			})
+		}
+
+		dibayar := dibayarModal + dibayarUsaha
+		if dibayar > 0 {
+			details = append(details, postgres.JurnalDetail{
+				AkunID:     config.AkunSimpananID,
+				Keterangan: "SHU dikreditkan ke simpanan anggota",
+				Kredit:     dibayar,
+			})
+		}
+
+		// The debit to SHU is whatever the kredit lines add up to, so
+		// rounding leftovers on member shares stay balanced.
+		var totalKredit money.Amount
+		for _, detail := range details {
+			totalKredit += detail.Kredit
+		}
+		details[0].Debit = totalKredit
+
+		now := time.Now()
+		jurnal := &postgres.JurnalUmum{
+			TenantID:         perhitungan.TenantID,
+			KoperasiID:       perhitungan.KoperasiID,
+			TanggalTransaksi: now,
+			Referensi:        referensi,
+			Keterangan:       fmt.Sprintf("Pembagian SHU tahun %d", perhitungan.Tahun),
+			Status:           "posted",
+			SumberTransaksi:  "shu_perhitungan",
+			SumberID:         perhitungan.ID,
+			CreatedBy:        paidBy,
+			PostedAt:         &now,
+			PostedBy:         paidBy,
+		}
+
+		if err := s.financialService.saveJurnal(tx, jurnal, details); err != nil {
+			return err
+		}
+
+		perhitungan.Status = "paid"
+		perhitungan.PaidBy = paidBy
+		perhitungan.PaidAt = &now
+		perhitungan.JurnalID = jurnal.ID
+
+		return shuRepo.UpdatePerhitungan(perhitungan.ID, map[string]interface{}{
+			"status":    "paid",
+			"paid_by":   paidBy,
+			"paid_at":   now,
+			"jurnal_id": jurnal.ID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return perhitungan, nil
+}
+
+func (s *SHUService) checkAkun(koperasiID, akunID uint64) error {
+	akun, err := s.financialRepo.GetCOAAkunByID(akunID)
+	if err != nil {
+		return fmt.Errorf("akun %d not found: %v", akunID, err)
+	}
+	if akun.KoperasiID != koperasiID {
+		return fmt.Errorf("akun %s does not belong to koperasi %d", akun.KodeAkun, koperasiID)
+	}
+	return nil
+}
+
+type SaveSHUConfigRequest struct {
+	TenantID         uint64                    `json:"tenant_id" binding:"required"`
+	KoperasiID       uint64                    `json:"koperasi_id" binding:"required"`
+	AkunSHUID        uint64                    `json:"akun_shu_id" binding:"required"`
+	ProdukSimpananID uint64                    `json:"produk_simpanan_id" binding:"required"`
+	AkunSimpananID   uint64                    `json:"akun_simpanan_id" binding:"required"`
+	Alokasi          []SHUConfigAlokasiRequest `json:"alokasi" binding:"required,min=1,dive"`
+}
+
+type SHUConfigAlokasiRequest struct {
+	KodeAlokasi string  `json:"kode_alokasi" binding:"required"`
+	Nama        string  `json:"nama" binding:"required"`
+	Persen      float64 `json:"persen" binding:"required,gt=0,lte=100"`
+	AkunID      uint64  `json:"akun_id" binding:"required"`
+}
+
+type HitungSHURequest struct {
+	KoperasiID uint64 `json:"koperasi_id" binding:"required"`
+	Tahun      int    `json:"tahun" binding:"required,min=2000"`
+}
@@ -5,21 +5,30 @@ import (
 	"math"
 	"time"
 
+	"gorm.io/gorm"
 	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
 	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+	"koperasi-merah-putih/internal/utils"
 )
 
 type SimpanPinjamService struct {
 	simpanPinjamRepo *postgresRepo.SimpanPinjamRepository
+	postingService   *PostingService
+	pajakService     *PajakService
 	sequenceService  *SequenceService
 }
 
 func NewSimpanPinjamService(
 	simpanPinjamRepo *postgresRepo.SimpanPinjamRepository,
+	postingService *PostingService,
+	pajakService *PajakService,
 	sequenceService *SequenceService,
 ) *SimpanPinjamService {
 	return &SimpanPinjamService{
 		simpanPinjamRepo: simpanPinjamRepo,
+		postingService:   postingService,
+		pajakService:     pajakService,
 		sequenceService:  sequenceService,
 	}
 }
@@ -120,11 +129,42 @@ func (s *SimpanPinjamService) CreateTransaksi(req *CreateTransaksiRequest) (*pos
 		saldoSebelum = rekening.SisaPokok
 	}
 
+	// Interest on simpanan is credited net of the PPh 4(2) withheld on it.
+	var potongan *postgres.PotonganPajak
+	netto := req.Jumlah
+	if req.JenisTransaksi == "bunga" && rekening.Produk.Jenis == "simpanan" {
+		tarif, pph, kodeObjek, err := s.pajakService.pphBunga(req.KoperasiID, req.Jumlah)
+		if err != nil {
+			return nil, err
+		}
+		if pph > 0 {
+			potongan = &postgres.PotonganPajak{
+				KoperasiID:      req.KoperasiID,
+				JenisPajak:      JenisPPh4Ayat2,
+				KodeObjekPajak:  kodeObjek,
+				SumberTransaksi: "transaksi_simpan_pinjam",
+				NamaPenerima:    rekening.Anggota.Nama,
+				NIK:             rekening.Anggota.NIK,
+				Alamat:          rekening.Anggota.Alamat,
+				Bruto:           req.Jumlah,
+				Tarif:           tarif,
+				JumlahPPh:       pph,
+				CreatedBy:       req.CreatedBy,
+			}
+			netto -= pph
+		}
+	}
+
 	saldoSesudah := saldoSebelum
 	switch req.JenisTransaksi {
 	case "setoran":
 		saldoSesudah = saldoSebelum + req.Jumlah
 		rekening.SaldoSimpanan = saldoSesudah
+	case "bunga":
+		if rekening.Produk.Jenis == "simpanan" {
+			saldoSesudah = saldoSebelum + netto
+			rekening.SaldoSimpanan = saldoSesudah
+		}
 	case "penarikan":
 		if saldoSebelum < req.Jumlah {
 			return nil, fmt.Errorf("insufficient balance")
@@ -160,19 +200,148 @@ func (s *SimpanPinjamService) CreateTransaksi(req *CreateTransaksiRequest) (*pos
 		CreatedBy:        req.CreatedBy,
 	}
 
-	err = s.simpanPinjamRepo.CreateTransaksi(transaksi)
+	err = s.simpanPinjamRepo.Transaction(func(tx *gorm.DB) error {
+		simpanPinjamRepo := s.simpanPinjamRepo.WithTx(tx)
+
+		if err := simpanPinjamRepo.CreateTransaksi(transaksi); err != nil {
+			return fmt.Errorf("failed to create transaksi: %v", err)
+		}
+
+		if err := simpanPinjamRepo.UpdateRekening(rekening); err != nil {
+			return fmt.Errorf("failed to update rekening: %v", err)
+		}
+
+		komponen := map[string]money.Amount{"jumlah": transaksi.Jumlah}
+		if transaksi.JenisTransaksi == "bunga" && rekening.Produk.Jenis == "simpanan" {
+			komponen["netto"] = netto
+		}
+		if potongan != nil {
+			potongan.TanggalPotong = transaksi.TanggalTransaksi
+			potongan.SumberID = transaksi.ID
+			if err := s.pajakService.catatPotongan(tx, potongan); err != nil {
+				return err
+			}
+			komponen["pph"] = potongan.JumlahPPh
+		}
+
+		jurnal, err := s.postingService.Post(tx, &PostingRequest{
+			KoperasiID:           transaksi.KoperasiID,
+			KodeEvent:            rekening.Produk.Jenis + "_" + transaksi.JenisTransaksi,
+			ProdukSimpanPinjamID: rekening.ProdukID,
+			TanggalTransaksi:     transaksi.TanggalTransaksi,
+			Referensi:            transaksi.NomorTransaksi,
+			Keterangan:           fmt.Sprintf("%s %s %s", transaksi.JenisTransaksi, rekening.Produk.NamaProduk, rekening.NomorRekening),
+			SumberTransaksi:      "transaksi_simpan_pinjam",
+			SumberID:             transaksi.ID,
+			AnggotaID:            rekening.AnggotaID,
+			Komponen:             komponen,
+			CreatedBy:            transaksi.CreatedBy,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to post jurnal: %v", err)
+		}
+
+		if jurnal != nil {
+			transaksi.JurnalID = jurnal.ID
+			if potongan != nil {
+				if err := s.pajakService.updatePotonganJurnal(tx, potongan.ID, jurnal.ID); err != nil {
+					return fmt.Errorf("failed to link potongan pajak: %v", err)
+				}
+			}
+			return simpanPinjamRepo.UpdateTransaksiJurnal(transaksi.ID, jurnal.ID)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create transaksi: %v", err)
+		return nil, err
 	}
 
-	err = s.simpanPinjamRepo.UpdateRekening(rekening)
+	return transaksi, nil
+}
+
+// mutasiSimpanan credits (setoran) or debits (penarikan) a simpanan rekening
+// inside tx on behalf of another module, e.g. an SHU payout or a purchase paid
+// from savings. The rekening row is locked for the duration of tx. No journal
+// is posted; the caller's own journal covers the savings control account.
+func (s *SimpanPinjamService) mutasiSimpanan(tx *gorm.DB, rekeningID uint64, jenisTransaksi string, jumlah money.Amount, keterangan, referensi string, createdBy uint64) (*postgres.TransaksiSimpanPinjam, error) {
+	simpanPinjamRepo := s.simpanPinjamRepo.WithTx(tx)
+
+	rekening, err := simpanPinjamRepo.GetRekeningForUpdate(rekeningID)
 	if err != nil {
+		return nil, fmt.Errorf("rekening not found: %v", err)
+	}
+
+	if rekening.Status != "aktif" {
+		return nil, fmt.Errorf("rekening not active")
+	}
+	if rekening.Produk.Jenis != "simpanan" {
+		return nil, fmt.Errorf("rekening %s is not a simpanan account", rekening.NomorRekening)
+	}
+
+	saldoSebelum := rekening.SaldoSimpanan
+	saldoSesudah := saldoSebelum
+	switch jenisTransaksi {
+	case "setoran":
+		saldoSesudah = saldoSebelum + jumlah
+	case "penarikan":
+		if saldoSebelum < jumlah {
+			return nil, fmt.Errorf("insufficient balance")
+		}
+		saldoSesudah = saldoSebelum - jumlah
+	default:
+		return nil, fmt.Errorf("unsupported jenis transaksi %s", jenisTransaksi)
+	}
+
+	nomorTransaksi, err := s.generateNomorTransaksi(rekening.KoperasiID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate nomor transaksi: %v", err)
+	}
+
+	transaksi := &postgres.TransaksiSimpanPinjam{
+		KoperasiID:       rekening.KoperasiID,
+		RekeningID:       rekening.ID,
+		NomorTransaksi:   nomorTransaksi,
+		TanggalTransaksi: time.Now(),
+		JenisTransaksi:   jenisTransaksi,
+		Jumlah:           jumlah,
+		SaldoSebelum:     saldoSebelum,
+		SaldoSesudah:     saldoSesudah,
+		Keterangan:       utils.TruncateString(keterangan, 255),
+		Referensi:        referensi,
+		CreatedBy:        createdBy,
+	}
+
+	if err := simpanPinjamRepo.CreateTransaksi(transaksi); err != nil {
+		return nil, fmt.Errorf("failed to create transaksi: %v", err)
+	}
+
+	if err := simpanPinjamRepo.UpdateSaldoSimpanan(rekening.ID, saldoSesudah); err != nil {
 		return nil, fmt.Errorf("failed to update rekening: %v", err)
 	}
 
 	return transaksi, nil
 }
 
+// cekRekeningAnggota checks that rekeningID is an active account of the
+// member at koperasiID, before another module debits it through
+// mutasiSimpanan.
+func (s *SimpanPinjamService) cekRekeningAnggota(rekeningID, anggotaID, koperasiID uint64) error {
+	rekenings, err := s.simpanPinjamRepo.GetRekeningByAnggota(anggotaID)
+	if err != nil {
+		return fmt.Errorf("failed to load rekening anggota: %v", err)
+	}
+	for _, rekening := range rekenings {
+		if rekening.ID != rekeningID {
+			continue
+		}
+		if rekening.KoperasiID != koperasiID {
+			return fmt.Errorf("rekening %d belongs to another koperasi", rekeningID)
+		}
+		return nil
+	}
+	return fmt.Errorf("rekening %d is not an active account of anggota %d", rekeningID, anggotaID)
+}
+
 func (s *SimpanPinjamService) GetTransaksiByRekening(rekeningID uint64, page, limit int) ([]postgres.TransaksiSimpanPinjam, error) {
 	offset := (page - 1) * limit
 	return s.simpanPinjamRepo.GetTransaksiByRekening(rekeningID, limit, offset)
@@ -214,17 +383,20 @@ func (s *SimpanPinjamService) generateNomorTransaksi(koperasiID uint64) (string,
 	return fmt.Sprintf("TRX%04d%010d", koperasiID, number), nil
 }
 
-func (s *SimpanPinjamService) calculateAngsuran(pokok, bunga float64, jangkaWaktu int) (float64, float64) {
+// calculateAngsuran returns the monthly annuity installment and the first
+// month's interest, both rounded with the interest rule.
+func (s *SimpanPinjamService) calculateAngsuran(pokok money.Amount, bunga float64, jangkaWaktu int) (money.Amount, money.Amount) {
 	bungaBulanan := bunga / 12 / 100
 
 	if bungaBulanan == 0 {
-		return pokok / float64(jangkaWaktu), 0
+		return pokok.Div(int64(jangkaWaktu), money.RoundBunga), 0
 	}
 
-	angsuranPokok := pokok * (bungaBulanan * math.Pow(1+bungaBulanan, float64(jangkaWaktu))) /
+	faktor := (bungaBulanan * math.Pow(1+bungaBulanan, float64(jangkaWaktu))) /
 		(math.Pow(1+bungaBulanan, float64(jangkaWaktu)) - 1)
+	angsuranPokok := pokok.Mul(faktor, money.RoundBunga)
 
-	angsuranBunga := pokok * bungaBulanan
+	angsuranBunga := pokok.Mul(bungaBulanan, money.RoundBunga)
 
 	return angsuranPokok, angsuranBunga
 }
@@ -236,10 +408,10 @@ type CreateProdukSimpanPinjamRequest struct {
 	Jenis            string  `json:"jenis" binding:"required,oneof=simpanan pinjaman"`
 	Kategori         string  `json:"kategori"`
 	BungaSimpanan    float64 `json:"bunga_simpanan"`
-	MinimalSaldo     float64 `json:"minimal_saldo"`
+	MinimalSaldo     money.Amount `json:"minimal_saldo"`
 	BungaPinjaman    float64 `json:"bunga_pinjaman"`
 	BungaDenda       float64 `json:"bunga_denda"`
-	MaksimalPinjaman float64 `json:"maksimal_pinjaman"`
+	MaksimalPinjaman money.Amount `json:"maksimal_pinjaman"`
 	JangkaWaktuMax   int     `json:"jangka_waktu_max"`
 	SyaratKetentuan  string  `json:"syarat_ketentuan"`
 }
@@ -248,7 +420,7 @@ type CreateRekeningRequest struct {
 	KoperasiID     uint64    `json:"koperasi_id" binding:"required"`
 	AnggotaID      uint64    `json:"anggota_id" binding:"required"`
 	ProdukID       uint64    `json:"produk_id" binding:"required"`
-	PokokPinjaman  float64   `json:"pokok_pinjaman"`
+	PokokPinjaman money.Amount `json:"pokok_pinjaman"`
 	JangkaWaktu    int       `json:"jangka_waktu"`
 	TanggalMulai   time.Time `json:"tanggal_mulai"`
 }
@@ -257,7 +429,7 @@ type CreateTransaksiRequest struct {
 	KoperasiID      uint64  `json:"koperasi_id" binding:"required"`
 	RekeningID      uint64  `json:"rekening_id" binding:"required"`
 	JenisTransaksi  string  `json:"jenis_transaksi" binding:"required,oneof=setoran penarikan pencairan angsuran bunga denda"`
-	Jumlah          float64 `json:"jumlah" binding:"required,gt=0"`
+	Jumlah         money.Amount `json:"jumlah" binding:"required,gt=0"`
 	Keterangan      string  `json:"keterangan"`
 	Referensi       string  `json:"referensi"`
 	CreatedBy       uint64  `json:"created_by"`
@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
 	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
 )
 
@@ -17,6 +19,7 @@ type UserService struct {
 	registrationRepo *postgresRepo.UserRegistrationRepository
 	anggotaRepo      *postgresRepo.AnggotaKoperasiRepository
 	paymentService   *PaymentService
+	postingService   *PostingService
 	sequenceService  *SequenceService
 }
 
@@ -25,6 +28,7 @@ func NewUserService(
 	registrationRepo *postgresRepo.UserRegistrationRepository,
 	anggotaRepo *postgresRepo.AnggotaKoperasiRepository,
 	paymentService *PaymentService,
+	postingService *PostingService,
 	sequenceService *SequenceService,
 ) *UserService {
 	return &UserService{
@@ -32,6 +36,7 @@ func NewUserService(
 		registrationRepo: registrationRepo,
 		anggotaRepo:      anggotaRepo,
 		paymentService:   paymentService,
+		postingService:   postingService,
 		sequenceService:  sequenceService,
 	}
 }
@@ -147,7 +152,10 @@ func (s *UserService) RegisterUser(req *UserRegistrationRequest) (*postgres.User
 	return registration, nil
 }
 
-func (s *UserService) VerifyPayment(paymentID uint64) error {
+// VerifyPayment records the paid simpanan pokok of a registration and posts
+// it through the simpanan_pokok posting rule in the same transaction as the
+// status change. The member is linked to it on approval.
+func (s *UserService) VerifyPayment(paymentID uint64, verifiedBy uint64) error {
 	registration, err := s.registrationRepo.GetByPaymentID(paymentID)
 	if err != nil {
 		return fmt.Errorf("registration not found for payment ID %d: %v", paymentID, err)
@@ -157,8 +165,59 @@ func (s *UserService) VerifyPayment(paymentID uint64) error {
 		return errors.New("registration is not in pending_payment status")
 	}
 
-	registration.Status = "payment_verified"
-	return s.registrationRepo.Update(registration)
+	nomorTransaksi, err := s.generateNomorSimpananPokok(registration.KoperasiID)
+	if err != nil {
+		return fmt.Errorf("failed to generate nomor transaksi: %v", err)
+	}
+
+	return s.registrationRepo.Transaction(func(tx *gorm.DB) error {
+		registrationRepo := s.registrationRepo.WithTx(tx)
+
+		updated, err := registrationRepo.UpdateStatusFrom(registration.ID, "pending_payment", "payment_verified")
+		if err != nil {
+			return fmt.Errorf("failed to update registration: %v", err)
+		}
+		if !updated {
+			return errors.New("registration is not in pending_payment status")
+		}
+
+		now := time.Now()
+		transaksi := &postgres.SimpananPokokTransaksi{
+			KoperasiID:       registration.KoperasiID,
+			RegistrationID:   registration.ID,
+			NomorTransaksi:   nomorTransaksi,
+			Jumlah:           registration.SimpananPokokAmount,
+			PaymentID:        paymentID,
+			Status:           "paid",
+			TanggalTransaksi: now,
+			TanggalLunas:     &now,
+			Keterangan:       fmt.Sprintf("Simpanan pokok %s", registration.NamaLengkap),
+			CreatedBy:        verifiedBy,
+		}
+		if err := registrationRepo.CreateSimpananPokokTransaksi(transaksi); err != nil {
+			return fmt.Errorf("failed to create simpanan pokok transaksi: %v", err)
+		}
+
+		jurnal, err := s.postingService.Post(tx, &PostingRequest{
+			KoperasiID:       registration.KoperasiID,
+			KodeEvent:        PostingEventSimpananPokok,
+			TanggalTransaksi: now,
+			Referensi:        nomorTransaksi,
+			Keterangan:       transaksi.Keterangan,
+			SumberTransaksi:  "simpanan_pokok",
+			SumberID:         transaksi.ID,
+			Komponen:         map[string]money.Amount{"jumlah": transaksi.Jumlah},
+			CreatedBy:        verifiedBy,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to post jurnal: %v", err)
+		}
+
+		if jurnal != nil {
+			return registrationRepo.UpdateSimpananPokokJurnal(transaksi.ID, jurnal.ID)
+		}
+		return nil
+	})
 }
 
 func (s *UserService) ApproveRegistration(registrationID uint64, approvedBy uint64) error {
@@ -205,6 +264,10 @@ func (s *UserService) ApproveRegistration(registrationID uint64, approvedBy uint
 		return fmt.Errorf("failed to create anggota: %v", err)
 	}
 
+	if err := s.registrationRepo.UpdateSimpananPokokAnggota(registration.ID, anggota.ID); err != nil {
+		return fmt.Errorf("failed to link simpanan pokok: %v", err)
+	}
+
 	user := &postgres.User{
 		TenantID:     1,
 		KoperasiID:   registration.KoperasiID,
@@ -255,6 +318,14 @@ func (s *UserService) generateNIAK(koperasiID uint64) string {
 	return fmt.Sprintf("ANG%04d%06d", koperasiID, number)
 }
 
+func (s *UserService) generateNomorSimpananPokok(koperasiID uint64) (string, error) {
+	number, err := s.sequenceService.GetNextNumber(1, koperasiID, "simpanan_pokok")
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("SP%04d%08d", koperasiID, number), nil
+}
+
 func generateVerificationToken() (string, error) {
 	bytes := make([]byte, 16)
 	if _, err := rand.Read(bytes); err != nil {
@@ -279,7 +350,7 @@ type UserRegistrationRequest struct {
 	Email               string     `json:"email"`
 	Username            string     `json:"username"`
 	Password            string     `json:"password"`
-	SimpananPokokAmount float64    `json:"simpanan_pokok_amount"`
+	SimpananPokokAmount money.Amount `json:"simpanan_pokok_amount"`
 	PaymentProviderID   uint64     `json:"payment_provider_id"`
 	PaymentMethodID     uint64     `json:"payment_method_id"`
 }
\ No newline at end of file
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStoragePath(t *testing.T) {
+	root := t.TempDir()
+	s, err := NewLocalStorage(root)
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+
+	for _, key := range []string{"", "..", "../luar.txt", "lampiran/../../luar.txt", "lampiran/..", "./"} {
+		if path, err := s.path(key); err == nil {
+			t.Errorf("path(%q) = %s, want an error", key, path)
+		}
+	}
+
+	for key, want := range map[string]string{
+		"lampiran/1/jurnal_umum/7/a.pdf": filepath.Join(root, "lampiran", "1", "jurnal_umum", "7", "a.pdf"),
+		"lampiran/x/../a.pdf":            filepath.Join(root, "lampiran", "a.pdf"),
+		"/a.pdf":                         filepath.Join(root, "a.pdf"),
+	} {
+		got, err := s.path(key)
+		if err != nil || got != want {
+			t.Errorf("path(%q) = %s, %v, want %s", key, got, err, want)
+		}
+	}
+}
+
+func TestLocalStoragePutOpenDelete(t *testing.T) {
+	root := t.TempDir()
+	s, err := NewLocalStorage(root)
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+
+	key := "lampiran/1/nota.txt"
+	if err := s.Put(key, strings.NewReader("isi nota")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	f, err := s.Open(key)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	got, err := io.ReadAll(f)
+	f.Close()
+	if err != nil || string(got) != "isi nota" {
+		t.Errorf("content = %q, %v, want %q", got, err, "isi nota")
+	}
+
+	entries, err := os.ReadDir(filepath.Join(root, "lampiran", "1"))
+	if err != nil || len(entries) != 1 {
+		t.Errorf("directory holds %d entries, %v, want only the file", len(entries), err)
+	}
+
+	if err := s.Put("../luar.txt", strings.NewReader("x")); err == nil {
+		t.Error("Put outside the root should fail")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(root), "luar.txt")); !os.IsNotExist(err) {
+		t.Error("Put wrote outside the root")
+	}
+
+	if err := s.Delete(key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Open(key); err != ErrNotFound {
+		t.Errorf("Open after Delete = %v, want ErrNotFound", err)
+	}
+	if err := s.Delete(key); err != nil {
+		t.Errorf("deleting a missing key = %v, want nil", err)
+	}
+}
@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"koperasi-merah-putih/config"
+)
+
+// ErrNotFound is returned by Open when no object is stored under the key.
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage keeps uploaded files. Keys are slash separated paths chosen by the
+// caller; implementations must not let a key escape their own namespace.
+type Storage interface {
+	Put(key string, r io.Reader) error
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// NewStorage returns the storage backend selected in the configuration.
+func NewStorage(cfg *config.StorageConfig) (Storage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocalStorage(cfg.LocalPath)
+	default:
+		return nil, fmt.Errorf("unsupported storage driver: %s", cfg.Driver)
+	}
+}
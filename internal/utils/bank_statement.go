@@ -0,0 +1,356 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"koperasi-merah-putih/internal/money"
+)
+
+// BankStatement is a parsed bank statement. Line amounts are signed from the
+// account holder's side: positive is money into the account, negative is
+// money out.
+type BankStatement struct {
+	NomorRekening string
+	SaldoAwal     *money.Amount
+	SaldoAkhir    *money.Amount
+	Lines         []BankStatementLine
+}
+
+type BankStatementLine struct {
+	Tanggal    time.Time
+	Keterangan string
+	Referensi  string
+	Jumlah     money.Amount
+	Saldo      *money.Amount
+}
+
+// ParseBankStatementCSV reads a statement with a header row. Recognised
+// columns are tanggal/date, keterangan/description, referensi/reference,
+// saldo/balance, and either jumlah/amount (signed) or debit and kredit/credit
+// as printed by the bank (debit is money out).
+func ParseBankStatementCSV(r io.Reader) (*BankStatement, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %v", err)
+	}
+
+	columns := make(map[string]int)
+	aliases := map[string]string{
+		"tanggal": "tanggal", "date": "tanggal",
+		"keterangan": "keterangan", "description": "keterangan",
+		"referensi": "referensi", "reference": "referensi",
+		"jumlah": "jumlah", "amount": "jumlah",
+		"debit":  "debit",
+		"kredit": "kredit", "credit": "kredit",
+		"saldo": "saldo", "balance": "saldo",
+	}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if key, ok := aliases[name]; ok {
+			columns[key] = i
+		}
+	}
+
+	if _, ok := columns["tanggal"]; !ok {
+		return nil, fmt.Errorf("CSV must have a tanggal column")
+	}
+	_, hasJumlah := columns["jumlah"]
+	_, hasDebit := columns["debit"]
+	_, hasKredit := columns["kredit"]
+	if !hasJumlah && !(hasDebit && hasKredit) {
+		return nil, fmt.Errorf("CSV must have a jumlah column or debit and kredit columns")
+	}
+
+	field := func(record []string, key string) string {
+		i, ok := columns[key]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	statement := &BankStatement{}
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %v", row, err)
+		}
+		if field(record, "tanggal") == "" {
+			continue
+		}
+
+		tanggal, err := ParseDateString(field(record, "tanggal"))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %v", row, err)
+		}
+
+		line := BankStatementLine{
+			Tanggal:    tanggal,
+			Keterangan: field(record, "keterangan"),
+			Referensi:  field(record, "referensi"),
+		}
+
+		if hasJumlah {
+			line.Jumlah, err = parseStatementAmount(field(record, "jumlah"))
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %v", row, err)
+			}
+		} else {
+			debit, err := parseStatementAmount(field(record, "debit"))
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %v", row, err)
+			}
+			kredit, err := parseStatementAmount(field(record, "kredit"))
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %v", row, err)
+			}
+			line.Jumlah = kredit - debit
+		}
+
+		if saldoStr := field(record, "saldo"); saldoStr != "" {
+			saldo, err := parseStatementAmount(saldoStr)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %v", row, err)
+			}
+			line.Saldo = &saldo
+		}
+
+		statement.Lines = append(statement.Lines, line)
+	}
+
+	if n := len(statement.Lines); n > 0 && statement.Lines[0].Saldo != nil && statement.Lines[n-1].Saldo != nil {
+		saldoAwal := *statement.Lines[0].Saldo - statement.Lines[0].Jumlah
+		statement.SaldoAwal = &saldoAwal
+		statement.SaldoAkhir = statement.Lines[n-1].Saldo
+	}
+
+	return statement, nil
+}
+
+// ParseMT940 reads a SWIFT MT940 statement: :25: account, :60F:/:60M:
+// opening balance, :61: statement lines with their :86: description, and
+// :62F:/:62M: closing balance. Multiple statements in one file are appended.
+func ParseMT940(r io.Reader) (*BankStatement, error) {
+	var fields []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(text, ":") {
+			fields = append(fields, text)
+		} else if len(fields) > 0 && text != "-" && text != "-}" {
+			fields[len(fields)-1] += "\n" + text
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read MT940: %v", err)
+	}
+
+	statement := &BankStatement{}
+	for _, f := range fields {
+		end := strings.Index(f[1:], ":")
+		if end < 0 {
+			continue
+		}
+		tag, value := f[1:end+1], f[end+2:]
+
+		switch tag {
+		case "25":
+			if statement.NomorRekening == "" {
+				statement.NomorRekening = strings.TrimSpace(value)
+			}
+		case "60F", "60M":
+			if statement.SaldoAwal == nil {
+				saldo, err := parseMT940Balance(value)
+				if err != nil {
+					return nil, fmt.Errorf(":%s: %v", tag, err)
+				}
+				statement.SaldoAwal = &saldo
+			}
+		case "62F", "62M":
+			saldo, err := parseMT940Balance(value)
+			if err != nil {
+				return nil, fmt.Errorf(":%s: %v", tag, err)
+			}
+			statement.SaldoAkhir = &saldo
+		case "61":
+			line, err := parseMT940Line(value)
+			if err != nil {
+				return nil, fmt.Errorf(":61: %v", err)
+			}
+			statement.Lines = append(statement.Lines, *line)
+		case "86":
+			if n := len(statement.Lines); n > 0 && statement.Lines[n-1].Keterangan == "" {
+				statement.Lines[n-1].Keterangan = strings.Join(strings.Fields(value), " ")
+			}
+		}
+	}
+
+	if len(statement.Lines) == 0 {
+		return nil, fmt.Errorf("no :61: statement lines found")
+	}
+
+	return statement, nil
+}
+
+// parseMT940Balance parses D/C mark, YYMMDD date, currency and amount, e.g.
+// C240131IDR1500000,00.
+func parseMT940Balance(value string) (money.Amount, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 11 {
+		return 0, fmt.Errorf("invalid balance %q", value)
+	}
+
+	amount, err := money.Parse(strings.Replace(value[10:], ",", ".", 1))
+	if err != nil {
+		return 0, fmt.Errorf("invalid balance amount %q", value[10:])
+	}
+
+	if value[0] == 'D' {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+// parseMT940Line parses a :61: field: value date YYMMDD, optional entry date
+// MMDD, mark (C, D, RC, RD), optional funds code, amount, a four character
+// transaction type and the customer reference up to "//".
+func parseMT940Line(value string) (*BankStatementLine, error) {
+	value = strings.SplitN(value, "\n", 2)[0]
+	if len(value) < 8 {
+		return nil, fmt.Errorf("invalid statement line %q", value)
+	}
+
+	tanggal, err := time.Parse("060102", value[:6])
+	if err != nil {
+		return nil, fmt.Errorf("invalid value date %q", value[:6])
+	}
+	rest := value[6:]
+
+	if len(rest) >= 4 && isDigits(rest[:4]) {
+		rest = rest[4:]
+	}
+
+	var sign money.Amount
+	switch {
+	case strings.HasPrefix(rest, "RC"):
+		sign, rest = -1, rest[2:]
+	case strings.HasPrefix(rest, "RD"):
+		sign, rest = 1, rest[2:]
+	case strings.HasPrefix(rest, "C"):
+		sign, rest = 1, rest[1:]
+	case strings.HasPrefix(rest, "D"):
+		sign, rest = -1, rest[1:]
+	default:
+		return nil, fmt.Errorf("invalid debit/credit mark in %q", value)
+	}
+
+	if len(rest) > 0 && rest[0] >= 'A' && rest[0] <= 'Z' {
+		rest = rest[1:]
+	}
+
+	end := 0
+	for end < len(rest) && (rest[end] >= '0' && rest[end] <= '9' || rest[end] == ',') {
+		end++
+	}
+	amount, err := money.Parse(strings.Replace(rest[:end], ",", ".", 1))
+	if err != nil || rest[:end] == "" {
+		return nil, fmt.Errorf("invalid amount in %q", value)
+	}
+	rest = rest[end:]
+
+	if len(rest) >= 4 {
+		rest = rest[4:]
+	}
+	referensi := strings.SplitN(rest, "//", 2)[0]
+	if referensi == "NONREF" {
+		referensi = ""
+	}
+
+	return &BankStatementLine{
+		Tanggal:   tanggal,
+		Referensi: strings.TrimSpace(referensi),
+		Jumlah:    sign * amount,
+	}, nil
+}
+
+// parseStatementAmount accepts both 1,234,567.89 and 1.234.567,89 styles,
+// with an optional leading minus or trailing CR/DB marker. When only one
+// kind of separator appears, it is grouping if it repeats or is followed by
+// exactly three digits, so 150,000 and 1.500 are whole rupiah while 12,50
+// and 12.5 have decimals.
+func parseStatementAmount(value string) (money.Amount, error) {
+	value = strings.TrimSpace(strings.ReplaceAll(value, "Rp", ""))
+	if value == "" {
+		return 0, nil
+	}
+
+	sign := money.Amount(1)
+	upper := strings.ToUpper(value)
+	switch {
+	case strings.HasSuffix(upper, "DB"):
+		sign, value = -1, strings.TrimSpace(value[:len(value)-2])
+	case strings.HasSuffix(upper, "CR"):
+		value = strings.TrimSpace(value[:len(value)-2])
+	}
+	if strings.HasPrefix(value, "-") {
+		sign, value = -sign, value[1:]
+	}
+	value = strings.ReplaceAll(value, " ", "")
+
+	decimal := ""
+	lastDot := strings.LastIndex(value, ".")
+	lastComma := strings.LastIndex(value, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		decimal = "."
+		if lastComma > lastDot {
+			decimal = ","
+		}
+	case lastDot >= 0:
+		if strings.Count(value, ".") == 1 && len(value)-lastDot-1 != 3 {
+			decimal = "."
+		}
+	case lastComma >= 0:
+		if strings.Count(value, ",") == 1 && len(value)-lastComma-1 != 3 {
+			decimal = ","
+		}
+	}
+	for _, sep := range []string{".", ","} {
+		if sep != decimal {
+			value = strings.ReplaceAll(value, sep, "")
+		}
+	}
+	if decimal == "," {
+		value = strings.Replace(value, ",", ".", 1)
+	}
+	if strings.Count(value, ".") > 1 {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+
+	amount, err := money.Parse(value)
+	if err != nil || value == "" {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	return sign * amount, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
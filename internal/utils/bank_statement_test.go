@@ -0,0 +1,180 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"koperasi-merah-putih/internal/money"
+)
+
+func TestParseStatementAmount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want money.Amount
+	}{
+		{"150000", money.FromInt(150000)},
+		{"150,000", money.FromInt(150000)},
+		{"150.000", money.FromInt(150000)},
+		{"1.500", money.FromInt(1500)},
+		{"1,234,567", money.FromInt(1234567)},
+		{"1.234.567", money.FromInt(1234567)},
+		{"1,234,567.89", money.MustParse("1234567.89")},
+		{"1.234.567,89", money.MustParse("1234567.89")},
+		{"12,50", money.MustParse("12.5")},
+		{"12.5", money.MustParse("12.5")},
+		{"0,75", money.MustParse("0.75")},
+		{"1500.00", money.FromInt(1500)},
+		{"10,000 DB", -money.FromInt(10000)},
+		{"10.000,00 CR", money.FromInt(10000)},
+		{"-2,500", -money.FromInt(2500)},
+		{"Rp 1.500.000", money.FromInt(1500000)},
+		{"1 500 000", money.FromInt(1500000)},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		got, err := parseStatementAmount(tt.in)
+		if err != nil {
+			t.Errorf("parseStatementAmount(%q): unexpected error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseStatementAmount(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"abc", "1.2.3,4,5", "12-"} {
+		if _, err := parseStatementAmount(in); err == nil {
+			t.Errorf("parseStatementAmount(%q): expected an error", in)
+		}
+	}
+}
+
+func TestParseBankStatementCSV(t *testing.T) {
+	t.Run("debit and kredit columns", func(t *testing.T) {
+		csv := "\ufeffTanggal,Keterangan,Referensi,Debit,Kredit,Saldo\n" +
+			"02/01/2025,Setoran tunai,REF1,,\"1,500,000.00\",\"11,500,000.00\"\n" +
+			"03/01/2025,Biaya admin,,\"10,000\",,\"11,490,000.00\"\n" +
+			",,,,,\n"
+		statement, err := ParseBankStatementCSV(strings.NewReader(csv))
+		if err != nil {
+			t.Fatalf("ParseBankStatementCSV: %v", err)
+		}
+		if len(statement.Lines) != 2 {
+			t.Fatalf("got %d lines, want 2", len(statement.Lines))
+		}
+
+		first := statement.Lines[0]
+		if !first.Tanggal.Equal(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)) || first.Referensi != "REF1" || first.Keterangan != "Setoran tunai" {
+			t.Errorf("first line = %+v", first)
+		}
+		if first.Jumlah != money.FromInt(1500000) {
+			t.Errorf("credit = %s, want 1500000.00", first.Jumlah)
+		}
+		if statement.Lines[1].Jumlah != -money.FromInt(10000) {
+			t.Errorf("debit = %s, want -10000.00", statement.Lines[1].Jumlah)
+		}
+		if statement.SaldoAwal == nil || *statement.SaldoAwal != money.FromInt(10000000) {
+			t.Errorf("saldo awal = %v, want 10000000.00", statement.SaldoAwal)
+		}
+		if statement.SaldoAkhir == nil || *statement.SaldoAkhir != money.FromInt(11490000) {
+			t.Errorf("saldo akhir = %v, want 11490000.00", statement.SaldoAkhir)
+		}
+	})
+
+	t.Run("signed amount column", func(t *testing.T) {
+		csv := "date;amount\n2025-01-02;-250.000\n2025-01-03;1.250.000,50\n"
+		_, err := ParseBankStatementCSV(strings.NewReader(csv))
+		if err == nil {
+			t.Fatal("expected an error for a header without recognised columns")
+		}
+
+		csv = "date,description,amount\n2025-01-02,Transfer keluar,-250.000\n2025-01-03,Transfer masuk,\"1.250.000,50\"\n"
+		statement, err := ParseBankStatementCSV(strings.NewReader(csv))
+		if err != nil {
+			t.Fatalf("ParseBankStatementCSV: %v", err)
+		}
+		if got := statement.Lines[0].Jumlah; got != -money.FromInt(250000) {
+			t.Errorf("first amount = %s, want -250000.00", got)
+		}
+		if got := statement.Lines[1].Jumlah; got != money.MustParse("1250000.50") {
+			t.Errorf("second amount = %s, want 1250000.50", got)
+		}
+		if statement.SaldoAwal != nil {
+			t.Error("saldo awal without a saldo column")
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for name, csv := range map[string]string{
+			"no tanggal":   "keterangan,jumlah\nx,1\n",
+			"no amount":    "tanggal,keterangan\n2025-01-02,x\n",
+			"bad date":     "tanggal,jumlah\n2025/13/45,1\n",
+			"bad amount":   "tanggal,jumlah\n2025-01-02,satu\n",
+			"empty header": "",
+		} {
+			if _, err := ParseBankStatementCSV(strings.NewReader(csv)); err == nil {
+				t.Errorf("%s: expected an error", name)
+			}
+		}
+	})
+}
+
+func TestParseMT940(t *testing.T) {
+	mt940 := strings.Join([]string{
+		"{1:F01BANKIDJAXXXX0000000000}{4:",
+		":20:STMT250131",
+		":25:1234567890",
+		":28C:1/1",
+		":60F:C250101IDR10000000,00",
+		":61:2501020102C1500000,00NTRFREF001//BANK001",
+		":86:Setoran dari",
+		"anggota 001",
+		":61:250103D10000,NCHGNONREF",
+		":86:Biaya admin",
+		":61:250104RD250000,50NTRFREF003",
+		":62F:C250131IDR11240000,50",
+		"-}",
+	}, "\r\n")
+
+	statement, err := ParseMT940(strings.NewReader(mt940))
+	if err != nil {
+		t.Fatalf("ParseMT940: %v", err)
+	}
+	if statement.NomorRekening != "1234567890" {
+		t.Errorf("nomor rekening = %q", statement.NomorRekening)
+	}
+	if statement.SaldoAwal == nil || *statement.SaldoAwal != money.FromInt(10000000) {
+		t.Errorf("saldo awal = %v, want 10000000.00", statement.SaldoAwal)
+	}
+	if statement.SaldoAkhir == nil || *statement.SaldoAkhir != money.MustParse("11240000.50") {
+		t.Errorf("saldo akhir = %v, want 11240000.50", statement.SaldoAkhir)
+	}
+
+	want := []BankStatementLine{
+		{Tanggal: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), Referensi: "REF001", Keterangan: "Setoran dari anggota 001", Jumlah: money.FromInt(1500000)},
+		{Tanggal: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), Referensi: "", Keterangan: "Biaya admin", Jumlah: -money.FromInt(10000)},
+		{Tanggal: time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC), Referensi: "REF003", Jumlah: money.MustParse("250000.50")},
+	}
+	if len(statement.Lines) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(statement.Lines), len(want))
+	}
+	for i, line := range statement.Lines {
+		w := want[i]
+		if !line.Tanggal.Equal(w.Tanggal) || line.Referensi != w.Referensi || line.Keterangan != w.Keterangan || line.Jumlah != w.Jumlah {
+			t.Errorf("line %d = %+v, want %+v", i, line, w)
+		}
+	}
+
+	for name, in := range map[string]string{
+		"no lines":    ":25:1234567890\n:60F:C250101IDR0,00\n",
+		"bad mark":    ":61:250102X100,00NTRF\n",
+		"bad date":    ":61:259902C100,00NTRF\n",
+		"bad amount":  ":61:250102C,NTRF\n",
+		"bad balance": ":60F:C25\n:61:250102C100,00NTRF\n",
+	} {
+		if _, err := ParseMT940(strings.NewReader(in)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -173,6 +173,11 @@ func ParseCurrency(currency string) (float64, error) {
 	return strconv.ParseFloat(cleaned, 64)
 }
 
+// RoundCurrency rounds an amount to whole sen (two decimals).
+func RoundCurrency(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
 func CalculatePercentage(part, total float64) float64 {
 	if total == 0 {
 		return 0
@@ -0,0 +1,270 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A4 portrait in points.
+const (
+	pdfPageWidth  = 595.28
+	pdfPageHeight = 841.89
+	pdfMargin     = 50.0
+)
+
+// PDFColumn is one column of a PDF table row. Widths are in points and are
+// laid out left to right from the margin.
+type PDFColumn struct {
+	Width float64
+	Right bool
+}
+
+// PDFDocument builds a plain text report on A4 pages using the standard
+// Helvetica fonts, so nothing has to be embedded. Text is written top down;
+// a new page starts when the current one is full.
+type PDFDocument struct {
+	pages   []*bytes.Buffer
+	page    *bytes.Buffer
+	y       float64
+	columns []PDFColumn
+	footer  string
+}
+
+func NewPDFDocument() *PDFDocument {
+	d := &PDFDocument{}
+	d.NewPage()
+	return d
+}
+
+// SetFooter sets a line printed at the bottom of every page next to the page
+// number.
+func (d *PDFDocument) SetFooter(text string) {
+	d.footer = text
+}
+
+func (d *PDFDocument) NewPage() {
+	d.page = &bytes.Buffer{}
+	d.pages = append(d.pages, d.page)
+	d.y = pdfPageHeight - pdfMargin
+}
+
+// Space moves down by h points.
+func (d *PDFDocument) Space(h float64) {
+	d.y -= h
+}
+
+// Heading writes a centred bold line.
+func (d *PDFDocument) Heading(text string, size float64) {
+	d.ensure(size * 1.4)
+	x := (pdfPageWidth - pdfTextWidth(text, size, true)) / 2
+	d.text(x, d.y-size, text, size, true)
+	d.y -= size * 1.4
+}
+
+// Paragraph writes text wrapped to the page width.
+func (d *PDFDocument) Paragraph(text string, size float64) {
+	width := pdfPageWidth - 2*pdfMargin
+	for _, line := range pdfWrap(text, size, width) {
+		d.ensure(size * 1.4)
+		d.text(pdfMargin, d.y-size, line, size, false)
+		d.y -= size * 1.4
+	}
+}
+
+// SetColumns sets the column layout used by Row.
+func (d *PDFDocument) SetColumns(columns ...PDFColumn) {
+	d.columns = columns
+}
+
+// Row writes one table row. Cells that do not fit their column are cut.
+func (d *PDFDocument) Row(size float64, bold bool, cells ...string) {
+	d.ensure(size * 1.5)
+	x := pdfMargin
+	for i, col := range d.columns {
+		if i < len(cells) && cells[i] != "" {
+			text := pdfFit(cells[i], size, bold, col.Width-4)
+			tx := x
+			if col.Right {
+				tx = x + col.Width - pdfTextWidth(text, size, bold)
+			}
+			d.text(tx, d.y-size, text, size, bold)
+		}
+		x += col.Width
+	}
+	d.y -= size * 1.5
+}
+
+// Rule draws a horizontal line across the columns from column from onwards,
+// or across the page when no columns are set.
+func (d *PDFDocument) Rule(from int) {
+	x1, x2 := pdfMargin, pdfPageWidth-pdfMargin
+	if len(d.columns) > 0 {
+		x1 = pdfMargin
+		for i := 0; i < from && i < len(d.columns); i++ {
+			x1 += d.columns[i].Width
+		}
+		x2 = pdfMargin
+		for _, col := range d.columns {
+			x2 += col.Width
+		}
+	}
+	d.ensure(4)
+	fmt.Fprintf(d.page, "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, d.y-1, x2, d.y-1)
+	d.y -= 4
+}
+
+func (d *PDFDocument) ensure(h float64) {
+	if d.y-h < pdfMargin+20 {
+		d.NewPage()
+	}
+}
+
+func (d *PDFDocument) text(x, y float64, s string, size float64, bold bool) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.page, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfEscape(s))
+}
+
+// Write renders the document.
+func (d *PDFDocument) Write(w io.Writer) error {
+	var buf bytes.Buffer
+	var offsets []int
+
+	obj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// Objects 1-4 are the catalog, page tree and fonts; pages and their
+	// content streams follow in pairs.
+	n := len(d.pages)
+	kids := make([]string, n)
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), n))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		content := page.String()
+		footer := fmt.Sprintf("Halaman %d dari %d", i+1, n)
+		content += fmt.Sprintf("BT /F1 8.0 Tf %.2f %.2f Td (%s) Tj ET\n",
+			pdfPageWidth-pdfMargin-pdfTextWidth(footer, 8, false), pdfMargin-10, pdfEscape(footer))
+		if d.footer != "" {
+			content += fmt.Sprintf("BT /F1 8.0 Tf %.2f %.2f Td (%s) Tj ET\n",
+				pdfMargin, pdfMargin-10, pdfEscape(pdfFit(d.footer, 8, false, pdfPageWidth-2*pdfMargin-100)))
+		}
+
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 6+2*i))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// pdfEscape encodes s for a PDF string in WinAnsi. Characters outside
+// Latin-1 become "?".
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32:
+			b.WriteByte(' ')
+		case r < 128:
+			b.WriteRune(r)
+		case r < 256:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+func pdfWrap(text string, size, width float64) []string {
+	var lines []string
+	var line string
+	for _, word := range strings.Fields(text) {
+		next := word
+		if line != "" {
+			next = line + " " + word
+		}
+		if line != "" && pdfTextWidth(next, size, false) > width {
+			lines = append(lines, line)
+			next = word
+		}
+		line = next
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func pdfFit(s string, size float64, bold bool, width float64) string {
+	if pdfTextWidth(s, size, bold) <= width {
+		return s
+	}
+	r := []rune(s)
+	for len(r) > 0 && pdfTextWidth(string(r)+"...", size, bold) > width {
+		r = r[:len(r)-1]
+	}
+	return string(r) + "..."
+}
+
+// pdfTextWidth measures s in points from the Helvetica metrics.
+func pdfTextWidth(s string, size float64, bold bool) float64 {
+	widths := &helveticaWidths
+	if bold {
+		widths = &helveticaBoldWidths
+	}
+	total := 0
+	for _, r := range s {
+		if r >= 32 && r < 127 {
+			total += widths[r-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// Glyph widths of the printable ASCII range, per 1000 units of font size.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestPDFDocumentWellFormed builds a multi-page report and checks the file
+// structure a PDF reader relies on: header, object offsets in the xref
+// table, stream lengths, page count and trailer.
+func TestPDFDocumentWellFormed(t *testing.T) {
+	doc := NewPDFDocument()
+	doc.SetFooter("Koperasi (Contoh) \\ Café")
+	doc.Heading("Neraca", 14)
+	doc.Paragraph(strings.Repeat("Laporan posisi keuangan per akhir periode. ", 20), 10)
+	doc.SetColumns(PDFColumn{Width: 300}, PDFColumn{Width: 150, Right: true})
+	for i := 0; i < 80; i++ {
+		doc.Row(9, i == 0, fmt.Sprintf("Akun %d dengan nama yang sangat panjang sekali hingga terpotong", i), "1.500.000,00")
+	}
+	doc.Rule(1)
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	pdf := buf.String()
+
+	if !strings.HasPrefix(pdf, "%PDF-1.4\n") {
+		t.Fatal("missing PDF header")
+	}
+	if !strings.HasSuffix(pdf, "%%EOF\n") {
+		t.Fatal("missing EOF marker")
+	}
+
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(pdf)
+	if m == nil {
+		t.Fatal("missing startxref")
+	}
+	xref, _ := strconv.Atoi(m[1])
+	if !strings.HasPrefix(pdf[xref:], "xref\n") {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+
+	var size int
+	if _, err := fmt.Sscanf(pdf[xref:], "xref\n0 %d\n", &size); err != nil {
+		t.Fatalf("bad xref header: %v", err)
+	}
+	entries := strings.Split(pdf[xref:], "\n")[2 : 2+size]
+	for i, entry := range entries[1:] {
+		off, err := strconv.Atoi(entry[:10])
+		if err != nil {
+			t.Fatalf("bad xref entry %q", entry)
+		}
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !strings.HasPrefix(pdf[off:], want) {
+			t.Errorf("xref entry for object %d points at %q", i+1, pdf[off:off+10])
+		}
+	}
+	if !strings.Contains(pdf, fmt.Sprintf("/Size %d /Root 1 0 R", size)) {
+		t.Error("trailer size does not match the xref table")
+	}
+
+	pages := strings.Count(pdf, "/Type /Page /Parent")
+	if pages < 2 {
+		t.Errorf("expected the rows to spill onto a second page, got %d pages", pages)
+	}
+	if !strings.Contains(pdf, fmt.Sprintf("/Count %d >>", pages)) {
+		t.Error("page tree count does not match the pages")
+	}
+	if size != 5+2*pages {
+		t.Errorf("xref has %d entries, want %d", size, 5+2*pages)
+	}
+
+	streams := regexp.MustCompile(`<< /Length (\d+) >>\nstream\n`)
+	for _, loc := range streams.FindAllStringSubmatchIndex(pdf, -1) {
+		length, _ := strconv.Atoi(pdf[loc[2]:loc[3]])
+		if !strings.HasPrefix(pdf[loc[1]+length:], "endstream") {
+			t.Errorf("stream at %d is not %d bytes long", loc[1], length)
+		}
+	}
+
+	if !strings.Contains(pdf, `(Koperasi \(Contoh\) \\ Caf\351)`) {
+		t.Error("footer text is not escaped")
+	}
+	if !strings.Contains(pdf, fmt.Sprintf("(Halaman %d dari %d)", pages, pages)) {
+		t.Error("missing page number on the last page")
+	}
+}
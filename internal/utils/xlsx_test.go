@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"testing"
+)
+
+func TestXLSXRoundTrip(t *testing.T) {
+	rows := [][]string{
+		{"Kode", "Nama", "", "Saldo Normal"},
+		{"0101", "Kas & Bank <Utama>", "", "debit"},
+		{},
+		{"1-1001", "", "  spasi  "},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, XLSXSheet{Name: "Akun/COA", Rows: rows}, XLSXSheet{Rows: [][]string{{"lain"}}}); err != nil {
+		t.Fatalf("WriteXLSX: %v", err)
+	}
+
+	got, err := ReadXLSX(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ReadXLSX: %v", err)
+	}
+	want := [][]string{
+		{"Kode", "Nama", "", "Saldo Normal"},
+		{"0101", "Kas & Bank <Utama>", "", "debit"},
+		nil,
+		{"1-1001", "", "  spasi  "},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip = %q, want %q", got, want)
+	}
+
+	if err := WriteXLSX(&buf); err == nil {
+		t.Error("expected an error for a workbook without sheets")
+	}
+}
+
+// TestReadXLSXSharedStrings reads a workbook laid out the way spreadsheet
+// applications save it: shared and rich text strings, numbers, sparse cell
+// references and an absolute relationship target.
+func TestReadXLSXSharedStrings(t *testing.T) {
+	files := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="COA" sheetId="1" r:id="rId7"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId7" Type="worksheet" Target="/xl/worksheets/data.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<si><t>Kode</t></si><si><t>Nama</t></si><si><r><t>Kas </t></r><r><t>Kecil</t></r></si></sst>`,
+		"xl/worksheets/data.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>` +
+			`<row r="2"><c r="B2" t="s"><v>2</v></c><c><v>1500.5</v></c><c r="AA2" t="inlineStr"><is><t>akhir</t></is></c></row>` +
+			`</sheetData></worksheet>`,
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(xml.Header + content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadXLSX(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ReadXLSX: %v", err)
+	}
+
+	row2 := make([]string, 27)
+	row2[1], row2[2], row2[26] = "Kas Kecil", "1500.5", "akhir"
+	want := [][]string{{"Kode", "", "Nama"}, row2}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadXLSX = %q, want %q", got, want)
+	}
+}
+
+func TestReadXLSXInvalid(t *testing.T) {
+	data := []byte("not a zip file")
+	if _, err := ReadXLSX(bytes.NewReader(data), int64(len(data))); err == nil {
+		t.Error("expected an error for a file that is not a workbook")
+	}
+}
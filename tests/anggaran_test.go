@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+	"koperasi-merah-putih/internal/services"
+	"koperasi-merah-putih/tests/helpers"
+)
+
+// TestPostingRespectsBlockedAnggaran checks that a journal posted straight
+// from a transaction is refused when it overspends a budget in block mode,
+// the same as a manual journal posted with PostJurnal.
+func TestPostingRespectsBlockedAnggaran(t *testing.T) {
+	db := helpers.OpenTestPostgres(t)
+	helpers.CreateKoperasi(t, db, 1)
+
+	kas := helpers.CreateAkun(t, db, 1, "1101", "aset", "debit")
+	beban := helpers.CreateAkun(t, db, 1, "5101", "beban", "debit")
+
+	require.NoError(t, db.Create(&postgres.Anggaran{
+		TenantID:        1,
+		KoperasiID:      1,
+		Tahun:           2025,
+		Versi:           1,
+		KontrolAnggaran: "block",
+		Status:          "approved",
+		Details: []postgres.AnggaranDetail{
+			{AkunID: beban.ID, Bulan: 5, Jumlah: money.FromInt(1000000)},
+		},
+	}).Error)
+
+	helpers.CreatePostingRule(t, db, 1, services.PostingEventPembelian,
+		postgres.PostingRuleLine{AkunID: beban.ID, Posisi: "debit", Komponen: "total"},
+		postgres.PostingRuleLine{AkunID: kas.ID, Posisi: "kredit", Komponen: "total"})
+
+	postingService := services.NewPostingService(
+		postgresRepo.NewPostingRepository(db),
+		postgresRepo.NewFinancialRepository(db),
+		newFinancialService(db),
+	)
+	post := func(jumlah int64) error {
+		_, err := postingService.Post(db, &services.PostingRequest{
+			KoperasiID:       1,
+			KodeEvent:        services.PostingEventPembelian,
+			TanggalTransaksi: time.Date(2025, 5, 20, 0, 0, 0, 0, time.Local),
+			SumberTransaksi:  "pembelian_header",
+			Komponen:         map[string]money.Amount{"total": money.FromInt(jumlah)},
+			CreatedBy:        1,
+		})
+		return err
+	}
+
+	require.NoError(t, post(600000))
+	assert.Error(t, post(500000))
+	assert.NoError(t, post(400000))
+}
@@ -0,0 +1,321 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+	"koperasi-merah-putih/internal/services"
+	"koperasi-merah-putih/tests/helpers"
+)
+
+type asetFixture struct {
+	db         *gorm.DB
+	service    *services.AsetService
+	kas        postgres.COAAkun
+	modal      postgres.COAAkun
+	akunAset   postgres.COAAkun
+	akumulasi  postgres.COAAkun
+	beban      postgres.COAAkun
+	labaRugi   postgres.COAAkun
+	kategoriID uint64
+}
+
+func newAsetFixture(t *testing.T) *asetFixture {
+	t.Helper()
+	db := helpers.OpenTestPostgres(t)
+	helpers.CreateKoperasi(t, db, 1)
+
+	f := &asetFixture{
+		db:        db,
+		kas:       helpers.CreateAkun(t, db, 1, "1101", "aset", "debit"),
+		akunAset:  helpers.CreateAkun(t, db, 1, "1301", "aset", "debit"),
+		akumulasi: helpers.CreateAkun(t, db, 1, "1302", "aset", "kredit"),
+		modal:     helpers.CreateAkun(t, db, 1, "3101", "ekuitas", "kredit"),
+		labaRugi:  helpers.CreateAkun(t, db, 1, "4901", "pendapatan", "kredit"),
+		beban:     helpers.CreateAkun(t, db, 1, "5201", "beban", "debit"),
+	}
+
+	f.service = services.NewAsetService(
+		postgresRepo.NewAsetRepository(db),
+		postgresRepo.NewFinancialRepository(db),
+		postgresRepo.NewProdukRepository(db),
+		newFinancialService(db),
+		services.NewSequenceService(postgresRepo.NewSequenceRepository(db)),
+	)
+
+	kategori, err := f.service.CreateKategori(&services.CreateKategoriAsetRequest{
+		TenantID:            1,
+		KoperasiID:          1,
+		Kode:                "KND",
+		Nama:                "Kendaraan",
+		UmurEkonomis:        12,
+		MetodePenyusutan:    services.MetodeGarisLurus,
+		AkunAsetID:          f.akunAset.ID,
+		AkunAkumulasiID:     f.akumulasi.ID,
+		AkunBebanID:         f.beban.ID,
+		AkunLabaRugiLepasID: f.labaRugi.ID,
+	})
+	require.NoError(t, err)
+	f.kategoriID = kategori.ID
+	return f
+}
+
+// beli registers an asset bought with kas, so its cost is in the ledger.
+func (f *asetFixture) beli(t *testing.T, nama string, tanggal time.Time, harga money.Amount, umur int, metode string) *postgres.AsetTetap {
+	t.Helper()
+	aset, err := f.service.CreateAset(&services.CreateAsetRequest{
+		TenantID:         1,
+		KoperasiID:       1,
+		KategoriAsetID:   f.kategoriID,
+		Nama:             nama,
+		TanggalPerolehan: tanggal,
+		HargaPerolehan:   harga,
+		UmurEkonomis:     umur,
+		MetodePenyusutan: metode,
+		AkunSumberID:     f.kas.ID,
+	}, 1)
+	require.NoError(t, err)
+	return aset
+}
+
+func (f *asetFixture) aset(t *testing.T, id uint64) *postgres.AsetTetap {
+	t.Helper()
+	aset, err := f.service.GetAset(id)
+	require.NoError(t, err)
+	return aset
+}
+
+// TestRunPenyusutanCatchUp runs depreciation once for three missed months:
+// each month gets its own journal, assets bought later join in their first
+// month, the last month of a short life takes the rounding remainder and a
+// second run does nothing.
+func TestRunPenyusutanCatchUp(t *testing.T) {
+	f := newAsetFixture(t)
+
+	mobil := f.beli(t, "Mobil", tgl(2025, 1, 10), money.FromInt(12000000), 12, services.MetodeGarisLurus)
+	motor := f.beli(t, "Motor", tgl(2025, 2, 20), money.FromInt(6000000), 12, services.MetodeSaldoMenurun)
+	printer := f.beli(t, "Printer", tgl(2025, 1, 5), money.FromInt(1000), 3, services.MetodeGarisLurus)
+
+	hasil, err := f.service.RunPenyusutan(1, tgl(2025, 4, 15), 1)
+	require.NoError(t, err)
+	require.Len(t, hasil, 4)
+
+	want := []struct {
+		periode time.Time
+		aset    int
+		total   money.Amount
+	}{
+		{tgl(2025, 1, 31), 2, money.MustParse("1000333.33")},
+		{tgl(2025, 2, 28), 3, money.MustParse("2000333.33")},
+		{tgl(2025, 3, 31), 3, money.MustParse("1833666.67")},
+		{tgl(2025, 4, 30), 2, money.MustParse("1694444.45")},
+	}
+	for i, w := range want {
+		assert.True(t, hasil[i].Periode.Equal(w.periode), "periode %d = %v", i, hasil[i].Periode)
+		assert.Equal(t, w.aset, hasil[i].JumlahAset, "jumlah aset %d", i)
+		assert.Equal(t, w.total, hasil[i].Total, "total %d", i)
+
+		var jurnal postgres.JurnalUmum
+		require.NoError(t, f.db.First(&jurnal, hasil[i].JurnalID).Error)
+		assert.Equal(t, "posted", jurnal.Status)
+		assert.Equal(t, w.total, jurnal.TotalDebit)
+		assert.True(t, jurnal.TanggalTransaksi.Equal(w.periode))
+	}
+
+	m := f.aset(t, mobil.ID)
+	assert.Equal(t, money.FromInt(4000000), m.AkumulasiPenyusutan)
+	assert.Equal(t, money.FromInt(8000000), m.NilaiBuku)
+	require.NotNil(t, m.PenyusutanSampai)
+	assert.True(t, m.PenyusutanSampai.Equal(tgl(2025, 4, 30)))
+
+	riwayat, err := f.service.GetRiwayatPenyusutan(printer.ID)
+	require.NoError(t, err)
+	require.Len(t, riwayat, 3)
+	jumlah := []money.Amount{riwayat[0].Jumlah, riwayat[1].Jumlah, riwayat[2].Jumlah}
+	assert.ElementsMatch(t, []money.Amount{money.MustParse("333.33"), money.MustParse("333.33"), money.MustParse("333.34")}, jumlah)
+	p := f.aset(t, printer.ID)
+	assert.Zero(t, p.NilaiBuku)
+	assert.True(t, p.PenyusutanSampai.Equal(tgl(2025, 4, 30)), "fully depreciated assets still move on")
+
+	assert.Equal(t, money.MustParse("2527777.78"), f.aset(t, motor.ID).AkumulasiPenyusutan)
+
+	hasil, err = f.service.RunPenyusutan(1, tgl(2025, 4, 30), 1)
+	require.NoError(t, err)
+	assert.Empty(t, hasil)
+
+	_, err = f.service.RunPenyusutan(1, time.Now().AddDate(0, 2, 0), 1)
+	assert.Error(t, err, "future months are refused")
+}
+
+// TestRunPenyusutanAkumulasiAwal continues an asset taken over with prior
+// accumulated depreciation from the month after PenyusutanSampai.
+func TestRunPenyusutanAkumulasiAwal(t *testing.T) {
+	f := newAsetFixture(t)
+
+	sampai := tgl(2024, 12, 15)
+	aset, err := f.service.CreateAset(&services.CreateAsetRequest{
+		TenantID:         1,
+		KoperasiID:       1,
+		KategoriAsetID:   f.kategoriID,
+		Nama:             "Gedung",
+		TanggalPerolehan: tgl(2024, 1, 1),
+		HargaPerolehan:   money.FromInt(12000000),
+		UmurEkonomis:     24,
+		AkumulasiAwal:    money.FromInt(6000000),
+		PenyusutanSampai: &sampai,
+	}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, money.FromInt(6000000), aset.NilaiBuku)
+	assert.True(t, aset.PenyusutanSampai.Equal(tgl(2024, 12, 31)))
+
+	_, err = f.service.CreateAset(&services.CreateAsetRequest{
+		TenantID:         1,
+		KoperasiID:       1,
+		KategoriAsetID:   f.kategoriID,
+		Nama:             "Gudang",
+		TanggalPerolehan: tgl(2024, 1, 1),
+		HargaPerolehan:   money.FromInt(12000000),
+		AkumulasiAwal:    money.FromInt(6000000),
+	}, 1)
+	assert.Error(t, err, "akumulasi_awal needs penyusutan_sampai")
+
+	hasil, err := f.service.RunPenyusutan(1, tgl(2025, 2, 1), 1)
+	require.NoError(t, err)
+	require.Len(t, hasil, 2)
+	assert.True(t, hasil[0].Periode.Equal(tgl(2025, 1, 31)))
+	assert.Equal(t, money.FromInt(500000), hasil[0].Total)
+	assert.Equal(t, money.FromInt(500000), hasil[1].Total)
+
+	a := f.aset(t, aset.ID)
+	assert.Equal(t, money.FromInt(7000000), a.AkumulasiPenyusutan)
+	assert.Equal(t, money.FromInt(5000000), a.NilaiBuku)
+}
+
+// TestLepasAset disposes of assets at a gain and at a loss, after the guard
+// that depreciation runs through the month before the disposal.
+func TestLepasAset(t *testing.T) {
+	f := newAsetFixture(t)
+
+	mobil := f.beli(t, "Mobil", tgl(2025, 1, 10), money.FromInt(12000000), 12, services.MetodeGarisLurus)
+	meja := f.beli(t, "Meja", tgl(2025, 1, 10), money.FromInt(2400000), 12, services.MetodeGarisLurus)
+
+	_, err := f.service.RunPenyusutan(1, tgl(2025, 3, 31), 1)
+	require.NoError(t, err)
+
+	_, err = f.service.LepasAset(mobil.ID, &services.LepasAsetRequest{Tanggal: tgl(2025, 5, 10), HargaJual: money.FromInt(9000000), AkunKasID: f.kas.ID}, 1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "04/2025")
+
+	_, err = f.service.LepasAset(mobil.ID, &services.LepasAsetRequest{Tanggal: tgl(2025, 4, 10), HargaJual: money.FromInt(9000000)}, 1)
+	assert.Error(t, err, "harga_jual needs akun_kas_id")
+
+	jual, err := f.service.LepasAset(mobil.ID, &services.LepasAsetRequest{Tanggal: tgl(2025, 4, 10), HargaJual: money.FromInt(9500000), AkunKasID: f.kas.ID}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "dilepas", jual.Status)
+	assertJurnalLines(t, f.db, jual.JurnalPelepasanID, map[uint64][2]money.Amount{
+		f.akunAset.ID:  {0, money.FromInt(12000000)},
+		f.akumulasi.ID: {money.FromInt(3000000), 0},
+		f.kas.ID:       {money.FromInt(9500000), 0},
+		f.labaRugi.ID:  {0, money.FromInt(500000)},
+	})
+
+	_, err = f.service.LepasAset(mobil.ID, &services.LepasAsetRequest{Tanggal: tgl(2025, 4, 11)}, 1)
+	assert.Error(t, err, "an asset is disposed only once")
+
+	hapus, err := f.service.LepasAset(meja.ID, &services.LepasAsetRequest{Tanggal: tgl(2025, 4, 30)}, 1)
+	require.NoError(t, err)
+	assertJurnalLines(t, f.db, hapus.JurnalPelepasanID, map[uint64][2]money.Amount{
+		f.akunAset.ID:  {0, money.FromInt(2400000)},
+		f.akumulasi.ID: {money.FromInt(600000), 0},
+		f.labaRugi.ID:  {money.FromInt(1800000), 0},
+	})
+}
+
+// TestRegisterAsetReconciles checks the register against the ledger: it
+// balances with assets bought, taken over with an opening balance,
+// depreciated and disposed of, and shows a journal posted straight to the
+// accumulated depreciation account as a difference.
+func TestRegisterAsetReconciles(t *testing.T) {
+	f := newAsetFixture(t)
+
+	sampai := tgl(2024, 12, 31)
+	_, err := f.service.CreateAset(&services.CreateAsetRequest{
+		TenantID:         1,
+		KoperasiID:       1,
+		KategoriAsetID:   f.kategoriID,
+		Nama:             "Gedung",
+		TanggalPerolehan: tgl(2024, 1, 1),
+		HargaPerolehan:   money.FromInt(12000000),
+		UmurEkonomis:     24,
+		AkumulasiAwal:    money.FromInt(6000000),
+		PenyusutanSampai: &sampai,
+	}, 1)
+	require.NoError(t, err)
+	postJurnal(t, f.db, tgl(2024, 12, 31),
+		postgres.JurnalDetail{AkunID: f.akunAset.ID, Debit: money.FromInt(12000000)},
+		postgres.JurnalDetail{AkunID: f.akumulasi.ID, Kredit: money.FromInt(6000000)},
+		postgres.JurnalDetail{AkunID: f.modal.ID, Kredit: money.FromInt(6000000)})
+
+	f.beli(t, "Mobil", tgl(2025, 1, 10), money.FromInt(12000000), 12, services.MetodeGarisLurus)
+	meja := f.beli(t, "Meja", tgl(2025, 1, 10), money.FromInt(2400000), 12, services.MetodeGarisLurus)
+
+	_, err = f.service.RunPenyusutan(1, tgl(2025, 2, 28), 1)
+	require.NoError(t, err)
+	_, err = f.service.LepasAset(meja.ID, &services.LepasAsetRequest{Tanggal: tgl(2025, 3, 5)}, 1)
+	require.NoError(t, err)
+
+	februari, err := f.service.GetRegister(1, tgl(2025, 2, 28))
+	require.NoError(t, err)
+	assert.True(t, februari.Seimbang, "%+v", februari.Rekonsiliasi)
+	assert.Len(t, februari.Items, 3)
+	assert.Equal(t, money.FromInt(26400000), februari.TotalHargaPerolehan)
+	assert.Equal(t, money.FromInt(9400000), februari.TotalAkumulasi)
+
+	maret, err := f.service.GetRegister(1, tgl(2025, 3, 31))
+	require.NoError(t, err)
+	assert.True(t, maret.Seimbang, "%+v", maret.Rekonsiliasi)
+	assert.Len(t, maret.Items, 2, "the disposed asset leaves the register")
+	assert.Equal(t, money.FromInt(24000000), maret.TotalHargaPerolehan)
+	assert.Equal(t, money.FromInt(9000000), maret.TotalAkumulasi)
+	assert.Equal(t, money.FromInt(15000000), maret.TotalNilaiBuku)
+
+	postJurnal(t, f.db, tgl(2025, 3, 20),
+		postgres.JurnalDetail{AkunID: f.beban.ID, Debit: money.FromInt(250000)},
+		postgres.JurnalDetail{AkunID: f.akumulasi.ID, Kredit: money.FromInt(250000)})
+
+	maret, err = f.service.GetRegister(1, tgl(2025, 3, 31))
+	require.NoError(t, err)
+	assert.False(t, maret.Seimbang)
+	for _, item := range maret.Rekonsiliasi {
+		switch item.AkunID {
+		case f.akumulasi.ID:
+			assert.Equal(t, money.FromInt(250000), item.Selisih)
+		default:
+			assert.Zero(t, item.Selisih, "akun %s", item.KodeAkun)
+		}
+	}
+}
+
+// assertJurnalLines checks the debit and kredit per akun of a journal.
+func assertJurnalLines(t *testing.T, db *gorm.DB, jurnalID uint64, want map[uint64][2]money.Amount) {
+	t.Helper()
+
+	var details []postgres.JurnalDetail
+	require.NoError(t, db.Where("jurnal_id = ?", jurnalID).Find(&details).Error)
+
+	got := make(map[uint64][2]money.Amount)
+	for _, detail := range details {
+		line := got[detail.AkunID]
+		line[0] += detail.Debit
+		line[1] += detail.Kredit
+		got[detail.AkunID] = line
+	}
+	assert.Equal(t, want, got)
+}
@@ -0,0 +1,139 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"koperasi-merah-putih/internal/database"
+	postgresModel "koperasi-merah-putih/internal/models/postgres"
+)
+
+// OpenTestPostgres migrates the server schema into a throwaway PostgreSQL
+// schema and returns a connection bound to it. The database comes from
+// TEST_DATABASE_URL; the test is skipped when it is not set. The schema is
+// dropped when the test finishes.
+func OpenTestPostgres(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	config := &gorm.Config{
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+		DisableForeignKeyConstraintWhenMigrating: true,
+	}
+
+	admin, err := gorm.Open(postgres.Open(dsn), config)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("failed to create test schema: %v", err)
+	}
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	db, err := gorm.Open(postgres.Open(withSearchPath(dsn, schema)), config)
+	if err != nil {
+		t.Fatalf("failed to connect to test schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if err := db.AutoMigrate(database.Models()...); err != nil {
+		t.Fatalf("failed to migrate test schema: %v", err)
+	}
+
+	return db
+}
+
+// withSearchPath adds search_path to either a URL or a keyword/value DSN.
+func withSearchPath(dsn, schema string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		if strings.Contains(dsn, "?") {
+			return dsn + "&search_path=" + schema
+		}
+		return dsn + "?search_path=" + schema
+	}
+	return dsn + " search_path=" + schema
+}
+
+// CreateKoperasi adds the koperasi row that posting locks to chain its
+// journals.
+func CreateKoperasi(t *testing.T, db *gorm.DB, koperasiID uint64) {
+	t.Helper()
+
+	koperasi := postgresModel.Koperasi{
+		ID:           koperasiID,
+		TenantID:     1,
+		NomorSK:      fmt.Sprintf("SK-%03d", koperasiID),
+		NIK:          koperasiID,
+		NamaKoperasi: fmt.Sprintf("Koperasi %d", koperasiID),
+		NamaSK:       fmt.Sprintf("Koperasi %d", koperasiID),
+	}
+	if err := db.Create(&koperasi).Error; err != nil {
+		t.Fatalf("failed to create koperasi %d: %v", koperasiID, err)
+	}
+}
+
+// CreateAkun adds a COA akun of the koperasi under the kategori of the given
+// tipe, creating the kategori on first use.
+func CreateAkun(t *testing.T, db *gorm.DB, koperasiID uint64, kode, tipe, saldoNormal string) postgresModel.COAAkun {
+	t.Helper()
+
+	kategori := postgresModel.COAKategori{Kode: tipe, Nama: tipe, Tipe: tipe}
+	if err := db.Where("kode = ?", tipe).FirstOrCreate(&kategori).Error; err != nil {
+		t.Fatalf("failed to create kategori %s: %v", tipe, err)
+	}
+
+	akun := postgresModel.COAAkun{
+		TenantID:    1,
+		KoperasiID:  koperasiID,
+		KodeAkun:    kode,
+		NamaAkun:    "Akun " + kode,
+		KategoriID:  kategori.ID,
+		SaldoNormal: saldoNormal,
+		IsAktif:     true,
+	}
+	if err := db.Create(&akun).Error; err != nil {
+		t.Fatalf("failed to create akun %s: %v", kode, err)
+	}
+	akun.Kategori = kategori
+	return akun
+}
+
+// CreatePostingRule adds an active posting rule of the koperasi for
+// kodeEvent with the given lines.
+func CreatePostingRule(t *testing.T, db *gorm.DB, koperasiID uint64, kodeEvent string, lines ...postgresModel.PostingRuleLine) {
+	t.Helper()
+
+	rule := postgresModel.PostingRule{
+		TenantID:   1,
+		KoperasiID: koperasiID,
+		KodeEvent:  kodeEvent,
+		Nama:       kodeEvent,
+		IsAktif:    true,
+		Lines:      lines,
+	}
+	if err := db.Create(&rule).Error; err != nil {
+		t.Fatalf("failed to create posting rule %s: %v", kodeEvent, err)
+	}
+}
@@ -16,9 +16,11 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 
+	"koperasi-merah-putih/internal/gateway"
 	"koperasi-merah-putih/internal/handlers"
 	"koperasi-merah-putih/internal/middleware"
 	"koperasi-merah-putih/internal/models/cassandra"
+	"koperasi-merah-putih/internal/money"
 	postgresModel "koperasi-merah-putih/internal/models/postgres"
 	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
 	"koperasi-merah-putih/internal/services"
@@ -34,6 +36,7 @@ type BaseTestSuite struct {
 	Token          string
 	AdminToken     string
 	SuperAdminToken string
+	PPOBProvider   *gateway.FakePPOBAdapter
 }
 
 // SetupSuite runs before all tests
@@ -85,6 +88,11 @@ func (s *BaseTestSuite) setupRouter() {
 	koperasiRepo := postgresRepo.NewKoperasiRepository(s.DB)
 	produkRepo := postgresRepo.NewProdukRepository(s.DB)
 	financialRepo := postgresRepo.NewFinancialRepository(s.DB)
+	postingRepo := postgresRepo.NewPostingRepository(s.DB)
+	periodeRepo := postgresRepo.NewPeriodeRepository(s.DB)
+	anggaranRepo := postgresRepo.NewAnggaranRepository(s.DB)
+	lampiranRepo := postgresRepo.NewLampiranRepository(s.DB)
+	jurnalTemplateRepo := postgresRepo.NewJurnalTemplateRepository(s.DB)
 	simpanPinjamRepo := postgresRepo.NewSimpanPinjamRepository(s.DB)
 	ppobRepo := postgresRepo.NewPPOBRepository(s.DB)
 	klinikRepo := postgresRepo.NewKlinikRepository(s.DB)
@@ -93,27 +101,37 @@ func (s *BaseTestSuite) setupRouter() {
 	masterDataRepo := postgresRepo.NewMasterDataRepository(s.DB)
 	paymentRepo := postgresRepo.NewPaymentRepository(s.DB)
 	paymentProviderRepo := postgresRepo.NewPaymentProviderRepository(s.DB)
+	pajakRepo := postgresRepo.NewPajakRepository(s.DB)
 
 	// Initialize services
 	sequenceService := services.NewSequenceService(sequenceRepo)
-	paymentService := services.NewPaymentService(paymentRepo, paymentProviderRepo, sequenceService)
-	userService := services.NewUserService(userRepo, registrationRepo, anggotaRepo, paymentService, sequenceService)
-	koperasiService := services.NewKoperasiService(koperasiRepo, anggotaRepo, wilayahRepo, sequenceService)
-	produkService := services.NewProdukService(produkRepo, sequenceRepo)
-	financialService := services.NewFinancialService(financialRepo, sequenceService)
-	simpanPinjamService := services.NewSimpanPinjamService(simpanPinjamRepo, sequenceService)
-	ppobService := services.NewPPOBService(ppobRepo, paymentService, sequenceService)
-	klinikService := services.NewKlinikService(klinikRepo, sequenceService)
+	paymentService := services.NewPaymentService(paymentRepo, paymentProviderRepo, sequenceService, nil)
+	financialService := services.NewFinancialService(financialRepo, periodeRepo, anggaranRepo, lampiranRepo, sequenceService)
+	postingService := services.NewPostingService(postingRepo, financialRepo, financialService)
+	userService := services.NewUserService(userRepo, registrationRepo, anggotaRepo, paymentService, postingService, sequenceService)
+	periodeService := services.NewPeriodeService(periodeRepo, financialRepo, financialService)
+	jurnalTemplateService := services.NewJurnalTemplateService(jurnalTemplateRepo, financialRepo, financialService)
+	coaService := services.NewCOAService(financialRepo, koperasiRepo)
+	laporanKeuanganService := services.NewLaporanKeuanganService(financialRepo, koperasiRepo)
+	koperasiService := services.NewKoperasiService(koperasiRepo, anggotaRepo, wilayahRepo, sequenceService, coaService)
+	pajakService := services.NewPajakService(pajakRepo, produkRepo, koperasiRepo, postingService, sequenceService)
+	simpanPinjamService := services.NewSimpanPinjamService(simpanPinjamRepo, postingService, pajakService, sequenceService)
+	produkService := services.NewProdukService(produkRepo, sequenceRepo, postingService, simpanPinjamService, pajakService)
+	s.PPOBProvider = gateway.NewFakePPOBAdapter(money.FromInt(1000000))
+	ppobAdapters := gateway.NewPPOBAdapterRegistry(nil)
+	ppobAdapters.Register(gateway.PPOBAdapterFake, s.PPOBProvider.Factory())
+	ppobService := services.NewPPOBService(ppobRepo, paymentService, postingService, financialService, simpanPinjamService, sequenceService, ppobAdapters)
+	klinikService := services.NewKlinikService(klinikRepo, postingService, sequenceService)
 	wilayahService := services.NewWilayahService(wilayahRepo)
 	masterDataService := services.NewMasterDataService(masterDataRepo)
-	reportingService := services.NewReportingService(koperasiRepo, anggotaRepo, produkRepo, simpanPinjamRepo, financialRepo, klinikRepo, nil)
+	reportingService := services.NewReportingService(koperasiRepo, anggotaRepo, produkRepo, simpanPinjamRepo, financialRepo, klinikRepo, financialService, nil)
 
 	// Initialize handlers
 	userHandler := handlers.NewUserHandler(userService)
 	paymentHandler := handlers.NewPaymentHandler(paymentService, userService, ppobService)
 	koperasiHandler := handlers.NewKoperasiHandler(koperasiService)
 	produkHandler := handlers.NewProdukHandler(produkService)
-	financialHandler := handlers.NewFinancialHandler(financialService)
+	financialHandler := handlers.NewFinancialHandler(financialService, postingService, periodeService, jurnalTemplateService, coaService, laporanKeuanganService)
 	simpanPinjamHandler := handlers.NewSimpanPinjamHandler(simpanPinjamService)
 	ppobHandler := handlers.NewPPOBHandler(ppobService)
 	klinikHandler := handlers.NewKlinikHandler(klinikService)
@@ -0,0 +1,283 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+	"koperasi-merah-putih/internal/services"
+	"koperasi-merah-putih/tests/helpers"
+)
+
+type hutangFixture struct {
+	db       *gorm.DB
+	service  *services.HutangService
+	supplier postgres.Supplier
+	kas      postgres.COAAkun
+	hutang   postgres.COAAkun
+	pph      postgres.COAAkun
+}
+
+func newHutangFixture(t *testing.T) *hutangFixture {
+	t.Helper()
+	db := helpers.OpenTestPostgres(t)
+	helpers.CreateKoperasi(t, db, 1)
+
+	f := &hutangFixture{
+		db:     db,
+		kas:    helpers.CreateAkun(t, db, 1, "1101", "aset", "debit"),
+		hutang: helpers.CreateAkun(t, db, 1, "2101", "kewajiban", "kredit"),
+		pph:    helpers.CreateAkun(t, db, 1, "2102", "kewajiban", "kredit"),
+	}
+	helpers.CreatePostingRule(t, db, 1, services.PostingEventPembayaranHutang,
+		postgres.PostingRuleLine{AkunID: f.hutang.ID, Posisi: "debit", Komponen: "total"},
+		postgres.PostingRuleLine{AkunID: f.kas.ID, Posisi: "kredit", Komponen: "transfer"},
+		postgres.PostingRuleLine{AkunID: f.pph.ID, Posisi: "kredit", Komponen: "pph"})
+	require.NoError(t, db.Create(&postgres.PengaturanPajak{KoperasiID: 1, TarifPPh23: 2}).Error)
+
+	f.supplier = postgres.Supplier{KoperasiID: 1, Kode: "SUP1", Nama: "CV Sumber Makmur", NPWP: "01.234.567.8-901.000", TermPembayaran: 30}
+	require.NoError(t, db.Create(&f.supplier).Error)
+
+	financialService := newFinancialService(db)
+	f.service = services.NewHutangService(
+		postgresRepo.NewProdukRepository(db),
+		services.NewPostingService(postgresRepo.NewPostingRepository(db), postgresRepo.NewFinancialRepository(db), financialService),
+		newPajakService(db),
+		services.NewSequenceService(postgresRepo.NewSequenceRepository(db)),
+	)
+	return f
+}
+
+// faktur adds an unpaid invoice of supplier. jatuhTempo may be nil to fall
+// back to the supplier's TermPembayaran.
+func (f *hutangFixture) faktur(t *testing.T, supplierID uint64, nomor string, tanggal time.Time, jatuhTempo *time.Time, total, pajak money.Amount) *postgres.PembelianHeader {
+	t.Helper()
+	pembelian := &postgres.PembelianHeader{
+		KoperasiID:        1,
+		SupplierID:        supplierID,
+		NomorFaktur:       nomor,
+		TanggalFaktur:     tanggal,
+		TanggalJatuhTempo: jatuhTempo,
+		TotalPajak:        pajak,
+		GrandTotal:        total,
+		StatusPembayaran:  "unpaid",
+	}
+	require.NoError(t, f.db.Create(pembelian).Error)
+	return pembelian
+}
+
+func (f *hutangFixture) pembelian(t *testing.T, id uint64) postgres.PembelianHeader {
+	t.Helper()
+	var pembelian postgres.PembelianHeader
+	require.NoError(t, f.db.First(&pembelian, id).Error)
+	return pembelian
+}
+
+func (f *hutangFixture) bayar(jumlah money.Amount, alokasi ...services.AlokasiBayarHutang) (*services.PembayaranHutang, error) {
+	return f.service.BayarHutang(&services.BayarHutangRequest{
+		KoperasiID:       1,
+		SupplierID:       f.supplier.ID,
+		TanggalBayar:     tgl(2025, 4, 1),
+		MetodePembayaran: "transfer",
+		Jumlah:           jumlah,
+		Alokasi:          alokasi,
+	}, 1)
+}
+
+// tglPtr is tgl for optional dates.
+func tglPtr(year int, month time.Month, day int) *time.Time {
+	t := tgl(year, month, day)
+	return &t
+}
+
+// TestBayarHutangFIFO pays the open invoices in order of due date, with the
+// invoice without TanggalJatuhTempo falling due after TermPembayaran, and
+// refuses more than the supplier is owed.
+func TestBayarHutangFIFO(t *testing.T) {
+	f := newHutangFixture(t)
+
+	a := f.faktur(t, f.supplier.ID, "INV-A", tgl(2025, 1, 1), tglPtr(2025, 3, 1), money.FromInt(1000000), 0)
+	b := f.faktur(t, f.supplier.ID, "INV-B", tgl(2025, 1, 15), tglPtr(2025, 2, 1), money.FromInt(2000000), 0)
+	c := f.faktur(t, f.supplier.ID, "INV-C", tgl(2025, 2, 1), nil, money.FromInt(500000), 0)
+
+	hasil, err := f.bayar(money.FromInt(2500000))
+	require.NoError(t, err)
+	assert.Equal(t, money.FromInt(2500000), hasil.Total)
+	assert.Equal(t, money.FromInt(2500000), hasil.Dibayar)
+	require.Len(t, hasil.Pembayaran, 2)
+	assert.Equal(t, b.ID, hasil.Pembayaran[0].PembelianHeaderID)
+	assert.Equal(t, money.FromInt(2000000), hasil.Pembayaran[0].JumlahBayar)
+	assert.Equal(t, a.ID, hasil.Pembayaran[1].PembelianHeaderID)
+	assert.Equal(t, money.FromInt(500000), hasil.Pembayaran[1].JumlahBayar)
+
+	assert.Equal(t, "paid", f.pembelian(t, b.ID).StatusPembayaran)
+	assert.Equal(t, "partial", f.pembelian(t, a.ID).StatusPembayaran)
+	assert.Equal(t, "unpaid", f.pembelian(t, c.ID).StatusPembayaran)
+	assertJurnalLines(t, f.db, hasil.JurnalID, map[uint64][2]money.Amount{
+		f.hutang.ID: {money.FromInt(2500000), 0},
+		f.kas.ID:    {0, money.FromInt(2500000)},
+	})
+
+	_, err = f.bayar(money.FromInt(1500000))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "by 500000.00")
+	assert.Equal(t, money.FromInt(500000), f.pembelian(t, a.ID).TotalBayar, "a refused payment changes nothing")
+	assert.Zero(t, f.pembelian(t, c.ID).TotalBayar)
+
+	hasil, err = f.bayar(money.FromInt(1000000))
+	require.NoError(t, err)
+	assert.Len(t, hasil.Pembayaran, 2)
+	assert.Equal(t, "paid", f.pembelian(t, a.ID).StatusPembayaran)
+	assert.Equal(t, "paid", f.pembelian(t, c.ID).StatusPembayaran)
+}
+
+// TestBayarHutangAlokasi pays the invoices chosen by the caller and rejects
+// invoices listed twice, invoices of another supplier and amounts above what
+// is still owed.
+func TestBayarHutangAlokasi(t *testing.T) {
+	f := newHutangFixture(t)
+
+	lain := postgres.Supplier{KoperasiID: 1, Kode: "SUP2", Nama: "UD Lain"}
+	require.NoError(t, f.db.Create(&lain).Error)
+
+	a := f.faktur(t, f.supplier.ID, "INV-A", tgl(2025, 1, 1), tglPtr(2025, 2, 1), money.FromInt(1000000), 0)
+	b := f.faktur(t, f.supplier.ID, "INV-B", tgl(2025, 1, 15), tglPtr(2025, 3, 1), money.FromInt(2000000), 0)
+	asing := f.faktur(t, lain.ID, "INV-X", tgl(2025, 1, 10), nil, money.FromInt(700000), 0)
+
+	hasil, err := f.bayar(0,
+		services.AlokasiBayarHutang{PembelianHeaderID: b.ID, Jumlah: money.FromInt(2000000)},
+		services.AlokasiBayarHutang{PembelianHeaderID: a.ID, Jumlah: money.FromInt(300000)})
+	require.NoError(t, err)
+	assert.Equal(t, money.FromInt(2300000), hasil.Total)
+	assert.Equal(t, "paid", f.pembelian(t, b.ID).StatusPembayaran)
+	assert.Equal(t, money.FromInt(300000), f.pembelian(t, a.ID).TotalBayar)
+
+	for name, alokasi := range map[string][]services.AlokasiBayarHutang{
+		"listed twice": {
+			{PembelianHeaderID: a.ID, Jumlah: money.FromInt(100000)},
+			{PembelianHeaderID: a.ID, Jumlah: money.FromInt(100000)},
+		},
+		"another supplier": {{PembelianHeaderID: asing.ID, Jumlah: money.FromInt(100000)}},
+		"already paid":     {{PembelianHeaderID: b.ID, Jumlah: money.FromInt(100000)}},
+		"above sisa":       {{PembelianHeaderID: a.ID, Jumlah: money.FromInt(800000)}},
+	} {
+		_, err := f.bayar(0, alokasi...)
+		assert.Error(t, err, name)
+	}
+	assert.Equal(t, money.FromInt(300000), f.pembelian(t, a.ID).TotalBayar)
+	assert.Zero(t, f.pembelian(t, asing.ID).TotalBayar)
+}
+
+// TestBayarHutangPPh withholds PPh 23 on the part of the invoice that is not
+// PPN: the invoice is settled in full while less leaves kas.
+func TestBayarHutangPPh(t *testing.T) {
+	f := newHutangFixture(t)
+
+	jasa := f.faktur(t, f.supplier.ID, "INV-JASA", tgl(2025, 3, 1), nil, money.FromInt(11100000), money.FromInt(1100000))
+
+	hasil, err := f.service.BayarHutang(&services.BayarHutangRequest{
+		KoperasiID:       1,
+		SupplierID:       f.supplier.ID,
+		TanggalBayar:     tgl(2025, 3, 20),
+		MetodePembayaran: "transfer",
+		Jumlah:           money.FromInt(11100000),
+		PPh:              &services.PotongPPhRequest{Jenis: services.JenisPPh23, KodeObjekPajak: "24-104-01"},
+	}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, money.FromInt(11100000), hasil.Total)
+	assert.Equal(t, money.FromInt(200000), hasil.PPh)
+	assert.Equal(t, money.FromInt(10900000), hasil.Dibayar)
+	require.NotNil(t, hasil.Potongan)
+	assert.Equal(t, money.FromInt(10000000), hasil.Potongan.Bruto)
+	assert.Equal(t, hasil.JurnalID, hasil.Potongan.JurnalID)
+
+	assert.Equal(t, "paid", f.pembelian(t, jasa.ID).StatusPembayaran)
+	assertJurnalLines(t, f.db, hasil.JurnalID, map[uint64][2]money.Amount{
+		f.hutang.ID: {money.FromInt(11100000), 0},
+		f.kas.ID:    {0, money.FromInt(10900000)},
+		f.pph.ID:    {0, money.FromInt(200000)},
+	})
+
+	kecil := f.faktur(t, f.supplier.ID, "INV-KECIL", tgl(2025, 3, 2), nil, money.FromInt(100000), 0)
+	_, err = f.service.BayarHutang(&services.BayarHutangRequest{
+		KoperasiID:       1,
+		SupplierID:       f.supplier.ID,
+		TanggalBayar:     tgl(2025, 3, 20),
+		MetodePembayaran: "transfer",
+		Jumlah:           money.FromInt(100000),
+		PPh:              &services.PotongPPhRequest{Jenis: services.JenisPPh23, Tarif: 100},
+	}, 1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not less than the payment")
+	assert.Equal(t, "unpaid", f.pembelian(t, kecil.ID).StatusPembayaran)
+
+	var potongan int64
+	require.NoError(t, f.db.Model(&postgres.PotonganPajak{}).Count(&potongan).Error)
+	assert.Equal(t, int64(1), potongan)
+}
+
+// TestUmurHutang ages the payables on 15 April 2025 across every bucket,
+// leaving out payments made after that day and invoices dated later.
+func TestUmurHutang(t *testing.T) {
+	f := newHutangFixture(t)
+	id := f.supplier.ID
+
+	f.faktur(t, id, "INV-1", tgl(2025, 4, 1), tglPtr(2025, 4, 20), money.FromInt(100000), 0)
+	f.faktur(t, id, "INV-2", tgl(2025, 3, 15), tglPtr(2025, 4, 15), money.FromInt(200000), 0)
+	f.faktur(t, id, "INV-3", tgl(2025, 2, 14), tglPtr(2025, 3, 16), money.FromInt(300000), 0)
+	f.faktur(t, id, "INV-4", tgl(2025, 2, 13), tglPtr(2025, 3, 15), money.FromInt(400000), 0)
+	f.faktur(t, id, "INV-5", tgl(2025, 1, 1), nil, money.FromInt(500000), 0)
+	f.faktur(t, id, "INV-6", tgl(2024, 12, 1), tglPtr(2025, 1, 1), money.FromInt(600000), 0)
+	f.faktur(t, id, "INV-7", tgl(2025, 4, 16), nil, money.FromInt(700000), 0)
+
+	lunas := f.faktur(t, id, "INV-8", tgl(2025, 4, 1), tglPtr(2025, 5, 1), money.FromInt(1000000), 0)
+	for _, p := range []postgres.PembayaranPembelian{
+		{PembelianHeaderID: lunas.ID, NomorPembayaran: "BYR-1", TanggalBayar: tgl(2025, 4, 10), JumlahBayar: money.FromInt(400000)},
+		{PembelianHeaderID: lunas.ID, NomorPembayaran: "BYR-2", TanggalBayar: tgl(2025, 4, 20), JumlahBayar: money.FromInt(600000)},
+	} {
+		require.NoError(t, f.db.Create(&p).Error)
+	}
+	require.NoError(t, f.db.Model(lunas).Updates(map[string]interface{}{"status_pembayaran": "paid", "total_bayar": money.FromInt(1000000)}).Error)
+
+	laporan, err := f.service.GetUmurHutang(1, tgl(2025, 4, 15))
+	require.NoError(t, err)
+	require.Len(t, laporan.Supplier, 1)
+
+	want := map[string]struct {
+		hariLewat int
+		kelompok  string
+		sisa      money.Amount
+	}{
+		"INV-1": {-5, services.UmurBelumJatuhTempo, money.FromInt(100000)},
+		"INV-2": {0, services.Umur0Sampai30, money.FromInt(200000)},
+		"INV-3": {30, services.Umur0Sampai30, money.FromInt(300000)},
+		"INV-4": {31, services.Umur31Sampai60, money.FromInt(400000)},
+		"INV-5": {74, services.Umur61Sampai90, money.FromInt(500000)},
+		"INV-6": {104, services.UmurLebih90, money.FromInt(600000)},
+		"INV-8": {-16, services.UmurBelumJatuhTempo, money.FromInt(600000)},
+	}
+	faktur := laporan.Supplier[0].Faktur
+	require.Len(t, faktur, len(want))
+	for _, item := range faktur {
+		w, ok := want[item.NomorFaktur]
+		require.True(t, ok, "unexpected faktur %s", item.NomorFaktur)
+		assert.Equal(t, w.hariLewat, item.HariLewat, item.NomorFaktur)
+		assert.Equal(t, w.kelompok, item.Kelompok, item.NomorFaktur)
+		assert.Equal(t, w.sisa, item.Sisa, item.NomorFaktur)
+	}
+
+	total := laporan.Total
+	assert.Equal(t, money.FromInt(700000), total.BelumJatuhTempo)
+	assert.Equal(t, money.FromInt(500000), total.Hari0Sampai30)
+	assert.Equal(t, money.FromInt(400000), total.Hari31Sampai60)
+	assert.Equal(t, money.FromInt(500000), total.Hari61Sampai90)
+	assert.Equal(t, money.FromInt(600000), total.LebihDari90)
+	assert.Equal(t, money.FromInt(2700000), total.Total)
+	assert.Equal(t, total, laporan.Supplier[0].SaldoUmur)
+}
@@ -0,0 +1,187 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+	"koperasi-merah-putih/internal/services"
+	"koperasi-merah-putih/tests/helpers"
+)
+
+type jurnalTemplateFixture struct {
+	service *services.JurnalTemplateService
+	kas     postgres.COAAkun
+	beban   postgres.COAAkun
+}
+
+func newJurnalTemplateFixture(t *testing.T) *jurnalTemplateFixture {
+	t.Helper()
+	db := helpers.OpenTestPostgres(t)
+	helpers.CreateKoperasi(t, db, 1)
+
+	return &jurnalTemplateFixture{
+		service: services.NewJurnalTemplateService(
+			postgresRepo.NewJurnalTemplateRepository(db),
+			postgresRepo.NewFinancialRepository(db),
+			newFinancialService(db),
+		),
+		kas:   helpers.CreateAkun(t, db, 1, "1101", "aset", "debit"),
+		beban: helpers.CreateAkun(t, db, 1, "5101", "beban", "debit"),
+	}
+}
+
+// createTemplate schedules a monthly salary journal from 31 January 2025.
+// Its amount is the parameter gaji.
+func (f *jurnalTemplateFixture) createTemplate(t *testing.T, gaji money.Amount) *postgres.JurnalTemplate {
+	t.Helper()
+	template, err := f.service.CreateTemplate(&services.JurnalTemplateRequest{
+		TenantID:     1,
+		KoperasiID:   1,
+		Nama:         "Gaji karyawan",
+		Frekuensi:    "monthly",
+		TanggalMulai: tgl(2025, 1, 31),
+		Lines: []services.JurnalTemplateLineRequest{
+			{AkunID: f.beban.ID, Posisi: "debit", Parameter: "gaji"},
+			{AkunID: f.kas.ID, Posisi: "kredit", Parameter: "gaji"},
+		},
+		Parameter: []services.JurnalTemplateParameterRequest{
+			{Nama: "gaji", Nilai: gaji},
+		},
+	}, 1)
+	require.NoError(t, err)
+	return template
+}
+
+func (f *jurnalTemplateFixture) template(t *testing.T, id uint64) *postgres.JurnalTemplate {
+	t.Helper()
+	template, err := f.service.GetTemplate(id)
+	require.NoError(t, err)
+	return template
+}
+
+func (f *jurnalTemplateFixture) jurnalDates(t *testing.T, id uint64) []string {
+	t.Helper()
+	riwayat, err := f.service.GetRiwayat(id)
+	require.NoError(t, err)
+	var dates []string
+	for _, jurnal := range riwayat {
+		dates = append(dates, jurnal.TanggalTransaksi.Format("2006-01-02"))
+	}
+	return dates
+}
+
+// tgl returns midnight, local time, of the date.
+func tgl(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
+
+// TestRunDueJurnalTemplate catches up every missed occurrence exactly once,
+// even when several runs race for the template, and moves the next date on
+// with the day clamped to the end of the month.
+func TestRunDueJurnalTemplate(t *testing.T) {
+	f := newJurnalTemplateFixture(t)
+	template := f.createTemplate(t, money.FromInt(5000000))
+	assert.True(t, tgl(2025, 1, 31).Equal(template.BerikutnyaPada))
+
+	now := tgl(2025, 4, 15)
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = f.service.RunDue(now)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	assert.ElementsMatch(t, []string{"2025-01-31", "2025-02-28", "2025-03-31"}, f.jurnalDates(t, template.ID))
+	template = f.template(t, template.ID)
+	assert.True(t, tgl(2025, 4, 30).Equal(template.BerikutnyaPada), template.BerikutnyaPada)
+	assert.NotNil(t, template.TerakhirJalan)
+	assert.Empty(t, template.TerakhirError)
+
+	require.NoError(t, f.service.RunDue(now))
+	assert.Len(t, f.jurnalDates(t, template.ID), 3, "a repeated run generates nothing new")
+
+	require.NoError(t, f.service.RunDue(tgl(2025, 4, 30)))
+	assert.Len(t, f.jurnalDates(t, template.ID), 4)
+	assert.True(t, tgl(2025, 5, 31).Equal(f.template(t, template.ID).BerikutnyaPada))
+}
+
+// TestRunDueJurnalTemplateGagal records each failed run, waits one, two,
+// four and eight hours before retrying and deactivates the template at the
+// fifth failure in a row. Reactivating it starts with a clean count.
+func TestRunDueJurnalTemplateGagal(t *testing.T) {
+	f := newJurnalTemplateFixture(t)
+	template := f.createTemplate(t, 0)
+
+	now := tgl(2025, 2, 1)
+	for gagal := 1; gagal <= 4; gagal++ {
+		require.Error(t, f.service.RunDue(now), "run %d", gagal)
+
+		template = f.template(t, template.ID)
+		assert.Equal(t, gagal, template.GagalBeruntun)
+		assert.Contains(t, template.TerakhirError, "produced no journal lines")
+		assert.True(t, template.IsAktif)
+		require.NotNil(t, template.CobaLagiPada)
+		tunggu := time.Duration(1<<(gagal-1)) * time.Hour
+		assert.True(t, now.Add(tunggu).Equal(*template.CobaLagiPada), "run %d: retry at %s", gagal, template.CobaLagiPada)
+
+		require.NoError(t, f.service.RunDue(now.Add(tunggu-time.Minute)), "no retry before the backoff ends")
+		assert.Equal(t, gagal, f.template(t, template.ID).GagalBeruntun)
+
+		now = *template.CobaLagiPada
+	}
+
+	require.Error(t, f.service.RunDue(now))
+	template = f.template(t, template.ID)
+	assert.False(t, template.IsAktif)
+	assert.Equal(t, 5, template.GagalBeruntun)
+	assert.Nil(t, template.CobaLagiPada)
+	assert.Contains(t, template.TerakhirError, "deactivated after 5 failed runs")
+	assert.True(t, tgl(2025, 1, 31).Equal(template.BerikutnyaPada), "a failed run keeps the occurrence")
+
+	require.NoError(t, f.service.RunDue(now.Add(24*time.Hour)), "an inactive template is not retried")
+	assert.Empty(t, f.jurnalDates(t, template.ID))
+
+	require.NoError(t, f.service.SetParameter(template.ID, map[string]money.Amount{"gaji": money.FromInt(5000000)}))
+	require.NoError(t, f.service.SetAktif(template.ID, true))
+	template = f.template(t, template.ID)
+	assert.Zero(t, template.GagalBeruntun)
+	assert.Nil(t, template.CobaLagiPada)
+
+	require.NoError(t, f.service.RunDue(now))
+	assert.Equal(t, []string{"2025-01-31"}, f.jurnalDates(t, template.ID))
+	template = f.template(t, template.ID)
+	assert.Empty(t, template.TerakhirError)
+	assert.True(t, tgl(2025, 2, 28).Equal(template.BerikutnyaPada))
+}
+
+// TestSetParameterJurnalTemplate retries a failed template at the next run
+// once its parameters have been corrected, without waiting for the backoff.
+func TestSetParameterJurnalTemplate(t *testing.T) {
+	f := newJurnalTemplateFixture(t)
+	template := f.createTemplate(t, 0)
+
+	now := tgl(2025, 2, 1)
+	require.Error(t, f.service.RunDue(now))
+	require.NotNil(t, f.template(t, template.ID).CobaLagiPada)
+
+	require.NoError(t, f.service.SetParameter(template.ID, map[string]money.Amount{"gaji": money.FromInt(5000000)}))
+	require.NoError(t, f.service.RunDue(now.Add(time.Minute)))
+	assert.Equal(t, []string{"2025-01-31"}, f.jurnalDates(t, template.ID))
+	template = f.template(t, template.ID)
+	assert.Zero(t, template.GagalBeruntun)
+	assert.Nil(t, template.CobaLagiPada)
+}
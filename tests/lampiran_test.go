@@ -0,0 +1,217 @@
+package tests
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+	"koperasi-merah-putih/internal/services"
+	"koperasi-merah-putih/internal/storage"
+	"koperasi-merah-putih/tests/helpers"
+)
+
+const maxLampiranTest = 1024
+
+type lampiranFixture struct {
+	db        *gorm.DB
+	root      string
+	service   *services.LampiranService
+	financial *services.FinancialService
+	jurnal    *postgres.JurnalUmum
+}
+
+// newLampiranFixture stores attachments in a temporary directory, capped at
+// maxLampiranTest bytes, and has a draft journal of koperasi 1 to attach to.
+func newLampiranFixture(t *testing.T) *lampiranFixture {
+	t.Helper()
+	db := helpers.OpenTestPostgres(t)
+	helpers.CreateKoperasi(t, db, 1)
+	kas := helpers.CreateAkun(t, db, 1, "1101", "aset", "debit")
+	modal := helpers.CreateAkun(t, db, 1, "3101", "ekuitas", "kredit")
+
+	f := &lampiranFixture{db: db, root: t.TempDir(), financial: newFinancialService(db)}
+	local, err := storage.NewLocalStorage(f.root)
+	require.NoError(t, err)
+	f.service = services.NewLampiranService(
+		postgresRepo.NewLampiranRepository(db),
+		postgresRepo.NewFinancialRepository(db),
+		postgresRepo.NewProdukRepository(db),
+		postgresRepo.NewSimpanPinjamRepository(db),
+		local,
+		maxLampiranTest,
+	)
+
+	f.jurnal, err = f.financial.CreateJurnalUmum(&services.CreateJurnalRequest{
+		TenantID:         1,
+		KoperasiID:       1,
+		TanggalTransaksi: tgl(2025, 3, 1),
+		Keterangan:       "Setoran modal",
+		Details: []services.CreateJurnalDetailRequest{
+			{AkunID: kas.ID, Debit: money.FromInt(100000)},
+			{AkunID: modal.ID, Kredit: money.FromInt(100000)},
+		},
+		CreatedBy: 1,
+	})
+	require.NoError(t, err)
+	return f
+}
+
+func (f *lampiranFixture) upload(namaFile, isi string) (*postgres.Lampiran, error) {
+	return f.service.UploadLampiran(&services.UploadLampiranRequest{
+		JenisDokumen: postgres.LampiranJurnalUmum,
+		DokumenID:    f.jurnal.ID,
+		NamaFile:     namaFile,
+	}, strings.NewReader(isi), 1)
+}
+
+// files lists what is stored under the storage root.
+func (f *lampiranFixture) files(t *testing.T) []string {
+	t.Helper()
+	var files []string
+	err := filepath.WalkDir(f.root, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files = append(files, path)
+		}
+		return err
+	})
+	require.NoError(t, err)
+	return files
+}
+
+const (
+	isiPNG  = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+	isiPDF  = "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n"
+	isiZIP  = "PK\x03\x04\x14\x00\x06\x00"
+	isiDOC  = "\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1\x00\x00"
+	isiTeks = "Kuitansi setoran modal"
+)
+
+// TestUploadLampiranJenis accepts images and office documents whose content
+// matches the extension and refuses everything else.
+func TestUploadLampiranJenis(t *testing.T) {
+	f := newLampiranFixture(t)
+
+	for nama, want := range map[string]struct {
+		isi         string
+		contentType string
+	}{
+		"nota.png":    {isiPNG, "image/png"},
+		"faktur.pdf":  {isiPDF, "application/pdf"},
+		"rekap.xlsx":  {isiZIP, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+		"surat.doc":   {isiDOC, "application/msword"},
+		"catatan.txt": {isiTeks, "text/plain; charset=utf-8"},
+		"NOTA.PNG":    {isiPNG, "image/png"},
+	} {
+		lampiran, err := f.upload(nama, want.isi)
+		require.NoError(t, err, nama)
+		assert.Equal(t, want.contentType, lampiran.ContentType, nama)
+		assert.Equal(t, nama, lampiran.NamaFile)
+	}
+
+	for nama, isi := range map[string]string{
+		"setup.exe":      "MZ\x90\x00",
+		"gambar.svg":     "<svg></svg>",
+		"halaman.html":   "<html></html>",
+		"tanpa-ekstensi": isiTeks,
+		"nota.png":       isiPDF,
+		"faktur.pdf":     "<html><body>bukan pdf</body></html>",
+		"rekap.xlsx":     isiTeks,
+		"kosong.txt":     "",
+	} {
+		_, err := f.upload(nama, isi)
+		assert.Error(t, err, nama)
+	}
+
+	for jenis, id := range map[string]uint64{
+		"rahasia":                   f.jurnal.ID,
+		postgres.LampiranJurnalUmum: f.jurnal.ID + 100,
+		postgres.LampiranPembelian:  1,
+	} {
+		_, err := f.service.UploadLampiran(&services.UploadLampiranRequest{
+			JenisDokumen: jenis,
+			DokumenID:    id,
+			NamaFile:     "nota.png",
+		}, strings.NewReader(isiPNG), 1)
+		assert.Error(t, err, jenis)
+	}
+
+	lampirans, err := f.service.GetLampiranList(postgres.LampiranJurnalUmum, f.jurnal.ID)
+	require.NoError(t, err)
+	assert.Len(t, lampirans, 6)
+	assert.Len(t, f.files(t), 6, "refused files are not left in storage")
+}
+
+// TestUploadLampiranUkuran takes a file of exactly the maximum size and
+// refuses one byte more without leaving it in storage.
+func TestUploadLampiranUkuran(t *testing.T) {
+	f := newLampiranFixture(t)
+
+	isi := strings.Repeat("a", maxLampiranTest)
+	lampiran, err := f.upload("penuh.txt", isi)
+	require.NoError(t, err)
+	assert.Equal(t, int64(maxLampiranTest), lampiran.Ukuran)
+	sum := sha256.Sum256([]byte(isi))
+	assert.Equal(t, hex.EncodeToString(sum[:]), lampiran.Checksum)
+
+	_, err = f.upload("lebih.txt", isi+"a")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "maximum size")
+	assert.Len(t, f.files(t), 1)
+
+	var jumlah int64
+	require.NoError(t, f.db.Model(&postgres.Lampiran{}).Count(&jumlah).Error)
+	assert.Equal(t, int64(1), jumlah)
+}
+
+// TestLampiranStorageKey stores each file under a generated key scoped to
+// its koperasi and document, whatever name the client sends, and keeps the
+// attachments of a posted journal.
+func TestLampiranStorageKey(t *testing.T) {
+	f := newLampiranFixture(t)
+
+	pola := regexp.MustCompile(`^lampiran/1/jurnal_umum/\d+/lampiran_\d{14}_[0-9a-f]{6}\.png$`)
+	a, err := f.upload("../../../etc/passwd.png", isiPNG)
+	require.NoError(t, err)
+	b, err := f.upload("nota.png", isiPNG)
+	require.NoError(t, err)
+
+	for _, lampiran := range []*postgres.Lampiran{a, b} {
+		assert.Regexp(t, pola, lampiran.StorageKey)
+		assert.True(t, strings.HasPrefix(lampiran.StorageKey, "lampiran/1/jurnal_umum/"))
+	}
+	assert.NotEqual(t, a.StorageKey, b.StorageKey)
+	assert.Equal(t, "../../../etc/passwd.png", a.NamaFile)
+	for _, path := range f.files(t) {
+		assert.True(t, strings.HasPrefix(path, filepath.Join(f.root, "lampiran", "1", "jurnal_umum")+string(filepath.Separator)), path)
+	}
+
+	lampiran, content, err := f.service.OpenLampiran(a.ID)
+	require.NoError(t, err)
+	isi, err := io.ReadAll(content)
+	content.Close()
+	require.NoError(t, err)
+	assert.Equal(t, isiPNG, string(isi))
+	assert.Equal(t, "image/png", lampiran.ContentType)
+
+	require.NoError(t, f.service.DeleteLampiran(a.ID))
+	assert.Len(t, f.files(t), 1)
+	_, _, err = f.service.OpenLampiran(a.ID)
+	assert.Error(t, err)
+
+	_, err = f.financial.PostJurnal(f.jurnal.ID, 1)
+	require.NoError(t, err)
+	assert.Error(t, f.service.DeleteLampiran(b.ID), "a posted journal keeps its attachments")
+	assert.Len(t, f.files(t), 1)
+}
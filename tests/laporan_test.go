@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+	"koperasi-merah-putih/internal/services"
+	"koperasi-merah-putih/tests/helpers"
+)
+
+// postJurnal writes a posted journal of koperasi 1 straight to the database.
+func postJurnal(t *testing.T, db *gorm.DB, tanggal time.Time, details ...postgres.JurnalDetail) {
+	t.Helper()
+
+	var total money.Amount
+	for _, detail := range details {
+		total += detail.Debit
+	}
+	require.NoError(t, db.Create(&postgres.JurnalUmum{
+		TenantID:         1,
+		KoperasiID:       1,
+		NomorJurnal:      tanggal.Format("JU20060102150405.000000000"),
+		TanggalTransaksi: tanggal,
+		TotalDebit:       total,
+		TotalKredit:      total,
+		Status:           "posted",
+		SumberTransaksi:  "manual",
+		JurnalDetail:     details,
+	}).Error)
+}
+
+// TestArusKasIncludesLastDay checks that journals posted during the last day
+// of the report, after midnight, are part of the cash flow and the closing
+// kas balance.
+func TestArusKasIncludesLastDay(t *testing.T) {
+	db := helpers.OpenTestPostgres(t)
+
+	kas := helpers.CreateAkun(t, db, 1, "1101", "aset", "debit")
+	pendapatan := helpers.CreateAkun(t, db, 1, "4101", "pendapatan", "kredit")
+
+	postJurnal(t, db, time.Date(2025, 5, 2, 9, 0, 0, 0, time.Local),
+		postgres.JurnalDetail{AkunID: kas.ID, Debit: money.FromInt(1000000)},
+		postgres.JurnalDetail{AkunID: pendapatan.ID, Kredit: money.FromInt(1000000)})
+	postJurnal(t, db, time.Date(2025, 5, 31, 16, 30, 0, 0, time.Local),
+		postgres.JurnalDetail{AkunID: kas.ID, Debit: money.FromInt(250000)},
+		postgres.JurnalDetail{AkunID: pendapatan.ID, Kredit: money.FromInt(250000)})
+	postJurnal(t, db, time.Date(2025, 6, 1, 0, 0, 0, 0, time.Local),
+		postgres.JurnalDetail{AkunID: kas.ID, Debit: money.FromInt(400000)},
+		postgres.JurnalDetail{AkunID: pendapatan.ID, Kredit: money.FromInt(400000)})
+
+	financialService := newFinancialService(db)
+	for _, metode := range []string{"langsung", "tidak_langsung"} {
+		arusKas, err := financialService.GetArusKas(1,
+			time.Date(2025, 5, 1, 0, 0, 0, 0, time.Local),
+			time.Date(2025, 5, 31, 0, 0, 0, 0, time.Local), metode)
+		require.NoError(t, err)
+
+		assert.Equal(t, money.FromInt(1250000), arusKas.KenaikanKas, metode)
+		assert.Equal(t, money.FromInt(1250000), arusKas.SaldoAkhirBuku, metode)
+		assert.True(t, arusKas.IsBalanced, metode)
+	}
+}
+
+// TestBukuBesarIncludesLastDay checks that the ledger keeps lines posted
+// during the last day of the range.
+func TestBukuBesarIncludesLastDay(t *testing.T) {
+	db := helpers.OpenTestPostgres(t)
+
+	kas := helpers.CreateAkun(t, db, 1, "1101", "aset", "debit")
+	pendapatan := helpers.CreateAkun(t, db, 1, "4101", "pendapatan", "kredit")
+
+	postJurnal(t, db, time.Date(2025, 5, 31, 16, 30, 0, 0, time.Local),
+		postgres.JurnalDetail{AkunID: kas.ID, Debit: money.FromInt(250000)},
+		postgres.JurnalDetail{AkunID: pendapatan.ID, Kredit: money.FromInt(250000)})
+	postJurnal(t, db, time.Date(2025, 6, 1, 0, 0, 0, 0, time.Local),
+		postgres.JurnalDetail{AkunID: kas.ID, Debit: money.FromInt(400000)},
+		postgres.JurnalDetail{AkunID: pendapatan.ID, Kredit: money.FromInt(400000)})
+
+	ledger, err := newFinancialService(db).GetBukuBesar(&services.BukuBesarRequest{
+		KoperasiID: 1,
+		AkunID:     kas.ID,
+		Dari:       time.Date(2025, 5, 1, 0, 0, 0, 0, time.Local),
+		Sampai:     time.Date(2025, 5, 31, 0, 0, 0, 0, time.Local),
+	})
+	require.NoError(t, err)
+	require.Len(t, ledger, 1)
+	assert.Len(t, ledger[0].Entries, 1)
+	assert.Equal(t, money.FromInt(250000), ledger[0].SaldoAkhir)
+}
+
+// TestKonsolidasiIncludesLastDay checks that the consolidated trial balance
+// and laba rugi take journals posted during the last day.
+func TestKonsolidasiIncludesLastDay(t *testing.T) {
+	db := helpers.OpenTestPostgres(t)
+
+	require.NoError(t, db.Create(&postgres.Koperasi{
+		ID:           1,
+		TenantID:     1,
+		NomorSK:      "SK-001",
+		NIK:          1,
+		NamaKoperasi: "Koperasi Desa",
+		NamaSK:       "Koperasi Desa",
+	}).Error)
+
+	kas := helpers.CreateAkun(t, db, 1, "1101", "aset", "debit")
+	pendapatan := helpers.CreateAkun(t, db, 1, "4101", "pendapatan", "kredit")
+
+	postJurnal(t, db, time.Date(2025, 5, 31, 16, 30, 0, 0, time.Local),
+		postgres.JurnalDetail{AkunID: kas.ID, Debit: money.FromInt(250000)},
+		postgres.JurnalDetail{AkunID: pendapatan.ID, Kredit: money.FromInt(250000)})
+
+	konsolidasiService := services.NewKonsolidasiService(
+		postgresRepo.NewKonsolidasiRepository(db),
+		postgresRepo.NewFinancialRepository(db),
+		postgresRepo.NewKoperasiRepository(db),
+	)
+	tanggal := time.Date(2025, 5, 31, 0, 0, 0, 0, time.Local)
+
+	neracaSaldo, err := konsolidasiService.GetNeracaSaldo(1, nil, tanggal)
+	require.NoError(t, err)
+	assert.Equal(t, money.FromInt(250000), neracaSaldo.TotalDebit)
+
+	labaRugi, err := konsolidasiService.GetLabaRugi(1, nil, time.Date(2025, 5, 1, 0, 0, 0, 0, time.Local), tanggal)
+	require.NoError(t, err)
+	assert.Equal(t, money.FromInt(250000), labaRugi.LabaRugi.Konsolidasi)
+}
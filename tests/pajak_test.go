@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+	"koperasi-merah-putih/internal/services"
+	"koperasi-merah-putih/tests/helpers"
+)
+
+func newPajakService(db *gorm.DB) *services.PajakService {
+	financialService := newFinancialService(db)
+	return services.NewPajakService(
+		postgresRepo.NewPajakRepository(db),
+		postgresRepo.NewProdukRepository(db),
+		postgresRepo.NewKoperasiRepository(db),
+		services.NewPostingService(postgresRepo.NewPostingRepository(db), postgresRepo.NewFinancialRepository(db), financialService),
+		services.NewSequenceService(postgresRepo.NewSequenceRepository(db)),
+	)
+}
+
+// TestRekapPajakCarriesLebihBayar checks that the PPN lebih bayar of a
+// recorded masa is compensated in the next one.
+func TestRekapPajakCarriesLebihBayar(t *testing.T) {
+	db := helpers.OpenTestPostgres(t)
+	require.NoError(t, db.Create(&postgres.PengaturanPajak{KoperasiID: 1, IsPKP: true, TarifPPN: 11}).Error)
+
+	require.NoError(t, db.Create(&postgres.PembelianHeader{
+		KoperasiID:    1,
+		SupplierID:    1,
+		NomorFaktur:   "INV-001",
+		TanggalFaktur: time.Date(2025, 5, 10, 0, 0, 0, 0, time.Local),
+		DPP:           money.FromInt(3000000),
+		TotalPajak:    money.FromInt(330000),
+	}).Error)
+	require.NoError(t, db.Create(&postgres.PenjualanHeader{
+		KoperasiID:       1,
+		NomorTransaksi:   "TRX-001",
+		TanggalTransaksi: time.Date(2025, 5, 20, 0, 0, 0, 0, time.Local),
+		DPP:              money.FromInt(1000000),
+		TotalPajak:       money.FromInt(110000),
+	}).Error)
+	require.NoError(t, db.Create(&postgres.PenjualanHeader{
+		KoperasiID:       1,
+		NomorTransaksi:   "TRX-002",
+		TanggalTransaksi: time.Date(2025, 6, 3, 0, 0, 0, 0, time.Local),
+		DPP:              money.FromInt(5000000),
+		TotalPajak:       money.FromInt(550000),
+	}).Error)
+
+	pajakService := newPajakService(db)
+
+	mei, err := pajakService.GetRekapPajak(1, "2025-05")
+	require.NoError(t, err)
+	assert.Equal(t, money.FromInt(220000), mei.PPNLebihBayar)
+	assert.Zero(t, mei.TotalSetor)
+
+	setoran, err := pajakService.SetorPajak(&services.SetorPajakRequest{
+		KoperasiID:   1,
+		MasaPajak:    "2025-05",
+		TanggalSetor: time.Date(2025, 6, 10, 0, 0, 0, 0, time.Local),
+	}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, money.FromInt(220000), setoran.PPNLebihBayar)
+
+	juni, err := pajakService.GetRekapPajak(1, "2025-06")
+	require.NoError(t, err)
+	assert.Equal(t, money.FromInt(220000), juni.PPNKompensasi)
+	assert.Equal(t, money.FromInt(330000), juni.PPNKurangBayar)
+	assert.Equal(t, money.FromInt(330000), juni.TotalSetor)
+}
+
+// TestRekapPajakNonPKP checks that a koperasi that is not PKP does not credit
+// the PPN its suppliers charge.
+func TestRekapPajakNonPKP(t *testing.T) {
+	db := helpers.OpenTestPostgres(t)
+
+	require.NoError(t, db.Create(&postgres.PembelianHeader{
+		KoperasiID:    1,
+		SupplierID:    1,
+		NomorFaktur:   "INV-001",
+		TanggalFaktur: time.Date(2025, 5, 10, 0, 0, 0, 0, time.Local),
+		DPP:           money.FromInt(3000000),
+		TotalPajak:    money.FromInt(330000),
+	}).Error)
+
+	rekap, err := newPajakService(db).GetRekapPajak(1, "2025-05")
+	require.NoError(t, err)
+	assert.Zero(t, rekap.PPNMasukan)
+	assert.Zero(t, rekap.PPNLebihBayar)
+}
+
+// TestExportEFakturKeluaranNeedsNomorFaktur checks that the e-Faktur
+// keluaran file is only built once every sale in it has an NSFP.
+func TestExportEFakturKeluaranNeedsNomorFaktur(t *testing.T) {
+	db := helpers.OpenTestPostgres(t)
+	require.NoError(t, db.Create(&postgres.PengaturanPajak{KoperasiID: 1, IsPKP: true, TarifPPN: 11}).Error)
+
+	anggota := postgres.AnggotaKoperasi{KoperasiID: 1, NIAK: "A-001", NIK: "3201010101010001", Nama: "Siti", JenisKelamin: "P"}
+	require.NoError(t, db.Create(&anggota).Error)
+	penjualan := postgres.PenjualanHeader{
+		KoperasiID:       1,
+		AnggotaID:        anggota.ID,
+		NomorTransaksi:   "TRX-001",
+		TanggalTransaksi: time.Date(2025, 5, 20, 0, 0, 0, 0, time.Local),
+		DPP:              money.FromInt(1000000),
+		TotalPajak:       money.FromInt(110000),
+	}
+	require.NoError(t, db.Create(&penjualan).Error)
+
+	pajakService := newPajakService(db)
+
+	_, err := pajakService.ExportPajak(1, "2025-05", services.ExportEFakturKeluaran)
+	assert.ErrorContains(t, err, "TRX-001")
+
+	_, err = pajakService.SetNomorFakturPajak(penjualan.ID, &services.NomorFakturPajakRequest{
+		KoperasiID:       1,
+		NomorFakturPajak: "010.000-25.00000001",
+	}, 1)
+	require.NoError(t, err)
+
+	rows, err := pajakService.ExportPajak(1, "2025-05", services.ExportEFakturKeluaran)
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+	assert.Equal(t, "0002500000001", rows[3][3])
+}
@@ -0,0 +1,168 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+	"koperasi-merah-putih/internal/services"
+	"koperasi-merah-putih/tests/helpers"
+)
+
+// TestTutupBukuOnMigratedSchema closes a fiscal year and checks that the
+// closing journal is left out of the year's laba rugi, and that journals can
+// no longer be written into the closed year, even for months without a
+// period record.
+func TestTutupBukuOnMigratedSchema(t *testing.T) {
+	db := helpers.OpenTestPostgres(t)
+	helpers.CreateKoperasi(t, db, 1)
+
+	kas := helpers.CreateAkun(t, db, 1, "1101", "aset", "debit")
+	shu := helpers.CreateAkun(t, db, 1, "3301", "ekuitas", "kredit")
+	pendapatan := helpers.CreateAkun(t, db, 1, "4101", "pendapatan", "kredit")
+	beban := helpers.CreateAkun(t, db, 1, "5101", "beban", "debit")
+
+	financialRepo := postgresRepo.NewFinancialRepository(db)
+	periodeRepo := postgresRepo.NewPeriodeRepository(db)
+	financialService := services.NewFinancialService(
+		financialRepo,
+		periodeRepo,
+		postgresRepo.NewAnggaranRepository(db),
+		postgresRepo.NewLampiranRepository(db),
+		services.NewSequenceService(postgresRepo.NewSequenceRepository(db)),
+	)
+	periodeService := services.NewPeriodeService(periodeRepo, financialRepo, financialService)
+
+	for bulan := 1; bulan <= 12; bulan++ {
+		mulai := time.Date(2025, time.Month(bulan), 1, 0, 0, 0, 0, time.Local)
+		require.NoError(t, periodeRepo.CreatePeriode(&postgres.PeriodeAkuntansi{
+			TenantID:       1,
+			KoperasiID:     1,
+			Tahun:          2025,
+			Bulan:          bulan,
+			TanggalMulai:   mulai,
+			TanggalSelesai: mulai.AddDate(0, 1, -1),
+			Status:         "closed",
+		}))
+	}
+
+	jurnal := func(tanggal time.Time, details ...postgres.JurnalDetail) {
+		var total money.Amount
+		for _, detail := range details {
+			total += detail.Debit
+		}
+		require.NoError(t, db.Create(&postgres.JurnalUmum{
+			TenantID:         1,
+			KoperasiID:       1,
+			NomorJurnal:      tanggal.Format("JU20060102150405"),
+			TanggalTransaksi: tanggal,
+			TotalDebit:       total,
+			TotalKredit:      total,
+			Status:           "posted",
+			SumberTransaksi:  "manual",
+			JurnalDetail:     details,
+		}).Error)
+	}
+
+	jurnal(time.Date(2025, 4, 2, 10, 0, 0, 0, time.Local),
+		postgres.JurnalDetail{AkunID: kas.ID, Debit: money.FromInt(5000000)},
+		postgres.JurnalDetail{AkunID: pendapatan.ID, Kredit: money.FromInt(5000000)})
+	jurnal(time.Date(2025, 9, 15, 10, 0, 0, 0, time.Local),
+		postgres.JurnalDetail{AkunID: beban.ID, Debit: money.FromInt(2000000)},
+		postgres.JurnalDetail{AkunID: kas.ID, Kredit: money.FromInt(2000000)})
+
+	tutupBuku, err := periodeService.TutupBuku(&services.TutupBukuRequest{KoperasiID: 1, Tahun: 2025, AkunSHUID: shu.ID}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, money.FromInt(3000000), tutupBuku.SHUTahunBerjalan)
+	assert.NotZero(t, tutupBuku.JurnalID)
+
+	labaRugi, err := financialRepo.GetLabaRugi(1,
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local),
+		time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local).Add(-time.Nanosecond))
+	require.NoError(t, err)
+	assert.Equal(t, money.FromInt(3000000), labaRugi.LabaRugi)
+
+	saldoAwal, err := periodeRepo.GetSaldoAwal(1, 2026)
+	require.NoError(t, err)
+	require.Len(t, saldoAwal, 2)
+
+	buatJurnal := func(tanggal time.Time) error {
+		_, err := financialService.CreateJurnalUmum(&services.CreateJurnalRequest{
+			TenantID:         1,
+			KoperasiID:       1,
+			TanggalTransaksi: tanggal,
+			Keterangan:       "Koreksi",
+			Details: []services.CreateJurnalDetailRequest{
+				{AkunID: beban.ID, Debit: money.FromInt(100000)},
+				{AkunID: kas.ID, Kredit: money.FromInt(100000)},
+			},
+			CreatedBy: 1,
+		})
+		return err
+	}
+
+	assert.Error(t, buatJurnal(time.Date(2025, 12, 31, 18, 0, 0, 0, time.Local)))
+	assert.Error(t, buatJurnal(time.Date(2024, 6, 1, 0, 0, 0, 0, time.Local)), "a month without a period record in a closed year")
+	assert.NoError(t, buatJurnal(time.Date(2026, 1, 5, 0, 0, 0, 0, time.Local)))
+}
+
+// TestReviewReopenOnce has two reviewers decide the same reopen request at
+// once: only one decision is recorded.
+func TestReviewReopenOnce(t *testing.T) {
+	db := helpers.OpenTestPostgres(t)
+	helpers.CreateKoperasi(t, db, 1)
+
+	financialRepo := postgresRepo.NewFinancialRepository(db)
+	periodeRepo := postgresRepo.NewPeriodeRepository(db)
+	periodeService := services.NewPeriodeService(periodeRepo, financialRepo, newFinancialService(db))
+
+	periode := &postgres.PeriodeAkuntansi{
+		TenantID:       1,
+		KoperasiID:     1,
+		Tahun:          2025,
+		Bulan:          3,
+		TanggalMulai:   time.Date(2025, 3, 1, 0, 0, 0, 0, time.Local),
+		TanggalSelesai: time.Date(2025, 3, 31, 0, 0, 0, 0, time.Local),
+		Status:         "closed",
+	}
+	require.NoError(t, periodeRepo.CreatePeriode(periode))
+
+	reopen, err := periodeService.RequestReopen(periode.ID, &services.ReopenPeriodeRequest{Alasan: "Koreksi biaya"}, 1)
+	require.NoError(t, err)
+
+	errs := make(chan error, 2)
+	var wg sync.WaitGroup
+	for reviewer, approve := range map[uint64]bool{2: true, 3: false} {
+		wg.Add(1)
+		go func(reviewer uint64, approve bool) {
+			defer wg.Done()
+			_, err := periodeService.ReviewReopen(reopen.ID, &services.ReviewReopenRequest{Approve: approve, Catatan: "ok"}, reviewer)
+			errs <- err
+		}(reviewer, approve)
+	}
+	wg.Wait()
+	close(errs)
+
+	berhasil := 0
+	for err := range errs {
+		if err == nil {
+			berhasil++
+		}
+	}
+	assert.Equal(t, 1, berhasil)
+
+	var audit int64
+	require.NoError(t, db.Model(&postgres.AuditLog{}).
+		Where("table_name = ? AND record_id = ?", "periode_akuntansis", periode.ID).
+		Count(&audit).Error)
+	assert.Equal(t, int64(1), audit)
+
+	_, err = periodeService.ReviewReopen(reopen.ID, &services.ReviewReopenRequest{Approve: true, Catatan: "lagi"}, 2)
+	assert.Error(t, err)
+}
@@ -0,0 +1,338 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+	"koperasi-merah-putih/internal/services"
+	"koperasi-merah-putih/tests/helpers"
+)
+
+// piutangFixture is a koperasi shop selling one product to two members, on
+// credit, for cash or against simpanan.
+type piutangFixture struct {
+	db          *gorm.DB
+	produk      *services.ProdukService
+	piutang     *services.PiutangService
+	barang      postgres.Produk
+	siti        postgres.AnggotaKoperasi
+	budi        postgres.AnggotaKoperasi
+	rekening    postgres.RekeningSimpanPinjam
+	kas         postgres.COAAkun
+	akunPiutang postgres.COAAkun
+	simpanan    postgres.COAAkun
+	pendapatan  postgres.COAAkun
+}
+
+func newPiutangFixture(t *testing.T) *piutangFixture {
+	t.Helper()
+	db := helpers.OpenTestPostgres(t)
+	helpers.CreateKoperasi(t, db, 1)
+
+	f := &piutangFixture{
+		db:          db,
+		kas:         helpers.CreateAkun(t, db, 1, "1101", "aset", "debit"),
+		akunPiutang: helpers.CreateAkun(t, db, 1, "1201", "aset", "debit"),
+		simpanan:    helpers.CreateAkun(t, db, 1, "2101", "kewajiban", "kredit"),
+		pendapatan:  helpers.CreateAkun(t, db, 1, "4101", "pendapatan", "kredit"),
+	}
+	helpers.CreatePostingRule(t, db, 1, services.PostingEventPenjualan,
+		postgres.PostingRuleLine{AkunID: f.kas.ID, Posisi: "debit", Komponen: "kas"},
+		postgres.PostingRuleLine{AkunID: f.akunPiutang.ID, Posisi: "debit", Komponen: "piutang"},
+		postgres.PostingRuleLine{AkunID: f.simpanan.ID, Posisi: "debit", Komponen: "simpanan"},
+		postgres.PostingRuleLine{AkunID: f.pendapatan.ID, Posisi: "kredit", Komponen: "total"})
+	helpers.CreatePostingRule(t, db, 1, services.PostingEventPelunasanPiutang,
+		postgres.PostingRuleLine{AkunID: f.kas.ID, Posisi: "debit", Komponen: "cash"},
+		postgres.PostingRuleLine{AkunID: f.simpanan.ID, Posisi: "debit", Komponen: "simpanan"},
+		postgres.PostingRuleLine{AkunID: f.akunPiutang.ID, Posisi: "kredit", Komponen: "total"})
+
+	f.siti = postgres.AnggotaKoperasi{KoperasiID: 1, NIAK: "A-001", Nama: "Siti", JenisKelamin: "P"}
+	require.NoError(t, db.Create(&f.siti).Error)
+	f.budi = postgres.AnggotaKoperasi{KoperasiID: 1, NIAK: "A-002", Nama: "Budi", JenisKelamin: "L"}
+	require.NoError(t, db.Create(&f.budi).Error)
+	f.rekening = createRekeningSimpanan(t, db, 1, f.siti.ID, money.FromInt(500000))
+
+	kategori := postgres.KategoriProduk{Kode: "SMB", Nama: "Sembako"}
+	require.NoError(t, db.Create(&kategori).Error)
+	satuan := postgres.SatuanProduk{Kode: "PCS", Nama: "Pcs"}
+	require.NoError(t, db.Create(&satuan).Error)
+	f.barang = postgres.Produk{
+		KoperasiID:       1,
+		KategoriProdukID: kategori.ID,
+		SatuanProdukID:   satuan.ID,
+		KodeProduk:       "BRS5",
+		Barcode:          "899000000001",
+		NamaProduk:       "Beras 5 kg",
+		HargaBeli:        money.FromInt(80000),
+		HargaJual:        money.FromInt(100000),
+		StokCurrent:      100,
+		IsActive:         true,
+	}
+	require.NoError(t, db.Create(&f.barang).Error)
+
+	sequenceService := services.NewSequenceService(postgresRepo.NewSequenceRepository(db))
+	postingService := services.NewPostingService(postgresRepo.NewPostingRepository(db), postgresRepo.NewFinancialRepository(db), newFinancialService(db))
+	pajakService := newPajakService(db)
+	simpanPinjamService := services.NewSimpanPinjamService(postgresRepo.NewSimpanPinjamRepository(db), postingService, pajakService, sequenceService)
+	f.produk = services.NewProdukService(
+		postgresRepo.NewProdukRepository(db),
+		postgresRepo.NewSequenceRepository(db),
+		postingService,
+		simpanPinjamService,
+		pajakService,
+	)
+	f.piutang = services.NewPiutangService(
+		postgresRepo.NewProdukRepository(db),
+		postgresRepo.NewAnggotaKoperasiRepository(db),
+		postingService,
+		simpanPinjamService,
+		sequenceService,
+	)
+	return f
+}
+
+// createRekeningSimpanan opens a simpanan sukarela account for anggotaID at
+// koperasiID with saldo in it.
+func createRekeningSimpanan(t *testing.T, db *gorm.DB, koperasiID, anggotaID uint64, saldo money.Amount) postgres.RekeningSimpanPinjam {
+	t.Helper()
+
+	produk := postgres.ProdukSimpanPinjam{KoperasiID: koperasiID, KodeProduk: fmt.Sprintf("SS%d", anggotaID), NamaProduk: "Simpanan Sukarela", Jenis: "simpanan", IsAktif: true}
+	require.NoError(t, db.Create(&produk).Error)
+	rekening := postgres.RekeningSimpanPinjam{
+		KoperasiID:    koperasiID,
+		AnggotaID:     anggotaID,
+		ProdukID:      produk.ID,
+		NomorRekening: fmt.Sprintf("SIM%03d%011d", koperasiID, anggotaID),
+		SaldoSimpanan: saldo,
+		Status:        "aktif",
+	}
+	require.NoError(t, db.Create(&rekening).Error)
+	return rekening
+}
+
+// jual sells qty of the product to anggotaID. A credit sale takes jumlahBayar
+// as the down payment.
+func (f *piutangFixture) jual(anggotaID uint64, metode string, qty int, jumlahBayar money.Amount, req services.CreatePenjualanRequest) (*postgres.PenjualanHeader, error) {
+	req.KoperasiID = 1
+	req.AnggotaID = anggotaID
+	req.MetodePembayaran = metode
+	req.JumlahBayar = jumlahBayar
+	req.Items = []services.PenjualanDetailRequest{{ProdukID: f.barang.ID, Qty: qty, HargaSatuan: money.FromInt(100000)}}
+	req.CreatedBy = 1
+	if req.TanggalTransaksi.IsZero() {
+		req.TanggalTransaksi = tgl(2025, 3, 1)
+	}
+	return f.produk.CreatePenjualan(&req)
+}
+
+func (f *piutangFixture) penjualan(t *testing.T, id uint64) postgres.PenjualanHeader {
+	t.Helper()
+	var penjualan postgres.PenjualanHeader
+	require.NoError(t, f.db.First(&penjualan, id).Error)
+	return penjualan
+}
+
+func (f *piutangFixture) saldoSimpanan(t *testing.T) money.Amount {
+	t.Helper()
+	var rekening postgres.RekeningSimpanPinjam
+	require.NoError(t, f.db.First(&rekening, f.rekening.ID).Error)
+	return rekening.SaldoSimpanan
+}
+
+// TestPenjualanKredit sells on credit with a down payment: the unpaid part
+// is debited to piutang and the sale falls due after TermPiutangDefault.
+func TestPenjualanKredit(t *testing.T) {
+	f := newPiutangFixture(t)
+
+	penjualan, err := f.jual(f.siti.ID, "credit", 3, money.FromInt(50000), services.CreatePenjualanRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, money.FromInt(300000), penjualan.GrandTotal)
+	assert.Equal(t, "partial", penjualan.StatusPembayaran)
+	assert.Equal(t, money.FromInt(50000), penjualan.TotalTerbayar)
+	require.NotNil(t, penjualan.TanggalJatuhTempo)
+	assert.True(t, penjualan.TanggalJatuhTempo.Equal(tgl(2025, 3, 31)))
+	assertJurnalLines(t, f.db, penjualan.JurnalID, map[uint64][2]money.Amount{
+		f.kas.ID:         {money.FromInt(50000), 0},
+		f.akunPiutang.ID: {money.FromInt(250000), 0},
+		f.pendapatan.ID:  {0, money.FromInt(300000)},
+	})
+
+	var details int64
+	require.NoError(t, f.db.Model(&postgres.PenjualanDetail{}).Where("penjualan_header_id = ?", penjualan.ID).Count(&details).Error)
+	assert.Equal(t, int64(1), details)
+
+	tanpaDP, err := f.jual(f.siti.ID, "credit", 1, 0, services.CreatePenjualanRequest{TanggalJatuhTempo: tglPtr(2025, 3, 15)})
+	require.NoError(t, err)
+	assert.Equal(t, "unpaid", tanpaDP.StatusPembayaran)
+	assert.True(t, tanpaDP.TanggalJatuhTempo.Equal(tgl(2025, 3, 15)))
+
+	_, err = f.jual(0, "credit", 1, 0, services.CreatePenjualanRequest{})
+	assert.Error(t, err, "a credit sale needs an anggota")
+	_, err = f.jual(f.siti.ID, "credit", 1, money.FromInt(150000), services.CreatePenjualanRequest{})
+	assert.Error(t, err, "the down payment cannot exceed the total")
+	_, err = f.jual(f.siti.ID, "credit", 1, 0, services.CreatePenjualanRequest{TanggalJatuhTempo: tglPtr(2025, 2, 1)})
+	assert.Error(t, err, "jatuh tempo before the sale")
+}
+
+// TestPenjualanSimpanan pays a sale from the member's own simpanan and
+// refuses another member's account or a balance that is too low.
+func TestPenjualanSimpanan(t *testing.T) {
+	f := newPiutangFixture(t)
+
+	penjualan, err := f.jual(f.siti.ID, "simpanan", 2, 0, services.CreatePenjualanRequest{RekeningSimpananID: f.rekening.ID})
+	require.NoError(t, err)
+	assert.Equal(t, "paid", penjualan.StatusPembayaran)
+	assert.Equal(t, money.FromInt(200000), penjualan.TotalTerbayar)
+	assert.Equal(t, money.FromInt(300000), f.saldoSimpanan(t))
+	assertJurnalLines(t, f.db, penjualan.JurnalID, map[uint64][2]money.Amount{
+		f.simpanan.ID:   {money.FromInt(200000), 0},
+		f.pendapatan.ID: {0, money.FromInt(200000)},
+	})
+
+	_, err = f.jual(f.budi.ID, "simpanan", 1, 0, services.CreatePenjualanRequest{RekeningSimpananID: f.rekening.ID})
+	assert.Error(t, err, "budi cannot pay from siti's simpanan")
+	_, err = f.jual(f.siti.ID, "simpanan", 1, 0, services.CreatePenjualanRequest{})
+	assert.Error(t, err, "a simpanan sale needs the rekening")
+
+	_, err = f.jual(f.siti.ID, "simpanan", 4, 0, services.CreatePenjualanRequest{RekeningSimpananID: f.rekening.ID})
+	assert.Error(t, err, "the simpanan balance is too low")
+	assert.Equal(t, money.FromInt(300000), f.saldoSimpanan(t))
+
+	var jumlah int64
+	require.NoError(t, f.db.Model(&postgres.PenjualanHeader{}).Count(&jumlah).Error)
+	assert.Equal(t, int64(1), jumlah, "the refused sale is rolled back")
+}
+
+// TestBayarPiutang collects from a member over their open credit sales by
+// due date, then against a chosen sale from simpanan, and refuses payments
+// that do not match what the member owes.
+func TestBayarPiutang(t *testing.T) {
+	f := newPiutangFixture(t)
+
+	lama, err := f.jual(f.siti.ID, "credit", 3, 0, services.CreatePenjualanRequest{TanggalJatuhTempo: tglPtr(2025, 4, 30)})
+	require.NoError(t, err)
+	baru, err := f.jual(f.siti.ID, "credit", 2, 0, services.CreatePenjualanRequest{TanggalTransaksi: tgl(2025, 3, 5)})
+	require.NoError(t, err)
+	milikBudi, err := f.jual(f.budi.ID, "credit", 1, 0, services.CreatePenjualanRequest{})
+	require.NoError(t, err)
+	tunai, err := f.jual(f.siti.ID, "cash", 1, money.FromInt(100000), services.CreatePenjualanRequest{})
+	require.NoError(t, err)
+
+	bayar := func(req services.BayarPiutangRequest) (*services.PembayaranPiutang, error) {
+		req.KoperasiID = 1
+		req.AnggotaID = f.siti.ID
+		req.TanggalBayar = tgl(2025, 3, 20)
+		if req.MetodePembayaran == "" {
+			req.MetodePembayaran = "cash"
+		}
+		return f.piutang.BayarPiutang(&req, 1)
+	}
+
+	hasil, err := bayar(services.BayarPiutangRequest{Jumlah: money.FromInt(250000)})
+	require.NoError(t, err)
+	require.Len(t, hasil.Pembayaran, 2)
+	assert.Equal(t, baru.ID, hasil.Pembayaran[0].PenjualanHeaderID, "the sale due on 4 April is paid first")
+	assert.Equal(t, money.FromInt(200000), hasil.Pembayaran[0].JumlahBayar)
+	assert.Equal(t, money.FromInt(50000), hasil.Pembayaran[1].JumlahBayar)
+	assert.Equal(t, "paid", f.penjualan(t, baru.ID).StatusPembayaran)
+	assert.Equal(t, "partial", f.penjualan(t, lama.ID).StatusPembayaran)
+	assertJurnalLines(t, f.db, hasil.JurnalID, map[uint64][2]money.Amount{
+		f.kas.ID:         {money.FromInt(250000), 0},
+		f.akunPiutang.ID: {0, money.FromInt(250000)},
+	})
+
+	hasil, err = bayar(services.BayarPiutangRequest{
+		MetodePembayaran:   "simpanan",
+		RekeningSimpananID: f.rekening.ID,
+		Alokasi:            []services.AlokasiBayarPiutang{{PenjualanHeaderID: lama.ID, Jumlah: money.FromInt(100000)}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, money.FromInt(400000), f.saldoSimpanan(t))
+	assert.Equal(t, money.FromInt(150000), f.penjualan(t, lama.ID).TotalTerbayar)
+	assertJurnalLines(t, f.db, hasil.JurnalID, map[uint64][2]money.Amount{
+		f.simpanan.ID:    {money.FromInt(100000), 0},
+		f.akunPiutang.ID: {0, money.FromInt(100000)},
+	})
+
+	for name, req := range map[string]services.BayarPiutangRequest{
+		"more than owed":       {Jumlah: money.FromInt(200000)},
+		"another member":       {Alokasi: []services.AlokasiBayarPiutang{{PenjualanHeaderID: milikBudi.ID, Jumlah: money.FromInt(10000)}}},
+		"not a credit sale":    {Alokasi: []services.AlokasiBayarPiutang{{PenjualanHeaderID: tunai.ID, Jumlah: money.FromInt(10000)}}},
+		"already paid":         {Alokasi: []services.AlokasiBayarPiutang{{PenjualanHeaderID: baru.ID, Jumlah: money.FromInt(10000)}}},
+		"above sisa":           {Alokasi: []services.AlokasiBayarPiutang{{PenjualanHeaderID: lama.ID, Jumlah: money.FromInt(160000)}}},
+		"simpanan no rekening": {MetodePembayaran: "simpanan", Jumlah: money.FromInt(10000)},
+		"listed twice": {Alokasi: []services.AlokasiBayarPiutang{
+			{PenjualanHeaderID: lama.ID, Jumlah: money.FromInt(10000)},
+			{PenjualanHeaderID: lama.ID, Jumlah: money.FromInt(10000)},
+		}},
+	} {
+		_, err := bayar(req)
+		assert.Error(t, err, name)
+	}
+	assert.Equal(t, money.FromInt(150000), f.penjualan(t, lama.ID).TotalTerbayar)
+
+	kartu, err := f.piutang.GetKartuPiutang(f.siti.ID)
+	require.NoError(t, err)
+	assert.Equal(t, money.FromInt(150000), kartu.Saldo)
+}
+
+// TestUmurPiutang ages the receivables on 15 April 2025: down payments and
+// collections up to that day reduce what is owed, later ones do not, and
+// cash sales are left out.
+func TestUmurPiutang(t *testing.T) {
+	f := newPiutangFixture(t)
+
+	maret, err := f.jual(f.siti.ID, "credit", 3, money.FromInt(100000), services.CreatePenjualanRequest{})
+	require.NoError(t, err)
+	_, err = f.jual(f.siti.ID, "credit", 1, 0, services.CreatePenjualanRequest{TanggalTransaksi: tgl(2025, 4, 10)})
+	require.NoError(t, err)
+	_, err = f.jual(f.budi.ID, "credit", 1, 0, services.CreatePenjualanRequest{TanggalTransaksi: tgl(2025, 1, 1)})
+	require.NoError(t, err)
+	_, err = f.jual(f.budi.ID, "credit", 1, 0, services.CreatePenjualanRequest{TanggalTransaksi: tgl(2025, 4, 16)})
+	require.NoError(t, err)
+	_, err = f.jual(f.budi.ID, "cash", 1, money.FromInt(100000), services.CreatePenjualanRequest{})
+	require.NoError(t, err)
+
+	_, err = f.piutang.BayarPiutang(&services.BayarPiutangRequest{
+		KoperasiID:       1,
+		AnggotaID:        f.siti.ID,
+		TanggalBayar:     tgl(2025, 4, 20),
+		MetodePembayaran: "cash",
+		Alokasi:          []services.AlokasiBayarPiutang{{PenjualanHeaderID: maret.ID, Jumlah: money.FromInt(200000)}},
+	}, 1)
+	require.NoError(t, err)
+
+	laporan, err := f.piutang.GetUmurPiutang(1, tgl(2025, 4, 15))
+	require.NoError(t, err)
+	require.Len(t, laporan.Anggota, 2)
+
+	for _, anggota := range laporan.Anggota {
+		switch anggota.AnggotaID {
+		case f.siti.ID:
+			require.Len(t, anggota.Penjualan, 2)
+			assert.Equal(t, money.FromInt(200000), anggota.Penjualan[0].Sisa)
+			assert.Equal(t, 15, anggota.Penjualan[0].HariLewat)
+			assert.Equal(t, services.Umur0Sampai30, anggota.Penjualan[0].Kelompok)
+			assert.Equal(t, services.UmurBelumJatuhTempo, anggota.Penjualan[1].Kelompok)
+			assert.Equal(t, money.FromInt(300000), anggota.Total)
+		case f.budi.ID:
+			require.Len(t, anggota.Penjualan, 1)
+			assert.Equal(t, 74, anggota.Penjualan[0].HariLewat)
+			assert.Equal(t, services.Umur61Sampai90, anggota.Penjualan[0].Kelompok)
+		default:
+			t.Errorf("unexpected anggota %d", anggota.AnggotaID)
+		}
+	}
+
+	assert.Equal(t, money.FromInt(100000), laporan.Total.BelumJatuhTempo)
+	assert.Equal(t, money.FromInt(200000), laporan.Total.Hari0Sampai30)
+	assert.Equal(t, money.FromInt(100000), laporan.Total.Hari61Sampai90)
+	assert.Equal(t, money.FromInt(400000), laporan.Total.Total)
+}
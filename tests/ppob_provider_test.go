@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"koperasi-merah-putih/internal/gateway"
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+)
+
+func TestDigiflazzAdapter(t *testing.T) {
+	var lastRequest map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastRequest = map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&lastRequest)
+
+		switch r.URL.Path {
+		case "/v1/cek-saldo":
+			w.Write([]byte(`{"data":{"deposit":150000}}`))
+		case "/v1/transaction":
+			if lastRequest["commands"] == "inq-pasca" {
+				w.Write([]byte(`{"data":{"ref_id":"PPOB0003","customer_name":"BUDI","status":"Sukses","price":151000,"selling_price":152500,"admin":2500,"desc":{"detail":[{"periode":"202608"},{"periode":"202609"}]}}}`))
+				return
+			}
+			status := "Sukses"
+			if lastRequest["customer_no"] == "0800" {
+				w.WriteHeader(http.StatusBadRequest)
+				status = "Gagal"
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"ref_id":  lastRequest["ref_id"],
+					"status":  status,
+					"message": "Transaksi " + status,
+					"sn":      "SN123",
+					"price":   10200,
+				},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	registry := gateway.NewPPOBAdapterRegistry(nil)
+	adapter, err := registry.Adapter(&postgres.PPOBProvider{
+		ID: 1, Kode: "DIGI", BaseURL: server.URL + "/", Username: "koperasi", APIKey: "rahasia", IsAktif: true,
+	})
+	assert.NoError(t, err)
+
+	resp, err := adapter.Topup(&gateway.PPOBRequest{RefID: "PPOB0001", KodeProduk: "TSEL10", NomorTujuan: "0812"})
+	assert.NoError(t, err)
+	assert.Equal(t, gateway.PPOBStatusSukses, resp.Status)
+	assert.Equal(t, "SN123", resp.NomorReferensi)
+	assert.Equal(t, money.FromInt(10200), resp.Harga)
+
+	sign := md5.Sum([]byte("koperasi" + "rahasia" + "PPOB0001"))
+	assert.Equal(t, hex.EncodeToString(sign[:]), lastRequest["sign"])
+	assert.Equal(t, "TSEL10", lastRequest["buyer_sku_code"])
+
+	resp, err = adapter.Topup(&gateway.PPOBRequest{RefID: "PPOB0002", KodeProduk: "TSEL10", NomorTujuan: "0800"})
+	assert.NoError(t, err)
+	assert.Equal(t, gateway.PPOBStatusGagal, resp.Status)
+
+	resp, err = adapter.Inquiry(&gateway.PPOBRequest{RefID: "PPOB0003", KodeProduk: "PLNPASCA", NomorTujuan: "5123", Pascabayar: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "BUDI", resp.NamaPelanggan)
+	assert.Equal(t, money.FromInt(150000), resp.Tagihan)
+	assert.Equal(t, money.FromInt(2500), resp.AdminProvider)
+	assert.Equal(t, money.FromInt(151000), resp.Harga)
+	assert.Equal(t, []string{"202608", "202609"}, resp.Periode)
+
+	_, err = adapter.Topup(&gateway.PPOBRequest{RefID: "PPOB0003", KodeProduk: "PLNPASCA", NomorTujuan: "5123", Pascabayar: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "pay-pasca", lastRequest["commands"])
+
+	saldo, err := adapter.CekSaldo()
+	assert.NoError(t, err)
+	assert.Equal(t, money.FromInt(150000), saldo)
+	assert.Equal(t, "deposit", lastRequest["cmd"])
+
+	_, err = registry.Adapter(&postgres.PPOBProvider{ID: 2, Kode: "X", Adapter: "unknown", IsAktif: true})
+	assert.Error(t, err)
+}
+
+func TestFakePPOBAdapter(t *testing.T) {
+	fake := gateway.NewFakePPOBAdapter(money.FromInt(25000))
+	fake.SetHarga("TSEL10", money.FromInt(10000))
+	fake.SetHasil("0813", gateway.PPOBStatusPending)
+	fake.SetHasil("0800", gateway.PPOBStatusGagal)
+
+	registry := gateway.NewPPOBAdapterRegistry(nil)
+	registry.Register(gateway.PPOBAdapterFake, fake.Factory())
+	adapter, err := registry.Adapter(&postgres.PPOBProvider{ID: 1, Kode: "FAKE", Adapter: gateway.PPOBAdapterFake, IsAktif: true})
+	assert.NoError(t, err)
+
+	resp, err := adapter.Topup(&gateway.PPOBRequest{RefID: "A", KodeProduk: "TSEL10", NomorTujuan: "0812"})
+	assert.NoError(t, err)
+	assert.Equal(t, gateway.PPOBStatusSukses, resp.Status)
+	assert.NotEmpty(t, resp.NomorReferensi)
+
+	// The same ref ID does not charge the deposit twice.
+	again, err := adapter.Topup(&gateway.PPOBRequest{RefID: "A", KodeProduk: "TSEL10", NomorTujuan: "0812"})
+	assert.NoError(t, err)
+	assert.Equal(t, resp.NomorReferensi, again.NomorReferensi)
+
+	resp, err = adapter.Topup(&gateway.PPOBRequest{RefID: "B", KodeProduk: "TSEL10", NomorTujuan: "0813"})
+	assert.NoError(t, err)
+	assert.Equal(t, gateway.PPOBStatusPending, resp.Status)
+
+	assert.NoError(t, fake.Selesaikan("B", gateway.PPOBStatusGagal))
+	resp, err = adapter.CekStatus(&gateway.PPOBRequest{RefID: "B"})
+	assert.NoError(t, err)
+	assert.Equal(t, gateway.PPOBStatusGagal, resp.Status)
+
+	resp, err = adapter.Topup(&gateway.PPOBRequest{RefID: "C", KodeProduk: "TSEL10", NomorTujuan: "0800"})
+	assert.NoError(t, err)
+	assert.Equal(t, gateway.PPOBStatusGagal, resp.Status)
+
+	// A postpaid payment can only use the ref_id of an inquiry.
+	fake.SetTagihan("5123", "BUDI", money.FromInt(4000), money.FromInt(1000), "202609")
+	resp, err = adapter.Topup(&gateway.PPOBRequest{RefID: "D", KodeProduk: "PLNPASCA", NomorTujuan: "5123", Pascabayar: true})
+	assert.NoError(t, err)
+	assert.Equal(t, gateway.PPOBStatusGagal, resp.Status)
+
+	resp, err = adapter.Inquiry(&gateway.PPOBRequest{RefID: "E", KodeProduk: "PLNPASCA", NomorTujuan: "5123", Pascabayar: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "BUDI", resp.NamaPelanggan)
+	assert.Equal(t, money.FromInt(4000), resp.Tagihan)
+
+	resp, err = adapter.Topup(&gateway.PPOBRequest{RefID: "E", KodeProduk: "PLNPASCA", NomorTujuan: "5123", Pascabayar: true})
+	assert.NoError(t, err)
+	assert.Equal(t, gateway.PPOBStatusSukses, resp.Status)
+
+	saldo, err := adapter.CekSaldo()
+	assert.NoError(t, err)
+	assert.Equal(t, money.FromInt(10000), saldo)
+
+	_, err = registry.Adapter(&postgres.PPOBProvider{ID: 1, Kode: "FAKE", Adapter: gateway.PPOBAdapterFake})
+	assert.Error(t, err)
+}
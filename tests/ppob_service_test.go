@@ -0,0 +1,448 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"koperasi-merah-putih/internal/gateway"
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+	"koperasi-merah-putih/internal/services"
+	"koperasi-merah-putih/tests/helpers"
+)
+
+// ppobFixture is a koperasi selling one prepaid product through the fake
+// provider, paid through a payment gateway method.
+type ppobFixture struct {
+	db       *gorm.DB
+	fake     *gateway.FakePPOBAdapter
+	refunder *fakeRefunder
+	service  *services.PPOBService
+	produk   postgres.PPOBProduk
+	provider postgres.PaymentProvider
+	method   postgres.PaymentMethod
+}
+
+// fakeRefunder stands in for the payment gateway's refund API.
+type fakeRefunder struct {
+	err   error
+	calls int
+}
+
+func (r *fakeRefunder) Refund(payment *postgres.PaymentTransaction, amount money.Amount, refundKey, alasan string) (string, error) {
+	r.calls++
+	if r.err != nil {
+		return "", r.err
+	}
+	return "RF-" + refundKey, nil
+}
+
+func newPPOBFixture(t *testing.T) *ppobFixture {
+	t.Helper()
+	db := helpers.OpenTestPostgres(t)
+	helpers.CreateKoperasi(t, db, 1)
+
+	kas := helpers.CreateAkun(t, db, 1, "1101", "aset", "debit")
+	simpanan := helpers.CreateAkun(t, db, 1, "2101", "kewajiban", "kredit")
+	pendapatan := helpers.CreateAkun(t, db, 1, "4101", "pendapatan", "kredit")
+	helpers.CreatePostingRule(t, db, 1, services.PostingEventPPOBPenjualan,
+		postgres.PostingRuleLine{AkunID: kas.ID, Posisi: "debit", Komponen: "kas"},
+		postgres.PostingRuleLine{AkunID: simpanan.ID, Posisi: "debit", Komponen: "simpanan"},
+		postgres.PostingRuleLine{AkunID: pendapatan.ID, Posisi: "kredit", Komponen: "harga_jual"},
+		postgres.PostingRuleLine{AkunID: pendapatan.ID, Posisi: "kredit", Komponen: "admin_fee"})
+
+	f := &ppobFixture{db: db, refunder: &fakeRefunder{}}
+	f.provider = postgres.PaymentProvider{Kode: "midtrans", Nama: "Midtrans", Jenis: "payment_gateway", FeeType: "fixed"}
+	require.NoError(t, db.Create(&f.provider).Error)
+	f.method = postgres.PaymentMethod{ProviderID: f.provider.ID, Kode: "va_bca", Nama: "VA BCA", Jenis: "virtual_account", IsActive: true}
+	require.NoError(t, db.Create(&f.method).Error)
+
+	ppobProvider := postgres.PPOBProvider{Kode: "FAKE", Nama: "Fake", Adapter: gateway.PPOBAdapterFake, IsAktif: true}
+	require.NoError(t, db.Create(&ppobProvider).Error)
+	kategori := postgres.PPOBKategori{Kode: "PULSA", Nama: "Pulsa", IsAktif: true}
+	require.NoError(t, db.Create(&kategori).Error)
+	f.produk = postgres.PPOBProduk{
+		ProviderID: ppobProvider.ID,
+		KategoriID: kategori.ID,
+		KodeProduk: "TSEL10",
+		NamaProduk: "Telkomsel 10.000",
+		HargaBeli:  money.FromInt(10200),
+		HargaJual:  money.FromInt(11000),
+		IsAktif:    true,
+	}
+	require.NoError(t, db.Create(&f.produk).Error)
+
+	f.fake = gateway.NewFakePPOBAdapter(money.FromInt(1000000))
+	f.fake.SetHarga("TSEL10", money.FromInt(10200))
+	registry := gateway.NewPPOBAdapterRegistry(nil)
+	registry.Register(gateway.PPOBAdapterFake, f.fake.Factory())
+
+	sequenceService := services.NewSequenceService(postgresRepo.NewSequenceRepository(db))
+	financialService := newFinancialService(db)
+	postingService := services.NewPostingService(postgresRepo.NewPostingRepository(db), postgresRepo.NewFinancialRepository(db), financialService)
+	f.service = services.NewPPOBService(
+		postgresRepo.NewPPOBRepository(db),
+		services.NewPaymentService(postgresRepo.NewPaymentRepository(db), postgresRepo.NewPaymentProviderRepository(db), sequenceService,
+			map[string]services.PaymentRefunder{f.provider.Kode: f.refunder}),
+		postingService,
+		financialService,
+		services.NewSimpanPinjamService(postgresRepo.NewSimpanPinjamRepository(db), postingService, newPajakService(db), sequenceService),
+		sequenceService,
+		registry,
+	)
+	return f
+}
+
+// beli orders the product for nomorTujuan through the payment gateway and
+// confirms the payment, as the gateway callback does.
+func (f *ppobFixture) beli(t *testing.T, nomorTujuan string) (*postgres.PPOBTransaksi, error) {
+	t.Helper()
+
+	transaksi, err := f.service.CreateTransaction(&services.PPOBTransactionRequest{
+		KoperasiID:        1,
+		ProdukID:          f.produk.ID,
+		NomorTujuan:       nomorTujuan,
+		PaymentProviderID: f.provider.ID,
+		PaymentMethodID:   f.method.ID,
+		CreatedBy:         1,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, transaksi.PaymentID)
+
+	require.NoError(t, f.db.Model(&postgres.PaymentTransaction{}).Where("id = ?", transaksi.PaymentID).
+		Update("status", "paid").Error)
+	return transaksi, f.service.ProcessPayment(transaksi.PaymentID)
+}
+
+func (f *ppobFixture) transaksi(t *testing.T, id uint64) postgres.PPOBTransaksi {
+	t.Helper()
+	var transaksi postgres.PPOBTransaksi
+	require.NoError(t, f.db.First(&transaksi, id).Error)
+	return transaksi
+}
+
+func (f *ppobFixture) jurnal(t *testing.T, id uint64) postgres.JurnalUmum {
+	t.Helper()
+	var jurnal postgres.JurnalUmum
+	require.NoError(t, f.db.Preload("JurnalDetail").First(&jurnal, id).Error)
+	return jurnal
+}
+
+// TestPPOBGatewayPurchasePostsJournal follows a gateway-paid purchase from
+// order to provider to the sales journal.
+func TestPPOBGatewayPurchasePostsJournal(t *testing.T) {
+	f := newPPOBFixture(t)
+
+	transaksi, err := f.beli(t, "081234567890")
+	require.NoError(t, err)
+
+	transaksi, err = f.service.CekStatusTransaksi(transaksi.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "success", transaksi.Status)
+	assert.Equal(t, "paid", transaksi.PaymentStatus)
+	require.NotZero(t, transaksi.JurnalID)
+
+	jurnal := f.jurnal(t, transaksi.JurnalID)
+	assert.Equal(t, money.FromInt(16000), jurnal.TotalDebit)
+	assert.Equal(t, "posted", jurnal.Status)
+	assert.Equal(t, services.SystemUserID, jurnal.CreatedBy)
+
+	saldo, err := f.fake.CekSaldo()
+	require.NoError(t, err)
+	assert.Equal(t, money.FromInt(989800), saldo)
+
+	assert.Error(t, f.service.ProcessPayment(transaksi.PaymentID), "a payment is only processed once")
+}
+
+// TestPPOBLostProviderResponse checks that an order whose response never
+// came back stays pending without a refund, and is settled by the status
+// check without charging the deposit twice.
+func TestPPOBLostProviderResponse(t *testing.T) {
+	f := newPPOBFixture(t)
+	f.fake.SetResponsHilang("081234567890")
+
+	transaksi, err := f.beli(t, "081234567890")
+	require.NoError(t, err)
+
+	pending := f.transaksi(t, transaksi.ID)
+	assert.Equal(t, "pending", pending.Status)
+	assert.Equal(t, "paid", pending.PaymentStatus)
+	assert.Zero(t, pending.JurnalID)
+
+	transaksi, err = f.service.CekStatusTransaksi(transaksi.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "success", transaksi.Status)
+	assert.Empty(t, transaksi.StatusRefund)
+	require.NotZero(t, transaksi.JurnalID)
+	assert.Equal(t, uint64(1), f.jurnal(t, transaksi.JurnalID).CreatedBy)
+
+	saldo, err := f.fake.CekSaldo()
+	require.NoError(t, err)
+	assert.Equal(t, money.FromInt(989800), saldo)
+}
+
+// TestPPOBInquiryTTLAndSingleUse checks that a postpaid bill is paid at the
+// inquired amount, that an inquiry pays one bill only, and that an expired
+// inquiry is refused.
+func TestPPOBInquiryTTLAndSingleUse(t *testing.T) {
+	f := newPPOBFixture(t)
+
+	pasca := postgres.PPOBProduk{
+		ProviderID:   f.produk.ProviderID,
+		KategoriID:   f.produk.KategoriID,
+		KodeProduk:   "PLNPASCA",
+		NamaProduk:   "PLN Pascabayar",
+		IsAktif:      true,
+		IsPascabayar: true,
+	}
+	require.NoError(t, f.db.Create(&pasca).Error)
+	f.fake.SetTagihan("512345678901", "BUDI", money.FromInt(150000), money.FromInt(2500), "202609")
+
+	inquiry := func() *postgres.PPOBInquiry {
+		inquiry, err := f.service.Inquiry(&services.PPOBInquiryRequest{KoperasiID: 1, ProdukID: pasca.ID, NomorTujuan: "512345678901"})
+		require.NoError(t, err)
+		return inquiry
+	}
+	bayar := func(nomorInquiry string) (*postgres.PPOBTransaksi, error) {
+		return f.service.CreateTransaction(&services.PPOBTransactionRequest{
+			KoperasiID:        1,
+			ProdukID:          pasca.ID,
+			NomorInquiry:      nomorInquiry,
+			PaymentProviderID: f.provider.ID,
+			PaymentMethodID:   f.method.ID,
+			CreatedBy:         1,
+		})
+	}
+
+	pertama := inquiry()
+	assert.Equal(t, "BUDI", pertama.NamaPelanggan)
+
+	transaksi, err := bayar(pertama.NomorInquiry)
+	require.NoError(t, err)
+	assert.Equal(t, pertama.NomorInquiry, transaksi.NomorTransaksi)
+	assert.Equal(t, money.FromInt(152500), transaksi.HargaJual)
+
+	_, err = bayar(pertama.NomorInquiry)
+	assert.Error(t, err, "an inquiry pays one bill only")
+
+	kedua := inquiry()
+	require.NoError(t, f.db.Model(&postgres.PPOBInquiry{}).Where("id = ?", kedua.ID).
+		Update("kedaluwarsa_pada", time.Now().Add(-time.Minute)).Error)
+	_, err = bayar(kedua.NomorInquiry)
+	assert.ErrorContains(t, err, "expired")
+}
+
+// TestPPOBSimpananDebitIsAtomic checks that paying from simpanan debits the
+// member's balance with the order, that an order the balance cannot cover
+// leaves nothing behind, and that a purchase the provider rejects is paid
+// back to the same rekening.
+func TestPPOBSimpananDebitIsAtomic(t *testing.T) {
+	f := newPPOBFixture(t)
+
+	produk := postgres.ProdukSimpanPinjam{KoperasiID: 1, KodeProduk: "SS", NamaProduk: "Simpanan Sukarela", Jenis: "simpanan", IsAktif: true}
+	require.NoError(t, f.db.Create(&produk).Error)
+	rekening := postgres.RekeningSimpanPinjam{
+		KoperasiID:    1,
+		AnggotaID:     1,
+		ProdukID:      produk.ID,
+		NomorRekening: "SIM000100000001",
+		SaldoSimpanan: money.FromInt(50000),
+		Status:        "aktif",
+	}
+	require.NoError(t, f.db.Create(&rekening).Error)
+
+	beli := func(nomorTujuan string) (*postgres.PPOBTransaksi, error) {
+		return f.service.CreateTransaction(&services.PPOBTransactionRequest{
+			KoperasiID:         1,
+			AnggotaID:          1,
+			ProdukID:           f.produk.ID,
+			NomorTujuan:        nomorTujuan,
+			MetodePembayaran:   services.PPOBBayarSimpanan,
+			RekeningSimpananID: rekening.ID,
+			CreatedBy:          1,
+		})
+	}
+	saldo := func() money.Amount {
+		var current postgres.RekeningSimpanPinjam
+		require.NoError(t, f.db.First(&current, rekening.ID).Error)
+		return current.SaldoSimpanan
+	}
+
+	transaksi, err := beli("081234567890")
+	require.NoError(t, err)
+	assert.Equal(t, "success", transaksi.Status)
+	assert.NotZero(t, transaksi.JurnalID)
+	assert.Equal(t, money.FromInt(34000), saldo())
+
+	f.fake.SetHasil("081200000000", gateway.PPOBStatusGagal)
+	_, err = beli("081200000000")
+	assert.Error(t, err)
+	assert.Equal(t, money.FromInt(34000), saldo(), "the rejected purchase is paid back")
+
+	var gagal postgres.PPOBTransaksi
+	require.NoError(t, f.db.Where("nomor_tujuan = ?", "081200000000").First(&gagal).Error)
+	assert.Equal(t, "failed", gagal.Status)
+	assert.Equal(t, postgres.PPOBRefundSukses, gagal.StatusRefund)
+	assert.Zero(t, gagal.JurnalID)
+
+	var sebelum int64
+	require.NoError(t, f.db.Model(&postgres.PPOBTransaksi{}).Count(&sebelum).Error)
+	require.NoError(t, f.db.Model(&postgres.RekeningSimpanPinjam{}).Where("id = ?", rekening.ID).
+		Update("saldo_simpanan", money.FromInt(10000)).Error)
+	_, err = beli("081234567890")
+	assert.ErrorContains(t, err, "insufficient balance")
+
+	var sesudah int64
+	require.NoError(t, f.db.Model(&postgres.PPOBTransaksi{}).Count(&sesudah).Error)
+	assert.Equal(t, sebelum, sesudah, "no order without its debit")
+	assert.Equal(t, money.FromInt(10000), saldo())
+}
+
+// TestPPOBSimpananOtherMember checks that a member cannot charge an order to
+// another member's simpanan, while an operator may order for any member.
+func TestPPOBSimpananOtherMember(t *testing.T) {
+	f := newPPOBFixture(t)
+	rekening := createRekeningSimpanan(t, f.db, 1, 2, money.FromInt(50000))
+
+	req := func(pembeli uint64) *services.PPOBTransactionRequest {
+		return &services.PPOBTransactionRequest{
+			KoperasiID:         1,
+			AnggotaID:          2,
+			ProdukID:           f.produk.ID,
+			NomorTujuan:        "081234567890",
+			MetodePembayaran:   services.PPOBBayarSimpanan,
+			RekeningSimpananID: rekening.ID,
+			PembeliAnggotaID:   pembeli,
+			CreatedBy:          1,
+		}
+	}
+
+	_, err := f.service.CreateTransaction(req(1))
+	assert.ErrorContains(t, err, "themselves")
+
+	// Leaving anggota_id out does not help: the order is the caller's own,
+	// and rekening 2 is not theirs.
+	own := req(1)
+	own.AnggotaID = 0
+	_, err = f.service.CreateTransaction(own)
+	assert.ErrorContains(t, err, "not an active account")
+
+	var rekeningSesudah postgres.RekeningSimpanPinjam
+	require.NoError(t, f.db.First(&rekeningSesudah, rekening.ID).Error)
+	assert.Equal(t, money.FromInt(50000), rekeningSesudah.SaldoSimpanan)
+
+	transaksi, err := f.service.CreateTransaction(req(0))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), transaksi.AnggotaID)
+}
+
+// TestPPOBSimpananOtherKoperasi checks that an order cannot be paid from a
+// rekening held at another koperasi.
+func TestPPOBSimpananOtherKoperasi(t *testing.T) {
+	f := newPPOBFixture(t)
+	rekening := createRekeningSimpanan(t, f.db, 2, 1, money.FromInt(50000))
+
+	_, err := f.service.CreateTransaction(&services.PPOBTransactionRequest{
+		KoperasiID:         1,
+		AnggotaID:          1,
+		ProdukID:           f.produk.ID,
+		NomorTujuan:        "081234567890",
+		MetodePembayaran:   services.PPOBBayarSimpanan,
+		RekeningSimpananID: rekening.ID,
+		CreatedBy:          1,
+	})
+	assert.ErrorContains(t, err, "another koperasi")
+
+	var count int64
+	require.NoError(t, f.db.Model(&postgres.PPOBTransaksi{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+// TestPPOBGatewayRefundStates follows the refund of a gateway payment the
+// provider rejected: a failed refund waits for the operator instead of
+// being retried by the scheduler, a retry completes it, and a completed
+// refund cannot be sent again.
+func TestPPOBGatewayRefundStates(t *testing.T) {
+	f := newPPOBFixture(t)
+	f.fake.SetHasil("081200000000", gateway.PPOBStatusGagal)
+	f.refunder.err = errors.New("gateway unavailable")
+
+	transaksi, err := f.beli(t, "081200000000")
+	assert.ErrorContains(t, err, "provider rejected")
+
+	gagal := f.transaksi(t, transaksi.ID)
+	assert.Equal(t, "failed", gagal.Status)
+	assert.Equal(t, postgres.PPOBRefundGagal, gagal.StatusRefund)
+	assert.Equal(t, money.FromInt(16000), gagal.JumlahRefund)
+	assert.Equal(t, 1, f.refunder.calls)
+
+	require.NoError(t, f.service.RetryRefunds(time.Now()))
+	assert.Equal(t, 1, f.refunder.calls, "the scheduler only sends pending refunds")
+
+	f.refunder.err = nil
+	selesai, err := f.service.RetryRefund(transaksi.ID)
+	require.NoError(t, err)
+	assert.Equal(t, postgres.PPOBRefundSukses, selesai.StatusRefund)
+	assert.Equal(t, "refunded", selesai.PaymentStatus)
+	assert.Equal(t, "RF-"+transaksi.NomorTransaksi+"-RF", selesai.ReferensiRefund)
+	assert.Equal(t, 2, f.refunder.calls)
+
+	_, err = f.service.RetryRefund(transaksi.ID)
+	assert.Error(t, err)
+	assert.Equal(t, 2, f.refunder.calls)
+
+	_, err = f.service.CekStatusTransaksi(transaksi.ID, 1)
+	assert.Error(t, err, "a refunded transaction is no longer paid")
+	assert.Equal(t, postgres.PPOBRefundSukses, f.transaksi(t, transaksi.ID).StatusRefund)
+}
+
+// TestRunAutoSettlementIsIdempotent runs the scheduled settlement twice for
+// the same day and checks that yesterday's sales are settled and posted
+// once.
+func TestRunAutoSettlementIsIdempotent(t *testing.T) {
+	f := newPPOBFixture(t)
+
+	kas := helpers.CreateAkun(t, f.db, 1, "1102", "aset", "debit")
+	pendapatan := helpers.CreateAkun(t, f.db, 1, "4102", "pendapatan", "kredit")
+	helpers.CreatePostingRule(t, f.db, 1, services.PostingEventPPOBSettlement,
+		postgres.PostingRuleLine{AkunID: kas.ID, Posisi: "debit", Komponen: "total"},
+		postgres.PostingRuleLine{AkunID: pendapatan.ID, Posisi: "kredit", Komponen: "admin_fee"})
+	require.NoError(t, f.db.Create(&postgres.PPOBPaymentConfig{
+		KoperasiID:            1,
+		AllowedPaymentMethods: "[]",
+		AutoSettlement:        true,
+		SettlementSchedule:    postgres.PPOBSettlementHarian,
+		PPOBAdminFee:          money.FromInt(5000),
+		PPOBAdminFeeType:      "fixed",
+		IsActive:              true,
+	}).Error)
+
+	now := time.Now()
+	kemarin := time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, now.Location()).AddDate(0, 0, -1)
+	transaksi, err := f.beli(t, "081234567890")
+	require.NoError(t, err)
+	require.NoError(t, f.db.Model(&postgres.PPOBTransaksi{}).Where("id = ?", transaksi.ID).
+		Update("tanggal_transaksi", kemarin).Error)
+
+	require.NoError(t, f.service.RunAutoSettlement(now))
+	require.NoError(t, f.service.RunAutoSettlement(now))
+
+	var settlements []postgres.PPOBSettlement
+	require.NoError(t, f.db.Find(&settlements).Error)
+	require.Len(t, settlements, 1)
+	assert.Equal(t, postgres.PPOBSettlementProcessed, settlements[0].Status)
+	assert.Equal(t, 1, settlements[0].JumlahTransaksi)
+	assert.Equal(t, money.FromInt(5000), settlements[0].TotalSettlement)
+	assert.NotZero(t, settlements[0].JurnalID)
+	assert.NotNil(t, f.transaksi(t, transaksi.ID).TanggalSettlement)
+
+	var jurnal int64
+	require.NoError(t, f.db.Model(&postgres.JurnalUmum{}).Where("sumber_transaksi = ?", "ppob_settlement").Count(&jurnal).Error)
+	assert.Equal(t, int64(1), jurnal)
+}
@@ -0,0 +1,195 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	"koperasi-merah-putih/internal/services"
+	"koperasi-merah-putih/tests/helpers"
+)
+
+// postRantai posts three journals of koperasi 1 through the service so they
+// are chained, and returns them in posting order.
+func postRantai(t *testing.T) (*gorm.DB, *services.FinancialService, []*postgres.JurnalUmum) {
+	t.Helper()
+	db := helpers.OpenTestPostgres(t)
+	helpers.CreateKoperasi(t, db, 1)
+
+	kas := helpers.CreateAkun(t, db, 1, "1101", "aset", "debit")
+	pendapatan := helpers.CreateAkun(t, db, 1, "4101", "pendapatan", "kredit")
+
+	financialService := newFinancialService(db)
+	var jurnals []*postgres.JurnalUmum
+	for i := int64(1); i <= 3; i++ {
+		jurnal, err := financialService.CreateJurnalUmum(&services.CreateJurnalRequest{
+			TenantID:         1,
+			KoperasiID:       1,
+			TanggalTransaksi: time.Date(2025, 5, int(i), 0, 0, 0, 0, time.Local),
+			Keterangan:       "Penjualan tunai",
+			Details: []services.CreateJurnalDetailRequest{
+				{AkunID: kas.ID, Debit: money.FromInt(100000 * i)},
+				{AkunID: pendapatan.ID, Kredit: money.FromInt(100000 * i)},
+			},
+			CreatedBy: 1,
+		})
+		require.NoError(t, err)
+		_, err = financialService.PostJurnal(jurnal.ID, 1)
+		require.NoError(t, err)
+		jurnals = append(jurnals, jurnal)
+	}
+	return db, financialService, jurnals
+}
+
+func TestRantaiJurnalUtuh(t *testing.T) {
+	_, financialService, _ := postRantai(t)
+
+	hasil, err := financialService.VerifikasiRantaiJurnal(1, nil)
+	require.NoError(t, err)
+	assert.True(t, hasil.Utuh)
+	assert.Nil(t, hasil.Putus)
+	assert.Equal(t, 3, hasil.JumlahDiperiksa)
+	assert.Equal(t, uint64(3), hasil.UrutanTerakhir)
+	assert.Len(t, hasil.HashTerakhir, 64)
+	assert.Zero(t, hasil.JurnalTanpaRantai)
+
+	anchor := &services.AnchorRantaiJurnal{Urutan: hasil.UrutanTerakhir, Hash: hasil.HashTerakhir}
+	hasil, err = financialService.VerifikasiRantaiJurnal(1, anchor)
+	require.NoError(t, err)
+	assert.True(t, hasil.Utuh)
+}
+
+func TestRantaiJurnalEditedLine(t *testing.T) {
+	db, financialService, jurnals := postRantai(t)
+
+	require.NoError(t, db.Model(&postgres.JurnalDetail{}).
+		Where("jurnal_id = ?", jurnals[1].ID).
+		Updates(map[string]interface{}{"keterangan": "diubah"}).Error)
+
+	hasil, err := financialService.VerifikasiRantaiJurnal(1, nil)
+	require.NoError(t, err)
+	assert.False(t, hasil.Utuh)
+	require.NotNil(t, hasil.Putus)
+	assert.Equal(t, uint64(2), hasil.Putus.Urutan)
+	assert.Equal(t, jurnals[1].NomorJurnal, hasil.Putus.NomorJurnal)
+	assert.Contains(t, hasil.Putus.Alasan, "changed")
+	assert.Equal(t, 1, hasil.JumlahDiperiksa)
+}
+
+func TestRantaiJurnalDeletedJurnal(t *testing.T) {
+	db, financialService, jurnals := postRantai(t)
+
+	require.NoError(t, db.Where("jurnal_id = ?", jurnals[1].ID).Delete(&postgres.JurnalDetail{}).Error)
+	require.NoError(t, db.Delete(&postgres.JurnalUmum{}, jurnals[1].ID).Error)
+
+	hasil, err := financialService.VerifikasiRantaiJurnal(1, nil)
+	require.NoError(t, err)
+	assert.False(t, hasil.Utuh)
+	require.NotNil(t, hasil.Putus)
+	assert.Equal(t, uint64(2), hasil.Putus.Urutan)
+	assert.Equal(t, jurnals[1].ID, hasil.Putus.JurnalID)
+	assert.Contains(t, hasil.Putus.Alasan, "deleted")
+}
+
+func TestRantaiJurnalMissingLink(t *testing.T) {
+	db, financialService, jurnals := postRantai(t)
+
+	require.NoError(t, db.Where("koperasi_id = ? AND urutan = ?", 1, 2).Delete(&postgres.RantaiJurnal{}).Error)
+
+	hasil, err := financialService.VerifikasiRantaiJurnal(1, nil)
+	require.NoError(t, err)
+	assert.False(t, hasil.Utuh)
+	require.NotNil(t, hasil.Putus)
+	assert.Equal(t, uint64(2), hasil.Putus.Urutan)
+	assert.Contains(t, hasil.Putus.Alasan, "missing")
+	assert.Equal(t, int64(1), hasil.JurnalTanpaRantai, "jurnal %s lost its link", jurnals[1].NomorJurnal)
+}
+
+// TestRantaiJurnalAnchor checks that removing the last journal together with
+// its link passes on the chain alone, and is caught against an anchor taken
+// before.
+func TestRantaiJurnalAnchor(t *testing.T) {
+	db, financialService, jurnals := postRantai(t)
+
+	sebelum, err := financialService.VerifikasiRantaiJurnal(1, nil)
+	require.NoError(t, err)
+	anchor := &services.AnchorRantaiJurnal{Urutan: sebelum.UrutanTerakhir, Hash: sebelum.HashTerakhir}
+
+	require.NoError(t, db.Where("koperasi_id = ? AND urutan = ?", 1, 3).Delete(&postgres.RantaiJurnal{}).Error)
+	require.NoError(t, db.Where("jurnal_id = ?", jurnals[2].ID).Delete(&postgres.JurnalDetail{}).Error)
+	require.NoError(t, db.Delete(&postgres.JurnalUmum{}, jurnals[2].ID).Error)
+
+	hasil, err := financialService.VerifikasiRantaiJurnal(1, nil)
+	require.NoError(t, err)
+	assert.True(t, hasil.Utuh)
+
+	hasil, err = financialService.VerifikasiRantaiJurnal(1, anchor)
+	require.NoError(t, err)
+	assert.False(t, hasil.Utuh)
+	require.NotNil(t, hasil.Putus)
+	assert.Equal(t, uint64(3), hasil.Putus.Urutan)
+
+	anchor.Urutan = 2
+	hasil, err = financialService.VerifikasiRantaiJurnal(1, anchor)
+	require.NoError(t, err)
+	assert.False(t, hasil.Utuh)
+	assert.Contains(t, hasil.Putus.Alasan, "anchor")
+}
+
+// TestPostJurnalConcurrent posts the same draft from several requests at once:
+// exactly one goes through and the journal is chained once.
+func TestPostJurnalConcurrent(t *testing.T) {
+	db, financialService, _ := postRantai(t)
+
+	kas := helpers.CreateAkun(t, db, 1, "1102", "aset", "debit")
+	modal := helpers.CreateAkun(t, db, 1, "3101", "ekuitas", "kredit")
+	jurnal, err := financialService.CreateJurnalUmum(&services.CreateJurnalRequest{
+		TenantID:         1,
+		KoperasiID:       1,
+		TanggalTransaksi: time.Date(2025, 5, 10, 0, 0, 0, 0, time.Local),
+		Keterangan:       "Setoran modal",
+		Details: []services.CreateJurnalDetailRequest{
+			{AkunID: kas.ID, Debit: money.FromInt(50000)},
+			{AkunID: modal.ID, Kredit: money.FromInt(50000)},
+		},
+		CreatedBy: 1,
+	})
+	require.NoError(t, err)
+
+	const n = 4
+	errs := make(chan error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := financialService.PostJurnal(jurnal.ID, 1)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	berhasil := 0
+	for err := range errs {
+		if err == nil {
+			berhasil++
+		}
+	}
+	assert.Equal(t, 1, berhasil)
+
+	var links int64
+	require.NoError(t, db.Model(&postgres.RantaiJurnal{}).Where("jurnal_id = ?", jurnal.ID).Count(&links).Error)
+	assert.Equal(t, int64(1), links)
+
+	hasil, err := financialService.VerifikasiRantaiJurnal(1, nil)
+	require.NoError(t, err)
+	assert.True(t, hasil.Utuh)
+	assert.Equal(t, uint64(4), hasil.UrutanTerakhir)
+}
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+
+	"koperasi-merah-putih/internal/database"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+)
+
+var tabelDalamQuery = regexp.MustCompile(`(?i)\b(?:FROM|JOIN)\s+\(?\s*"?([a-z_][a-z0-9_]*)"?`)
+
+// TestReportQueriesUseMigratedTables records the SQL of the hand-written
+// report queries and checks every table they read is one GORM migrates.
+func TestReportQueriesUseMigratedTables(t *testing.T) {
+	tabel := map[string]bool{}
+	cache := &sync.Map{}
+	for _, model := range database.Models() {
+		parsed, err := schema.Parse(model, cache, schema.NamingStrategy{})
+		require.NoError(t, err)
+		tabel[parsed.Table] = true
+	}
+
+	var queries []string
+	sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherFunc(func(_, actual string) error {
+		queries = append(queries, actual)
+		return nil
+	})))
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+
+	repo := postgresRepo.NewFinancialRepository(db)
+	shuRepo := postgresRepo.NewSHURepository(db)
+	now := time.Now()
+	calls := []func(){
+		func() { repo.GetSaldoAkun(1, now) },
+		func() { repo.GetNeracaSaldo(1, now) },
+		func() { repo.GetLabaRugi(1, now, now) },
+		func() { repo.GetNeraca(1, now) },
+		func() { repo.GetMutasiAkun(1, time.Time{}, now) },
+		func() { repo.GetMutasiHasilUsaha(1, now, now) },
+		func() { repo.GetSaldoKas(1, now) },
+		func() { repo.GetMutasiNonKas(1, now, now, true) },
+		func() { shuRepo.GetSaldoSimpananHistory(1, now) },
+		func() { shuRepo.GetTransaksiUsahaAnggota(1, now, now) },
+	}
+	for _, call := range calls {
+		mock.ExpectQuery("").WillReturnRows(sqlmock.NewRows(nil))
+		call()
+	}
+
+	require.Len(t, queries, len(calls))
+	for _, query := range queries {
+		for _, match := range tabelDalamQuery.FindAllStringSubmatch(query, -1) {
+			assert.True(t, tabel[match[1]], "table %q is not migrated, query: %s", match[1], query)
+		}
+	}
+}
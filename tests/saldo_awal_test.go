@@ -0,0 +1,354 @@
+package tests
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+	"koperasi-merah-putih/internal/services"
+	"koperasi-merah-putih/tests/helpers"
+)
+
+// saldoAwalFixture is a koperasi moving onto the platform at the end of
+// 2024 with member savings and a loan, a car in its asset register, one
+// unpaid supplier invoice and one member credit sale still open.
+type saldoAwalFixture struct {
+	db        *gorm.DB
+	service   *services.SaldoAwalService
+	financial *services.FinancialService
+	akun      map[string]postgres.COAAkun
+	siti      postgres.AnggotaKoperasi
+	budi      postgres.AnggotaKoperasi
+	supplier  postgres.Supplier
+}
+
+func newSaldoAwalFixture(t *testing.T) *saldoAwalFixture {
+	t.Helper()
+	db := helpers.OpenTestPostgres(t)
+	helpers.CreateKoperasi(t, db, 1)
+
+	f := &saldoAwalFixture{db: db, akun: make(map[string]postgres.COAAkun)}
+	for _, a := range []struct{ kode, tipe, saldoNormal string }{
+		{"1101", "aset", "debit"},
+		{"1201", "aset", "debit"},
+		{"1301", "aset", "debit"},
+		{"1302", "aset", "kredit"},
+		{"1401", "aset", "debit"},
+		{"1501", "aset", "debit"},
+		{"2101", "kewajiban", "kredit"},
+		{"2201", "kewajiban", "kredit"},
+		{"3101", "ekuitas", "kredit"},
+		{"4101", "pendapatan", "kredit"},
+		{"4901", "pendapatan", "kredit"},
+		{"5201", "beban", "debit"},
+	} {
+		f.akun[a.kode] = helpers.CreateAkun(t, db, 1, a.kode, a.tipe, a.saldoNormal)
+	}
+
+	helpers.CreatePostingRule(t, db, 1, services.PostingEventSimpananSetoran,
+		postgres.PostingRuleLine{AkunID: f.akun["1101"].ID, Posisi: "debit", Komponen: "jumlah"},
+		postgres.PostingRuleLine{AkunID: f.akun["2101"].ID, Posisi: "kredit", Komponen: "jumlah"})
+	helpers.CreatePostingRule(t, db, 1, services.PostingEventPinjamanPencairan,
+		postgres.PostingRuleLine{AkunID: f.akun["1401"].ID, Posisi: "debit", Komponen: "jumlah"},
+		postgres.PostingRuleLine{AkunID: f.akun["1101"].ID, Posisi: "kredit", Komponen: "jumlah"})
+	helpers.CreatePostingRule(t, db, 1, services.PostingEventPembelian,
+		postgres.PostingRuleLine{AkunID: f.akun["1501"].ID, Posisi: "debit", Komponen: "subtotal"},
+		postgres.PostingRuleLine{AkunID: f.akun["2201"].ID, Posisi: "kredit", Komponen: "total"})
+	helpers.CreatePostingRule(t, db, 1, services.PostingEventPenjualan,
+		postgres.PostingRuleLine{AkunID: f.akun["1101"].ID, Posisi: "debit", Komponen: "kas"},
+		postgres.PostingRuleLine{AkunID: f.akun["1201"].ID, Posisi: "debit", Komponen: "piutang"},
+		postgres.PostingRuleLine{AkunID: f.akun["4101"].ID, Posisi: "kredit", Komponen: "total"})
+
+	f.siti = postgres.AnggotaKoperasi{KoperasiID: 1, NIAK: "A-001", Nama: "Siti", JenisKelamin: "P"}
+	require.NoError(t, db.Create(&f.siti).Error)
+	f.budi = postgres.AnggotaKoperasi{KoperasiID: 1, NIAK: "A-002", Nama: "Budi", JenisKelamin: "L"}
+	require.NoError(t, db.Create(&f.budi).Error)
+	f.supplier = postgres.Supplier{KoperasiID: 1, Kode: "SUP1", Nama: "CV Sumber Makmur"}
+	require.NoError(t, db.Create(&f.supplier).Error)
+	for _, produk := range []postgres.ProdukSimpanPinjam{
+		{KoperasiID: 1, KodeProduk: "SP", NamaProduk: "Simpanan Pokok", Jenis: "simpanan", IsAktif: true},
+		{KoperasiID: 1, KodeProduk: "PJ", NamaProduk: "Pinjaman Umum", Jenis: "pinjaman", BungaPinjaman: 12, IsAktif: true},
+	} {
+		require.NoError(t, db.Create(&produk).Error)
+	}
+
+	f.financial = newFinancialService(db)
+	sequenceService := services.NewSequenceService(postgresRepo.NewSequenceRepository(db))
+	postingService := services.NewPostingService(postgresRepo.NewPostingRepository(db), postgresRepo.NewFinancialRepository(db), f.financial)
+	simpanPinjamService := services.NewSimpanPinjamService(postgresRepo.NewSimpanPinjamRepository(db), postingService, newPajakService(db), sequenceService)
+	f.service = services.NewSaldoAwalService(
+		postgresRepo.NewSaldoAwalRepository(db),
+		postgresRepo.NewFinancialRepository(db),
+		postgresRepo.NewPeriodeRepository(db),
+		postgresRepo.NewPostingRepository(db),
+		postgresRepo.NewSimpanPinjamRepository(db),
+		postgresRepo.NewAnggotaKoperasiRepository(db),
+		postgresRepo.NewKoperasiRepository(db),
+		postgresRepo.NewAsetRepository(db),
+		postgresRepo.NewProdukRepository(db),
+		f.financial,
+		simpanPinjamService,
+	)
+
+	// The car was bought before the cutover and is depreciated through it;
+	// the motorbike comes after and is not part of the opening balance.
+	asetService := services.NewAsetService(
+		postgresRepo.NewAsetRepository(db),
+		postgresRepo.NewFinancialRepository(db),
+		postgresRepo.NewProdukRepository(db),
+		f.financial,
+		sequenceService,
+	)
+	kategori, err := asetService.CreateKategori(&services.CreateKategoriAsetRequest{
+		TenantID:            1,
+		KoperasiID:          1,
+		Kode:                "KND",
+		Nama:                "Kendaraan",
+		UmurEkonomis:        48,
+		MetodePenyusutan:    services.MetodeGarisLurus,
+		AkunAsetID:          f.akun["1301"].ID,
+		AkunAkumulasiID:     f.akun["1302"].ID,
+		AkunBebanID:         f.akun["5201"].ID,
+		AkunLabaRugiLepasID: f.akun["4901"].ID,
+	})
+	require.NoError(t, err)
+	for _, req := range []services.CreateAsetRequest{
+		{Nama: "Mobil", TanggalPerolehan: tgl(2023, 7, 1), HargaPerolehan: money.FromInt(24000000),
+			AkumulasiAwal: money.FromInt(9000000), PenyusutanSampai: tglPtr(2024, 12, 31)},
+		{Nama: "Motor", TanggalPerolehan: tgl(2025, 1, 10), HargaPerolehan: money.FromInt(6000000)},
+	} {
+		req.TenantID, req.KoperasiID, req.KategoriAsetID = 1, 1, kategori.ID
+		_, err := asetService.CreateAset(&req, 1)
+		require.NoError(t, err)
+	}
+
+	_, err = f.service.CreateMigrasi(&services.CreateSaldoAwalRequest{KoperasiID: 1, TanggalCutover: tgl(2024, 12, 31)}, 1)
+	require.NoError(t, err)
+	return f
+}
+
+// neracaAwal is the trial balance matching the fixture's sub-ledgers, per
+// kode akun, debit positive and kredit negative.
+func neracaAwal() map[string]int64 {
+	return map[string]int64{
+		"1101": 10000000,
+		"1201": 700000,
+		"1301": 24000000,
+		"1302": -9000000,
+		"1401": 6000000,
+		"2101": -4000000,
+		"2201": -2500000,
+		"3101": -25200000,
+	}
+}
+
+func (f *saldoAwalFixture) importNeraca(t *testing.T, saldo map[string]int64) {
+	t.Helper()
+	kodes := make([]string, 0, len(saldo))
+	for kode := range saldo {
+		kodes = append(kodes, kode)
+	}
+	sort.Strings(kodes)
+
+	csv := "kode_akun,debit,kredit\n"
+	for _, kode := range kodes {
+		if saldo[kode] >= 0 {
+			csv += fmt.Sprintf("%s,%d,0\n", kode, saldo[kode])
+		} else {
+			csv += fmt.Sprintf("%s,0,%d\n", kode, -saldo[kode])
+		}
+	}
+	_, err := f.service.ImportNeraca(1, "csv", strings.NewReader(csv))
+	require.NoError(t, err)
+}
+
+func (f *saldoAwalFixture) importSemua(t *testing.T) {
+	t.Helper()
+	f.importNeraca(t, neracaAwal())
+
+	_, err := f.service.ImportRekening(1, "csv", strings.NewReader(
+		"niak,kode_produk,saldo,sisa_angsuran\n"+
+			"A-001,SP,3000000,\n"+
+			"A-002,SP,1000000,\n"+
+			"A-002,PJ,6000000,12\n"))
+	require.NoError(t, err)
+
+	_, err = f.service.ImportFaktur(1, "csv", strings.NewReader(
+		"jenis,kode_supplier,niak,nomor_faktur,tanggal_faktur,tanggal_jatuh_tempo,sisa\n"+
+			"hutang,SUP1,,INV-0912,2024-12-10,2025-01-09,2500000\n"+
+			"piutang,,A-001,PJL-0930,2024-11-30,,700000\n"))
+	require.NoError(t, err)
+}
+
+func kontrolByKode(hasil *services.RekonsiliasiSaldoAwal) map[string]services.KontrolSaldoAwal {
+	kontrol := make(map[string]services.KontrolSaldoAwal)
+	for _, k := range hasil.Kontrol {
+		kontrol[k.KodeAkun] = k
+	}
+	return kontrol
+}
+
+// TestRekonsiliasiSaldoAwal reconciles every sub-ledger with its control
+// account, then moves 100,000 of each control account to kas and expects
+// exactly that account to be reported off by 100,000.
+func TestRekonsiliasiSaldoAwal(t *testing.T) {
+	f := newSaldoAwalFixture(t)
+	f.importSemua(t)
+
+	hasil, err := f.service.Rekonsiliasi(1)
+	require.NoError(t, err)
+	assert.Empty(t, hasil.Errors)
+	assert.True(t, hasil.Seimbang)
+	assert.True(t, hasil.SiapPosting)
+
+	want := map[string]struct {
+		sumber string
+		saldo  money.Amount
+		jumlah int
+		normal string
+	}{
+		"2101": {"SP", money.FromInt(4000000), 2, "kredit"},
+		"1401": {"PJ", money.FromInt(6000000), 1, "debit"},
+		"1301": {"KND", money.FromInt(24000000), 1, "debit"},
+		"1302": {"KND", money.FromInt(9000000), 1, "kredit"},
+		"2201": {"hutang", money.FromInt(2500000), 1, "kredit"},
+		"1201": {"piutang", money.FromInt(700000), 1, "debit"},
+	}
+	kontrol := kontrolByKode(hasil)
+	require.Len(t, kontrol, len(want))
+	for kode, w := range want {
+		k := kontrol[kode]
+		assert.Contains(t, k.Sumber, w.sumber, kode)
+		assert.Equal(t, w.saldo, k.SaldoRincian, kode)
+		assert.Equal(t, w.saldo, k.SaldoNeraca, kode)
+		assert.Equal(t, w.jumlah, k.JumlahRincian, kode)
+		assert.Zero(t, k.Selisih, kode)
+	}
+
+	for kode, w := range want {
+		t.Run(kode, func(t *testing.T) {
+			saldo := neracaAwal()
+			saldo[kode] += 100000
+			saldo["1101"] -= 100000
+			f.importNeraca(t, saldo)
+
+			hasil, err := f.service.Rekonsiliasi(1)
+			require.NoError(t, err)
+			assert.True(t, hasil.Seimbang)
+			assert.False(t, hasil.SiapPosting)
+			require.Len(t, hasil.Errors, 1)
+			assert.Contains(t, hasil.Errors[0], "akun "+kode+":")
+
+			selisih := money.FromInt(100000)
+			if w.normal == "kredit" {
+				selisih = -selisih
+			}
+			assert.Equal(t, selisih, kontrolByKode(hasil)[kode].Selisih)
+		})
+	}
+}
+
+// TestPostSaldoAwal posts the reconciled wizard: one opening journal, the
+// member rekening, and the open faktur as unpaid pembelian and penjualan.
+func TestPostSaldoAwal(t *testing.T) {
+	f := newSaldoAwalFixture(t)
+	f.importSemua(t)
+
+	migrasi, err := f.service.PostMigrasi(1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "posted", migrasi.Status)
+	assertJurnalLines(t, f.db, migrasi.JurnalID, map[uint64][2]money.Amount{
+		f.akun["1101"].ID: {money.FromInt(10000000), 0},
+		f.akun["1201"].ID: {money.FromInt(700000), 0},
+		f.akun["1301"].ID: {money.FromInt(24000000), 0},
+		f.akun["1302"].ID: {0, money.FromInt(9000000)},
+		f.akun["1401"].ID: {money.FromInt(6000000), 0},
+		f.akun["2101"].ID: {0, money.FromInt(4000000)},
+		f.akun["2201"].ID: {0, money.FromInt(2500000)},
+		f.akun["3101"].ID: {0, money.FromInt(25200000)},
+	})
+
+	var rekening []postgres.RekeningSimpanPinjam
+	require.NoError(t, f.db.Preload("Produk").Order("id ASC").Find(&rekening).Error)
+	require.Len(t, rekening, 3)
+	assert.Equal(t, f.siti.ID, rekening[0].AnggotaID)
+	assert.Equal(t, money.FromInt(3000000), rekening[0].SaldoSimpanan)
+	assert.Equal(t, "PJ", rekening[2].Produk.KodeProduk)
+	assert.Equal(t, money.FromInt(6000000), rekening[2].SisaPokok)
+	assert.Equal(t, 12, rekening[2].JangkaWaktu)
+
+	var pembelian postgres.PembelianHeader
+	require.NoError(t, f.db.Where("nomor_faktur = ?", "INV-0912").First(&pembelian).Error)
+	assert.Equal(t, f.supplier.ID, pembelian.SupplierID)
+	assert.Equal(t, money.FromInt(2500000), pembelian.GrandTotal)
+	assert.Equal(t, "unpaid", pembelian.StatusPembayaran)
+	assert.Equal(t, migrasi.JurnalID, pembelian.JurnalID)
+
+	var penjualan postgres.PenjualanHeader
+	require.NoError(t, f.db.Where("nomor_transaksi = ?", "PJL-0930").First(&penjualan).Error)
+	assert.Equal(t, f.siti.ID, penjualan.AnggotaID)
+	assert.Equal(t, "credit", penjualan.MetodePembayaran)
+	assert.Equal(t, money.FromInt(700000), penjualan.GrandTotal)
+	assert.Equal(t, "unpaid", penjualan.StatusPembayaran)
+
+	migrasi, err = f.service.GetMigrasi(1)
+	require.NoError(t, err)
+	require.Len(t, migrasi.Faktur, 2)
+	assert.Equal(t, pembelian.ID, migrasi.Faktur[0].PembelianHeaderID)
+	assert.Equal(t, penjualan.ID, migrasi.Faktur[1].PenjualanHeaderID)
+
+	_, err = f.service.PostMigrasi(1, 1)
+	assert.Error(t, err, "saldo awal is posted once")
+}
+
+// TestSaldoAwalCutover checks both sides of the cutover: a journal already
+// dated on or before it stops the posting, and once posted no journal can
+// be dated on or before it.
+func TestSaldoAwalCutover(t *testing.T) {
+	f := newSaldoAwalFixture(t)
+	f.importSemua(t)
+
+	jurnal := func(tanggal time.Time) (*postgres.JurnalUmum, error) {
+		return f.financial.CreateJurnalUmum(&services.CreateJurnalRequest{
+			TenantID:         1,
+			KoperasiID:       1,
+			TanggalTransaksi: tanggal,
+			Keterangan:       "Setoran modal",
+			Details: []services.CreateJurnalDetailRequest{
+				{AkunID: f.akun["1101"].ID, Debit: money.FromInt(50000)},
+				{AkunID: f.akun["3101"].ID, Kredit: money.FromInt(50000)},
+			},
+			CreatedBy: 1,
+		})
+	}
+
+	sebelum, err := jurnal(tgl(2024, 12, 31))
+	require.NoError(t, err)
+	hasil, err := f.service.Rekonsiliasi(1)
+	require.NoError(t, err)
+	assert.False(t, hasil.SiapPosting)
+	assert.Contains(t, hasil.Errors, "1 jurnal already dated on or before the cutover")
+	_, err = f.service.PostMigrasi(1, 1)
+	require.Error(t, err)
+
+	require.NoError(t, f.financial.CancelJurnal(sebelum.ID, 1))
+	_, err = f.service.PostMigrasi(1, 1)
+	require.NoError(t, err)
+
+	for _, tanggal := range []time.Time{tgl(2024, 6, 15), tgl(2024, 12, 31)} {
+		_, err := jurnal(tanggal)
+		require.Error(t, err, tanggal)
+		assert.Contains(t, err.Error(), "saldo awal cutover")
+	}
+	_, err = jurnal(tgl(2025, 1, 1))
+	assert.NoError(t, err)
+}
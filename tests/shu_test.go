@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+	"koperasi-merah-putih/internal/services"
+	"koperasi-merah-putih/tests/helpers"
+)
+
+// TestSHUHitungOnMigratedSchema runs Hitung against the migrated schema: the
+// net income takes every posted pendapatan and beban line of the year,
+// including 31 December after midnight, and leaves out the year-end close and
+// the next year.
+func TestSHUHitungOnMigratedSchema(t *testing.T) {
+	db := helpers.OpenTestPostgres(t)
+
+	pendapatan := postgres.COAKategori{Kode: "4", Nama: "Pendapatan", Tipe: "pendapatan"}
+	beban := postgres.COAKategori{Kode: "5", Nama: "Beban", Tipe: "beban"}
+	require.NoError(t, db.Create(&pendapatan).Error)
+	require.NoError(t, db.Create(&beban).Error)
+
+	akunPendapatan := postgres.COAAkun{TenantID: 1, KoperasiID: 1, KodeAkun: "4101", NamaAkun: "Pendapatan Usaha", KategoriID: pendapatan.ID, SaldoNormal: "kredit"}
+	akunBeban := postgres.COAAkun{TenantID: 1, KoperasiID: 1, KodeAkun: "5101", NamaAkun: "Beban Usaha", KategoriID: beban.ID, SaldoNormal: "debit"}
+	require.NoError(t, db.Create(&akunPendapatan).Error)
+	require.NoError(t, db.Create(&akunBeban).Error)
+
+	jurnal := func(tanggal time.Time, sumber string, akunID uint64, debit, kredit int64) {
+		j := postgres.JurnalUmum{
+			TenantID:         1,
+			KoperasiID:       1,
+			NomorJurnal:      tanggal.Format("JU-20060102150405") + sumber,
+			TanggalTransaksi: tanggal,
+			Status:           "posted",
+			SumberTransaksi:  sumber,
+			JurnalDetail: []postgres.JurnalDetail{
+				{AkunID: akunID, Debit: money.FromInt(debit), Kredit: money.FromInt(kredit)},
+			},
+		}
+		require.NoError(t, db.Create(&j).Error)
+	}
+
+	jurnal(time.Date(2025, 3, 10, 9, 0, 0, 0, time.Local), "manual", akunPendapatan.ID, 0, 10000000)
+	jurnal(time.Date(2025, 6, 1, 9, 0, 0, 0, time.Local), "manual", akunBeban.ID, 4000000, 0)
+	jurnal(time.Date(2025, 12, 31, 15, 0, 0, 0, time.Local), "manual", akunPendapatan.ID, 0, 1000000)
+	jurnal(time.Date(2025, 12, 31, 23, 0, 0, 0, time.Local), "tutup_buku", akunPendapatan.ID, 11000000, 0)
+	jurnal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local), "manual", akunPendapatan.ID, 0, 5000000)
+
+	shuRepo := postgresRepo.NewSHURepository(db)
+	require.NoError(t, shuRepo.SaveSHUConfig(&postgres.SHUConfig{
+		TenantID:         1,
+		KoperasiID:       1,
+		AkunSHUID:        akunPendapatan.ID,
+		ProdukSimpananID: 1,
+		AkunSimpananID:   akunBeban.ID,
+		Alokasi: []postgres.SHUConfigAlokasi{
+			{KodeAlokasi: "cadangan", Nama: "Cadangan", Persen: 40, AkunID: akunPendapatan.ID},
+			{KodeAlokasi: services.SHUAlokasiJasaModal, Nama: "Jasa Modal", Persen: 60, AkunID: akunBeban.ID},
+		},
+	}))
+
+	shuService := services.NewSHUService(shuRepo, postgresRepo.NewFinancialRepository(db), nil, nil, nil)
+	perhitungan, err := shuService.Hitung(&services.HitungSHURequest{KoperasiID: 1, Tahun: 2025}, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, money.FromInt(7000000), perhitungan.SHUBersih)
+	require.Len(t, perhitungan.Alokasi, 2)
+	assert.Equal(t, money.FromInt(2800000), perhitungan.Alokasi[0].Jumlah)
+	assert.Equal(t, money.FromInt(4200000), perhitungan.Alokasi[1].Jumlah)
+
+	var saved postgres.SHUPerhitungan
+	require.NoError(t, db.First(&saved, perhitungan.ID).Error)
+	assert.Equal(t, "draft", saved.Status)
+}
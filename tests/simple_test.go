@@ -11,6 +11,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 
+	"koperasi-merah-putih/internal/money"
 	"koperasi-merah-putih/internal/services"
 )
 
@@ -329,4 +330,25 @@ func TestRBACPatterns(t *testing.T) {
 	}
 
 	t.Log("✓ RBAC patterns verified successfully")
-}
\ No newline at end of file
+}
+
+// Money amounts must stay exact where float64 would drift
+func TestMoneyAmount(t *testing.T) {
+	var total money.Amount
+	for i := 0; i < 10; i++ {
+		total += money.MustParse("0.10")
+	}
+	assert.Equal(t, money.MustParse("1.00"), total)
+
+	assert.Equal(t, money.MustParse("166.67"), money.FromInt(500).Div(3, money.RoundSen))
+	assert.Equal(t, money.FromInt(1099), money.MustParse("9999.99").Percent(11, money.RoundPajak))
+	assert.Equal(t, money.FromInt(100), money.Sum(money.FromInt(100).Split(3)...))
+
+	data, err := json.Marshal(money.MustParse("1500.50"))
+	assert.NoError(t, err)
+	assert.Equal(t, "1500.5", string(data))
+
+	var parsed money.Amount
+	assert.NoError(t, json.Unmarshal([]byte(`"250000"`), &parsed))
+	assert.Equal(t, money.FromInt(250000), parsed)
+}
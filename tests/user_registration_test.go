@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"koperasi-merah-putih/internal/models/postgres"
+	"koperasi-merah-putih/internal/money"
+	postgresRepo "koperasi-merah-putih/internal/repository/postgres"
+	"koperasi-merah-putih/internal/services"
+	"koperasi-merah-putih/tests/helpers"
+)
+
+func newFinancialService(db *gorm.DB) *services.FinancialService {
+	return services.NewFinancialService(
+		postgresRepo.NewFinancialRepository(db),
+		postgresRepo.NewPeriodeRepository(db),
+		postgresRepo.NewAnggaranRepository(db),
+		postgresRepo.NewLampiranRepository(db),
+		services.NewSequenceService(postgresRepo.NewSequenceRepository(db)),
+	)
+}
+
+// TestVerifyPaymentPostsSimpananPokok checks that verifying a registration
+// payment records the simpanan pokok, posts it and links the journal, and
+// that the same payment cannot be verified twice.
+func TestVerifyPaymentPostsSimpananPokok(t *testing.T) {
+	db := helpers.OpenTestPostgres(t)
+	helpers.CreateKoperasi(t, db, 1)
+
+	kas := helpers.CreateAkun(t, db, 1, "1101", "aset", "debit")
+	simpananPokok := helpers.CreateAkun(t, db, 1, "3101", "ekuitas", "kredit")
+	helpers.CreatePostingRule(t, db, 1, services.PostingEventSimpananPokok,
+		postgres.PostingRuleLine{AkunID: kas.ID, Posisi: "debit", Komponen: "jumlah"},
+		postgres.PostingRuleLine{AkunID: simpananPokok.ID, Posisi: "kredit", Komponen: "jumlah", Urutan: 1})
+
+	registration := postgres.UserRegistration{
+		KoperasiID:          1,
+		NIK:                 "3201010101010001",
+		NamaLengkap:         "Siti Aminah",
+		JenisKelamin:        "P",
+		Telepon:             "081200000001",
+		Email:               "siti@example.com",
+		Username:            "siti",
+		PasswordHash:        "x",
+		SimpananPokokAmount: money.FromInt(100000),
+		Status:              "pending_payment",
+		PaymentID:           77,
+	}
+	require.NoError(t, db.Create(&registration).Error)
+
+	financialRepo := postgresRepo.NewFinancialRepository(db)
+	financialService := newFinancialService(db)
+	sequenceService := services.NewSequenceService(postgresRepo.NewSequenceRepository(db))
+	userService := services.NewUserService(
+		postgresRepo.NewUserRepository(db),
+		postgresRepo.NewUserRegistrationRepository(db),
+		postgresRepo.NewAnggotaKoperasiRepository(db),
+		nil,
+		services.NewPostingService(postgresRepo.NewPostingRepository(db), financialRepo, financialService),
+		sequenceService,
+	)
+
+	require.NoError(t, userService.VerifyPayment(77, 5))
+	assert.Error(t, userService.VerifyPayment(77, 5))
+
+	var transaksi postgres.SimpananPokokTransaksi
+	require.NoError(t, db.Where("registration_id = ?", registration.ID).First(&transaksi).Error)
+	assert.Equal(t, "paid", transaksi.Status)
+	assert.Equal(t, money.FromInt(100000), transaksi.Jumlah)
+	require.NotZero(t, transaksi.JurnalID)
+
+	jurnal, err := financialRepo.GetJurnalUmumByID(transaksi.JurnalID)
+	require.NoError(t, err)
+	assert.Equal(t, "simpanan_pokok", jurnal.SumberTransaksi)
+	assert.Equal(t, transaksi.ID, jurnal.SumberID)
+	assert.Equal(t, uint64(5), jurnal.CreatedBy)
+	assert.Equal(t, money.FromInt(100000), jurnal.TotalDebit)
+}